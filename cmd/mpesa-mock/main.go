@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ngenohkevin/lms/internal/mpesamock"
+)
+
+// mpesa-mock runs a fake Daraja API for local development. Point the server at it
+// with LMS_MPESA_BASE_URL and every STK push is answered with a callback after a delay.
+func main() {
+	addr := flag.String("addr", ":8090", "address to listen on")
+	consumerKey := flag.String("consumer-key", "", "consumer key to accept (any when empty)")
+	consumerSecret := flag.String("consumer-secret", "", "consumer secret to accept")
+	passKey := flag.String("pass-key", "", "pass key used to check STK push passwords (unchecked when empty)")
+	delay := flag.Duration("delay", 3*time.Second, "delay before the payment callback is sent")
+	resultCode := flag.Int("result", mpesamock.ResultSuccess, "result code to report: 0 paid, 1 insufficient funds, 1032 cancelled")
+	flag.Parse()
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+
+	server := mpesamock.New(*consumerKey, *consumerSecret, *passKey)
+	server.AutoCallback = true
+	server.CallbackDelay = *delay
+	server.ResultCode = *resultCode
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.Info("Daraja request", "method", r.Method, "path", r.URL.Path)
+		server.ServeHTTP(w, r)
+	})
+
+	logger.Info("Starting fake M-Pesa Daraja API", "addr", *addr, "result_code", *resultCode, "delay", delay.String())
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := httpServer.ListenAndServe(); err != nil {
+		logger.Error("Fake M-Pesa server stopped", "error", err)
+		os.Exit(1)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/ngenohkevin/lms/internal/middleware"
 	"github.com/ngenohkevin/lms/internal/models"
 	"github.com/ngenohkevin/lms/internal/services"
+	"github.com/shopspring/decimal"
 )
 
 func main() {
@@ -80,26 +81,174 @@ func main() {
 		os.Exit(1)
 	}
 	userService := services.NewUserService(db.Pool, logger)
-	bookService := services.NewBookService(db.Queries)
+	bookService := services.NewBookService(services.NewBookStore(db.Pool))
+	bookCopyService := services.NewBookCopyService(db.Queries)
 	studentService := services.NewStudentService(db.Queries, authService)
-	reservationService := services.NewReservationService(db.Queries)
-	enhancedTransactionService := services.NewEnhancedTransactionService(db.Queries, reservationService)
+	policyService := services.NewCirculationPolicyService(db.Queries)
+	calendarService := services.NewCalendarService(db.Queries)
+	blockService := services.NewStudentBlockService(services.NewStudentBlockStore(db.Pool)).
+		WithFineThreshold(decimal.NewFromFloat(cfg.Fines.BlockThreshold)).
+		WithOverdueDays(cfg.Fines.BlockOverdueDays)
+	reservationService := services.NewReservationService(db.Queries).
+		WithPolicyResolver(policyService).
+		WithCalendar(calendarService).
+		WithBlockChecker(blockService).
+		WithMaxSuspensionDays(cfg.Holds.MaxSuspensionDays)
+	enhancedTransactionService := services.NewEnhancedTransactionService(services.NewTransactionStore(db.Pool), reservationService)
+	enhancedTransactionService.WithPickupDays(cfg.Holds.PickupDays).
+		WithPolicyResolver(policyService).WithCalendar(calendarService).WithBlockChecker(blockService)
+	reservationService.WithHoldShelf(enhancedTransactionService.TransactionService)
+	recallService := services.NewRecallService(enhancedTransactionService.TransactionService, logger).
+		WithNoticeDays(cfg.Recalls.NoticeDays)
+	if cfg.Recalls.AutoRecall {
+		reservationService.WithRecaller(recallService)
+	}
+	fineService := services.NewFineService(services.NewFineStore(db.Pool))
+	fineAccrualService := services.NewFineAccrualService(db.Queries, enhancedTransactionService.TransactionService, logger)
+	dashboardService := services.NewStudentDashboardService(db.Queries, enhancedTransactionService)
+	if mpesaConfig := cfg.GetMpesaConfig(); mpesaConfig.Enabled() {
+		fineService.WithPaymentProvider(services.NewDarajaProvider(mpesaConfig))
+		logger.Info("M-Pesa payments enabled", "base_url", mpesaConfig.BaseURL)
+	} else if mpesaConfig.ConsumerKey != "" && mpesaConfig.CallbackToken == "" {
+		logger.Warn("M-Pesa payments disabled: a callback token is required")
+	}
 	importExportService := services.NewImportExportService(bookService, "./uploads")
 
 	// Initialize notification system services
 	emailConfig := &models.EmailConfig{
-		SMTPHost:     cfg.Email.SMTPHost,
-		SMTPPort:     cfg.Email.SMTPPort,
-		SMTPUsername: cfg.Email.SMTPUsername,
-		SMTPPassword: cfg.Email.SMTPPassword,
-		FromEmail:    cfg.Email.FromEmail,
-		FromName:     cfg.Email.FromName,
-		UseTLS:       cfg.Email.UseTLS,
-		UseSSL:       cfg.Email.UseSSL,
+		SMTPHost:           cfg.Email.SMTPHost,
+		SMTPPort:           cfg.Email.SMTPPort,
+		SMTPUsername:       cfg.Email.SMTPUsername,
+		SMTPPassword:       cfg.Email.SMTPPassword,
+		FromEmail:          cfg.Email.FromEmail,
+		FromName:           cfg.Email.FromName,
+		UseTLS:             cfg.Email.UseTLS,
+		UseSSL:             cfg.Email.UseSSL,
+		BounceAddress:      cfg.Email.BounceAddress,
+		BounceSecret:       cfg.Email.BounceSecret,
+		DKIMDomain:         cfg.Email.DKIMDomain,
+		DKIMSelector:       cfg.Email.DKIMSelector,
+		DKIMPrivateKeyPath: cfg.Email.DKIMPrivateKeyPath,
+		LogoPath:           cfg.Email.LogoPath,
 	}
-	emailService := services.NewEmailService(emailConfig, logger)
+	emailTemplateService := services.NewEmailTemplateService(services.NewEmailTemplateStore(db.Pool), services.NewEmailTemplateManager(logger), logger)
+	emailService := services.NewEmailService(emailConfig, logger).WithTemplateStore(emailTemplateService)
 	queueService := services.NewQueueService(redis.Client, logger)
-	notificationService := services.NewNotificationService(db.Queries, emailService, queueService, logger)
+	emailDeliveryService := services.NewEmailDeliveryService(db.Queries, logger)
+	emailBounceService := services.NewEmailBounceService(db.Queries, cfg.GetEmailBounceConfig(), logger)
+	emailQueueService := services.NewEmailQueueService(db.Queries, redis.Client, logger).(*services.EmailQueueService).
+		WithBatchSize(cfg.EmailQueue.BatchSize).
+		WithPollInterval(time.Duration(cfg.EmailQueue.PollIntervalSeconds) * time.Second)
+	notificationService := services.NewNotificationService(db.Queries, emailService, queueService, logger).
+		WithEmailQueue(emailQueueService).
+		WithEmailMaxAttempts(cfg.EmailQueue.MaxAttempts).
+		WithDeliveryRecorder(emailDeliveryService).
+		WithSuppressions(emailBounceService).
+		WithTemplateStore(emailTemplateService)
+	emailQueueService.WithDeliverer(notificationService)
+	reservationService.WithNotifier(notificationService)
+	enhancedTransactionService.WithNotifier(notificationService)
+	blockService.WithNotifier(notificationService)
+
+	var smsService *services.SMSService
+	if smsConfig := cfg.GetSMSConfig(); smsConfig.Enabled() {
+		smsService = services.NewSMSService(db.Queries, services.NewAfricasTalkingProvider(smsConfig), smsConfig.DefaultCountryCode, logger)
+		notificationService.WithSMS(smsService)
+		logger.Info("SMS notifications enabled", "base_url", smsConfig.BaseURL)
+	}
+
+	// Live events travel over Redis pub/sub so clients on any instance receive them
+	realtimeService := services.NewRealtimeService(db.Queries, services.NewRedisEventBroker(redis.Client, logger), logger)
+	notificationService.WithRealtime(realtimeService)
+	reservationService.WithRealtime(realtimeService)
+	enhancedTransactionService.WithRealtime(realtimeService)
+
+	notificationLocation, err := time.LoadLocation(cfg.Notifications.Timezone)
+	if err != nil {
+		logger.Warn("Unknown notification time zone, using local time", "timezone", cfg.Notifications.Timezone, "error", err)
+		notificationLocation = time.Local
+	}
+	preferenceService := services.NewNotificationPreferenceService(db.Queries, notificationLocation)
+	notificationService.WithPreferences(preferenceService)
+	if cfg.Email.UnsubscribeURL != "" && cfg.Email.UnsubscribeSecret != "" {
+		preferenceService.WithUnsubscribeLinks(cfg.Email.UnsubscribeURL, cfg.Email.UnsubscribeSecret)
+		notificationService.WithUnsubscribeLinks(preferenceService)
+	}
+	broadcastService := services.NewBroadcastService(db.Queries, notificationService)
+
+	// Every instance registers the jobs; leader election decides which one fires them
+	jobScheduler := services.NewJobScheduler(db.Queries, services.NewRedisJobLocker(redis.Client), logger)
+	scheduledJobs := []services.ScheduledJob{
+		{
+			Name:     services.DueSoonRemindersJobName,
+			Schedule: cfg.Jobs.Schedules.DueSoonReminders,
+			Run:      notificationService.SendDueSoonReminders,
+		},
+		{
+			Name:     services.OverdueRemindersJobName,
+			Schedule: cfg.Jobs.Schedules.OverdueReminders,
+			Run:      notificationService.SendOverdueReminders,
+		},
+		{
+			Name:     services.FineNoticesJobName,
+			Schedule: cfg.Jobs.Schedules.FineNotices,
+			Run:      notificationService.SendFineNotices,
+		},
+		{
+			Name:     services.ExpireReservationsJobName,
+			Schedule: cfg.Jobs.Schedules.ExpireReservations,
+			Run: func(ctx context.Context) error {
+				expired, err := reservationService.ExpireReservations(ctx)
+				if expired > 0 {
+					logger.Info("Expired reservations", "count", expired)
+				}
+				return err
+			},
+		},
+		{
+			Name:     services.CleanupNotificationsJobName,
+			Schedule: cfg.Jobs.Schedules.CleanupNotifications,
+			Run: func(ctx context.Context) error {
+				return notificationService.CleanupOldNotifications(ctx, cfg.Jobs.NotificationRetentionDays)
+			},
+		},
+		{
+			Name:     services.ScheduledNotificationsJobName,
+			Schedule: cfg.Jobs.Schedules.ScheduledNotifications,
+			Run:      queueService.ProcessScheduledNotifications,
+		},
+		{
+			Name:     services.PublishLiveCountersJobName,
+			Schedule: cfg.Jobs.Schedules.PublishLiveCounters,
+			Run:      realtimeService.PublishCounters,
+		},
+		{
+			Name:     services.DailyDigestsJobName,
+			Schedule: cfg.Jobs.Schedules.DailyDigests,
+			Run:      notificationService.SendDailyDigests,
+		},
+		{
+			Name:     services.WeeklyDigestsJobName,
+			Schedule: cfg.Jobs.Schedules.WeeklyDigests,
+			Run:      notificationService.SendWeeklyDigests,
+		},
+		{
+			Name:     services.ProcessBouncesJobName,
+			Schedule: cfg.Jobs.Schedules.ProcessBounces,
+			Run:      emailBounceService.ProcessMaildir,
+		},
+		{
+			Name:     services.FineAccrualJobName,
+			Schedule: cfg.Jobs.Schedules.FineAccrual,
+			Run:      fineAccrualService.Run,
+		},
+	}
+	for _, job := range scheduledJobs {
+		if err := jobScheduler.Register(job); err != nil {
+			slog.Error("Invalid job configuration", "error", err)
+			os.Exit(1)
+		}
+	}
 
 	// Initialize Gin router
 	r := gin.New()
@@ -120,13 +269,28 @@ func main() {
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler(db, redis, emailService)
 	authHandler := handlers.NewAuthHandler(authService, userService)
+	preferenceHandler := handlers.NewNotificationPreferenceHandler(preferenceService)
 	bookHandler := handlers.NewBookHandler(bookService)
-	studentHandler := handlers.NewStudentHandler(studentService)
+	bookCopyHandler := handlers.NewBookCopyHandler(bookCopyService)
+	studentHandler := handlers.NewStudentHandler(studentService).WithBlockService(blockService)
+	studentBlockHandler := handlers.NewStudentBlockHandler(blockService)
 	reservationHandler := handlers.NewReservationHandler(reservationService)
 	transactionHandler := handlers.NewTransactionHandler(enhancedTransactionService)
+	recallHandler := handlers.NewRecallHandler(recallService)
+	policyHandler := handlers.NewCirculationPolicyHandler(policyService)
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+	fineHandler := handlers.NewFineHandler(fineService)
+	fineAccrualHandler := handlers.NewFineAccrualHandler(fineAccrualService)
+	dashboardHandler := handlers.NewStudentDashboardHandler(dashboardService)
 	uploadHandler := handlers.NewUploadHandler(bookService)
 	importExportHandler := handlers.NewImportExportHandler(importExportService)
 	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	broadcastHandler := handlers.NewBroadcastHandler(broadcastService)
+	jobHandler := handlers.NewJobHandler(jobScheduler)
+	emailQueueHandler := handlers.NewEmailQueueHandler(emailQueueService)
+	emailTemplateHandler := handlers.NewEmailTemplateHandler(emailTemplateService)
+	emailBounceHandler := handlers.NewEmailBounceHandler(emailBounceService)
+	realtimeHandler := handlers.NewRealtimeHandler(realtimeService, time.Duration(cfg.Realtime.HeartbeatSeconds)*time.Second)
 
 	// Public routes (no authentication required)
 	public := r.Group("/api/v1")
@@ -143,6 +307,34 @@ func main() {
 			auth.POST("/forgot-password", authHandler.ForgotPassword)
 			auth.POST("/reset-password", authHandler.ResetPassword)
 		}
+
+		// Payment provider callbacks
+		public.POST("/payments/mpesa/callback", fineHandler.MpesaCallback)
+
+		// SMS gateway delivery reports
+		if smsService != nil {
+			public.POST("/sms/delivery-reports", handlers.NewSMSHandler(smsService).DeliveryReport)
+		}
+
+		// Bounce messages forwarded by the mail server
+		if cfg.EmailBounces.WebhookToken != "" {
+			public.POST("/email/bounces", emailBounceHandler.ReceiveBounce)
+		}
+
+		// One-click unsubscribe links in notice emails
+		if cfg.Email.UnsubscribeURL != "" && cfg.Email.UnsubscribeSecret != "" {
+			public.POST("/notifications/unsubscribe", preferenceHandler.Unsubscribe)
+		}
+	}
+
+	// Live event streams; a long-lived connection is not rate limited like API calls
+	events := r.Group("/api/v1/events")
+	events.Use(authMiddleware.RequireStreamAuth())
+	{
+		events.GET("/stream", realtimeHandler.Stream)
+		if cfg.Realtime.WebSocket {
+			events.GET("/ws", realtimeHandler.WebSocket)
+		}
 	}
 
 	// Protected routes (authentication required)
@@ -152,6 +344,9 @@ func main() {
 	{
 		// Profile management
 		protected.GET("/profile", authHandler.GetProfile)
+		protected.PUT("/profile/locale", authHandler.UpdateLocale)
+		protected.GET("/profile/notification-preferences", preferenceHandler.GetPreferences)
+		protected.PUT("/profile/notification-preferences", preferenceHandler.UpdatePreferences)
 		protected.POST("/auth/logout", authHandler.Logout)
 		protected.POST("/auth/change-password", authHandler.ChangePassword)
 
@@ -168,6 +363,10 @@ func main() {
 			books.PUT("/:id", bookHandler.UpdateBook)
 			books.DELETE("/:id", bookHandler.DeleteBook)
 
+			// Physical copy routes
+			books.GET("/:id/copies", bookCopyHandler.ListCopies)
+			books.POST("/:id/copies", bookCopyHandler.AddCopy)
+
 			// File upload routes
 			books.POST("/:id/cover", uploadHandler.UploadBookCover)
 			books.DELETE("/:id/cover", uploadHandler.DeleteBookCover)
@@ -181,6 +380,42 @@ func main() {
 			books.GET("/export-history", importExportHandler.GetExportHistory)
 		}
 
+		// Book copy routes (librarian access required)
+		copies := protected.Group("/copies")
+		copies.Use(authMiddleware.RequireLibrarian())
+		{
+			copies.GET("/barcode/:barcode", bookCopyHandler.GetCopyByBarcode)
+			copies.PUT("/:id", bookCopyHandler.UpdateCopy)
+		}
+
+		// Circulation policy routes (admin access required)
+		policies := protected.Group("/circulation-policies")
+		policies.Use(authMiddleware.RequireAdmin())
+		{
+			policies.GET("", policyHandler.ListPolicies)
+			policies.POST("", policyHandler.CreatePolicy)
+			policies.GET("/:id", policyHandler.GetPolicy)
+			policies.PUT("/:id", policyHandler.UpdatePolicy)
+			policies.DELETE("/:id", policyHandler.DeletePolicy)
+		}
+
+		// Library calendar routes (read for all users, changes require admin access)
+		calendar := protected.Group("/calendar")
+		{
+			calendar.GET("/hours", calendarHandler.GetOpeningHours)
+			calendar.GET("/closures", calendarHandler.ListClosures)
+			calendar.GET("/closures/:id", calendarHandler.GetClosure)
+
+			calendarAdmin := calendar.Group("")
+			calendarAdmin.Use(authMiddleware.RequireAdmin())
+			{
+				calendarAdmin.PUT("/hours/:day", calendarHandler.UpdateOpeningHours)
+				calendarAdmin.POST("/closures", calendarHandler.CreateClosure)
+				calendarAdmin.PUT("/closures/:id", calendarHandler.UpdateClosure)
+				calendarAdmin.DELETE("/closures/:id", calendarHandler.DeleteClosure)
+			}
+		}
+
 		// Student management routes (librarian access required)
 		students := protected.Group("/students")
 		students.Use(authMiddleware.RequireLibrarian())
@@ -189,6 +424,7 @@ func main() {
 			students.GET("", studentHandler.ListStudents)
 			students.GET("/search", studentHandler.SearchStudents)
 			students.GET("/statistics", studentHandler.GetStudentStatistics)
+			students.GET("/email-issues", emailBounceHandler.ListStudentEmailIssues)
 			students.POST("/generate-id", studentHandler.GenerateStudentID)
 			students.POST("/bulk-import", studentHandler.BulkImportStudents)
 			students.GET("/:id", studentHandler.GetStudent)
@@ -219,6 +455,36 @@ func main() {
 
 			// Phase 6.7: Renewal statistics for students (accessible by librarians)
 			students.GET("/:id/renewal-statistics", transactionHandler.GetRenewalStatistics)
+
+			// Fine balance for the desk
+			students.GET("/:id/fines", fineHandler.GetStudentBalance)
+			students.POST("/:id/fines/mpesa", fineHandler.InitiateMobilePayment)
+
+			// Account blocks
+			students.GET("/:id/blocks", studentBlockHandler.GetStudentBlocks)
+			students.POST("/:id/blocks", studentBlockHandler.PlaceBlock)
+			students.POST("/:id/blocks/:block_id/lift", studentBlockHandler.LiftBlock)
+
+			// Email addresses flagged invalid after repeated bounces
+			students.DELETE("/:id/email-suppression", emailBounceHandler.ClearStudentSuppression)
+		}
+
+		// Fine ledger routes (librarian access required)
+		fines := protected.Group("/fines")
+		fines.Use(authMiddleware.RequireLibrarian())
+		{
+			fines.POST("", fineHandler.CreateFine)
+			fines.GET("/reconciliation", fineHandler.GetCashReconciliation)
+			fines.GET("/accrual", fineAccrualHandler.GetStatus)
+			fines.POST("/accrual/run", fineAccrualHandler.RunAccrual)
+			fines.GET("/receipts/:receipt", fineHandler.GetReceipt)
+			fines.GET("/payment-requests/:id", fineHandler.GetPaymentRequest)
+			fines.GET("/:id", fineHandler.GetFine)
+			fines.GET("/:id/ledger", fineHandler.GetFineLedger)
+			fines.POST("/:id/payments", fineHandler.RecordPayment)
+			fines.POST("/:id/waive", fineHandler.WaiveFine)
+			fines.POST("/:id/refunds", fineHandler.RefundPayment)
+			fines.POST("/:id/adjustments", fineHandler.AdjustFine)
 		}
 
 		// Reservation management routes
@@ -228,14 +494,18 @@ func main() {
 			reservations.POST("", reservationHandler.ReserveBook)
 			reservations.GET("/my-reservations", reservationHandler.GetStudentReservations)
 			reservations.POST("/:id/cancel", reservationHandler.CancelReservation)
+			reservations.POST("/:id/suspend", reservationHandler.SuspendReservation)
+			reservations.POST("/:id/resume", reservationHandler.ResumeReservation)
 
 			// Librarian routes - librarians can manage all reservations
 			librarianReservations := reservations.Group("")
 			librarianReservations.Use(authMiddleware.RequireLibrarian())
 			{
 				librarianReservations.GET("", reservationHandler.GetAllReservations)
+				librarianReservations.GET("/hold-shelf", reservationHandler.GetHoldShelf)
 				librarianReservations.GET("/:id", reservationHandler.GetReservation)
 				librarianReservations.POST("/:id/fulfill", reservationHandler.FulfillReservation)
+				librarianReservations.POST("/:id/move", reservationHandler.MoveReservation)
 				librarianReservations.GET("/student/:studentId", reservationHandler.GetStudentReservations)
 				librarianReservations.GET("/book/:bookId", reservationHandler.GetBookReservations)
 				librarianReservations.GET("/book/:bookId/next", reservationHandler.GetNextReservation)
@@ -251,6 +521,9 @@ func main() {
 			librarianTransactions.Use(authMiddleware.RequireLibrarian())
 			{
 				librarianTransactions.POST("/borrow", transactionHandler.BorrowBook)
+				librarianTransactions.POST("/batch/borrow", transactionHandler.BatchBorrow)
+				librarianTransactions.POST("/batch/return", transactionHandler.BatchReturn)
+				librarianTransactions.POST("/return", transactionHandler.ReturnBookByBarcode)
 				librarianTransactions.POST("/:id/return", transactionHandler.ReturnBook)
 				librarianTransactions.POST("/:id/renew", transactionHandler.RenewBook)
 				librarianTransactions.GET("/overdue", transactionHandler.GetOverdueTransactions)
@@ -258,6 +531,10 @@ func main() {
 				// Phase 6.7: Enhanced Renewal System endpoints
 				librarianTransactions.GET("/:id/can-renew", transactionHandler.CanBookBeRenewed)
 				librarianTransactions.GET("/renewal-history", transactionHandler.GetRenewalHistory)
+				librarianTransactions.POST("/:id/lost", transactionHandler.MarkLost)
+				librarianTransactions.POST("/:id/claimed-returned", transactionHandler.MarkClaimedReturned)
+				librarianTransactions.POST("/:id/found", transactionHandler.MarkFound)
+				librarianTransactions.POST("/:id/recall", recallHandler.RecallLoan)
 			}
 
 			// Student can view their own transaction history
@@ -271,6 +548,15 @@ func main() {
 			profile.PUT("", studentHandler.UpdateStudentProfile)
 		}
 
+		// Student self-service: the student is taken from the token, never the URL
+		me := protected.Group("/students/me")
+		me.Use(authMiddleware.RequireStudent())
+		{
+			me.GET("/dashboard", dashboardHandler.GetDashboard)
+			me.GET("/loans/:id/can-renew", transactionHandler.CanRenewOwnLoan)
+			me.POST("/loans/:id/renew", transactionHandler.RenewOwnLoan)
+		}
+
 		// Notification management routes
 		notifications := protected.Group("/notifications")
 		{
@@ -292,9 +578,49 @@ func main() {
 				librarianNotifications.POST("/overdue", notificationHandler.SendOverdueReminders)
 				librarianNotifications.POST("/book-available", notificationHandler.SendBookAvailableNotifications)
 				librarianNotifications.POST("/fine-notices", notificationHandler.SendFineNotices)
+				librarianNotifications.POST("/broadcasts", broadcastHandler.CreateBroadcast)
+				librarianNotifications.GET("/broadcasts", broadcastHandler.ListBroadcasts)
+				librarianNotifications.GET("/broadcasts/:id", broadcastHandler.GetBroadcast)
 			}
 		}
 
+		// Scheduled job administration
+		jobs := protected.Group("/jobs")
+		jobs.Use(authMiddleware.RequireAdmin())
+		{
+			jobs.GET("", jobHandler.ListJobs)
+			jobs.GET("/:name/runs", jobHandler.ListRuns)
+			jobs.POST("/:name/run", jobHandler.TriggerJob)
+			jobs.POST("/:name/pause", jobHandler.PauseJob)
+			jobs.POST("/:name/resume", jobHandler.ResumeJob)
+		}
+
+		// Email queue administration
+		emailQueue := protected.Group("/email-queue")
+		emailQueue.Use(authMiddleware.RequireAdmin())
+		{
+			emailQueue.GET("/stats", emailQueueHandler.GetStats)
+			emailQueue.POST("/:id/retry", emailQueueHandler.RetryItem)
+			emailQueue.POST("/:id/cancel", emailQueueHandler.CancelItem)
+		}
+
+		// Email template editing, versioning and preview
+		emailTemplates := protected.Group("/email-templates")
+		emailTemplates.Use(authMiddleware.RequireLibrarian())
+		{
+			emailTemplates.GET("", emailTemplateHandler.ListTemplates)
+			emailTemplates.POST("", emailTemplateHandler.CreateTemplate)
+			emailTemplates.GET("/:name", emailTemplateHandler.GetTemplate)
+			emailTemplates.PUT("/:name", emailTemplateHandler.UpdateTemplate)
+			emailTemplates.DELETE("/:name", emailTemplateHandler.DeleteTemplate)
+			emailTemplates.GET("/:name/versions", emailTemplateHandler.ListVersions)
+			emailTemplates.GET("/:name/versions/:version", emailTemplateHandler.GetVersion)
+			emailTemplates.POST("/:name/versions/:version/publish", emailTemplateHandler.PublishVersion)
+			emailTemplates.POST("/:name/versions/:version/rollback", emailTemplateHandler.RollbackTemplate)
+			emailTemplates.POST("/:name/preview", emailTemplateHandler.PreviewTemplate)
+			emailTemplates.GET("/:name/diff", emailTemplateHandler.DiffVersions)
+		}
+
 	}
 
 	// Static file serving for uploaded images
@@ -317,6 +643,29 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// The built-in templates are stored once so they can be edited like any other
+	if err := emailTemplateService.SeedDefaults(context.Background()); err != nil {
+		slog.Error("Failed to seed email templates", "error", err)
+	}
+
+	if cfg.Jobs.Enabled {
+		jobScheduler.Start()
+		slog.Info("Job scheduler started")
+	}
+
+	if cfg.EmailQueue.Workers > 0 {
+		// Emails claimed by a server that died are sent again rather than lost
+		stuckAfter := time.Duration(cfg.EmailQueue.StuckAfterMinutes) * time.Minute
+		if err := emailQueueService.ResetStuckItems(context.Background(), stuckAfter); err != nil {
+			slog.Error("Failed to reset stuck emails", "error", err)
+		}
+		if err := emailQueueService.StartWorkers(context.Background(), cfg.EmailQueue.Workers); err != nil {
+			slog.Error("Failed to start email workers", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Email workers started", "workers", cfg.EmailQueue.Workers)
+	}
+
 	// Start server in a goroutine
 	go func() {
 		slog.Info("Starting server", "port", port, "mode", cfg.Server.Mode)
@@ -336,6 +685,17 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Live event streams never end on their own, so they are closed first
+	realtimeHandler.Shutdown()
+
+	// Let running jobs finish before the database and Redis connections close
+	if err := jobScheduler.Shutdown(ctx); err != nil {
+		slog.Error("Scheduled jobs did not finish", "error", err)
+	}
+	if err := emailQueueService.Shutdown(ctx); err != nil {
+		slog.Error("Email workers did not finish", "error", err)
+	}
+
 	if err := srv.Shutdown(ctx); err != nil {
 		slog.Error("Server forced to shutdown", "error", err)
 		os.Exit(1)
@@ -10,11 +10,21 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Email    EmailConfig    `mapstructure:"email"`
+	Server        ServerConfig        `mapstructure:"server"`
+	Database      DatabaseConfig      `mapstructure:"database"`
+	Redis         RedisConfig         `mapstructure:"redis"`
+	JWT           JWTConfig           `mapstructure:"jwt"`
+	Email         EmailConfig         `mapstructure:"email"`
+	EmailQueue    EmailQueueConfig    `mapstructure:"email_queue"`
+	EmailBounces  EmailBouncesConfig  `mapstructure:"email_bounces"`
+	Mpesa         MpesaConfig         `mapstructure:"mpesa"`
+	SMS           SMSConfig           `mapstructure:"sms"`
+	Fines         FinesConfig         `mapstructure:"fines"`
+	Recalls       RecallsConfig       `mapstructure:"recalls"`
+	Holds         HoldsConfig         `mapstructure:"holds"`
+	Realtime      RealtimeConfig      `mapstructure:"realtime"`
+	Notifications NotificationsConfig `mapstructure:"notifications"`
+	Jobs          JobsConfig          `mapstructure:"jobs"`
 }
 
 type ServerConfig struct {
@@ -55,6 +65,124 @@ type EmailConfig struct {
 	FromName     string `mapstructure:"from_name"`
 	UseTLS       bool   `mapstructure:"use_tls"`
 	UseSSL       bool   `mapstructure:"use_ssl"`
+	// BounceAddress is the return path emails are sent with; each email's carries its
+	// delivery ID so bounces can be matched to it
+	BounceAddress string `mapstructure:"bounce_address"`
+	// BounceSecret signs the delivery IDs in return paths so bounce reports cannot be
+	// forged for other emails; return paths carry no delivery ID unless it is set
+	BounceSecret string `mapstructure:"bounce_secret"`
+	// DKIM signs outgoing email with the private key at DKIMPrivateKeyPath, whose public
+	// key is published at <DKIMSelector>._domainkey.<DKIMDomain>; email is unsigned unless all three are set
+	DKIMDomain         string `mapstructure:"dkim_domain"`
+	DKIMSelector       string `mapstructure:"dkim_selector"`
+	DKIMPrivateKeyPath string `mapstructure:"dkim_private_key_path"`
+	// LogoPath is an image embedded in HTML emails that show it with <img src="cid:logo">
+	LogoPath string `mapstructure:"logo_path"`
+	// UnsubscribeURL is where the unsubscribe endpoint is reached from outside; emails of notices
+	// that can be turned off carry a List-Unsubscribe link to it when UnsubscribeSecret is also set
+	UnsubscribeURL string `mapstructure:"unsubscribe_url"`
+	// UnsubscribeSecret signs unsubscribe links so they cannot be made for other recipients
+	UnsubscribeSecret string `mapstructure:"unsubscribe_secret"`
+}
+
+type EmailBouncesConfig struct {
+	// WebhookToken must be given by whatever posts bounce messages to the webhook; the webhook is off when empty
+	WebhookToken string `mapstructure:"webhook_token"`
+	// Maildir is the mailbox the bounce address delivers to; it is polled for bounces when set
+	Maildir string `mapstructure:"maildir"`
+	// HardBounceThreshold is how many hard bounces flag an address invalid and stop email to it
+	HardBounceThreshold int `mapstructure:"hard_bounce_threshold"`
+}
+
+type EmailQueueConfig struct {
+	// Workers is how many emails this server sends at once; 0 leaves sending to other servers
+	Workers int `mapstructure:"workers"`
+	// BatchSize is how many queued emails a worker claims at a time
+	BatchSize int `mapstructure:"batch_size"`
+	// PollIntervalSeconds is how often an idle worker checks for due emails
+	PollIntervalSeconds int `mapstructure:"poll_interval_seconds"`
+	// StuckAfterMinutes is how long an email may stay claimed before startup puts it back in the queue
+	StuckAfterMinutes int `mapstructure:"stuck_after_minutes"`
+	// MaxAttempts is how many times an email is tried before it is left failed
+	MaxAttempts int `mapstructure:"max_attempts"`
+}
+
+type MpesaConfig struct {
+	BaseURL        string `mapstructure:"base_url"`
+	ConsumerKey    string `mapstructure:"consumer_key"`
+	ConsumerSecret string `mapstructure:"consumer_secret"`
+	ShortCode      string `mapstructure:"short_code"`
+	PassKey        string `mapstructure:"pass_key"`
+	CallbackURL    string `mapstructure:"callback_url"`
+	CallbackToken  string `mapstructure:"callback_token"`
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"`
+}
+
+type SMSConfig struct {
+	BaseURL        string `mapstructure:"base_url"`
+	Username       string `mapstructure:"username"`
+	APIKey         string `mapstructure:"api_key"`
+	SenderID       string `mapstructure:"sender_id"`
+	CallbackToken  string `mapstructure:"callback_token"`
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"`
+	// DefaultCountryCode is the calling code assumed for phone numbers stored without one
+	DefaultCountryCode string `mapstructure:"default_country_code"`
+}
+
+type FinesConfig struct {
+	// BlockThreshold is the unpaid balance above which a student's account is blocked; 0 disables it
+	BlockThreshold float64 `mapstructure:"block_threshold"`
+	// BlockOverdueDays is how long a loan may be overdue before the account is blocked; 0 disables it
+	BlockOverdueDays int `mapstructure:"block_overdue_days"`
+}
+
+type RecallsConfig struct {
+	// AutoRecall recalls a loan of a book whenever a reservation is placed for it
+	AutoRecall bool `mapstructure:"auto_recall"`
+	// NoticeDays is the least time a student is given to return a recalled book
+	NoticeDays int `mapstructure:"notice_days"`
+}
+
+type HoldsConfig struct {
+	// PickupDays is how long a returned book is held for the student who reserved it
+	PickupDays int `mapstructure:"pickup_days"`
+	// MaxSuspensionDays is how far ahead a student may pause a reservation
+	MaxSuspensionDays int `mapstructure:"max_suspension_days"`
+}
+
+type RealtimeConfig struct {
+	// HeartbeatSeconds is how often an idle event stream is sent a heartbeat
+	HeartbeatSeconds int `mapstructure:"heartbeat_seconds"`
+	// WebSocket offers the event stream over WebSocket as well as server-sent events
+	WebSocket bool `mapstructure:"websocket"`
+}
+
+type NotificationsConfig struct {
+	// Timezone is the IANA time zone that recipients' quiet hours are read in
+	Timezone string `mapstructure:"timezone"`
+}
+
+type JobsConfig struct {
+	// Enabled fires the job schedules; when off the jobs only run when an admin triggers them
+	Enabled bool `mapstructure:"enabled"`
+	// NotificationRetentionDays is how long read notifications are kept before cleanup removes them
+	NotificationRetentionDays int `mapstructure:"notification_retention_days"`
+	// Schedules are cron expressions; an empty schedule leaves the job to be triggered by hand
+	Schedules JobSchedules `mapstructure:"schedules"`
+}
+
+type JobSchedules struct {
+	DueSoonReminders       string `mapstructure:"due_soon_reminders"`
+	OverdueReminders       string `mapstructure:"overdue_reminders"`
+	FineNotices            string `mapstructure:"fine_notices"`
+	ExpireReservations     string `mapstructure:"expire_reservations"`
+	CleanupNotifications   string `mapstructure:"cleanup_notifications"`
+	ScheduledNotifications string `mapstructure:"scheduled_notifications"`
+	PublishLiveCounters    string `mapstructure:"publish_live_counters"`
+	DailyDigests           string `mapstructure:"daily_digests"`
+	WeeklyDigests          string `mapstructure:"weekly_digests"`
+	ProcessBounces         string `mapstructure:"process_bounces"`
+	FineAccrual            string `mapstructure:"fine_accrual"`
 }
 
 func Load() (*Config, error) {
@@ -83,6 +211,39 @@ func Load() (*Config, error) {
 	viper.SetDefault("email.from_name", "Library Management System")
 	viper.SetDefault("email.use_tls", true)
 	viper.SetDefault("email.use_ssl", false)
+	viper.SetDefault("mpesa.base_url", "https://sandbox.safaricom.co.ke")
+	viper.SetDefault("mpesa.timeout_seconds", 30)
+	viper.SetDefault("sms.base_url", "https://api.sandbox.africastalking.com")
+	viper.SetDefault("sms.timeout_seconds", 30)
+	viper.SetDefault("sms.default_country_code", "254")
+	viper.SetDefault("fines.block_threshold", 10.0)
+	viper.SetDefault("fines.block_overdue_days", 30)
+	viper.SetDefault("recalls.auto_recall", true)
+	viper.SetDefault("recalls.notice_days", 3)
+	viper.SetDefault("holds.pickup_days", 3)
+	viper.SetDefault("holds.max_suspension_days", 30)
+	viper.SetDefault("realtime.heartbeat_seconds", 25)
+	viper.SetDefault("realtime.websocket", true)
+	viper.SetDefault("notifications.timezone", "Africa/Nairobi")
+	viper.SetDefault("email_queue.workers", 2)
+	viper.SetDefault("email_queue.batch_size", 10)
+	viper.SetDefault("email_queue.poll_interval_seconds", 5)
+	viper.SetDefault("email_queue.stuck_after_minutes", 15)
+	viper.SetDefault("email_queue.max_attempts", 3)
+	viper.SetDefault("email_bounces.hard_bounce_threshold", 3)
+	viper.SetDefault("jobs.enabled", true)
+	viper.SetDefault("jobs.notification_retention_days", 30)
+	viper.SetDefault("jobs.schedules.due_soon_reminders", "0 8 * * *")
+	viper.SetDefault("jobs.schedules.overdue_reminders", "0 9 * * *")
+	viper.SetDefault("jobs.schedules.fine_notices", "0 10 * * 1")
+	viper.SetDefault("jobs.schedules.expire_reservations", "*/15 * * * *")
+	viper.SetDefault("jobs.schedules.cleanup_notifications", "30 2 * * *")
+	viper.SetDefault("jobs.schedules.scheduled_notifications", "* * * * *")
+	viper.SetDefault("jobs.schedules.publish_live_counters", "* * * * *")
+	viper.SetDefault("jobs.schedules.daily_digests", "0 7 * * *")
+	viper.SetDefault("jobs.schedules.weekly_digests", "0 7 * * 1")
+	viper.SetDefault("jobs.schedules.process_bounces", "*/5 * * * *")
+	viper.SetDefault("jobs.schedules.fine_accrual", "0 1 * * *")
 
 	// Try to read config file
 	if err := viper.ReadInConfig(); err != nil {
@@ -121,6 +282,55 @@ func Load() (*Config, error) {
 	if fromName := os.Getenv("LMS_EMAIL_FROM_NAME"); fromName != "" {
 		viper.Set("email.from_name", fromName)
 	}
+	if bounceAddress := os.Getenv("LMS_EMAIL_BOUNCE_ADDRESS"); bounceAddress != "" {
+		viper.Set("email.bounce_address", bounceAddress)
+	}
+	if bounceSecret := os.Getenv("LMS_EMAIL_BOUNCE_SECRET"); bounceSecret != "" {
+		viper.Set("email.bounce_secret", bounceSecret)
+	}
+	if dkimKeyPath := os.Getenv("LMS_EMAIL_DKIM_PRIVATE_KEY_PATH"); dkimKeyPath != "" {
+		viper.Set("email.dkim_private_key_path", dkimKeyPath)
+	}
+	if unsubscribeSecret := os.Getenv("LMS_EMAIL_UNSUBSCRIBE_SECRET"); unsubscribeSecret != "" {
+		viper.Set("email.unsubscribe_secret", unsubscribeSecret)
+	}
+	if webhookToken := os.Getenv("LMS_EMAIL_BOUNCES_WEBHOOK_TOKEN"); webhookToken != "" {
+		viper.Set("email_bounces.webhook_token", webhookToken)
+	}
+	if maildir := os.Getenv("LMS_EMAIL_BOUNCES_MAILDIR"); maildir != "" {
+		viper.Set("email_bounces.maildir", maildir)
+	}
+
+	// M-Pesa configuration from environment
+	mpesaEnvVars := map[string]string{
+		"LMS_MPESA_BASE_URL":        "mpesa.base_url",
+		"LMS_MPESA_CONSUMER_KEY":    "mpesa.consumer_key",
+		"LMS_MPESA_CONSUMER_SECRET": "mpesa.consumer_secret",
+		"LMS_MPESA_SHORT_CODE":      "mpesa.short_code",
+		"LMS_MPESA_PASS_KEY":        "mpesa.pass_key",
+		"LMS_MPESA_CALLBACK_URL":    "mpesa.callback_url",
+		"LMS_MPESA_CALLBACK_TOKEN":  "mpesa.callback_token",
+	}
+	for env, key := range mpesaEnvVars {
+		if value := os.Getenv(env); value != "" {
+			viper.Set(key, value)
+		}
+	}
+
+	// SMS configuration from environment
+	smsEnvVars := map[string]string{
+		"LMS_SMS_BASE_URL":             "sms.base_url",
+		"LMS_SMS_USERNAME":             "sms.username",
+		"LMS_SMS_API_KEY":              "sms.api_key",
+		"LMS_SMS_SENDER_ID":            "sms.sender_id",
+		"LMS_SMS_CALLBACK_TOKEN":       "sms.callback_token",
+		"LMS_SMS_DEFAULT_COUNTRY_CODE": "sms.default_country_code",
+	}
+	for env, key := range smsEnvVars {
+		if value := os.Getenv(env); value != "" {
+			viper.Set(key, value)
+		}
+	}
 
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
@@ -133,13 +343,57 @@ func Load() (*Config, error) {
 // GetEmailConfig creates a models.EmailConfig from the main config
 func (c *Config) GetEmailConfig() *models.EmailConfig {
 	return &models.EmailConfig{
-		SMTPHost:     c.Email.SMTPHost,
-		SMTPPort:     c.Email.SMTPPort,
-		SMTPUsername: c.Email.SMTPUsername,
-		SMTPPassword: c.Email.SMTPPassword,
-		FromEmail:    c.Email.FromEmail,
-		FromName:     c.Email.FromName,
-		UseTLS:       c.Email.UseTLS,
-		UseSSL:       c.Email.UseSSL,
+		SMTPHost:           c.Email.SMTPHost,
+		SMTPPort:           c.Email.SMTPPort,
+		SMTPUsername:       c.Email.SMTPUsername,
+		SMTPPassword:       c.Email.SMTPPassword,
+		FromEmail:          c.Email.FromEmail,
+		FromName:           c.Email.FromName,
+		UseTLS:             c.Email.UseTLS,
+		UseSSL:             c.Email.UseSSL,
+		BounceAddress:      c.Email.BounceAddress,
+		BounceSecret:       c.Email.BounceSecret,
+		DKIMDomain:         c.Email.DKIMDomain,
+		DKIMSelector:       c.Email.DKIMSelector,
+		DKIMPrivateKeyPath: c.Email.DKIMPrivateKeyPath,
+		LogoPath:           c.Email.LogoPath,
+	}
+}
+
+// GetEmailBounceConfig creates a models.EmailBounceConfig from the main config
+func (c *Config) GetEmailBounceConfig() *models.EmailBounceConfig {
+	return &models.EmailBounceConfig{
+		BounceAddress:       c.Email.BounceAddress,
+		BounceSecret:        c.Email.BounceSecret,
+		WebhookToken:        c.EmailBounces.WebhookToken,
+		Maildir:             c.EmailBounces.Maildir,
+		HardBounceThreshold: c.EmailBounces.HardBounceThreshold,
+	}
+}
+
+// GetMpesaConfig creates a models.MpesaConfig from the main config
+func (c *Config) GetMpesaConfig() *models.MpesaConfig {
+	return &models.MpesaConfig{
+		BaseURL:        c.Mpesa.BaseURL,
+		ConsumerKey:    c.Mpesa.ConsumerKey,
+		ConsumerSecret: c.Mpesa.ConsumerSecret,
+		ShortCode:      c.Mpesa.ShortCode,
+		PassKey:        c.Mpesa.PassKey,
+		CallbackURL:    c.Mpesa.CallbackURL,
+		CallbackToken:  c.Mpesa.CallbackToken,
+		TimeoutSeconds: c.Mpesa.TimeoutSeconds,
+	}
+}
+
+// GetSMSConfig creates a models.SMSConfig from the main config
+func (c *Config) GetSMSConfig() *models.SMSConfig {
+	return &models.SMSConfig{
+		BaseURL:            c.SMS.BaseURL,
+		Username:           c.SMS.Username,
+		APIKey:             c.SMS.APIKey,
+		SenderID:           c.SMS.SenderID,
+		CallbackToken:      c.SMS.CallbackToken,
+		TimeoutSeconds:     c.SMS.TimeoutSeconds,
+		DefaultCountryCode: c.SMS.DefaultCountryCode,
 	}
 }
@@ -125,4 +125,38 @@ func TestConfigDefaults(t *testing.T) {
 	if cfg.JWT.ExpiryHours != 24 {
 		t.Errorf("Expected default JWT expiry 24 hours, got %d", cfg.JWT.ExpiryHours)
 	}
+
+	if cfg.Fines.BlockThreshold != 10.0 || cfg.Fines.BlockOverdueDays != 30 {
+		t.Errorf("Expected accounts blocked above 10.00 owed or 30 days overdue, got %v and %d", cfg.Fines.BlockThreshold, cfg.Fines.BlockOverdueDays)
+	}
+
+	if !cfg.Recalls.AutoRecall || cfg.Recalls.NoticeDays != 3 {
+		t.Errorf("Expected automatic recalls with 3 days notice, got %v and %d", cfg.Recalls.AutoRecall, cfg.Recalls.NoticeDays)
+	}
+
+	if cfg.Holds.PickupDays != 3 {
+		t.Errorf("Expected held books kept for 3 days, got %d", cfg.Holds.PickupDays)
+	}
+	if cfg.Holds.MaxSuspensionDays != 30 {
+		t.Errorf("Expected reservations suspendable for up to 30 days, got %d", cfg.Holds.MaxSuspensionDays)
+	}
+
+	if cfg.EmailQueue.Workers != 2 || cfg.EmailQueue.BatchSize != 10 || cfg.EmailQueue.PollIntervalSeconds != 5 {
+		t.Errorf("Expected 2 email workers taking 10 emails every 5s, got %+v", cfg.EmailQueue)
+	}
+	if cfg.EmailQueue.StuckAfterMinutes != 15 || cfg.EmailQueue.MaxAttempts != 3 {
+		t.Errorf("Expected stuck emails reset after 15 minutes and 3 attempts, got %+v", cfg.EmailQueue)
+	}
+	if cfg.EmailBounces.HardBounceThreshold != 3 {
+		t.Errorf("Expected addresses suppressed after 3 hard bounces, got %d", cfg.EmailBounces.HardBounceThreshold)
+	}
+	if !cfg.Jobs.Enabled || cfg.Jobs.NotificationRetentionDays != 30 {
+		t.Errorf("Expected scheduled jobs enabled keeping notifications 30 days, got %v and %d", cfg.Jobs.Enabled, cfg.Jobs.NotificationRetentionDays)
+	}
+	if cfg.Jobs.Schedules.DueSoonReminders != "0 8 * * *" || cfg.Jobs.Schedules.ExpireReservations != "*/15 * * * *" {
+		t.Errorf("Expected default job schedules, got %+v", cfg.Jobs.Schedules)
+	}
+	if cfg.Jobs.Schedules.FineAccrual != "0 1 * * *" {
+		t.Errorf("Expected fines accrued nightly at 01:00, got %q", cfg.Jobs.Schedules.FineAccrual)
+	}
 }
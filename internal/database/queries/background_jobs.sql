@@ -0,0 +1,36 @@
+-- name: ClaimBackgroundJob :one
+-- Takes the run lease unless another instance holds one that has not expired.
+-- Returns no rows when the job is already running elsewhere.
+INSERT INTO background_jobs (name, locked_by, locked_until, last_status, last_started_at)
+VALUES ($1, $2, $3, 'running', NOW())
+ON CONFLICT (name) DO UPDATE
+SET locked_by = EXCLUDED.locked_by, locked_until = EXCLUDED.locked_until,
+    last_status = 'running', last_started_at = NOW(), updated_at = NOW()
+WHERE background_jobs.locked_until IS NULL OR background_jobs.locked_until < NOW()
+RETURNING *;
+
+-- name: FinishBackgroundJob :one
+-- Releases the lease and records the outcome. A holder whose lease was taken over records nothing.
+UPDATE background_jobs
+SET locked_by = NULL, locked_until = NULL, last_status = $3, last_error = $4, last_result = $5,
+    last_success_at = CASE WHEN $3 = 'succeeded' THEN NOW() ELSE last_success_at END,
+    last_finished_at = NOW(), updated_at = NOW()
+WHERE name = $1 AND locked_by = $2
+RETURNING *;
+
+-- name: GetBackgroundJob :one
+SELECT * FROM background_jobs
+WHERE name = $1;
+
+-- name: ListBackgroundJobs :many
+SELECT * FROM background_jobs
+ORDER BY name;
+
+-- name: SetBackgroundJobPaused :one
+-- Pauses or resumes a job's schedule, creating the job's row if it has never run.
+-- A NULL paused_at resumes it.
+INSERT INTO background_jobs (name, paused_at, paused_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (name) DO UPDATE
+SET paused_at = EXCLUDED.paused_at, paused_by = EXCLUDED.paused_by, updated_at = NOW()
+RETURNING *;
@@ -0,0 +1,61 @@
+-- name: CreateBookCopy :one
+INSERT INTO book_copies (book_id, barcode, condition, shelf_location, status, notes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetBookCopyByID :one
+SELECT * FROM book_copies
+WHERE id = $1;
+
+-- name: GetBookCopyByIDForUpdate :one
+SELECT * FROM book_copies
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetBookCopyByBarcode :one
+SELECT * FROM book_copies
+WHERE barcode = $1;
+
+-- name: GetBookCopyByBarcodeForUpdate :one
+SELECT * FROM book_copies
+WHERE barcode = $1
+FOR UPDATE;
+
+-- name: GetAvailableBookCopyForUpdate :one
+SELECT * FROM book_copies
+WHERE book_id = $1 AND status = 'available'
+ORDER BY id
+LIMIT 1
+FOR UPDATE;
+
+-- name: ListBookCopiesByBook :many
+SELECT * FROM book_copies
+WHERE book_id = $1
+ORDER BY barcode;
+
+-- name: CountBookCopiesByBook :one
+SELECT COUNT(*) FROM book_copies
+WHERE book_id = $1;
+
+-- name: UpdateBookCopy :one
+UPDATE book_copies
+SET condition = $2, shelf_location = $3, status = $4, notes = $5, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateBookCopyStatus :exec
+UPDATE book_copies
+SET status = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateBookCopyCondition :exec
+UPDATE book_copies
+SET condition = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SyncBookCopyCounts :exec
+UPDATE books
+SET total_copies = (SELECT COUNT(*) FROM book_copies c WHERE c.book_id = books.id AND c.status NOT IN ('lost', 'withdrawn')),
+    available_copies = (SELECT COUNT(*) FROM book_copies c WHERE c.book_id = books.id AND c.status = 'available'),
+    updated_at = NOW()
+WHERE id = $1;
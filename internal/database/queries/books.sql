@@ -7,6 +7,11 @@ RETURNING *;
 SELECT * FROM books
 WHERE id = $1 AND deleted_at IS NULL;
 
+-- name: GetBookByIDForUpdate :one
+SELECT * FROM books
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
 -- name: GetBookByBookID :one
 SELECT * FROM books
 WHERE book_id = $1 AND deleted_at IS NULL;
@@ -17,7 +22,7 @@ WHERE isbn = $1 AND deleted_at IS NULL;
 
 -- name: UpdateBook :one
 UPDATE books
-SET book_id = $2, isbn = $3, title = $4, author = $5, publisher = $6, published_year = $7, genre = $8, description = $9, cover_image_url = $10, total_copies = $11, available_copies = $12, shelf_location = $13, updated_at = NOW()
+SET book_id = $2, isbn = $3, title = $4, author = $5, publisher = $6, published_year = $7, genre = $8, description = $9, cover_image_url = $10, shelf_location = $11, updated_at = NOW()
 WHERE id = $1 AND deleted_at IS NULL
 RETURNING *;
 
@@ -26,6 +31,11 @@ UPDATE books
 SET available_copies = $2, updated_at = NOW()
 WHERE id = $1;
 
+-- name: UpdateBookCopyTotals :exec
+UPDATE books
+SET total_copies = $2, available_copies = $3, updated_at = NOW()
+WHERE id = $1;
+
 -- name: UpdateBookCondition :exec
 UPDATE books
 SET condition = $2, updated_at = NOW()
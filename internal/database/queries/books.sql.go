@@ -157,6 +157,38 @@ func (q *Queries) GetBookByID(ctx context.Context, id int32) (Book, error) {
 	return i, err
 }
 
+const getBookByIDForUpdate = `-- name: GetBookByIDForUpdate :one
+SELECT id, book_id, isbn, title, author, publisher, published_year, genre, description, cover_image_url, total_copies, available_copies, shelf_location, is_active, deleted_at, created_at, updated_at, condition FROM books
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE
+`
+
+func (q *Queries) GetBookByIDForUpdate(ctx context.Context, id int32) (Book, error) {
+	row := q.db.QueryRow(ctx, getBookByIDForUpdate, id)
+	var i Book
+	err := row.Scan(
+		&i.ID,
+		&i.BookID,
+		&i.Isbn,
+		&i.Title,
+		&i.Author,
+		&i.Publisher,
+		&i.PublishedYear,
+		&i.Genre,
+		&i.Description,
+		&i.CoverImageUrl,
+		&i.TotalCopies,
+		&i.AvailableCopies,
+		&i.ShelfLocation,
+		&i.IsActive,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Condition,
+	)
+	return i, err
+}
+
 const getBookByISBN = `-- name: GetBookByISBN :one
 SELECT id, book_id, isbn, title, author, publisher, published_year, genre, description, cover_image_url, total_copies, available_copies, shelf_location, is_active, deleted_at, created_at, updated_at, condition FROM books
 WHERE isbn = $1 AND deleted_at IS NULL
@@ -408,25 +440,23 @@ func (q *Queries) SoftDeleteBook(ctx context.Context, id int32) error {
 
 const updateBook = `-- name: UpdateBook :one
 UPDATE books
-SET book_id = $2, isbn = $3, title = $4, author = $5, publisher = $6, published_year = $7, genre = $8, description = $9, cover_image_url = $10, total_copies = $11, available_copies = $12, shelf_location = $13, updated_at = NOW()
+SET book_id = $2, isbn = $3, title = $4, author = $5, publisher = $6, published_year = $7, genre = $8, description = $9, cover_image_url = $10, shelf_location = $11, updated_at = NOW()
 WHERE id = $1 AND deleted_at IS NULL
 RETURNING id, book_id, isbn, title, author, publisher, published_year, genre, description, cover_image_url, total_copies, available_copies, shelf_location, is_active, deleted_at, created_at, updated_at, condition
 `
 
 type UpdateBookParams struct {
-	ID              int32       `db:"id" json:"id"`
-	BookID          string      `db:"book_id" json:"book_id"`
-	Isbn            pgtype.Text `db:"isbn" json:"isbn"`
-	Title           string      `db:"title" json:"title"`
-	Author          string      `db:"author" json:"author"`
-	Publisher       pgtype.Text `db:"publisher" json:"publisher"`
-	PublishedYear   pgtype.Int4 `db:"published_year" json:"published_year"`
-	Genre           pgtype.Text `db:"genre" json:"genre"`
-	Description     pgtype.Text `db:"description" json:"description"`
-	CoverImageUrl   pgtype.Text `db:"cover_image_url" json:"cover_image_url"`
-	TotalCopies     pgtype.Int4 `db:"total_copies" json:"total_copies"`
-	AvailableCopies pgtype.Int4 `db:"available_copies" json:"available_copies"`
-	ShelfLocation   pgtype.Text `db:"shelf_location" json:"shelf_location"`
+	ID            int32       `db:"id" json:"id"`
+	BookID        string      `db:"book_id" json:"book_id"`
+	Isbn          pgtype.Text `db:"isbn" json:"isbn"`
+	Title         string      `db:"title" json:"title"`
+	Author        string      `db:"author" json:"author"`
+	Publisher     pgtype.Text `db:"publisher" json:"publisher"`
+	PublishedYear pgtype.Int4 `db:"published_year" json:"published_year"`
+	Genre         pgtype.Text `db:"genre" json:"genre"`
+	Description   pgtype.Text `db:"description" json:"description"`
+	CoverImageUrl pgtype.Text `db:"cover_image_url" json:"cover_image_url"`
+	ShelfLocation pgtype.Text `db:"shelf_location" json:"shelf_location"`
 }
 
 func (q *Queries) UpdateBook(ctx context.Context, arg UpdateBookParams) (Book, error) {
@@ -441,8 +471,6 @@ func (q *Queries) UpdateBook(ctx context.Context, arg UpdateBookParams) (Book, e
 		arg.Genre,
 		arg.Description,
 		arg.CoverImageUrl,
-		arg.TotalCopies,
-		arg.AvailableCopies,
 		arg.ShelfLocation,
 	)
 	var i Book
@@ -500,3 +528,20 @@ func (q *Queries) UpdateBookCondition(ctx context.Context, arg UpdateBookConditi
 	_, err := q.db.Exec(ctx, updateBookCondition, arg.ID, arg.Condition)
 	return err
 }
+
+const updateBookCopyTotals = `-- name: UpdateBookCopyTotals :exec
+UPDATE books
+SET total_copies = $2, available_copies = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateBookCopyTotalsParams struct {
+	ID              int32       `db:"id" json:"id"`
+	TotalCopies     pgtype.Int4 `db:"total_copies" json:"total_copies"`
+	AvailableCopies pgtype.Int4 `db:"available_copies" json:"available_copies"`
+}
+
+func (q *Queries) UpdateBookCopyTotals(ctx context.Context, arg UpdateBookCopyTotalsParams) error {
+	_, err := q.db.Exec(ctx, updateBookCopyTotals, arg.ID, arg.TotalCopies, arg.AvailableCopies)
+	return err
+}
@@ -0,0 +1,45 @@
+-- name: CreateCirculationPolicy :one
+INSERT INTO circulation_policies (
+    name, description, year_of_study, department, user_type, item_type,
+    loan_days, max_loans, max_renewals, fine_per_day, grace_period_days,
+    max_reservations, reservation_days, priority, is_active,
+    replacement_cost, processing_fee, damage_charge_per_step, damage_step_threshold,
+    recall_loan_days, recall_fine_per_day, reservation_priority
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22
+) RETURNING *;
+
+-- name: GetCirculationPolicyByID :one
+SELECT * FROM circulation_policies
+WHERE id = $1;
+
+-- name: ListCirculationPolicies :many
+SELECT * FROM circulation_policies
+ORDER BY priority DESC, id;
+
+-- name: UpdateCirculationPolicy :one
+UPDATE circulation_policies
+SET name = $2, description = $3, year_of_study = $4, department = $5, user_type = $6, item_type = $7,
+    loan_days = $8, max_loans = $9, max_renewals = $10, fine_per_day = $11, grace_period_days = $12,
+    max_reservations = $13, reservation_days = $14, priority = $15, is_active = $16,
+    replacement_cost = $17, processing_fee = $18, damage_charge_per_step = $19, damage_step_threshold = $20,
+    recall_loan_days = $21, recall_fine_per_day = $22, reservation_priority = $23, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteCirculationPolicy :exec
+DELETE FROM circulation_policies
+WHERE id = $1;
+
+-- name: ResolveCirculationPolicy :one
+SELECT * FROM circulation_policies
+WHERE is_active = true
+  AND (year_of_study IS NULL OR year_of_study = $1::int)
+  AND (department IS NULL OR LOWER(department) = LOWER($2::text))
+  AND (user_type IS NULL OR user_type = $3::text)
+  AND (item_type IS NULL OR LOWER(item_type) = LOWER($4::text))
+ORDER BY priority DESC,
+    ((year_of_study IS NOT NULL)::int + (department IS NOT NULL)::int +
+     (user_type IS NOT NULL)::int + (item_type IS NOT NULL)::int) DESC,
+    id
+LIMIT 1;
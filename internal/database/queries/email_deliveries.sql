@@ -117,4 +117,20 @@ FROM email_deliveries ed
 JOIN notifications n ON ed.notification_id = n.id
 WHERE ed.email_address = $1
 ORDER BY ed.created_at DESC
-LIMIT $2 OFFSET $3;
\ No newline at end of file
+LIMIT $2 OFFSET $3;
+
+-- name: GetEmailDeliveryByProviderMessageID :one
+SELECT * FROM email_deliveries
+WHERE provider_message_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: MarkEmailDeliveryBounced :one
+UPDATE email_deliveries 
+SET 
+    status = 'bounced',
+    error_message = $2,
+    failed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
@@ -188,6 +188,35 @@ func (q *Queries) GetEmailDelivery(ctx context.Context, id int32) (EmailDelivery
 	return i, err
 }
 
+const getEmailDeliveryByProviderMessageID = `-- name: GetEmailDeliveryByProviderMessageID :one
+SELECT id, notification_id, email_address, status, sent_at, delivered_at, failed_at, error_message, retry_count, max_retries, provider_message_id, delivery_metadata, created_at, updated_at FROM email_deliveries
+WHERE provider_message_id = $1
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetEmailDeliveryByProviderMessageID(ctx context.Context, providerMessageID pgtype.Text) (EmailDelivery, error) {
+	row := q.db.QueryRow(ctx, getEmailDeliveryByProviderMessageID, providerMessageID)
+	var i EmailDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.NotificationID,
+		&i.EmailAddress,
+		&i.Status,
+		&i.SentAt,
+		&i.DeliveredAt,
+		&i.FailedAt,
+		&i.ErrorMessage,
+		&i.RetryCount,
+		&i.MaxRetries,
+		&i.ProviderMessageID,
+		&i.DeliveryMetadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getEmailDeliveryHistory = `-- name: GetEmailDeliveryHistory :many
 SELECT 
     ed.id, ed.notification_id, ed.email_address, ed.status, ed.sent_at, ed.delivered_at, ed.failed_at, ed.error_message, ed.retry_count, ed.max_retries, ed.provider_message_id, ed.delivery_metadata, ed.created_at, ed.updated_at,
@@ -389,6 +418,44 @@ func (q *Queries) GetPendingEmailDeliveries(ctx context.Context, limit int32) ([
 	return items, nil
 }
 
+const markEmailDeliveryBounced = `-- name: MarkEmailDeliveryBounced :one
+UPDATE email_deliveries 
+SET 
+    status = 'bounced',
+    error_message = $2,
+    failed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, notification_id, email_address, status, sent_at, delivered_at, failed_at, error_message, retry_count, max_retries, provider_message_id, delivery_metadata, created_at, updated_at
+`
+
+type MarkEmailDeliveryBouncedParams struct {
+	ID           int32       `db:"id" json:"id"`
+	ErrorMessage pgtype.Text `db:"error_message" json:"error_message"`
+}
+
+func (q *Queries) MarkEmailDeliveryBounced(ctx context.Context, arg MarkEmailDeliveryBouncedParams) (EmailDelivery, error) {
+	row := q.db.QueryRow(ctx, markEmailDeliveryBounced, arg.ID, arg.ErrorMessage)
+	var i EmailDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.NotificationID,
+		&i.EmailAddress,
+		&i.Status,
+		&i.SentAt,
+		&i.DeliveredAt,
+		&i.FailedAt,
+		&i.ErrorMessage,
+		&i.RetryCount,
+		&i.MaxRetries,
+		&i.ProviderMessageID,
+		&i.DeliveryMetadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateEmailDeliveryError = `-- name: UpdateEmailDeliveryError :one
 UPDATE email_deliveries 
 SET 
@@ -73,11 +73,13 @@ WHERE id = $1
 RETURNING *;
 
 -- name: UpdateQueueItemError :one
+-- Items with attempts left are retried after a delay that doubles with each attempt
 UPDATE email_queue 
 SET 
     status = CASE WHEN attempts + 1 >= max_attempts THEN 'failed' ELSE 'pending' END,
     error_message = $2,
     attempts = attempts + 1,
+    scheduled_for = NOW() + INTERVAL '1 minute' * POWER(2, attempts),
     processing_completed_at = NOW(),
     worker_id = NULL,
     updated_at = NOW()
@@ -100,7 +102,7 @@ SET
     processing_completed_at = NOW(),
     worker_id = NULL,
     updated_at = NOW()
-WHERE id = $1
+WHERE id = $1 AND status = 'pending'
 RETURNING *;
 
 -- name: ResetStuckQueueItems :exec
@@ -133,4 +135,47 @@ WHERE created_at < $1 AND status IN ('completed', 'failed', 'cancelled');
 -- name: GetQueueItemsByNotification :many
 SELECT * FROM email_queue 
 WHERE notification_id = $1 
-ORDER BY created_at DESC;
\ No newline at end of file
+ORDER BY created_at DESC;
+
+-- name: ClaimNextQueueItems :many
+-- Takes the next due items for a worker. Rows another worker is claiming are
+-- skipped, so two workers never send the same email.
+UPDATE email_queue
+SET
+    status = 'processing',
+    processing_started_at = NOW(),
+    worker_id = $1,
+    updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM email_queue
+    WHERE status = 'pending'
+    AND scheduled_for <= NOW()
+    ORDER BY priority ASC, scheduled_for ASC
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ReleaseQueueItem :exec
+-- Puts back an item a stopping worker claimed but did not get to
+UPDATE email_queue
+SET
+    status = 'pending',
+    worker_id = NULL,
+    processing_started_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'processing';
+
+-- name: RetryQueueItem :one
+-- Gives a failed or cancelled item a fresh set of attempts
+UPDATE email_queue
+SET
+    status = 'pending',
+    attempts = 0,
+    scheduled_for = NOW(),
+    worker_id = NULL,
+    processing_started_at = NULL,
+    processing_completed_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('failed', 'cancelled')
+RETURNING *;
@@ -18,7 +18,7 @@ SET
     processing_completed_at = NOW(),
     worker_id = NULL,
     updated_at = NOW()
-WHERE id = $1
+WHERE id = $1 AND status = 'pending'
 RETURNING id, notification_id, priority, scheduled_for, attempts, max_attempts, status, error_message, processing_started_at, processing_completed_at, worker_id, queue_metadata, created_at, updated_at
 `
 
@@ -44,6 +44,67 @@ func (q *Queries) CancelQueueItem(ctx context.Context, id int32) (EmailQueue, er
 	return i, err
 }
 
+const claimNextQueueItems = `-- name: ClaimNextQueueItems :many
+
+UPDATE email_queue
+SET
+    status = 'processing',
+    processing_started_at = NOW(),
+    worker_id = $1,
+    updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM email_queue
+    WHERE status = 'pending'
+    AND scheduled_for <= NOW()
+    ORDER BY priority ASC, scheduled_for ASC
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, notification_id, priority, scheduled_for, attempts, max_attempts, status, error_message, processing_started_at, processing_completed_at, worker_id, queue_metadata, created_at, updated_at
+`
+
+type ClaimNextQueueItemsParams struct {
+	WorkerID pgtype.Text `db:"worker_id" json:"worker_id"`
+	Limit    int32       `db:"limit" json:"limit"`
+}
+
+// Takes the next due items for a worker. Rows another worker is claiming are
+// skipped, so two workers never send the same email.
+func (q *Queries) ClaimNextQueueItems(ctx context.Context, arg ClaimNextQueueItemsParams) ([]EmailQueue, error) {
+	rows, err := q.db.Query(ctx, claimNextQueueItems, arg.WorkerID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []EmailQueue{}
+	for rows.Next() {
+		var i EmailQueue
+		if err := rows.Scan(
+			&i.ID,
+			&i.NotificationID,
+			&i.Priority,
+			&i.ScheduledFor,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.Status,
+			&i.ErrorMessage,
+			&i.ProcessingStartedAt,
+			&i.ProcessingCompletedAt,
+			&i.WorkerID,
+			&i.QueueMetadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const completeQueueItem = `-- name: CompleteQueueItem :one
 UPDATE email_queue 
 SET 
@@ -382,6 +443,23 @@ func (q *Queries) GetQueueStats(ctx context.Context, arg GetQueueStatsParams) (G
 	return i, err
 }
 
+const releaseQueueItem = `-- name: ReleaseQueueItem :exec
+
+UPDATE email_queue
+SET
+    status = 'pending',
+    worker_id = NULL,
+    processing_started_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'processing'
+`
+
+// Puts back an item a stopping worker claimed but did not get to
+func (q *Queries) ReleaseQueueItem(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, releaseQueueItem, id)
+	return err
+}
+
 const resetStuckQueueItems = `-- name: ResetStuckQueueItems :exec
 UPDATE email_queue 
 SET 
@@ -398,12 +476,52 @@ func (q *Queries) ResetStuckQueueItems(ctx context.Context, processingStartedAt
 	return err
 }
 
+const retryQueueItem = `-- name: RetryQueueItem :one
+
+UPDATE email_queue
+SET
+    status = 'pending',
+    attempts = 0,
+    scheduled_for = NOW(),
+    worker_id = NULL,
+    processing_started_at = NULL,
+    processing_completed_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status IN ('failed', 'cancelled')
+RETURNING id, notification_id, priority, scheduled_for, attempts, max_attempts, status, error_message, processing_started_at, processing_completed_at, worker_id, queue_metadata, created_at, updated_at
+`
+
+// Gives a failed or cancelled item a fresh set of attempts
+func (q *Queries) RetryQueueItem(ctx context.Context, id int32) (EmailQueue, error) {
+	row := q.db.QueryRow(ctx, retryQueueItem, id)
+	var i EmailQueue
+	err := row.Scan(
+		&i.ID,
+		&i.NotificationID,
+		&i.Priority,
+		&i.ScheduledFor,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.Status,
+		&i.ErrorMessage,
+		&i.ProcessingStartedAt,
+		&i.ProcessingCompletedAt,
+		&i.WorkerID,
+		&i.QueueMetadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateQueueItemError = `-- name: UpdateQueueItemError :one
+
 UPDATE email_queue 
 SET 
     status = CASE WHEN attempts + 1 >= max_attempts THEN 'failed' ELSE 'pending' END,
     error_message = $2,
     attempts = attempts + 1,
+    scheduled_for = NOW() + INTERVAL '1 minute' * POWER(2, attempts),
     processing_completed_at = NOW(),
     worker_id = NULL,
     updated_at = NOW()
@@ -416,6 +534,7 @@ type UpdateQueueItemErrorParams struct {
 	ErrorMessage pgtype.Text `db:"error_message" json:"error_message"`
 }
 
+// Items with attempts left are retried after a delay that doubles with each attempt
 func (q *Queries) UpdateQueueItemError(ctx context.Context, arg UpdateQueueItemErrorParams) (EmailQueue, error) {
 	row := q.db.QueryRow(ctx, updateQueueItemError, arg.ID, arg.ErrorMessage)
 	var i EmailQueue
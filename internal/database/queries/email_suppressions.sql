@@ -0,0 +1,59 @@
+-- Counts a hard bounce against an address. The address is suppressed when its
+-- count reaches the threshold; once suppressed it stays so until the suppression is cleared.
+-- name: RecordEmailHardBounce :one
+INSERT INTO email_suppressions (
+    email_address,
+    hard_bounce_count,
+    last_bounced_at,
+    last_bounce_status,
+    last_bounce_reason,
+    suppressed_at
+) VALUES (
+    LOWER($1), 1, NOW(), $2, $3, CASE WHEN $4::int <= 1 THEN NOW() END
+)
+ON CONFLICT (email_address) DO UPDATE SET
+    hard_bounce_count = email_suppressions.hard_bounce_count + 1,
+    last_bounced_at = NOW(),
+    last_bounce_status = EXCLUDED.last_bounce_status,
+    last_bounce_reason = EXCLUDED.last_bounce_reason,
+    suppressed_at = COALESCE(email_suppressions.suppressed_at,
+        CASE WHEN email_suppressions.hard_bounce_count + 1 >= $4::int THEN NOW() END),
+    updated_at = NOW()
+RETURNING *;
+
+-- name: IsEmailSuppressed :one
+SELECT EXISTS (
+    SELECT 1 FROM email_suppressions
+    WHERE email_address = LOWER($1) AND suppressed_at IS NOT NULL
+) AS suppressed;
+
+-- name: ClearEmailSuppression :execrows
+DELETE FROM email_suppressions WHERE email_address = LOWER($1);
+
+-- Students whose email address on file is suppressed. A student drops off the
+-- list as soon as their address is changed.
+-- name: ListStudentsWithSuppressedEmail :many
+SELECT 
+    s.id,
+    s.student_id,
+    s.first_name,
+    s.last_name,
+    s.email,
+    s.phone,
+    s.department,
+    s.year_of_study,
+    es.hard_bounce_count,
+    es.last_bounced_at,
+    es.last_bounce_status,
+    es.last_bounce_reason,
+    es.suppressed_at
+FROM students s
+JOIN email_suppressions es ON es.email_address = LOWER(s.email)
+WHERE es.suppressed_at IS NOT NULL AND s.deleted_at IS NULL
+ORDER BY es.suppressed_at DESC, s.id
+LIMIT $1 OFFSET $2;
+
+-- name: CountStudentsWithSuppressedEmail :one
+SELECT COUNT(*) FROM students s
+JOIN email_suppressions es ON es.email_address = LOWER(s.email)
+WHERE es.suppressed_at IS NOT NULL AND s.deleted_at IS NULL;
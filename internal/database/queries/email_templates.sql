@@ -0,0 +1,66 @@
+-- name: CreateEmailTemplate :one
+-- Returns no rows when the template already exists in the locale
+INSERT INTO email_templates (name, locale, description, created_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (name, locale) DO NOTHING
+RETURNING *;
+
+-- name: GetEmailTemplateByName :one
+SELECT * FROM email_templates
+WHERE name = $1 AND locale = $2;
+
+-- name: ListEmailTemplates :many
+SELECT * FROM email_templates
+ORDER BY name, locale;
+
+-- name: NextEmailTemplateVersion :one
+-- Hands out the template's next version number. The row stays locked until the
+-- transaction ends, so concurrent edits are numbered one after the other.
+UPDATE email_templates
+SET latest_version = latest_version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetEmailTemplatePublishedVersion :one
+UPDATE email_templates
+SET published_version = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteEmailTemplate :exec
+DELETE FROM email_templates
+WHERE id = $1;
+
+-- name: CreateEmailTemplateVersion :one
+INSERT INTO email_template_versions (template_id, version, subject, body, is_html, variables, change_note, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetEmailTemplateVersion :one
+SELECT * FROM email_template_versions
+WHERE template_id = $1 AND version = $2;
+
+-- name: ListEmailTemplateVersions :many
+SELECT * FROM email_template_versions
+WHERE template_id = $1
+ORDER BY version DESC;
+
+-- name: ListPublishedEmailTemplateVersions :many
+SELECT * FROM email_template_versions
+WHERE status = 'published';
+
+-- name: GetPublishedEmailTemplateVersion :one
+SELECT v.* FROM email_template_versions v
+JOIN email_templates t ON t.id = v.template_id
+WHERE t.name = $1 AND t.locale = $2 AND v.status = 'published';
+
+-- name: ArchivePublishedEmailTemplateVersion :exec
+UPDATE email_template_versions
+SET status = 'archived'
+WHERE template_id = $1 AND status = 'published';
+
+-- name: PublishEmailTemplateVersion :one
+UPDATE email_template_versions
+SET status = 'published', published_at = NOW()
+WHERE id = $1
+RETURNING *;
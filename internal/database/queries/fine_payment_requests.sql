@@ -0,0 +1,28 @@
+-- name: CreateFinePaymentRequest :one
+INSERT INTO fine_payment_requests (
+    student_id, provider, phone, amount, requested_by
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetFinePaymentRequestByID :one
+SELECT * FROM fine_payment_requests
+WHERE id = $1;
+
+-- name: GetFinePaymentRequestByCheckoutIDForUpdate :one
+SELECT * FROM fine_payment_requests
+WHERE checkout_request_id = $1
+FOR UPDATE;
+
+-- name: SetFinePaymentRequestCheckout :one
+UPDATE fine_payment_requests
+SET merchant_request_id = $2, checkout_request_id = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: CompleteFinePaymentRequest :one
+UPDATE fine_payment_requests
+SET status = $2, amount_applied = $3, provider_receipt = $4, result_code = $5, result_desc = $6,
+    updated_at = NOW(), completed_at = NOW()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,68 @@
+-- name: CreateFine :one
+INSERT INTO fines (
+    student_id, transaction_id, fine_type, description, amount, created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetFineByID :one
+SELECT * FROM fines
+WHERE id = $1;
+
+-- name: GetFineByIDForUpdate :one
+SELECT * FROM fines
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListFinesByStudent :many
+SELECT * FROM fines
+WHERE student_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: ListFinesByTransaction :many
+SELECT * FROM fines
+WHERE transaction_id = $1
+ORDER BY id;
+
+-- name: UpdateFineTotals :one
+UPDATE fines
+SET amount = $2, amount_paid = $3, amount_waived = $4, status = $5, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetStudentFineBalance :one
+SELECT COALESCE(SUM(amount - amount_paid - amount_waived), 0)::numeric AS balance,
+       COUNT(*) FILTER (WHERE status IN ('outstanding', 'partially_paid')) AS outstanding_fines
+FROM fines
+WHERE student_id = $1;
+
+-- name: NextFineReceiptNumber :one
+SELECT nextval('fine_receipt_seq')::bigint AS receipt_seq;
+
+-- name: CreateFineLedgerEntry :one
+INSERT INTO fine_ledger_entries (
+    fine_id, student_id, entry_type, amount, payment_method, reference, reason, receipt_number, recorded_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: ListFineLedgerEntriesByFine :many
+SELECT * FROM fine_ledger_entries
+WHERE fine_id = $1
+ORDER BY created_at, id;
+
+-- name: GetFineLedgerEntryByReceipt :one
+SELECT * FROM fine_ledger_entries
+WHERE receipt_number = $1;
+
+-- name: ListFineLedgerEntriesBetween :many
+SELECT e.*, u.username AS recorded_by_username
+FROM fine_ledger_entries e
+LEFT JOIN users u ON e.recorded_by = u.id
+WHERE e.created_at >= $1 AND e.created_at < $2
+ORDER BY e.created_at, e.id;
+
+-- name: ListOutstandingFinesByStudent :many
+SELECT * FROM fines
+WHERE student_id = $1 AND status IN ('outstanding', 'partially_paid')
+ORDER BY created_at, id;
@@ -0,0 +1,25 @@
+-- name: CreateJobRun :one
+INSERT INTO job_runs (job_name, trigger_type, triggered_by, instance_id)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: FinishJobRun :one
+UPDATE job_runs
+SET status = $2, error = $3, finished_at = $4, duration_ms = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: ListJobRuns :many
+SELECT * FROM job_runs
+WHERE job_name = $1
+ORDER BY started_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ListLatestJobRuns :many
+-- The most recent run of each job
+SELECT * FROM job_runs
+WHERE id IN (
+    SELECT DISTINCT ON (job_name) id FROM job_runs
+    ORDER BY job_name, started_at DESC, id DESC
+)
+ORDER BY job_name;
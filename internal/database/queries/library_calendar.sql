@@ -0,0 +1,46 @@
+-- name: ListOpeningHours :many
+SELECT * FROM library_opening_hours
+ORDER BY day_of_week;
+
+-- name: UpsertOpeningHours :one
+INSERT INTO library_opening_hours (
+    day_of_week, opens_at, closes_at, is_closed
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (day_of_week) DO UPDATE
+SET opens_at = EXCLUDED.opens_at, closes_at = EXCLUDED.closes_at,
+    is_closed = EXCLUDED.is_closed, updated_at = NOW()
+RETURNING *;
+
+-- name: CreateLibraryClosure :one
+INSERT INTO library_closures (
+    name, description, start_date, end_date, recurs_annually
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetLibraryClosureByID :one
+SELECT * FROM library_closures
+WHERE id = $1;
+
+-- name: ListLibraryClosures :many
+SELECT * FROM library_closures
+ORDER BY recurs_annually DESC, start_date, id;
+
+-- name: ListLibraryClosuresBetween :many
+SELECT * FROM library_closures
+WHERE recurs_annually = true
+   OR (end_date >= $1 AND start_date <= $2)
+ORDER BY start_date, id;
+
+-- name: UpdateLibraryClosure :one
+UPDATE library_closures
+SET name = $2, description = $3, start_date = $4, end_date = $5,
+    recurs_annually = $6, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteLibraryClosure :exec
+DELETE FROM library_closures
+WHERE id = $1;
@@ -24,6 +24,27 @@ type AuditLog struct {
 	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
 }
 
+// Run lease and last run outcome for each background job
+type BackgroundJob struct {
+	Name string `db:"name" json:"name"`
+	// Server instance holding the run lease
+	LockedBy pgtype.Text `db:"locked_by" json:"locked_by"`
+	// When the lease expires if the holder never finishes
+	LockedUntil    pgtype.Timestamp `db:"locked_until" json:"locked_until"`
+	LastStatus     pgtype.Text      `db:"last_status" json:"last_status"`
+	LastStartedAt  pgtype.Timestamp `db:"last_started_at" json:"last_started_at"`
+	LastFinishedAt pgtype.Timestamp `db:"last_finished_at" json:"last_finished_at"`
+	LastSuccessAt  pgtype.Timestamp `db:"last_success_at" json:"last_success_at"`
+	LastError      pgtype.Text      `db:"last_error" json:"last_error"`
+	// Job specific summary of the last run
+	LastResult []byte           `db:"last_result" json:"last_result"`
+	CreatedAt  pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt  pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	// When the job schedule was paused; NULL while it runs on schedule
+	PausedAt pgtype.Timestamp `db:"paused_at" json:"paused_at"`
+	PausedBy pgtype.Int4      `db:"paused_by" json:"paused_by"`
+}
+
 type Book struct {
 	ID              int32            `db:"id" json:"id"`
 	BookID          string           `db:"book_id" json:"book_id"`
@@ -45,6 +66,60 @@ type Book struct {
 	Condition       pgtype.Text      `db:"condition" json:"condition"`
 }
 
+// Physical copies of library titles tracked by barcode
+type BookCopy struct {
+	ID     int32 `db:"id" json:"id"`
+	BookID int32 `db:"book_id" json:"book_id"`
+	// Barcode printed on the physical copy
+	Barcode       string      `db:"barcode" json:"barcode"`
+	Condition     pgtype.Text `db:"condition" json:"condition"`
+	ShelfLocation pgtype.Text `db:"shelf_location" json:"shelf_location"`
+	// Copy status: available, borrowed, on_hold, maintenance, lost, withdrawn
+	Status    pgtype.Text      `db:"status" json:"status"`
+	Notes     pgtype.Text      `db:"notes" json:"notes"`
+	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+}
+
+// Loan, renewal, fine and reservation rules by patron class and item type
+type CirculationPolicy struct {
+	ID          int32       `db:"id" json:"id"`
+	Name        string      `db:"name" json:"name"`
+	Description pgtype.Text `db:"description" json:"description"`
+	YearOfStudy pgtype.Int4 `db:"year_of_study" json:"year_of_study"`
+	Department  pgtype.Text `db:"department" json:"department"`
+	UserType    pgtype.Text `db:"user_type" json:"user_type"`
+	// Book genre the rule applies to; NULL matches any item
+	ItemType    pgtype.Text    `db:"item_type" json:"item_type"`
+	LoanDays    int32          `db:"loan_days" json:"loan_days"`
+	MaxLoans    int32          `db:"max_loans" json:"max_loans"`
+	MaxRenewals int32          `db:"max_renewals" json:"max_renewals"`
+	FinePerDay  pgtype.Numeric `db:"fine_per_day" json:"fine_per_day"`
+	// Overdue days that are not fined
+	GracePeriodDays int32 `db:"grace_period_days" json:"grace_period_days"`
+	MaxReservations int32 `db:"max_reservations" json:"max_reservations"`
+	ReservationDays int32 `db:"reservation_days" json:"reservation_days"`
+	// Higher priority rules win before specificity is considered
+	Priority  int32            `db:"priority" json:"priority"`
+	IsActive  pgtype.Bool      `db:"is_active" json:"is_active"`
+	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	// Charged when a borrowed copy is declared lost or claimed returned
+	ReplacementCost pgtype.Numeric `db:"replacement_cost" json:"replacement_cost"`
+	// Charged with the replacement cost
+	ProcessingFee pgtype.Numeric `db:"processing_fee" json:"processing_fee"`
+	// Charged per condition step lost on return once the threshold is reached
+	DamageChargePerStep pgtype.Numeric `db:"damage_charge_per_step" json:"damage_charge_per_step"`
+	// Condition steps a copy can drop on return before a damage charge applies
+	DamageStepThreshold int32 `db:"damage_step_threshold" json:"damage_step_threshold"`
+	// Days a borrower keeps a recalled book, counted from the loan date
+	RecallLoanDays int32 `db:"recall_loan_days" json:"recall_loan_days"`
+	// Fine per day charged instead of fine_per_day when a recalled book is late
+	RecallFinePerDay pgtype.Numeric `db:"recall_fine_per_day" json:"recall_fine_per_day"`
+	// Queue priority given to reservations placed under this rule; higher goes first
+	ReservationPriority int32 `db:"reservation_priority" json:"reservation_priority"`
+}
+
 // Tracks email delivery status and attempts for notifications
 type EmailDelivery struct {
 	ID             int32  `db:"id" json:"id"`
@@ -89,6 +164,149 @@ type EmailQueue struct {
 	UpdatedAt     pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 }
 
+// Hard bounces counted against each email address, and whether email to it is suppressed
+type EmailSuppression struct {
+	ID int32 `db:"id" json:"id"`
+	// Address in lower case
+	EmailAddress    string           `db:"email_address" json:"email_address"`
+	HardBounceCount int32            `db:"hard_bounce_count" json:"hard_bounce_count"`
+	LastBouncedAt   pgtype.Timestamp `db:"last_bounced_at" json:"last_bounced_at"`
+	// Enhanced status code of the last bounce, such as 5.1.1
+	LastBounceStatus pgtype.Text `db:"last_bounce_status" json:"last_bounce_status"`
+	// Diagnostic the receiving server gave for the last bounce
+	LastBounceReason pgtype.Text `db:"last_bounce_reason" json:"last_bounce_reason"`
+	// When the address was flagged invalid; NULL while email is still sent to it
+	SuppressedAt pgtype.Timestamp `db:"suppressed_at" json:"suppressed_at"`
+	CreatedAt    pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+}
+
+// Email templates; the content lives in email_template_versions
+type EmailTemplate struct {
+	ID          int32       `db:"id" json:"id"`
+	Name        string      `db:"name" json:"name"`
+	Description pgtype.Text `db:"description" json:"description"`
+	// Highest version number given out, used to number the next version
+	LatestVersion int32 `db:"latest_version" json:"latest_version"`
+	// Version sent to recipients; NULL until a version is published
+	PublishedVersion pgtype.Int4      `db:"published_version" json:"published_version"`
+	CreatedBy        pgtype.Int4      `db:"created_by" json:"created_by"`
+	CreatedAt        pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt        pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	// Language of the template; each name has one template per locale
+	Locale string `db:"locale" json:"locale"`
+}
+
+// Every saved version of an email template
+type EmailTemplateVersion struct {
+	ID         int32    `db:"id" json:"id"`
+	TemplateID int32    `db:"template_id" json:"template_id"`
+	Version    int32    `db:"version" json:"version"`
+	Subject    string   `db:"subject" json:"subject"`
+	Body       string   `db:"body" json:"body"`
+	IsHtml     bool     `db:"is_html" json:"is_html"`
+	Variables  []string `db:"variables" json:"variables"`
+	// Version status: draft, published, archived
+	Status      string           `db:"status" json:"status"`
+	ChangeNote  pgtype.Text      `db:"change_note" json:"change_note"`
+	CreatedBy   pgtype.Int4      `db:"created_by" json:"created_by"`
+	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
+	PublishedAt pgtype.Timestamp `db:"published_at" json:"published_at"`
+}
+
+// Charges owed by students; balance is amount - amount_paid - amount_waived
+type Fine struct {
+	ID            int32            `db:"id" json:"id"`
+	StudentID     int32            `db:"student_id" json:"student_id"`
+	TransactionID pgtype.Int4      `db:"transaction_id" json:"transaction_id"`
+	FineType      string           `db:"fine_type" json:"fine_type"`
+	Description   pgtype.Text      `db:"description" json:"description"`
+	Amount        pgtype.Numeric   `db:"amount" json:"amount"`
+	AmountPaid    pgtype.Numeric   `db:"amount_paid" json:"amount_paid"`
+	AmountWaived  pgtype.Numeric   `db:"amount_waived" json:"amount_waived"`
+	Status        string           `db:"status" json:"status"`
+	CreatedBy     pgtype.Int4      `db:"created_by" json:"created_by"`
+	CreatedAt     pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+}
+
+// Immutable record of payments, waivers, refunds and adjustments against fines
+type FineLedgerEntry struct {
+	ID        int32  `db:"id" json:"id"`
+	FineID    int32  `db:"fine_id" json:"fine_id"`
+	StudentID int32  `db:"student_id" json:"student_id"`
+	EntryType string `db:"entry_type" json:"entry_type"`
+	// Positive for payments, waivers and refunds; signed change to the fine amount for adjustments
+	Amount        pgtype.Numeric `db:"amount" json:"amount"`
+	PaymentMethod pgtype.Text    `db:"payment_method" json:"payment_method"`
+	Reference     pgtype.Text    `db:"reference" json:"reference"`
+	Reason        pgtype.Text    `db:"reason" json:"reason"`
+	// Receipt issued for payments and refunds
+	ReceiptNumber pgtype.Text      `db:"receipt_number" json:"receipt_number"`
+	RecordedBy    pgtype.Int4      `db:"recorded_by" json:"recorded_by"`
+	CreatedAt     pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
+// Mobile money payment requests for student fines, settled by the provider callback
+type FinePaymentRequest struct {
+	ID        int32          `db:"id" json:"id"`
+	StudentID int32          `db:"student_id" json:"student_id"`
+	Provider  string         `db:"provider" json:"provider"`
+	Phone     string         `db:"phone" json:"phone"`
+	Amount    pgtype.Numeric `db:"amount" json:"amount"`
+	// Part of the amount paid that was applied to fines; the provider charges whole shillings
+	AmountApplied     pgtype.Numeric `db:"amount_applied" json:"amount_applied"`
+	Status            string         `db:"status" json:"status"`
+	MerchantRequestID pgtype.Text    `db:"merchant_request_id" json:"merchant_request_id"`
+	CheckoutRequestID pgtype.Text    `db:"checkout_request_id" json:"checkout_request_id"`
+	// Provider transaction receipt, e.g. the M-Pesa receipt number
+	ProviderReceipt pgtype.Text      `db:"provider_receipt" json:"provider_receipt"`
+	ResultCode      pgtype.Int4      `db:"result_code" json:"result_code"`
+	ResultDesc      pgtype.Text      `db:"result_desc" json:"result_desc"`
+	RequestedBy     pgtype.Int4      `db:"requested_by" json:"requested_by"`
+	CreatedAt       pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	CompletedAt     pgtype.Timestamp `db:"completed_at" json:"completed_at"`
+}
+
+// History of scheduled job runs
+type JobRun struct {
+	ID      int32  `db:"id" json:"id"`
+	JobName string `db:"job_name" json:"job_name"`
+	// Whether the run was fired by the schedule or triggered by an admin
+	TriggerType string      `db:"trigger_type" json:"trigger_type"`
+	TriggeredBy pgtype.Int4 `db:"triggered_by" json:"triggered_by"`
+	// Server instance that ran the job
+	InstanceID string           `db:"instance_id" json:"instance_id"`
+	Status     string           `db:"status" json:"status"`
+	StartedAt  pgtype.Timestamp `db:"started_at" json:"started_at"`
+	FinishedAt pgtype.Timestamp `db:"finished_at" json:"finished_at"`
+	DurationMs pgtype.Int8      `db:"duration_ms" json:"duration_ms"`
+	Error      pgtype.Text      `db:"error" json:"error"`
+}
+
+// Holidays and breaks when the library is closed
+type LibraryClosure struct {
+	ID          int32       `db:"id" json:"id"`
+	Name        string      `db:"name" json:"name"`
+	Description pgtype.Text `db:"description" json:"description"`
+	StartDate   pgtype.Date `db:"start_date" json:"start_date"`
+	EndDate     pgtype.Date `db:"end_date" json:"end_date"`
+	// Closed on the same month and day every year; the year of the dates is ignored and the range may wrap over the new year
+	RecursAnnually bool             `db:"recurs_annually" json:"recurs_annually"`
+	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+}
+
+// Regular opening hours; day_of_week 0 is Sunday
+type LibraryOpeningHour struct {
+	DayOfWeek int32            `db:"day_of_week" json:"day_of_week"`
+	OpensAt   pgtype.Time      `db:"opens_at" json:"opens_at"`
+	ClosesAt  pgtype.Time      `db:"closes_at" json:"closes_at"`
+	IsClosed  bool             `db:"is_closed" json:"is_closed"`
+	UpdatedAt pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+}
+
 type Notification struct {
 	ID            int32            `db:"id" json:"id"`
 	RecipientID   int32            `db:"recipient_id" json:"recipient_id"`
@@ -99,6 +317,68 @@ type Notification struct {
 	IsRead        pgtype.Bool      `db:"is_read" json:"is_read"`
 	SentAt        pgtype.Timestamp `db:"sent_at" json:"sent_at"`
 	CreatedAt     pgtype.Timestamp `db:"created_at" json:"created_at"`
+	// Language the notification was written in
+	Locale string `db:"locale" json:"locale"`
+	// Values the email template is rendered with, already formatted for the locale
+	TemplateData []byte `db:"template_data" json:"template_data"`
+	// Channels the notification is delivered on: email, sms
+	Channels []string `db:"channels" json:"channels"`
+	// Held for the recipient's digest email instead of being sent on its own
+	Digest bool `db:"digest" json:"digest"`
+}
+
+// Announcements sent to a segment of students
+type NotificationBroadcast struct {
+	ID       int32    `db:"id" json:"id"`
+	Title    string   `db:"title" json:"title"`
+	Message  string   `db:"message" json:"message"`
+	Priority string   `db:"priority" json:"priority"`
+	Channels []string `db:"channels" json:"channels"`
+	// Students targeted, as {"year_of_study": 2, "department": "Physics", "active_borrowers": true, "with_overdue_loans": true}; an empty segment is every active student
+	Segment      []byte           `db:"segment" json:"segment"`
+	ScheduledFor pgtype.Timestamp `db:"scheduled_for" json:"scheduled_for"`
+	// Librarian who sent the broadcast
+	CreatedBy pgtype.Int4      `db:"created_by" json:"created_by"`
+	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
+// Each student a broadcast was sent to
+type NotificationBroadcastRecipient struct {
+	ID          int32 `db:"id" json:"id"`
+	BroadcastID int32 `db:"broadcast_id" json:"broadcast_id"`
+	StudentID   int32 `db:"student_id" json:"student_id"`
+	// Notification created for the student; NULL if it could not be created
+	NotificationID pgtype.Int4 `db:"notification_id" json:"notification_id"`
+	// Why the notification could not be created
+	ErrorMessage pgtype.Text      `db:"error_message" json:"error_message"`
+	CreatedAt    pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
+// Channels each notification has reached its recipient on
+type NotificationChannelDelivery struct {
+	NotificationID int32 `db:"notification_id" json:"notification_id"`
+	// Channel the notification was delivered on: email, sms
+	Channel     string           `db:"channel" json:"channel"`
+	DeliveredAt pgtype.Timestamp `db:"delivered_at" json:"delivered_at"`
+}
+
+// How each student and librarian wants to receive notifications
+type NotificationPreference struct {
+	ID            int32  `db:"id" json:"id"`
+	RecipientID   int32  `db:"recipient_id" json:"recipient_id"`
+	RecipientType string `db:"recipient_type" json:"recipient_type"`
+	// Channels enabled per notification type, as {"due_soon": ["email"]}; types not listed use every channel
+	Channels []byte `db:"channels" json:"channels"`
+	// Start of the daily window in which notifications are held back, in library time
+	QuietHoursStart pgtype.Time `db:"quiet_hours_start" json:"quiet_hours_start"`
+	// End of the quiet hours window; may be earlier than the start to span midnight
+	QuietHoursEnd pgtype.Time `db:"quiet_hours_end" json:"quiet_hours_end"`
+	// Routine notifications are collected into a digest: off, daily, weekly
+	DigestMode string `db:"digest_mode" json:"digest_mode"`
+	// When the recipient was last sent a digest
+	LastDigestAt pgtype.Timestamp `db:"last_digest_at" json:"last_digest_at"`
+	CreatedAt    pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 }
 
 type Reservation struct {
@@ -111,6 +391,44 @@ type Reservation struct {
 	FulfilledAt pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
 	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
 	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	// Copy set aside on the hold shelf for a ready reservation
+	CopyID pgtype.Int4 `db:"copy_id" json:"copy_id"`
+	// When the reserved book was set aside for the student
+	ReadyAt pgtype.Timestamp `db:"ready_at" json:"ready_at"`
+	// Last day to collect a ready reservation before it passes to the next student
+	PickupDeadline pgtype.Timestamp `db:"pickup_deadline" json:"pickup_deadline"`
+	// The queue passes over the reservation until this time; it keeps its place
+	SuspendedUntil pgtype.Timestamp `db:"suspended_until" json:"suspended_until"`
+	// Queue priority from the circulation policy when the reservation was placed; higher goes first
+	Priority int32 `db:"priority" json:"priority"`
+	// Orders reservations of the same priority; moved when a librarian reorders the queue
+	QueuedAt pgtype.Timestamp `db:"queued_at" json:"queued_at"`
+}
+
+// Tracks SMS delivery status for notifications
+type SmsDelivery struct {
+	ID             int32 `db:"id" json:"id"`
+	NotificationID int32 `db:"notification_id" json:"notification_id"`
+	// Recipient phone number in E.164 form
+	PhoneNumber string `db:"phone_number" json:"phone_number"`
+	Message     string `db:"message" json:"message"`
+	// Number of SMS segments the message is billed as
+	Segments int32 `db:"segments" json:"segments"`
+	// Message encoding: gsm7, or ucs2 when the text needs it
+	Encoding string `db:"encoding" json:"encoding"`
+	Provider string `db:"provider" json:"provider"`
+	// SMS delivery status: pending, sent, delivered, failed
+	Status string `db:"status" json:"status"`
+	// Message ID from the SMS provider, used to match delivery reports
+	ProviderMessageID pgtype.Text `db:"provider_message_id" json:"provider_message_id"`
+	// Cost reported by the SMS provider, with its currency
+	Cost         pgtype.Text      `db:"cost" json:"cost"`
+	ErrorMessage pgtype.Text      `db:"error_message" json:"error_message"`
+	SentAt       pgtype.Timestamp `db:"sent_at" json:"sent_at"`
+	DeliveredAt  pgtype.Timestamp `db:"delivered_at" json:"delivered_at"`
+	FailedAt     pgtype.Timestamp `db:"failed_at" json:"failed_at"`
+	CreatedAt    pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 }
 
 type Student struct {
@@ -128,6 +446,23 @@ type Student struct {
 	DeletedAt      pgtype.Timestamp `db:"deleted_at" json:"deleted_at"`
 	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
 	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	// Language notifications are written in: en, sw
+	Locale string `db:"locale" json:"locale"`
+}
+
+type StudentBlock struct {
+	ID        int32  `db:"id" json:"id"`
+	StudentID int32  `db:"student_id" json:"student_id"`
+	Reason    string `db:"reason" json:"reason"`
+	// The block ends on its own at this time; NULL blocks until lifted
+	ExpiresAt pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	CreatedBy pgtype.Int4      `db:"created_by" json:"created_by"`
+	LiftedAt  pgtype.Timestamp `db:"lifted_at" json:"lifted_at"`
+	LiftedBy  pgtype.Int4      `db:"lifted_by" json:"lifted_by"`
+	// Why the block was lifted early
+	LiftReason pgtype.Text      `db:"lift_reason" json:"lift_reason"`
+	CreatedAt  pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt  pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 }
 
 type Transaction struct {
@@ -146,6 +481,15 @@ type Transaction struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
 	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	// lost or claimed_returned while the copy is missing; found once it is recovered
+	LossStatus     pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	// Set when the loan is recalled for another student; recalled loans cannot be renewed
+	RecalledAt pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	// Due date before the recall brought it forward
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
 }
 
 type User struct {
@@ -159,4 +503,6 @@ type User struct {
 	DeletedAt    pgtype.Timestamp `db:"deleted_at" json:"deleted_at"`
 	CreatedAt    pgtype.Timestamp `db:"created_at" json:"created_at"`
 	UpdatedAt    pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	// Language notifications are written in: en, sw
+	Locale string `db:"locale" json:"locale"`
 }
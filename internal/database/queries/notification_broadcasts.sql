@@ -0,0 +1,68 @@
+-- Active students in a broadcast segment; a zero year, empty department or
+-- false flag leaves that filter out. A renewed loan is overdue only by its
+-- latest renewal
+-- name: ListBroadcastStudents :many
+SELECT s.id, s.first_name, s.last_name FROM students s
+WHERE s.is_active = true AND s.deleted_at IS NULL
+  AND ($1::int = 0 OR s.year_of_study = $1::int)
+  AND ($2::text = '' OR LOWER(s.department) = LOWER($2::text))
+  AND (NOT $3::boolean OR EXISTS (
+      SELECT 1 FROM transactions t
+      WHERE t.student_id = s.id AND t.returned_date IS NULL
+  ))
+  AND (NOT $4::boolean OR EXISTS (
+      SELECT 1 FROM transactions t
+      WHERE t.student_id = s.id AND t.returned_date IS NULL AND t.due_date < NOW()
+        AND NOT EXISTS (
+          SELECT 1 FROM transactions r
+          WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+            AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+        )
+  ))
+ORDER BY s.id;
+
+-- name: CreateNotificationBroadcast :one
+INSERT INTO notification_broadcasts (title, message, priority, channels, segment, scheduled_for, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetNotificationBroadcast :one
+SELECT * FROM notification_broadcasts
+WHERE id = $1;
+
+-- name: ListNotificationBroadcasts :many
+SELECT b.*, (SELECT COUNT(*) FROM notification_broadcast_recipients r WHERE r.broadcast_id = b.id) AS recipient_count
+FROM notification_broadcasts b
+ORDER BY b.created_at DESC, b.id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountNotificationBroadcasts :one
+SELECT COUNT(*) FROM notification_broadcasts;
+
+-- name: CreateBroadcastRecipient :exec
+INSERT INTO notification_broadcast_recipients (broadcast_id, student_id, notification_id, error_message)
+VALUES ($1, $2, $3, $4);
+
+-- Each recipient's delivery status: failed if the notification could not be
+-- created or its email ran out of attempts, sent once delivered, otherwise pending
+-- name: ListBroadcastRecipients :many
+SELECT r.student_id, s.first_name, s.last_name, r.notification_id, n.sent_at,
+    CASE
+        WHEN r.notification_id IS NULL THEN 'failed'
+        WHEN n.sent_at IS NOT NULL THEN 'sent'
+        WHEN EXISTS (
+            SELECT 1 FROM email_queue q
+            WHERE q.notification_id = r.notification_id AND q.status = 'failed' AND q.attempts >= q.max_attempts
+        ) THEN 'failed'
+        ELSE 'pending'
+    END::text AS status,
+    COALESCE(r.error_message, (
+        SELECT q.error_message FROM email_queue q
+        WHERE q.notification_id = r.notification_id AND q.status = 'failed'
+        ORDER BY q.updated_at DESC LIMIT 1
+    ), '')::text AS error_message
+FROM notification_broadcast_recipients r
+JOIN students s ON s.id = r.student_id
+LEFT JOIN notifications n ON n.id = r.notification_id
+WHERE r.broadcast_id = $1
+ORDER BY s.last_name, s.first_name, r.student_id;
@@ -0,0 +1,44 @@
+-- Notification Preferences Queries
+
+-- name: GetNotificationPreferences :one
+SELECT * FROM notification_preferences
+WHERE recipient_id = $1 AND recipient_type = $2;
+
+-- name: UpsertNotificationPreferences :one
+INSERT INTO notification_preferences (
+    recipient_id,
+    recipient_type,
+    channels,
+    quiet_hours_start,
+    quiet_hours_end,
+    digest_mode
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (recipient_id, recipient_type) DO UPDATE SET
+    channels = EXCLUDED.channels,
+    quiet_hours_start = EXCLUDED.quiet_hours_start,
+    quiet_hours_end = EXCLUDED.quiet_hours_end,
+    digest_mode = EXCLUDED.digest_mode,
+    updated_at = NOW()
+RETURNING *;
+
+-- Recipients on the given digest mode with notifications waiting for a digest.
+-- Recipients who have since turned digests off are included so the
+-- notifications held for them still go out.
+-- name: ListDigestRecipients :many
+SELECT * FROM notification_preferences
+WHERE (digest_mode = $1 OR digest_mode = 'off')
+  AND EXISTS (
+      SELECT 1 FROM notifications n
+      WHERE n.recipient_id = notification_preferences.recipient_id
+        AND n.recipient_type = notification_preferences.recipient_type
+        AND n.digest = true
+        AND n.sent_at IS NULL
+  )
+ORDER BY id;
+
+-- name: MarkDigestSent :exec
+UPDATE notification_preferences
+SET last_digest_at = NOW()
+WHERE recipient_id = $1 AND recipient_type = $2;
@@ -1,6 +1,6 @@
 -- name: CreateNotification :one
-INSERT INTO notifications (recipient_id, recipient_type, type, title, message)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO notifications (recipient_id, recipient_type, type, title, message, locale, template_data, channels, digest)
+VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8::text[], '{email}'), $9)
 RETURNING *;
 
 -- name: GetNotificationByID :one
@@ -42,10 +42,16 @@ LIMIT $2 OFFSET $3;
 
 -- name: ListUnsentNotifications :many
 SELECT * FROM notifications
-WHERE sent_at IS NULL
+WHERE sent_at IS NULL AND digest = false
 ORDER BY created_at ASC
 LIMIT $1;
 
+-- Notifications held for the recipient's next digest email
+-- name: ListPendingDigestNotifications :many
+SELECT * FROM notifications
+WHERE recipient_id = $1 AND recipient_type = $2 AND digest = true AND sent_at IS NULL
+ORDER BY created_at ASC;
+
 -- name: CountUnreadNotificationsByRecipient :one
 SELECT COUNT(*) FROM notifications
 WHERE recipient_id = $1 AND recipient_type = $2 AND is_read = false;
@@ -60,4 +66,14 @@ WHERE id = $1;
 
 -- name: DeleteOldNotifications :exec
 DELETE FROM notifications
-WHERE created_at < $1 AND is_read = true;
\ No newline at end of file
+WHERE created_at < $1 AND is_read = true;
+
+-- name: ListDeliveredNotificationChannels :many
+-- The channels a notification has already reached its recipient on
+SELECT channel FROM notification_channel_deliveries
+WHERE notification_id = $1;
+
+-- name: RecordNotificationChannelDelivered :exec
+INSERT INTO notification_channel_deliveries (notification_id, channel)
+VALUES ($1, $2)
+ON CONFLICT (notification_id, channel) DO NOTHING;
@@ -41,17 +41,21 @@ func (q *Queries) CountUnreadNotificationsByRecipient(ctx context.Context, arg C
 }
 
 const createNotification = `-- name: CreateNotification :one
-INSERT INTO notifications (recipient_id, recipient_type, type, title, message)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at
+INSERT INTO notifications (recipient_id, recipient_type, type, title, message, locale, template_data, channels, digest)
+VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8::text[], '{email}'), $9)
+RETURNING id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at, locale, template_data, channels, digest
 `
 
 type CreateNotificationParams struct {
-	RecipientID   int32  `db:"recipient_id" json:"recipient_id"`
-	RecipientType string `db:"recipient_type" json:"recipient_type"`
-	Type          string `db:"type" json:"type"`
-	Title         string `db:"title" json:"title"`
-	Message       string `db:"message" json:"message"`
+	RecipientID   int32    `db:"recipient_id" json:"recipient_id"`
+	RecipientType string   `db:"recipient_type" json:"recipient_type"`
+	Type          string   `db:"type" json:"type"`
+	Title         string   `db:"title" json:"title"`
+	Message       string   `db:"message" json:"message"`
+	Locale        string   `db:"locale" json:"locale"`
+	TemplateData  []byte   `db:"template_data" json:"template_data"`
+	Channels      []string `db:"channels" json:"channels"`
+	Digest        bool     `db:"digest" json:"digest"`
 }
 
 func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error) {
@@ -61,6 +65,10 @@ func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotification
 		arg.Type,
 		arg.Title,
 		arg.Message,
+		arg.Locale,
+		arg.TemplateData,
+		arg.Channels,
+		arg.Digest,
 	)
 	var i Notification
 	err := row.Scan(
@@ -73,6 +81,10 @@ func (q *Queries) CreateNotification(ctx context.Context, arg CreateNotification
 		&i.IsRead,
 		&i.SentAt,
 		&i.CreatedAt,
+		&i.Locale,
+		&i.TemplateData,
+		&i.Channels,
+		&i.Digest,
 	)
 	return i, err
 }
@@ -98,7 +110,7 @@ func (q *Queries) DeleteOldNotifications(ctx context.Context, createdAt pgtype.T
 }
 
 const getNotificationByID = `-- name: GetNotificationByID :one
-SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at FROM notifications
+SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at, locale, template_data, channels, digest FROM notifications
 WHERE id = $1
 `
 
@@ -115,12 +127,43 @@ func (q *Queries) GetNotificationByID(ctx context.Context, id int32) (Notificati
 		&i.IsRead,
 		&i.SentAt,
 		&i.CreatedAt,
+		&i.Locale,
+		&i.TemplateData,
+		&i.Channels,
+		&i.Digest,
 	)
 	return i, err
 }
 
+const listDeliveredNotificationChannels = `-- name: ListDeliveredNotificationChannels :many
+
+SELECT channel FROM notification_channel_deliveries
+WHERE notification_id = $1
+`
+
+// The channels a notification has already reached its recipient on
+func (q *Queries) ListDeliveredNotificationChannels(ctx context.Context, notificationID int32) ([]string, error) {
+	rows, err := q.db.Query(ctx, listDeliveredNotificationChannels, notificationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var channel string
+		if err := rows.Scan(&channel); err != nil {
+			return nil, err
+		}
+		items = append(items, channel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listNotifications = `-- name: ListNotifications :many
-SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at FROM notifications
+SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at, locale, template_data, channels, digest FROM notifications
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -149,6 +192,10 @@ func (q *Queries) ListNotifications(ctx context.Context, arg ListNotificationsPa
 			&i.IsRead,
 			&i.SentAt,
 			&i.CreatedAt,
+			&i.Locale,
+			&i.TemplateData,
+			&i.Channels,
+			&i.Digest,
 		); err != nil {
 			return nil, err
 		}
@@ -161,7 +208,7 @@ func (q *Queries) ListNotifications(ctx context.Context, arg ListNotificationsPa
 }
 
 const listNotificationsByRecipient = `-- name: ListNotificationsByRecipient :many
-SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at FROM notifications
+SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at, locale, template_data, channels, digest FROM notifications
 WHERE recipient_id = $1 AND recipient_type = $2
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $4
@@ -198,6 +245,10 @@ func (q *Queries) ListNotificationsByRecipient(ctx context.Context, arg ListNoti
 			&i.IsRead,
 			&i.SentAt,
 			&i.CreatedAt,
+			&i.Locale,
+			&i.TemplateData,
+			&i.Channels,
+			&i.Digest,
 		); err != nil {
 			return nil, err
 		}
@@ -210,7 +261,7 @@ func (q *Queries) ListNotificationsByRecipient(ctx context.Context, arg ListNoti
 }
 
 const listNotificationsByType = `-- name: ListNotificationsByType :many
-SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at FROM notifications
+SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at, locale, template_data, channels, digest FROM notifications
 WHERE type = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -241,6 +292,57 @@ func (q *Queries) ListNotificationsByType(ctx context.Context, arg ListNotificat
 			&i.IsRead,
 			&i.SentAt,
 			&i.CreatedAt,
+			&i.Locale,
+			&i.TemplateData,
+			&i.Channels,
+			&i.Digest,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingDigestNotifications = `-- name: ListPendingDigestNotifications :many
+
+SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at, locale, template_data, channels, digest FROM notifications
+WHERE recipient_id = $1 AND recipient_type = $2 AND digest = true AND sent_at IS NULL
+ORDER BY created_at ASC
+`
+
+type ListPendingDigestNotificationsParams struct {
+	RecipientID   int32  `db:"recipient_id" json:"recipient_id"`
+	RecipientType string `db:"recipient_type" json:"recipient_type"`
+}
+
+// Notifications held for the recipient's next digest email
+func (q *Queries) ListPendingDigestNotifications(ctx context.Context, arg ListPendingDigestNotificationsParams) ([]Notification, error) {
+	rows, err := q.db.Query(ctx, listPendingDigestNotifications, arg.RecipientID, arg.RecipientType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Notification{}
+	for rows.Next() {
+		var i Notification
+		if err := rows.Scan(
+			&i.ID,
+			&i.RecipientID,
+			&i.RecipientType,
+			&i.Type,
+			&i.Title,
+			&i.Message,
+			&i.IsRead,
+			&i.SentAt,
+			&i.CreatedAt,
+			&i.Locale,
+			&i.TemplateData,
+			&i.Channels,
+			&i.Digest,
 		); err != nil {
 			return nil, err
 		}
@@ -253,7 +355,7 @@ func (q *Queries) ListNotificationsByType(ctx context.Context, arg ListNotificat
 }
 
 const listUnreadNotificationsByRecipient = `-- name: ListUnreadNotificationsByRecipient :many
-SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at FROM notifications
+SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at, locale, template_data, channels, digest FROM notifications
 WHERE recipient_id = $1 AND recipient_type = $2 AND is_read = false
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $4
@@ -290,6 +392,10 @@ func (q *Queries) ListUnreadNotificationsByRecipient(ctx context.Context, arg Li
 			&i.IsRead,
 			&i.SentAt,
 			&i.CreatedAt,
+			&i.Locale,
+			&i.TemplateData,
+			&i.Channels,
+			&i.Digest,
 		); err != nil {
 			return nil, err
 		}
@@ -302,8 +408,8 @@ func (q *Queries) ListUnreadNotificationsByRecipient(ctx context.Context, arg Li
 }
 
 const listUnsentNotifications = `-- name: ListUnsentNotifications :many
-SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at FROM notifications
-WHERE sent_at IS NULL
+SELECT id, recipient_id, recipient_type, type, title, message, is_read, sent_at, created_at, locale, template_data, channels, digest FROM notifications
+WHERE sent_at IS NULL AND digest = false
 ORDER BY created_at ASC
 LIMIT $1
 `
@@ -327,6 +433,10 @@ func (q *Queries) ListUnsentNotifications(ctx context.Context, limit int32) ([]N
 			&i.IsRead,
 			&i.SentAt,
 			&i.CreatedAt,
+			&i.Locale,
+			&i.TemplateData,
+			&i.Channels,
+			&i.Digest,
 		); err != nil {
 			return nil, err
 		}
@@ -359,3 +469,19 @@ func (q *Queries) MarkNotificationAsSent(ctx context.Context, id int32) error {
 	_, err := q.db.Exec(ctx, markNotificationAsSent, id)
 	return err
 }
+
+const recordNotificationChannelDelivered = `-- name: RecordNotificationChannelDelivered :exec
+INSERT INTO notification_channel_deliveries (notification_id, channel)
+VALUES ($1, $2)
+ON CONFLICT (notification_id, channel) DO NOTHING
+`
+
+type RecordNotificationChannelDeliveredParams struct {
+	NotificationID int32  `db:"notification_id" json:"notification_id"`
+	Channel        string `db:"channel" json:"channel"`
+}
+
+func (q *Queries) RecordNotificationChannelDelivered(ctx context.Context, arg RecordNotificationChannelDeliveredParams) error {
+	_, err := q.db.Exec(ctx, recordNotificationChannelDelivered, arg.NotificationID, arg.Channel)
+	return err
+}
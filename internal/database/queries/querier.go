@@ -11,95 +11,192 @@ import (
 )
 
 type Querier interface {
+	// Sets the running fine on a loan that is still out. Loans returned in the meantime
+	// keep the fine charged at return, and an unchanged amount is not rewritten.
+	AccrueTransactionFine(ctx context.Context, arg AccrueTransactionFineParams) (int64, error)
+	ArchivePublishedEmailTemplateVersion(ctx context.Context, templateID int32) error
 	BulkUpdateStudentStatus(ctx context.Context, arg BulkUpdateStudentStatusParams) error
 	CancelQueueItem(ctx context.Context, id int32) (EmailQueue, error)
 	CancelReservation(ctx context.Context, id int32) (Reservation, error)
+	// Takes the run lease unless another instance holds one that has not expired.
+	// Returns no rows when the job is already running elsewhere.
+	ClaimBackgroundJob(ctx context.Context, arg ClaimBackgroundJobParams) (BackgroundJob, error)
+	// Takes the next due items for a worker. Rows another worker is claiming are
+	// skipped, so two workers never send the same email.
+	ClaimNextQueueItems(ctx context.Context, arg ClaimNextQueueItemsParams) ([]EmailQueue, error)
+	ClearEmailSuppression(ctx context.Context, lower string) (int64, error)
+	// Closes the rows a loan was renewed from once it has been closed on its
+	// current row, so no part of it is left open to accrue fines
+	CloseRenewedLoanTransactions(ctx context.Context, id int32) error
+	CloseTransactionAsMissing(ctx context.Context, arg CloseTransactionAsMissingParams) (Transaction, error)
+	CompleteFinePaymentRequest(ctx context.Context, arg CompleteFinePaymentRequestParams) (FinePaymentRequest, error)
 	CompleteQueueItem(ctx context.Context, id int32) (EmailQueue, error)
+	// Suspended reservations are not counted as waiting for the book
 	CountActiveReservationsByBook(ctx context.Context, bookID int32) (int64, error)
 	CountActiveReservationsByStudent(ctx context.Context, studentID int32) (int64, error)
 	CountAuditLogs(ctx context.Context) (int64, error)
 	CountAuditLogsByTable(ctx context.Context, tableName string) (int64, error)
 	CountAvailableBooks(ctx context.Context) (int64, error)
+	CountBookCopiesByBook(ctx context.Context, bookID int32) (int64, error)
 	CountBooks(ctx context.Context) (int64, error)
+	CountHoldShelf(ctx context.Context) (int64, error)
+	CountNotificationBroadcasts(ctx context.Context) (int64, error)
 	CountNotificationsByType(ctx context.Context, type_ string) (int64, error)
+	CountOpenRecallsByBook(ctx context.Context, bookID int32) (int64, error)
+	// Open loans past their due date, each counted once on its latest open row
 	CountOverdueTransactions(ctx context.Context) (int64, error)
 	// Renewal-related queries for Phase 6.7
 	CountRenewalsByStudentAndBook(ctx context.Context, arg CountRenewalsByStudentAndBookParams) (int64, error)
 	CountStudents(ctx context.Context) (int64, error)
 	CountStudentsByStatus(ctx context.Context, isActive pgtype.Bool) (int64, error)
 	CountStudentsByYear(ctx context.Context, yearOfStudy int32) (int64, error)
+	CountStudentsWithSuppressedEmail(ctx context.Context) (int64, error)
 	CountTransactions(ctx context.Context) (int64, error)
 	CountUnreadNotificationsByRecipient(ctx context.Context, arg CountUnreadNotificationsByRecipientParams) (int64, error)
 	CountUsers(ctx context.Context) (int64, error)
 	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) error
 	CreateBook(ctx context.Context, arg CreateBookParams) (Book, error)
+	CreateBookCopy(ctx context.Context, arg CreateBookCopyParams) (BookCopy, error)
+	CreateBroadcastRecipient(ctx context.Context, arg CreateBroadcastRecipientParams) error
+	CreateCirculationPolicy(ctx context.Context, arg CreateCirculationPolicyParams) (CirculationPolicy, error)
 	// Email Deliveries Queries
 	// Phase 7.4: Email Integration - Delivery Tracking
 	CreateEmailDelivery(ctx context.Context, arg CreateEmailDeliveryParams) (EmailDelivery, error)
 	// Email Queue Queries
 	// Phase 7.4: Email Integration - Queue Processing
 	CreateEmailQueueItem(ctx context.Context, arg CreateEmailQueueItemParams) (EmailQueue, error)
+	// Returns no rows when the template already exists in the locale
+	CreateEmailTemplate(ctx context.Context, arg CreateEmailTemplateParams) (EmailTemplate, error)
+	CreateEmailTemplateVersion(ctx context.Context, arg CreateEmailTemplateVersionParams) (EmailTemplateVersion, error)
+	CreateFine(ctx context.Context, arg CreateFineParams) (Fine, error)
+	CreateFineLedgerEntry(ctx context.Context, arg CreateFineLedgerEntryParams) (FineLedgerEntry, error)
+	CreateFinePaymentRequest(ctx context.Context, arg CreateFinePaymentRequestParams) (FinePaymentRequest, error)
+	CreateJobRun(ctx context.Context, arg CreateJobRunParams) (JobRun, error)
+	CreateLibraryClosure(ctx context.Context, arg CreateLibraryClosureParams) (LibraryClosure, error)
 	CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error)
+	CreateNotificationBroadcast(ctx context.Context, arg CreateNotificationBroadcastParams) (NotificationBroadcast, error)
 	CreateReservation(ctx context.Context, arg CreateReservationParams) (Reservation, error)
+	// SMS Deliveries Queries
+	CreateSmsDelivery(ctx context.Context, arg CreateSmsDeliveryParams) (SmsDelivery, error)
 	CreateStudent(ctx context.Context, arg CreateStudentParams) (Student, error)
+	CreateStudentBlock(ctx context.Context, arg CreateStudentBlockParams) (StudentBlock, error)
 	CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteCirculationPolicy(ctx context.Context, id int32) error
+	DeleteEmailTemplate(ctx context.Context, id int32) error
+	DeleteLibraryClosure(ctx context.Context, id int32) error
 	DeleteNotification(ctx context.Context, id int32) error
 	DeleteOldAuditLogs(ctx context.Context, createdAt pgtype.Timestamp) error
 	DeleteOldEmailDeliveries(ctx context.Context, createdAt pgtype.Timestamp) error
 	DeleteOldNotifications(ctx context.Context, createdAt pgtype.Timestamp) error
 	DeleteOldQueueItems(ctx context.Context, createdAt pgtype.Timestamp) error
+	DeleteOldSmsDeliveries(ctx context.Context, createdAt pgtype.Timestamp) error
+	ExpireHold(ctx context.Context, id int32) (Reservation, error)
+	// Releases the lease and records the outcome. A holder whose lease was taken over records nothing.
+	FinishBackgroundJob(ctx context.Context, arg FinishBackgroundJobParams) (BackgroundJob, error)
+	FinishJobRun(ctx context.Context, arg FinishJobRunParams) (JobRun, error)
+	// The current row of the open loan of a copy. A borrow that has been renewed
+	// stays open alongside its renewals, so the latest of them is the loan.
+	GetActiveTransactionByCopyID(ctx context.Context, copyID pgtype.Int4) (Transaction, error)
+	GetAvailableBookCopyForUpdate(ctx context.Context, bookID int32) (BookCopy, error)
+	GetBackgroundJob(ctx context.Context, name string) (BackgroundJob, error)
 	GetBookByBookID(ctx context.Context, bookID string) (Book, error)
 	GetBookByID(ctx context.Context, id int32) (Book, error)
+	GetBookByIDForUpdate(ctx context.Context, id int32) (Book, error)
 	GetBookByISBN(ctx context.Context, isbn pgtype.Text) (Book, error)
+	GetBookCopyByBarcode(ctx context.Context, barcode string) (BookCopy, error)
+	GetBookCopyByBarcodeForUpdate(ctx context.Context, barcode string) (BookCopy, error)
+	GetBookCopyByID(ctx context.Context, id int32) (BookCopy, error)
+	GetBookCopyByIDForUpdate(ctx context.Context, id int32) (BookCopy, error)
+	// How loans of a book returned since the given time ran. A loan is counted once,
+	// from its borrow: the loan period is the one it was lent for, renewals counts the
+	// times loans were renewed, and lateness is in days past the due date of the row
+	// the book came back on, negative when it came back early.
+	GetBookReturnHistory(ctx context.Context, arg GetBookReturnHistoryParams) (GetBookReturnHistoryRow, error)
 	GetBookUtilizationReport(ctx context.Context, arg GetBookUtilizationReportParams) ([]GetBookUtilizationReportRow, error)
 	GetBorrowingStatistics(ctx context.Context, arg GetBorrowingStatisticsParams) ([]GetBorrowingStatisticsRow, error)
 	GetBorrowingStatisticsByDepartment(ctx context.Context, arg GetBorrowingStatisticsByDepartmentParams) ([]GetBorrowingStatisticsByDepartmentRow, error)
 	GetBorrowingTrends(ctx context.Context, arg GetBorrowingTrendsParams) ([]GetBorrowingTrendsRow, error)
+	GetCirculationPolicyByID(ctx context.Context, id int32) (CirculationPolicy, error)
+	// The latest open row of the loan a transaction belongs to, which is the
+	// transaction itself unless the loan has been renewed since
+	GetCurrentLoanTransactionID(ctx context.Context, id int32) (int32, error)
 	GetDashboardMetrics(ctx context.Context) (GetDashboardMetricsRow, error)
 	GetEmailDeliveriesByNotification(ctx context.Context, notificationID int32) ([]EmailDelivery, error)
 	GetEmailDeliveriesByStatus(ctx context.Context, arg GetEmailDeliveriesByStatusParams) ([]EmailDelivery, error)
 	GetEmailDelivery(ctx context.Context, id int32) (EmailDelivery, error)
+	GetEmailDeliveryByProviderMessageID(ctx context.Context, providerMessageID pgtype.Text) (EmailDelivery, error)
 	GetEmailDeliveryHistory(ctx context.Context, arg GetEmailDeliveryHistoryParams) ([]GetEmailDeliveryHistoryRow, error)
 	GetEmailDeliveryStats(ctx context.Context, arg GetEmailDeliveryStatsParams) (GetEmailDeliveryStatsRow, error)
 	GetEmailQueueItem(ctx context.Context, id int32) (EmailQueue, error)
+	GetEmailTemplateByName(ctx context.Context, arg GetEmailTemplateByNameParams) (EmailTemplate, error)
+	GetEmailTemplateVersion(ctx context.Context, arg GetEmailTemplateVersionParams) (EmailTemplateVersion, error)
 	GetFailedEmailDeliveries(ctx context.Context, limit int32) ([]EmailDelivery, error)
+	GetFineByID(ctx context.Context, id int32) (Fine, error)
+	GetFineByIDForUpdate(ctx context.Context, id int32) (Fine, error)
+	GetFineLedgerEntryByReceipt(ctx context.Context, receiptNumber pgtype.Text) (FineLedgerEntry, error)
+	GetFinePaymentRequestByCheckoutIDForUpdate(ctx context.Context, checkoutRequestID pgtype.Text) (FinePaymentRequest, error)
+	GetFinePaymentRequestByID(ctx context.Context, id int32) (FinePaymentRequest, error)
 	GetFineStatistics(ctx context.Context, arg GetFineStatisticsParams) (GetFineStatisticsRow, error)
 	GetGenrePopularity(ctx context.Context, arg GetGenrePopularityParams) ([]GetGenrePopularityRow, error)
 	GetInventoryStatus(ctx context.Context) ([]GetInventoryStatusRow, error)
+	GetLibraryClosureByID(ctx context.Context, id int32) (LibraryClosure, error)
 	GetLibraryOverview(ctx context.Context) (GetLibraryOverviewRow, error)
+	// The same summary as GetBookReturnHistory across every book
+	GetLibraryReturnHistory(ctx context.Context, returnedDate pgtype.Timestamp) (GetLibraryReturnHistoryRow, error)
 	GetMonthlyTrends(ctx context.Context, arg GetMonthlyTrendsParams) ([]GetMonthlyTrendsRow, error)
 	GetNextQueueItems(ctx context.Context, limit int32) ([]EmailQueue, error)
 	GetNextReservationForBook(ctx context.Context, bookID int32) (GetNextReservationForBookRow, error)
+	GetNotificationBroadcast(ctx context.Context, id int32) (NotificationBroadcast, error)
 	GetNotificationByID(ctx context.Context, id int32) (Notification, error)
+	// Notification Preferences Queries
+	GetNotificationPreferences(ctx context.Context, arg GetNotificationPreferencesParams) (NotificationPreference, error)
 	GetOverdueBooksByYear(ctx context.Context, arg GetOverdueBooksByYearParams) ([]GetOverdueBooksByYearRow, error)
 	GetPendingEmailDeliveries(ctx context.Context, limit int32) ([]EmailDelivery, error)
 	GetPopularBooks(ctx context.Context, arg GetPopularBooksParams) ([]GetPopularBooksRow, error)
 	GetProcessingQueueItems(ctx context.Context, processingStartedAt pgtype.Timestamp) ([]EmailQueue, error)
+	GetPublishedEmailTemplateVersion(ctx context.Context, arg GetPublishedEmailTemplateVersionParams) (EmailTemplateVersion, error)
 	GetQueueItemsByNotification(ctx context.Context, notificationID int32) ([]EmailQueue, error)
 	GetQueueItemsByStatus(ctx context.Context, arg GetQueueItemsByStatusParams) ([]EmailQueue, error)
 	GetQueueStats(ctx context.Context, arg GetQueueStatsParams) (GetQueueStatsRow, error)
+	GetReadyReservationForStudent(ctx context.Context, arg GetReadyReservationForStudentParams) (Reservation, error)
+	// The oldest loan of the book that has not been recalled. A borrow that has been
+	// renewed stays open alongside its renewal, so only the renewal can be recalled.
+	GetRecallableLoanByBook(ctx context.Context, bookID int32) (int32, error)
 	GetRenewalStatisticsByStudent(ctx context.Context, studentID int32) (GetRenewalStatisticsByStudentRow, error)
 	GetReservationByID(ctx context.Context, id int32) (GetReservationByIDRow, error)
+	GetSmsDeliveriesByNotification(ctx context.Context, notificationID int32) ([]SmsDelivery, error)
+	GetSmsDelivery(ctx context.Context, id int32) (SmsDelivery, error)
 	GetStudentActivity(ctx context.Context, arg GetStudentActivityParams) ([]GetStudentActivityRow, error)
+	GetStudentBlockByIDForUpdate(ctx context.Context, id int32) (StudentBlock, error)
 	GetStudentByEmail(ctx context.Context, email pgtype.Text) (Student, error)
 	GetStudentByID(ctx context.Context, id int32) (Student, error)
+	GetStudentByIDForUpdate(ctx context.Context, id int32) (Student, error)
 	GetStudentByStudentID(ctx context.Context, studentID string) (Student, error)
 	GetStudentCountByYearAndDepartment(ctx context.Context) ([]GetStudentCountByYearAndDepartmentRow, error)
 	GetStudentEnrollmentTrends(ctx context.Context, arg GetStudentEnrollmentTrendsParams) ([]GetStudentEnrollmentTrendsRow, error)
+	GetStudentFineBalance(ctx context.Context, studentID int32) (GetStudentFineBalanceRow, error)
 	GetStudentReservationForBook(ctx context.Context, arg GetStudentReservationForBookParams) (GetStudentReservationForBookRow, error)
 	GetStudentsByStatus(ctx context.Context, arg GetStudentsByStatusParams) ([]Student, error)
 	GetTopBorrowingStudents(ctx context.Context, arg GetTopBorrowingStudentsParams) ([]GetTopBorrowingStudentsRow, error)
 	GetTransactionByID(ctx context.Context, id int32) (GetTransactionByIDRow, error)
+	GetTransactionByIDForUpdate(ctx context.Context, id int32) (GetTransactionByIDForUpdateRow, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id int32) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
 	GetYearlyStatistics(ctx context.Context, dollar_1 []int32) ([]GetYearlyStatisticsRow, error)
 	HasActiveReservationsByOtherStudents(ctx context.Context, arg HasActiveReservationsByOtherStudentsParams) (bool, error)
+	HasSentSmsDelivery(ctx context.Context, notificationID int32) (bool, error)
+	IsEmailSuppressed(ctx context.Context, lower string) (bool, error)
+	LiftStudentBlock(ctx context.Context, arg LiftStudentBlockParams) (StudentBlock, error)
+	// Open loans, each listed once on its latest open row
 	ListActiveBorrowings(ctx context.Context, arg ListActiveBorrowingsParams) ([]ListActiveBorrowingsRow, error)
 	ListActiveReservations(ctx context.Context) ([]ListActiveReservationsRow, error)
 	// Notification-related queries for Phase 7.2
 	ListActiveReservationsForAvailableBook(ctx context.Context, bookID int32) ([]ListActiveReservationsForAvailableBookRow, error)
+	ListActiveStudentBlocks(ctx context.Context, studentID int32) ([]StudentBlock, error)
+	// A student's open loans. A borrow that has been renewed stays open alongside its
+	// renewals, so only the latest open row for a student and book is listed.
 	ListActiveTransactionsByStudent(ctx context.Context, studentID int32) ([]ListActiveTransactionsByStudentRow, error)
 	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
 	ListAuditLogsByAction(ctx context.Context, arg ListAuditLogsByActionParams) ([]AuditLog, error)
@@ -108,49 +205,140 @@ type Querier interface {
 	ListAuditLogsByTable(ctx context.Context, arg ListAuditLogsByTableParams) ([]AuditLog, error)
 	ListAuditLogsByUser(ctx context.Context, arg ListAuditLogsByUserParams) ([]AuditLog, error)
 	ListAvailableBooks(ctx context.Context, arg ListAvailableBooksParams) ([]Book, error)
+	ListBackgroundJobs(ctx context.Context) ([]BackgroundJob, error)
+	ListBookCopiesByBook(ctx context.Context, bookID int32) ([]BookCopy, error)
 	ListBooks(ctx context.Context, arg ListBooksParams) ([]Book, error)
+	// Each recipient's delivery status: failed if the notification could not be
+	// created or its email ran out of attempts, sent once delivered, otherwise pending
+	ListBroadcastRecipients(ctx context.Context, broadcastID int32) ([]ListBroadcastRecipientsRow, error)
+	// Active students in a broadcast segment; a zero year, empty department or
+	// false flag leaves that filter out. A renewed loan is overdue only by its
+	// latest renewal
+	ListBroadcastStudents(ctx context.Context, arg ListBroadcastStudentsParams) ([]ListBroadcastStudentsRow, error)
+	ListCirculationPolicies(ctx context.Context) ([]CirculationPolicy, error)
+	// The channels a notification has already reached its recipient on
+	ListDeliveredNotificationChannels(ctx context.Context, notificationID int32) ([]string, error)
+	// Recipients on the given digest mode with notifications waiting for a digest.
+	// Recipients who have since turned digests off are included so the
+	// notifications held for them still go out.
+	ListDigestRecipients(ctx context.Context, digestMode string) ([]NotificationPreference, error)
+	ListEmailTemplateVersions(ctx context.Context, templateID int32) ([]EmailTemplateVersion, error)
+	ListEmailTemplates(ctx context.Context) ([]EmailTemplate, error)
+	ListExpiredHolds(ctx context.Context) ([]Reservation, error)
 	ListExpiredReservations(ctx context.Context) ([]ListExpiredReservationsRow, error)
+	ListFineLedgerEntriesBetween(ctx context.Context, arg ListFineLedgerEntriesBetweenParams) ([]ListFineLedgerEntriesBetweenRow, error)
+	ListFineLedgerEntriesByFine(ctx context.Context, fineID int32) ([]FineLedgerEntry, error)
+	ListFinesByStudent(ctx context.Context, studentID int32) ([]Fine, error)
+	ListFinesByTransaction(ctx context.Context, transactionID pgtype.Int4) ([]Fine, error)
+	// When each copy of a book now on the hold shelf was set aside
+	ListHoldReadyTimesByBook(ctx context.Context, bookID int32) ([]pgtype.Timestamp, error)
+	ListHoldShelf(ctx context.Context) ([]ListHoldShelfRow, error)
+	ListJobRuns(ctx context.Context, arg ListJobRunsParams) ([]JobRun, error)
+	// The most recent run of each job
+	ListLatestJobRuns(ctx context.Context) ([]JobRun, error)
+	ListLibraryClosures(ctx context.Context) ([]LibraryClosure, error)
+	ListLibraryClosuresBetween(ctx context.Context, arg ListLibraryClosuresBetweenParams) ([]LibraryClosure, error)
+	ListNotificationBroadcasts(ctx context.Context, arg ListNotificationBroadcastsParams) ([]ListNotificationBroadcastsRow, error)
 	ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error)
 	ListNotificationsByRecipient(ctx context.Context, arg ListNotificationsByRecipientParams) ([]Notification, error)
 	ListNotificationsByType(ctx context.Context, arg ListNotificationsByTypeParams) ([]Notification, error)
+	// Wait time estimate queries
+	// Due dates of the copies of a book out on loan. A borrow that has been renewed
+	// stays open alongside its renewal, so only the renewal is counted.
+	ListOpenLoanDueDatesByBook(ctx context.Context, bookID int32) ([]pgtype.Timestamp, error)
+	// A borrow that has been renewed stays open alongside its renewals until the
+	// book is returned, so only the latest open row for a student and book is the loan.
+	ListOpenOverdueLoans(ctx context.Context) ([]ListOpenOverdueLoansRow, error)
+	ListOpeningHours(ctx context.Context) ([]LibraryOpeningHour, error)
+	ListOutstandingFinesByStudent(ctx context.Context, studentID int32) ([]Fine, error)
+	// Open loans past their due date, each listed once on its latest open row
 	ListOverdueTransactions(ctx context.Context) ([]ListOverdueTransactionsRow, error)
+	// Notifications held for the recipient's next digest email
+	ListPendingDigestNotifications(ctx context.Context, arg ListPendingDigestNotificationsParams) ([]Notification, error)
+	ListPublishedEmailTemplateVersions(ctx context.Context) ([]EmailTemplateVersion, error)
 	ListRenewalsByStudentAndBook(ctx context.Context, arg ListRenewalsByStudentAndBookParams) ([]ListRenewalsByStudentAndBookRow, error)
 	ListReservations(ctx context.Context, arg ListReservationsParams) ([]ListReservationsRow, error)
 	ListReservationsByBook(ctx context.Context, bookID int32) ([]ListReservationsByBookRow, error)
 	ListReservationsByStudent(ctx context.Context, arg ListReservationsByStudentParams) ([]ListReservationsByStudentRow, error)
+	ListStudentBlocks(ctx context.Context, studentID int32) ([]StudentBlock, error)
 	ListStudents(ctx context.Context, arg ListStudentsParams) ([]Student, error)
 	ListStudentsByYear(ctx context.Context, arg ListStudentsByYearParams) ([]Student, error)
+	// Students whose email address on file is suppressed. A student drops off the
+	// list as soon as their address is changed.
+	ListStudentsWithSuppressedEmail(ctx context.Context, arg ListStudentsWithSuppressedEmailParams) ([]ListStudentsWithSuppressedEmailRow, error)
 	ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]ListTransactionsRow, error)
 	ListTransactionsByBook(ctx context.Context, arg ListTransactionsByBookParams) ([]ListTransactionsByBookRow, error)
 	ListTransactionsByStudent(ctx context.Context, arg ListTransactionsByStudentParams) ([]ListTransactionsByStudentRow, error)
 	// Notification-related queries for Phase 7.2
+	// Loans of active students due within three days, each listed once on its latest open row
 	ListTransactionsDueSoon(ctx context.Context) ([]ListTransactionsDueSoonRow, error)
+	// Overdue loans of active students, each listed once on its latest open row
 	ListTransactionsOverdue(ctx context.Context) ([]ListTransactionsOverdueRow, error)
 	ListTransactionsWithUnpaidFines(ctx context.Context) ([]ListTransactionsWithUnpaidFinesRow, error)
 	ListUnreadNotificationsByRecipient(ctx context.Context, arg ListUnreadNotificationsByRecipientParams) ([]Notification, error)
 	ListUnsentNotifications(ctx context.Context, limit int32) ([]Notification, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	MarkDigestSent(ctx context.Context, arg MarkDigestSentParams) error
+	MarkEmailDeliveryBounced(ctx context.Context, arg MarkEmailDeliveryBouncedParams) (EmailDelivery, error)
 	MarkNotificationAsRead(ctx context.Context, id int32) error
 	MarkNotificationAsSent(ctx context.Context, id int32) error
-	PayTransactionFine(ctx context.Context, id int32) error
+	// Hold shelf queries
+	MarkReservationReady(ctx context.Context, arg MarkReservationReadyParams) (Reservation, error)
+	MoveReservationInQueue(ctx context.Context, arg MoveReservationInQueueParams) (Reservation, error)
+	// Hands out the template's next version number. The row stays locked until the
+	// transaction ends, so concurrent edits are numbered one after the other.
+	NextEmailTemplateVersion(ctx context.Context, id int32) (EmailTemplate, error)
+	NextFineReceiptNumber(ctx context.Context) (int64, error)
+	PublishEmailTemplateVersion(ctx context.Context, id int32) (EmailTemplateVersion, error)
+	// Brings the due date of an open loan forward, keeping the due date it had before.
+	// A loan is only recalled once.
+	RecallTransaction(ctx context.Context, arg RecallTransactionParams) (Transaction, error)
+	// Counts a hard bounce against an address. The address is suppressed when its
+	// count reaches the threshold; once suppressed it stays so until the suppression is cleared.
+	RecordEmailHardBounce(ctx context.Context, arg RecordEmailHardBounceParams) (EmailSuppression, error)
+	RecordNotificationChannelDelivered(ctx context.Context, arg RecordNotificationChannelDeliveredParams) error
+	// Puts back an item a stopping worker claimed but did not get to
+	ReleaseQueueItem(ctx context.Context, id int32) error
 	ResetStuckQueueItems(ctx context.Context, processingStartedAt pgtype.Timestamp) error
+	ResolveCirculationPolicy(ctx context.Context, arg ResolveCirculationPolicyParams) (CirculationPolicy, error)
+	ResumeReservation(ctx context.Context, arg ResumeReservationParams) (Reservation, error)
+	// Gives a failed or cancelled item a fresh set of attempts
+	RetryQueueItem(ctx context.Context, id int32) (EmailQueue, error)
 	ReturnBook(ctx context.Context, arg ReturnBookParams) (Transaction, error)
 	SearchBooks(ctx context.Context, arg SearchBooksParams) ([]Book, error)
 	SearchBooksByGenre(ctx context.Context, arg SearchBooksByGenreParams) ([]Book, error)
 	SearchStudents(ctx context.Context, arg SearchStudentsParams) ([]Student, error)
 	SearchStudentsIncludingDeleted(ctx context.Context, arg SearchStudentsIncludingDeletedParams) ([]Student, error)
+	// Pauses or resumes a job's schedule, creating the job's row if it has never run.
+	// A NULL paused_at resumes it.
+	SetBackgroundJobPaused(ctx context.Context, arg SetBackgroundJobPausedParams) (BackgroundJob, error)
+	SetEmailTemplatePublishedVersion(ctx context.Context, arg SetEmailTemplatePublishedVersionParams) (EmailTemplate, error)
+	SetFinePaymentRequestCheckout(ctx context.Context, arg SetFinePaymentRequestCheckoutParams) (FinePaymentRequest, error)
+	SetTransactionFinePaid(ctx context.Context, arg SetTransactionFinePaidParams) error
+	SetTransactionLossStatus(ctx context.Context, arg SetTransactionLossStatusParams) (Transaction, error)
 	SoftDeleteBook(ctx context.Context, id int32) error
 	SoftDeleteStudent(ctx context.Context, id int32) error
 	SoftDeleteUser(ctx context.Context, id int32) error
+	// Queue management queries
+	SuspendReservation(ctx context.Context, arg SuspendReservationParams) (Reservation, error)
+	SyncBookCopyCounts(ctx context.Context, id int32) error
 	UpdateBook(ctx context.Context, arg UpdateBookParams) (Book, error)
 	UpdateBookAvailability(ctx context.Context, arg UpdateBookAvailabilityParams) error
 	UpdateBookCondition(ctx context.Context, arg UpdateBookConditionParams) error
+	UpdateBookCopy(ctx context.Context, arg UpdateBookCopyParams) (BookCopy, error)
+	UpdateBookCopyCondition(ctx context.Context, arg UpdateBookCopyConditionParams) error
+	UpdateBookCopyStatus(ctx context.Context, arg UpdateBookCopyStatusParams) error
+	UpdateBookCopyTotals(ctx context.Context, arg UpdateBookCopyTotalsParams) error
+	UpdateCirculationPolicy(ctx context.Context, arg UpdateCirculationPolicyParams) (CirculationPolicy, error)
 	UpdateEmailDeliveryError(ctx context.Context, arg UpdateEmailDeliveryErrorParams) (EmailDelivery, error)
 	UpdateEmailDeliveryProviderInfo(ctx context.Context, arg UpdateEmailDeliveryProviderInfoParams) (EmailDelivery, error)
 	UpdateEmailDeliveryStatus(ctx context.Context, arg UpdateEmailDeliveryStatusParams) (EmailDelivery, error)
 	UpdateEmailDeliveryToDelivered(ctx context.Context, id int32) (EmailDelivery, error)
 	UpdateEmailDeliveryToFailed(ctx context.Context, id int32) (EmailDelivery, error)
 	UpdateEmailDeliveryToSent(ctx context.Context, id int32) (EmailDelivery, error)
+	UpdateFineTotals(ctx context.Context, arg UpdateFineTotalsParams) (Fine, error)
+	UpdateLibraryClosure(ctx context.Context, arg UpdateLibraryClosureParams) (LibraryClosure, error)
+	// Items with attempts left are retried after a delay that doubles with each attempt
 	UpdateQueueItemError(ctx context.Context, arg UpdateQueueItemErrorParams) (EmailQueue, error)
 	// Items processing longer than threshold
 	UpdateQueueItemStatus(ctx context.Context, arg UpdateQueueItemStatusParams) (EmailQueue, error)
@@ -158,6 +346,10 @@ type Querier interface {
 	UpdateQueueItemToFailed(ctx context.Context, arg UpdateQueueItemToFailedParams) (EmailQueue, error)
 	UpdateQueueItemToProcessing(ctx context.Context, arg UpdateQueueItemToProcessingParams) (EmailQueue, error)
 	UpdateReservationStatus(ctx context.Context, arg UpdateReservationStatusParams) (Reservation, error)
+	UpdateSmsDeliveryError(ctx context.Context, arg UpdateSmsDeliveryErrorParams) (SmsDelivery, error)
+	// Delivery reports can arrive more than once; a final status is never undone
+	UpdateSmsDeliveryReport(ctx context.Context, arg UpdateSmsDeliveryReportParams) (SmsDelivery, error)
+	UpdateSmsDeliveryToSent(ctx context.Context, arg UpdateSmsDeliveryToSentParams) (SmsDelivery, error)
 	UpdateStudent(ctx context.Context, arg UpdateStudentParams) (Student, error)
 	UpdateStudentPassword(ctx context.Context, arg UpdateStudentPasswordParams) error
 	// Status Management Queries
@@ -166,6 +358,8 @@ type Querier interface {
 	UpdateTransactionReturn(ctx context.Context, arg UpdateTransactionReturnParams) (Transaction, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateUserLastLogin(ctx context.Context, id int32) error
+	UpsertNotificationPreferences(ctx context.Context, arg UpsertNotificationPreferencesParams) (NotificationPreference, error)
+	UpsertOpeningHours(ctx context.Context, arg UpsertOpeningHoursParams) (LibraryOpeningHour, error)
 }
 
 var _ Querier = (*Queries)(nil)
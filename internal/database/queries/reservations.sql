@@ -1,6 +1,6 @@
 -- name: CreateReservation :one
-INSERT INTO reservations (student_id, book_id, expires_at)
-VALUES ($1, $2, $3)
+INSERT INTO reservations (student_id, book_id, expires_at, priority)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: GetReservationByID :one
@@ -37,7 +37,7 @@ SELECT r.*, s.first_name, s.last_name, s.student_id as student_code
 FROM reservations r
 JOIN students s ON r.student_id = s.id
 WHERE r.book_id = $1 AND r.status = 'active'
-ORDER BY r.reserved_at ASC;
+ORDER BY r.priority DESC, r.queued_at ASC, r.id ASC;
 
 -- name: ListActiveReservations :many
 SELECT r.*, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code
@@ -48,7 +48,7 @@ WHERE r.status = 'active'
 ORDER BY r.reserved_at ASC;
 
 -- name: ListExpiredReservations :many
-SELECT r.*, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code
+SELECT r.*, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code, s.locale
 FROM reservations r
 JOIN students s ON r.student_id = s.id
 JOIN books b ON r.book_id = b.id
@@ -57,18 +57,21 @@ ORDER BY r.expires_at ASC;
 
 -- name: CountActiveReservationsByStudent :one
 SELECT COUNT(*) FROM reservations
-WHERE student_id = $1 AND status = 'active';
+WHERE student_id = $1 AND status IN ('active', 'ready');
 
+-- Suspended reservations are not counted as waiting for the book
 -- name: CountActiveReservationsByBook :one
 SELECT COUNT(*) FROM reservations
-WHERE book_id = $1 AND status = 'active';
+WHERE book_id = $1 AND status = 'active'
+  AND (suspended_until IS NULL OR suspended_until <= NOW());
 
 -- name: GetNextReservationForBook :one
-SELECT r.*, s.first_name, s.last_name, s.student_id as student_code
+SELECT r.*, s.first_name, s.last_name, s.student_id as student_code, s.locale
 FROM reservations r
 JOIN students s ON r.student_id = s.id
 WHERE r.book_id = $1 AND r.status = 'active'
-ORDER BY r.reserved_at ASC
+  AND (r.suspended_until IS NULL OR r.suspended_until <= NOW())
+ORDER BY r.priority DESC, r.queued_at ASC, r.id ASC
 LIMIT 1;
 
 -- name: GetStudentReservationForBook :one
@@ -82,17 +85,83 @@ LIMIT 1;
 -- name: CancelReservation :one
 UPDATE reservations
 SET status = 'cancelled', updated_at = NOW()
-WHERE id = $1 AND status IN ('active', 'fulfilled')
+WHERE id = $1 AND status IN ('active', 'ready', 'fulfilled')
+RETURNING *;
+
+-- Hold shelf queries
+
+-- name: MarkReservationReady :one
+UPDATE reservations
+SET status = 'ready', copy_id = $2, ready_at = NOW(), pickup_deadline = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: GetReadyReservationForStudent :one
+SELECT * FROM reservations
+WHERE student_id = $1 AND book_id = $2 AND status = 'ready'
+ORDER BY ready_at ASC
+LIMIT 1
+FOR UPDATE;
+
+-- name: ExpireHold :one
+UPDATE reservations
+SET status = 'expired', updated_at = NOW()
+WHERE id = $1 AND status = 'ready'
+RETURNING *;
+
+-- name: ListExpiredHolds :many
+SELECT * FROM reservations
+WHERE status = 'ready' AND pickup_deadline < NOW()
+ORDER BY pickup_deadline ASC;
+
+-- name: ListHoldShelf :many
+SELECT r.*, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code, c.barcode
+FROM reservations r
+JOIN students s ON r.student_id = s.id
+JOIN books b ON r.book_id = b.id
+LEFT JOIN book_copies c ON r.copy_id = c.id
+WHERE r.status = 'ready'
+ORDER BY r.pickup_deadline ASC, r.id ASC;
+
+-- name: CountHoldShelf :one
+SELECT COUNT(*) FROM reservations
+WHERE status = 'ready';
+
+-- Queue management queries
+
+-- name: SuspendReservation :one
+UPDATE reservations
+SET suspended_until = $2, expires_at = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'active'
 RETURNING *;
 
+-- name: ResumeReservation :one
+UPDATE reservations
+SET suspended_until = NULL, expires_at = $2, updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: MoveReservationInQueue :one
+UPDATE reservations
+SET priority = $2, queued_at = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: ListHoldReadyTimesByBook :many
+-- When each copy of a book now on the hold shelf was set aside
+SELECT ready_at FROM reservations
+WHERE book_id = $1 AND status = 'ready'
+ORDER BY ready_at;
+
 -- Notification-related queries for Phase 7.2
 
 -- name: ListActiveReservationsForAvailableBook :many
-SELECT r.*, s.first_name, s.last_name, s.student_id as student_code, s.email, b.title, b.author, b.book_id as book_code
+SELECT r.*, s.first_name, s.last_name, s.student_id as student_code, s.email, s.locale, b.title, b.author, b.book_id as book_code
 FROM reservations r
 JOIN students s ON r.student_id = s.id
 JOIN books b ON r.book_id = b.id
 WHERE r.book_id = $1 AND r.status = 'active'
+  AND (r.suspended_until IS NULL OR r.suspended_until <= NOW())
   AND s.is_active = true
   AND s.deleted_at IS NULL
-ORDER BY r.reserved_at ASC;
\ No newline at end of file
+ORDER BY r.priority DESC, r.queued_at ASC, r.id ASC;
\ No newline at end of file
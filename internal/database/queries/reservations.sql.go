@@ -14,8 +14,8 @@ import (
 const cancelReservation = `-- name: CancelReservation :one
 UPDATE reservations
 SET status = 'cancelled', updated_at = NOW()
-WHERE id = $1 AND status IN ('active', 'fulfilled')
-RETURNING id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at
+WHERE id = $1 AND status IN ('active', 'ready', 'fulfilled')
+RETURNING id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at, copy_id, ready_at, pickup_deadline, suspended_until, priority, queued_at
 `
 
 func (q *Queries) CancelReservation(ctx context.Context, id int32) (Reservation, error) {
@@ -31,15 +31,24 @@ func (q *Queries) CancelReservation(ctx context.Context, id int32) (Reservation,
 		&i.FulfilledAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CopyID,
+		&i.ReadyAt,
+		&i.PickupDeadline,
+		&i.SuspendedUntil,
+		&i.Priority,
+		&i.QueuedAt,
 	)
 	return i, err
 }
 
 const countActiveReservationsByBook = `-- name: CountActiveReservationsByBook :one
+
 SELECT COUNT(*) FROM reservations
 WHERE book_id = $1 AND status = 'active'
+  AND (suspended_until IS NULL OR suspended_until <= NOW())
 `
 
+// Suspended reservations are not counted as waiting for the book
 func (q *Queries) CountActiveReservationsByBook(ctx context.Context, bookID int32) (int64, error) {
 	row := q.db.QueryRow(ctx, countActiveReservationsByBook, bookID)
 	var count int64
@@ -49,7 +58,7 @@ func (q *Queries) CountActiveReservationsByBook(ctx context.Context, bookID int3
 
 const countActiveReservationsByStudent = `-- name: CountActiveReservationsByStudent :one
 SELECT COUNT(*) FROM reservations
-WHERE student_id = $1 AND status = 'active'
+WHERE student_id = $1 AND status IN ('active', 'ready')
 `
 
 func (q *Queries) CountActiveReservationsByStudent(ctx context.Context, studentID int32) (int64, error) {
@@ -59,20 +68,38 @@ func (q *Queries) CountActiveReservationsByStudent(ctx context.Context, studentI
 	return count, err
 }
 
+const countHoldShelf = `-- name: CountHoldShelf :one
+SELECT COUNT(*) FROM reservations
+WHERE status = 'ready'
+`
+
+func (q *Queries) CountHoldShelf(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countHoldShelf)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createReservation = `-- name: CreateReservation :one
-INSERT INTO reservations (student_id, book_id, expires_at)
-VALUES ($1, $2, $3)
-RETURNING id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at
+INSERT INTO reservations (student_id, book_id, expires_at, priority)
+VALUES ($1, $2, $3, $4)
+RETURNING id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at, copy_id, ready_at, pickup_deadline, suspended_until, priority, queued_at
 `
 
 type CreateReservationParams struct {
 	StudentID int32            `db:"student_id" json:"student_id"`
 	BookID    int32            `db:"book_id" json:"book_id"`
 	ExpiresAt pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	Priority  int32            `db:"priority" json:"priority"`
 }
 
 func (q *Queries) CreateReservation(ctx context.Context, arg CreateReservationParams) (Reservation, error) {
-	row := q.db.QueryRow(ctx, createReservation, arg.StudentID, arg.BookID, arg.ExpiresAt)
+	row := q.db.QueryRow(ctx, createReservation,
+		arg.StudentID,
+		arg.BookID,
+		arg.ExpiresAt,
+		arg.Priority,
+	)
 	var i Reservation
 	err := row.Scan(
 		&i.ID,
@@ -84,32 +111,76 @@ func (q *Queries) CreateReservation(ctx context.Context, arg CreateReservationPa
 		&i.FulfilledAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CopyID,
+		&i.ReadyAt,
+		&i.PickupDeadline,
+		&i.SuspendedUntil,
+		&i.Priority,
+		&i.QueuedAt,
+	)
+	return i, err
+}
+
+const expireHold = `-- name: ExpireHold :one
+UPDATE reservations
+SET status = 'expired', updated_at = NOW()
+WHERE id = $1 AND status = 'ready'
+RETURNING id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at, copy_id, ready_at, pickup_deadline, suspended_until, priority, queued_at
+`
+
+func (q *Queries) ExpireHold(ctx context.Context, id int32) (Reservation, error) {
+	row := q.db.QueryRow(ctx, expireHold, id)
+	var i Reservation
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.BookID,
+		&i.ReservedAt,
+		&i.ExpiresAt,
+		&i.Status,
+		&i.FulfilledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CopyID,
+		&i.ReadyAt,
+		&i.PickupDeadline,
+		&i.SuspendedUntil,
+		&i.Priority,
+		&i.QueuedAt,
 	)
 	return i, err
 }
 
 const getNextReservationForBook = `-- name: GetNextReservationForBook :one
-SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, s.first_name, s.last_name, s.student_id as student_code
+SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, r.copy_id, r.ready_at, r.pickup_deadline, r.suspended_until, r.priority, r.queued_at, s.first_name, s.last_name, s.student_id as student_code, s.locale
 FROM reservations r
 JOIN students s ON r.student_id = s.id
 WHERE r.book_id = $1 AND r.status = 'active'
-ORDER BY r.reserved_at ASC
+  AND (r.suspended_until IS NULL OR r.suspended_until <= NOW())
+ORDER BY r.priority DESC, r.queued_at ASC, r.id ASC
 LIMIT 1
 `
 
 type GetNextReservationForBookRow struct {
-	ID          int32            `db:"id" json:"id"`
-	StudentID   int32            `db:"student_id" json:"student_id"`
-	BookID      int32            `db:"book_id" json:"book_id"`
-	ReservedAt  pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
-	ExpiresAt   pgtype.Timestamp `db:"expires_at" json:"expires_at"`
-	Status      pgtype.Text      `db:"status" json:"status"`
-	FulfilledAt pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
-	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	FirstName   string           `db:"first_name" json:"first_name"`
-	LastName    string           `db:"last_name" json:"last_name"`
-	StudentCode string           `db:"student_code" json:"student_code"`
+	ID             int32            `db:"id" json:"id"`
+	StudentID      int32            `db:"student_id" json:"student_id"`
+	BookID         int32            `db:"book_id" json:"book_id"`
+	ReservedAt     pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
+	ExpiresAt      pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	Status         pgtype.Text      `db:"status" json:"status"`
+	FulfilledAt    pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
+	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	CopyID         pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	ReadyAt        pgtype.Timestamp `db:"ready_at" json:"ready_at"`
+	PickupDeadline pgtype.Timestamp `db:"pickup_deadline" json:"pickup_deadline"`
+	SuspendedUntil pgtype.Timestamp `db:"suspended_until" json:"suspended_until"`
+	Priority       int32            `db:"priority" json:"priority"`
+	QueuedAt       pgtype.Timestamp `db:"queued_at" json:"queued_at"`
+	FirstName      string           `db:"first_name" json:"first_name"`
+	LastName       string           `db:"last_name" json:"last_name"`
+	StudentCode    string           `db:"student_code" json:"student_code"`
+	Locale         string           `db:"locale" json:"locale"`
 }
 
 func (q *Queries) GetNextReservationForBook(ctx context.Context, bookID int32) (GetNextReservationForBookRow, error) {
@@ -125,15 +196,58 @@ func (q *Queries) GetNextReservationForBook(ctx context.Context, bookID int32) (
 		&i.FulfilledAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CopyID,
+		&i.ReadyAt,
+		&i.PickupDeadline,
+		&i.SuspendedUntil,
+		&i.Priority,
+		&i.QueuedAt,
 		&i.FirstName,
 		&i.LastName,
 		&i.StudentCode,
+		&i.Locale,
+	)
+	return i, err
+}
+
+const getReadyReservationForStudent = `-- name: GetReadyReservationForStudent :one
+SELECT id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at, copy_id, ready_at, pickup_deadline, suspended_until, priority, queued_at FROM reservations
+WHERE student_id = $1 AND book_id = $2 AND status = 'ready'
+ORDER BY ready_at ASC
+LIMIT 1
+FOR UPDATE
+`
+
+type GetReadyReservationForStudentParams struct {
+	StudentID int32 `db:"student_id" json:"student_id"`
+	BookID    int32 `db:"book_id" json:"book_id"`
+}
+
+func (q *Queries) GetReadyReservationForStudent(ctx context.Context, arg GetReadyReservationForStudentParams) (Reservation, error) {
+	row := q.db.QueryRow(ctx, getReadyReservationForStudent, arg.StudentID, arg.BookID)
+	var i Reservation
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.BookID,
+		&i.ReservedAt,
+		&i.ExpiresAt,
+		&i.Status,
+		&i.FulfilledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CopyID,
+		&i.ReadyAt,
+		&i.PickupDeadline,
+		&i.SuspendedUntil,
+		&i.Priority,
+		&i.QueuedAt,
 	)
 	return i, err
 }
 
 const getReservationByID = `-- name: GetReservationByID :one
-SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code
+SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, r.copy_id, r.ready_at, r.pickup_deadline, r.suspended_until, r.priority, r.queued_at, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code
 FROM reservations r
 JOIN students s ON r.student_id = s.id
 JOIN books b ON r.book_id = b.id
@@ -141,21 +255,27 @@ WHERE r.id = $1
 `
 
 type GetReservationByIDRow struct {
-	ID          int32            `db:"id" json:"id"`
-	StudentID   int32            `db:"student_id" json:"student_id"`
-	BookID      int32            `db:"book_id" json:"book_id"`
-	ReservedAt  pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
-	ExpiresAt   pgtype.Timestamp `db:"expires_at" json:"expires_at"`
-	Status      pgtype.Text      `db:"status" json:"status"`
-	FulfilledAt pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
-	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	FirstName   string           `db:"first_name" json:"first_name"`
-	LastName    string           `db:"last_name" json:"last_name"`
-	StudentCode string           `db:"student_code" json:"student_code"`
-	Title       string           `db:"title" json:"title"`
-	Author      string           `db:"author" json:"author"`
-	BookCode    string           `db:"book_code" json:"book_code"`
+	ID             int32            `db:"id" json:"id"`
+	StudentID      int32            `db:"student_id" json:"student_id"`
+	BookID         int32            `db:"book_id" json:"book_id"`
+	ReservedAt     pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
+	ExpiresAt      pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	Status         pgtype.Text      `db:"status" json:"status"`
+	FulfilledAt    pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
+	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	CopyID         pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	ReadyAt        pgtype.Timestamp `db:"ready_at" json:"ready_at"`
+	PickupDeadline pgtype.Timestamp `db:"pickup_deadline" json:"pickup_deadline"`
+	SuspendedUntil pgtype.Timestamp `db:"suspended_until" json:"suspended_until"`
+	Priority       int32            `db:"priority" json:"priority"`
+	QueuedAt       pgtype.Timestamp `db:"queued_at" json:"queued_at"`
+	FirstName      string           `db:"first_name" json:"first_name"`
+	LastName       string           `db:"last_name" json:"last_name"`
+	StudentCode    string           `db:"student_code" json:"student_code"`
+	Title          string           `db:"title" json:"title"`
+	Author         string           `db:"author" json:"author"`
+	BookCode       string           `db:"book_code" json:"book_code"`
 }
 
 func (q *Queries) GetReservationByID(ctx context.Context, id int32) (GetReservationByIDRow, error) {
@@ -171,6 +291,12 @@ func (q *Queries) GetReservationByID(ctx context.Context, id int32) (GetReservat
 		&i.FulfilledAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CopyID,
+		&i.ReadyAt,
+		&i.PickupDeadline,
+		&i.SuspendedUntil,
+		&i.Priority,
+		&i.QueuedAt,
 		&i.FirstName,
 		&i.LastName,
 		&i.StudentCode,
@@ -182,7 +308,7 @@ func (q *Queries) GetReservationByID(ctx context.Context, id int32) (GetReservat
 }
 
 const getStudentReservationForBook = `-- name: GetStudentReservationForBook :one
-SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, s.first_name, s.last_name, s.student_id as student_code
+SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, r.copy_id, r.ready_at, r.pickup_deadline, r.suspended_until, r.priority, r.queued_at, s.first_name, s.last_name, s.student_id as student_code
 FROM reservations r
 JOIN students s ON r.student_id = s.id
 WHERE r.student_id = $1 AND r.book_id = $2 AND r.status = $3
@@ -197,18 +323,24 @@ type GetStudentReservationForBookParams struct {
 }
 
 type GetStudentReservationForBookRow struct {
-	ID          int32            `db:"id" json:"id"`
-	StudentID   int32            `db:"student_id" json:"student_id"`
-	BookID      int32            `db:"book_id" json:"book_id"`
-	ReservedAt  pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
-	ExpiresAt   pgtype.Timestamp `db:"expires_at" json:"expires_at"`
-	Status      pgtype.Text      `db:"status" json:"status"`
-	FulfilledAt pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
-	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	FirstName   string           `db:"first_name" json:"first_name"`
-	LastName    string           `db:"last_name" json:"last_name"`
-	StudentCode string           `db:"student_code" json:"student_code"`
+	ID             int32            `db:"id" json:"id"`
+	StudentID      int32            `db:"student_id" json:"student_id"`
+	BookID         int32            `db:"book_id" json:"book_id"`
+	ReservedAt     pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
+	ExpiresAt      pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	Status         pgtype.Text      `db:"status" json:"status"`
+	FulfilledAt    pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
+	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	CopyID         pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	ReadyAt        pgtype.Timestamp `db:"ready_at" json:"ready_at"`
+	PickupDeadline pgtype.Timestamp `db:"pickup_deadline" json:"pickup_deadline"`
+	SuspendedUntil pgtype.Timestamp `db:"suspended_until" json:"suspended_until"`
+	Priority       int32            `db:"priority" json:"priority"`
+	QueuedAt       pgtype.Timestamp `db:"queued_at" json:"queued_at"`
+	FirstName      string           `db:"first_name" json:"first_name"`
+	LastName       string           `db:"last_name" json:"last_name"`
+	StudentCode    string           `db:"student_code" json:"student_code"`
 }
 
 func (q *Queries) GetStudentReservationForBook(ctx context.Context, arg GetStudentReservationForBookParams) (GetStudentReservationForBookRow, error) {
@@ -224,6 +356,12 @@ func (q *Queries) GetStudentReservationForBook(ctx context.Context, arg GetStude
 		&i.FulfilledAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CopyID,
+		&i.ReadyAt,
+		&i.PickupDeadline,
+		&i.SuspendedUntil,
+		&i.Priority,
+		&i.QueuedAt,
 		&i.FirstName,
 		&i.LastName,
 		&i.StudentCode,
@@ -232,7 +370,7 @@ func (q *Queries) GetStudentReservationForBook(ctx context.Context, arg GetStude
 }
 
 const listActiveReservations = `-- name: ListActiveReservations :many
-SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code
+SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, r.copy_id, r.ready_at, r.pickup_deadline, r.suspended_until, r.priority, r.queued_at, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code
 FROM reservations r
 JOIN students s ON r.student_id = s.id
 JOIN books b ON r.book_id = b.id
@@ -241,21 +379,27 @@ ORDER BY r.reserved_at ASC
 `
 
 type ListActiveReservationsRow struct {
-	ID          int32            `db:"id" json:"id"`
-	StudentID   int32            `db:"student_id" json:"student_id"`
-	BookID      int32            `db:"book_id" json:"book_id"`
-	ReservedAt  pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
-	ExpiresAt   pgtype.Timestamp `db:"expires_at" json:"expires_at"`
-	Status      pgtype.Text      `db:"status" json:"status"`
-	FulfilledAt pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
-	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	FirstName   string           `db:"first_name" json:"first_name"`
-	LastName    string           `db:"last_name" json:"last_name"`
-	StudentCode string           `db:"student_code" json:"student_code"`
-	Title       string           `db:"title" json:"title"`
-	Author      string           `db:"author" json:"author"`
-	BookCode    string           `db:"book_code" json:"book_code"`
+	ID             int32            `db:"id" json:"id"`
+	StudentID      int32            `db:"student_id" json:"student_id"`
+	BookID         int32            `db:"book_id" json:"book_id"`
+	ReservedAt     pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
+	ExpiresAt      pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	Status         pgtype.Text      `db:"status" json:"status"`
+	FulfilledAt    pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
+	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	CopyID         pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	ReadyAt        pgtype.Timestamp `db:"ready_at" json:"ready_at"`
+	PickupDeadline pgtype.Timestamp `db:"pickup_deadline" json:"pickup_deadline"`
+	SuspendedUntil pgtype.Timestamp `db:"suspended_until" json:"suspended_until"`
+	Priority       int32            `db:"priority" json:"priority"`
+	QueuedAt       pgtype.Timestamp `db:"queued_at" json:"queued_at"`
+	FirstName      string           `db:"first_name" json:"first_name"`
+	LastName       string           `db:"last_name" json:"last_name"`
+	StudentCode    string           `db:"student_code" json:"student_code"`
+	Title          string           `db:"title" json:"title"`
+	Author         string           `db:"author" json:"author"`
+	BookCode       string           `db:"book_code" json:"book_code"`
 }
 
 func (q *Queries) ListActiveReservations(ctx context.Context) ([]ListActiveReservationsRow, error) {
@@ -277,6 +421,12 @@ func (q *Queries) ListActiveReservations(ctx context.Context) ([]ListActiveReser
 			&i.FulfilledAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.CopyID,
+			&i.ReadyAt,
+			&i.PickupDeadline,
+			&i.SuspendedUntil,
+			&i.Priority,
+			&i.QueuedAt,
 			&i.FirstName,
 			&i.LastName,
 			&i.StudentCode,
@@ -296,33 +446,41 @@ func (q *Queries) ListActiveReservations(ctx context.Context) ([]ListActiveReser
 
 const listActiveReservationsForAvailableBook = `-- name: ListActiveReservationsForAvailableBook :many
 
-SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, s.first_name, s.last_name, s.student_id as student_code, s.email, b.title, b.author, b.book_id as book_code
+SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, r.copy_id, r.ready_at, r.pickup_deadline, r.suspended_until, r.priority, r.queued_at, s.first_name, s.last_name, s.student_id as student_code, s.email, s.locale, b.title, b.author, b.book_id as book_code
 FROM reservations r
 JOIN students s ON r.student_id = s.id
 JOIN books b ON r.book_id = b.id
 WHERE r.book_id = $1 AND r.status = 'active'
+  AND (r.suspended_until IS NULL OR r.suspended_until <= NOW())
   AND s.is_active = true
   AND s.deleted_at IS NULL
-ORDER BY r.reserved_at ASC
+ORDER BY r.priority DESC, r.queued_at ASC, r.id ASC
 `
 
 type ListActiveReservationsForAvailableBookRow struct {
-	ID          int32            `db:"id" json:"id"`
-	StudentID   int32            `db:"student_id" json:"student_id"`
-	BookID      int32            `db:"book_id" json:"book_id"`
-	ReservedAt  pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
-	ExpiresAt   pgtype.Timestamp `db:"expires_at" json:"expires_at"`
-	Status      pgtype.Text      `db:"status" json:"status"`
-	FulfilledAt pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
-	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	FirstName   string           `db:"first_name" json:"first_name"`
-	LastName    string           `db:"last_name" json:"last_name"`
-	StudentCode string           `db:"student_code" json:"student_code"`
-	Email       pgtype.Text      `db:"email" json:"email"`
-	Title       string           `db:"title" json:"title"`
-	Author      string           `db:"author" json:"author"`
-	BookCode    string           `db:"book_code" json:"book_code"`
+	ID             int32            `db:"id" json:"id"`
+	StudentID      int32            `db:"student_id" json:"student_id"`
+	BookID         int32            `db:"book_id" json:"book_id"`
+	ReservedAt     pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
+	ExpiresAt      pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	Status         pgtype.Text      `db:"status" json:"status"`
+	FulfilledAt    pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
+	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	CopyID         pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	ReadyAt        pgtype.Timestamp `db:"ready_at" json:"ready_at"`
+	PickupDeadline pgtype.Timestamp `db:"pickup_deadline" json:"pickup_deadline"`
+	SuspendedUntil pgtype.Timestamp `db:"suspended_until" json:"suspended_until"`
+	Priority       int32            `db:"priority" json:"priority"`
+	QueuedAt       pgtype.Timestamp `db:"queued_at" json:"queued_at"`
+	FirstName      string           `db:"first_name" json:"first_name"`
+	LastName       string           `db:"last_name" json:"last_name"`
+	StudentCode    string           `db:"student_code" json:"student_code"`
+	Email          pgtype.Text      `db:"email" json:"email"`
+	Locale         string           `db:"locale" json:"locale"`
+	Title          string           `db:"title" json:"title"`
+	Author         string           `db:"author" json:"author"`
+	BookCode       string           `db:"book_code" json:"book_code"`
 }
 
 // Notification-related queries for Phase 7.2
@@ -345,10 +503,17 @@ func (q *Queries) ListActiveReservationsForAvailableBook(ctx context.Context, bo
 			&i.FulfilledAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.CopyID,
+			&i.ReadyAt,
+			&i.PickupDeadline,
+			&i.SuspendedUntil,
+			&i.Priority,
+			&i.QueuedAt,
 			&i.FirstName,
 			&i.LastName,
 			&i.StudentCode,
 			&i.Email,
+			&i.Locale,
 			&i.Title,
 			&i.Author,
 			&i.BookCode,
@@ -363,8 +528,50 @@ func (q *Queries) ListActiveReservationsForAvailableBook(ctx context.Context, bo
 	return items, nil
 }
 
+const listExpiredHolds = `-- name: ListExpiredHolds :many
+SELECT id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at, copy_id, ready_at, pickup_deadline, suspended_until, priority, queued_at FROM reservations
+WHERE status = 'ready' AND pickup_deadline < NOW()
+ORDER BY pickup_deadline ASC
+`
+
+func (q *Queries) ListExpiredHolds(ctx context.Context) ([]Reservation, error) {
+	rows, err := q.db.Query(ctx, listExpiredHolds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Reservation{}
+	for rows.Next() {
+		var i Reservation
+		if err := rows.Scan(
+			&i.ID,
+			&i.StudentID,
+			&i.BookID,
+			&i.ReservedAt,
+			&i.ExpiresAt,
+			&i.Status,
+			&i.FulfilledAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CopyID,
+			&i.ReadyAt,
+			&i.PickupDeadline,
+			&i.SuspendedUntil,
+			&i.Priority,
+			&i.QueuedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listExpiredReservations = `-- name: ListExpiredReservations :many
-SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code
+SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, r.copy_id, r.ready_at, r.pickup_deadline, r.suspended_until, r.priority, r.queued_at, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code, s.locale
 FROM reservations r
 JOIN students s ON r.student_id = s.id
 JOIN books b ON r.book_id = b.id
@@ -373,21 +580,28 @@ ORDER BY r.expires_at ASC
 `
 
 type ListExpiredReservationsRow struct {
-	ID          int32            `db:"id" json:"id"`
-	StudentID   int32            `db:"student_id" json:"student_id"`
-	BookID      int32            `db:"book_id" json:"book_id"`
-	ReservedAt  pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
-	ExpiresAt   pgtype.Timestamp `db:"expires_at" json:"expires_at"`
-	Status      pgtype.Text      `db:"status" json:"status"`
-	FulfilledAt pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
-	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	FirstName   string           `db:"first_name" json:"first_name"`
-	LastName    string           `db:"last_name" json:"last_name"`
-	StudentCode string           `db:"student_code" json:"student_code"`
-	Title       string           `db:"title" json:"title"`
-	Author      string           `db:"author" json:"author"`
-	BookCode    string           `db:"book_code" json:"book_code"`
+	ID             int32            `db:"id" json:"id"`
+	StudentID      int32            `db:"student_id" json:"student_id"`
+	BookID         int32            `db:"book_id" json:"book_id"`
+	ReservedAt     pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
+	ExpiresAt      pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	Status         pgtype.Text      `db:"status" json:"status"`
+	FulfilledAt    pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
+	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	CopyID         pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	ReadyAt        pgtype.Timestamp `db:"ready_at" json:"ready_at"`
+	PickupDeadline pgtype.Timestamp `db:"pickup_deadline" json:"pickup_deadline"`
+	SuspendedUntil pgtype.Timestamp `db:"suspended_until" json:"suspended_until"`
+	Priority       int32            `db:"priority" json:"priority"`
+	QueuedAt       pgtype.Timestamp `db:"queued_at" json:"queued_at"`
+	FirstName      string           `db:"first_name" json:"first_name"`
+	LastName       string           `db:"last_name" json:"last_name"`
+	StudentCode    string           `db:"student_code" json:"student_code"`
+	Title          string           `db:"title" json:"title"`
+	Author         string           `db:"author" json:"author"`
+	BookCode       string           `db:"book_code" json:"book_code"`
+	Locale         string           `db:"locale" json:"locale"`
 }
 
 func (q *Queries) ListExpiredReservations(ctx context.Context) ([]ListExpiredReservationsRow, error) {
@@ -409,12 +623,125 @@ func (q *Queries) ListExpiredReservations(ctx context.Context) ([]ListExpiredRes
 			&i.FulfilledAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.CopyID,
+			&i.ReadyAt,
+			&i.PickupDeadline,
+			&i.SuspendedUntil,
+			&i.Priority,
+			&i.QueuedAt,
+			&i.FirstName,
+			&i.LastName,
+			&i.StudentCode,
+			&i.Title,
+			&i.Author,
+			&i.BookCode,
+			&i.Locale,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHoldReadyTimesByBook = `-- name: ListHoldReadyTimesByBook :many
+
+SELECT ready_at FROM reservations
+WHERE book_id = $1 AND status = 'ready'
+ORDER BY ready_at
+`
+
+// When each copy of a book now on the hold shelf was set aside
+func (q *Queries) ListHoldReadyTimesByBook(ctx context.Context, bookID int32) ([]pgtype.Timestamp, error) {
+	rows, err := q.db.Query(ctx, listHoldReadyTimesByBook, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.Timestamp{}
+	for rows.Next() {
+		var readyAt pgtype.Timestamp
+		if err := rows.Scan(&readyAt); err != nil {
+			return nil, err
+		}
+		items = append(items, readyAt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHoldShelf = `-- name: ListHoldShelf :many
+SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, r.copy_id, r.ready_at, r.pickup_deadline, r.suspended_until, r.priority, r.queued_at, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code, c.barcode
+FROM reservations r
+JOIN students s ON r.student_id = s.id
+JOIN books b ON r.book_id = b.id
+LEFT JOIN book_copies c ON r.copy_id = c.id
+WHERE r.status = 'ready'
+ORDER BY r.pickup_deadline ASC, r.id ASC
+`
+
+type ListHoldShelfRow struct {
+	ID             int32            `db:"id" json:"id"`
+	StudentID      int32            `db:"student_id" json:"student_id"`
+	BookID         int32            `db:"book_id" json:"book_id"`
+	ReservedAt     pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
+	ExpiresAt      pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	Status         pgtype.Text      `db:"status" json:"status"`
+	FulfilledAt    pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
+	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	CopyID         pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	ReadyAt        pgtype.Timestamp `db:"ready_at" json:"ready_at"`
+	PickupDeadline pgtype.Timestamp `db:"pickup_deadline" json:"pickup_deadline"`
+	SuspendedUntil pgtype.Timestamp `db:"suspended_until" json:"suspended_until"`
+	Priority       int32            `db:"priority" json:"priority"`
+	QueuedAt       pgtype.Timestamp `db:"queued_at" json:"queued_at"`
+	FirstName      string           `db:"first_name" json:"first_name"`
+	LastName       string           `db:"last_name" json:"last_name"`
+	StudentCode    string           `db:"student_code" json:"student_code"`
+	Title          string           `db:"title" json:"title"`
+	Author         string           `db:"author" json:"author"`
+	BookCode       string           `db:"book_code" json:"book_code"`
+	Barcode        pgtype.Text      `db:"barcode" json:"barcode"`
+}
+
+func (q *Queries) ListHoldShelf(ctx context.Context) ([]ListHoldShelfRow, error) {
+	rows, err := q.db.Query(ctx, listHoldShelf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListHoldShelfRow{}
+	for rows.Next() {
+		var i ListHoldShelfRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StudentID,
+			&i.BookID,
+			&i.ReservedAt,
+			&i.ExpiresAt,
+			&i.Status,
+			&i.FulfilledAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CopyID,
+			&i.ReadyAt,
+			&i.PickupDeadline,
+			&i.SuspendedUntil,
+			&i.Priority,
+			&i.QueuedAt,
 			&i.FirstName,
 			&i.LastName,
 			&i.StudentCode,
 			&i.Title,
 			&i.Author,
 			&i.BookCode,
+			&i.Barcode,
 		); err != nil {
 			return nil, err
 		}
@@ -427,7 +754,7 @@ func (q *Queries) ListExpiredReservations(ctx context.Context) ([]ListExpiredRes
 }
 
 const listReservations = `-- name: ListReservations :many
-SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code
+SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, r.copy_id, r.ready_at, r.pickup_deadline, r.suspended_until, r.priority, r.queued_at, s.first_name, s.last_name, s.student_id as student_code, b.title, b.author, b.book_id as book_code
 FROM reservations r
 JOIN students s ON r.student_id = s.id
 JOIN books b ON r.book_id = b.id
@@ -441,21 +768,27 @@ type ListReservationsParams struct {
 }
 
 type ListReservationsRow struct {
-	ID          int32            `db:"id" json:"id"`
-	StudentID   int32            `db:"student_id" json:"student_id"`
-	BookID      int32            `db:"book_id" json:"book_id"`
-	ReservedAt  pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
-	ExpiresAt   pgtype.Timestamp `db:"expires_at" json:"expires_at"`
-	Status      pgtype.Text      `db:"status" json:"status"`
-	FulfilledAt pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
-	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	FirstName   string           `db:"first_name" json:"first_name"`
-	LastName    string           `db:"last_name" json:"last_name"`
-	StudentCode string           `db:"student_code" json:"student_code"`
-	Title       string           `db:"title" json:"title"`
-	Author      string           `db:"author" json:"author"`
-	BookCode    string           `db:"book_code" json:"book_code"`
+	ID             int32            `db:"id" json:"id"`
+	StudentID      int32            `db:"student_id" json:"student_id"`
+	BookID         int32            `db:"book_id" json:"book_id"`
+	ReservedAt     pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
+	ExpiresAt      pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	Status         pgtype.Text      `db:"status" json:"status"`
+	FulfilledAt    pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
+	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	CopyID         pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	ReadyAt        pgtype.Timestamp `db:"ready_at" json:"ready_at"`
+	PickupDeadline pgtype.Timestamp `db:"pickup_deadline" json:"pickup_deadline"`
+	SuspendedUntil pgtype.Timestamp `db:"suspended_until" json:"suspended_until"`
+	Priority       int32            `db:"priority" json:"priority"`
+	QueuedAt       pgtype.Timestamp `db:"queued_at" json:"queued_at"`
+	FirstName      string           `db:"first_name" json:"first_name"`
+	LastName       string           `db:"last_name" json:"last_name"`
+	StudentCode    string           `db:"student_code" json:"student_code"`
+	Title          string           `db:"title" json:"title"`
+	Author         string           `db:"author" json:"author"`
+	BookCode       string           `db:"book_code" json:"book_code"`
 }
 
 func (q *Queries) ListReservations(ctx context.Context, arg ListReservationsParams) ([]ListReservationsRow, error) {
@@ -477,6 +810,12 @@ func (q *Queries) ListReservations(ctx context.Context, arg ListReservationsPara
 			&i.FulfilledAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.CopyID,
+			&i.ReadyAt,
+			&i.PickupDeadline,
+			&i.SuspendedUntil,
+			&i.Priority,
+			&i.QueuedAt,
 			&i.FirstName,
 			&i.LastName,
 			&i.StudentCode,
@@ -495,26 +834,32 @@ func (q *Queries) ListReservations(ctx context.Context, arg ListReservationsPara
 }
 
 const listReservationsByBook = `-- name: ListReservationsByBook :many
-SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, s.first_name, s.last_name, s.student_id as student_code
+SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, r.copy_id, r.ready_at, r.pickup_deadline, r.suspended_until, r.priority, r.queued_at, s.first_name, s.last_name, s.student_id as student_code
 FROM reservations r
 JOIN students s ON r.student_id = s.id
 WHERE r.book_id = $1 AND r.status = 'active'
-ORDER BY r.reserved_at ASC
+ORDER BY r.priority DESC, r.queued_at ASC, r.id ASC
 `
 
 type ListReservationsByBookRow struct {
-	ID          int32            `db:"id" json:"id"`
-	StudentID   int32            `db:"student_id" json:"student_id"`
-	BookID      int32            `db:"book_id" json:"book_id"`
-	ReservedAt  pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
-	ExpiresAt   pgtype.Timestamp `db:"expires_at" json:"expires_at"`
-	Status      pgtype.Text      `db:"status" json:"status"`
-	FulfilledAt pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
-	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	FirstName   string           `db:"first_name" json:"first_name"`
-	LastName    string           `db:"last_name" json:"last_name"`
-	StudentCode string           `db:"student_code" json:"student_code"`
+	ID             int32            `db:"id" json:"id"`
+	StudentID      int32            `db:"student_id" json:"student_id"`
+	BookID         int32            `db:"book_id" json:"book_id"`
+	ReservedAt     pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
+	ExpiresAt      pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	Status         pgtype.Text      `db:"status" json:"status"`
+	FulfilledAt    pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
+	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	CopyID         pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	ReadyAt        pgtype.Timestamp `db:"ready_at" json:"ready_at"`
+	PickupDeadline pgtype.Timestamp `db:"pickup_deadline" json:"pickup_deadline"`
+	SuspendedUntil pgtype.Timestamp `db:"suspended_until" json:"suspended_until"`
+	Priority       int32            `db:"priority" json:"priority"`
+	QueuedAt       pgtype.Timestamp `db:"queued_at" json:"queued_at"`
+	FirstName      string           `db:"first_name" json:"first_name"`
+	LastName       string           `db:"last_name" json:"last_name"`
+	StudentCode    string           `db:"student_code" json:"student_code"`
 }
 
 func (q *Queries) ListReservationsByBook(ctx context.Context, bookID int32) ([]ListReservationsByBookRow, error) {
@@ -536,6 +881,12 @@ func (q *Queries) ListReservationsByBook(ctx context.Context, bookID int32) ([]L
 			&i.FulfilledAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.CopyID,
+			&i.ReadyAt,
+			&i.PickupDeadline,
+			&i.SuspendedUntil,
+			&i.Priority,
+			&i.QueuedAt,
 			&i.FirstName,
 			&i.LastName,
 			&i.StudentCode,
@@ -551,7 +902,7 @@ func (q *Queries) ListReservationsByBook(ctx context.Context, bookID int32) ([]L
 }
 
 const listReservationsByStudent = `-- name: ListReservationsByStudent :many
-SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, b.title, b.author, b.book_id as book_code
+SELECT r.id, r.student_id, r.book_id, r.reserved_at, r.expires_at, r.status, r.fulfilled_at, r.created_at, r.updated_at, r.copy_id, r.ready_at, r.pickup_deadline, r.suspended_until, r.priority, r.queued_at, b.title, b.author, b.book_id as book_code
 FROM reservations r
 JOIN books b ON r.book_id = b.id
 WHERE r.student_id = $1
@@ -566,18 +917,24 @@ type ListReservationsByStudentParams struct {
 }
 
 type ListReservationsByStudentRow struct {
-	ID          int32            `db:"id" json:"id"`
-	StudentID   int32            `db:"student_id" json:"student_id"`
-	BookID      int32            `db:"book_id" json:"book_id"`
-	ReservedAt  pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
-	ExpiresAt   pgtype.Timestamp `db:"expires_at" json:"expires_at"`
-	Status      pgtype.Text      `db:"status" json:"status"`
-	FulfilledAt pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
-	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt   pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	Title       string           `db:"title" json:"title"`
-	Author      string           `db:"author" json:"author"`
-	BookCode    string           `db:"book_code" json:"book_code"`
+	ID             int32            `db:"id" json:"id"`
+	StudentID      int32            `db:"student_id" json:"student_id"`
+	BookID         int32            `db:"book_id" json:"book_id"`
+	ReservedAt     pgtype.Timestamp `db:"reserved_at" json:"reserved_at"`
+	ExpiresAt      pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	Status         pgtype.Text      `db:"status" json:"status"`
+	FulfilledAt    pgtype.Timestamp `db:"fulfilled_at" json:"fulfilled_at"`
+	CreatedAt      pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	CopyID         pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	ReadyAt        pgtype.Timestamp `db:"ready_at" json:"ready_at"`
+	PickupDeadline pgtype.Timestamp `db:"pickup_deadline" json:"pickup_deadline"`
+	SuspendedUntil pgtype.Timestamp `db:"suspended_until" json:"suspended_until"`
+	Priority       int32            `db:"priority" json:"priority"`
+	QueuedAt       pgtype.Timestamp `db:"queued_at" json:"queued_at"`
+	Title          string           `db:"title" json:"title"`
+	Author         string           `db:"author" json:"author"`
+	BookCode       string           `db:"book_code" json:"book_code"`
 }
 
 func (q *Queries) ListReservationsByStudent(ctx context.Context, arg ListReservationsByStudentParams) ([]ListReservationsByStudentRow, error) {
@@ -599,6 +956,12 @@ func (q *Queries) ListReservationsByStudent(ctx context.Context, arg ListReserva
 			&i.FulfilledAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.CopyID,
+			&i.ReadyAt,
+			&i.PickupDeadline,
+			&i.SuspendedUntil,
+			&i.Priority,
+			&i.QueuedAt,
 			&i.Title,
 			&i.Author,
 			&i.BookCode,
@@ -613,11 +976,158 @@ func (q *Queries) ListReservationsByStudent(ctx context.Context, arg ListReserva
 	return items, nil
 }
 
+const markReservationReady = `-- name: MarkReservationReady :one
+
+UPDATE reservations
+SET status = 'ready', copy_id = $2, ready_at = NOW(), pickup_deadline = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at, copy_id, ready_at, pickup_deadline, suspended_until, priority, queued_at
+`
+
+type MarkReservationReadyParams struct {
+	ID             int32            `db:"id" json:"id"`
+	CopyID         pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	PickupDeadline pgtype.Timestamp `db:"pickup_deadline" json:"pickup_deadline"`
+}
+
+// Hold shelf queries
+func (q *Queries) MarkReservationReady(ctx context.Context, arg MarkReservationReadyParams) (Reservation, error) {
+	row := q.db.QueryRow(ctx, markReservationReady, arg.ID, arg.CopyID, arg.PickupDeadline)
+	var i Reservation
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.BookID,
+		&i.ReservedAt,
+		&i.ExpiresAt,
+		&i.Status,
+		&i.FulfilledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CopyID,
+		&i.ReadyAt,
+		&i.PickupDeadline,
+		&i.SuspendedUntil,
+		&i.Priority,
+		&i.QueuedAt,
+	)
+	return i, err
+}
+
+const moveReservationInQueue = `-- name: MoveReservationInQueue :one
+UPDATE reservations
+SET priority = $2, queued_at = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at, copy_id, ready_at, pickup_deadline, suspended_until, priority, queued_at
+`
+
+type MoveReservationInQueueParams struct {
+	ID       int32            `db:"id" json:"id"`
+	Priority int32            `db:"priority" json:"priority"`
+	QueuedAt pgtype.Timestamp `db:"queued_at" json:"queued_at"`
+}
+
+func (q *Queries) MoveReservationInQueue(ctx context.Context, arg MoveReservationInQueueParams) (Reservation, error) {
+	row := q.db.QueryRow(ctx, moveReservationInQueue, arg.ID, arg.Priority, arg.QueuedAt)
+	var i Reservation
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.BookID,
+		&i.ReservedAt,
+		&i.ExpiresAt,
+		&i.Status,
+		&i.FulfilledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CopyID,
+		&i.ReadyAt,
+		&i.PickupDeadline,
+		&i.SuspendedUntil,
+		&i.Priority,
+		&i.QueuedAt,
+	)
+	return i, err
+}
+
+const resumeReservation = `-- name: ResumeReservation :one
+UPDATE reservations
+SET suspended_until = NULL, expires_at = $2, updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at, copy_id, ready_at, pickup_deadline, suspended_until, priority, queued_at
+`
+
+type ResumeReservationParams struct {
+	ID        int32            `db:"id" json:"id"`
+	ExpiresAt pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+}
+
+func (q *Queries) ResumeReservation(ctx context.Context, arg ResumeReservationParams) (Reservation, error) {
+	row := q.db.QueryRow(ctx, resumeReservation, arg.ID, arg.ExpiresAt)
+	var i Reservation
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.BookID,
+		&i.ReservedAt,
+		&i.ExpiresAt,
+		&i.Status,
+		&i.FulfilledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CopyID,
+		&i.ReadyAt,
+		&i.PickupDeadline,
+		&i.SuspendedUntil,
+		&i.Priority,
+		&i.QueuedAt,
+	)
+	return i, err
+}
+
+const suspendReservation = `-- name: SuspendReservation :one
+
+UPDATE reservations
+SET suspended_until = $2, expires_at = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at, copy_id, ready_at, pickup_deadline, suspended_until, priority, queued_at
+`
+
+type SuspendReservationParams struct {
+	ID             int32            `db:"id" json:"id"`
+	SuspendedUntil pgtype.Timestamp `db:"suspended_until" json:"suspended_until"`
+	ExpiresAt      pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+}
+
+// Queue management queries
+func (q *Queries) SuspendReservation(ctx context.Context, arg SuspendReservationParams) (Reservation, error) {
+	row := q.db.QueryRow(ctx, suspendReservation, arg.ID, arg.SuspendedUntil, arg.ExpiresAt)
+	var i Reservation
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.BookID,
+		&i.ReservedAt,
+		&i.ExpiresAt,
+		&i.Status,
+		&i.FulfilledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CopyID,
+		&i.ReadyAt,
+		&i.PickupDeadline,
+		&i.SuspendedUntil,
+		&i.Priority,
+		&i.QueuedAt,
+	)
+	return i, err
+}
+
 const updateReservationStatus = `-- name: UpdateReservationStatus :one
 UPDATE reservations
 SET status = $2, fulfilled_at = $3, updated_at = NOW()
 WHERE id = $1
-RETURNING id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at
+RETURNING id, student_id, book_id, reserved_at, expires_at, status, fulfilled_at, created_at, updated_at, copy_id, ready_at, pickup_deadline, suspended_until, priority, queued_at
 `
 
 type UpdateReservationStatusParams struct {
@@ -639,6 +1149,12 @@ func (q *Queries) UpdateReservationStatus(ctx context.Context, arg UpdateReserva
 		&i.FulfilledAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CopyID,
+		&i.ReadyAt,
+		&i.PickupDeadline,
+		&i.SuspendedUntil,
+		&i.Priority,
+		&i.QueuedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,61 @@
+-- SMS Deliveries Queries
+
+-- name: CreateSmsDelivery :one
+INSERT INTO sms_deliveries (
+    notification_id,
+    phone_number,
+    message,
+    segments,
+    encoding,
+    provider
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetSmsDelivery :one
+SELECT * FROM sms_deliveries WHERE id = $1;
+
+-- name: GetSmsDeliveriesByNotification :many
+SELECT * FROM sms_deliveries WHERE notification_id = $1 ORDER BY created_at DESC;
+
+-- name: HasSentSmsDelivery :one
+SELECT EXISTS (
+    SELECT 1 FROM sms_deliveries
+    WHERE notification_id = $1 AND status IN ('sent', 'delivered')
+) AS has_sent;
+
+-- name: UpdateSmsDeliveryToSent :one
+UPDATE sms_deliveries
+SET
+    status = 'sent',
+    provider_message_id = $2,
+    cost = $3,
+    sent_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateSmsDeliveryError :one
+UPDATE sms_deliveries
+SET
+    status = 'failed',
+    error_message = $2,
+    failed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- Delivery reports can arrive more than once; a final status is never undone
+-- name: UpdateSmsDeliveryReport :one
+UPDATE sms_deliveries
+SET
+    status = $2::varchar,
+    error_message = COALESCE($3, error_message),
+    delivered_at = CASE WHEN $2::varchar = 'delivered' THEN NOW() ELSE delivered_at END,
+    failed_at = CASE WHEN $2::varchar = 'failed' THEN NOW() ELSE failed_at END,
+    updated_at = NOW()
+WHERE provider_message_id = $1 AND status NOT IN ('delivered', 'failed')
+RETURNING *;
+
+-- name: DeleteOldSmsDeliveries :exec
+DELETE FROM sms_deliveries WHERE created_at < $1;
@@ -0,0 +1,29 @@
+-- name: CreateStudentBlock :one
+INSERT INTO student_blocks (
+    student_id, reason, expires_at, created_by
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetStudentBlockByIDForUpdate :one
+SELECT * FROM student_blocks
+WHERE id = $1
+FOR UPDATE;
+
+-- name: LiftStudentBlock :one
+UPDATE student_blocks
+SET lifted_at = NOW(), lifted_by = $2, lift_reason = $3, updated_at = NOW()
+WHERE id = $1 AND lifted_at IS NULL
+RETURNING *;
+
+-- name: ListActiveStudentBlocks :many
+SELECT * FROM student_blocks
+WHERE student_id = $1
+  AND lifted_at IS NULL
+  AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at ASC;
+
+-- name: ListStudentBlocks :many
+SELECT * FROM student_blocks
+WHERE student_id = $1
+ORDER BY created_at DESC;
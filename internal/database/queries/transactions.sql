@@ -1,15 +1,23 @@
 -- name: CreateTransaction :one
-INSERT INTO transactions (student_id, book_id, transaction_type, due_date, librarian_id, notes)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO transactions (student_id, book_id, transaction_type, due_date, librarian_id, notes, copy_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: GetTransactionByID :one
-SELECT t.*, s.first_name, s.last_name, s.student_id, b.title, b.author, b.book_id
+SELECT t.*, s.first_name, s.last_name, s.student_id, s.year_of_study, s.department, b.title, b.author, b.book_id, b.genre, s.locale
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
 WHERE t.id = $1;
 
+-- name: GetTransactionByIDForUpdate :one
+SELECT t.*, s.first_name, s.last_name, s.student_id, s.year_of_study, s.department, b.title, b.author, b.book_id, b.genre, s.locale
+FROM transactions t
+JOIN students s ON t.student_id = s.id
+JOIN books b ON t.book_id = b.id
+WHERE t.id = $1
+FOR UPDATE OF t;
+
 -- name: UpdateTransactionReturn :one
 UPDATE transactions
 SET returned_date = NOW(), fine_amount = $2, updated_at = NOW()
@@ -21,10 +29,12 @@ UPDATE transactions
 SET fine_amount = $2, updated_at = NOW()
 WHERE id = $1;
 
--- name: PayTransactionFine :exec
+-- name: AccrueTransactionFine :execrows
+-- Sets the running fine on a loan that is still out. Loans returned in the meantime
+-- keep the fine charged at return, and an unchanged amount is not rewritten.
 UPDATE transactions
-SET fine_paid = true, updated_at = NOW()
-WHERE id = $1;
+SET fine_amount = $2, updated_at = NOW()
+WHERE id = $1 AND returned_date IS NULL AND fine_amount IS DISTINCT FROM $2;
 
 -- name: ListTransactions :many
 SELECT t.*, s.first_name, s.last_name, s.student_id, b.title, b.author, b.book_id
@@ -51,33 +61,75 @@ ORDER BY t.transaction_date DESC
 LIMIT $2 OFFSET $3;
 
 -- name: ListOverdueTransactions :many
+-- Open loans past their due date, each listed once on its latest open row
 SELECT t.*, s.first_name, s.last_name, s.student_id, b.title, b.author, b.book_id
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
 WHERE t.due_date < NOW() AND t.returned_date IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
 ORDER BY t.due_date ASC;
 
+-- name: ListOpenOverdueLoans :many
+-- A borrow that has been renewed stays open alongside its renewals until the
+-- book is returned, so only the latest open row for a student and book is the loan.
+SELECT t.id, t.student_id, t.book_id, t.due_date, t.fine_amount, t.recalled_at, s.year_of_study, s.department, b.genre
+FROM transactions t
+JOIN students s ON t.student_id = s.id
+JOIN books b ON t.book_id = b.id
+WHERE t.transaction_type IN ('borrow', 'renew')
+  AND t.returned_date IS NULL
+  AND t.due_date < NOW()
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
+ORDER BY t.id;
+
 -- name: ListActiveTransactionsByStudent :many
+-- A student's open loans. A borrow that has been renewed stays open alongside its
+-- renewals, so only the latest open row for a student and book is listed.
 SELECT t.*, b.title, b.author, b.book_id
 FROM transactions t
 JOIN books b ON t.book_id = b.id
 WHERE t.student_id = $1 AND t.returned_date IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
 ORDER BY t.due_date ASC;
 
 -- name: CountTransactions :one
 SELECT COUNT(*) FROM transactions;
 
 -- name: CountOverdueTransactions :one
-SELECT COUNT(*) FROM transactions
-WHERE due_date < NOW() AND returned_date IS NULL;
+-- Open loans past their due date, each counted once on its latest open row
+SELECT COUNT(*) FROM transactions t
+WHERE t.due_date < NOW() AND t.returned_date IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  );
 
 -- name: ListActiveBorrowings :many
+-- Open loans, each listed once on its latest open row
 SELECT t.*, s.first_name, s.last_name, s.student_id, b.title, b.author, b.book_id
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
 WHERE t.returned_date IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
 ORDER BY t.due_date ASC
 LIMIT $1 OFFSET $2;
 
@@ -118,7 +170,8 @@ GROUP BY student_id;
 -- Notification-related queries for Phase 7.2
 
 -- name: ListTransactionsDueSoon :many
-SELECT t.*, s.first_name, s.last_name, s.student_id, s.email, b.title, b.author, b.book_id
+-- Loans of active students due within three days, each listed once on its latest open row
+SELECT t.*, s.first_name, s.last_name, s.student_id, s.email, s.locale, b.title, b.author, b.book_id
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
@@ -126,24 +179,181 @@ WHERE t.due_date >= NOW() AND t.due_date <= NOW() + INTERVAL '3 days'
   AND t.returned_date IS NULL
   AND s.is_active = true
   AND s.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
 ORDER BY t.due_date ASC;
 
 -- name: ListTransactionsOverdue :many
-SELECT t.*, s.first_name, s.last_name, s.student_id, s.email, b.title, b.author, b.book_id
+-- Overdue loans of active students, each listed once on its latest open row
+SELECT t.*, s.first_name, s.last_name, s.student_id, s.email, s.locale, b.title, b.author, b.book_id
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
 WHERE t.due_date < NOW() AND t.returned_date IS NULL
   AND s.is_active = true
   AND s.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
 ORDER BY t.due_date ASC;
 
 -- name: ListTransactionsWithUnpaidFines :many
-SELECT t.*, s.first_name, s.last_name, s.student_id, s.email, b.title, b.author, b.book_id
+SELECT t.*, s.first_name, s.last_name, s.student_id, s.email, s.locale, b.title, b.author, b.book_id
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
 WHERE t.fine_amount > 0 AND t.fine_paid = false
   AND s.is_active = true
   AND s.deleted_at IS NULL
-ORDER BY t.fine_amount DESC;
\ No newline at end of file
+ORDER BY t.fine_amount DESC;
+-- name: GetActiveTransactionByCopyID :one
+-- The current row of the open loan of a copy. A borrow that has been renewed
+-- stays open alongside its renewals, so the latest of them is the loan.
+SELECT * FROM transactions
+WHERE copy_id = $1 AND returned_date IS NULL AND transaction_type IN ('borrow', 'renew')
+ORDER BY transaction_date DESC, id DESC
+LIMIT 1;
+
+-- name: GetCurrentLoanTransactionID :one
+-- The latest open row of the loan a transaction belongs to, which is the
+-- transaction itself unless the loan has been renewed since
+SELECT t.id AS current_id FROM transactions t
+JOIN transactions l ON l.id = $1
+WHERE t.id = l.id
+   OR (t.student_id = l.student_id AND t.book_id = l.book_id
+       AND t.copy_id IS NOT DISTINCT FROM l.copy_id
+       AND t.transaction_type IN ('borrow', 'renew')
+       AND t.returned_date IS NULL)
+ORDER BY t.id DESC
+LIMIT 1;
+
+-- name: CloseRenewedLoanTransactions :exec
+-- Closes the rows a loan was renewed from once it has been closed on its
+-- current row, so no part of it is left open to accrue fines
+UPDATE transactions t
+SET returned_date = l.returned_date, updated_at = NOW()
+FROM transactions l
+WHERE l.id = $1
+  AND l.returned_date IS NOT NULL
+  AND t.student_id = l.student_id AND t.book_id = l.book_id
+  AND t.copy_id IS NOT DISTINCT FROM l.copy_id
+  AND t.transaction_type IN ('borrow', 'renew')
+  AND t.returned_date IS NULL
+  AND t.id < l.id;
+
+-- name: SetTransactionFinePaid :exec
+UPDATE transactions
+SET fine_paid = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CloseTransactionAsMissing :one
+UPDATE transactions
+SET returned_date = NOW(), fine_amount = $2, loss_status = $3, loss_reported_at = NOW(), condition_notes = $4, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetTransactionLossStatus :one
+UPDATE transactions
+SET loss_status = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: RecallTransaction :one
+-- Brings the due date of an open loan forward, keeping the due date it had before.
+-- A loan is only recalled once.
+UPDATE transactions
+SET original_due_date = due_date, due_date = $2, recalled_at = NOW(), recalled_by = $3, updated_at = NOW()
+WHERE id = $1 AND returned_date IS NULL AND recalled_at IS NULL
+RETURNING *;
+
+-- name: CountOpenRecallsByBook :one
+SELECT COUNT(*) FROM transactions
+WHERE book_id = $1 AND returned_date IS NULL AND recalled_at IS NOT NULL;
+
+-- name: GetRecallableLoanByBook :one
+-- The oldest loan of the book that has not been recalled. A borrow that has been
+-- renewed stays open alongside its renewal, so only the renewal can be recalled.
+SELECT t.id FROM transactions t
+WHERE t.book_id = $1
+  AND t.transaction_type IN ('borrow', 'renew')
+  AND t.returned_date IS NULL
+  AND t.recalled_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
+ORDER BY t.transaction_date, t.id
+LIMIT 1;
+
+-- Wait time estimate queries
+
+-- name: ListOpenLoanDueDatesByBook :many
+-- Due dates of the copies of a book out on loan. A borrow that has been renewed
+-- stays open alongside its renewal, so only the renewal is counted.
+SELECT t.due_date FROM transactions t
+WHERE t.book_id = $1
+  AND t.transaction_type IN ('borrow', 'renew')
+  AND t.returned_date IS NULL
+  AND t.due_date IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
+ORDER BY t.due_date;
+
+-- name: GetBookReturnHistory :one
+-- How loans of a book returned since the given time ran. A loan is counted once,
+-- from its borrow: the loan period is the one it was lent for, renewals counts the
+-- times loans were renewed, and lateness is in days past the due date of the row
+-- the book came back on, negative when it came back early.
+SELECT
+    COUNT(*) FILTER (WHERE transaction_type = 'borrow')::int AS returned_loans,
+    COUNT(*) FILTER (WHERE transaction_type = 'renew')::int AS renewals,
+    COALESCE(AVG(EXTRACT(EPOCH FROM (due_date - transaction_date)) / 86400) FILTER (WHERE transaction_type = 'borrow'), 0)::float8 AS avg_loan_days,
+    COALESCE(AVG(EXTRACT(EPOCH FROM (returned_date - due_date)) / 86400) FILTER (WHERE NOT renewed), 0)::float8 AS avg_days_late,
+    COALESCE(STDDEV_SAMP(EXTRACT(EPOCH FROM (returned_date - due_date)) / 86400) FILTER (WHERE NOT renewed), 0)::float8 AS stddev_days_late
+FROM (
+    SELECT t.transaction_type, t.transaction_date, t.due_date, t.returned_date,
+        EXISTS (
+            SELECT 1 FROM transactions r
+            WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+              AND r.copy_id IS NOT DISTINCT FROM t.copy_id
+              AND r.transaction_type = 'renew' AND r.id > t.id
+              AND r.transaction_date <= t.returned_date
+        ) AS renewed
+    FROM transactions t
+    WHERE t.book_id = $1
+      AND t.transaction_type IN ('borrow', 'renew')
+      AND t.returned_date >= $2
+      AND t.due_date IS NOT NULL
+) loans;
+
+-- name: GetLibraryReturnHistory :one
+-- The same summary as GetBookReturnHistory across every book
+SELECT
+    COUNT(*) FILTER (WHERE transaction_type = 'borrow')::int AS returned_loans,
+    COUNT(*) FILTER (WHERE transaction_type = 'renew')::int AS renewals,
+    COALESCE(AVG(EXTRACT(EPOCH FROM (due_date - transaction_date)) / 86400) FILTER (WHERE transaction_type = 'borrow'), 0)::float8 AS avg_loan_days,
+    COALESCE(AVG(EXTRACT(EPOCH FROM (returned_date - due_date)) / 86400) FILTER (WHERE NOT renewed), 0)::float8 AS avg_days_late,
+    COALESCE(STDDEV_SAMP(EXTRACT(EPOCH FROM (returned_date - due_date)) / 86400) FILTER (WHERE NOT renewed), 0)::float8 AS stddev_days_late
+FROM (
+    SELECT t.transaction_type, t.transaction_date, t.due_date, t.returned_date,
+        EXISTS (
+            SELECT 1 FROM transactions r
+            WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+              AND r.copy_id IS NOT DISTINCT FROM t.copy_id
+              AND r.transaction_type = 'renew' AND r.id > t.id
+              AND r.transaction_date <= t.returned_date
+        ) AS renewed
+    FROM transactions t
+    WHERE t.transaction_type IN ('borrow', 'renew')
+      AND t.returned_date >= $1
+      AND t.due_date IS NOT NULL
+) loans;
@@ -11,11 +11,121 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const countOverdueTransactions = `-- name: CountOverdueTransactions :one
+const accrueTransactionFine = `-- name: AccrueTransactionFine :execrows
+
+UPDATE transactions
+SET fine_amount = $2, updated_at = NOW()
+WHERE id = $1 AND returned_date IS NULL AND fine_amount IS DISTINCT FROM $2
+`
+
+type AccrueTransactionFineParams struct {
+	ID         int32          `db:"id" json:"id"`
+	FineAmount pgtype.Numeric `db:"fine_amount" json:"fine_amount"`
+}
+
+// Sets the running fine on a loan that is still out. Loans returned in the meantime
+// keep the fine charged at return, and an unchanged amount is not rewritten.
+func (q *Queries) AccrueTransactionFine(ctx context.Context, arg AccrueTransactionFineParams) (int64, error) {
+	result, err := q.db.Exec(ctx, accrueTransactionFine, arg.ID, arg.FineAmount)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const closeRenewedLoanTransactions = `-- name: CloseRenewedLoanTransactions :exec
+
+UPDATE transactions t
+SET returned_date = l.returned_date, updated_at = NOW()
+FROM transactions l
+WHERE l.id = $1
+  AND l.returned_date IS NOT NULL
+  AND t.student_id = l.student_id AND t.book_id = l.book_id
+  AND t.copy_id IS NOT DISTINCT FROM l.copy_id
+  AND t.transaction_type IN ('borrow', 'renew')
+  AND t.returned_date IS NULL
+  AND t.id < l.id
+`
+
+// Closes the rows a loan was renewed from once it has been closed on its
+// current row, so no part of it is left open to accrue fines
+func (q *Queries) CloseRenewedLoanTransactions(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, closeRenewedLoanTransactions, id)
+	return err
+}
+
+const closeTransactionAsMissing = `-- name: CloseTransactionAsMissing :one
+UPDATE transactions
+SET returned_date = NOW(), fine_amount = $2, loss_status = $3, loss_reported_at = NOW(), condition_notes = $4, updated_at = NOW()
+WHERE id = $1
+RETURNING id, student_id, book_id, transaction_type, transaction_date, due_date, returned_date, librarian_id, fine_amount, fine_paid, notes, created_at, updated_at, return_condition, condition_notes, copy_id, loss_status, loss_reported_at, recalled_at, recalled_by, original_due_date
+`
+
+type CloseTransactionAsMissingParams struct {
+	ID             int32          `db:"id" json:"id"`
+	FineAmount     pgtype.Numeric `db:"fine_amount" json:"fine_amount"`
+	LossStatus     pgtype.Text    `db:"loss_status" json:"loss_status"`
+	ConditionNotes pgtype.Text    `db:"condition_notes" json:"condition_notes"`
+}
+
+func (q *Queries) CloseTransactionAsMissing(ctx context.Context, arg CloseTransactionAsMissingParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, closeTransactionAsMissing,
+		arg.ID,
+		arg.FineAmount,
+		arg.LossStatus,
+		arg.ConditionNotes,
+	)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.BookID,
+		&i.TransactionType,
+		&i.TransactionDate,
+		&i.DueDate,
+		&i.ReturnedDate,
+		&i.LibrarianID,
+		&i.FineAmount,
+		&i.FinePaid,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReturnCondition,
+		&i.ConditionNotes,
+		&i.CopyID,
+		&i.LossStatus,
+		&i.LossReportedAt,
+		&i.RecalledAt,
+		&i.RecalledBy,
+		&i.OriginalDueDate,
+	)
+	return i, err
+}
+
+const countOpenRecallsByBook = `-- name: CountOpenRecallsByBook :one
 SELECT COUNT(*) FROM transactions
-WHERE due_date < NOW() AND returned_date IS NULL
+WHERE book_id = $1 AND returned_date IS NULL AND recalled_at IS NOT NULL
+`
+
+func (q *Queries) CountOpenRecallsByBook(ctx context.Context, bookID int32) (int64, error) {
+	row := q.db.QueryRow(ctx, countOpenRecallsByBook, bookID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countOverdueTransactions = `-- name: CountOverdueTransactions :one
+
+SELECT COUNT(*) FROM transactions t
+WHERE t.due_date < NOW() AND t.returned_date IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
 `
 
+// Open loans past their due date, each counted once on its latest open row
 func (q *Queries) CountOverdueTransactions(ctx context.Context) (int64, error) {
 	row := q.db.QueryRow(ctx, countOverdueTransactions)
 	var count int64
@@ -54,9 +164,9 @@ func (q *Queries) CountTransactions(ctx context.Context) (int64, error) {
 }
 
 const createTransaction = `-- name: CreateTransaction :one
-INSERT INTO transactions (student_id, book_id, transaction_type, due_date, librarian_id, notes)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, student_id, book_id, transaction_type, transaction_date, due_date, returned_date, librarian_id, fine_amount, fine_paid, notes, created_at, updated_at, return_condition, condition_notes
+INSERT INTO transactions (student_id, book_id, transaction_type, due_date, librarian_id, notes, copy_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, student_id, book_id, transaction_type, transaction_date, due_date, returned_date, librarian_id, fine_amount, fine_paid, notes, created_at, updated_at, return_condition, condition_notes, copy_id, loss_status, loss_reported_at, recalled_at, recalled_by, original_due_date
 `
 
 type CreateTransactionParams struct {
@@ -66,6 +176,7 @@ type CreateTransactionParams struct {
 	DueDate         pgtype.Timestamp `db:"due_date" json:"due_date"`
 	LibrarianID     pgtype.Int4      `db:"librarian_id" json:"librarian_id"`
 	Notes           pgtype.Text      `db:"notes" json:"notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error) {
@@ -76,7 +187,47 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.DueDate,
 		arg.LibrarianID,
 		arg.Notes,
+		arg.CopyID,
+	)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.BookID,
+		&i.TransactionType,
+		&i.TransactionDate,
+		&i.DueDate,
+		&i.ReturnedDate,
+		&i.LibrarianID,
+		&i.FineAmount,
+		&i.FinePaid,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReturnCondition,
+		&i.ConditionNotes,
+		&i.CopyID,
+		&i.LossStatus,
+		&i.LossReportedAt,
+		&i.RecalledAt,
+		&i.RecalledBy,
+		&i.OriginalDueDate,
 	)
+	return i, err
+}
+
+const getActiveTransactionByCopyID = `-- name: GetActiveTransactionByCopyID :one
+
+SELECT id, student_id, book_id, transaction_type, transaction_date, due_date, returned_date, librarian_id, fine_amount, fine_paid, notes, created_at, updated_at, return_condition, condition_notes, copy_id, loss_status, loss_reported_at, recalled_at, recalled_by, original_due_date FROM transactions
+WHERE copy_id = $1 AND returned_date IS NULL AND transaction_type IN ('borrow', 'renew')
+ORDER BY transaction_date DESC, id DESC
+LIMIT 1
+`
+
+// The current row of the open loan of a copy. A borrow that has been renewed
+// stays open alongside its renewals, so the latest of them is the loan.
+func (q *Queries) GetActiveTransactionByCopyID(ctx context.Context, copyID pgtype.Int4) (Transaction, error) {
+	row := q.db.QueryRow(ctx, getActiveTransactionByCopyID, copyID)
 	var i Transaction
 	err := row.Scan(
 		&i.ID,
@@ -94,10 +245,164 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.UpdatedAt,
 		&i.ReturnCondition,
 		&i.ConditionNotes,
+		&i.CopyID,
+		&i.LossStatus,
+		&i.LossReportedAt,
+		&i.RecalledAt,
+		&i.RecalledBy,
+		&i.OriginalDueDate,
+	)
+	return i, err
+}
+
+const getBookReturnHistory = `-- name: GetBookReturnHistory :one
+
+SELECT
+    COUNT(*) FILTER (WHERE transaction_type = 'borrow')::int AS returned_loans,
+    COUNT(*) FILTER (WHERE transaction_type = 'renew')::int AS renewals,
+    COALESCE(AVG(EXTRACT(EPOCH FROM (due_date - transaction_date)) / 86400) FILTER (WHERE transaction_type = 'borrow'), 0)::float8 AS avg_loan_days,
+    COALESCE(AVG(EXTRACT(EPOCH FROM (returned_date - due_date)) / 86400) FILTER (WHERE NOT renewed), 0)::float8 AS avg_days_late,
+    COALESCE(STDDEV_SAMP(EXTRACT(EPOCH FROM (returned_date - due_date)) / 86400) FILTER (WHERE NOT renewed), 0)::float8 AS stddev_days_late
+FROM (
+    SELECT t.transaction_type, t.transaction_date, t.due_date, t.returned_date,
+        EXISTS (
+            SELECT 1 FROM transactions r
+            WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+              AND r.copy_id IS NOT DISTINCT FROM t.copy_id
+              AND r.transaction_type = 'renew' AND r.id > t.id
+              AND r.transaction_date <= t.returned_date
+        ) AS renewed
+    FROM transactions t
+    WHERE t.book_id = $1
+      AND t.transaction_type IN ('borrow', 'renew')
+      AND t.returned_date >= $2
+      AND t.due_date IS NOT NULL
+) loans
+`
+
+type GetBookReturnHistoryParams struct {
+	BookID       int32            `db:"book_id" json:"book_id"`
+	ReturnedDate pgtype.Timestamp `db:"returned_date" json:"returned_date"`
+}
+
+type GetBookReturnHistoryRow struct {
+	ReturnedLoans  int32   `db:"returned_loans" json:"returned_loans"`
+	Renewals       int32   `db:"renewals" json:"renewals"`
+	AvgLoanDays    float64 `db:"avg_loan_days" json:"avg_loan_days"`
+	AvgDaysLate    float64 `db:"avg_days_late" json:"avg_days_late"`
+	StddevDaysLate float64 `db:"stddev_days_late" json:"stddev_days_late"`
+}
+
+// How loans of a book returned since the given time ran. A loan is counted once,
+// from its borrow: the loan period is the one it was lent for, renewals counts the
+// times loans were renewed, and lateness is in days past the due date of the row
+// the book came back on, negative when it came back early.
+func (q *Queries) GetBookReturnHistory(ctx context.Context, arg GetBookReturnHistoryParams) (GetBookReturnHistoryRow, error) {
+	row := q.db.QueryRow(ctx, getBookReturnHistory, arg.BookID, arg.ReturnedDate)
+	var i GetBookReturnHistoryRow
+	err := row.Scan(
+		&i.ReturnedLoans,
+		&i.Renewals,
+		&i.AvgLoanDays,
+		&i.AvgDaysLate,
+		&i.StddevDaysLate,
+	)
+	return i, err
+}
+
+const getCurrentLoanTransactionID = `-- name: GetCurrentLoanTransactionID :one
+
+SELECT t.id AS current_id FROM transactions t
+JOIN transactions l ON l.id = $1
+WHERE t.id = l.id
+   OR (t.student_id = l.student_id AND t.book_id = l.book_id
+       AND t.copy_id IS NOT DISTINCT FROM l.copy_id
+       AND t.transaction_type IN ('borrow', 'renew')
+       AND t.returned_date IS NULL)
+ORDER BY t.id DESC
+LIMIT 1
+`
+
+// The latest open row of the loan a transaction belongs to, which is the
+// transaction itself unless the loan has been renewed since
+func (q *Queries) GetCurrentLoanTransactionID(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, getCurrentLoanTransactionID, id)
+	var current_id int32
+	err := row.Scan(&current_id)
+	return current_id, err
+}
+
+const getLibraryReturnHistory = `-- name: GetLibraryReturnHistory :one
+
+SELECT
+    COUNT(*) FILTER (WHERE transaction_type = 'borrow')::int AS returned_loans,
+    COUNT(*) FILTER (WHERE transaction_type = 'renew')::int AS renewals,
+    COALESCE(AVG(EXTRACT(EPOCH FROM (due_date - transaction_date)) / 86400) FILTER (WHERE transaction_type = 'borrow'), 0)::float8 AS avg_loan_days,
+    COALESCE(AVG(EXTRACT(EPOCH FROM (returned_date - due_date)) / 86400) FILTER (WHERE NOT renewed), 0)::float8 AS avg_days_late,
+    COALESCE(STDDEV_SAMP(EXTRACT(EPOCH FROM (returned_date - due_date)) / 86400) FILTER (WHERE NOT renewed), 0)::float8 AS stddev_days_late
+FROM (
+    SELECT t.transaction_type, t.transaction_date, t.due_date, t.returned_date,
+        EXISTS (
+            SELECT 1 FROM transactions r
+            WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+              AND r.copy_id IS NOT DISTINCT FROM t.copy_id
+              AND r.transaction_type = 'renew' AND r.id > t.id
+              AND r.transaction_date <= t.returned_date
+        ) AS renewed
+    FROM transactions t
+    WHERE t.transaction_type IN ('borrow', 'renew')
+      AND t.returned_date >= $1
+      AND t.due_date IS NOT NULL
+) loans
+`
+
+type GetLibraryReturnHistoryRow struct {
+	ReturnedLoans  int32   `db:"returned_loans" json:"returned_loans"`
+	Renewals       int32   `db:"renewals" json:"renewals"`
+	AvgLoanDays    float64 `db:"avg_loan_days" json:"avg_loan_days"`
+	AvgDaysLate    float64 `db:"avg_days_late" json:"avg_days_late"`
+	StddevDaysLate float64 `db:"stddev_days_late" json:"stddev_days_late"`
+}
+
+// The same summary as GetBookReturnHistory across every book
+func (q *Queries) GetLibraryReturnHistory(ctx context.Context, returnedDate pgtype.Timestamp) (GetLibraryReturnHistoryRow, error) {
+	row := q.db.QueryRow(ctx, getLibraryReturnHistory, returnedDate)
+	var i GetLibraryReturnHistoryRow
+	err := row.Scan(
+		&i.ReturnedLoans,
+		&i.Renewals,
+		&i.AvgLoanDays,
+		&i.AvgDaysLate,
+		&i.StddevDaysLate,
 	)
 	return i, err
 }
 
+const getRecallableLoanByBook = `-- name: GetRecallableLoanByBook :one
+
+SELECT t.id FROM transactions t
+WHERE t.book_id = $1
+  AND t.transaction_type IN ('borrow', 'renew')
+  AND t.returned_date IS NULL
+  AND t.recalled_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
+ORDER BY t.transaction_date, t.id
+LIMIT 1
+`
+
+// The oldest loan of the book that has not been recalled. A borrow that has been
+// renewed stays open alongside its renewal, so only the renewal can be recalled.
+func (q *Queries) GetRecallableLoanByBook(ctx context.Context, bookID int32) (int32, error) {
+	row := q.db.QueryRow(ctx, getRecallableLoanByBook, bookID)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
 const getRenewalStatisticsByStudent = `-- name: GetRenewalStatisticsByStudent :one
 SELECT 
     student_id,
@@ -122,7 +427,7 @@ func (q *Queries) GetRenewalStatisticsByStudent(ctx context.Context, studentID i
 }
 
 const getTransactionByID = `-- name: GetTransactionByID :one
-SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, s.first_name, s.last_name, s.student_id, b.title, b.author, b.book_id
+SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, t.copy_id, t.loss_status, t.loss_reported_at, t.recalled_at, t.recalled_by, t.original_due_date, s.first_name, s.last_name, s.student_id, s.year_of_study, s.department, b.title, b.author, b.book_id, b.genre, s.locale
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
@@ -145,12 +450,22 @@ type GetTransactionByIDRow struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
 	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	LossStatus      pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt  pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	RecalledAt      pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy      pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
 	FirstName       string           `db:"first_name" json:"first_name"`
 	LastName        string           `db:"last_name" json:"last_name"`
 	StudentID_2     string           `db:"student_id_2" json:"student_id_2"`
+	YearOfStudy     int32            `db:"year_of_study" json:"year_of_study"`
+	Department      pgtype.Text      `db:"department" json:"department"`
 	Title           string           `db:"title" json:"title"`
 	Author          string           `db:"author" json:"author"`
 	BookID_2        string           `db:"book_id_2" json:"book_id_2"`
+	Genre           pgtype.Text      `db:"genre" json:"genre"`
+	Locale          string           `db:"locale" json:"locale"`
 }
 
 func (q *Queries) GetTransactionByID(ctx context.Context, id int32) (GetTransactionByIDRow, error) {
@@ -172,12 +487,104 @@ func (q *Queries) GetTransactionByID(ctx context.Context, id int32) (GetTransact
 		&i.UpdatedAt,
 		&i.ReturnCondition,
 		&i.ConditionNotes,
+		&i.CopyID,
+		&i.LossStatus,
+		&i.LossReportedAt,
+		&i.RecalledAt,
+		&i.RecalledBy,
+		&i.OriginalDueDate,
 		&i.FirstName,
 		&i.LastName,
 		&i.StudentID_2,
+		&i.YearOfStudy,
+		&i.Department,
 		&i.Title,
 		&i.Author,
 		&i.BookID_2,
+		&i.Genre,
+		&i.Locale,
+	)
+	return i, err
+}
+
+const getTransactionByIDForUpdate = `-- name: GetTransactionByIDForUpdate :one
+SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, t.copy_id, t.loss_status, t.loss_reported_at, t.recalled_at, t.recalled_by, t.original_due_date, s.first_name, s.last_name, s.student_id, s.year_of_study, s.department, b.title, b.author, b.book_id, b.genre, s.locale
+FROM transactions t
+JOIN students s ON t.student_id = s.id
+JOIN books b ON t.book_id = b.id
+WHERE t.id = $1
+FOR UPDATE OF t
+`
+
+type GetTransactionByIDForUpdateRow struct {
+	ID              int32            `db:"id" json:"id"`
+	StudentID       int32            `db:"student_id" json:"student_id"`
+	BookID          int32            `db:"book_id" json:"book_id"`
+	TransactionType string           `db:"transaction_type" json:"transaction_type"`
+	TransactionDate pgtype.Timestamp `db:"transaction_date" json:"transaction_date"`
+	DueDate         pgtype.Timestamp `db:"due_date" json:"due_date"`
+	ReturnedDate    pgtype.Timestamp `db:"returned_date" json:"returned_date"`
+	LibrarianID     pgtype.Int4      `db:"librarian_id" json:"librarian_id"`
+	FineAmount      pgtype.Numeric   `db:"fine_amount" json:"fine_amount"`
+	FinePaid        pgtype.Bool      `db:"fine_paid" json:"fine_paid"`
+	Notes           pgtype.Text      `db:"notes" json:"notes"`
+	CreatedAt       pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
+	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	LossStatus      pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt  pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	RecalledAt      pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy      pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
+	FirstName       string           `db:"first_name" json:"first_name"`
+	LastName        string           `db:"last_name" json:"last_name"`
+	StudentID_2     string           `db:"student_id_2" json:"student_id_2"`
+	YearOfStudy     int32            `db:"year_of_study" json:"year_of_study"`
+	Department      pgtype.Text      `db:"department" json:"department"`
+	Title           string           `db:"title" json:"title"`
+	Author          string           `db:"author" json:"author"`
+	BookID_2        string           `db:"book_id_2" json:"book_id_2"`
+	Genre           pgtype.Text      `db:"genre" json:"genre"`
+	Locale          string           `db:"locale" json:"locale"`
+}
+
+func (q *Queries) GetTransactionByIDForUpdate(ctx context.Context, id int32) (GetTransactionByIDForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, getTransactionByIDForUpdate, id)
+	var i GetTransactionByIDForUpdateRow
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.BookID,
+		&i.TransactionType,
+		&i.TransactionDate,
+		&i.DueDate,
+		&i.ReturnedDate,
+		&i.LibrarianID,
+		&i.FineAmount,
+		&i.FinePaid,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReturnCondition,
+		&i.ConditionNotes,
+		&i.CopyID,
+		&i.LossStatus,
+		&i.LossReportedAt,
+		&i.RecalledAt,
+		&i.RecalledBy,
+		&i.OriginalDueDate,
+		&i.FirstName,
+		&i.LastName,
+		&i.StudentID_2,
+		&i.YearOfStudy,
+		&i.Department,
+		&i.Title,
+		&i.Author,
+		&i.BookID_2,
+		&i.Genre,
+		&i.Locale,
 	)
 	return i, err
 }
@@ -202,11 +609,17 @@ func (q *Queries) HasActiveReservationsByOtherStudents(ctx context.Context, arg
 }
 
 const listActiveBorrowings = `-- name: ListActiveBorrowings :many
-SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, s.first_name, s.last_name, s.student_id, b.title, b.author, b.book_id
+
+SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, t.copy_id, t.loss_status, t.loss_reported_at, t.recalled_at, t.recalled_by, t.original_due_date, s.first_name, s.last_name, s.student_id, b.title, b.author, b.book_id
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
 WHERE t.returned_date IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
 ORDER BY t.due_date ASC
 LIMIT $1 OFFSET $2
 `
@@ -232,6 +645,12 @@ type ListActiveBorrowingsRow struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
 	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	LossStatus      pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt  pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	RecalledAt      pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy      pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
 	FirstName       string           `db:"first_name" json:"first_name"`
 	LastName        string           `db:"last_name" json:"last_name"`
 	StudentID_2     string           `db:"student_id_2" json:"student_id_2"`
@@ -240,6 +659,7 @@ type ListActiveBorrowingsRow struct {
 	BookID_2        string           `db:"book_id_2" json:"book_id_2"`
 }
 
+// Open loans, each listed once on its latest open row
 func (q *Queries) ListActiveBorrowings(ctx context.Context, arg ListActiveBorrowingsParams) ([]ListActiveBorrowingsRow, error) {
 	rows, err := q.db.Query(ctx, listActiveBorrowings, arg.Limit, arg.Offset)
 	if err != nil {
@@ -265,6 +685,12 @@ func (q *Queries) ListActiveBorrowings(ctx context.Context, arg ListActiveBorrow
 			&i.UpdatedAt,
 			&i.ReturnCondition,
 			&i.ConditionNotes,
+			&i.CopyID,
+			&i.LossStatus,
+			&i.LossReportedAt,
+			&i.RecalledAt,
+			&i.RecalledBy,
+			&i.OriginalDueDate,
 			&i.FirstName,
 			&i.LastName,
 			&i.StudentID_2,
@@ -283,10 +709,16 @@ func (q *Queries) ListActiveBorrowings(ctx context.Context, arg ListActiveBorrow
 }
 
 const listActiveTransactionsByStudent = `-- name: ListActiveTransactionsByStudent :many
-SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, b.title, b.author, b.book_id
+
+SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, t.copy_id, t.loss_status, t.loss_reported_at, t.recalled_at, t.recalled_by, t.original_due_date, b.title, b.author, b.book_id
 FROM transactions t
 JOIN books b ON t.book_id = b.id
 WHERE t.student_id = $1 AND t.returned_date IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
 ORDER BY t.due_date ASC
 `
 
@@ -306,11 +738,19 @@ type ListActiveTransactionsByStudentRow struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
 	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	LossStatus      pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt  pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	RecalledAt      pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy      pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
 	Title           string           `db:"title" json:"title"`
 	Author          string           `db:"author" json:"author"`
 	BookID_2        string           `db:"book_id_2" json:"book_id_2"`
 }
 
+// A student's open loans. A borrow that has been renewed stays open alongside its
+// renewals, so only the latest open row for a student and book is listed.
 func (q *Queries) ListActiveTransactionsByStudent(ctx context.Context, studentID int32) ([]ListActiveTransactionsByStudentRow, error) {
 	rows, err := q.db.Query(ctx, listActiveTransactionsByStudent, studentID)
 	if err != nil {
@@ -336,6 +776,12 @@ func (q *Queries) ListActiveTransactionsByStudent(ctx context.Context, studentID
 			&i.UpdatedAt,
 			&i.ReturnCondition,
 			&i.ConditionNotes,
+			&i.CopyID,
+			&i.LossStatus,
+			&i.LossReportedAt,
+			&i.RecalledAt,
+			&i.RecalledBy,
+			&i.OriginalDueDate,
 			&i.Title,
 			&i.Author,
 			&i.BookID_2,
@@ -350,12 +796,117 @@ func (q *Queries) ListActiveTransactionsByStudent(ctx context.Context, studentID
 	return items, nil
 }
 
+const listOpenLoanDueDatesByBook = `-- name: ListOpenLoanDueDatesByBook :many
+
+SELECT t.due_date FROM transactions t
+WHERE t.book_id = $1
+  AND t.transaction_type IN ('borrow', 'renew')
+  AND t.returned_date IS NULL
+  AND t.due_date IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
+ORDER BY t.due_date
+`
+
+// Wait time estimate queries
+// Due dates of the copies of a book out on loan. A borrow that has been renewed
+// stays open alongside its renewal, so only the renewal is counted.
+func (q *Queries) ListOpenLoanDueDatesByBook(ctx context.Context, bookID int32) ([]pgtype.Timestamp, error) {
+	rows, err := q.db.Query(ctx, listOpenLoanDueDatesByBook, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []pgtype.Timestamp{}
+	for rows.Next() {
+		var dueDate pgtype.Timestamp
+		if err := rows.Scan(&dueDate); err != nil {
+			return nil, err
+		}
+		items = append(items, dueDate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenOverdueLoans = `-- name: ListOpenOverdueLoans :many
+
+SELECT t.id, t.student_id, t.book_id, t.due_date, t.fine_amount, t.recalled_at, s.year_of_study, s.department, b.genre
+FROM transactions t
+JOIN students s ON t.student_id = s.id
+JOIN books b ON t.book_id = b.id
+WHERE t.transaction_type IN ('borrow', 'renew')
+  AND t.returned_date IS NULL
+  AND t.due_date < NOW()
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
+ORDER BY t.id
+`
+
+type ListOpenOverdueLoansRow struct {
+	ID          int32            `db:"id" json:"id"`
+	StudentID   int32            `db:"student_id" json:"student_id"`
+	BookID      int32            `db:"book_id" json:"book_id"`
+	DueDate     pgtype.Timestamp `db:"due_date" json:"due_date"`
+	FineAmount  pgtype.Numeric   `db:"fine_amount" json:"fine_amount"`
+	RecalledAt  pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	YearOfStudy int32            `db:"year_of_study" json:"year_of_study"`
+	Department  pgtype.Text      `db:"department" json:"department"`
+	Genre       pgtype.Text      `db:"genre" json:"genre"`
+}
+
+// A borrow that has been renewed stays open alongside its renewals until the
+// book is returned, so only the latest open row for a student and book is the loan.
+func (q *Queries) ListOpenOverdueLoans(ctx context.Context) ([]ListOpenOverdueLoansRow, error) {
+	rows, err := q.db.Query(ctx, listOpenOverdueLoans)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOpenOverdueLoansRow{}
+	for rows.Next() {
+		var i ListOpenOverdueLoansRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StudentID,
+			&i.BookID,
+			&i.DueDate,
+			&i.FineAmount,
+			&i.RecalledAt,
+			&i.YearOfStudy,
+			&i.Department,
+			&i.Genre,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listOverdueTransactions = `-- name: ListOverdueTransactions :many
-SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, s.first_name, s.last_name, s.student_id, b.title, b.author, b.book_id
+
+SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, t.copy_id, t.loss_status, t.loss_reported_at, t.recalled_at, t.recalled_by, t.original_due_date, s.first_name, s.last_name, s.student_id, b.title, b.author, b.book_id
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
 WHERE t.due_date < NOW() AND t.returned_date IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
 ORDER BY t.due_date ASC
 `
 
@@ -375,6 +926,12 @@ type ListOverdueTransactionsRow struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
 	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	LossStatus      pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt  pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	RecalledAt      pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy      pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
 	FirstName       string           `db:"first_name" json:"first_name"`
 	LastName        string           `db:"last_name" json:"last_name"`
 	StudentID_2     string           `db:"student_id_2" json:"student_id_2"`
@@ -383,6 +940,7 @@ type ListOverdueTransactionsRow struct {
 	BookID_2        string           `db:"book_id_2" json:"book_id_2"`
 }
 
+// Open loans past their due date, each listed once on its latest open row
 func (q *Queries) ListOverdueTransactions(ctx context.Context) ([]ListOverdueTransactionsRow, error) {
 	rows, err := q.db.Query(ctx, listOverdueTransactions)
 	if err != nil {
@@ -408,6 +966,12 @@ func (q *Queries) ListOverdueTransactions(ctx context.Context) ([]ListOverdueTra
 			&i.UpdatedAt,
 			&i.ReturnCondition,
 			&i.ConditionNotes,
+			&i.CopyID,
+			&i.LossStatus,
+			&i.LossReportedAt,
+			&i.RecalledAt,
+			&i.RecalledBy,
+			&i.OriginalDueDate,
 			&i.FirstName,
 			&i.LastName,
 			&i.StudentID_2,
@@ -426,7 +990,7 @@ func (q *Queries) ListOverdueTransactions(ctx context.Context) ([]ListOverdueTra
 }
 
 const listRenewalsByStudentAndBook = `-- name: ListRenewalsByStudentAndBook :many
-SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, b.title, b.author, b.book_id
+SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, t.copy_id, t.loss_status, t.loss_reported_at, t.recalled_at, t.recalled_by, t.original_due_date, b.title, b.author, b.book_id
 FROM transactions t
 JOIN books b ON t.book_id = b.id
 WHERE t.student_id = $1 AND t.book_id = $2 AND t.transaction_type = 'renew'
@@ -454,6 +1018,12 @@ type ListRenewalsByStudentAndBookRow struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
 	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	LossStatus      pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt  pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	RecalledAt      pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy      pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
 	Title           string           `db:"title" json:"title"`
 	Author          string           `db:"author" json:"author"`
 	BookID_2        string           `db:"book_id_2" json:"book_id_2"`
@@ -484,6 +1054,12 @@ func (q *Queries) ListRenewalsByStudentAndBook(ctx context.Context, arg ListRene
 			&i.UpdatedAt,
 			&i.ReturnCondition,
 			&i.ConditionNotes,
+			&i.CopyID,
+			&i.LossStatus,
+			&i.LossReportedAt,
+			&i.RecalledAt,
+			&i.RecalledBy,
+			&i.OriginalDueDate,
 			&i.Title,
 			&i.Author,
 			&i.BookID_2,
@@ -499,7 +1075,7 @@ func (q *Queries) ListRenewalsByStudentAndBook(ctx context.Context, arg ListRene
 }
 
 const listTransactions = `-- name: ListTransactions :many
-SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, s.first_name, s.last_name, s.student_id, b.title, b.author, b.book_id
+SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, t.copy_id, t.loss_status, t.loss_reported_at, t.recalled_at, t.recalled_by, t.original_due_date, s.first_name, s.last_name, s.student_id, b.title, b.author, b.book_id
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
@@ -528,6 +1104,12 @@ type ListTransactionsRow struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
 	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	LossStatus      pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt  pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	RecalledAt      pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy      pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
 	FirstName       string           `db:"first_name" json:"first_name"`
 	LastName        string           `db:"last_name" json:"last_name"`
 	StudentID_2     string           `db:"student_id_2" json:"student_id_2"`
@@ -561,6 +1143,12 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 			&i.UpdatedAt,
 			&i.ReturnCondition,
 			&i.ConditionNotes,
+			&i.CopyID,
+			&i.LossStatus,
+			&i.LossReportedAt,
+			&i.RecalledAt,
+			&i.RecalledBy,
+			&i.OriginalDueDate,
 			&i.FirstName,
 			&i.LastName,
 			&i.StudentID_2,
@@ -579,7 +1167,7 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 }
 
 const listTransactionsByBook = `-- name: ListTransactionsByBook :many
-SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, s.first_name, s.last_name, s.student_id
+SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, t.copy_id, t.loss_status, t.loss_reported_at, t.recalled_at, t.recalled_by, t.original_due_date, s.first_name, s.last_name, s.student_id
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 WHERE t.book_id = $1
@@ -609,6 +1197,12 @@ type ListTransactionsByBookRow struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
 	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	LossStatus      pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt  pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	RecalledAt      pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy      pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
 	FirstName       string           `db:"first_name" json:"first_name"`
 	LastName        string           `db:"last_name" json:"last_name"`
 	StudentID_2     string           `db:"student_id_2" json:"student_id_2"`
@@ -639,6 +1233,12 @@ func (q *Queries) ListTransactionsByBook(ctx context.Context, arg ListTransactio
 			&i.UpdatedAt,
 			&i.ReturnCondition,
 			&i.ConditionNotes,
+			&i.CopyID,
+			&i.LossStatus,
+			&i.LossReportedAt,
+			&i.RecalledAt,
+			&i.RecalledBy,
+			&i.OriginalDueDate,
 			&i.FirstName,
 			&i.LastName,
 			&i.StudentID_2,
@@ -654,7 +1254,7 @@ func (q *Queries) ListTransactionsByBook(ctx context.Context, arg ListTransactio
 }
 
 const listTransactionsByStudent = `-- name: ListTransactionsByStudent :many
-SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, b.title, b.author, b.book_id
+SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, t.copy_id, t.loss_status, t.loss_reported_at, t.recalled_at, t.recalled_by, t.original_due_date, b.title, b.author, b.book_id
 FROM transactions t
 JOIN books b ON t.book_id = b.id
 WHERE t.student_id = $1
@@ -684,6 +1284,12 @@ type ListTransactionsByStudentRow struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
 	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	LossStatus      pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt  pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	RecalledAt      pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy      pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
 	Title           string           `db:"title" json:"title"`
 	Author          string           `db:"author" json:"author"`
 	BookID_2        string           `db:"book_id_2" json:"book_id_2"`
@@ -714,6 +1320,12 @@ func (q *Queries) ListTransactionsByStudent(ctx context.Context, arg ListTransac
 			&i.UpdatedAt,
 			&i.ReturnCondition,
 			&i.ConditionNotes,
+			&i.CopyID,
+			&i.LossStatus,
+			&i.LossReportedAt,
+			&i.RecalledAt,
+			&i.RecalledBy,
+			&i.OriginalDueDate,
 			&i.Title,
 			&i.Author,
 			&i.BookID_2,
@@ -730,7 +1342,8 @@ func (q *Queries) ListTransactionsByStudent(ctx context.Context, arg ListTransac
 
 const listTransactionsDueSoon = `-- name: ListTransactionsDueSoon :many
 
-SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, s.first_name, s.last_name, s.student_id, s.email, b.title, b.author, b.book_id
+
+SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, t.copy_id, t.loss_status, t.loss_reported_at, t.recalled_at, t.recalled_by, t.original_due_date, s.first_name, s.last_name, s.student_id, s.email, s.locale, b.title, b.author, b.book_id
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
@@ -738,6 +1351,11 @@ WHERE t.due_date >= NOW() AND t.due_date <= NOW() + INTERVAL '3 days'
   AND t.returned_date IS NULL
   AND s.is_active = true
   AND s.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
 ORDER BY t.due_date ASC
 `
 
@@ -757,16 +1375,24 @@ type ListTransactionsDueSoonRow struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
 	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	LossStatus      pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt  pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	RecalledAt      pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy      pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
 	FirstName       string           `db:"first_name" json:"first_name"`
 	LastName        string           `db:"last_name" json:"last_name"`
 	StudentID_2     string           `db:"student_id_2" json:"student_id_2"`
 	Email           pgtype.Text      `db:"email" json:"email"`
+	Locale          string           `db:"locale" json:"locale"`
 	Title           string           `db:"title" json:"title"`
 	Author          string           `db:"author" json:"author"`
 	BookID_2        string           `db:"book_id_2" json:"book_id_2"`
 }
 
 // Notification-related queries for Phase 7.2
+// Loans of active students due within three days, each listed once on its latest open row
 func (q *Queries) ListTransactionsDueSoon(ctx context.Context) ([]ListTransactionsDueSoonRow, error) {
 	rows, err := q.db.Query(ctx, listTransactionsDueSoon)
 	if err != nil {
@@ -792,10 +1418,17 @@ func (q *Queries) ListTransactionsDueSoon(ctx context.Context) ([]ListTransactio
 			&i.UpdatedAt,
 			&i.ReturnCondition,
 			&i.ConditionNotes,
+			&i.CopyID,
+			&i.LossStatus,
+			&i.LossReportedAt,
+			&i.RecalledAt,
+			&i.RecalledBy,
+			&i.OriginalDueDate,
 			&i.FirstName,
 			&i.LastName,
 			&i.StudentID_2,
 			&i.Email,
+			&i.Locale,
 			&i.Title,
 			&i.Author,
 			&i.BookID_2,
@@ -811,13 +1444,19 @@ func (q *Queries) ListTransactionsDueSoon(ctx context.Context) ([]ListTransactio
 }
 
 const listTransactionsOverdue = `-- name: ListTransactionsOverdue :many
-SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, s.first_name, s.last_name, s.student_id, s.email, b.title, b.author, b.book_id
+
+SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, t.copy_id, t.loss_status, t.loss_reported_at, t.recalled_at, t.recalled_by, t.original_due_date, s.first_name, s.last_name, s.student_id, s.email, s.locale, b.title, b.author, b.book_id
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
 WHERE t.due_date < NOW() AND t.returned_date IS NULL
   AND s.is_active = true
   AND s.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transactions r
+    WHERE r.student_id = t.student_id AND r.book_id = t.book_id
+      AND r.transaction_type = 'renew' AND r.returned_date IS NULL AND r.id > t.id
+  )
 ORDER BY t.due_date ASC
 `
 
@@ -837,15 +1476,23 @@ type ListTransactionsOverdueRow struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
 	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	LossStatus      pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt  pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	RecalledAt      pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy      pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
 	FirstName       string           `db:"first_name" json:"first_name"`
 	LastName        string           `db:"last_name" json:"last_name"`
 	StudentID_2     string           `db:"student_id_2" json:"student_id_2"`
 	Email           pgtype.Text      `db:"email" json:"email"`
+	Locale          string           `db:"locale" json:"locale"`
 	Title           string           `db:"title" json:"title"`
 	Author          string           `db:"author" json:"author"`
 	BookID_2        string           `db:"book_id_2" json:"book_id_2"`
 }
 
+// Overdue loans of active students, each listed once on its latest open row
 func (q *Queries) ListTransactionsOverdue(ctx context.Context) ([]ListTransactionsOverdueRow, error) {
 	rows, err := q.db.Query(ctx, listTransactionsOverdue)
 	if err != nil {
@@ -871,10 +1518,17 @@ func (q *Queries) ListTransactionsOverdue(ctx context.Context) ([]ListTransactio
 			&i.UpdatedAt,
 			&i.ReturnCondition,
 			&i.ConditionNotes,
+			&i.CopyID,
+			&i.LossStatus,
+			&i.LossReportedAt,
+			&i.RecalledAt,
+			&i.RecalledBy,
+			&i.OriginalDueDate,
 			&i.FirstName,
 			&i.LastName,
 			&i.StudentID_2,
 			&i.Email,
+			&i.Locale,
 			&i.Title,
 			&i.Author,
 			&i.BookID_2,
@@ -890,7 +1544,7 @@ func (q *Queries) ListTransactionsOverdue(ctx context.Context) ([]ListTransactio
 }
 
 const listTransactionsWithUnpaidFines = `-- name: ListTransactionsWithUnpaidFines :many
-SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, s.first_name, s.last_name, s.student_id, s.email, b.title, b.author, b.book_id
+SELECT t.id, t.student_id, t.book_id, t.transaction_type, t.transaction_date, t.due_date, t.returned_date, t.librarian_id, t.fine_amount, t.fine_paid, t.notes, t.created_at, t.updated_at, t.return_condition, t.condition_notes, t.copy_id, t.loss_status, t.loss_reported_at, t.recalled_at, t.recalled_by, t.original_due_date, s.first_name, s.last_name, s.student_id, s.email, s.locale, b.title, b.author, b.book_id
 FROM transactions t
 JOIN students s ON t.student_id = s.id
 JOIN books b ON t.book_id = b.id
@@ -916,10 +1570,17 @@ type ListTransactionsWithUnpaidFinesRow struct {
 	UpdatedAt       pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 	ReturnCondition pgtype.Text      `db:"return_condition" json:"return_condition"`
 	ConditionNotes  pgtype.Text      `db:"condition_notes" json:"condition_notes"`
+	CopyID          pgtype.Int4      `db:"copy_id" json:"copy_id"`
+	LossStatus      pgtype.Text      `db:"loss_status" json:"loss_status"`
+	LossReportedAt  pgtype.Timestamp `db:"loss_reported_at" json:"loss_reported_at"`
+	RecalledAt      pgtype.Timestamp `db:"recalled_at" json:"recalled_at"`
+	RecalledBy      pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+	OriginalDueDate pgtype.Timestamp `db:"original_due_date" json:"original_due_date"`
 	FirstName       string           `db:"first_name" json:"first_name"`
 	LastName        string           `db:"last_name" json:"last_name"`
 	StudentID_2     string           `db:"student_id_2" json:"student_id_2"`
 	Email           pgtype.Text      `db:"email" json:"email"`
+	Locale          string           `db:"locale" json:"locale"`
 	Title           string           `db:"title" json:"title"`
 	Author          string           `db:"author" json:"author"`
 	BookID_2        string           `db:"book_id_2" json:"book_id_2"`
@@ -950,10 +1611,17 @@ func (q *Queries) ListTransactionsWithUnpaidFines(ctx context.Context) ([]ListTr
 			&i.UpdatedAt,
 			&i.ReturnCondition,
 			&i.ConditionNotes,
+			&i.CopyID,
+			&i.LossStatus,
+			&i.LossReportedAt,
+			&i.RecalledAt,
+			&i.RecalledBy,
+			&i.OriginalDueDate,
 			&i.FirstName,
 			&i.LastName,
 			&i.StudentID_2,
 			&i.Email,
+			&i.Locale,
 			&i.Title,
 			&i.Author,
 			&i.BookID_2,
@@ -968,22 +1636,56 @@ func (q *Queries) ListTransactionsWithUnpaidFines(ctx context.Context) ([]ListTr
 	return items, nil
 }
 
-const payTransactionFine = `-- name: PayTransactionFine :exec
+const recallTransaction = `-- name: RecallTransaction :one
+
 UPDATE transactions
-SET fine_paid = true, updated_at = NOW()
-WHERE id = $1
+SET original_due_date = due_date, due_date = $2, recalled_at = NOW(), recalled_by = $3, updated_at = NOW()
+WHERE id = $1 AND returned_date IS NULL AND recalled_at IS NULL
+RETURNING id, student_id, book_id, transaction_type, transaction_date, due_date, returned_date, librarian_id, fine_amount, fine_paid, notes, created_at, updated_at, return_condition, condition_notes, copy_id, loss_status, loss_reported_at, recalled_at, recalled_by, original_due_date
 `
 
-func (q *Queries) PayTransactionFine(ctx context.Context, id int32) error {
-	_, err := q.db.Exec(ctx, payTransactionFine, id)
-	return err
+type RecallTransactionParams struct {
+	ID         int32            `db:"id" json:"id"`
+	DueDate    pgtype.Timestamp `db:"due_date" json:"due_date"`
+	RecalledBy pgtype.Int4      `db:"recalled_by" json:"recalled_by"`
+}
+
+// Brings the due date of an open loan forward, keeping the due date it had before.
+// A loan is only recalled once.
+func (q *Queries) RecallTransaction(ctx context.Context, arg RecallTransactionParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, recallTransaction, arg.ID, arg.DueDate, arg.RecalledBy)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.BookID,
+		&i.TransactionType,
+		&i.TransactionDate,
+		&i.DueDate,
+		&i.ReturnedDate,
+		&i.LibrarianID,
+		&i.FineAmount,
+		&i.FinePaid,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReturnCondition,
+		&i.ConditionNotes,
+		&i.CopyID,
+		&i.LossStatus,
+		&i.LossReportedAt,
+		&i.RecalledAt,
+		&i.RecalledBy,
+		&i.OriginalDueDate,
+	)
+	return i, err
 }
 
 const returnBook = `-- name: ReturnBook :one
 UPDATE transactions
 SET returned_date = NOW(), fine_amount = $2, return_condition = $3, condition_notes = $4, updated_at = NOW()
 WHERE id = $1
-RETURNING id, student_id, book_id, transaction_type, transaction_date, due_date, returned_date, librarian_id, fine_amount, fine_paid, notes, created_at, updated_at, return_condition, condition_notes
+RETURNING id, student_id, book_id, transaction_type, transaction_date, due_date, returned_date, librarian_id, fine_amount, fine_paid, notes, created_at, updated_at, return_condition, condition_notes, copy_id, loss_status, loss_reported_at, recalled_at, recalled_by, original_due_date
 `
 
 type ReturnBookParams struct {
@@ -1017,6 +1719,69 @@ func (q *Queries) ReturnBook(ctx context.Context, arg ReturnBookParams) (Transac
 		&i.UpdatedAt,
 		&i.ReturnCondition,
 		&i.ConditionNotes,
+		&i.CopyID,
+		&i.LossStatus,
+		&i.LossReportedAt,
+		&i.RecalledAt,
+		&i.RecalledBy,
+		&i.OriginalDueDate,
+	)
+	return i, err
+}
+
+const setTransactionFinePaid = `-- name: SetTransactionFinePaid :exec
+UPDATE transactions
+SET fine_paid = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetTransactionFinePaidParams struct {
+	ID       int32       `db:"id" json:"id"`
+	FinePaid pgtype.Bool `db:"fine_paid" json:"fine_paid"`
+}
+
+func (q *Queries) SetTransactionFinePaid(ctx context.Context, arg SetTransactionFinePaidParams) error {
+	_, err := q.db.Exec(ctx, setTransactionFinePaid, arg.ID, arg.FinePaid)
+	return err
+}
+
+const setTransactionLossStatus = `-- name: SetTransactionLossStatus :one
+UPDATE transactions
+SET loss_status = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, student_id, book_id, transaction_type, transaction_date, due_date, returned_date, librarian_id, fine_amount, fine_paid, notes, created_at, updated_at, return_condition, condition_notes, copy_id, loss_status, loss_reported_at, recalled_at, recalled_by, original_due_date
+`
+
+type SetTransactionLossStatusParams struct {
+	ID         int32       `db:"id" json:"id"`
+	LossStatus pgtype.Text `db:"loss_status" json:"loss_status"`
+}
+
+func (q *Queries) SetTransactionLossStatus(ctx context.Context, arg SetTransactionLossStatusParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, setTransactionLossStatus, arg.ID, arg.LossStatus)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.StudentID,
+		&i.BookID,
+		&i.TransactionType,
+		&i.TransactionDate,
+		&i.DueDate,
+		&i.ReturnedDate,
+		&i.LibrarianID,
+		&i.FineAmount,
+		&i.FinePaid,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReturnCondition,
+		&i.ConditionNotes,
+		&i.CopyID,
+		&i.LossStatus,
+		&i.LossReportedAt,
+		&i.RecalledAt,
+		&i.RecalledBy,
+		&i.OriginalDueDate,
 	)
 	return i, err
 }
@@ -1041,7 +1806,7 @@ const updateTransactionReturn = `-- name: UpdateTransactionReturn :one
 UPDATE transactions
 SET returned_date = NOW(), fine_amount = $2, updated_at = NOW()
 WHERE id = $1
-RETURNING id, student_id, book_id, transaction_type, transaction_date, due_date, returned_date, librarian_id, fine_amount, fine_paid, notes, created_at, updated_at, return_condition, condition_notes
+RETURNING id, student_id, book_id, transaction_type, transaction_date, due_date, returned_date, librarian_id, fine_amount, fine_paid, notes, created_at, updated_at, return_condition, condition_notes, copy_id, loss_status, loss_reported_at, recalled_at, recalled_by, original_due_date
 `
 
 type UpdateTransactionReturnParams struct {
@@ -1068,6 +1833,12 @@ func (q *Queries) UpdateTransactionReturn(ctx context.Context, arg UpdateTransac
 		&i.UpdatedAt,
 		&i.ReturnCondition,
 		&i.ConditionNotes,
+		&i.CopyID,
+		&i.LossStatus,
+		&i.LossReportedAt,
+		&i.RecalledAt,
+		&i.RecalledBy,
+		&i.OriginalDueDate,
 	)
 	return i, err
 }
@@ -26,7 +26,7 @@ func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (username, email, password_hash, role)
 VALUES ($1, $2, $3, $4)
-RETURNING id, username, email, password_hash, role, is_active, last_login, deleted_at, created_at, updated_at
+RETURNING id, username, email, password_hash, role, is_active, last_login, deleted_at, created_at, updated_at, locale
 `
 
 type CreateUserParams struct {
@@ -55,12 +55,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, password_hash, role, is_active, last_login, deleted_at, created_at, updated_at FROM users
+SELECT id, username, email, password_hash, role, is_active, last_login, deleted_at, created_at, updated_at, locale FROM users
 WHERE email = $1 AND deleted_at IS NULL
 `
 
@@ -78,12 +79,13 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, email, password_hash, role, is_active, last_login, deleted_at, created_at, updated_at FROM users
+SELECT id, username, email, password_hash, role, is_active, last_login, deleted_at, created_at, updated_at, locale FROM users
 WHERE id = $1 AND deleted_at IS NULL
 `
 
@@ -101,12 +103,13 @@ func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, email, password_hash, role, is_active, last_login, deleted_at, created_at, updated_at FROM users
+SELECT id, username, email, password_hash, role, is_active, last_login, deleted_at, created_at, updated_at, locale FROM users
 WHERE username = $1 AND deleted_at IS NULL
 `
 
@@ -124,12 +127,13 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
 	)
 	return i, err
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, username, email, password_hash, role, is_active, last_login, deleted_at, created_at, updated_at FROM users
+SELECT id, username, email, password_hash, role, is_active, last_login, deleted_at, created_at, updated_at, locale FROM users
 WHERE deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
@@ -160,6 +164,7 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Locale,
 		); err != nil {
 			return nil, err
 		}
@@ -186,7 +191,7 @@ const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET username = $2, email = $3, password_hash = $4, role = $5, updated_at = NOW()
 WHERE id = $1 AND deleted_at IS NULL
-RETURNING id, username, email, password_hash, role, is_active, last_login, deleted_at, created_at, updated_at
+RETURNING id, username, email, password_hash, role, is_active, last_login, deleted_at, created_at, updated_at, locale
 `
 
 type UpdateUserParams struct {
@@ -217,6 +222,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Locale,
 	)
 	return i, err
 }
@@ -228,18 +228,16 @@ func TestQueries_BookOperations(t *testing.T) {
 
 	// Test UpdateBook
 	updatedBook, err := q.UpdateBook(ctx, queries.UpdateBookParams{
-		ID:              book.ID,
-		BookID:          "BOOK001",
-		Isbn:            pgtype.Text{String: "978-1234567890", Valid: true},
-		Title:           "Updated Test Book",
-		Author:          "Updated Test Author",
-		Publisher:       pgtype.Text{String: "Updated Publisher", Valid: true},
-		PublishedYear:   pgtype.Int4{Int32: 2024, Valid: true},
-		Genre:           pgtype.Text{String: "Non-Fiction", Valid: true},
-		Description:     pgtype.Text{String: "An updated test book", Valid: true},
-		TotalCopies:     pgtype.Int4{Int32: 10, Valid: true},
-		AvailableCopies: pgtype.Int4{Int32: 8, Valid: true},
-		ShelfLocation:   pgtype.Text{String: "B2-002", Valid: true},
+		ID:            book.ID,
+		BookID:        "BOOK001",
+		Isbn:          pgtype.Text{String: "978-1234567890", Valid: true},
+		Title:         "Updated Test Book",
+		Author:        "Updated Test Author",
+		Publisher:     pgtype.Text{String: "Updated Publisher", Valid: true},
+		PublishedYear: pgtype.Int4{Int32: 2024, Valid: true},
+		Genre:         pgtype.Text{String: "Non-Fiction", Valid: true},
+		Description:   pgtype.Text{String: "An updated test book", Valid: true},
+		ShelfLocation: pgtype.Text{String: "B2-002", Valid: true},
 	})
 	require.NoError(t, err)
 	assert.Equal(t, "Updated Test Book", updatedBook.Title)
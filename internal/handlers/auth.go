@@ -459,6 +459,47 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	})
 }
 
+// UpdateLocale changes the language the caller's notifications are written in
+func (h *AuthHandler) UpdateLocale(c *gin.Context) {
+	var req models.UpdateLocaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error": gin.H{
+				"code":    "VALIDATION_ERROR",
+				"message": "Invalid request data",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+
+	var err error
+	if middleware.GetUserType(c) == "student" {
+		err = h.userService.UpdateStudentLocale(userID, req.Locale)
+	} else {
+		err = h.userService.UpdateLocale(userID, req.Locale)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error": gin.H{
+				"code":    "UPDATE_ERROR",
+				"message": "Error updating locale",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"locale": req.Locale},
+		"message": "Locale updated successfully",
+	})
+}
+
 func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	var req models.ForgotPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
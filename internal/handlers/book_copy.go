@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// BookCopyHandler handles HTTP requests for physical book copies
+type BookCopyHandler struct {
+	bookCopyService services.BookCopyServiceInterface
+}
+
+// NewBookCopyHandler creates a new book copy handler
+func NewBookCopyHandler(bookCopyService services.BookCopyServiceInterface) *BookCopyHandler {
+	return &BookCopyHandler{
+		bookCopyService: bookCopyService,
+	}
+}
+
+// ListCopies lists the physical copies of a book
+// @Summary List book copies
+// @Description List all physical copies of a book with their barcode, condition and status
+// @Tags books
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {object} SuccessResponse{data=[]models.BookCopyResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/books/{id}/copies [get]
+func (h *BookCopyHandler) ListCopies(c *gin.Context) {
+	bookID, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid book ID",
+			},
+		})
+		return
+	}
+
+	copies, err := h.bookCopyService.ListCopies(c.Request.Context(), int32(bookID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve book copies",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    copies,
+	})
+}
+
+// AddCopy adds a physical copy to a book
+// @Summary Add a book copy
+// @Description Register a new physical copy of a book. A barcode is generated when none is given.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param copy body models.CreateBookCopyRequest true "Copy data"
+// @Success 201 {object} SuccessResponse{data=models.BookCopyResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/books/{id}/copies [post]
+func (h *BookCopyHandler) AddCopy(c *gin.Context) {
+	bookID, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid book ID",
+			},
+		})
+		return
+	}
+
+	var req models.CreateBookCopyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	bookCopy, err := h.bookCopyService.AddCopy(c.Request.Context(), int32(bookID), req)
+	if err != nil {
+		h.handleError(c, err, "Failed to add book copy")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    bookCopy,
+		Message: "Book copy added successfully",
+	})
+}
+
+// GetCopyByBarcode retrieves a physical copy by its barcode
+// @Summary Get a book copy by barcode
+// @Description Look up a physical copy by scanning its barcode
+// @Tags books
+// @Produce json
+// @Param barcode path string true "Copy barcode"
+// @Success 200 {object} SuccessResponse{data=models.BookCopyResponse}
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/copies/barcode/{barcode} [get]
+func (h *BookCopyHandler) GetCopyByBarcode(c *gin.Context) {
+	bookCopy, err := h.bookCopyService.GetCopyByBarcode(c.Request.Context(), c.Param("barcode"))
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve book copy")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    bookCopy,
+	})
+}
+
+// UpdateCopy updates a physical copy
+// @Summary Update a book copy
+// @Description Update the condition, shelf location, status or notes of a physical copy
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path int true "Copy ID"
+// @Param copy body models.UpdateBookCopyRequest true "Copy data"
+// @Success 200 {object} SuccessResponse{data=models.BookCopyResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/copies/{id} [put]
+func (h *BookCopyHandler) UpdateCopy(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid copy ID",
+			},
+		})
+		return
+	}
+
+	var req models.UpdateBookCopyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	bookCopy, err := h.bookCopyService.UpdateCopy(c.Request.Context(), int32(id), req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update book copy")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    bookCopy,
+		Message: "Book copy updated successfully",
+	})
+}
+
+// handleError maps book copy service errors to HTTP responses
+func (h *BookCopyHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case isValidationError(err):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: err.Error(),
+			},
+		})
+	case isConflictError(err):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "CONFLICT_ERROR",
+				Message: err.Error(),
+			},
+		})
+	case isNotFoundError(err):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: err.Error(),
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// CalendarHandler handles HTTP requests for the library calendar
+type CalendarHandler struct {
+	calendarService services.CalendarServiceInterface
+}
+
+// NewCalendarHandler creates a new library calendar handler
+func NewCalendarHandler(calendarService services.CalendarServiceInterface) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// GetOpeningHours returns the weekly opening hours
+// @Summary Get opening hours
+// @Description Get the library opening hours for each day of the week (0 is Sunday)
+// @Tags calendar
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]models.OpeningHoursResponse}
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/calendar/hours [get]
+func (h *CalendarHandler) GetOpeningHours(c *gin.Context) {
+	hours, err := h.calendarService.GetOpeningHours(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve opening hours",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    hours,
+	})
+}
+
+// UpdateOpeningHours sets the opening hours for a day of the week
+// @Summary Update opening hours
+// @Description Set the opening hours for a day of the week, or mark it closed
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param day path int true "Day of week (0 is Sunday)"
+// @Param hours body models.UpdateOpeningHoursRequest true "Opening hours"
+// @Success 200 {object} SuccessResponse{data=models.OpeningHoursResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/calendar/hours/{day} [put]
+func (h *CalendarHandler) UpdateOpeningHours(c *gin.Context) {
+	day, err := strconv.Atoi(c.Param("day"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid day of week",
+			},
+		})
+		return
+	}
+
+	var req models.UpdateOpeningHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	hours, err := h.calendarService.UpdateOpeningHours(c.Request.Context(), day, req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update opening hours")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    hours,
+		Message: "Opening hours updated successfully",
+	})
+}
+
+// ListClosures lists holidays and breaks
+// @Summary List library closures
+// @Description List the holidays and breaks when the library is closed
+// @Tags calendar
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]models.LibraryClosureResponse}
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/calendar/closures [get]
+func (h *CalendarHandler) ListClosures(c *gin.Context) {
+	closures, err := h.calendarService.ListClosures(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve library closures",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    closures,
+	})
+}
+
+// GetClosure retrieves a closure
+// @Summary Get a library closure
+// @Description Get a holiday or break by ID
+// @Tags calendar
+// @Produce json
+// @Param id path int true "Closure ID"
+// @Success 200 {object} SuccessResponse{data=models.LibraryClosureResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/calendar/closures/{id} [get]
+func (h *CalendarHandler) GetClosure(c *gin.Context) {
+	id, ok := h.parseClosureID(c)
+	if !ok {
+		return
+	}
+
+	closure, err := h.calendarService.GetClosure(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve library closure")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    closure,
+	})
+}
+
+// CreateClosure adds a holiday or break
+// @Summary Create a library closure
+// @Description Add a one-off holiday or break, or an annual closure on the same dates every year
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param closure body models.CreateLibraryClosureRequest true "Closure data"
+// @Success 201 {object} SuccessResponse{data=models.LibraryClosureResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/calendar/closures [post]
+func (h *CalendarHandler) CreateClosure(c *gin.Context) {
+	var req models.CreateLibraryClosureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	closure, err := h.calendarService.CreateClosure(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create library closure")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    closure,
+		Message: "Library closure created successfully",
+	})
+}
+
+// UpdateClosure updates a holiday or break
+// @Summary Update a library closure
+// @Description Update the name, dates or recurrence of a closure
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param id path int true "Closure ID"
+// @Param closure body models.UpdateLibraryClosureRequest true "Closure data"
+// @Success 200 {object} SuccessResponse{data=models.LibraryClosureResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/calendar/closures/{id} [put]
+func (h *CalendarHandler) UpdateClosure(c *gin.Context) {
+	id, ok := h.parseClosureID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateLibraryClosureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	closure, err := h.calendarService.UpdateClosure(c.Request.Context(), id, req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update library closure")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    closure,
+		Message: "Library closure updated successfully",
+	})
+}
+
+// DeleteClosure removes a holiday or break
+// @Summary Delete a library closure
+// @Description Remove a holiday or break from the calendar
+// @Tags calendar
+// @Produce json
+// @Param id path int true "Closure ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/calendar/closures/{id} [delete]
+func (h *CalendarHandler) DeleteClosure(c *gin.Context) {
+	id, ok := h.parseClosureID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.calendarService.DeleteClosure(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "Failed to delete library closure")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Message: "Library closure deleted successfully",
+	})
+}
+
+// parseClosureID reads the closure ID path parameter, writing a 400 response when invalid
+func (h *CalendarHandler) parseClosureID(c *gin.Context) (int32, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid closure ID",
+			},
+		})
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// handleError maps calendar service errors to HTTP responses
+func (h *CalendarHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case isValidationError(err):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: err.Error(),
+			},
+		})
+	case isNotFoundError(err):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: err.Error(),
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
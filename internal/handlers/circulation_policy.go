@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// CirculationPolicyHandler handles HTTP requests for the circulation policy matrix
+type CirculationPolicyHandler struct {
+	policyService services.CirculationPolicyServiceInterface
+}
+
+// NewCirculationPolicyHandler creates a new circulation policy handler
+func NewCirculationPolicyHandler(policyService services.CirculationPolicyServiceInterface) *CirculationPolicyHandler {
+	return &CirculationPolicyHandler{
+		policyService: policyService,
+	}
+}
+
+// ListPolicies lists all circulation policies
+// @Summary List circulation policies
+// @Description List the circulation policy matrix in resolution order (highest priority first)
+// @Tags circulation-policies
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]models.CirculationPolicyResponse}
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/circulation-policies [get]
+func (h *CirculationPolicyHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.policyService.ListPolicies(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve circulation policies",
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    policies,
+	})
+}
+
+// GetPolicy retrieves a circulation policy
+// @Summary Get a circulation policy
+// @Description Get a circulation policy by ID
+// @Tags circulation-policies
+// @Produce json
+// @Param id path int true "Policy ID"
+// @Success 200 {object} SuccessResponse{data=models.CirculationPolicyResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/circulation-policies/{id} [get]
+func (h *CirculationPolicyHandler) GetPolicy(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.policyService.GetPolicy(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve circulation policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    policy,
+	})
+}
+
+// CreatePolicy creates a circulation policy
+// @Summary Create a circulation policy
+// @Description Add a rule to the circulation policy matrix. Omitted matching fields match any patron or item.
+// @Tags circulation-policies
+// @Accept json
+// @Produce json
+// @Param policy body models.CreateCirculationPolicyRequest true "Policy data"
+// @Success 201 {object} SuccessResponse{data=models.CirculationPolicyResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/circulation-policies [post]
+func (h *CirculationPolicyHandler) CreatePolicy(c *gin.Context) {
+	var req models.CreateCirculationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	policy, err := h.policyService.CreatePolicy(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create circulation policy")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    policy,
+		Message: "Circulation policy created successfully",
+	})
+}
+
+// UpdatePolicy updates a circulation policy
+// @Summary Update a circulation policy
+// @Description Update a circulation policy. Send an empty string (or 0 for year_of_study) to clear a matching field.
+// @Tags circulation-policies
+// @Accept json
+// @Produce json
+// @Param id path int true "Policy ID"
+// @Param policy body models.UpdateCirculationPolicyRequest true "Policy data"
+// @Success 200 {object} SuccessResponse{data=models.CirculationPolicyResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/circulation-policies/{id} [put]
+func (h *CirculationPolicyHandler) UpdatePolicy(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCirculationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	policy, err := h.policyService.UpdatePolicy(c.Request.Context(), id, req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update circulation policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    policy,
+		Message: "Circulation policy updated successfully",
+	})
+}
+
+// DeletePolicy deletes a circulation policy
+// @Summary Delete a circulation policy
+// @Description Remove a rule from the circulation policy matrix
+// @Tags circulation-policies
+// @Produce json
+// @Param id path int true "Policy ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/circulation-policies/{id} [delete]
+func (h *CirculationPolicyHandler) DeletePolicy(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.policyService.DeletePolicy(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "Failed to delete circulation policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Message: "Circulation policy deleted successfully",
+	})
+}
+
+// parseID reads the policy ID path parameter, writing a 400 response when invalid
+func (h *CirculationPolicyHandler) parseID(c *gin.Context) (int32, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid policy ID",
+			},
+		})
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// handleError maps circulation policy service errors to HTTP responses
+func (h *CirculationPolicyHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case isValidationError(err):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: err.Error(),
+			},
+		})
+	case isNotFoundError(err):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: err.Error(),
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// maxBounceMessageBytes is the largest bounce message the webhook accepts;
+// bounces quote at most the headers of the message that bounced
+const maxBounceMessageBytes = 10 << 20
+
+// EmailBounceHandler receives bounce reports and shows librarians the students
+// whose email keeps bouncing
+type EmailBounceHandler struct {
+	bounces services.EmailBounceServiceInterface
+}
+
+// NewEmailBounceHandler creates a new email bounce handler
+func NewEmailBounceHandler(bounces services.EmailBounceServiceInterface) *EmailBounceHandler {
+	return &EmailBounceHandler{
+		bounces: bounces,
+	}
+}
+
+// ReceiveBounce processes a bounce message forwarded by the mail server
+// @Summary Email bounce webhook
+// @Description Called with a raw bounce message (a delivery status notification) received at the bounce address. Each failed recipient is matched to its email delivery, which is marked bounced; hard bounces count against the address, which is suppressed after repeated hard bounces. Bounces that match no delivery are ignored.
+// @Tags email
+// @Accept plain
+// @Produce json
+// @Param token query string true "Webhook token"
+// @Success 200 {object} SuccessResponse{data=models.EmailBounceResult}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email/bounces [post]
+func (h *EmailBounceHandler) ReceiveBounce(c *gin.Context) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxBounceMessageBytes)
+	result, err := h.bounces.HandleWebhook(c.Request.Context(), c.Query("token"), body)
+	if err != nil {
+		h.handleError(c, err, "Failed to process bounce")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    result,
+		Message: "Bounce received",
+	})
+}
+
+// ListStudentEmailIssues lists students whose email address needs fixing
+// @Summary List students with bouncing email
+// @Description List students whose email address on file has been flagged invalid after repeated hard bounces, most recently flagged first. No email is sent to these addresses; a student drops off the list once their address is changed.
+// @Tags students
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} SuccessResponse{data=models.StudentEmailIssueListResponse}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/students/email-issues [get]
+func (h *EmailBounceHandler) ListStudentEmailIssues(c *gin.Context) {
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	issues, err := h.bounces.ListStudentEmailIssues(c.Request.Context(), page, limit)
+	if err != nil {
+		h.handleError(c, err, "Failed to list students with bouncing email")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    issues,
+	})
+}
+
+// ClearStudentSuppression lets email go to a student's address again
+// @Summary Clear a student's email suppression
+// @Description Start emailing a student's current address again after it was flagged invalid, for when the address works without being changed. Its hard bounce count starts again from nothing.
+// @Tags students
+// @Produce json
+// @Param id path int true "Student ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/students/{id}/email-suppression [delete]
+func (h *EmailBounceHandler) ClearStudentSuppression(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid student ID",
+				Details: "Student ID must be a valid integer",
+			},
+		})
+		return
+	}
+
+	if err := h.bounces.ClearStudentSuppression(c.Request.Context(), int32(id)); err != nil {
+		h.handleError(c, err, "Failed to clear email suppression")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Message: "Email suppression cleared",
+	})
+}
+
+func (h *EmailBounceHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidBounceWebhookToken):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "UNAUTHORIZED",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrInvalidBounceMessage), errors.Is(err, services.ErrNotBounceReport):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrEmailSuppressionNotFound), err.Error() == "student not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: err.Error(),
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: message,
+				Details: err.Error(),
+			},
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// EmailQueueHandler lets admins watch the email queue and retry or cancel emails
+type EmailQueueHandler struct {
+	emailQueue services.EmailQueueServiceInterface
+}
+
+// NewEmailQueueHandler creates a new email queue handler
+func NewEmailQueueHandler(emailQueue services.EmailQueueServiceInterface) *EmailQueueHandler {
+	return &EmailQueueHandler{
+		emailQueue: emailQueue,
+	}
+}
+
+// GetStats reports how the email queue is doing
+// @Summary Get email queue statistics
+// @Description Count queued emails by status and report average processing time and attempts for emails queued in a period, the last 7 days by default
+// @Tags email-queue
+// @Produce json
+// @Param from query string false "Start of the period (RFC3339)"
+// @Param to query string false "End of the period (RFC3339)"
+// @Success 200 {object} SuccessResponse{data=models.EmailQueueStats}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-queue/stats [get]
+func (h *EmailQueueHandler) GetStats(c *gin.Context) {
+	to := time.Now()
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Success: false,
+				Error: ErrorDetail{
+					Code:    "INVALID_DATE",
+					Message: "Invalid to date, expected RFC3339",
+					Details: err.Error(),
+				},
+			})
+			return
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -7)
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Success: false,
+				Error: ErrorDetail{
+					Code:    "INVALID_DATE",
+					Message: "Invalid from date, expected RFC3339",
+					Details: err.Error(),
+				},
+			})
+			return
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INVALID_DATE_RANGE",
+				Message: "From date must be before to date",
+			},
+		})
+		return
+	}
+
+	stats, err := h.emailQueue.GetQueueStats(c.Request.Context(), from, to)
+	if err != nil {
+		h.handleError(c, err, "Failed to get email queue statistics")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    stats,
+		Message: "Email queue statistics retrieved successfully",
+	})
+}
+
+// RetryItem puts a failed or cancelled email back in the queue
+// @Summary Retry a queued email
+// @Description Put a failed or cancelled email back in the queue to be sent now, with a fresh set of attempts
+// @Tags email-queue
+// @Produce json
+// @Param id path int true "Queue item ID"
+// @Success 200 {object} SuccessResponse{data=models.EmailQueueItem}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-queue/{id}/retry [post]
+func (h *EmailQueueHandler) RetryItem(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.emailQueue.RetryQueueItem(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retry email")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    item,
+		Message: "Email queued for retry",
+	})
+}
+
+// CancelItem stops a queued email being sent
+// @Summary Cancel a queued email
+// @Description Cancel an email that is still waiting in the queue. Emails a worker has already picked up cannot be cancelled.
+// @Tags email-queue
+// @Produce json
+// @Param id path int true "Queue item ID"
+// @Success 200 {object} SuccessResponse{data=models.EmailQueueItem}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-queue/{id}/cancel [post]
+func (h *EmailQueueHandler) CancelItem(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.emailQueue.CancelQueueItem(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to cancel email")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    item,
+		Message: "Email cancelled successfully",
+	})
+}
+
+func (h *EmailQueueHandler) parseID(c *gin.Context) (int32, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INVALID_ID",
+				Message: "Invalid queue item ID",
+			},
+		})
+		return 0, false
+	}
+	return int32(id), true
+}
+
+func (h *EmailQueueHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrQueueItemNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Email queue item not found",
+			},
+		})
+	case errors.Is(err, services.ErrQueueItemNotCancellable), errors.Is(err, services.ErrQueueItemNotRetryable):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INVALID_QUEUE_STATUS",
+				Message: err.Error(),
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: message,
+				Details: err.Error(),
+			},
+		})
+	}
+}
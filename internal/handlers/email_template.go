@@ -0,0 +1,524 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ngenohkevin/lms/internal/middleware"
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// EmailTemplateHandler lets librarians edit, version, preview and publish email templates
+type EmailTemplateHandler struct {
+	templates services.EmailTemplateServiceInterface
+}
+
+// NewEmailTemplateHandler creates a new email template handler
+func NewEmailTemplateHandler(templates services.EmailTemplateServiceInterface) *EmailTemplateHandler {
+	return &EmailTemplateHandler{
+		templates: templates,
+	}
+}
+
+// ListTemplates lists the stored email templates
+// @Summary List email templates
+// @Description List the stored email templates with their published content
+// @Tags email-templates
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]models.EmailTemplateResponse}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-templates [get]
+func (h *EmailTemplateHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.templates.ListTemplates(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "Failed to list email templates")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    templates,
+		Message: "Email templates retrieved successfully",
+	})
+}
+
+// GetTemplate gets a stored email template
+// @Summary Get an email template
+// @Description Get a stored email template with its published content
+// @Tags email-templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Param locale query string false "Template locale (en or sw), English by default"
+// @Success 200 {object} SuccessResponse{data=models.EmailTemplateResponse}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-templates/{name} [get]
+func (h *EmailTemplateHandler) GetTemplate(c *gin.Context) {
+	locale, ok := h.parseLocale(c)
+	if !ok {
+		return
+	}
+
+	template, err := h.templates.GetTemplate(c.Request.Context(), c.Param("name"), locale)
+	if err != nil {
+		h.handleError(c, err, "Failed to get email template")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    template,
+		Message: "Email template retrieved successfully",
+	})
+}
+
+// CreateTemplate stores a new email template
+// @Summary Create an email template
+// @Description Create an email template. Its content is saved as version 1, as a draft unless publish is set.
+// @Tags email-templates
+// @Accept json
+// @Produce json
+// @Param request body models.CreateEmailTemplateRequest true "Template"
+// @Success 201 {object} SuccessResponse{data=models.EmailTemplateResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-templates [post]
+func (h *EmailTemplateHandler) CreateTemplate(c *gin.Context) {
+	var req models.CreateEmailTemplateRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	template, err := h.templates.CreateTemplate(c.Request.Context(), req, int32(middleware.GetUserID(c)))
+	if err != nil {
+		h.handleError(c, err, "Failed to create email template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    template,
+		Message: "Email template created successfully",
+	})
+}
+
+// UpdateTemplate saves new content for an email template
+// @Summary Update an email template
+// @Description Save new content for an email template as its next version. The version stays a draft unless publish is set; the published version keeps being sent until then.
+// @Tags email-templates
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param locale query string false "Template locale (en or sw), English by default"
+// @Param request body models.EmailTemplateVersionRequest true "Template content"
+// @Success 201 {object} SuccessResponse{data=models.EmailTemplateVersionResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-templates/{name} [put]
+func (h *EmailTemplateHandler) UpdateTemplate(c *gin.Context) {
+	locale, ok := h.parseLocale(c)
+	if !ok {
+		return
+	}
+
+	var req models.EmailTemplateVersionRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	version, err := h.templates.CreateVersion(c.Request.Context(), c.Param("name"), locale, req, int32(middleware.GetUserID(c)))
+	if err != nil {
+		h.handleError(c, err, "Failed to update email template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    version,
+		Message: "Email template version saved successfully",
+	})
+}
+
+// DeleteTemplate deletes an email template
+// @Summary Delete an email template
+// @Description Delete an email template and all its versions. The built-in templates cannot be deleted.
+// @Tags email-templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Param locale query string false "Template locale (en or sw), English by default"
+// @Success 200 {object} SuccessResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-templates/{name} [delete]
+func (h *EmailTemplateHandler) DeleteTemplate(c *gin.Context) {
+	locale, ok := h.parseLocale(c)
+	if !ok {
+		return
+	}
+
+	if err := h.templates.DeleteTemplate(c.Request.Context(), c.Param("name"), locale); err != nil {
+		h.handleError(c, err, "Failed to delete email template")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Message: "Email template deleted successfully",
+	})
+}
+
+// ListVersions lists an email template's versions
+// @Summary List email template versions
+// @Description List every version of an email template, newest first
+// @Tags email-templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Param locale query string false "Template locale (en or sw), English by default"
+// @Success 200 {object} SuccessResponse{data=[]models.EmailTemplateVersionResponse}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-templates/{name}/versions [get]
+func (h *EmailTemplateHandler) ListVersions(c *gin.Context) {
+	locale, ok := h.parseLocale(c)
+	if !ok {
+		return
+	}
+
+	versions, err := h.templates.ListVersions(c.Request.Context(), c.Param("name"), locale)
+	if err != nil {
+		h.handleError(c, err, "Failed to list email template versions")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    versions,
+		Message: "Email template versions retrieved successfully",
+	})
+}
+
+// GetVersion gets one version of an email template
+// @Summary Get an email template version
+// @Description Get one version of an email template
+// @Tags email-templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Param locale query string false "Template locale (en or sw), English by default"
+// @Param version path int true "Version number"
+// @Success 200 {object} SuccessResponse{data=models.EmailTemplateVersionResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-templates/{name}/versions/{version} [get]
+func (h *EmailTemplateHandler) GetVersion(c *gin.Context) {
+	locale, ok := h.parseLocale(c)
+	if !ok {
+		return
+	}
+	version, ok := h.parseVersion(c, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	templateVersion, err := h.templates.GetVersion(c.Request.Context(), c.Param("name"), locale, version)
+	if err != nil {
+		h.handleError(c, err, "Failed to get email template version")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    templateVersion,
+		Message: "Email template version retrieved successfully",
+	})
+}
+
+// PublishVersion makes a version the one that is sent
+// @Summary Publish an email template version
+// @Description Make a version of an email template the one used for sending. The version it replaces is archived.
+// @Tags email-templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Param locale query string false "Template locale (en or sw), English by default"
+// @Param version path int true "Version number"
+// @Success 200 {object} SuccessResponse{data=models.EmailTemplateVersionResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-templates/{name}/versions/{version}/publish [post]
+func (h *EmailTemplateHandler) PublishVersion(c *gin.Context) {
+	locale, ok := h.parseLocale(c)
+	if !ok {
+		return
+	}
+	version, ok := h.parseVersion(c, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	published, err := h.templates.PublishVersion(c.Request.Context(), c.Param("name"), locale, version)
+	if err != nil {
+		h.handleError(c, err, "Failed to publish email template version")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    published,
+		Message: "Email template version published successfully",
+	})
+}
+
+// RollbackTemplate goes back to an earlier version of an email template
+// @Summary Roll back an email template
+// @Description Publish the content of an earlier version as a new version, keeping the rollback in the template's history
+// @Tags email-templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Param locale query string false "Template locale (en or sw), English by default"
+// @Param version path int true "Version to roll back to"
+// @Success 201 {object} SuccessResponse{data=models.EmailTemplateVersionResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-templates/{name}/versions/{version}/rollback [post]
+func (h *EmailTemplateHandler) RollbackTemplate(c *gin.Context) {
+	locale, ok := h.parseLocale(c)
+	if !ok {
+		return
+	}
+	version, ok := h.parseVersion(c, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	rolledBack, err := h.templates.RollbackToVersion(c.Request.Context(), c.Param("name"), locale, version, int32(middleware.GetUserID(c)))
+	if err != nil {
+		h.handleError(c, err, "Failed to roll back email template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    rolledBack,
+		Message: "Email template rolled back successfully",
+	})
+}
+
+// PreviewTemplate renders an email template with sample data
+// @Summary Preview an email template
+// @Description Render a version of an email template, the latest by default, with sample data. Variables left out of the data are shown as placeholders.
+// @Tags email-templates
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param locale query string false "Template locale (en or sw), English by default"
+// @Param request body models.PreviewEmailTemplateRequest false "Version and sample data"
+// @Success 200 {object} SuccessResponse{data=models.TemplateTestResult}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-templates/{name}/preview [post]
+func (h *EmailTemplateHandler) PreviewTemplate(c *gin.Context) {
+	locale, ok := h.parseLocale(c)
+	if !ok {
+		return
+	}
+
+	var req models.PreviewEmailTemplateRequest
+	if c.Request.ContentLength != 0 && !h.bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.templates.PreviewTemplate(c.Request.Context(), c.Param("name"), locale, req)
+	if err != nil {
+		h.handleError(c, err, "Failed to preview email template")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    result,
+		Message: "Email template preview rendered",
+	})
+}
+
+// DiffVersions compares two versions of an email template
+// @Summary Compare email template versions
+// @Description Compare the subject and body of two versions of an email template line by line, and list the variables added or removed
+// @Tags email-templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Param locale query string false "Template locale (en or sw), English by default"
+// @Param from query int true "Version to compare from"
+// @Param to query int true "Version to compare to"
+// @Success 200 {object} SuccessResponse{data=models.EmailTemplateDiffResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/email-templates/{name}/diff [get]
+func (h *EmailTemplateHandler) DiffVersions(c *gin.Context) {
+	locale, ok := h.parseLocale(c)
+	if !ok {
+		return
+	}
+	from, ok := h.parseVersion(c, c.Query("from"))
+	if !ok {
+		return
+	}
+	to, ok := h.parseVersion(c, c.Query("to"))
+	if !ok {
+		return
+	}
+
+	diff, err := h.templates.DiffVersions(c.Request.Context(), c.Param("name"), locale, from, to)
+	if err != nil {
+		h.handleError(c, err, "Failed to compare email template versions")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    diff,
+		Message: "Email template versions compared successfully",
+	})
+}
+
+func (h *EmailTemplateHandler) bindJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func (h *EmailTemplateHandler) parseVersion(c *gin.Context, value string) (int32, bool) {
+	version, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INVALID_VERSION",
+				Message: "Invalid template version",
+			},
+		})
+		return 0, false
+	}
+	return int32(version), true
+}
+
+func (h *EmailTemplateHandler) parseLocale(c *gin.Context) (string, bool) {
+	locale := c.DefaultQuery("locale", models.DefaultLocale)
+	if !models.IsSupportedLocale(locale) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INVALID_LOCALE",
+				Message: "Unsupported template locale",
+				Details: "supported locales are " + strings.Join(models.SupportedLocales, ", "),
+			},
+		})
+		return "", false
+	}
+	return locale, true
+}
+
+func (h *EmailTemplateHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrTemplateNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Email template not found",
+			},
+		})
+	case errors.Is(err, services.ErrTemplateVersionNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Email template version not found",
+			},
+		})
+	case errors.Is(err, services.ErrInvalidTemplate):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INVALID_TEMPLATE",
+				Message: "Email template is invalid",
+				Details: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrTemplateExists):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "TEMPLATE_EXISTS",
+				Message: "An email template with this name already exists",
+			},
+		})
+	case errors.Is(err, services.ErrTemplateVersionPublished):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VERSION_ALREADY_PUBLISHED",
+				Message: "This version is already published",
+			},
+		})
+	case errors.Is(err, services.ErrDefaultTemplate):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "DEFAULT_TEMPLATE",
+				Message: "Built-in email templates cannot be deleted",
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: message,
+				Details: err.Error(),
+			},
+		})
+	}
+}
@@ -0,0 +1,550 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ngenohkevin/lms/internal/middleware"
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// FineHandler handles HTTP requests for fines and the fine ledger
+type FineHandler struct {
+	fineService services.FineServiceInterface
+}
+
+// NewFineHandler creates a new fine handler
+func NewFineHandler(fineService services.FineServiceInterface) *FineHandler {
+	return &FineHandler{
+		fineService: fineService,
+	}
+}
+
+// CreateFine charges a student a fine
+// @Summary Create a fine
+// @Description Charge a student a fine, for example for a damaged book. Overdue fines are created automatically on return.
+// @Tags fines
+// @Accept json
+// @Produce json
+// @Param fine body models.CreateFineRequest true "Fine data"
+// @Success 201 {object} SuccessResponse{data=models.FineResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/fines [post]
+func (h *FineHandler) CreateFine(c *gin.Context) {
+	var req models.CreateFineRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	fine, err := h.fineService.AssessFine(c.Request.Context(), req, h.actor(c))
+	if err != nil {
+		h.handleError(c, err, "Failed to create fine")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    fine,
+		Message: "Fine created successfully",
+	})
+}
+
+// GetFine retrieves a fine
+// @Summary Get a fine
+// @Description Get a fine and its outstanding balance by ID
+// @Tags fines
+// @Produce json
+// @Param id path int true "Fine ID"
+// @Success 200 {object} SuccessResponse{data=models.FineResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/fines/{id} [get]
+func (h *FineHandler) GetFine(c *gin.Context) {
+	id, ok := parseFineID(c, "id", "Invalid fine ID")
+	if !ok {
+		return
+	}
+
+	fine, err := h.fineService.GetFine(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve fine")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    fine,
+	})
+}
+
+// GetFineLedger lists the entries recorded against a fine
+// @Summary Get fine ledger
+// @Description List the payments, waivers, refunds and adjustments recorded against a fine, oldest first
+// @Tags fines
+// @Produce json
+// @Param id path int true "Fine ID"
+// @Success 200 {object} SuccessResponse{data=[]models.FineLedgerEntryResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/fines/{id}/ledger [get]
+func (h *FineHandler) GetFineLedger(c *gin.Context) {
+	id, ok := parseFineID(c, "id", "Invalid fine ID")
+	if !ok {
+		return
+	}
+
+	entries, err := h.fineService.GetFineLedger(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve fine ledger")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    entries,
+	})
+}
+
+// RecordPayment records a payment against a fine
+// @Summary Record a fine payment
+// @Description Record a full or partial payment against a fine and issue a receipt
+// @Tags fines
+// @Accept json
+// @Produce json
+// @Param id path int true "Fine ID"
+// @Param payment body models.RecordFinePaymentRequest true "Payment data"
+// @Success 201 {object} SuccessResponse{data=models.FineLedgerResultResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/fines/{id}/payments [post]
+func (h *FineHandler) RecordPayment(c *gin.Context) {
+	id, ok := parseFineID(c, "id", "Invalid fine ID")
+	if !ok {
+		return
+	}
+
+	var req models.RecordFinePaymentRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.fineService.RecordPayment(c.Request.Context(), id, req, h.actor(c))
+	if err != nil {
+		h.handleError(c, err, "Failed to record payment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    result,
+		Message: "Payment recorded successfully",
+	})
+}
+
+// WaiveFine waives part or all of a fine
+// @Summary Waive a fine
+// @Description Waive part of a fine's outstanding balance, or all of it when no amount is given. A reason is required.
+// @Tags fines
+// @Accept json
+// @Produce json
+// @Param id path int true "Fine ID"
+// @Param waiver body models.WaiveFineRequest true "Waiver data"
+// @Success 201 {object} SuccessResponse{data=models.FineLedgerResultResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/fines/{id}/waive [post]
+func (h *FineHandler) WaiveFine(c *gin.Context) {
+	id, ok := parseFineID(c, "id", "Invalid fine ID")
+	if !ok {
+		return
+	}
+
+	var req models.WaiveFineRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.fineService.WaiveFine(c.Request.Context(), id, req, h.actor(c))
+	if err != nil {
+		h.handleError(c, err, "Failed to waive fine")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    result,
+		Message: "Fine waived successfully",
+	})
+}
+
+// RefundPayment refunds money paid against a fine
+// @Summary Refund a fine payment
+// @Description Refund money previously paid against a fine and issue a receipt. A reason is required.
+// @Tags fines
+// @Accept json
+// @Produce json
+// @Param id path int true "Fine ID"
+// @Param refund body models.RefundFineRequest true "Refund data"
+// @Success 201 {object} SuccessResponse{data=models.FineLedgerResultResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/fines/{id}/refunds [post]
+func (h *FineHandler) RefundPayment(c *gin.Context) {
+	id, ok := parseFineID(c, "id", "Invalid fine ID")
+	if !ok {
+		return
+	}
+
+	var req models.RefundFineRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.fineService.RefundPayment(c.Request.Context(), id, req, h.actor(c))
+	if err != nil {
+		h.handleError(c, err, "Failed to refund payment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    result,
+		Message: "Refund recorded successfully",
+	})
+}
+
+// AdjustFine corrects the amount charged for a fine
+// @Summary Adjust a fine
+// @Description Increase or reduce the amount charged for a fine. A reason is required.
+// @Tags fines
+// @Accept json
+// @Produce json
+// @Param id path int true "Fine ID"
+// @Param adjustment body models.AdjustFineRequest true "Adjustment data"
+// @Success 201 {object} SuccessResponse{data=models.FineLedgerResultResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/fines/{id}/adjustments [post]
+func (h *FineHandler) AdjustFine(c *gin.Context) {
+	id, ok := parseFineID(c, "id", "Invalid fine ID")
+	if !ok {
+		return
+	}
+
+	var req models.AdjustFineRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.fineService.AdjustFine(c.Request.Context(), id, req, h.actor(c))
+	if err != nil {
+		h.handleError(c, err, "Failed to adjust fine")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    result,
+		Message: "Fine adjusted successfully",
+	})
+}
+
+// GetReceipt retrieves a payment or refund by receipt number
+// @Summary Get a receipt
+// @Description Look up a payment or refund by its receipt number
+// @Tags fines
+// @Produce json
+// @Param receipt path string true "Receipt number"
+// @Success 200 {object} SuccessResponse{data=models.FineLedgerEntryResponse}
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/fines/receipts/{receipt} [get]
+func (h *FineHandler) GetReceipt(c *gin.Context) {
+	receipt, err := h.fineService.GetReceipt(c.Request.Context(), c.Param("receipt"))
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve receipt")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    receipt,
+	})
+}
+
+// GetCashReconciliation summarises the day's fine payments and refunds
+// @Summary Daily cash reconciliation
+// @Description Summarise fine payments and refunds for a day, per staff member and payment method
+// @Tags fines
+// @Produce json
+// @Param date query string false "Day to reconcile (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} SuccessResponse{data=models.CashReconciliationResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/fines/reconciliation [get]
+func (h *FineHandler) GetCashReconciliation(c *gin.Context) {
+	date := time.Now()
+	if value := c.Query("date"); value != "" {
+		parsed, err := time.Parse(models.CalendarDateLayout, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Success: false,
+				Error: ErrorDetail{
+					Code:    "VALIDATION_ERROR",
+					Message: "date must be in YYYY-MM-DD format",
+				},
+			})
+			return
+		}
+		date = parsed
+	}
+
+	report, err := h.fineService.GetCashReconciliation(c.Request.Context(), date)
+	if err != nil {
+		h.handleError(c, err, "Failed to build cash reconciliation")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// GetStudentBalance returns what a student owes
+// @Summary Get student fine balance
+// @Description Get a student's outstanding fine balance and all of their fines
+// @Tags students
+// @Produce json
+// @Param id path int true "Student ID"
+// @Success 200 {object} SuccessResponse{data=models.StudentFineBalanceResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/students/{id}/fines [get]
+func (h *FineHandler) GetStudentBalance(c *gin.Context) {
+	studentID, ok := parseFineID(c, "id", "Invalid student ID")
+	if !ok {
+		return
+	}
+
+	balance, err := h.fineService.GetStudentBalance(c.Request.Context(), studentID)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve fine balance")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    balance,
+	})
+}
+
+// InitiateMobilePayment prompts a student's phone to pay their fines by M-Pesa
+// @Summary Request an M-Pesa fine payment
+// @Description Send an M-Pesa STK push for the student's outstanding balance, rounded up to whole shillings.
+// @Description The fines are settled when M-Pesa confirms the payment; poll the payment request for the outcome.
+// @Tags students
+// @Accept json
+// @Produce json
+// @Param id path int true "Student ID"
+// @Param request body models.InitiateMobilePaymentRequest false "Phone to prompt, defaults to the student's phone"
+// @Success 202 {object} SuccessResponse{data=models.FinePaymentRequestResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/students/{id}/fines/mpesa [post]
+func (h *FineHandler) InitiateMobilePayment(c *gin.Context) {
+	studentID, ok := parseFineID(c, "id", "Invalid student ID")
+	if !ok {
+		return
+	}
+
+	var req models.InitiateMobilePaymentRequest
+	if c.Request.ContentLength > 0 && !h.bindJSON(c, &req) {
+		return
+	}
+
+	request, err := h.fineService.InitiateMobilePayment(c.Request.Context(), studentID, req, h.actor(c))
+	if err != nil {
+		h.handleError(c, err, "Failed to request M-Pesa payment")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Success: true,
+		Data:    request,
+		Message: "Payment request sent to the student's phone",
+	})
+}
+
+// GetPaymentRequest retrieves a mobile payment request
+// @Summary Get a payment request
+// @Description Get a mobile payment request and whether it has been paid
+// @Tags fines
+// @Produce json
+// @Param id path int true "Payment request ID"
+// @Success 200 {object} SuccessResponse{data=models.FinePaymentRequestResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/fines/payment-requests/{id} [get]
+func (h *FineHandler) GetPaymentRequest(c *gin.Context) {
+	id, ok := parseFineID(c, "id", "Invalid payment request ID")
+	if !ok {
+		return
+	}
+
+	request, err := h.fineService.GetPaymentRequest(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve payment request")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    request,
+	})
+}
+
+// MpesaCallback receives STK push results from M-Pesa
+// @Summary M-Pesa payment callback
+// @Description Called by Safaricom Daraja with the result of an STK push. Settles the student's fines when paid.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param token query string false "Callback token, when one is configured"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/payments/mpesa/callback [post]
+func (h *FineHandler) MpesaCallback(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid callback body",
+			},
+		})
+		return
+	}
+
+	if _, err := h.fineService.HandlePaymentCallback(c.Request.Context(), c.Query("token"), body); err != nil {
+		h.handleError(c, err, "Failed to process payment callback")
+		return
+	}
+
+	// Daraja only needs to know the callback was accepted
+	c.JSON(http.StatusOK, gin.H{
+		"ResultCode": 0,
+		"ResultDesc": "Accepted",
+	})
+}
+
+// actor identifies the staff member making the request for the audit log
+func (h *FineHandler) actor(c *gin.Context) services.AuditActor {
+	return librarianActor(c)
+}
+
+// librarianActor identifies the librarian making a request for the audit log
+func librarianActor(c *gin.Context) services.AuditActor {
+	return services.AuditActor{
+		UserID:    int32(middleware.GetUserID(c)),
+		UserType:  "librarian",
+		IPAddress: c.ClientIP(),
+		UserAgent: c.GetHeader("User-Agent"),
+	}
+}
+
+// bindJSON binds the request body, writing a 400 response when invalid
+func (h *FineHandler) bindJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return false
+	}
+	return true
+}
+
+// parseFineID reads an ID path parameter, writing a 400 response when invalid
+func parseFineID(c *gin.Context, param, message string) (int32, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 32)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: message,
+			},
+		})
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// handleError maps fine service errors to HTTP responses
+func (h *FineHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrPaymentsNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "SERVICE_UNAVAILABLE",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrInvalidCallbackToken):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "UNAUTHORIZED",
+				Message: err.Error(),
+			},
+		})
+	case isValidationError(err):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: err.Error(),
+			},
+		})
+	case isNotFoundError(err):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: err.Error(),
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// FineAccrualHandler exposes the nightly overdue fine accrual job
+type FineAccrualHandler struct {
+	accrualService services.FineAccrualServiceInterface
+}
+
+// NewFineAccrualHandler creates a new fine accrual handler
+func NewFineAccrualHandler(accrualService services.FineAccrualServiceInterface) *FineAccrualHandler {
+	return &FineAccrualHandler{
+		accrualService: accrualService,
+	}
+}
+
+// GetStatus reports the state of the fine accrual job
+// @Summary Get fine accrual status
+// @Description Get whether the overdue fine accrual job is running, and how its last run went. When it next runs is listed with the scheduled jobs
+// @Tags fines
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=models.FineAccrualStatusResponse}
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/fines/accrual [get]
+func (h *FineAccrualHandler) GetStatus(c *gin.Context) {
+	status, err := h.accrualService.GetStatus(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to get fine accrual status",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    status,
+		Message: "Fine accrual status retrieved successfully",
+	})
+}
+
+// RunAccrual accrues overdue fines now instead of waiting for its scheduled run
+// @Summary Run fine accrual
+// @Description Recalculate the running fine on every open overdue loan. Safe to repeat; fines are always recalculated from the due date.
+// @Tags fines
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=models.FineAccrualResult}
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/fines/accrual/run [post]
+func (h *FineAccrualHandler) RunAccrual(c *gin.Context) {
+	result, err := h.accrualService.AccrueOverdueFines(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, services.ErrJobAlreadyRunning) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Success: false,
+				Error: ErrorDetail{
+					Code:    "JOB_ALREADY_RUNNING",
+					Message: "Fine accrual is already running",
+				},
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: err.Error(),
+				Details: result,
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    result,
+		Message: "Fines accrued successfully",
+	})
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ngenohkevin/lms/internal/middleware"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// JobHandler lets admins see, trigger and pause the scheduled jobs
+type JobHandler struct {
+	scheduler services.JobSchedulerInterface
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(scheduler services.JobSchedulerInterface) *JobHandler {
+	return &JobHandler{
+		scheduler: scheduler,
+	}
+}
+
+// ListJobs lists the scheduled jobs
+// @Summary List scheduled jobs
+// @Description List the scheduled jobs with their schedule, whether they are paused, when they next run and how their last run went
+// @Tags jobs
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]models.ScheduledJobResponse}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/jobs [get]
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	jobs, err := h.scheduler.ListJobs(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to list jobs",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    jobs,
+		Message: "Jobs retrieved successfully",
+	})
+}
+
+// ListRuns lists a job's run history
+// @Summary List job runs
+// @Description List a job's runs, newest first, with who or what triggered them, how long they took and how they ended
+// @Tags jobs
+// @Produce json
+// @Param name path string true "Job name"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Success 200 {object} ListResponse{data=[]models.JobRunResponse}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/jobs/{name}/runs [get]
+func (h *JobHandler) ListRuns(c *gin.Context) {
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	runs, err := h.scheduler.ListRuns(c.Request.Context(), c.Param("name"), int32(limit), int32((page-1)*limit))
+	if err != nil {
+		h.handleError(c, err, "Failed to list job runs")
+		return
+	}
+
+	c.JSON(http.StatusOK, ListResponse{
+		Success: true,
+		Data:    runs,
+		Meta: map[string]interface{}{
+			"page":  page,
+			"limit": limit,
+			"total": len(runs),
+		},
+	})
+}
+
+// TriggerJob runs a job now
+// @Summary Run a job
+// @Description Start a run of the job now, even if its schedule is paused. The run carries on in the background; follow it in the job's run history.
+// @Tags jobs
+// @Produce json
+// @Param name path string true "Job name"
+// @Success 202 {object} SuccessResponse{data=models.JobRunResponse}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/jobs/{name}/run [post]
+func (h *JobHandler) TriggerJob(c *gin.Context) {
+	run, err := h.scheduler.Trigger(c.Request.Context(), c.Param("name"), int32(middleware.GetUserID(c)))
+	if err != nil {
+		h.handleError(c, err, "Failed to start job")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Success: true,
+		Data:    run,
+		Message: "Job started",
+	})
+}
+
+// PauseJob stops a job's schedule firing
+// @Summary Pause a job
+// @Description Stop the job's schedule firing until it is resumed. A run already going is left to finish and the job can still be run by hand.
+// @Tags jobs
+// @Produce json
+// @Param name path string true "Job name"
+// @Success 200 {object} SuccessResponse{data=models.ScheduledJobResponse}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/jobs/{name}/pause [post]
+func (h *JobHandler) PauseJob(c *gin.Context) {
+	job, err := h.scheduler.Pause(c.Request.Context(), c.Param("name"), int32(middleware.GetUserID(c)))
+	if err != nil {
+		h.handleError(c, err, "Failed to pause job")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    job,
+		Message: "Job paused successfully",
+	})
+}
+
+// ResumeJob lets a paused job's schedule fire again
+// @Summary Resume a job
+// @Description Let a paused job's schedule fire again from its next scheduled time
+// @Tags jobs
+// @Produce json
+// @Param name path string true "Job name"
+// @Success 200 {object} SuccessResponse{data=models.ScheduledJobResponse}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/jobs/{name}/resume [post]
+func (h *JobHandler) ResumeJob(c *gin.Context) {
+	job, err := h.scheduler.Resume(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.handleError(c, err, "Failed to resume job")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    job,
+		Message: "Job resumed successfully",
+	})
+}
+
+func (h *JobHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Job not found",
+			},
+		})
+	case errors.Is(err, services.ErrJobAlreadyRunning):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "JOB_ALREADY_RUNNING",
+				Message: "Job is already running",
+			},
+		})
+	case errors.Is(err, services.ErrSchedulerStopped):
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "SHUTTING_DOWN",
+				Message: "Server is shutting down",
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: message,
+				Details: err.Error(),
+			},
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ngenohkevin/lms/internal/middleware"
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// BroadcastHandler lets librarians send announcements to segments of students
+type BroadcastHandler struct {
+	broadcasts services.BroadcastServiceInterface
+}
+
+// NewBroadcastHandler creates a new broadcast handler
+func NewBroadcastHandler(broadcasts services.BroadcastServiceInterface) *BroadcastHandler {
+	return &BroadcastHandler{
+		broadcasts: broadcasts,
+	}
+}
+
+// CreateBroadcast sends an announcement to a segment of students
+// @Summary Send a broadcast
+// @Description Send an announcement to every active student in a segment. The segment can be narrowed by year of study, department, students with a book on loan and students with an overdue loan; an empty segment is every active student. The message may use {{.StudentName}}, {{.FirstName}} and {{.LastName}}. Each student gets their own notification, and the response lists the delivery status of each one.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body models.CreateBroadcastRequest true "Broadcast"
+// @Success 201 {object} SuccessResponse{data=models.Broadcast}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/notifications/broadcasts [post]
+func (h *BroadcastHandler) CreateBroadcast(c *gin.Context) {
+	var req models.CreateBroadcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	broadcast, err := h.broadcasts.CreateBroadcast(c.Request.Context(), &req, int32(middleware.GetUserID(c)))
+	if err != nil {
+		h.handleError(c, err, "Failed to send broadcast")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    broadcast,
+		Message: "Broadcast sent successfully",
+	})
+}
+
+// ListBroadcasts lists sent broadcasts
+// @Summary List broadcasts
+// @Description List broadcasts newest first with how many students each was sent to
+// @Tags notifications
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} SuccessResponse{data=models.BroadcastListResponse}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/notifications/broadcasts [get]
+func (h *BroadcastHandler) ListBroadcasts(c *gin.Context) {
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	broadcasts, err := h.broadcasts.ListBroadcasts(c.Request.Context(), page, limit)
+	if err != nil {
+		h.handleError(c, err, "Failed to list broadcasts")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    broadcasts,
+	})
+}
+
+// GetBroadcast returns a broadcast with the delivery status of each recipient
+// @Summary Get a broadcast
+// @Description Get a broadcast with a summary of its delivery and the status of each recipient's notification: pending, sent or failed
+// @Tags notifications
+// @Produce json
+// @Param id path int true "Broadcast ID"
+// @Success 200 {object} SuccessResponse{data=models.Broadcast}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/notifications/broadcasts/{id} [get]
+func (h *BroadcastHandler) GetBroadcast(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid broadcast ID",
+				Details: "Broadcast ID must be a valid integer",
+			},
+		})
+		return
+	}
+
+	broadcast, err := h.broadcasts.GetBroadcast(c.Request.Context(), int32(id))
+	if err != nil {
+		h.handleError(c, err, "Failed to get broadcast")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    broadcast,
+	})
+}
+
+func (h *BroadcastHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrBroadcastNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Broadcast not found",
+			},
+		})
+	case errors.Is(err, services.ErrInvalidBroadcast):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrNoBroadcastRecipients):
+		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NO_RECIPIENTS",
+				Message: "No students match the broadcast segment",
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: message,
+				Details: err.Error(),
+			},
+		})
+	}
+}
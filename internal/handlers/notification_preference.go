@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ngenohkevin/lms/internal/middleware"
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// NotificationPreferenceHandler handles the caller's own notification preferences
+type NotificationPreferenceHandler struct {
+	preferenceService services.NotificationPreferenceServiceInterface
+}
+
+// NewNotificationPreferenceHandler creates a new notification preference handler
+func NewNotificationPreferenceHandler(preferenceService services.NotificationPreferenceServiceInterface) *NotificationPreferenceHandler {
+	return &NotificationPreferenceHandler{
+		preferenceService: preferenceService,
+	}
+}
+
+// GetPreferences returns the caller's notification preferences
+// @Summary Get notification preferences
+// @Description Get the channels enabled for each notification type, quiet hours and digest mode of the signed-in student or librarian. Defaults are returned if none have been saved.
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=models.NotificationPreferences}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/profile/notification-preferences [get]
+func (h *NotificationPreferenceHandler) GetPreferences(c *gin.Context) {
+	recipientID, recipientType, ok := preferenceRecipient(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.preferenceService.GetPreferences(c.Request.Context(), recipientID, recipientType)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    preferences,
+	})
+}
+
+// UpdatePreferences replaces the caller's notification preferences
+// @Summary Update notification preferences
+// @Description Replace the signed-in user's notification preferences. Channels lists the channels to keep for each notification type (an empty list turns the type off); quiet hours hold non-urgent notifications until they end; a daily or weekly digest rolls routine email notifications into one email. Mandatory notices such as fines cannot be turned off and are never put in a digest.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body models.UpdateNotificationPreferencesRequest true "Notification preferences"
+// @Success 200 {object} SuccessResponse{data=models.NotificationPreferences}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/profile/notification-preferences [put]
+func (h *NotificationPreferenceHandler) UpdatePreferences(c *gin.Context) {
+	recipientID, recipientType, ok := preferenceRecipient(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	preferences, err := h.preferenceService.UpdatePreferences(c.Request.Context(), recipientID, recipientType, &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    preferences,
+		Message: "Notification preferences updated successfully",
+	})
+}
+
+// Unsubscribe turns off email of a notification type from an unsubscribe link
+// @Summary One-click unsubscribe
+// @Description Called by mail clients from the List-Unsubscribe link of a notice email (RFC 8058 one-click unsubscribe). Email of that notification type is turned off for the recipient the link was made for; their other channels for it are kept. Mandatory notices such as fines carry no link.
+// @Tags notifications
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token query string true "Unsubscribe token from the link"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/notifications/unsubscribe [post]
+func (h *NotificationPreferenceHandler) Unsubscribe(c *gin.Context) {
+	if err := h.preferenceService.Unsubscribe(c.Request.Context(), c.Query("token")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Message: "Unsubscribed successfully",
+	})
+}
+
+// preferenceRecipient identifies the caller as a notification recipient, writing
+// a 403 if they are neither a student nor a librarian
+func preferenceRecipient(c *gin.Context) (int32, models.RecipientType, bool) {
+	recipientType := models.RecipientType(middleware.GetUserType(c))
+	if !recipientType.IsValid() {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "FORBIDDEN",
+				Message: "Notification preferences are only available to students and librarians",
+			},
+		})
+		return 0, "", false
+	}
+	return int32(middleware.GetUserID(c)), recipientType, true
+}
+
+func (h *NotificationPreferenceHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPreferences), errors.Is(err, services.ErrMandatoryNotificationType),
+		errors.Is(err, services.ErrInvalidUnsubscribeToken):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: err.Error(),
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to process notification preferences",
+				Details: err.Error(),
+			},
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"github.com/ngenohkevin/lms/internal/middleware"
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// defaultStreamHeartbeat is used when no heartbeat interval is configured
+const defaultStreamHeartbeat = 25 * time.Second
+
+// RealtimeHandler streams live events to students and librarians
+type RealtimeHandler struct {
+	realtime  services.RealtimeServiceInterface
+	heartbeat time.Duration
+
+	// shutdown is closed when the server is stopping, ending every open stream
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+// NewRealtimeHandler creates a new realtime handler. Idle connections are sent a
+// heartbeat at the given interval so proxies do not close them.
+func NewRealtimeHandler(realtime services.RealtimeServiceInterface, heartbeat time.Duration) *RealtimeHandler {
+	if heartbeat <= 0 {
+		heartbeat = defaultStreamHeartbeat
+	}
+
+	return &RealtimeHandler{
+		realtime:  realtime,
+		heartbeat: heartbeat,
+		shutdown:  make(chan struct{}),
+	}
+}
+
+// Shutdown ends every open stream and closes every WebSocket. It is called before
+// the HTTP server shuts down, which would otherwise wait on the streams for ever
+// and does not track WebSockets at all, having handed their connections over.
+func (h *RealtimeHandler) Shutdown() {
+	h.shutdownOnce.Do(func() {
+		close(h.shutdown)
+	})
+}
+
+// Stream sends the caller's live events as server-sent events
+// @Summary Stream live events
+// @Description Server-sent event stream of the caller's new notifications, notification read receipts and reservation status changes. Librarians also receive every reservation change and the overdue and hold shelf counters. The stream opens with the current unread count (and counters, for librarians). EventSource clients may pass the access token in the access_token query parameter.
+// @Tags events
+// @Produce text/event-stream
+// @Param access_token query string false "Access token, for clients that cannot set the Authorization header"
+// @Success 200 {object} models.RealtimeEvent
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/events/stream [get]
+func (h *RealtimeHandler) Stream(c *gin.Context) {
+	ctx := c.Request.Context()
+	subscription, snapshot, err := h.open(ctx, c)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	defer subscription.Close()
+
+	// The stream stays open far longer than the server's write timeout allows
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stops proxies such as nginx from holding events back in a buffer
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, event := range snapshot {
+		c.SSEvent(event.Type, event)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-h.shutdown:
+			return false
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			// A comment line, which EventSource ignores
+			fmt.Fprint(w, ": heartbeat\n\n")
+			return true
+		}
+	})
+}
+
+// WebSocket sends the caller's live events as JSON messages over a WebSocket
+// @Summary Stream live events over WebSocket
+// @Description WebSocket alternative to the server-sent event stream, carrying the same events as JSON messages, plus a heartbeat message when idle. Messages from the client are ignored.
+// @Tags events
+// @Param access_token query string false "Access token, for clients that cannot set the Authorization header"
+// @Success 101 {object} models.RealtimeEvent
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/events/ws [get]
+func (h *RealtimeHandler) WebSocket(c *gin.Context) {
+	subscription, snapshot, err := h.open(c.Request.Context(), c)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	defer subscription.Close()
+
+	// Unlike websocket.Handler, a Server with no Handshake skips the origin check;
+	// the connection is authorised by the access token, not by cookies
+	server := websocket.Server{
+		Handler: func(conn *websocket.Conn) {
+			h.serveWebSocket(conn, subscription, snapshot)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func (h *RealtimeHandler) serveWebSocket(conn *websocket.Conn, subscription services.EventSubscription, snapshot []models.RealtimeEvent) {
+	// The hijacked connection keeps the server's timeouts, which would cut it off
+	_ = conn.SetDeadline(time.Time{})
+
+	// Clients only listen, so reading is just how a closed connection is noticed
+	closed := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, conn)
+		close(closed)
+	}()
+
+	for _, event := range snapshot {
+		if err := websocket.JSON.Send(conn, event); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-h.shutdown:
+			conn.Close()
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			if err := websocket.JSON.Send(conn, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := websocket.JSON.Send(conn, models.RealtimeEvent{Type: models.EventHeartbeat, OccurredAt: time.Now()}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// open subscribes the caller to their events and loads the state the stream opens
+// with. The subscription starts first so no change between the two is missed.
+func (h *RealtimeHandler) open(ctx context.Context, c *gin.Context) (services.EventSubscription, []models.RealtimeEvent, error) {
+	userType := middleware.GetUserType(c)
+	userID := int32(middleware.GetUserID(c))
+
+	subscription, err := h.realtime.Subscribe(ctx, userType, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snapshot, err := h.realtime.Snapshot(ctx, userType, userID)
+	if err != nil {
+		subscription.Close()
+		return nil, nil, err
+	}
+
+	return subscription, snapshot, nil
+}
+
+func (h *RealtimeHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnknownStreamUser):
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "FORBIDDEN",
+				Message: err.Error(),
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to open event stream",
+				Details: err.Error(),
+			},
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// fakeRealtimeService subscribes every user to a single in-memory channel
+type fakeRealtimeService struct {
+	broker   *services.LocalEventBroker
+	snapshot []models.RealtimeEvent
+}
+
+func (f *fakeRealtimeService) Subscribe(ctx context.Context, userType string, userID int32) (services.EventSubscription, error) {
+	if userType != "student" && userType != "librarian" {
+		return nil, services.ErrUnknownStreamUser
+	}
+	return f.broker.Subscribe(ctx, "test")
+}
+
+func (f *fakeRealtimeService) Snapshot(ctx context.Context, userType string, userID int32) ([]models.RealtimeEvent, error) {
+	return f.snapshot, nil
+}
+
+func setupRealtimeServer(t *testing.T, userType string) (*httptest.Server, *services.LocalEventBroker, *RealtimeHandler) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	unread, err := models.NewRealtimeEvent(models.EventUnreadCount, models.NotificationEvent{UnreadCount: 3})
+	require.NoError(t, err)
+	service := &fakeRealtimeService{
+		broker:   services.NewLocalEventBroker(),
+		snapshot: []models.RealtimeEvent{unread},
+	}
+	handler := NewRealtimeHandler(service, time.Minute)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", 2)
+		c.Set("user_type", userType)
+	})
+	router.GET("/api/v1/events/stream", handler.Stream)
+	router.GET("/api/v1/events/ws", handler.WebSocket)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, service.broker, handler
+}
+
+// readSSEEvent reads the next event block from a server-sent event stream
+func readSSEEvent(t *testing.T, reader *bufio.Reader) (string, models.RealtimeEvent) {
+	t.Helper()
+	var name string
+	var event models.RealtimeEvent
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimPrefix(line, "event:")
+		case strings.HasPrefix(line, "data:"):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &event))
+		case line == "" && name != "":
+			return name, event
+		}
+	}
+}
+
+func TestRealtimeHandler_Stream(t *testing.T) {
+	t.Run("opens with snapshot then streams events", func(t *testing.T) {
+		server, broker, _ := setupRealtimeServer(t, "student")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/events/stream", nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		reader := bufio.NewReader(resp.Body)
+		name, event := readSSEEvent(t, reader)
+		assert.Equal(t, models.EventUnreadCount, name)
+		assert.Equal(t, models.EventUnreadCount, event.Type)
+
+		created, err := models.NewRealtimeEvent(models.EventNotificationCreated, models.NotificationEvent{NotificationID: 11, UnreadCount: 4})
+		require.NoError(t, err)
+		// The handler subscribes before sending the snapshot, so it is listening by now
+		require.NoError(t, broker.Publish(context.Background(), "test", created))
+
+		name, event = readSSEEvent(t, reader)
+		assert.Equal(t, models.EventNotificationCreated, name)
+		var data models.NotificationEvent
+		require.NoError(t, json.Unmarshal(event.Data, &data))
+		assert.Equal(t, int32(11), data.NotificationID)
+		assert.Equal(t, int64(4), data.UnreadCount)
+	})
+
+	t.Run("ends when the server shuts down", func(t *testing.T) {
+		server, _, handler := setupRealtimeServer(t, "student")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/events/stream", nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		reader := bufio.NewReader(resp.Body)
+		readSSEEvent(t, reader)
+
+		handler.Shutdown()
+
+		_, err = io.ReadAll(reader)
+		assert.NoError(t, err, "the stream ends rather than running until the client gives up")
+	})
+
+	t.Run("rejects unknown user type", func(t *testing.T) {
+		server, _, _ := setupRealtimeServer(t, "")
+
+		resp, err := http.Get(server.URL + "/api/v1/events/stream")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		var response ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, "FORBIDDEN", response.Error.Code)
+	})
+}
+
+func TestRealtimeHandler_WebSocket(t *testing.T) {
+	server, broker, _ := setupRealtimeServer(t, "librarian")
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/events/ws"
+	conn, err := websocket.Dial(url, "", server.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	var event models.RealtimeEvent
+	require.NoError(t, websocket.JSON.Receive(conn, &event))
+	assert.Equal(t, models.EventUnreadCount, event.Type)
+
+	changed, err := models.NewRealtimeEvent(models.EventReservationStatusChanged, models.ReservationEvent{ReservationID: 40, Status: "ready"})
+	require.NoError(t, err)
+	require.NoError(t, broker.Publish(context.Background(), "test", changed))
+
+	require.NoError(t, websocket.JSON.Receive(conn, &event))
+	assert.Equal(t, models.EventReservationStatusChanged, event.Type)
+	var data models.ReservationEvent
+	require.NoError(t, json.Unmarshal(event.Data, &data))
+	assert.Equal(t, int32(40), data.ReservationID)
+	assert.Equal(t, "ready", data.Status)
+}
+
+func TestRealtimeHandler_WebSocketShutdown(t *testing.T) {
+	server, _, handler := setupRealtimeServer(t, "librarian")
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/events/ws"
+	conn, err := websocket.Dial(url, "", server.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	var event models.RealtimeEvent
+	require.NoError(t, websocket.JSON.Receive(conn, &event))
+
+	handler.Shutdown()
+
+	err = websocket.JSON.Receive(conn, &event)
+	assert.ErrorIs(t, err, io.EOF, "the server closes the connection")
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// RecallHandler handles HTTP requests for recalling loans
+type RecallHandler struct {
+	recallService services.RecallServiceInterface
+}
+
+// NewRecallHandler creates a new recall handler
+func NewRecallHandler(recallService services.RecallServiceInterface) *RecallHandler {
+	return &RecallHandler{
+		recallService: recallService,
+	}
+}
+
+// RecallLoan handles recalling a loan that another student is waiting for
+// @Summary Recall a loan
+// @Description Bring the due date of a loan forward so a waiting reader can have the book. The student keeps it for at least the policy's recall loan period, cannot renew it and is fined at the recall rate if it comes back late. A recall notice is sent to the student.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param request body models.RecallLoanRequest false "Recall details"
+// @Success 200 {object} SuccessResponse{data=models.TransactionResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/transactions/{id}/recall [post]
+func (h *RecallHandler) RecallLoan(c *gin.Context) {
+	transactionID, ok := parseTransactionID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RecallLoanRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Success: false,
+				Error: ErrorDetail{
+					Code:    "VALIDATION_ERROR",
+					Message: "Invalid request data",
+					Details: err.Error(),
+				},
+			})
+			return
+		}
+	}
+
+	transaction, err := h.recallService.RecallLoan(c.Request.Context(), transactionID, strings.TrimSpace(req.Reason), librarianActor(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    convertToTransactionResponse(transaction),
+		Message: "Loan recalled",
+	})
+}
+
+// handleError maps recall errors to responses; a loan that cannot be recalled is a 400
+func (h *RecallHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "transaction not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: err.Error(),
+			},
+		})
+	case strings.HasPrefix(err.Error(), "failed to"):
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to recall loan",
+				Details: err.Error(),
+			},
+		})
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "RECALL_ERROR",
+				Message: err.Error(),
+			},
+		})
+	}
+}
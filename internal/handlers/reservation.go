@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/ngenohkevin/lms/internal/middleware"
 	"github.com/ngenohkevin/lms/internal/models"
 	"github.com/ngenohkevin/lms/internal/services"
 )
@@ -22,6 +26,10 @@ type ReservationServiceInterface interface {
 	GetNextReservationForBook(ctx context.Context, bookID int32) (*services.ReservationResponse, error)
 	ExpireReservations(ctx context.Context) (int, error)
 	GetAllReservations(ctx context.Context, limit, offset int32) ([]services.ReservationResponse, error)
+	ListHoldShelf(ctx context.Context) ([]services.ReservationResponse, error)
+	SuspendReservation(ctx context.Context, id, studentID int32, until time.Time) (*services.ReservationResponse, error)
+	ResumeReservation(ctx context.Context, id, studentID int32) (*services.ReservationResponse, error)
+	MoveReservation(ctx context.Context, id int32, position int) (*services.ReservationResponse, error)
 }
 
 // ReservationHandler handles reservation-related HTTP requests
@@ -231,9 +239,183 @@ func (h *ReservationHandler) FulfillReservation(c *gin.Context) {
 	})
 }
 
+// SuspendReservation handles pausing a reservation
+// @Summary Suspend a reservation
+// @Description Pause a reservation until a date. It keeps its place in the queue but is passed over until then, and its expiry is pushed back by the time suspended. Students can only suspend their own reservations.
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Param request body models.SuspendReservationRequest true "Suspend reservation request"
+// @Success 200 {object} SuccessResponse{data=models.ReservationResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reservations/{id}/suspend [post]
+func (h *ReservationHandler) SuspendReservation(c *gin.Context) {
+	idStr := c.Param("id")
+	reservationID, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    models.ReservationErrorCodeValidationError,
+				Message: "Invalid reservation ID",
+				Details: "Reservation ID must be a valid integer",
+			},
+		})
+		return
+	}
+
+	var req models.SuspendReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    models.ReservationErrorCodeValidationError,
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	reservation, err := h.reservationService.SuspendReservation(c.Request.Context(), int32(reservationID), reservationOwner(c), req.Until)
+	if err != nil {
+		statusCode, errorCode := h.getErrorCodeAndStatus(err)
+		c.JSON(statusCode, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    errorCode,
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+
+	response := convertToReservationResponse(reservation)
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    response,
+		Message: "Reservation suspended successfully",
+	})
+}
+
+// ResumeReservation handles ending a suspension early
+// @Summary Resume a reservation
+// @Description End a reservation's suspension early so the queue no longer passes over it. Students can only resume their own reservations.
+// @Tags reservations
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Success 200 {object} SuccessResponse{data=models.ReservationResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reservations/{id}/resume [post]
+func (h *ReservationHandler) ResumeReservation(c *gin.Context) {
+	idStr := c.Param("id")
+	reservationID, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    models.ReservationErrorCodeValidationError,
+				Message: "Invalid reservation ID",
+				Details: "Reservation ID must be a valid integer",
+			},
+		})
+		return
+	}
+
+	reservation, err := h.reservationService.ResumeReservation(c.Request.Context(), int32(reservationID), reservationOwner(c))
+	if err != nil {
+		statusCode, errorCode := h.getErrorCodeAndStatus(err)
+		c.JSON(statusCode, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    errorCode,
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+
+	response := convertToReservationResponse(reservation)
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    response,
+		Message: "Reservation resumed successfully",
+	})
+}
+
+// MoveReservation handles moving a reservation within its book's queue
+// @Summary Move a reservation in the queue
+// @Description Move an active reservation to a position in its book's queue (librarian only)
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path int true "Reservation ID"
+// @Param request body models.MoveReservationRequest true "Move reservation request"
+// @Success 200 {object} SuccessResponse{data=models.ReservationResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reservations/{id}/move [post]
+func (h *ReservationHandler) MoveReservation(c *gin.Context) {
+	idStr := c.Param("id")
+	reservationID, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    models.ReservationErrorCodeValidationError,
+				Message: "Invalid reservation ID",
+				Details: "Reservation ID must be a valid integer",
+			},
+		})
+		return
+	}
+
+	var req models.MoveReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    models.ReservationErrorCodeValidationError,
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	reservation, err := h.reservationService.MoveReservation(c.Request.Context(), int32(reservationID), req.Position)
+	if err != nil {
+		statusCode, errorCode := h.getErrorCodeAndStatus(err)
+		c.JSON(statusCode, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    errorCode,
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+
+	response := convertToReservationResponse(reservation)
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    response,
+		Message: "Reservation moved successfully",
+	})
+}
+
 // GetStudentReservations handles getting reservations for a specific student
 // @Summary Get student reservations
-// @Description Get all reservations for a specific student
+// @Description Get all reservations for a specific student. Reservations still in the queue include their position and an estimate of when they will be ready.
 // @Tags reservations
 // @Produce json
 // @Param studentId path int true "Student ID"
@@ -293,7 +475,7 @@ func (h *ReservationHandler) GetStudentReservations(c *gin.Context) {
 
 // GetBookReservations handles getting reservations for a specific book
 // @Summary Get book reservations
-// @Description Get all active reservations for a specific book (queue)
+// @Description Get all active reservations for a specific book (queue), each with an estimate of when it will be ready
 // @Tags reservations
 // @Produce json
 // @Param bookId path int true "Book ID"
@@ -464,6 +646,41 @@ func (h *ReservationHandler) ExpireReservations(c *gin.Context) {
 	})
 }
 
+// GetHoldShelf handles listing the reserved books waiting to be collected
+// @Summary Get the hold shelf
+// @Description List reserved copies set aside for students to collect, soonest pickup deadline first (librarian only)
+// @Tags reservations
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=[]models.HoldShelfItemResponse}
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reservations/hold-shelf [get]
+func (h *ReservationHandler) GetHoldShelf(c *gin.Context) {
+	holds, err := h.reservationService.ListHoldShelf(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    models.ReservationErrorCodeInternalError,
+				Message: "Failed to get hold shelf",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	now := time.Now()
+	response := make([]models.HoldShelfItemResponse, len(holds))
+	for i, hold := range holds {
+		response[i] = convertToHoldShelfItemResponse(&hold, now)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    response,
+		Message: "Hold shelf retrieved successfully",
+	})
+}
+
 // Helper functions
 
 func (h *ReservationHandler) parsePaginationParams(c *gin.Context) (int32, int32) {
@@ -483,10 +700,21 @@ func (h *ReservationHandler) parsePaginationParams(c *gin.Context) (int32, int32
 	return int32(limit), int32(offset)
 }
 
+// reservationOwner returns the student a reservation change is restricted to:
+// the authenticated student, or 0 for librarians who manage every reservation
+func reservationOwner(c *gin.Context) int32 {
+	if middleware.GetUserType(c) == "student" {
+		return int32(middleware.GetUserID(c))
+	}
+	return 0
+}
+
 func (h *ReservationHandler) getErrorCodeAndStatus(err error) (int, string) {
 	errorMessage := err.Error()
 
 	switch {
+	case errors.Is(err, services.ErrStudentBlocked):
+		return http.StatusForbidden, models.ReservationErrorCodeStudentBlocked
 	case contains(errorMessage, "book not found"):
 		return http.StatusNotFound, models.ReservationErrorCodeBookNotFound
 	case contains(errorMessage, "student not found"):
@@ -507,6 +735,10 @@ func (h *ReservationHandler) getErrorCodeAndStatus(err error) (int, string) {
 		return http.StatusConflict, models.ReservationErrorCodeDuplicateReservation
 	case contains(errorMessage, "reservation expired"):
 		return http.StatusUnprocessableEntity, models.ReservationErrorCodeReservationExpired
+	case contains(errorMessage, "suspend"), contains(errorMessage, "suspension"):
+		return http.StatusUnprocessableEntity, models.ReservationErrorCodeCannotSuspend
+	case contains(errorMessage, "in the queue"), contains(errorMessage, "queue position"):
+		return http.StatusUnprocessableEntity, models.ReservationErrorCodeNotInQueue
 	default:
 		return http.StatusInternalServerError, models.ReservationErrorCodeInternalError
 	}
@@ -531,71 +763,128 @@ func indexOf(s, substr string) int {
 
 func convertToReservationResponse(r *services.ReservationResponse) models.ReservationResponse {
 	return models.ReservationResponse{
-		ID:            r.ID,
-		StudentID:     r.StudentID,
-		BookID:        r.BookID,
-		ReservedAt:    r.ReservedAt,
-		ExpiresAt:     r.ExpiresAt,
-		Status:        r.Status,
-		FulfilledAt:   r.FulfilledAt,
-		CreatedAt:     r.CreatedAt,
-		UpdatedAt:     r.UpdatedAt,
-		QueuePosition: r.QueuePosition,
+		ID:             r.ID,
+		StudentID:      r.StudentID,
+		BookID:         r.BookID,
+		ReservedAt:     r.ReservedAt,
+		ExpiresAt:      r.ExpiresAt,
+		Status:         r.Status,
+		FulfilledAt:    r.FulfilledAt,
+		ReadyAt:        r.ReadyAt,
+		PickupDeadline: r.PickupDeadline,
+		SuspendedUntil: r.SuspendedUntil,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+		QueuePosition:  r.QueuePosition,
 	}
 }
 
 func convertToReservationDetailsResponse(r *services.ReservationResponse) models.ReservationDetailsResponse {
 	return models.ReservationDetailsResponse{
-		ID:            r.ID,
-		StudentID:     r.StudentID,
-		BookID:        r.BookID,
-		ReservedAt:    r.ReservedAt,
-		ExpiresAt:     r.ExpiresAt,
-		Status:        r.Status,
-		FulfilledAt:   r.FulfilledAt,
-		CreatedAt:     r.CreatedAt,
-		UpdatedAt:     r.UpdatedAt,
-		QueuePosition: r.QueuePosition,
-		StudentName:   r.StudentName,
-		StudentIDCode: r.StudentIDCode,
-		BookTitle:     r.BookTitle,
-		BookAuthor:    r.BookAuthor,
-		BookIDCode:    r.BookIDCode,
+		ID:             r.ID,
+		StudentID:      r.StudentID,
+		BookID:         r.BookID,
+		ReservedAt:     r.ReservedAt,
+		ExpiresAt:      r.ExpiresAt,
+		Status:         r.Status,
+		FulfilledAt:    r.FulfilledAt,
+		ReadyAt:        r.ReadyAt,
+		PickupDeadline: r.PickupDeadline,
+		SuspendedUntil: r.SuspendedUntil,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+		QueuePosition:  r.QueuePosition,
+		StudentName:    r.StudentName,
+		StudentIDCode:  r.StudentIDCode,
+		BookTitle:      r.BookTitle,
+		BookAuthor:     r.BookAuthor,
+		BookIDCode:     r.BookIDCode,
 	}
 }
 
 func convertToStudentReservationResponse(r *services.ReservationResponse) models.StudentReservationResponse {
 	return models.StudentReservationResponse{
-		ID:          r.ID,
-		StudentID:   r.StudentID,
-		BookID:      r.BookID,
-		ReservedAt:  r.ReservedAt,
-		ExpiresAt:   r.ExpiresAt,
-		Status:      r.Status,
-		FulfilledAt: r.FulfilledAt,
-		CreatedAt:   r.CreatedAt,
-		UpdatedAt:   r.UpdatedAt,
-		BookTitle:   r.BookTitle,
-		BookAuthor:  r.BookAuthor,
-		BookIDCode:  r.BookIDCode,
+		ID:             r.ID,
+		StudentID:      r.StudentID,
+		BookID:         r.BookID,
+		ReservedAt:     r.ReservedAt,
+		ExpiresAt:      r.ExpiresAt,
+		Status:         r.Status,
+		FulfilledAt:    r.FulfilledAt,
+		ReadyAt:        r.ReadyAt,
+		PickupDeadline: r.PickupDeadline,
+		SuspendedUntil: r.SuspendedUntil,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+		QueuePosition:  r.QueuePosition,
+		EstimatedWait:  convertToWaitTimeEstimate(r.EstimatedWait, time.Now()),
+		BookTitle:      r.BookTitle,
+		BookAuthor:     r.BookAuthor,
+		BookIDCode:     r.BookIDCode,
 	}
 }
 
 func convertToBookReservationResponse(r *services.ReservationResponse) models.BookReservationResponse {
 	return models.BookReservationResponse{
-		ID:            r.ID,
+		ID:             r.ID,
+		StudentID:      r.StudentID,
+		BookID:         r.BookID,
+		ReservedAt:     r.ReservedAt,
+		ExpiresAt:      r.ExpiresAt,
+		Status:         r.Status,
+		FulfilledAt:    r.FulfilledAt,
+		SuspendedUntil: r.SuspendedUntil,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+		QueuePosition:  r.QueuePosition,
+		Priority:       r.Priority,
+		EstimatedWait:  convertToWaitTimeEstimate(r.EstimatedWait, time.Now()),
+		StudentName:    r.StudentName,
+		StudentIDCode:  r.StudentIDCode,
+	}
+}
+
+func convertToWaitTimeEstimate(e *services.WaitEstimate, now time.Time) *models.WaitTimeEstimate {
+	if e == nil {
+		return nil
+	}
+
+	expectedDays := int(math.Ceil(e.ExpectedAt.Sub(now).Hours() / 24))
+	if expectedDays < 0 {
+		expectedDays = 0
+	}
+
+	return &models.WaitTimeEstimate{
+		ExpectedAt:   e.ExpectedAt,
+		EarliestAt:   e.Earliest,
+		LatestAt:     e.Latest,
+		ExpectedDays: expectedDays,
+	}
+}
+
+func convertToHoldShelfItemResponse(r *services.ReservationResponse, now time.Time) models.HoldShelfItemResponse {
+	response := models.HoldShelfItemResponse{
+		ReservationID: r.ID,
 		StudentID:     r.StudentID,
-		BookID:        r.BookID,
-		ReservedAt:    r.ReservedAt,
-		ExpiresAt:     r.ExpiresAt,
-		Status:        r.Status,
-		FulfilledAt:   r.FulfilledAt,
-		CreatedAt:     r.CreatedAt,
-		UpdatedAt:     r.UpdatedAt,
-		QueuePosition: r.QueuePosition,
 		StudentName:   r.StudentName,
 		StudentIDCode: r.StudentIDCode,
+		BookID:        r.BookID,
+		BookTitle:     r.BookTitle,
+		BookAuthor:    r.BookAuthor,
+		BookIDCode:    r.BookIDCode,
+		CopyID:        r.CopyID,
+		Barcode:       r.Barcode,
+	}
+
+	if r.ReadyAt != nil {
+		response.ReadyAt = *r.ReadyAt
 	}
+	if r.PickupDeadline != nil {
+		response.PickupDeadline = *r.PickupDeadline
+		response.PastDeadline = now.After(*r.PickupDeadline)
+	}
+
+	return response
 }
 
 func convertToReservationQueueResponse(reservations []services.ReservationResponse, bookID int32) models.ReservationQueueResponse {
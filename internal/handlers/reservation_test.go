@@ -83,6 +83,35 @@ func (m *MockReservationService) GetAllReservations(ctx context.Context, limit,
 	return args.Get(0).([]services.ReservationResponse), args.Error(1)
 }
 
+func (m *MockReservationService) ListHoldShelf(ctx context.Context) ([]services.ReservationResponse, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]services.ReservationResponse), args.Error(1)
+}
+
+func (m *MockReservationService) SuspendReservation(ctx context.Context, id, studentID int32, until time.Time) (*services.ReservationResponse, error) {
+	args := m.Called(ctx, id, studentID, until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.ReservationResponse), args.Error(1)
+}
+
+func (m *MockReservationService) ResumeReservation(ctx context.Context, id, studentID int32) (*services.ReservationResponse, error) {
+	args := m.Called(ctx, id, studentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.ReservationResponse), args.Error(1)
+}
+
+func (m *MockReservationService) MoveReservation(ctx context.Context, id int32, position int) (*services.ReservationResponse, error) {
+	args := m.Called(ctx, id, position)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.ReservationResponse), args.Error(1)
+}
+
 func TestNewReservationHandler(t *testing.T) {
 	mockService := &MockReservationService{}
 	handler := NewReservationHandler(mockService)
@@ -136,6 +165,39 @@ func TestReservationHandler_ReserveBook_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestReservationHandler_ReserveBook_StudentBlocked(t *testing.T) {
+	mockService := &MockReservationService{}
+	handler := NewReservationHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/reservations", handler.ReserveBook)
+
+	request := models.ReserveBookRequest{
+		StudentID: 1,
+		BookID:    2,
+	}
+
+	mockService.On("ReserveBook", mock.Anything, int32(1), int32(2)).
+		Return(nil, fmt.Errorf("%w: a loan has been overdue for 40 days, more than the 30 allowed", services.ErrStudentBlocked))
+
+	requestBody, _ := json.Marshal(request)
+	req, _ := http.NewRequest(http.MethodPost, "/reservations", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReservationErrorCodeStudentBlocked, response.Error.Code)
+
+	mockService.AssertExpectations(t)
+}
+
 func TestReservationHandler_ReserveBook_ValidationError(t *testing.T) {
 	mockService := &MockReservationService{}
 	handler := NewReservationHandler(mockService)
@@ -619,6 +681,166 @@ func TestReservationHandler_ExpireReservations_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestReservationHandler_GetHoldShelf_Success(t *testing.T) {
+	mockService := &MockReservationService{}
+	handler := NewReservationHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/reservations/hold-shelf", handler.GetHoldShelf)
+
+	copyID := int32(7)
+	readyAt := time.Now().AddDate(0, 0, -4)
+	lapsed := time.Now().AddDate(0, 0, -1)
+	due := time.Now().AddDate(0, 0, 2)
+	holds := []services.ReservationResponse{
+		{ID: 1, StudentID: 1, BookID: 2, Status: "ready", ReadyAt: &readyAt, PickupDeadline: &lapsed, CopyID: &copyID, Barcode: "BK002-001", StudentName: "John Doe", BookTitle: "Test Book"},
+		{ID: 2, StudentID: 3, BookID: 4, Status: "ready", ReadyAt: &readyAt, PickupDeadline: &due, StudentName: "Jane Smith", BookTitle: "Other Book"},
+	}
+
+	mockService.On("ListHoldShelf", mock.Anything).Return(holds, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/reservations/hold-shelf", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Success bool                           `json:"success"`
+		Data    []models.HoldShelfItemResponse `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.Success)
+	if assert.Len(t, response.Data, 2) {
+		assert.Equal(t, int32(1), response.Data[0].ReservationID)
+		assert.Equal(t, "BK002-001", response.Data[0].Barcode)
+		assert.True(t, response.Data[0].PastDeadline)
+		assert.Nil(t, response.Data[1].CopyID)
+		assert.False(t, response.Data[1].PastDeadline)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestReservationHandler_SuspendReservation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	until := time.Date(2030, 6, 14, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService := &MockReservationService{}
+		handler := NewReservationHandler(mockService)
+		router := gin.New()
+		router.POST("/reservations/:id/suspend", handler.SuspendReservation)
+
+		mockService.On("SuspendReservation", mock.Anything, int32(1), int32(0), until).Return(&services.ReservationResponse{
+			ID:             1,
+			Status:         "active",
+			SuspendedUntil: &until,
+			QueuePosition:  2,
+		}, nil)
+
+		body, _ := json.Marshal(map[string]string{"until": "2030-06-14T00:00:00Z"})
+		req, _ := http.NewRequest(http.MethodPost, "/reservations/1/suspend", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Success bool                       `json:"success"`
+			Data    models.ReservationResponse `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if assert.NotNil(t, response.Data.SuspendedUntil) {
+			assert.True(t, until.Equal(*response.Data.SuspendedUntil))
+		}
+		assert.Equal(t, 2, response.Data.QueuePosition)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("NotActive", func(t *testing.T) {
+		mockService := &MockReservationService{}
+		handler := NewReservationHandler(mockService)
+		router := gin.New()
+		router.POST("/reservations/:id/suspend", handler.SuspendReservation)
+
+		mockService.On("SuspendReservation", mock.Anything, int32(1), int32(0), until).Return(nil, fmt.Errorf("only active reservations can be suspended"))
+
+		body, _ := json.Marshal(map[string]string{"until": "2030-06-14T00:00:00Z"})
+		req, _ := http.NewRequest(http.MethodPost, "/reservations/1/suspend", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), models.ReservationErrorCodeCannotSuspend)
+	})
+
+	t.Run("StudentCanOnlySuspendOwnReservation", func(t *testing.T) {
+		mockService := &MockReservationService{}
+		handler := NewReservationHandler(mockService)
+		router := gin.New()
+		router.POST("/reservations/:id/suspend", func(c *gin.Context) {
+			c.Set("user_id", 7)
+			c.Set("user_type", "student")
+			handler.SuspendReservation(c)
+		})
+
+		mockService.On("SuspendReservation", mock.Anything, int32(1), int32(7), until).Return(nil, fmt.Errorf("reservation not found"))
+
+		body, _ := json.Marshal(map[string]string{"until": "2030-06-14T00:00:00Z"})
+		req, _ := http.NewRequest(http.MethodPost, "/reservations/1/suspend", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("MissingUntil", func(t *testing.T) {
+		handler := NewReservationHandler(&MockReservationService{})
+		router := gin.New()
+		router.POST("/reservations/:id/suspend", handler.SuspendReservation)
+
+		req, _ := http.NewRequest(http.MethodPost, "/reservations/1/suspend", bytes.NewBufferString("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestReservationHandler_MoveReservation(t *testing.T) {
+	mockService := &MockReservationService{}
+	handler := NewReservationHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/reservations/:id/move", handler.MoveReservation)
+
+	mockService.On("MoveReservation", mock.Anything, int32(3), 1).Return(&services.ReservationResponse{
+		ID:            3,
+		Status:        "active",
+		Priority:      10,
+		QueuePosition: 1,
+	}, nil)
+
+	body, _ := json.Marshal(models.MoveReservationRequest{Position: 1})
+	req, _ := http.NewRequest(http.MethodPost, "/reservations/3/move", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Reservation moved successfully")
+	mockService.AssertExpectations(t)
+}
+
 func TestReservationHandler_PaginationParams(t *testing.T) {
 	mockService := &MockReservationService{}
 	handler := NewReservationHandler(mockService)
@@ -691,6 +913,18 @@ func TestReservationHandler_ErrorCodeMapping(t *testing.T) {
 			expectedStatus: http.StatusConflict,
 			expectedCode:   models.ReservationErrorCodeDuplicateReservation,
 		},
+		{
+			name:           "NotSuspended",
+			errorMessage:   "reservation is not suspended",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   models.ReservationErrorCodeCannotSuspend,
+		},
+		{
+			name:           "NotInQueue",
+			errorMessage:   "only active reservations can be moved in the queue",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   models.ReservationErrorCodeNotInQueue,
+		},
 		{
 			name:           "InternalError",
 			errorMessage:   "database connection failed",
@@ -814,3 +1048,23 @@ func TestReservationHandler_ConvertToReservationQueueResponse_EmptyQueue(t *test
 	assert.Equal(t, "", queueResponse.BookIDCode)
 	assert.Len(t, queueResponse.Reservations, 0)
 }
+
+func TestReservationHandler_ConvertToWaitTimeEstimate(t *testing.T) {
+	now := time.Now()
+	estimate := &services.WaitEstimate{
+		ExpectedAt: now.Add(5*24*time.Hour + time.Hour),
+		Earliest:   now.Add(3 * 24 * time.Hour),
+		Latest:     now.Add(8 * 24 * time.Hour),
+	}
+
+	response := convertToWaitTimeEstimate(estimate, now)
+
+	assert.Equal(t, estimate.ExpectedAt, response.ExpectedAt)
+	assert.Equal(t, estimate.Earliest, response.EarliestAt)
+	assert.Equal(t, estimate.Latest, response.LatestAt)
+	assert.Equal(t, 6, response.ExpectedDays, "part of a day counts as a day")
+	assert.Nil(t, convertToWaitTimeEstimate(nil, now))
+
+	bookResponse := convertToBookReservationResponse(&services.ReservationResponse{ID: 1, EstimatedWait: estimate})
+	assert.NotNil(t, bookResponse.EstimatedWait)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// SMSHandler receives delivery reports from the SMS gateway
+type SMSHandler struct {
+	smsService services.SMSServiceInterface
+}
+
+// NewSMSHandler creates a new SMS handler
+func NewSMSHandler(smsService services.SMSServiceInterface) *SMSHandler {
+	return &SMSHandler{
+		smsService: smsService,
+	}
+}
+
+// DeliveryReport receives the delivery status of a notification SMS
+// @Summary SMS delivery report callback
+// @Description Called by the SMS gateway when a notification SMS is delivered or fails. Reports for unknown messages are accepted and ignored.
+// @Tags sms
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token query string false "Callback token, when one is configured"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/sms/delivery-reports [post]
+func (h *SMSHandler) DeliveryReport(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid delivery report body",
+			},
+		})
+		return
+	}
+
+	if _, err := h.smsService.HandleDeliveryReport(c.Request.Context(), c.Query("token"), c.Request.PostForm); err != nil {
+		h.handleError(c, err, "Failed to process delivery report")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Message: "Delivery report received",
+	})
+}
+
+func (h *SMSHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidSMSCallbackToken):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "UNAUTHORIZED",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrInvalidSMSDeliveryReport):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: err.Error(),
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: message,
+				Details: err.Error(),
+			},
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 // StudentHandler handles HTTP requests for student operations
 type StudentHandler struct {
 	studentService *services.StudentService
+	blockService   services.StudentBlockServiceInterface
 }
 
 // NewStudentHandler creates a new student handler
@@ -26,6 +27,12 @@ func NewStudentHandler(studentService *services.StudentService) *StudentHandler
 	}
 }
 
+// WithBlockService shows the blocks on a student's account in student detail responses
+func (h *StudentHandler) WithBlockService(blockService services.StudentBlockServiceInterface) *StudentHandler {
+	h.blockService = blockService
+	return h
+}
+
 // CreateStudent handles POST /api/v1/students
 func (h *StudentHandler) CreateStudent(c *gin.Context) {
 	var req models.CreateStudentRequest
@@ -135,13 +142,43 @@ func (h *StudentHandler) GetStudent(c *gin.Context) {
 		return
 	}
 
+	response, err := h.withBlocks(c, student.ToResponse())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve student blocks",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
-		Data:    student.ToResponse(),
+		Data:    response,
 		Message: "Student retrieved successfully",
 	})
 }
 
+// withBlocks adds the blocks currently on the student's account to a detail response
+func (h *StudentHandler) withBlocks(c *gin.Context, response models.StudentResponse) (models.StudentResponse, error) {
+	if h.blockService == nil {
+		return response, nil
+	}
+
+	blocks, err := h.blockService.ActiveBlocks(c.Request.Context(), response.ID)
+	if err != nil {
+		return response, err
+	}
+
+	isBlocked := len(blocks) > 0
+	response.IsBlocked = &isBlocked
+	response.Blocks = blocks
+	return response, nil
+}
+
 // UpdateStudent handles PUT /api/v1/students/:id
 func (h *StudentHandler) UpdateStudent(c *gin.Context) {
 	idParam := c.Param("id")
@@ -564,9 +601,22 @@ func (h *StudentHandler) GetStudentProfile(c *gin.Context) {
 		return
 	}
 
+	response, err := h.withBlocks(c, student.ToResponse())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve student blocks",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
-		Data:    student.ToResponse(),
+		Data:    response,
 		Message: "Profile retrieved successfully",
 	})
 }
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// StudentBlockHandler handles HTTP requests for blocks on student accounts
+type StudentBlockHandler struct {
+	blockService services.StudentBlockServiceInterface
+}
+
+// NewStudentBlockHandler creates a new student block handler
+func NewStudentBlockHandler(blockService services.StudentBlockServiceInterface) *StudentBlockHandler {
+	return &StudentBlockHandler{
+		blockService: blockService,
+	}
+}
+
+// GetStudentBlocks lists the blocks on a student's account
+// @Summary Get student blocks
+// @Description Get the blocks currently stopping a student from borrowing, renewing or reserving, including automatic fine and overdue blocks, and every block a librarian has placed
+// @Tags students
+// @Produce json
+// @Param id path int true "Student ID"
+// @Success 200 {object} SuccessResponse{data=models.StudentBlocksResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/students/{id}/blocks [get]
+func (h *StudentBlockHandler) GetStudentBlocks(c *gin.Context) {
+	studentID, ok := parseFineID(c, "id", "Invalid student ID")
+	if !ok {
+		return
+	}
+
+	blocks, err := h.blockService.GetStudentBlocks(c.Request.Context(), studentID)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve student blocks")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    blocks,
+	})
+}
+
+// PlaceBlock blocks a student's account
+// @Summary Block a student
+// @Description Stop a student from borrowing, renewing or reserving until the block is lifted or expires. A reason is required.
+// @Tags students
+// @Accept json
+// @Produce json
+// @Param id path int true "Student ID"
+// @Param block body models.CreateStudentBlockRequest true "Block data"
+// @Success 201 {object} SuccessResponse{data=models.StudentBlockResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/students/{id}/blocks [post]
+func (h *StudentBlockHandler) PlaceBlock(c *gin.Context) {
+	studentID, ok := parseFineID(c, "id", "Invalid student ID")
+	if !ok {
+		return
+	}
+
+	var req models.CreateStudentBlockRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	block, err := h.blockService.PlaceBlock(c.Request.Context(), studentID, req, librarianActor(c))
+	if err != nil {
+		h.handleError(c, err, "Failed to block student")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    block,
+		Message: "Student blocked successfully",
+	})
+}
+
+// LiftBlock lifts a block before it expires
+// @Summary Lift a student block
+// @Description Lift a block a librarian placed on a student's account. Automatic fine and overdue blocks clear once the fines are paid or the books are returned. A reason is required.
+// @Tags students
+// @Accept json
+// @Produce json
+// @Param id path int true "Student ID"
+// @Param block_id path int true "Block ID"
+// @Param lift body models.LiftStudentBlockRequest true "Lift data"
+// @Success 200 {object} SuccessResponse{data=models.StudentBlockResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/students/{id}/blocks/{block_id}/lift [post]
+func (h *StudentBlockHandler) LiftBlock(c *gin.Context) {
+	studentID, ok := parseFineID(c, "id", "Invalid student ID")
+	if !ok {
+		return
+	}
+	blockID, ok := parseFineID(c, "block_id", "Invalid block ID")
+	if !ok {
+		return
+	}
+
+	var req models.LiftStudentBlockRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	block, err := h.blockService.LiftBlock(c.Request.Context(), studentID, blockID, req, librarianActor(c))
+	if err != nil {
+		h.handleError(c, err, "Failed to lift student block")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    block,
+		Message: "Student block lifted successfully",
+	})
+}
+
+// bindJSON binds the request body, writing a 400 response when invalid
+func (h *StudentBlockHandler) bindJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return false
+	}
+	return true
+}
+
+// handleError maps block service errors to HTTP responses
+func (h *StudentBlockHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case isValidationError(err):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: err.Error(),
+			},
+		})
+	case isNotFoundError(err):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: err.Error(),
+			},
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: message,
+				Details: err.Error(),
+			},
+		})
+	}
+}
+
+// writeStudentBlockedError writes a 403 response if err is a blocked account,
+// reporting whether it did
+func writeStudentBlockedError(c *gin.Context, err error) bool {
+	if !errors.Is(err, services.ErrStudentBlocked) {
+		return false
+	}
+
+	c.JSON(http.StatusForbidden, ErrorResponse{
+		Success: false,
+		Error: ErrorDetail{
+			Code:    "STUDENT_BLOCKED",
+			Message: err.Error(),
+		},
+	})
+	return true
+}
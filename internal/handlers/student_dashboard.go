@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ngenohkevin/lms/internal/middleware"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+// StudentDashboardHandler handles the student self-service dashboard
+type StudentDashboardHandler struct {
+	dashboardService services.StudentDashboardServiceInterface
+}
+
+// NewStudentDashboardHandler creates a new student dashboard handler
+func NewStudentDashboardHandler(dashboardService services.StudentDashboardServiceInterface) *StudentDashboardHandler {
+	return &StudentDashboardHandler{
+		dashboardService: dashboardService,
+	}
+}
+
+// GetDashboard returns the authenticated student's dashboard
+// @Summary Get my dashboard
+// @Description Get the authenticated student's current loans with due dates and renewability, reservation queue positions, outstanding fines and unread notifications
+// @Tags students
+// @Produce json
+// @Success 200 {object} SuccessResponse{data=models.StudentDashboardResponse}
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/students/me/dashboard [get]
+func (h *StudentDashboardHandler) GetDashboard(c *gin.Context) {
+	dashboard, err := h.dashboardService.GetDashboard(c.Request.Context(), int32(middleware.GetUserID(c)))
+	if err != nil {
+		if err.Error() == "student not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Success: false,
+				Error: ErrorDetail{
+					Code:    "STUDENT_NOT_FOUND",
+					Message: "Student profile not found",
+				},
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to load dashboard",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    dashboard,
+		Message: "Dashboard retrieved successfully",
+	})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/shopspring/decimal"
 
 	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/middleware"
 	"github.com/ngenohkevin/lms/internal/models"
 	"github.com/ngenohkevin/lms/internal/services"
 )
@@ -17,15 +19,24 @@ import (
 // TransactionServiceInterface defines the interface for transaction service operations
 type TransactionServiceInterface interface {
 	BorrowBook(ctx context.Context, studentID, bookID, librarianID int32, notes string) (*services.TransactionResponse, error)
-	ReturnBook(ctx context.Context, transactionID int32) (*services.TransactionResponse, error)
+	BorrowBookByBarcode(ctx context.Context, studentID int32, barcode string, librarianID int32, notes string) (*services.TransactionResponse, error)
+	ReturnBook(ctx context.Context, transactionID int32, actor services.AuditActor) (*services.TransactionResponse, error)
+	ReturnBookByBarcode(ctx context.Context, barcode, returnCondition, conditionNotes string, actor services.AuditActor) (*services.TransactionResponse, error)
 	RenewBook(ctx context.Context, transactionID, librarianID int32) (*services.TransactionResponse, error)
 	GetOverdueTransactions(ctx context.Context) ([]queries.ListOverdueTransactionsRow, error)
-	PayFine(ctx context.Context, transactionID int32) error
+	PayFine(ctx context.Context, transactionID int32, req models.PayTransactionFineRequest, actor services.AuditActor) ([]models.FineLedgerResultResponse, error)
 	GetTransactionHistory(ctx context.Context, studentID int32, limit, offset int32) ([]queries.ListTransactionsByStudentRow, error)
 	// Phase 6.7: Enhanced Renewal System methods
 	CanBookBeRenewed(ctx context.Context, transactionID int32) (bool, string, error)
 	GetRenewalHistory(ctx context.Context, studentID, bookID int32) ([]queries.ListRenewalsByStudentAndBookRow, error)
 	GetRenewalStatistics(ctx context.Context, studentID int32) (*queries.GetRenewalStatisticsByStudentRow, error)
+	MarkLost(ctx context.Context, transactionID int32, notes string, actor services.AuditActor) (*services.TransactionResponse, error)
+	MarkClaimedReturned(ctx context.Context, transactionID int32, notes string, actor services.AuditActor) (*services.TransactionResponse, error)
+	MarkFound(ctx context.Context, transactionID int32, req models.MarkLoanFoundRequest, actor services.AuditActor) (*services.TransactionResponse, error)
+	RenewOwnLoan(ctx context.Context, transactionID, studentID int32) (*services.TransactionResponse, error)
+	CanRenewOwnLoan(ctx context.Context, transactionID, studentID int32) (bool, string, error)
+	BatchBorrow(ctx context.Context, req models.BatchBorrowRequest) ([]services.BatchItemResult, error)
+	BatchReturn(ctx context.Context, req models.BatchReturnRequest, actor services.AuditActor) ([]services.BatchItemResult, error)
 }
 
 // TransactionHandler handles transaction-related HTTP requests
@@ -42,7 +53,7 @@ func NewTransactionHandler(transactionService TransactionServiceInterface) *Tran
 
 // BorrowBook handles book borrowing requests
 // @Summary Borrow a book
-// @Description Allow a student to borrow a book from the library
+// @Description Allow a student to borrow a book from the library, either by book ID or by scanning a copy barcode
 // @Tags transactions
 // @Accept json
 // @Produce json
@@ -67,14 +78,41 @@ func (h *TransactionHandler) BorrowBook(c *gin.Context) {
 		return
 	}
 
-	transaction, err := h.transactionService.BorrowBook(
-		c.Request.Context(),
-		req.StudentID,
-		req.BookID,
-		req.LibrarianID,
-		req.Notes,
-	)
+	if req.BookID == 0 && req.Barcode == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: "Either book_id or barcode is required",
+			},
+		})
+		return
+	}
+
+	var transaction *services.TransactionResponse
+	var err error
+	if req.Barcode != "" {
+		transaction, err = h.transactionService.BorrowBookByBarcode(
+			c.Request.Context(),
+			req.StudentID,
+			req.Barcode,
+			req.LibrarianID,
+			req.Notes,
+		)
+	} else {
+		transaction, err = h.transactionService.BorrowBook(
+			c.Request.Context(),
+			req.StudentID,
+			req.BookID,
+			req.LibrarianID,
+			req.Notes,
+		)
+	}
 	if err != nil {
+		if writeStudentBlockedError(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Success: false,
 			Error: ErrorDetail{
@@ -119,7 +157,7 @@ func (h *TransactionHandler) ReturnBook(c *gin.Context) {
 		return
 	}
 
-	transaction, err := h.transactionService.ReturnBook(c.Request.Context(), int32(transactionID))
+	transaction, err := h.transactionService.ReturnBook(c.Request.Context(), int32(transactionID), librarianActor(c))
 	if err != nil {
 		statusCode := http.StatusBadRequest
 		if err.Error() == "transaction not found" {
@@ -144,6 +182,62 @@ func (h *TransactionHandler) ReturnBook(c *gin.Context) {
 	})
 }
 
+// ReturnBookByBarcode handles book returns by scanned copy barcode
+// @Summary Return a book by barcode
+// @Description Return the borrowed copy identified by its barcode
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param request body models.ReturnBookByBarcodeRequest true "Return book request"
+// @Success 200 {object} SuccessResponse{data=models.TransactionResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/transactions/return [post]
+func (h *TransactionHandler) ReturnBookByBarcode(c *gin.Context) {
+	var req models.ReturnBookByBarcodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	returnCondition := req.ReturnCondition
+	if returnCondition == "" {
+		returnCondition = "good"
+	}
+
+	transaction, err := h.transactionService.ReturnBookByBarcode(c.Request.Context(), req.Barcode, returnCondition, req.ConditionNotes, librarianActor(c))
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "book copy not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "RETURN_ERROR",
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+
+	response := convertToTransactionResponse(transaction)
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    response,
+		Message: "Book returned successfully",
+	})
+}
+
 // RenewBook handles book renewal requests
 // @Summary Renew a book
 // @Description Renew a borrowed book for additional time
@@ -154,6 +248,7 @@ func (h *TransactionHandler) ReturnBook(c *gin.Context) {
 // @Param request body models.RenewBookRequest true "Renew book request"
 // @Success 200 {object} SuccessResponse{data=models.TransactionResponse}
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/transactions/{id}/renew [post]
@@ -191,6 +286,9 @@ func (h *TransactionHandler) RenewBook(c *gin.Context) {
 		req.LibrarianID,
 	)
 	if err != nil {
+		if writeStudentBlockedError(c, err) {
+			return
+		}
 		statusCode := http.StatusBadRequest
 		if err.Error() == "transaction not found" {
 			statusCode = http.StatusNotFound
@@ -250,33 +348,49 @@ func (h *TransactionHandler) GetOverdueTransactions(c *gin.Context) {
 
 // PayFine handles fine payment requests
 // @Summary Pay a fine
-// @Description Mark a transaction fine as paid
+// @Description Pay off the outstanding fines raised against a transaction. Each payment is recorded in the fine ledger with its own receipt; the payment method defaults to cash.
 // @Tags transactions
+// @Accept json
 // @Produce json
 // @Param id path int true "Transaction ID"
-// @Success 200 {object} SuccessResponse
+// @Param request body models.PayTransactionFineRequest false "Payment details"
+// @Success 200 {object} SuccessResponse{data=[]models.FineLedgerResultResponse}
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /api/v1/transactions/{id}/pay-fine [post]
 func (h *TransactionHandler) PayFine(c *gin.Context) {
-	idStr := c.Param("id")
-	transactionID, err := strconv.ParseInt(idStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Success: false,
-			Error: ErrorDetail{
-				Code:    "VALIDATION_ERROR",
-				Message: "Invalid transaction ID",
-				Details: "Transaction ID must be a valid integer",
-			},
-		})
+	transactionID, ok := parseTransactionID(c)
+	if !ok {
 		return
 	}
 
-	err = h.transactionService.PayFine(c.Request.Context(), int32(transactionID))
+	var req models.PayTransactionFineRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Success: false,
+				Error: ErrorDetail{
+					Code:    "VALIDATION_ERROR",
+					Message: "Invalid request data",
+					Details: err.Error(),
+				},
+			})
+			return
+		}
+	}
+
+	payments, err := h.transactionService.PayFine(c.Request.Context(), transactionID, req, librarianActor(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+		statusCode := http.StatusInternalServerError
+		switch {
+		case isValidationError(err):
+			statusCode = http.StatusBadRequest
+		case isNotFoundError(err):
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
 			Success: false,
 			Error: ErrorDetail{
 				Code:    "PAYMENT_ERROR",
@@ -289,6 +403,7 @@ func (h *TransactionHandler) PayFine(c *gin.Context) {
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
+		Data:    payments,
 		Message: "Fine paid successfully",
 	})
 }
@@ -364,6 +479,284 @@ func (h *TransactionHandler) GetTransactionHistory(c *gin.Context) {
 	})
 }
 
+// MarkLost handles reporting a borrowed copy as lost
+// @Summary Mark a loan lost
+// @Description Close a loan whose copy was lost. Overdue fines stop, the replacement cost and processing fee are charged and the copy leaves the collection.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param request body models.MarkLoanMissingRequest false "Lost item details"
+// @Success 200 {object} SuccessResponse{data=models.TransactionResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/transactions/{id}/lost [post]
+func (h *TransactionHandler) MarkLost(c *gin.Context) {
+	h.markMissing(c, h.transactionService.MarkLost, "Loan marked lost")
+}
+
+// MarkClaimedReturned handles a student claiming a copy was returned when it cannot be found
+// @Summary Mark a loan claimed returned
+// @Description Close a loan the student says was returned but the library cannot find. It is charged like a lost copy until found.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param request body models.MarkLoanMissingRequest false "Claim details"
+// @Success 200 {object} SuccessResponse{data=models.TransactionResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/transactions/{id}/claimed-returned [post]
+func (h *TransactionHandler) MarkClaimedReturned(c *gin.Context) {
+	h.markMissing(c, h.transactionService.MarkClaimedReturned, "Loan marked claimed returned")
+}
+
+func (h *TransactionHandler) markMissing(c *gin.Context, mark func(context.Context, int32, string, services.AuditActor) (*services.TransactionResponse, error), message string) {
+	transactionID, ok := parseTransactionID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MarkLoanMissingRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Success: false,
+				Error: ErrorDetail{
+					Code:    "VALIDATION_ERROR",
+					Message: "Invalid request data",
+					Details: err.Error(),
+				},
+			})
+			return
+		}
+	}
+
+	transaction, err := mark(c.Request.Context(), transactionID, req.Notes, librarianActor(c))
+	if err != nil {
+		writeLossError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    convertToTransactionResponse(transaction),
+		Message: message,
+	})
+}
+
+// MarkFound handles a lost or claimed returned copy turning up
+// @Summary Mark a missing copy found
+// @Description Put a lost or claimed returned copy back into circulation and reverse its replacement charge. A refund method is required if the charge was already paid.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Param request body models.MarkLoanFoundRequest false "Found item details"
+// @Success 200 {object} SuccessResponse{data=models.TransactionResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/transactions/{id}/found [post]
+func (h *TransactionHandler) MarkFound(c *gin.Context) {
+	transactionID, ok := parseTransactionID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MarkLoanFoundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Success: false,
+				Error: ErrorDetail{
+					Code:    "VALIDATION_ERROR",
+					Message: "Invalid request data",
+					Details: err.Error(),
+				},
+			})
+			return
+		}
+	}
+
+	transaction, err := h.transactionService.MarkFound(c.Request.Context(), transactionID, req, librarianActor(c))
+	if err != nil {
+		writeLossError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    convertToTransactionResponse(transaction),
+		Message: "Copy returned to circulation",
+	})
+}
+
+// BatchBorrow handles issuing several books to one student
+// @Summary Batch checkout
+// @Description Issue several books to a student in one request. The set is checked against the loan limit first; in all_or_nothing mode (the default) any failure issues nothing, in best_effort mode each book that can be issued is.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param request body models.BatchBorrowRequest true "Batch checkout request"
+// @Success 200 {object} SuccessResponse{data=models.TransactionBatchOperationResult}
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse{error=ErrorDetail{details=models.TransactionBatchOperationResult}}
+// @Router /api/v1/transactions/batch/borrow [post]
+func (h *TransactionHandler) BatchBorrow(c *gin.Context) {
+	var req models.BatchBorrowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	results, err := h.transactionService.BatchBorrow(c.Request.Context(), req)
+	h.writeBatchResult(c, req.Mode, results, err, "BORROW_ERROR", "borrowed")
+}
+
+// BatchReturn handles checking in several loans at once
+// @Summary Batch check-in
+// @Description Return several loans in one request, each by transaction ID or copy barcode. Every returned copy fulfils the next reservation for its book. In all_or_nothing mode (the default) any failure returns nothing.
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param request body models.BatchReturnRequest true "Batch check-in request"
+// @Success 200 {object} SuccessResponse{data=models.TransactionBatchOperationResult}
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse{error=ErrorDetail{details=models.TransactionBatchOperationResult}}
+// @Router /api/v1/transactions/batch/return [post]
+func (h *TransactionHandler) BatchReturn(c *gin.Context) {
+	var req models.BatchReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid request data",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	results, err := h.transactionService.BatchReturn(c.Request.Context(), req, librarianActor(c))
+	h.writeBatchResult(c, req.Mode, results, err, "RETURN_ERROR", "returned")
+}
+
+// writeBatchResult reports a batch outcome. A rolled back all-or-nothing batch is
+// a 422 carrying the per-item results so the desk can see which item failed.
+func (h *TransactionHandler) writeBatchResult(c *gin.Context, mode models.BatchMode, results []services.BatchItemResult, err error, errorCode, verb string) {
+	if err != nil {
+		if writeStudentBlockedError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    errorCode,
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+
+	if mode == "" {
+		mode = models.BatchModeAllOrNothing
+	}
+	result := convertToTransactionBatchResult(mode, results, verb)
+
+	if mode == models.BatchModeAllOrNothing && !result.Success {
+		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    errorCode,
+				Message: result.Message,
+				Details: result,
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    result,
+		Message: result.Message,
+	})
+}
+
+func convertToTransactionBatchResult(mode models.BatchMode, results []services.BatchItemResult, verb string) models.TransactionBatchOperationResult {
+	batch := models.TransactionBatchOperationResult{
+		Mode:           mode,
+		ProcessedCount: len(results),
+		Results:        make([]models.TransactionOperationResult, 0, len(results)),
+	}
+
+	for _, item := range results {
+		result := models.TransactionOperationResult{Index: item.Index}
+		if item.Err != nil {
+			result.Error = item.Err.Error()
+			batch.FailureCount++
+		} else {
+			transaction := convertToTransactionResponse(item.Transaction)
+			result.Success = true
+			result.Transaction = &transaction
+			batch.SuccessCount++
+		}
+		batch.Results = append(batch.Results, result)
+	}
+
+	batch.Success = batch.FailureCount == 0
+	switch {
+	case batch.Success:
+		batch.Message = fmt.Sprintf("All %d books %s", batch.SuccessCount, verb)
+	case batch.SuccessCount == 0 || mode == models.BatchModeAllOrNothing:
+		batch.Message = fmt.Sprintf("No books %s; %d of %d items failed", verb, batch.FailureCount, batch.ProcessedCount)
+	default:
+		batch.Message = fmt.Sprintf("%d of %d books %s; %d failed", batch.SuccessCount, batch.ProcessedCount, verb, batch.FailureCount)
+	}
+
+	return batch
+}
+
+// parseTransactionID reads the :id path parameter, writing a 400 response when invalid
+func parseTransactionID(c *gin.Context) (int32, bool) {
+	transactionID, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid transaction ID",
+				Details: "Transaction ID must be a valid integer",
+			},
+		})
+		return 0, false
+	}
+	return int32(transactionID), true
+}
+
+func writeLossError(c *gin.Context, err error) {
+	statusCode := http.StatusBadRequest
+	if err.Error() == "transaction not found" {
+		statusCode = http.StatusNotFound
+	}
+
+	c.JSON(statusCode, ErrorResponse{
+		Success: false,
+		Error: ErrorDetail{
+			Code:    "LOSS_ERROR",
+			Message: err.Error(),
+		},
+	})
+}
+
 // Helper functions to convert between service and model types
 
 func convertToTransactionResponse(tx *services.TransactionResponse) models.TransactionResponse {
@@ -371,6 +764,8 @@ func convertToTransactionResponse(tx *services.TransactionResponse) models.Trans
 		ID:              tx.ID,
 		StudentID:       tx.StudentID,
 		BookID:          tx.BookID,
+		CopyID:          tx.CopyID,
+		Barcode:         tx.Barcode,
 		TransactionType: tx.TransactionType,
 		TransactionDate: tx.TransactionDate,
 		DueDate:         tx.DueDate,
@@ -379,6 +774,10 @@ func convertToTransactionResponse(tx *services.TransactionResponse) models.Trans
 		FineAmount:      tx.FineAmount,
 		FinePaid:        tx.FinePaid,
 		Notes:           tx.Notes,
+		LossStatus:      tx.LossStatus,
+		RecalledAt:      tx.RecalledAt,
+		OriginalDueDate: tx.OriginalDueDate,
+		Charges:         tx.Charges,
 		CreatedAt:       tx.CreatedAt,
 		UpdatedAt:       tx.UpdatedAt,
 	}
@@ -493,6 +892,89 @@ func (h *TransactionHandler) CanBookBeRenewed(c *gin.Context) {
 	})
 }
 
+// RenewOwnLoan lets a student renew one of their own loans
+// @Summary Renew my loan
+// @Description Renew a loan held by the authenticated student, subject to the usual renewal rules
+// @Tags students
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} SuccessResponse{data=models.TransactionResponse}
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/students/me/loans/{id}/renew [post]
+func (h *TransactionHandler) RenewOwnLoan(c *gin.Context) {
+	transactionID, ok := parseTransactionID(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.transactionService.RenewOwnLoan(c.Request.Context(), transactionID, int32(middleware.GetUserID(c)))
+	if err != nil {
+		if writeStudentBlockedError(c, err) {
+			return
+		}
+		statusCode := http.StatusBadRequest
+		if err.Error() == "transaction not found" {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "RENEW_ERROR",
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    convertToTransactionResponse(transaction),
+		Message: "Book renewed successfully",
+	})
+}
+
+// CanRenewOwnLoan checks whether the authenticated student can renew one of their loans
+// @Summary Check if my loan can be renewed
+// @Description Check if a loan held by the authenticated student can be renewed and get the reason if not
+// @Tags students
+// @Produce json
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} SuccessResponse{data=map[string]interface{}}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/students/me/loans/{id}/can-renew [get]
+func (h *TransactionHandler) CanRenewOwnLoan(c *gin.Context) {
+	transactionID, ok := parseTransactionID(c)
+	if !ok {
+		return
+	}
+
+	canRenew, reason, err := h.transactionService.CanRenewOwnLoan(c.Request.Context(), transactionID, int32(middleware.GetUserID(c)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to check renewal eligibility",
+				Details: err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"can_renew": canRenew,
+			"reason":    reason,
+		},
+		Message: "Renewal eligibility checked successfully",
+	})
+}
+
 // GetRenewalHistory gets renewal history for a student and book
 // @Summary Get renewal history
 // @Description Get renewal history for a specific student and book
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -17,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
 	"github.com/ngenohkevin/lms/internal/services"
 )
 
@@ -33,8 +35,24 @@ func (m *MockTransactionService) BorrowBook(ctx context.Context, studentID, book
 	return args.Get(0).(*services.TransactionResponse), args.Error(1)
 }
 
-func (m *MockTransactionService) ReturnBook(ctx context.Context, transactionID int32) (*services.TransactionResponse, error) {
-	args := m.Called(ctx, transactionID)
+func (m *MockTransactionService) BorrowBookByBarcode(ctx context.Context, studentID int32, barcode string, librarianID int32, notes string) (*services.TransactionResponse, error) {
+	args := m.Called(ctx, studentID, barcode, librarianID, notes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.TransactionResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) ReturnBookByBarcode(ctx context.Context, barcode, returnCondition, conditionNotes string, actor services.AuditActor) (*services.TransactionResponse, error) {
+	args := m.Called(ctx, barcode, returnCondition, conditionNotes, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.TransactionResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) ReturnBook(ctx context.Context, transactionID int32, actor services.AuditActor) (*services.TransactionResponse, error) {
+	args := m.Called(ctx, transactionID, actor)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -54,9 +72,12 @@ func (m *MockTransactionService) GetOverdueTransactions(ctx context.Context) ([]
 	return args.Get(0).([]queries.ListOverdueTransactionsRow), args.Error(1)
 }
 
-func (m *MockTransactionService) PayFine(ctx context.Context, transactionID int32) error {
-	args := m.Called(ctx, transactionID)
-	return args.Error(0)
+func (m *MockTransactionService) PayFine(ctx context.Context, transactionID int32, req models.PayTransactionFineRequest, actor services.AuditActor) ([]models.FineLedgerResultResponse, error) {
+	args := m.Called(ctx, transactionID, req, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.FineLedgerResultResponse), args.Error(1)
 }
 
 func (m *MockTransactionService) GetTransactionHistory(ctx context.Context, studentID int32, limit, offset int32) ([]queries.ListTransactionsByStudentRow, error) {
@@ -83,6 +104,59 @@ func (m *MockTransactionService) GetRenewalStatistics(ctx context.Context, stude
 	return args.Get(0).(*queries.GetRenewalStatisticsByStudentRow), args.Error(1)
 }
 
+func (m *MockTransactionService) MarkLost(ctx context.Context, transactionID int32, notes string, actor services.AuditActor) (*services.TransactionResponse, error) {
+	args := m.Called(ctx, transactionID, notes, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.TransactionResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) MarkClaimedReturned(ctx context.Context, transactionID int32, notes string, actor services.AuditActor) (*services.TransactionResponse, error) {
+	args := m.Called(ctx, transactionID, notes, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.TransactionResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) MarkFound(ctx context.Context, transactionID int32, req models.MarkLoanFoundRequest, actor services.AuditActor) (*services.TransactionResponse, error) {
+	args := m.Called(ctx, transactionID, req, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.TransactionResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) RenewOwnLoan(ctx context.Context, transactionID, studentID int32) (*services.TransactionResponse, error) {
+	args := m.Called(ctx, transactionID, studentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.TransactionResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) CanRenewOwnLoan(ctx context.Context, transactionID, studentID int32) (bool, string, error) {
+	args := m.Called(ctx, transactionID, studentID)
+	return args.Bool(0), args.String(1), args.Error(2)
+}
+
+func (m *MockTransactionService) BatchBorrow(ctx context.Context, req models.BatchBorrowRequest) ([]services.BatchItemResult, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]services.BatchItemResult), args.Error(1)
+}
+
+func (m *MockTransactionService) BatchReturn(ctx context.Context, req models.BatchReturnRequest, actor services.AuditActor) ([]services.BatchItemResult, error) {
+	args := m.Called(ctx, req, actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]services.BatchItemResult), args.Error(1)
+}
+
 // Test helper functions
 func setupTransactionRouter() (*gin.Engine, *MockTransactionService) {
 	gin.SetMode(gin.TestMode)
@@ -95,10 +169,16 @@ func setupTransactionRouter() (*gin.Engine, *MockTransactionService) {
 	v1 := router.Group("/api/v1")
 	{
 		v1.POST("/transactions/borrow", handler.BorrowBook)
+		v1.POST("/transactions/batch/borrow", handler.BatchBorrow)
+		v1.POST("/transactions/batch/return", handler.BatchReturn)
+		v1.POST("/transactions/return", handler.ReturnBookByBarcode)
 		v1.POST("/transactions/:id/return", handler.ReturnBook)
 		v1.POST("/transactions/:id/renew", handler.RenewBook)
 		v1.GET("/transactions/overdue", handler.GetOverdueTransactions)
 		v1.POST("/transactions/:id/pay-fine", handler.PayFine)
+		v1.POST("/transactions/:id/lost", handler.MarkLost)
+		v1.POST("/transactions/:id/claimed-returned", handler.MarkClaimedReturned)
+		v1.POST("/transactions/:id/found", handler.MarkFound)
 		v1.GET("/transactions/history/:studentId", handler.GetTransactionHistory)
 		// Phase 6.7: Enhanced Renewal System routes
 		v1.GET("/transactions/:id/can-renew", handler.CanBookBeRenewed)
@@ -227,6 +307,337 @@ func TestTransactionHandler_BorrowBook_ServiceError(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestTransactionHandler_BorrowBook_StudentBlocked(t *testing.T) {
+	router, mockService := setupTransactionRouter()
+
+	requestBody := map[string]interface{}{
+		"student_id":   1,
+		"book_id":      1,
+		"librarian_id": 1,
+	}
+
+	mockService.On("BorrowBook", mock.Anything, int32(1), int32(1), int32(1), "").
+		Return(nil, fmt.Errorf("%w: unpaid fines of 12.00 exceed the limit of 10.00", services.ErrStudentBlocked))
+
+	jsonBody, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/api/v1/transactions/borrow", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Equal(t, "STUDENT_BLOCKED", response.Error.Code)
+	assert.Contains(t, response.Error.Message, "unpaid fines")
+	mockService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_BorrowBook_ByBarcode(t *testing.T) {
+	router, mockService := setupTransactionRouter()
+
+	requestBody := map[string]interface{}{
+		"student_id":   1,
+		"barcode":      "BK001-002",
+		"librarian_id": 1,
+	}
+
+	expectedResponse := createTestTransactionResponse()
+	expectedResponse.Barcode = "BK001-002"
+
+	// Setup mock
+	mockService.On("BorrowBookByBarcode", mock.Anything, int32(1), "BK001-002", int32(1), "").Return(expectedResponse, nil)
+
+	// Create request
+	jsonBody, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/api/v1/transactions/borrow", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Perform request
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"barcode":"BK001-002"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_BorrowBook_MissingBookAndBarcode(t *testing.T) {
+	router, mockService := setupTransactionRouter()
+
+	requestBody := map[string]interface{}{
+		"student_id":   1,
+		"librarian_id": 1,
+	}
+
+	// Create request
+	jsonBody, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/api/v1/transactions/borrow", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Perform request
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_ReturnBookByBarcode(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		router, mockService := setupTransactionRouter()
+
+		expectedResponse := createTestTransactionResponse()
+		returnTime := time.Now()
+		expectedResponse.ReturnedDate = &returnTime
+
+		// Condition defaults to good when not provided
+		mockService.On("ReturnBookByBarcode", mock.Anything, "BK001-001", "good", "", mock.AnythingOfType("services.AuditActor")).Return(expectedResponse, nil)
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{"barcode": "BK001-001"})
+		req, _ := http.NewRequest("POST", "/api/v1/transactions/return", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("UnknownBarcode", func(t *testing.T) {
+		router, mockService := setupTransactionRouter()
+
+		mockService.On("ReturnBookByBarcode", mock.Anything, "NOPE", "fair", "", mock.AnythingOfType("services.AuditActor")).Return(nil, errors.New("book copy not found"))
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{"barcode": "NOPE", "return_condition": "fair"})
+		req, _ := http.NewRequest("POST", "/api/v1/transactions/return", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestTransactionHandler_MissingItems(t *testing.T) {
+	t.Run("MarkLost", func(t *testing.T) {
+		router, mockService := setupTransactionRouter()
+
+		expectedResponse := createTestTransactionResponse()
+		expectedResponse.LossStatus = "lost"
+		mockService.On("MarkLost", mock.Anything, int32(1), "Left on a bus", mock.AnythingOfType("services.AuditActor")).Return(expectedResponse, nil)
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{"notes": "Left on a bus"})
+		req, _ := http.NewRequest("POST", "/api/v1/transactions/1/lost", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"loss_status":"lost"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("MarkClaimedReturnedWithoutBody", func(t *testing.T) {
+		router, mockService := setupTransactionRouter()
+
+		mockService.On("MarkClaimedReturned", mock.Anything, int32(1), "", mock.AnythingOfType("services.AuditActor")).Return(nil, errors.New("transaction not found"))
+
+		req, _ := http.NewRequest("POST", "/api/v1/transactions/1/claimed-returned", nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("MarkFoundRejectsUnknownRefundMethod", func(t *testing.T) {
+		router, mockService := setupTransactionRouter()
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{"refund_method": "cheque"})
+		req, _ := http.NewRequest("POST", "/api/v1/transactions/1/found", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "MarkFound", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestTransactionHandler_RenewOwnLoan(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := &MockTransactionService{}
+	handler := NewTransactionHandler(mockService)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", 5)
+		c.Set("user_type", "student")
+	})
+	router.POST("/api/v1/students/me/loans/:id/renew", handler.RenewOwnLoan)
+	router.GET("/api/v1/students/me/loans/:id/can-renew", handler.CanRenewOwnLoan)
+
+	t.Run("RenewsForTokenStudent", func(t *testing.T) {
+		mockService.On("RenewOwnLoan", mock.Anything, int32(3), int32(5)).Return(createTestTransactionResponse(), nil).Once()
+
+		req, _ := http.NewRequest("POST", "/api/v1/students/me/loans/3/renew", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("NotOwnLoan", func(t *testing.T) {
+		mockService.On("RenewOwnLoan", mock.Anything, int32(4), int32(5)).Return(nil, errors.New("transaction not found")).Once()
+
+		req, _ := http.NewRequest("POST", "/api/v1/students/me/loans/4/renew", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("CanRenew", func(t *testing.T) {
+		mockService.On("CanRenewOwnLoan", mock.Anything, int32(3), int32(5)).Return(false, "Book is overdue and must be returned first", nil).Once()
+
+		req, _ := http.NewRequest("GET", "/api/v1/students/me/loans/3/can-renew", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "must be returned first")
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_BatchBorrow(t *testing.T) {
+	t.Run("AllIssued", func(t *testing.T) {
+		router, mockService := setupTransactionRouter()
+
+		mockService.On("BatchBorrow", mock.Anything, mock.MatchedBy(func(req models.BatchBorrowRequest) bool {
+			return req.StudentID == 1 && len(req.Items) == 2
+		})).Return([]services.BatchItemResult{
+			{Index: 0, Transaction: createTestTransactionResponse()},
+			{Index: 1, Transaction: createTestTransactionResponse()},
+		}, nil)
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{
+			"student_id":   1,
+			"librarian_id": 1,
+			"items":        []map[string]interface{}{{"book_id": 1}, {"barcode": "BK002-001"}},
+		})
+		req, _ := http.NewRequest("POST", "/api/v1/transactions/batch/borrow", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"success_count":2`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("OverLoanLimit", func(t *testing.T) {
+		router, mockService := setupTransactionRouter()
+
+		mockService.On("BatchBorrow", mock.Anything, mock.Anything).Return(nil, errors.New("student has 4 books on loan; borrowing 2 more would exceed the maximum of 5"))
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{
+			"student_id":   1,
+			"librarian_id": 1,
+			"items":        []map[string]interface{}{{"book_id": 1}, {"book_id": 2}},
+		})
+		req, _ := http.NewRequest("POST", "/api/v1/transactions/batch/borrow", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("EmptyItems", func(t *testing.T) {
+		router, mockService := setupTransactionRouter()
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{"student_id": 1, "librarian_id": 1, "items": []interface{}{}})
+		req, _ := http.NewRequest("POST", "/api/v1/transactions/batch/borrow", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "BatchBorrow", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTransactionHandler_BatchReturn(t *testing.T) {
+	t.Run("RolledBack", func(t *testing.T) {
+		router, mockService := setupTransactionRouter()
+
+		mockService.On("BatchReturn", mock.Anything, mock.Anything, mock.AnythingOfType("services.AuditActor")).Return([]services.BatchItemResult{
+			{Index: 0, Err: errors.New("not processed: another item in the batch failed and the batch was rolled back")},
+			{Index: 1, Err: errors.New("no active loan found for copy BK002-001")},
+		}, nil)
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{
+			"items": []map[string]interface{}{{"transaction_id": 1}, {"barcode": "BK002-001"}},
+		})
+		req, _ := http.NewRequest("POST", "/api/v1/transactions/batch/return", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "no active loan found for copy BK002-001")
+	})
+
+	t.Run("BestEffortPartial", func(t *testing.T) {
+		router, mockService := setupTransactionRouter()
+
+		mockService.On("BatchReturn", mock.Anything, mock.MatchedBy(func(req models.BatchReturnRequest) bool {
+			return req.Mode == models.BatchModeBestEffort
+		}), mock.AnythingOfType("services.AuditActor")).Return([]services.BatchItemResult{
+			{Index: 0, Transaction: createTestTransactionResponse()},
+			{Index: 1, Err: errors.New("book has already been returned")},
+		}, nil)
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{
+			"mode":  "best_effort",
+			"items": []map[string]interface{}{{"transaction_id": 1, "return_condition": "fair"}, {"transaction_id": 2}},
+		})
+		req, _ := http.NewRequest("POST", "/api/v1/transactions/batch/return", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "1 of 2 books returned; 1 failed")
+	})
+}
+
 func TestTransactionHandler_ReturnBook_Success(t *testing.T) {
 	router, mockService := setupTransactionRouter()
 
@@ -236,7 +647,7 @@ func TestTransactionHandler_ReturnBook_Success(t *testing.T) {
 	expectedResponse.ReturnedDate = &returnTime
 
 	// Setup mock
-	mockService.On("ReturnBook", mock.Anything, int32(1)).Return(expectedResponse, nil)
+	mockService.On("ReturnBook", mock.Anything, int32(1), mock.AnythingOfType("services.AuditActor")).Return(expectedResponse, nil)
 
 	// Create request
 	req, _ := http.NewRequest("POST", "/api/v1/transactions/"+transactionID+"/return", nil)
@@ -359,7 +770,9 @@ func TestTransactionHandler_PayFine_Success(t *testing.T) {
 	transactionID := "1"
 
 	// Setup mock
-	mockService.On("PayFine", mock.Anything, int32(1)).Return(nil)
+	mockService.On("PayFine", mock.Anything, int32(1), models.PayTransactionFineRequest{}, mock.AnythingOfType("services.AuditActor")).Return([]models.FineLedgerResultResponse{
+		{Entry: models.FineLedgerEntryResponse{ID: 3, EntryType: models.FineLedgerEntryPayment}},
+	}, nil)
 
 	// Create request
 	req, _ := http.NewRequest("POST", "/api/v1/transactions/"+transactionID+"/pay-fine", nil)
@@ -380,6 +793,24 @@ func TestTransactionHandler_PayFine_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestTransactionHandler_PayFine_NoOutstandingFine(t *testing.T) {
+	router, mockService := setupTransactionRouter()
+
+	mockService.On("PayFine", mock.Anything, int32(2), models.PayTransactionFineRequest{PaymentMethod: "card"}, mock.AnythingOfType("services.AuditActor")).
+		Return(nil, errors.New("validation error: transaction 2 has no outstanding fine"))
+
+	body, _ := json.Marshal(map[string]string{"payment_method": "card"})
+	req, _ := http.NewRequest("POST", "/api/v1/transactions/2/pay-fine", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "no outstanding fine")
+	mockService.AssertExpectations(t)
+}
+
 func TestTransactionHandler_GetTransactionHistory_Success(t *testing.T) {
 	router, mockService := setupTransactionRouter()
 
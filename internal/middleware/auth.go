@@ -72,6 +72,22 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	}
 }
 
+// RequireStreamAuth is RequireAuth for event streams. Browsers cannot set headers
+// on EventSource or WebSocket connections, so the access token may be given in
+// the access_token query parameter instead.
+func (m *AuthMiddleware) RequireStreamAuth() gin.HandlerFunc {
+	requireAuth := m.RequireAuth()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+
+		requireAuth(c)
+	}
+}
+
 func (m *AuthMiddleware) RequireRole(allowedRoles ...models.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
@@ -130,6 +146,25 @@ func (m *AuthMiddleware) RequireLibrarianOrAdmin() gin.HandlerFunc {
 	return m.RequireRole(models.RoleAdmin, models.RoleLibrarian)
 }
 
+// RequireStudent allows only students, for self-service routes that act on the caller's own record
+func (m *AuthMiddleware) RequireStudent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if GetUserType(c) != "student" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":    "STUDENT_ONLY",
+					"message": "This resource is only available to students",
+				},
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 func (m *AuthMiddleware) RequireStudentOrLibrarian() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userType, exists := c.Get("user_type")
@@ -374,6 +374,128 @@ func TestAuthMiddleware_RequireStudentOrLibrarian(t *testing.T) {
 	}
 }
 
+func TestAuthMiddleware_RequireStudent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authService := createTestAuthService()
+	middleware := NewAuthMiddleware(authService)
+
+	tests := []struct {
+		name           string
+		userType       string
+		expectedStatus int
+		shouldPass     bool
+	}{
+		{
+			name:           "student access",
+			userType:       "student",
+			expectedStatus: http.StatusOK,
+			shouldPass:     true,
+		},
+		{
+			name:           "librarian denied",
+			userType:       "librarian",
+			expectedStatus: http.StatusForbidden,
+			shouldPass:     false,
+		},
+		{
+			name:           "missing user type denied",
+			userType:       "",
+			expectedStatus: http.StatusForbidden,
+			shouldPass:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			c.Set("user_id", 1)
+			if tt.userType != "" {
+				c.Set("user_type", tt.userType)
+			}
+
+			handlerCalled := false
+			middleware.RequireStudent()(c)
+			if !c.IsAborted() {
+				handlerCalled = true
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			}
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.shouldPass, handlerCalled)
+		})
+	}
+}
+
+func TestAuthMiddleware_RequireStreamAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authService := createTestAuthService()
+	middleware := NewAuthMiddleware(authService)
+
+	validToken, _, err := authService.GenerateTokens(&models.User{ID: 1, Username: "testuser", Role: models.RoleLibrarian}, "librarian")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		url            string
+		authHeader     string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "token in query parameter",
+			url:            "/events/stream?access_token=" + validToken,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "token in header",
+			url:            "/events/stream",
+			authHeader:     "Bearer " + validToken,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid query token",
+			url:            "/events/stream?access_token=invalid-token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "INVALID_TOKEN",
+		},
+		{
+			name:           "no token",
+			url:            "/events/stream",
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "MISSING_AUTH_HEADER",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			c.Request = req
+
+			middleware.RequireStreamAuth()(c)
+			if !c.IsAborted() {
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			}
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			} else {
+				assert.Equal(t, 1, GetUserID(c))
+			}
+		})
+	}
+}
+
 func TestAuthMiddleware_HelperFunctions(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// BackgroundJobStatus describes a background job's run lease and how its last run went
+type BackgroundJobStatus struct {
+	Name           string     `json:"name"`
+	Running        bool       `json:"running"`
+	LockedBy       *string    `json:"locked_by,omitempty"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	LastStatus     *string    `json:"last_status,omitempty"`
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty"`
+	LastSuccessAt  *time.Time `json:"last_success_at,omitempty"`
+	LastError      *string    `json:"last_error,omitempty"`
+}
+
+// ScheduledJobResponse describes a job run by the scheduler
+type ScheduledJobResponse struct {
+	Name string `json:"name"`
+	// Schedule is the job's cron expression; empty if it only runs when triggered
+	Schedule  string          `json:"schedule,omitempty"`
+	Paused    bool            `json:"paused"`
+	PausedAt  *time.Time      `json:"paused_at,omitempty"`
+	PausedBy  *int32          `json:"paused_by,omitempty"`
+	NextRunAt *time.Time      `json:"next_run_at,omitempty"`
+	LastRun   *JobRunResponse `json:"last_run,omitempty"`
+}
+
+// JobRunResponse describes one run of a scheduled job
+type JobRunResponse struct {
+	ID          int32      `json:"id"`
+	JobName     string     `json:"job_name"`
+	TriggerType string     `json:"trigger_type"`
+	TriggeredBy *int32     `json:"triggered_by,omitempty"`
+	InstanceID  string     `json:"instance_id"`
+	Status      string     `json:"status"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	DurationMs  *int64     `json:"duration_ms,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+}
@@ -249,12 +249,9 @@ func (r *UpdateBookRequest) Validate() error {
 		}
 	}
 
-	// Validate copies
-	if r.TotalCopies != nil && *r.TotalCopies < 0 {
-		return errors.New("total_copies cannot be negative")
-	}
-	if r.AvailableCopies != nil && *r.AvailableCopies < 0 {
-		return errors.New("available_copies cannot be negative")
+	// Copy counts follow the book's registered copies
+	if r.TotalCopies != nil || r.AvailableCopies != nil {
+		return errors.New("total_copies and available_copies cannot be updated; add or withdraw copies instead")
 	}
 
 	return nil
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// BookCopyStatus represents the circulation status of a physical copy
+type BookCopyStatus string
+
+const (
+	BookCopyStatusAvailable   BookCopyStatus = "available"
+	BookCopyStatusBorrowed    BookCopyStatus = "borrowed"
+	BookCopyStatusOnHold      BookCopyStatus = "on_hold"
+	BookCopyStatusMaintenance BookCopyStatus = "maintenance"
+	BookCopyStatusLost        BookCopyStatus = "lost"
+	BookCopyStatusWithdrawn   BookCopyStatus = "withdrawn"
+)
+
+// ValidCopyConditions lists the accepted condition values for a copy
+var ValidCopyConditions = []string{"excellent", "good", "fair", "poor", "damaged"}
+
+// CreateBookCopyRequest represents the request to add a physical copy to a book
+type CreateBookCopyRequest struct {
+	Barcode       string  `json:"barcode" binding:"omitempty,max=100"`
+	Condition     *string `json:"condition" binding:"omitempty,oneof=excellent good fair poor damaged"`
+	ShelfLocation *string `json:"shelf_location" binding:"omitempty,max=50"`
+	Notes         *string `json:"notes" binding:"omitempty,max=1000"`
+}
+
+// UpdateBookCopyRequest represents the request to update a physical copy
+type UpdateBookCopyRequest struct {
+	Condition     *string `json:"condition" binding:"omitempty,oneof=excellent good fair poor damaged"`
+	ShelfLocation *string `json:"shelf_location" binding:"omitempty,max=50"`
+	Status        *string `json:"status" binding:"omitempty,oneof=available maintenance lost withdrawn"`
+	Notes         *string `json:"notes" binding:"omitempty,max=1000"`
+}
+
+// BookCopyResponse represents the response for copy operations
+type BookCopyResponse struct {
+	ID            int32          `json:"id"`
+	BookID        int32          `json:"book_id"`
+	Barcode       string         `json:"barcode"`
+	Condition     string         `json:"condition"`
+	ShelfLocation *string        `json:"shelf_location"`
+	Status        BookCopyStatus `json:"status"`
+	Notes         *string        `json:"notes,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// Validate validates the CreateBookCopyRequest
+func (r *CreateBookCopyRequest) Validate() error {
+	r.Barcode = strings.TrimSpace(r.Barcode)
+	if len(r.Barcode) > 100 {
+		return errors.New("barcode cannot exceed 100 characters")
+	}
+
+	if r.Condition != nil && !isValidCopyCondition(*r.Condition) {
+		return errors.New("condition must be one of excellent, good, fair, poor, damaged")
+	}
+
+	return nil
+}
+
+// Validate validates the UpdateBookCopyRequest
+func (r *UpdateBookCopyRequest) Validate() error {
+	if r.Condition != nil && !isValidCopyCondition(*r.Condition) {
+		return errors.New("condition must be one of excellent, good, fair, poor, damaged")
+	}
+
+	if r.Status != nil {
+		switch BookCopyStatus(*r.Status) {
+		case BookCopyStatusAvailable, BookCopyStatusMaintenance, BookCopyStatusLost, BookCopyStatusWithdrawn:
+		default:
+			return errors.New("status must be one of available, maintenance, lost, withdrawn")
+		}
+	}
+
+	return nil
+}
+
+func isValidCopyCondition(condition string) bool {
+	for _, valid := range ValidCopyConditions {
+		if condition == valid {
+			return true
+		}
+	}
+	return false
+}
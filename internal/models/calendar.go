@@ -0,0 +1,140 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Layouts used by the library calendar API
+const (
+	CalendarDateLayout = "2006-01-02"
+	CalendarTimeLayout = "15:04"
+)
+
+// UpdateOpeningHoursRequest represents the request to set the opening hours for a weekday
+type UpdateOpeningHoursRequest struct {
+	OpensAt  string `json:"opens_at"`
+	ClosesAt string `json:"closes_at"`
+	IsClosed bool   `json:"is_closed"`
+}
+
+// OpeningHoursResponse represents the opening hours for a weekday
+type OpeningHoursResponse struct {
+	DayOfWeek int     `json:"day_of_week"`
+	Day       string  `json:"day"`
+	OpensAt   *string `json:"opens_at"`
+	ClosesAt  *string `json:"closes_at"`
+	IsClosed  bool    `json:"is_closed"`
+}
+
+// CreateLibraryClosureRequest represents the request to add a holiday or break.
+// EndDate defaults to StartDate for single-day closures.
+type CreateLibraryClosureRequest struct {
+	Name           string  `json:"name" binding:"required,min=1,max=100"`
+	Description    *string `json:"description" binding:"omitempty,max=1000"`
+	StartDate      string  `json:"start_date" binding:"required"`
+	EndDate        string  `json:"end_date"`
+	RecursAnnually bool    `json:"recurs_annually"`
+}
+
+// UpdateLibraryClosureRequest represents the request to update a holiday or break
+type UpdateLibraryClosureRequest struct {
+	Name           *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description    *string `json:"description" binding:"omitempty,max=1000"`
+	StartDate      *string `json:"start_date"`
+	EndDate        *string `json:"end_date"`
+	RecursAnnually *bool   `json:"recurs_annually"`
+}
+
+// LibraryClosureResponse represents a holiday or break when the library is closed
+type LibraryClosureResponse struct {
+	ID             int32     `json:"id"`
+	Name           string    `json:"name"`
+	Description    *string   `json:"description,omitempty"`
+	StartDate      string    `json:"start_date"`
+	EndDate        string    `json:"end_date"`
+	RecursAnnually bool      `json:"recurs_annually"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Validate validates the UpdateOpeningHoursRequest
+func (r *UpdateOpeningHoursRequest) Validate() error {
+	if r.IsClosed {
+		return nil
+	}
+
+	opensAt, err := time.Parse(CalendarTimeLayout, r.OpensAt)
+	if err != nil {
+		return errors.New("opens_at must be in HH:MM format")
+	}
+
+	closesAt, err := time.Parse(CalendarTimeLayout, r.ClosesAt)
+	if err != nil {
+		return errors.New("closes_at must be in HH:MM format")
+	}
+
+	if !closesAt.After(opensAt) {
+		return errors.New("closes_at must be after opens_at")
+	}
+
+	return nil
+}
+
+// Validate validates the CreateLibraryClosureRequest
+func (r *CreateLibraryClosureRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if r.EndDate == "" {
+		r.EndDate = r.StartDate
+	}
+
+	return validateClosureDates(r.StartDate, r.EndDate, r.RecursAnnually)
+}
+
+// Validate validates the UpdateLibraryClosureRequest against the closure being updated
+func (r *UpdateLibraryClosureRequest) Validate(startDate, endDate string, recursAnnually bool) error {
+	if r.Name != nil {
+		trimmed := strings.TrimSpace(*r.Name)
+		if trimmed == "" {
+			return errors.New("name cannot be empty")
+		}
+		r.Name = &trimmed
+	}
+
+	if r.StartDate != nil {
+		startDate = *r.StartDate
+	}
+	if r.EndDate != nil {
+		endDate = *r.EndDate
+	}
+	if r.RecursAnnually != nil {
+		recursAnnually = *r.RecursAnnually
+	}
+
+	return validateClosureDates(startDate, endDate, recursAnnually)
+}
+
+// validateClosureDates checks the date format and order; annual closures may
+// wrap over the new year so their end can fall before their start
+func validateClosureDates(startDate, endDate string, recursAnnually bool) error {
+	start, err := time.Parse(CalendarDateLayout, startDate)
+	if err != nil {
+		return errors.New("start_date must be in YYYY-MM-DD format")
+	}
+
+	end, err := time.Parse(CalendarDateLayout, endDate)
+	if err != nil {
+		return errors.New("end_date must be in YYYY-MM-DD format")
+	}
+
+	if !recursAnnually && end.Before(start) {
+		return errors.New("end_date cannot be before start_date")
+	}
+
+	return nil
+}
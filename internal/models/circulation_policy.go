@@ -0,0 +1,248 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PatronType identifies the class of borrower a circulation policy applies to
+type PatronType string
+
+const (
+	PatronTypeStudent   PatronType = "student"
+	PatronTypeStaff     PatronType = "staff"
+	PatronTypeLibrarian PatronType = "librarian"
+	PatronTypeAdmin     PatronType = "admin"
+)
+
+// CreateCirculationPolicyRequest represents the request to create a circulation policy.
+// Empty matching fields (year, department, user type, item type) match any value.
+type CreateCirculationPolicyRequest struct {
+	Name            string          `json:"name" binding:"required,min=1,max=100"`
+	Description     *string         `json:"description" binding:"omitempty,max=1000"`
+	YearOfStudy     *int32          `json:"year_of_study" binding:"omitempty,min=1,max=8"`
+	Department      *string         `json:"department" binding:"omitempty,max=100"`
+	UserType        *string         `json:"user_type" binding:"omitempty,oneof=student staff librarian admin"`
+	ItemType        *string         `json:"item_type" binding:"omitempty,max=100"`
+	LoanDays        int32           `json:"loan_days" binding:"required,min=1"`
+	MaxLoans        int32           `json:"max_loans" binding:"min=0"`
+	MaxRenewals     int32           `json:"max_renewals" binding:"min=0"`
+	FinePerDay      decimal.Decimal `json:"fine_per_day"`
+	GracePeriodDays int32           `json:"grace_period_days" binding:"min=0"`
+	MaxReservations int32           `json:"max_reservations" binding:"min=0"`
+	ReservationDays int32           `json:"reservation_days" binding:"required,min=1"`
+	Priority        int32           `json:"priority"`
+	IsActive        *bool           `json:"is_active"`
+	// Lost and damaged item charges; the library defaults apply when omitted
+	ReplacementCost     *decimal.Decimal `json:"replacement_cost"`
+	ProcessingFee       *decimal.Decimal `json:"processing_fee"`
+	DamageChargePerStep *decimal.Decimal `json:"damage_charge_per_step"`
+	DamageStepThreshold *int32           `json:"damage_step_threshold" binding:"omitempty,min=1"`
+	// Guaranteed loan period and overdue rate for recalled loans
+	RecallLoanDays   *int32           `json:"recall_loan_days" binding:"omitempty,min=1"`
+	RecallFinePerDay *decimal.Decimal `json:"recall_fine_per_day"`
+	// Place in reservation queues; reservations under a higher priority go first
+	ReservationPriority *int32 `json:"reservation_priority"`
+}
+
+// UpdateCirculationPolicyRequest represents the request to update a circulation policy.
+// Only provided fields are changed; send an empty string to clear a matching field.
+type UpdateCirculationPolicyRequest struct {
+	Name            *string          `json:"name" binding:"omitempty,min=1,max=100"`
+	Description     *string          `json:"description" binding:"omitempty,max=1000"`
+	YearOfStudy     *int32           `json:"year_of_study" binding:"omitempty,min=0,max=8"`
+	Department      *string          `json:"department" binding:"omitempty,max=100"`
+	UserType        *string          `json:"user_type" binding:"omitempty,max=20"`
+	ItemType        *string          `json:"item_type" binding:"omitempty,max=100"`
+	LoanDays        *int32           `json:"loan_days" binding:"omitempty,min=1"`
+	MaxLoans        *int32           `json:"max_loans" binding:"omitempty,min=0"`
+	MaxRenewals     *int32           `json:"max_renewals" binding:"omitempty,min=0"`
+	FinePerDay      *decimal.Decimal `json:"fine_per_day"`
+	GracePeriodDays *int32           `json:"grace_period_days" binding:"omitempty,min=0"`
+	MaxReservations *int32           `json:"max_reservations" binding:"omitempty,min=0"`
+	ReservationDays *int32           `json:"reservation_days" binding:"omitempty,min=1"`
+	Priority        *int32           `json:"priority"`
+	IsActive        *bool            `json:"is_active"`
+
+	ReplacementCost     *decimal.Decimal `json:"replacement_cost"`
+	ProcessingFee       *decimal.Decimal `json:"processing_fee"`
+	DamageChargePerStep *decimal.Decimal `json:"damage_charge_per_step"`
+	DamageStepThreshold *int32           `json:"damage_step_threshold" binding:"omitempty,min=1"`
+	// Guaranteed loan period and overdue rate for recalled loans
+	RecallLoanDays   *int32           `json:"recall_loan_days" binding:"omitempty,min=1"`
+	RecallFinePerDay *decimal.Decimal `json:"recall_fine_per_day"`
+	// Place in reservation queues; reservations under a higher priority go first
+	ReservationPriority *int32 `json:"reservation_priority"`
+}
+
+// CirculationPolicyResponse represents the response for circulation policy operations
+type CirculationPolicyResponse struct {
+	ID              int32           `json:"id"`
+	Name            string          `json:"name"`
+	Description     *string         `json:"description,omitempty"`
+	YearOfStudy     *int32          `json:"year_of_study"`
+	Department      *string         `json:"department"`
+	UserType        *string         `json:"user_type"`
+	ItemType        *string         `json:"item_type"`
+	LoanDays        int32           `json:"loan_days"`
+	MaxLoans        int32           `json:"max_loans"`
+	MaxRenewals     int32           `json:"max_renewals"`
+	FinePerDay      decimal.Decimal `json:"fine_per_day"`
+	GracePeriodDays int32           `json:"grace_period_days"`
+	MaxReservations int32           `json:"max_reservations"`
+	ReservationDays int32           `json:"reservation_days"`
+	Priority        int32           `json:"priority"`
+	IsActive        bool            `json:"is_active"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+
+	ReplacementCost     decimal.Decimal `json:"replacement_cost"`
+	ProcessingFee       decimal.Decimal `json:"processing_fee"`
+	DamageChargePerStep decimal.Decimal `json:"damage_charge_per_step"`
+	DamageStepThreshold int32           `json:"damage_step_threshold"`
+
+	RecallLoanDays   int32           `json:"recall_loan_days"`
+	RecallFinePerDay decimal.Decimal `json:"recall_fine_per_day"`
+
+	ReservationPriority int32 `json:"reservation_priority"`
+}
+
+// Validate validates the CreateCirculationPolicyRequest
+func (r *CreateCirculationPolicyRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if r.LoanDays < 1 {
+		return errors.New("loan_days must be at least 1")
+	}
+
+	if r.ReservationDays < 1 {
+		return errors.New("reservation_days must be at least 1")
+	}
+
+	if r.MaxLoans < 0 || r.MaxRenewals < 0 || r.MaxReservations < 0 || r.GracePeriodDays < 0 {
+		return errors.New("limits and grace period cannot be negative")
+	}
+
+	if r.FinePerDay.IsNegative() {
+		return errors.New("fine_per_day cannot be negative")
+	}
+
+	if err := validateCharges(r.ReplacementCost, r.ProcessingFee, r.DamageChargePerStep, r.DamageStepThreshold); err != nil {
+		return err
+	}
+
+	if err := validateRecall(r.RecallLoanDays, r.RecallFinePerDay); err != nil {
+		return err
+	}
+
+	if r.YearOfStudy != nil && (*r.YearOfStudy < 1 || *r.YearOfStudy > 8) {
+		return errors.New("year_of_study must be between 1 and 8")
+	}
+
+	if r.UserType != nil && *r.UserType != "" && !isValidPatronType(*r.UserType) {
+		return errors.New("user_type must be one of student, staff, librarian, admin")
+	}
+
+	return nil
+}
+
+// Validate validates the UpdateCirculationPolicyRequest
+func (r *UpdateCirculationPolicyRequest) Validate() error {
+	if r.Name != nil {
+		trimmed := strings.TrimSpace(*r.Name)
+		if trimmed == "" {
+			return errors.New("name cannot be empty")
+		}
+		r.Name = &trimmed
+	}
+
+	if r.LoanDays != nil && *r.LoanDays < 1 {
+		return errors.New("loan_days must be at least 1")
+	}
+
+	if r.ReservationDays != nil && *r.ReservationDays < 1 {
+		return errors.New("reservation_days must be at least 1")
+	}
+
+	for _, v := range []*int32{r.MaxLoans, r.MaxRenewals, r.MaxReservations, r.GracePeriodDays} {
+		if v != nil && *v < 0 {
+			return errors.New("limits and grace period cannot be negative")
+		}
+	}
+
+	if r.FinePerDay != nil && r.FinePerDay.IsNegative() {
+		return errors.New("fine_per_day cannot be negative")
+	}
+
+	if err := validateCharges(r.ReplacementCost, r.ProcessingFee, r.DamageChargePerStep, r.DamageStepThreshold); err != nil {
+		return err
+	}
+
+	if err := validateRecall(r.RecallLoanDays, r.RecallFinePerDay); err != nil {
+		return err
+	}
+
+	// A year of 0 clears the year condition
+	if r.YearOfStudy != nil && (*r.YearOfStudy < 0 || *r.YearOfStudy > 8) {
+		return errors.New("year_of_study must be between 1 and 8, or 0 to match any year")
+	}
+
+	if r.UserType != nil && *r.UserType != "" && !isValidPatronType(*r.UserType) {
+		return errors.New("user_type must be one of student, staff, librarian, admin")
+	}
+
+	return nil
+}
+
+// validateCharges checks the optional lost and damaged item charges of a policy
+func validateCharges(replacementCost, processingFee, damageChargePerStep *decimal.Decimal, damageStepThreshold *int32) error {
+	for _, charge := range []*decimal.Decimal{replacementCost, processingFee, damageChargePerStep} {
+		if charge == nil {
+			continue
+		}
+		if charge.IsNegative() {
+			return errors.New("replacement and damage charges cannot be negative")
+		}
+		if err := validateMoney(*charge); err != nil {
+			return err
+		}
+	}
+
+	if damageStepThreshold != nil && *damageStepThreshold < 1 {
+		return errors.New("damage_step_threshold must be at least 1")
+	}
+
+	return nil
+}
+
+// validateRecall checks the optional recall loan period and overdue rate of a policy
+func validateRecall(recallLoanDays *int32, recallFinePerDay *decimal.Decimal) error {
+	if recallLoanDays != nil && *recallLoanDays < 1 {
+		return errors.New("recall_loan_days must be at least 1")
+	}
+
+	if recallFinePerDay != nil {
+		if recallFinePerDay.IsNegative() {
+			return errors.New("recall_fine_per_day cannot be negative")
+		}
+		if err := validateMoney(*recallFinePerDay); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isValidPatronType(userType string) bool {
+	switch PatronType(userType) {
+	case PatronTypeStudent, PatronTypeStaff, PatronTypeLibrarian, PatronTypeAdmin:
+		return true
+	}
+	return false
+}
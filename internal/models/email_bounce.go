@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// EmailBounceConfig is how bounce reports are received and acted on
+type EmailBounceConfig struct {
+	// BounceAddress is the return path emails are sent with. Each email's
+	// return path carries its signed delivery ID (bounces+42-<signature>@example.com)
+	// so that its bounce can be matched to it; bounces are not tracked when empty.
+	BounceAddress string `json:"bounce_address"`
+	// BounceSecret is the key delivery IDs in return paths are signed with.
+	// Bounces are only matched by the Message-ID of the bounced email when empty.
+	BounceSecret string `json:"-"`
+	// WebhookToken must be given by whatever posts bounce messages to the
+	// webhook; the webhook is off when empty
+	WebhookToken string `json:"webhook_token"`
+	// Maildir is the mailbox the bounce address delivers to. It is polled for
+	// bounce messages when set.
+	Maildir string `json:"maildir"`
+	// HardBounceThreshold is how many hard bounces suppress an address
+	HardBounceThreshold int `json:"hard_bounce_threshold"`
+}
+
+// BounceType is whether a bounce is permanent
+type BounceType string
+
+const (
+	// BounceTypeHard is a permanent failure, such as an unknown mailbox
+	BounceTypeHard BounceType = "hard"
+	// BounceTypeSoft is a temporary failure the receiving server gave up on,
+	// such as a full mailbox
+	BounceTypeSoft BounceType = "soft"
+)
+
+// EmailBounce is one recipient a bounce message reports as failed, and what was
+// done about it
+type EmailBounce struct {
+	EmailAddress string     `json:"email_address"`
+	Type         BounceType `json:"type"`
+	// Status is the enhanced status code, such as 5.1.1
+	Status     string `json:"status"`
+	Diagnostic string `json:"diagnostic,omitempty"`
+	// DeliveryID is the email delivery the bounce was matched to; bounces that
+	// match no delivery are ignored
+	DeliveryID *int32 `json:"delivery_id,omitempty"`
+	// Suppressed reports whether email to the address is now suppressed
+	Suppressed bool `json:"suppressed"`
+}
+
+// EmailBounceResult is what processing a bounce message did
+type EmailBounceResult struct {
+	Bounces []EmailBounce `json:"bounces"`
+}
+
+// StudentEmailIssue is a student whose email address has been suppressed after
+// bouncing, and needs correcting
+type StudentEmailIssue struct {
+	ID               int32      `json:"id"`
+	StudentID        string     `json:"student_id"`
+	FirstName        string     `json:"first_name"`
+	LastName         string     `json:"last_name"`
+	Email            string     `json:"email"`
+	Phone            *string    `json:"phone,omitempty"`
+	Department       *string    `json:"department,omitempty"`
+	YearOfStudy      int32      `json:"year_of_study"`
+	HardBounceCount  int32      `json:"hard_bounce_count"`
+	LastBounceStatus *string    `json:"last_bounce_status,omitempty"`
+	LastBounceReason *string    `json:"last_bounce_reason,omitempty"`
+	LastBouncedAt    *time.Time `json:"last_bounced_at,omitempty"`
+	SuppressedAt     time.Time  `json:"suppressed_at"`
+}
+
+// StudentEmailIssueListResponse is a page of students whose email needs
+// correcting, most recently suppressed first
+type StudentEmailIssueListResponse struct {
+	Students   []StudentEmailIssue `json:"students"`
+	Pagination Pagination          `json:"pagination"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// Email template version statuses
+const (
+	TemplateVersionDraft     = "draft"
+	TemplateVersionPublished = "published"
+	TemplateVersionArchived  = "archived"
+)
+
+// Line operations in a template diff
+const (
+	TemplateDiffEqual   = "equal"
+	TemplateDiffAdded   = "added"
+	TemplateDiffRemoved = "removed"
+)
+
+// CreateEmailTemplateRequest represents the request to create a stored email
+// template. Its content becomes version 1, published straight away if Publish is
+// set. Locale defaults to English.
+type CreateEmailTemplateRequest struct {
+	Name        string  `json:"name" binding:"required,min=1,max=100"`
+	Locale      string  `json:"locale" binding:"omitempty,oneof=en sw"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+	EmailTemplateVersionRequest
+}
+
+// EmailTemplateVersionRequest represents the content of a new template version
+type EmailTemplateVersionRequest struct {
+	Subject    string   `json:"subject" binding:"required"`
+	Body       string   `json:"body" binding:"required"`
+	IsHTML     bool     `json:"is_html"`
+	Variables  []string `json:"variables"`
+	ChangeNote *string  `json:"change_note" binding:"omitempty,max=500"`
+	Publish    bool     `json:"publish"`
+}
+
+// PreviewEmailTemplateRequest represents the request to render a template version
+// with sample data. Version 0 previews the latest version, and variables missing
+// from Data are filled with placeholders.
+type PreviewEmailTemplateRequest struct {
+	Version int32                  `json:"version"`
+	Data    map[string]interface{} `json:"data"`
+}
+
+// EmailTemplateResponse represents a stored email template and its published content
+type EmailTemplateResponse struct {
+	ID               int32                         `json:"id"`
+	Name             string                        `json:"name"`
+	Locale           string                        `json:"locale"`
+	Description      *string                       `json:"description,omitempty"`
+	LatestVersion    int32                         `json:"latest_version"`
+	PublishedVersion *int32                        `json:"published_version,omitempty"`
+	Published        *EmailTemplateVersionResponse `json:"published,omitempty"`
+	CreatedBy        *int32                        `json:"created_by,omitempty"`
+	CreatedAt        time.Time                     `json:"created_at"`
+	UpdatedAt        time.Time                     `json:"updated_at"`
+}
+
+// EmailTemplateVersionResponse represents one saved version of an email template
+type EmailTemplateVersionResponse struct {
+	ID          int32      `json:"id"`
+	Version     int32      `json:"version"`
+	Subject     string     `json:"subject"`
+	Body        string     `json:"body"`
+	IsHTML      bool       `json:"is_html"`
+	Variables   []string   `json:"variables"`
+	Status      string     `json:"status"`
+	ChangeNote  *string    `json:"change_note,omitempty"`
+	CreatedBy   *int32     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// EmailTemplateDiffResponse represents the changes between two template versions
+type EmailTemplateDiffResponse struct {
+	Name             string             `json:"name"`
+	Locale           string             `json:"locale"`
+	FromVersion      int32              `json:"from_version"`
+	ToVersion        int32              `json:"to_version"`
+	Subject          []TemplateDiffLine `json:"subject"`
+	Body             []TemplateDiffLine `json:"body"`
+	IsHTMLChanged    bool               `json:"is_html_changed"`
+	AddedVariables   []string           `json:"added_variables"`
+	RemovedVariables []string           `json:"removed_variables"`
+}
+
+// TemplateDiffLine is one line of a template diff
+type TemplateDiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
@@ -0,0 +1,249 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FineType identifies why a fine was charged
+type FineType string
+
+const (
+	FineTypeOverdue FineType = "overdue"
+	FineTypeDamage  FineType = "damage"
+	FineTypeLost    FineType = "lost"
+	FineTypeOther   FineType = "other"
+)
+
+// FineStatus represents how much of a fine has been settled
+type FineStatus string
+
+const (
+	FineStatusOutstanding   FineStatus = "outstanding"
+	FineStatusPartiallyPaid FineStatus = "partially_paid"
+	FineStatusPaid          FineStatus = "paid"
+	FineStatusWaived        FineStatus = "waived"
+)
+
+// FineLedgerEntryType identifies the kind of ledger entry recorded against a fine
+type FineLedgerEntryType string
+
+const (
+	FineLedgerEntryPayment    FineLedgerEntryType = "payment"
+	FineLedgerEntryWaiver     FineLedgerEntryType = "waiver"
+	FineLedgerEntryRefund     FineLedgerEntryType = "refund"
+	FineLedgerEntryAdjustment FineLedgerEntryType = "adjustment"
+)
+
+// PaymentMethod identifies how money was collected or refunded
+type PaymentMethod string
+
+const (
+	PaymentMethodCash         PaymentMethod = "cash"
+	PaymentMethodMpesa        PaymentMethod = "mpesa"
+	PaymentMethodCard         PaymentMethod = "card"
+	PaymentMethodBankTransfer PaymentMethod = "bank_transfer"
+)
+
+// CreateFineRequest represents the request to charge a student a fine manually
+type CreateFineRequest struct {
+	StudentID     int32           `json:"student_id" binding:"required,min=1"`
+	TransactionID *int32          `json:"transaction_id" binding:"omitempty,min=1"`
+	FineType      string          `json:"fine_type" binding:"required,oneof=overdue damage lost other"`
+	Description   *string         `json:"description" binding:"omitempty,max=1000"`
+	Amount        decimal.Decimal `json:"amount"`
+}
+
+// RecordFinePaymentRequest represents a payment taken against a fine
+type RecordFinePaymentRequest struct {
+	Amount        decimal.Decimal `json:"amount"`
+	PaymentMethod string          `json:"payment_method" binding:"required,oneof=cash mpesa card bank_transfer"`
+	Reference     *string         `json:"reference" binding:"omitempty,max=100"`
+}
+
+// WaiveFineRequest represents a waiver of part or all of a fine.
+// A nil amount waives the whole outstanding balance.
+type WaiveFineRequest struct {
+	Amount *decimal.Decimal `json:"amount"`
+	Reason string           `json:"reason" binding:"required,min=1,max=1000"`
+}
+
+// RefundFineRequest represents money returned to a student
+type RefundFineRequest struct {
+	Amount        decimal.Decimal `json:"amount"`
+	PaymentMethod string          `json:"payment_method" binding:"required,oneof=cash mpesa card bank_transfer"`
+	Reference     *string         `json:"reference" binding:"omitempty,max=100"`
+	Reason        string          `json:"reason" binding:"required,min=1,max=1000"`
+}
+
+// AdjustFineRequest represents a correction to the amount charged.
+// Amount is the signed change: positive increases the fine, negative reduces it.
+type AdjustFineRequest struct {
+	Amount decimal.Decimal `json:"amount"`
+	Reason string          `json:"reason" binding:"required,min=1,max=1000"`
+}
+
+// FineResponse represents a fine and its current balance
+type FineResponse struct {
+	ID            int32           `json:"id"`
+	StudentID     int32           `json:"student_id"`
+	TransactionID *int32          `json:"transaction_id,omitempty"`
+	FineType      FineType        `json:"fine_type"`
+	Description   *string         `json:"description,omitempty"`
+	Amount        decimal.Decimal `json:"amount"`
+	AmountPaid    decimal.Decimal `json:"amount_paid"`
+	AmountWaived  decimal.Decimal `json:"amount_waived"`
+	Balance       decimal.Decimal `json:"balance"`
+	Status        FineStatus      `json:"status"`
+	CreatedBy     *int32          `json:"created_by,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// FineLedgerEntryResponse represents a payment, waiver, refund or adjustment
+type FineLedgerEntryResponse struct {
+	ID                 int32               `json:"id"`
+	FineID             int32               `json:"fine_id"`
+	StudentID          int32               `json:"student_id"`
+	EntryType          FineLedgerEntryType `json:"entry_type"`
+	Amount             decimal.Decimal     `json:"amount"`
+	PaymentMethod      *string             `json:"payment_method,omitempty"`
+	Reference          *string             `json:"reference,omitempty"`
+	Reason             *string             `json:"reason,omitempty"`
+	ReceiptNumber      *string             `json:"receipt_number,omitempty"`
+	RecordedBy         *int32              `json:"recorded_by,omitempty"`
+	RecordedByUsername *string             `json:"recorded_by_username,omitempty"`
+	CreatedAt          time.Time           `json:"created_at"`
+}
+
+// FineLedgerResultResponse is returned when an entry is recorded against a fine
+type FineLedgerResultResponse struct {
+	Entry FineLedgerEntryResponse `json:"entry"`
+	Fine  FineResponse            `json:"fine"`
+}
+
+// StudentFineBalanceResponse represents what a student owes across all fines
+type StudentFineBalanceResponse struct {
+	StudentID        int32           `json:"student_id"`
+	Balance          decimal.Decimal `json:"balance"`
+	OutstandingFines int64           `json:"outstanding_fines"`
+	Fines            []FineResponse  `json:"fines"`
+}
+
+// CashReconciliationTotal summarises money taken by one staff member with one payment method
+type CashReconciliationTotal struct {
+	RecordedBy         *int32          `json:"recorded_by,omitempty"`
+	RecordedByUsername *string         `json:"recorded_by_username,omitempty"`
+	PaymentMethod      string          `json:"payment_method"`
+	PaymentCount       int             `json:"payment_count"`
+	RefundCount        int             `json:"refund_count"`
+	Collected          decimal.Decimal `json:"collected"`
+	Refunded           decimal.Decimal `json:"refunded"`
+	Net                decimal.Decimal `json:"net"`
+}
+
+// CashReconciliationResponse represents the money movements for one day at the desk
+type CashReconciliationResponse struct {
+	Date      string                    `json:"date"`
+	Collected decimal.Decimal           `json:"collected"`
+	Refunded  decimal.Decimal           `json:"refunded"`
+	Net       decimal.Decimal           `json:"net"`
+	Totals    []CashReconciliationTotal `json:"totals"`
+	Entries   []FineLedgerEntryResponse `json:"entries"`
+}
+
+// Validate validates the CreateFineRequest
+func (r *CreateFineRequest) Validate() error {
+	if !r.Amount.IsPositive() {
+		return errors.New("amount must be greater than zero")
+	}
+	return validateMoney(r.Amount)
+}
+
+// Validate validates the RecordFinePaymentRequest
+func (r *RecordFinePaymentRequest) Validate() error {
+	if !r.Amount.IsPositive() {
+		return errors.New("amount must be greater than zero")
+	}
+	if !isValidPaymentMethod(r.PaymentMethod) {
+		return errors.New("payment_method must be one of cash, mpesa, card, bank_transfer")
+	}
+	return validateMoney(r.Amount)
+}
+
+// Validate validates the WaiveFineRequest
+func (r *WaiveFineRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" {
+		return errors.New("reason is required")
+	}
+	if r.Amount != nil {
+		if !r.Amount.IsPositive() {
+			return errors.New("amount must be greater than zero")
+		}
+		return validateMoney(*r.Amount)
+	}
+	return nil
+}
+
+// Validate validates the RefundFineRequest
+func (r *RefundFineRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" {
+		return errors.New("reason is required")
+	}
+	if !r.Amount.IsPositive() {
+		return errors.New("amount must be greater than zero")
+	}
+	if !isValidPaymentMethod(r.PaymentMethod) {
+		return errors.New("payment_method must be one of cash, mpesa, card, bank_transfer")
+	}
+	return validateMoney(r.Amount)
+}
+
+// Validate validates the AdjustFineRequest
+func (r *AdjustFineRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" {
+		return errors.New("reason is required")
+	}
+	if r.Amount.IsZero() {
+		return errors.New("amount cannot be zero")
+	}
+	return validateMoney(r.Amount)
+}
+
+// validateMoney rejects amounts with more precision than the ledger stores
+func validateMoney(amount decimal.Decimal) error {
+	if !amount.Equal(amount.Round(2)) {
+		return errors.New("amount cannot have more than two decimal places")
+	}
+	return nil
+}
+
+func isValidPaymentMethod(method string) bool {
+	switch PaymentMethod(method) {
+	case PaymentMethodCash, PaymentMethodMpesa, PaymentMethodCard, PaymentMethodBankTransfer:
+		return true
+	}
+	return false
+}
+
+// FineAccrualResult summarises one run of the overdue fine accrual job
+type FineAccrualResult struct {
+	LoansChecked int             `json:"loans_checked"`
+	LoansUpdated int             `json:"loans_updated"`
+	LoansFailed  int             `json:"loans_failed"`
+	TotalAccrued decimal.Decimal `json:"total_accrued"`
+	StartedAt    time.Time       `json:"started_at"`
+	FinishedAt   time.Time       `json:"finished_at"`
+}
+
+// FineAccrualStatusResponse reports the state of the fine accrual job
+type FineAccrualStatusResponse struct {
+	BackgroundJobStatus
+	LastResult *FineAccrualResult `json:"last_result,omitempty"`
+}
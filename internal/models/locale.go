@@ -0,0 +1,29 @@
+package models
+
+// Locales notifications and email templates can be written in
+const (
+	LocaleEnglish = "en"
+	LocaleSwahili = "sw"
+	// DefaultLocale is used when a recipient has no preference, and for
+	// templates that have not been written in the recipient's locale
+	DefaultLocale = LocaleEnglish
+)
+
+// SupportedLocales lists the locales notifications can be written in
+var SupportedLocales = []string{LocaleEnglish, LocaleSwahili}
+
+// IsSupportedLocale reports whether notifications can be written in locale
+func IsSupportedLocale(locale string) bool {
+	for _, supported := range SupportedLocales {
+		if locale == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateLocaleRequest represents the request to change the language a user's
+// notifications are written in
+type UpdateLocaleRequest struct {
+	Locale string `json:"locale" binding:"required,oneof=en sw"`
+}
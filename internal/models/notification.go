@@ -13,20 +13,38 @@ import (
 type NotificationType string
 
 const (
-	NotificationTypeOverdueReminder NotificationType = "overdue_reminder"
-	NotificationTypeDueSoon         NotificationType = "due_soon"
-	NotificationTypeBookAvailable   NotificationType = "book_available"
-	NotificationTypeFineNotice      NotificationType = "fine_notice"
+	NotificationTypeOverdueReminder    NotificationType = "overdue_reminder"
+	NotificationTypeDueSoon            NotificationType = "due_soon"
+	NotificationTypeBookAvailable      NotificationType = "book_available"
+	NotificationTypeFineNotice         NotificationType = "fine_notice"
+	NotificationTypeRecallNotice       NotificationType = "recall_notice"
+	NotificationTypeReservationReady   NotificationType = "reservation_ready"
+	NotificationTypeReservationExpired NotificationType = "reservation_expired"
+	NotificationTypeAccountBlocked     NotificationType = "account_blocked"
+	NotificationTypeAnnouncement       NotificationType = "announcement"
 )
 
+// NotificationTypes lists every notification type
+var NotificationTypes = []NotificationType{
+	NotificationTypeOverdueReminder,
+	NotificationTypeDueSoon,
+	NotificationTypeBookAvailable,
+	NotificationTypeFineNotice,
+	NotificationTypeRecallNotice,
+	NotificationTypeReservationReady,
+	NotificationTypeReservationExpired,
+	NotificationTypeAccountBlocked,
+	NotificationTypeAnnouncement,
+}
+
 // IsValid checks if the notification type is valid
 func (nt NotificationType) IsValid() bool {
-	switch nt {
-	case NotificationTypeOverdueReminder, NotificationTypeDueSoon, NotificationTypeBookAvailable, NotificationTypeFineNotice:
-		return true
-	default:
-		return false
+	for _, notificationType := range NotificationTypes {
+		if nt == notificationType {
+			return true
+		}
 	}
+	return false
 }
 
 // RecipientType represents the type of notification recipient
@@ -67,6 +85,30 @@ func (np NotificationPriority) IsValid() bool {
 	}
 }
 
+// NotificationChannel represents a way a notification reaches its recipient
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail NotificationChannel = "email"
+	NotificationChannelSMS   NotificationChannel = "sms"
+)
+
+// NotificationChannels lists every notification channel
+var NotificationChannels = []NotificationChannel{
+	NotificationChannelEmail,
+	NotificationChannelSMS,
+}
+
+// IsValid checks if the notification channel is valid
+func (nc NotificationChannel) IsValid() bool {
+	switch nc {
+	case NotificationChannelEmail, NotificationChannelSMS:
+		return true
+	default:
+		return false
+	}
+}
+
 // NotificationStatus represents the status of a notification
 type NotificationStatus string
 
@@ -97,6 +139,15 @@ type NotificationRequest struct {
 	Priority      NotificationPriority   `json:"priority" validate:"required"`
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`
 	ScheduledFor  *time.Time             `json:"scheduled_for,omitempty"`
+	// Locale is the language the notification is written in. When empty the
+	// recipient's preferred locale is used.
+	Locale string `json:"locale,omitempty" validate:"omitempty,oneof=en sw"`
+	// TemplateData holds the values the notification's email template is
+	// rendered with
+	TemplateData map[string]interface{} `json:"template_data,omitempty"`
+	// Channels are the ways the notification is delivered. When empty it is
+	// sent by email.
+	Channels []NotificationChannel `json:"channels,omitempty"`
 }
 
 // Validate validates the notification request
@@ -125,6 +176,12 @@ func (nr *NotificationRequest) Validate() error {
 		return fmt.Errorf("invalid notification priority: %s", nr.Priority)
 	}
 
+	for _, channel := range nr.Channels {
+		if !channel.IsValid() {
+			return fmt.Errorf("invalid notification channel: %s", channel)
+		}
+	}
+
 	// Validate scheduled_for is not in the past
 	if nr.ScheduledFor != nil && nr.ScheduledFor.Before(time.Now()) {
 		return fmt.Errorf("scheduled_for cannot be in the past")
@@ -141,6 +198,8 @@ type NotificationResponse struct {
 	Type          NotificationType       `json:"type"`
 	Title         string                 `json:"title"`
 	Message       string                 `json:"message"`
+	Locale        string                 `json:"locale"`
+	Channels      []NotificationChannel  `json:"channels"`
 	Priority      NotificationPriority   `json:"priority"`
 	Status        NotificationStatus     `json:"status"`
 	IsRead        bool                   `json:"is_read"`
@@ -161,6 +220,9 @@ type NotificationBatch struct {
 	Recipients      []NotificationRecipient `json:"recipients"`
 	ScheduledFor    *time.Time              `json:"scheduled_for,omitempty"`
 	Metadata        map[string]interface{}  `json:"metadata,omitempty"`
+	// Channels are the ways each notification is delivered. When empty they
+	// are sent by email.
+	Channels []NotificationChannel `json:"channels,omitempty"`
 }
 
 // NotificationRecipient represents a recipient in a batch notification
@@ -230,6 +292,7 @@ type NotificationDeliveryLog struct {
 type EmailTemplate struct {
 	ID        int32     `json:"id"`
 	Name      string    `json:"name"`
+	Locale    string    `json:"locale,omitempty"`
 	Subject   string    `json:"subject"`
 	Body      string    `json:"body"`
 	IsHTML    bool      `json:"is_html"`
@@ -249,6 +312,21 @@ type EmailConfig struct {
 	FromName     string `json:"from_name"`
 	UseTLS       bool   `json:"use_tls"`
 	UseSSL       bool   `json:"use_ssl"`
+	// BounceAddress is the return path emails are sent with, where bounces are
+	// delivered; the from address is used when empty
+	BounceAddress string `json:"bounce_address"`
+	// BounceSecret signs the delivery ID each email's return path carries, so
+	// bounce reports cannot be forged for other emails. Return paths carry no
+	// delivery ID when empty.
+	BounceSecret string `json:"-"`
+	// DKIMDomain and DKIMSelector name the DNS record holding the public half of
+	// the key in DKIMPrivateKeyPath. Emails are signed when all three are set.
+	DKIMDomain         string `json:"dkim_domain"`
+	DKIMSelector       string `json:"dkim_selector"`
+	DKIMPrivateKeyPath string `json:"dkim_private_key_path"`
+	// LogoPath is an image embedded in HTML emails that show it with
+	// <img src="cid:logo">
+	LogoPath string `json:"logo_path"`
 }
 
 // TemplateFilter represents filters for template queries
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// DigestMode is how often routine notifications are rolled into a digest email
+type DigestMode string
+
+const (
+	DigestModeOff    DigestMode = "off"
+	DigestModeDaily  DigestMode = "daily"
+	DigestModeWeekly DigestMode = "weekly"
+)
+
+// IsValid checks if the digest mode is valid
+func (m DigestMode) IsValid() bool {
+	switch m {
+	case DigestModeOff, DigestModeDaily, DigestModeWeekly:
+		return true
+	default:
+		return false
+	}
+}
+
+// MandatoryNotificationTypes are the notices recipients cannot turn off or
+// defer to a digest, because they carry obligations with a cost or a deadline
+var MandatoryNotificationTypes = []NotificationType{
+	NotificationTypeFineNotice,
+	NotificationTypeRecallNotice,
+	NotificationTypeAccountBlocked,
+}
+
+// IsMandatory reports whether notifications of this type always go out
+func (nt NotificationType) IsMandatory() bool {
+	for _, mandatory := range MandatoryNotificationTypes {
+		if nt == mandatory {
+			return true
+		}
+	}
+	return false
+}
+
+// QuietHours is a daily window, in library time, in which notifications are held
+// back until it ends. An end earlier than the start spans midnight.
+type QuietHours struct {
+	Start string `json:"start" binding:"required" example:"22:00"`
+	End   string `json:"end" binding:"required" example:"07:00"`
+}
+
+// NotificationPreferences is how a student or librarian wants to receive notifications
+type NotificationPreferences struct {
+	RecipientID   int32         `json:"recipient_id"`
+	RecipientType RecipientType `json:"recipient_type"`
+	// Channels lists the channels enabled for each notification type. Types not
+	// listed are sent on every channel the notification uses.
+	Channels   map[NotificationType][]NotificationChannel `json:"channels"`
+	QuietHours *QuietHours                                `json:"quiet_hours,omitempty"`
+	DigestMode DigestMode                                 `json:"digest_mode"`
+	// MandatoryTypes are the types that cannot be turned off or put in a digest
+	MandatoryTypes []NotificationType `json:"mandatory_types"`
+	LastDigestAt   *time.Time         `json:"last_digest_at,omitempty"`
+	UpdatedAt      *time.Time         `json:"updated_at,omitempty"`
+}
+
+// UpdateNotificationPreferencesRequest replaces the caller's notification preferences
+type UpdateNotificationPreferencesRequest struct {
+	// Channels lists the channels to keep for each notification type; an empty
+	// list turns the type off. Types not listed use every channel.
+	Channels map[NotificationType][]NotificationChannel `json:"channels"`
+	// QuietHours holds notifications back during a daily window; omit to turn it off
+	QuietHours *QuietHours `json:"quiet_hours"`
+	DigestMode DigestMode  `json:"digest_mode" binding:"required"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MpesaConfig represents M-Pesa Daraja API configuration
+type MpesaConfig struct {
+	BaseURL        string `json:"base_url"`
+	ConsumerKey    string `json:"consumer_key"`
+	ConsumerSecret string `json:"consumer_secret"`
+	ShortCode      string `json:"short_code"`
+	PassKey        string `json:"pass_key"`
+	CallbackURL    string `json:"callback_url"`
+	CallbackToken  string `json:"callback_token"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+}
+
+// Enabled reports whether enough configuration is present to reach Daraja and
+// to tell its callbacks from forged ones
+func (c *MpesaConfig) Enabled() bool {
+	return c.ConsumerKey != "" && c.ConsumerSecret != "" && c.ShortCode != "" && c.PassKey != "" && c.CallbackURL != "" && c.CallbackToken != ""
+}
+
+// PaymentRequestStatus represents the state of a mobile payment request
+type PaymentRequestStatus string
+
+const (
+	PaymentRequestPending   PaymentRequestStatus = "pending"
+	PaymentRequestCompleted PaymentRequestStatus = "completed"
+	PaymentRequestFailed    PaymentRequestStatus = "failed"
+)
+
+// InitiateMobilePaymentRequest represents a request to prompt a student to pay their fines by phone.
+// When no phone is given the number on the student's record is used.
+type InitiateMobilePaymentRequest struct {
+	Phone string `json:"phone" binding:"omitempty,max=20"`
+}
+
+// FinePaymentRequestResponse represents a mobile payment request and its outcome
+type FinePaymentRequestResponse struct {
+	ID                int32                `json:"id"`
+	StudentID         int32                `json:"student_id"`
+	Provider          string               `json:"provider"`
+	Phone             string               `json:"phone"`
+	Amount            decimal.Decimal      `json:"amount"`
+	AmountApplied     decimal.Decimal      `json:"amount_applied"`
+	Status            PaymentRequestStatus `json:"status"`
+	CheckoutRequestID *string              `json:"checkout_request_id,omitempty"`
+	ProviderReceipt   *string              `json:"provider_receipt,omitempty"`
+	ResultCode        *int32               `json:"result_code,omitempty"`
+	ResultDesc        *string              `json:"result_desc,omitempty"`
+	CustomerMessage   string               `json:"customer_message,omitempty"`
+	RequestedBy       *int32               `json:"requested_by,omitempty"`
+	CreatedAt         time.Time            `json:"created_at"`
+	CompletedAt       *time.Time           `json:"completed_at,omitempty"`
+}
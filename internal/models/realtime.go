@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Realtime event types pushed to connected clients
+const (
+	// EventNotificationCreated carries a new notification and the recipient's unread count
+	EventNotificationCreated = "notification.created"
+	// EventNotificationRead tells every session of the recipient that a notification was read
+	EventNotificationRead = "notification.read"
+	// EventUnreadCount carries the recipient's unread count when a stream opens
+	EventUnreadCount = "notification.unread_count"
+	// EventReservationStatusChanged carries a reservation whose status changed
+	EventReservationStatusChanged = "reservation.status_changed"
+	// EventCountersUpdated carries the desk counters shown to library staff
+	EventCountersUpdated = "counters.updated"
+	// EventHeartbeat keeps idle WebSocket connections open
+	EventHeartbeat = "heartbeat"
+)
+
+// RealtimeEvent is a message pushed to clients over the event stream
+type RealtimeEvent struct {
+	Type       string          `json:"type"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// NewRealtimeEvent encodes data into an event of the given type
+func NewRealtimeEvent(eventType string, data interface{}) (RealtimeEvent, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return RealtimeEvent{}, err
+	}
+
+	return RealtimeEvent{
+		Type:       eventType,
+		Data:       encoded,
+		OccurredAt: time.Now(),
+	}, nil
+}
+
+// NotificationEvent is the payload of the notification events
+type NotificationEvent struct {
+	NotificationID int32                 `json:"notification_id"`
+	Notification   *NotificationResponse `json:"notification,omitempty"`
+	UnreadCount    int64                 `json:"unread_count"`
+}
+
+// ReservationEvent is the payload of EventReservationStatusChanged
+type ReservationEvent struct {
+	ReservationID  int32      `json:"reservation_id"`
+	StudentID      int32      `json:"student_id"`
+	BookID         int32      `json:"book_id"`
+	Status         string     `json:"status"`
+	CopyID         *int32     `json:"copy_id,omitempty"`
+	PickupDeadline *time.Time `json:"pickup_deadline,omitempty"`
+}
+
+// LiveCounters are the circulation desk counters kept up to date on staff screens
+type LiveCounters struct {
+	OverdueLoans int64 `json:"overdue_loans"`
+	HoldShelf    int64 `json:"hold_shelf"`
+}
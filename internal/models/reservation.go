@@ -23,32 +23,49 @@ type ReserveBookRequest struct {
 	BookID    int32 `json:"book_id" binding:"required,min=1"`
 }
 
+// SuspendReservationRequest represents a request to pause a reservation until a date.
+// The reservation keeps its place in the queue but is passed over until then.
+type SuspendReservationRequest struct {
+	Until time.Time `json:"until" binding:"required"`
+}
+
+// MoveReservationRequest represents a librarian moving a reservation within its book's queue
+type MoveReservationRequest struct {
+	Position int `json:"position" binding:"required,min=1"`
+}
+
 // ReservationResponse represents a reservation response
 type ReservationResponse struct {
-	ID            int32      `json:"id"`
-	StudentID     int32      `json:"student_id"`
-	BookID        int32      `json:"book_id"`
-	ReservedAt    time.Time  `json:"reserved_at"`
-	ExpiresAt     time.Time  `json:"expires_at"`
-	Status        string     `json:"status"`
-	FulfilledAt   *time.Time `json:"fulfilled_at,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	QueuePosition int        `json:"queue_position,omitempty"`
+	ID             int32      `json:"id"`
+	StudentID      int32      `json:"student_id"`
+	BookID         int32      `json:"book_id"`
+	ReservedAt     time.Time  `json:"reserved_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	Status         string     `json:"status"`
+	FulfilledAt    *time.Time `json:"fulfilled_at,omitempty"`
+	ReadyAt        *time.Time `json:"ready_at,omitempty"`
+	PickupDeadline *time.Time `json:"pickup_deadline,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	QueuePosition  int        `json:"queue_position,omitempty"`
 }
 
 // ReservationDetailsResponse represents a detailed reservation response with student and book information
 type ReservationDetailsResponse struct {
-	ID            int32      `json:"id"`
-	StudentID     int32      `json:"student_id"`
-	BookID        int32      `json:"book_id"`
-	ReservedAt    time.Time  `json:"reserved_at"`
-	ExpiresAt     time.Time  `json:"expires_at"`
-	Status        string     `json:"status"`
-	FulfilledAt   *time.Time `json:"fulfilled_at,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	QueuePosition int        `json:"queue_position,omitempty"`
+	ID             int32      `json:"id"`
+	StudentID      int32      `json:"student_id"`
+	BookID         int32      `json:"book_id"`
+	ReservedAt     time.Time  `json:"reserved_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	Status         string     `json:"status"`
+	FulfilledAt    *time.Time `json:"fulfilled_at,omitempty"`
+	ReadyAt        *time.Time `json:"ready_at,omitempty"`
+	PickupDeadline *time.Time `json:"pickup_deadline,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	QueuePosition  int        `json:"queue_position,omitempty"`
 	// Student information
 	StudentName   string `json:"student_name"`
 	StudentIDCode string `json:"student_id_code"`
@@ -60,15 +77,21 @@ type ReservationDetailsResponse struct {
 
 // StudentReservationResponse represents a reservation response for student-specific queries
 type StudentReservationResponse struct {
-	ID          int32      `json:"id"`
-	StudentID   int32      `json:"student_id"`
-	BookID      int32      `json:"book_id"`
-	ReservedAt  time.Time  `json:"reserved_at"`
-	ExpiresAt   time.Time  `json:"expires_at"`
-	Status      string     `json:"status"`
-	FulfilledAt *time.Time `json:"fulfilled_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID             int32      `json:"id"`
+	StudentID      int32      `json:"student_id"`
+	BookID         int32      `json:"book_id"`
+	ReservedAt     time.Time  `json:"reserved_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	Status         string     `json:"status"`
+	FulfilledAt    *time.Time `json:"fulfilled_at,omitempty"`
+	ReadyAt        *time.Time `json:"ready_at,omitempty"`
+	PickupDeadline *time.Time `json:"pickup_deadline,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	QueuePosition  int        `json:"queue_position,omitempty"`
+	// Set while the reservation is waiting in the queue
+	EstimatedWait *WaitTimeEstimate `json:"estimated_wait,omitempty"`
 	// Book information
 	BookTitle  string `json:"book_title"`
 	BookAuthor string `json:"book_author"`
@@ -77,21 +100,52 @@ type StudentReservationResponse struct {
 
 // BookReservationResponse represents a reservation response for book-specific queries
 type BookReservationResponse struct {
-	ID            int32      `json:"id"`
-	StudentID     int32      `json:"student_id"`
-	BookID        int32      `json:"book_id"`
-	ReservedAt    time.Time  `json:"reserved_at"`
-	ExpiresAt     time.Time  `json:"expires_at"`
-	Status        string     `json:"status"`
-	FulfilledAt   *time.Time `json:"fulfilled_at,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	QueuePosition int        `json:"queue_position"`
+	ID             int32      `json:"id"`
+	StudentID      int32      `json:"student_id"`
+	BookID         int32      `json:"book_id"`
+	ReservedAt     time.Time  `json:"reserved_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	Status         string     `json:"status"`
+	FulfilledAt    *time.Time `json:"fulfilled_at,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	QueuePosition  int        `json:"queue_position"`
+	Priority       int32      `json:"priority"`
+	// Absent when every copy of the book is lost or withdrawn
+	EstimatedWait *WaitTimeEstimate `json:"estimated_wait,omitempty"`
 	// Student information
 	StudentName   string `json:"student_name"`
 	StudentIDCode string `json:"student_id_code"`
 }
 
+// WaitTimeEstimate predicts when a queued reservation will be ready to collect.
+// The estimate is expected to fall between EarliestAt and LatestAt about two times in three.
+type WaitTimeEstimate struct {
+	ExpectedAt   time.Time `json:"expected_at"`
+	EarliestAt   time.Time `json:"earliest_at"`
+	LatestAt     time.Time `json:"latest_at"`
+	ExpectedDays int       `json:"expected_days"`
+}
+
+// HoldShelfItemResponse represents a reserved copy set aside for a student to collect
+type HoldShelfItemResponse struct {
+	ReservationID  int32     `json:"reservation_id"`
+	StudentID      int32     `json:"student_id"`
+	StudentName    string    `json:"student_name"`
+	StudentIDCode  string    `json:"student_id_code"`
+	BookID         int32     `json:"book_id"`
+	BookTitle      string    `json:"book_title"`
+	BookAuthor     string    `json:"book_author"`
+	BookIDCode     string    `json:"book_id_code"`
+	CopyID         *int32    `json:"copy_id,omitempty"`
+	Barcode        string    `json:"barcode,omitempty"`
+	ReadyAt        time.Time `json:"ready_at"`
+	PickupDeadline time.Time `json:"pickup_deadline"`
+	// Past the pickup deadline and waiting for the next expiry run to pass it on
+	PastDeadline bool `json:"past_deadline"`
+}
+
 // ReservationQueueResponse represents the queue status for a specific book
 type ReservationQueueResponse struct {
 	BookID       int32                     `json:"book_id"`
@@ -145,6 +199,7 @@ type ReservationSuccessResponse struct {
 // ReservationStatus constants for reservation statuses
 const (
 	ReservationStatusActive    = "active"
+	ReservationStatusReady     = "ready"
 	ReservationStatusFulfilled = "fulfilled"
 	ReservationStatusCancelled = "cancelled"
 	ReservationStatusExpired   = "expired"
@@ -158,9 +213,12 @@ const (
 	ReservationErrorCodeBookAvailable        = "BOOK_AVAILABLE"
 	ReservationErrorCodeBookNotActive        = "BOOK_NOT_ACTIVE"
 	ReservationErrorCodeStudentNotActive     = "STUDENT_NOT_ACTIVE"
+	ReservationErrorCodeStudentBlocked       = "STUDENT_BLOCKED"
 	ReservationErrorCodeMaxReservations      = "MAX_RESERVATIONS_REACHED"
 	ReservationErrorCodeDuplicateReservation = "DUPLICATE_RESERVATION"
 	ReservationErrorCodeReservationExpired   = "RESERVATION_EXPIRED"
+	ReservationErrorCodeCannotSuspend        = "CANNOT_SUSPEND"
+	ReservationErrorCodeNotInQueue           = "NOT_IN_QUEUE"
 	ReservationErrorCodeValidationError      = "VALIDATION_ERROR"
 	ReservationErrorCodeInternalError        = "INTERNAL_ERROR"
 )
@@ -168,7 +226,7 @@ const (
 // ValidateReservationStatus validates if a reservation status is valid
 func ValidateReservationStatus(status string) bool {
 	switch status {
-	case ReservationStatusActive, ReservationStatusFulfilled, ReservationStatusCancelled, ReservationStatusExpired:
+	case ReservationStatusActive, ReservationStatusReady, ReservationStatusFulfilled, ReservationStatusCancelled, ReservationStatusExpired:
 		return true
 	default:
 		return false
@@ -179,7 +237,8 @@ func ValidateReservationStatus(status string) bool {
 func IsValidReservationTransition(from, to string) bool {
 	// Define valid transitions
 	validTransitions := map[string][]string{
-		ReservationStatusActive:    {ReservationStatusFulfilled, ReservationStatusCancelled, ReservationStatusExpired},
+		ReservationStatusActive:    {ReservationStatusReady, ReservationStatusFulfilled, ReservationStatusCancelled, ReservationStatusExpired},
+		ReservationStatusReady:     {ReservationStatusFulfilled, ReservationStatusCancelled, ReservationStatusExpired},
 		ReservationStatusFulfilled: {}, // No transitions allowed from fulfilled
 		ReservationStatusCancelled: {}, // No transitions allowed from cancelled
 		ReservationStatusExpired:   {}, // No transitions allowed from expired
@@ -204,8 +263,10 @@ func GetReservationStatusDescription(status string) string {
 	switch status {
 	case ReservationStatusActive:
 		return "Active reservation waiting for book availability"
+	case ReservationStatusReady:
+		return "Book is on the hold shelf waiting to be collected"
 	case ReservationStatusFulfilled:
-		return "Reservation fulfilled - book is available for borrowing"
+		return "Reservation fulfilled - the reserved book has been borrowed"
 	case ReservationStatusCancelled:
 		return "Reservation cancelled by student or librarian"
 	case ReservationStatusExpired:
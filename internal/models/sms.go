@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// SMSConfig represents SMS gateway configuration
+type SMSConfig struct {
+	BaseURL  string `json:"base_url"`
+	Username string `json:"username"`
+	APIKey   string `json:"api_key"`
+	// SenderID is the registered alphanumeric sender or short code messages come
+	// from; the gateway's shared sender is used when empty
+	SenderID       string `json:"sender_id"`
+	CallbackToken  string `json:"callback_token"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+	// DefaultCountryCode is the calling code assumed for numbers stored without one
+	DefaultCountryCode string `json:"default_country_code"`
+}
+
+// Enabled reports whether enough configuration is present to reach the SMS gateway
+func (c *SMSConfig) Enabled() bool {
+	return c.Username != "" && c.APIKey != ""
+}
+
+// SMSDeliveryStatus represents the status of an SMS delivery
+type SMSDeliveryStatus string
+
+const (
+	SMSDeliveryStatusPending   SMSDeliveryStatus = "pending"
+	SMSDeliveryStatusSent      SMSDeliveryStatus = "sent"
+	SMSDeliveryStatusDelivered SMSDeliveryStatus = "delivered"
+	SMSDeliveryStatusFailed    SMSDeliveryStatus = "failed"
+)
+
+// IsValid checks if the SMS delivery status is valid
+func (s SMSDeliveryStatus) IsValid() bool {
+	switch s {
+	case SMSDeliveryStatusPending, SMSDeliveryStatusSent, SMSDeliveryStatusDelivered, SMSDeliveryStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// SMSDelivery represents an SMS delivery record
+type SMSDelivery struct {
+	ID                int32             `json:"id"`
+	NotificationID    int32             `json:"notification_id"`
+	PhoneNumber       string            `json:"phone_number"`
+	Message           string            `json:"message"`
+	Segments          int               `json:"segments"`
+	Encoding          string            `json:"encoding"`
+	Provider          string            `json:"provider"`
+	Status            SMSDeliveryStatus `json:"status"`
+	ProviderMessageID *string           `json:"provider_message_id,omitempty"`
+	Cost              *string           `json:"cost,omitempty"`
+	ErrorMessage      *string           `json:"error_message,omitempty"`
+	SentAt            *time.Time        `json:"sent_at,omitempty"`
+	DeliveredAt       *time.Time        `json:"delivered_at,omitempty"`
+	FailedAt          *time.Time        `json:"failed_at,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
@@ -22,6 +22,7 @@ type StudentDB struct {
 	Department     pgtype.Text      `json:"department"`
 	EnrollmentDate pgtype.Date      `json:"enrollment_date"`
 	PasswordHash   pgtype.Text      `json:"password_hash,omitempty"`
+	Locale         string           `json:"locale"`
 	IsActive       pgtype.Bool      `json:"is_active"`
 	DeletedAt      pgtype.Timestamp `json:"deleted_at,omitempty"`
 	CreatedAt      pgtype.Timestamp `json:"created_at"`
@@ -37,6 +38,7 @@ type CreateStudentRequest struct {
 	Phone       string `json:"phone" binding:"omitempty"`
 	YearOfStudy int32  `json:"year_of_study" binding:"required,min=1,max=8"`
 	Department  string `json:"department" binding:"omitempty"`
+	Locale      string `json:"locale" binding:"omitempty,oneof=en sw"`
 }
 
 // UpdateStudentRequest represents the request payload for updating a student
@@ -47,6 +49,7 @@ type UpdateStudentRequest struct {
 	Phone       string `json:"phone" binding:"omitempty"`
 	YearOfStudy int32  `json:"year_of_study" binding:"required,min=1,max=8"`
 	Department  string `json:"department" binding:"omitempty"`
+	Locale      string `json:"locale" binding:"omitempty,oneof=en sw"`
 }
 
 // UpdateStudentProfileRequest represents the request payload for students updating their own profile
@@ -55,6 +58,7 @@ type UpdateStudentProfileRequest struct {
 	LastName  string `json:"last_name" binding:"required"`
 	Email     string `json:"email" binding:"omitempty,email"`
 	Phone     string `json:"phone" binding:"omitempty"`
+	Locale    string `json:"locale" binding:"omitempty,oneof=en sw"`
 }
 
 // StudentResponse represents the response payload for student operations
@@ -67,10 +71,14 @@ type StudentResponse struct {
 	Phone          string `json:"phone,omitempty"`
 	YearOfStudy    int32  `json:"year_of_study"`
 	Department     string `json:"department,omitempty"`
+	Locale         string `json:"locale"`
 	EnrollmentDate string `json:"enrollment_date"`
 	IsActive       bool   `json:"is_active"`
 	CreatedAt      string `json:"created_at"`
 	UpdatedAt      string `json:"updated_at"`
+	// IsBlocked and Blocks are only filled in on single student responses
+	IsBlocked *bool                  `json:"is_blocked,omitempty"`
+	Blocks    []StudentBlockResponse `json:"blocks,omitempty"`
 }
 
 // StudentListResponse represents the response payload for listing students
@@ -132,6 +140,7 @@ var (
 	ErrInvalidYear          = errors.New("year of study must be between 1 and 8")
 	ErrInvalidEmail         = errors.New("invalid email format")
 	ErrInvalidPhone         = errors.New("invalid phone number format")
+	ErrInvalidLocale        = errors.New("locale must be en or sw")
 	ErrStudentIDExists      = errors.New("student ID already exists")
 	ErrEmailExists          = errors.New("email already exists")
 	ErrStudentNotFound      = errors.New("student not found")
@@ -172,6 +181,11 @@ func (r *CreateStudentRequest) Validate() error {
 		return ErrInvalidPhone
 	}
 
+	// Validate locale if provided
+	if r.Locale != "" && !IsSupportedLocale(r.Locale) {
+		return ErrInvalidLocale
+	}
+
 	return nil
 }
 
@@ -202,6 +216,11 @@ func (r *UpdateStudentRequest) Validate() error {
 		return ErrInvalidPhone
 	}
 
+	// Validate locale if provided
+	if r.Locale != "" && !IsSupportedLocale(r.Locale) {
+		return ErrInvalidLocale
+	}
+
 	return nil
 }
 
@@ -226,6 +245,11 @@ func (r *UpdateStudentProfileRequest) Validate() error {
 		return ErrInvalidPhone
 	}
 
+	// Validate locale if provided
+	if r.Locale != "" && !IsSupportedLocale(r.Locale) {
+		return ErrInvalidLocale
+	}
+
 	return nil
 }
 
@@ -273,6 +297,7 @@ func (s *StudentDB) ToResponse() StudentResponse {
 		FirstName:   s.FirstName,
 		LastName:    s.LastName,
 		YearOfStudy: s.YearOfStudy,
+		Locale:      s.Locale,
 		IsActive:    s.IsActive.Bool,
 	}
 
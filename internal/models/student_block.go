@@ -0,0 +1,75 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// StudentBlockType identifies why a student is blocked
+type StudentBlockType string
+
+const (
+	// StudentBlockTypeManual is a block placed by a librarian
+	StudentBlockTypeManual StudentBlockType = "manual"
+	// StudentBlockTypeFines applies while unpaid fines are over the limit
+	StudentBlockTypeFines StudentBlockType = "fines"
+	// StudentBlockTypeOverdue applies while a loan is overdue for longer than allowed
+	StudentBlockTypeOverdue StudentBlockType = "overdue"
+)
+
+// CreateStudentBlockRequest represents a librarian placing a block on a student's account
+type CreateStudentBlockRequest struct {
+	Reason    string     `json:"reason" binding:"required,min=1,max=1000"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// LiftStudentBlockRequest represents a librarian lifting a block before it expires
+type LiftStudentBlockRequest struct {
+	Reason string `json:"reason" binding:"required,min=1,max=1000"`
+}
+
+// StudentBlockResponse represents a block on a student's account. Automatic
+// blocks have no ID; they last as long as the condition that caused them.
+type StudentBlockResponse struct {
+	ID         *int32           `json:"id,omitempty"`
+	StudentID  int32            `json:"student_id"`
+	BlockType  StudentBlockType `json:"block_type"`
+	Reason     string           `json:"reason"`
+	ExpiresAt  *time.Time       `json:"expires_at,omitempty"`
+	CreatedBy  *int32           `json:"created_by,omitempty"`
+	CreatedAt  *time.Time       `json:"created_at,omitempty"`
+	LiftedAt   *time.Time       `json:"lifted_at,omitempty"`
+	LiftedBy   *int32           `json:"lifted_by,omitempty"`
+	LiftReason *string          `json:"lift_reason,omitempty"`
+}
+
+// StudentBlocksResponse lists the blocks in force on a student's account and
+// every block a librarian has placed on it
+type StudentBlocksResponse struct {
+	StudentID int32                  `json:"student_id"`
+	IsBlocked bool                   `json:"is_blocked"`
+	Active    []StudentBlockResponse `json:"active"`
+	History   []StudentBlockResponse `json:"history"`
+}
+
+// Validate validates the CreateStudentBlockRequest
+func (r *CreateStudentBlockRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" {
+		return errors.New("reason is required")
+	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		return errors.New("expires_at must be in the future")
+	}
+	return nil
+}
+
+// Validate validates the LiftStudentBlockRequest
+func (r *LiftStudentBlockRequest) Validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" {
+		return errors.New("reason is required")
+	}
+	return nil
+}
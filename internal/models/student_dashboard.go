@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// StudentDashboardResponse is everything a student needs to see about their account at a glance
+type StudentDashboardResponse struct {
+	StudentID     int32                  `json:"student_id"`
+	Loans         []DashboardLoan        `json:"loans"`
+	Reservations  []DashboardReservation `json:"reservations"`
+	Fines         DashboardFines         `json:"fines"`
+	Notifications DashboardNotifications `json:"notifications"`
+	GeneratedAt   time.Time              `json:"generated_at"`
+}
+
+// DashboardLoan is a book the student currently has on loan
+type DashboardLoan struct {
+	TransactionID  int32     `json:"transaction_id"`
+	BookID         int32     `json:"book_id"`
+	BookCode       string    `json:"book_code"`
+	Title          string    `json:"title"`
+	Author         string    `json:"author"`
+	BorrowedAt     time.Time `json:"borrowed_at"`
+	DueDate        time.Time `json:"due_date"`
+	IsOverdue      bool      `json:"is_overdue"`
+	DaysUntilDue   int       `json:"days_until_due"`
+	CanRenew       bool      `json:"can_renew"`
+	RenewalBlocker string    `json:"renewal_blocker,omitempty"`
+}
+
+// DashboardReservation is an active reservation and the student's place in its queue
+type DashboardReservation struct {
+	ReservationID int32     `json:"reservation_id"`
+	BookID        int32     `json:"book_id"`
+	BookCode      string    `json:"book_code"`
+	Title         string    `json:"title"`
+	Author        string    `json:"author"`
+	Status        string    `json:"status"`
+	ReservedAt    time.Time `json:"reserved_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	QueuePosition int       `json:"queue_position"`
+	QueueLength   int       `json:"queue_length"`
+	// Set once the book is on the hold shelf waiting to be collected
+	PickupDeadline *time.Time `json:"pickup_deadline,omitempty"`
+}
+
+// DashboardFines summarises the fines the student still owes
+type DashboardFines struct {
+	Balance          decimal.Decimal `json:"balance"`
+	OutstandingFines int64           `json:"outstanding_fines"`
+	Fines            []FineResponse  `json:"fines"`
+}
+
+// DashboardNotifications lists the student's most recent unread notifications
+type DashboardNotifications struct {
+	UnreadCount int64                  `json:"unread_count"`
+	Items       []NotificationResponse `json:"items"`
+}
@@ -23,14 +23,117 @@ type Transaction struct {
 	UpdatedAt       time.Time       `json:"updated_at"`
 }
 
-// BorrowBookRequest represents a request to borrow a book
+// BorrowBookRequest represents a request to borrow a book.
+// Either BookID or Barcode must be provided; a barcode selects a specific copy.
 type BorrowBookRequest struct {
 	StudentID   int32  `json:"student_id" binding:"required,min=1"`
-	BookID      int32  `json:"book_id" binding:"required,min=1"`
+	BookID      int32  `json:"book_id" binding:"omitempty,min=1"`
+	Barcode     string `json:"barcode" binding:"omitempty,max=100"`
 	LibrarianID int32  `json:"librarian_id" binding:"required,min=1"`
 	Notes       string `json:"notes"`
 }
 
+// ReturnBookByBarcodeRequest represents a request to return a copy by scanning its barcode
+type ReturnBookByBarcodeRequest struct {
+	Barcode         string `json:"barcode" binding:"required,max=100"`
+	ReturnCondition string `json:"return_condition" binding:"omitempty,oneof=excellent good fair poor damaged"`
+	ConditionNotes  string `json:"condition_notes"`
+}
+
+// LossStatus records what happened to a loan whose copy went missing
+type LossStatus string
+
+const (
+	LossStatusLost            LossStatus = "lost"
+	LossStatusClaimedReturned LossStatus = "claimed_returned"
+	LossStatusFound           LossStatus = "found"
+)
+
+// MarkLoanMissingRequest represents a request to mark a loan lost or claimed returned
+type MarkLoanMissingRequest struct {
+	Notes string `json:"notes" binding:"max=1000"`
+}
+
+// RecallLoanRequest represents a request to recall a loan that another student is waiting for
+type RecallLoanRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// MarkLoanFoundRequest represents a request to restore a lost or claimed returned copy.
+// RefundMethod is required when money has already been paid towards the replacement charge.
+type MarkLoanFoundRequest struct {
+	ReturnCondition string  `json:"return_condition" binding:"omitempty,oneof=excellent good fair poor damaged"`
+	RefundMethod    *string `json:"refund_method" binding:"omitempty,oneof=cash mpesa card bank_transfer"`
+	Reference       *string `json:"reference" binding:"omitempty,max=100"`
+}
+
+// PayTransactionFineRequest represents payment in full of the fines raised against a loan.
+// The payment method defaults to cash.
+type PayTransactionFineRequest struct {
+	PaymentMethod string  `json:"payment_method" binding:"omitempty,oneof=cash mpesa card bank_transfer"`
+	Reference     *string `json:"reference" binding:"omitempty,max=100"`
+}
+
+// BatchMode selects how a batch checkout or check-in handles failing items
+type BatchMode string
+
+const (
+	// BatchModeAllOrNothing commits the batch only if every item succeeds
+	BatchModeAllOrNothing BatchMode = "all_or_nothing"
+	// BatchModeBestEffort commits each item that succeeds and reports the rest
+	BatchModeBestEffort BatchMode = "best_effort"
+)
+
+// BatchBorrowItem identifies one book in a batch checkout, by title or by scanned copy
+type BatchBorrowItem struct {
+	BookID  int32  `json:"book_id" binding:"omitempty,min=1"`
+	Barcode string `json:"barcode" binding:"omitempty,max=100"`
+	Notes   string `json:"notes"`
+}
+
+// BatchBorrowRequest represents a request to issue several books to one student.
+// Mode defaults to all_or_nothing.
+type BatchBorrowRequest struct {
+	StudentID   int32             `json:"student_id" binding:"required,min=1"`
+	LibrarianID int32             `json:"librarian_id" binding:"required,min=1"`
+	Mode        BatchMode         `json:"mode" binding:"omitempty,oneof=all_or_nothing best_effort"`
+	Items       []BatchBorrowItem `json:"items" binding:"required,min=1,max=50,dive"`
+}
+
+// BatchReturnItem identifies one loan in a batch check-in, by transaction or by scanned copy
+type BatchReturnItem struct {
+	TransactionID   int32  `json:"transaction_id" binding:"omitempty,min=1"`
+	Barcode         string `json:"barcode" binding:"omitempty,max=100"`
+	ReturnCondition string `json:"return_condition" binding:"omitempty,oneof=excellent good fair poor damaged"`
+	ConditionNotes  string `json:"condition_notes"`
+}
+
+// BatchReturnRequest represents a request to check in several loans at once.
+// Mode defaults to all_or_nothing.
+type BatchReturnRequest struct {
+	Mode  BatchMode         `json:"mode" binding:"omitempty,oneof=all_or_nothing best_effort"`
+	Items []BatchReturnItem `json:"items" binding:"required,min=1,max=200,dive"`
+}
+
+// TransactionOperationResult represents the result of one item in a batch checkout or check-in
+type TransactionOperationResult struct {
+	Index       int                  `json:"index"`
+	Success     bool                 `json:"success"`
+	Transaction *TransactionResponse `json:"transaction,omitempty"`
+	Error       string               `json:"error,omitempty"`
+}
+
+// TransactionBatchOperationResult represents the result of a batch checkout or check-in
+type TransactionBatchOperationResult struct {
+	Success        bool                         `json:"success"`
+	Mode           BatchMode                    `json:"mode"`
+	ProcessedCount int                          `json:"processed_count"`
+	SuccessCount   int                          `json:"success_count"`
+	FailureCount   int                          `json:"failure_count"`
+	Results        []TransactionOperationResult `json:"results"`
+	Message        string                       `json:"message"`
+}
+
 // RenewBookRequest represents a request to renew a book
 type RenewBookRequest struct {
 	LibrarianID int32 `json:"librarian_id" binding:"required,min=1"`
@@ -41,6 +144,8 @@ type TransactionResponse struct {
 	ID              int32           `json:"id"`
 	StudentID       int32           `json:"student_id"`
 	BookID          int32           `json:"book_id"`
+	CopyID          *int32          `json:"copy_id,omitempty"`
+	Barcode         string          `json:"barcode,omitempty"`
 	TransactionType string          `json:"transaction_type"`
 	TransactionDate time.Time       `json:"transaction_date"`
 	DueDate         time.Time       `json:"due_date"`
@@ -49,6 +154,10 @@ type TransactionResponse struct {
 	FineAmount      decimal.Decimal `json:"fine_amount"`
 	FinePaid        bool            `json:"fine_paid"`
 	Notes           string          `json:"notes"`
+	LossStatus      string          `json:"loss_status,omitempty"`
+	RecalledAt      *time.Time      `json:"recalled_at,omitempty"`
+	OriginalDueDate *time.Time      `json:"original_due_date,omitempty"`
+	Charges         []FineResponse  `json:"charges,omitempty"`
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
 }
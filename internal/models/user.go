@@ -20,6 +20,7 @@ type User struct {
 	Email        string     `json:"email" db:"email"`
 	PasswordHash string     `json:"-" db:"password_hash"`
 	Role         UserRole   `json:"role" db:"role"`
+	Locale       string     `json:"locale" db:"locale"`
 	IsActive     bool       `json:"is_active" db:"is_active"`
 	LastLogin    *time.Time `json:"last_login" db:"last_login"`
 	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
@@ -37,6 +38,7 @@ type Student struct {
 	Department     *string    `json:"department" db:"department"`
 	EnrollmentDate time.Time  `json:"enrollment_date" db:"enrollment_date"`
 	PasswordHash   *string    `json:"-" db:"password_hash"`
+	Locale         string     `json:"locale" db:"locale"`
 	IsActive       bool       `json:"is_active" db:"is_active"`
 	DeletedAt      *time.Time `json:"deleted_at" db:"deleted_at"`
 	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
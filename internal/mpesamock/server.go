@@ -0,0 +1,267 @@
+// Package mpesamock is a fake of the Safaricom Daraja OAuth and STK push APIs.
+// It backs the M-Pesa tests and can be run locally with cmd/mpesa-mock so the
+// whole payment flow works without sandbox credentials.
+package mpesamock
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AccessToken is the bearer token issued by the fake OAuth endpoint
+const AccessToken = "mpesamock-access-token"
+
+// Result codes Daraja reports in STK push callbacks
+const (
+	ResultSuccess           = 0
+	ResultInsufficientFunds = 1
+	ResultCancelledByUser   = 1032
+)
+
+// STKPush is an STK push request received by the server
+type STKPush struct {
+	BusinessShortCode string      `json:"BusinessShortCode"`
+	Password          string      `json:"Password"`
+	Timestamp         string      `json:"Timestamp"`
+	TransactionType   string      `json:"TransactionType"`
+	Amount            json.Number `json:"Amount"`
+	PartyA            string      `json:"PartyA"`
+	PartyB            string      `json:"PartyB"`
+	PhoneNumber       string      `json:"PhoneNumber"`
+	CallBackURL       string      `json:"CallBackURL"`
+	AccountReference  string      `json:"AccountReference"`
+	TransactionDesc   string      `json:"TransactionDesc"`
+
+	MerchantRequestID string `json:"-"`
+	CheckoutRequestID string `json:"-"`
+	// ReceiptNumber is reported in the callback when the push is paid
+	ReceiptNumber string `json:"-"`
+}
+
+// Server fakes Daraja. Credentials left empty are not checked.
+type Server struct {
+	ConsumerKey    string
+	ConsumerSecret string
+	PassKey        string
+
+	// AutoCallback makes the server post a result to the push's callback URL
+	// after CallbackDelay, as the real API does once the payer responds
+	AutoCallback  bool
+	CallbackDelay time.Duration
+	ResultCode    int
+
+	client *http.Client
+
+	mu     sync.Mutex
+	seq    int
+	pushes []STKPush
+}
+
+// New creates a fake Daraja server accepting the given credentials
+func New(consumerKey, consumerSecret, passKey string) *Server {
+	return &Server{
+		ConsumerKey:    consumerKey,
+		ConsumerSecret: consumerSecret,
+		PassKey:        passKey,
+		client:         &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/oauth/v1/generate":
+		s.handleToken(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == "/mpesa/stkpush/v1/processrequest":
+		s.handleSTKPush(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "404.001.01", "Resource not found")
+	}
+}
+
+// Pushes returns the STK push requests received so far
+func (s *Server) Pushes() []STKPush {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pushes := make([]STKPush, len(s.pushes))
+	copy(pushes, s.pushes)
+	return pushes
+}
+
+// CallbackBody builds the callback Daraja would send for a push
+func (s *Server) CallbackBody(checkoutRequestID string, resultCode int) ([]byte, error) {
+	push, ok := s.push(checkoutRequestID)
+	if !ok {
+		return nil, fmt.Errorf("unknown checkout request %s", checkoutRequestID)
+	}
+
+	callback := map[string]interface{}{
+		"MerchantRequestID": push.MerchantRequestID,
+		"CheckoutRequestID": push.CheckoutRequestID,
+		"ResultCode":        resultCode,
+		"ResultDesc":        resultDescription(resultCode),
+	}
+	if resultCode == ResultSuccess {
+		amount, _ := push.Amount.Int64()
+		phone, _ := json.Number(push.PhoneNumber).Int64()
+		callback["CallbackMetadata"] = map[string]interface{}{
+			"Item": []map[string]interface{}{
+				{"Name": "Amount", "Value": amount},
+				{"Name": "MpesaReceiptNumber", "Value": push.ReceiptNumber},
+				{"Name": "Balance"},
+				{"Name": "TransactionDate", "Value": time.Now().Format("20060102150405")},
+				{"Name": "PhoneNumber", "Value": phone},
+			},
+		}
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"Body": map[string]interface{}{"stkCallback": callback},
+	})
+}
+
+// SendCallback posts the result of a push to its callback URL
+func (s *Server) SendCallback(ctx context.Context, checkoutRequestID string, resultCode int) error {
+	push, ok := s.push(checkoutRequestID)
+	if !ok {
+		return fmt.Errorf("unknown checkout request %s", checkoutRequestID)
+	}
+
+	body, err := s.CallbackBody(checkoutRequestID, resultCode)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, push.CallBackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("grant_type") != "client_credentials" {
+		writeError(w, http.StatusBadRequest, "400.008.02", "Invalid grant type passed")
+		return
+	}
+
+	key, secret, ok := r.BasicAuth()
+	if s.ConsumerKey != "" && (!ok || key != s.ConsumerKey || secret != s.ConsumerSecret) {
+		writeError(w, http.StatusBadRequest, "400.008.01", "Invalid Authentication passed")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": AccessToken,
+		"expires_in":   "3599",
+	})
+}
+
+func (s *Server) handleSTKPush(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+AccessToken {
+		writeError(w, http.StatusUnauthorized, "404.001.03", "Invalid Access Token")
+		return
+	}
+
+	var push STKPush
+	if err := json.NewDecoder(r.Body).Decode(&push); err != nil {
+		writeError(w, http.StatusBadRequest, "400.002.02", "Bad Request - Invalid Body")
+		return
+	}
+
+	if amount, err := push.Amount.Int64(); err != nil || amount < 1 {
+		writeError(w, http.StatusBadRequest, "400.002.02", "Bad Request - Invalid Amount")
+		return
+	}
+	if push.PhoneNumber == "" || push.CallBackURL == "" {
+		writeError(w, http.StatusBadRequest, "400.002.02", "Bad Request - Invalid PhoneNumber or CallBackURL")
+		return
+	}
+	if s.PassKey != "" {
+		expected := base64.StdEncoding.EncodeToString([]byte(push.BusinessShortCode + s.PassKey + push.Timestamp))
+		if push.Password != expected {
+			writeError(w, http.StatusBadRequest, "500.001.1001", "Wrong credentials")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.seq++
+	push.MerchantRequestID = fmt.Sprintf("mock-%d", s.seq)
+	push.CheckoutRequestID = fmt.Sprintf("ws_CO_mock_%06d", s.seq)
+	push.ReceiptNumber = fmt.Sprintf("MCK%07d", s.seq)
+	s.pushes = append(s.pushes, push)
+	s.mu.Unlock()
+
+	if s.AutoCallback {
+		go func(checkoutRequestID string) {
+			time.Sleep(s.CallbackDelay)
+			_ = s.SendCallback(context.Background(), checkoutRequestID, s.ResultCode)
+		}(push.CheckoutRequestID)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"MerchantRequestID":   push.MerchantRequestID,
+		"CheckoutRequestID":   push.CheckoutRequestID,
+		"ResponseCode":        "0",
+		"ResponseDescription": "Success. Request accepted for processing",
+		"CustomerMessage":     "Success. Request accepted for processing",
+	})
+}
+
+func (s *Server) push(checkoutRequestID string) (STKPush, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, push := range s.pushes {
+		if push.CheckoutRequestID == checkoutRequestID {
+			return push, true
+		}
+	}
+	return STKPush{}, false
+}
+
+func resultDescription(code int) string {
+	switch code {
+	case ResultSuccess:
+		return "The service request is processed successfully."
+	case ResultInsufficientFunds:
+		return "The balance is insufficient for the transaction."
+	case ResultCancelledByUser:
+		return "Request cancelled by user"
+	default:
+		return "The transaction failed."
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]string{
+		"requestId":    fmt.Sprintf("mock-%d", time.Now().UnixNano()),
+		"errorCode":    code,
+		"errorMessage": message,
+	})
+}
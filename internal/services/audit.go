@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+)
+
+// AuditActor identifies who performed an audited change and from where
+type AuditActor struct {
+	UserID    int32
+	UserType  string // librarian, student or system
+	IPAddress string
+	UserAgent string
+}
+
+// auditLogWriter is implemented by queriers that can write to audit_logs
+type auditLogWriter interface {
+	CreateAuditLog(ctx context.Context, arg queries.CreateAuditLogParams) error
+}
+
+// writeAuditLog records a change in audit_logs. It runs on the caller's querier so
+// the audit row commits or rolls back together with the change it describes.
+func writeAuditLog(ctx context.Context, w auditLogWriter, actor AuditActor, tableName string, recordID int32, action string, oldValues, newValues interface{}) error {
+	params := queries.CreateAuditLogParams{
+		TableName: tableName,
+		RecordID:  recordID,
+		Action:    action,
+		UserType:  pgtype.Text{String: actor.UserType, Valid: actor.UserType != ""},
+		UserAgent: pgtype.Text{String: actor.UserAgent, Valid: actor.UserAgent != ""},
+	}
+
+	if oldValues != nil {
+		data, err := json.Marshal(oldValues)
+		if err != nil {
+			return fmt.Errorf("failed to marshal old values: %w", err)
+		}
+		params.OldValues = data
+	}
+	if newValues != nil {
+		data, err := json.Marshal(newValues)
+		if err != nil {
+			return fmt.Errorf("failed to marshal new values: %w", err)
+		}
+		params.NewValues = data
+	}
+	if actor.UserID > 0 {
+		params.UserID = pgtype.Int4{Int32: actor.UserID, Valid: true}
+	}
+	if actor.IPAddress != "" {
+		if addr, err := netip.ParseAddr(actor.IPAddress); err == nil {
+			params.IpAddress = &addr
+		}
+	}
+
+	if err := w.CreateAuditLog(ctx, params); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 // BookQuerier defines the interface for book database operations
 type BookQuerier interface {
 	CreateBook(ctx context.Context, arg queries.CreateBookParams) (queries.Book, error)
+	CreateBookCopy(ctx context.Context, arg queries.CreateBookCopyParams) (queries.BookCopy, error)
 	GetBookByID(ctx context.Context, id int32) (queries.Book, error)
 	GetBookByBookID(ctx context.Context, bookID string) (queries.Book, error)
 	GetBookByISBN(ctx context.Context, isbn pgtype.Text) (queries.Book, error)
@@ -25,6 +26,8 @@ type BookQuerier interface {
 	SearchBooksByGenre(ctx context.Context, arg queries.SearchBooksByGenreParams) ([]queries.Book, error)
 	CountBooks(ctx context.Context) (int64, error)
 	CountAvailableBooks(ctx context.Context) (int64, error)
+	// ExecTx runs fn inside a single database transaction, committing only if fn succeeds
+	ExecTx(ctx context.Context, fn func(BookQuerier) error) error
 }
 
 // BookServiceInterface defines the interface for book service operations
@@ -114,10 +117,39 @@ func (s *BookService) CreateBook(ctx context.Context, req models.CreateBookReque
 		params.ShelfLocation = pgtype.Text{String: *req.ShelfLocation, Valid: true}
 	}
 
-	// Create the book
-	book, err := s.querier.CreateBook(ctx, params)
+	// Create the book together with its copies, so a failed copy leaves no
+	// title behind whose counts no copy backs
+	var book queries.Book
+	err = s.querier.ExecTx(ctx, func(q BookQuerier) error {
+		var err error
+		book, err = q.CreateBook(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to create book: %w", err)
+		}
+
+		// Register a physical copy for each unit; copies beyond the available count
+		// start out of circulation until a librarian releases them
+		for i := int32(1); i <= book.TotalCopies.Int32; i++ {
+			status := models.BookCopyStatusAvailable
+			if i > book.AvailableCopies.Int32 {
+				status = models.BookCopyStatusMaintenance
+			}
+
+			_, err := q.CreateBookCopy(ctx, queries.CreateBookCopyParams{
+				BookID:        book.ID,
+				Barcode:       copyBarcode(book.BookID, int64(i)),
+				Condition:     pgtype.Text{String: "good", Valid: true},
+				ShelfLocation: book.ShelfLocation,
+				Status:        pgtype.Text{String: string(status), Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create book copy: %w", err)
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create book: %w", err)
+		return nil, err
 	}
 
 	// Convert to response model
@@ -185,19 +217,17 @@ func (s *BookService) UpdateBook(ctx context.Context, id int32, req models.Updat
 
 	// Prepare update parameters
 	params := queries.UpdateBookParams{
-		ID:              id,
-		BookID:          existingBook.BookID,
-		Title:           existingBook.Title,
-		Author:          existingBook.Author,
-		Isbn:            existingBook.Isbn,
-		Publisher:       existingBook.Publisher,
-		PublishedYear:   existingBook.PublishedYear,
-		Genre:           existingBook.Genre,
-		Description:     existingBook.Description,
-		CoverImageUrl:   existingBook.CoverImageUrl,
-		TotalCopies:     existingBook.TotalCopies,
-		AvailableCopies: existingBook.AvailableCopies,
-		ShelfLocation:   existingBook.ShelfLocation,
+		ID:            id,
+		BookID:        existingBook.BookID,
+		Title:         existingBook.Title,
+		Author:        existingBook.Author,
+		Isbn:          existingBook.Isbn,
+		Publisher:     existingBook.Publisher,
+		PublishedYear: existingBook.PublishedYear,
+		Genre:         existingBook.Genre,
+		Description:   existingBook.Description,
+		CoverImageUrl: existingBook.CoverImageUrl,
+		ShelfLocation: existingBook.ShelfLocation,
 	}
 
 	// Update fields if provided
@@ -248,12 +278,6 @@ func (s *BookService) UpdateBook(ctx context.Context, id int32, req models.Updat
 			params.CoverImageUrl = pgtype.Text{String: *req.CoverImageURL, Valid: true}
 		}
 	}
-	if req.TotalCopies != nil {
-		params.TotalCopies = pgtype.Int4{Int32: *req.TotalCopies, Valid: true}
-	}
-	if req.AvailableCopies != nil {
-		params.AvailableCopies = pgtype.Int4{Int32: *req.AvailableCopies, Valid: true}
-	}
 	if req.ShelfLocation != nil {
 		if *req.ShelfLocation == "" {
 			params.ShelfLocation = pgtype.Text{Valid: false}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// BookCopyQuerier defines the interface for book copy database operations
+type BookCopyQuerier interface {
+	GetBookByID(ctx context.Context, id int32) (queries.Book, error)
+	CreateBookCopy(ctx context.Context, arg queries.CreateBookCopyParams) (queries.BookCopy, error)
+	GetBookCopyByID(ctx context.Context, id int32) (queries.BookCopy, error)
+	GetBookCopyByBarcode(ctx context.Context, barcode string) (queries.BookCopy, error)
+	ListBookCopiesByBook(ctx context.Context, bookID int32) ([]queries.BookCopy, error)
+	CountBookCopiesByBook(ctx context.Context, bookID int32) (int64, error)
+	UpdateBookCopy(ctx context.Context, arg queries.UpdateBookCopyParams) (queries.BookCopy, error)
+	SyncBookCopyCounts(ctx context.Context, id int32) error
+}
+
+// BookCopyServiceInterface defines the interface for book copy service operations
+type BookCopyServiceInterface interface {
+	AddCopy(ctx context.Context, bookID int32, req models.CreateBookCopyRequest) (*models.BookCopyResponse, error)
+	ListCopies(ctx context.Context, bookID int32) ([]models.BookCopyResponse, error)
+	GetCopyByBarcode(ctx context.Context, barcode string) (*models.BookCopyResponse, error)
+	UpdateCopy(ctx context.Context, id int32, req models.UpdateBookCopyRequest) (*models.BookCopyResponse, error)
+}
+
+// BookCopyService handles the physical copies of library titles
+type BookCopyService struct {
+	querier BookCopyQuerier
+}
+
+// NewBookCopyService creates a new book copy service
+func NewBookCopyService(querier BookCopyQuerier) *BookCopyService {
+	return &BookCopyService{
+		querier: querier,
+	}
+}
+
+// AddCopy registers a new physical copy for a book and refreshes its availability counts
+func (s *BookCopyService) AddCopy(ctx context.Context, bookID int32, req models.CreateBookCopyRequest) (*models.BookCopyResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	book, err := s.querier.GetBookByID(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	barcode := req.Barcode
+	if barcode == "" {
+		count, err := s.querier.CountBookCopiesByBook(ctx, bookID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count book copies: %w", err)
+		}
+		barcode = copyBarcode(book.BookID, count+1)
+	}
+
+	if _, err := s.querier.GetBookCopyByBarcode(ctx, barcode); err == nil {
+		return nil, fmt.Errorf("book copy with barcode %s already exists", barcode)
+	}
+
+	params := queries.CreateBookCopyParams{
+		BookID:        bookID,
+		Barcode:       barcode,
+		Condition:     pgtype.Text{String: "good", Valid: true},
+		ShelfLocation: book.ShelfLocation,
+		Status:        pgtype.Text{String: string(models.BookCopyStatusAvailable), Valid: true},
+	}
+	if req.Condition != nil {
+		params.Condition = pgtype.Text{String: *req.Condition, Valid: true}
+	}
+	if req.ShelfLocation != nil && *req.ShelfLocation != "" {
+		params.ShelfLocation = pgtype.Text{String: *req.ShelfLocation, Valid: true}
+	}
+	if req.Notes != nil && *req.Notes != "" {
+		params.Notes = pgtype.Text{String: *req.Notes, Valid: true}
+	}
+
+	bookCopy, err := s.querier.CreateBookCopy(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create book copy: %w", err)
+	}
+
+	if err := s.querier.SyncBookCopyCounts(ctx, bookID); err != nil {
+		return nil, fmt.Errorf("failed to update book availability: %w", err)
+	}
+
+	response := convertToBookCopyResponse(bookCopy)
+	return &response, nil
+}
+
+// ListCopies returns all physical copies of a book
+func (s *BookCopyService) ListCopies(ctx context.Context, bookID int32) ([]models.BookCopyResponse, error) {
+	copies, err := s.querier.ListBookCopiesByBook(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list book copies: %w", err)
+	}
+
+	responses := make([]models.BookCopyResponse, 0, len(copies))
+	for _, bookCopy := range copies {
+		responses = append(responses, convertToBookCopyResponse(bookCopy))
+	}
+
+	return responses, nil
+}
+
+// GetCopyByBarcode looks up a physical copy by its barcode
+func (s *BookCopyService) GetCopyByBarcode(ctx context.Context, barcode string) (*models.BookCopyResponse, error) {
+	bookCopy, err := s.querier.GetBookCopyByBarcode(ctx, barcode)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("book copy not found")
+		}
+		return nil, fmt.Errorf("failed to get book copy: %w", err)
+	}
+
+	response := convertToBookCopyResponse(bookCopy)
+	return &response, nil
+}
+
+// UpdateCopy updates a copy's condition, location, status or notes.
+// Copies on loan can only change status through the return process.
+func (s *BookCopyService) UpdateCopy(ctx context.Context, id int32, req models.UpdateBookCopyRequest) (*models.BookCopyResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	existing, err := s.querier.GetBookCopyByID(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("book copy not found")
+		}
+		return nil, fmt.Errorf("failed to get book copy: %w", err)
+	}
+
+	params := queries.UpdateBookCopyParams{
+		ID:            id,
+		Condition:     existing.Condition,
+		ShelfLocation: existing.ShelfLocation,
+		Status:        existing.Status,
+		Notes:         existing.Notes,
+	}
+
+	if req.Status != nil && *req.Status != existing.Status.String {
+		if existing.Status.String == string(models.BookCopyStatusBorrowed) {
+			return nil, fmt.Errorf("validation error: copy %s is on loan and must be returned first", existing.Barcode)
+		}
+		params.Status = pgtype.Text{String: *req.Status, Valid: true}
+	}
+	if req.Condition != nil {
+		params.Condition = pgtype.Text{String: *req.Condition, Valid: true}
+	}
+	if req.ShelfLocation != nil {
+		params.ShelfLocation = pgtype.Text{String: *req.ShelfLocation, Valid: *req.ShelfLocation != ""}
+	}
+	if req.Notes != nil {
+		params.Notes = pgtype.Text{String: *req.Notes, Valid: *req.Notes != ""}
+	}
+
+	bookCopy, err := s.querier.UpdateBookCopy(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update book copy: %w", err)
+	}
+
+	if err := s.querier.SyncBookCopyCounts(ctx, bookCopy.BookID); err != nil {
+		return nil, fmt.Errorf("failed to update book availability: %w", err)
+	}
+
+	response := convertToBookCopyResponse(bookCopy)
+	return &response, nil
+}
+
+// copyBarcode builds the default barcode for the n-th copy of a title
+func copyBarcode(bookID string, n int64) string {
+	return fmt.Sprintf("%s-%03d", bookID, n)
+}
+
+// convertToBookCopyResponse converts a queries.BookCopy to BookCopyResponse
+func convertToBookCopyResponse(bookCopy queries.BookCopy) models.BookCopyResponse {
+	response := models.BookCopyResponse{
+		ID:        bookCopy.ID,
+		BookID:    bookCopy.BookID,
+		Barcode:   bookCopy.Barcode,
+		Condition: "good",
+		Status:    models.BookCopyStatus(bookCopy.Status.String),
+		CreatedAt: bookCopy.CreatedAt.Time,
+		UpdatedAt: bookCopy.UpdatedAt.Time,
+	}
+
+	if bookCopy.Condition.Valid {
+		response.Condition = bookCopy.Condition.String
+	}
+	if bookCopy.ShelfLocation.Valid {
+		response.ShelfLocation = &bookCopy.ShelfLocation.String
+	}
+	if bookCopy.Notes.Valid {
+		response.Notes = &bookCopy.Notes.String
+	}
+
+	return response
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockBookCopyQuerier is a mock implementation of BookCopyQuerier interface
+type MockBookCopyQuerier struct {
+	mock.Mock
+}
+
+func (m *MockBookCopyQuerier) GetBookByID(ctx context.Context, id int32) (queries.Book, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Book), args.Error(1)
+}
+
+func (m *MockBookCopyQuerier) CreateBookCopy(ctx context.Context, arg queries.CreateBookCopyParams) (queries.BookCopy, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.BookCopy), args.Error(1)
+}
+
+func (m *MockBookCopyQuerier) GetBookCopyByID(ctx context.Context, id int32) (queries.BookCopy, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.BookCopy), args.Error(1)
+}
+
+func (m *MockBookCopyQuerier) GetBookCopyByBarcode(ctx context.Context, barcode string) (queries.BookCopy, error) {
+	args := m.Called(ctx, barcode)
+	return args.Get(0).(queries.BookCopy), args.Error(1)
+}
+
+func (m *MockBookCopyQuerier) ListBookCopiesByBook(ctx context.Context, bookID int32) ([]queries.BookCopy, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).([]queries.BookCopy), args.Error(1)
+}
+
+func (m *MockBookCopyQuerier) CountBookCopiesByBook(ctx context.Context, bookID int32) (int64, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBookCopyQuerier) UpdateBookCopy(ctx context.Context, arg queries.UpdateBookCopyParams) (queries.BookCopy, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.BookCopy), args.Error(1)
+}
+
+func (m *MockBookCopyQuerier) SyncBookCopyCounts(ctx context.Context, id int32) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestBookCopyService_AddCopy(t *testing.T) {
+	ctx := context.Background()
+	book := queries.Book{
+		ID:            1,
+		BookID:        "BK001",
+		ShelfLocation: pgtype.Text{String: "A1", Valid: true},
+	}
+
+	t.Run("GeneratesBarcode", func(t *testing.T) {
+		mockQuerier := new(MockBookCopyQuerier)
+		service := NewBookCopyService(mockQuerier)
+
+		mockQuerier.On("GetBookByID", ctx, int32(1)).Return(book, nil)
+		mockQuerier.On("CountBookCopiesByBook", ctx, int32(1)).Return(int64(2), nil)
+		mockQuerier.On("GetBookCopyByBarcode", ctx, "BK001-003").Return(queries.BookCopy{}, sql.ErrNoRows)
+		mockQuerier.On("CreateBookCopy", ctx, queries.CreateBookCopyParams{
+			BookID:        1,
+			Barcode:       "BK001-003",
+			Condition:     pgtype.Text{String: "good", Valid: true},
+			ShelfLocation: pgtype.Text{String: "A1", Valid: true},
+			Status:        pgtype.Text{String: "available", Valid: true},
+		}).Return(queries.BookCopy{
+			ID:        3,
+			BookID:    1,
+			Barcode:   "BK001-003",
+			Condition: pgtype.Text{String: "good", Valid: true},
+			Status:    pgtype.Text{String: "available", Valid: true},
+		}, nil)
+		mockQuerier.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+
+		result, err := service.AddCopy(ctx, 1, models.CreateBookCopyRequest{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "BK001-003", result.Barcode)
+		assert.Equal(t, models.BookCopyStatusAvailable, result.Status)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("DuplicateBarcode", func(t *testing.T) {
+		mockQuerier := new(MockBookCopyQuerier)
+		service := NewBookCopyService(mockQuerier)
+
+		mockQuerier.On("GetBookByID", ctx, int32(1)).Return(book, nil)
+		mockQuerier.On("GetBookCopyByBarcode", ctx, "LIB-0001").Return(queries.BookCopy{ID: 9}, nil)
+
+		_, err := service.AddCopy(ctx, 1, models.CreateBookCopyRequest{Barcode: " LIB-0001 "})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already exists")
+		mockQuerier.AssertNotCalled(t, "CreateBookCopy", mock.Anything, mock.Anything)
+	})
+
+	t.Run("InvalidCondition", func(t *testing.T) {
+		service := NewBookCopyService(new(MockBookCopyQuerier))
+
+		_, err := service.AddCopy(ctx, 1, models.CreateBookCopyRequest{Condition: stringPtr("shiny")})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validation error")
+	})
+}
+
+func TestBookCopyService_UpdateCopy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("WithdrawsCopy", func(t *testing.T) {
+		mockQuerier := new(MockBookCopyQuerier)
+		service := NewBookCopyService(mockQuerier)
+
+		existing := queries.BookCopy{
+			ID:        5,
+			BookID:    1,
+			Barcode:   "BK001-001",
+			Condition: pgtype.Text{String: "poor", Valid: true},
+			Status:    pgtype.Text{String: "available", Valid: true},
+		}
+		updated := existing
+		updated.Status = pgtype.Text{String: "withdrawn", Valid: true}
+
+		mockQuerier.On("GetBookCopyByID", ctx, int32(5)).Return(existing, nil)
+		mockQuerier.On("UpdateBookCopy", ctx, queries.UpdateBookCopyParams{
+			ID:        5,
+			Condition: existing.Condition,
+			Status:    pgtype.Text{String: "withdrawn", Valid: true},
+		}).Return(updated, nil)
+		mockQuerier.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+
+		result, err := service.UpdateCopy(ctx, 5, models.UpdateBookCopyRequest{Status: stringPtr("withdrawn")})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.BookCopyStatusWithdrawn, result.Status)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("BorrowedCopyStatusLocked", func(t *testing.T) {
+		mockQuerier := new(MockBookCopyQuerier)
+		service := NewBookCopyService(mockQuerier)
+
+		mockQuerier.On("GetBookCopyByID", ctx, int32(5)).Return(queries.BookCopy{
+			ID:      5,
+			BookID:  1,
+			Barcode: "BK001-001",
+			Status:  pgtype.Text{String: "borrowed", Valid: true},
+		}, nil)
+
+		_, err := service.UpdateCopy(ctx, 5, models.UpdateBookCopyRequest{Status: stringPtr("available")})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "on loan")
+		mockQuerier.AssertNotCalled(t, "UpdateBookCopy", mock.Anything, mock.Anything)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockQuerier := new(MockBookCopyQuerier)
+		service := NewBookCopyService(mockQuerier)
+
+		mockQuerier.On("GetBookCopyByID", ctx, int32(99)).Return(queries.BookCopy{}, sql.ErrNoRows)
+
+		_, err := service.UpdateCopy(ctx, 99, models.UpdateBookCopyRequest{})
+
+		require.Error(t, err)
+		assert.Equal(t, "book copy not found", err.Error())
+	})
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+)
+
+// BookStore provides the book catalogue queries backed by a connection pool
+// and the ability to run several of them inside one database transaction
+type BookStore struct {
+	*queries.Queries
+	pool *pgxpool.Pool
+}
+
+// NewBookStore creates a new book store for the given pool
+func NewBookStore(pool *pgxpool.Pool) *BookStore {
+	return &BookStore{
+		Queries: queries.New(pool),
+		pool:    pool,
+	}
+}
+
+// ExecTx runs fn inside a database transaction, committing only if fn succeeds.
+// Calls made on a store that is already bound to a transaction reuse it.
+func (s *BookStore) ExecTx(ctx context.Context, fn func(BookQuerier) error) error {
+	if s.pool == nil {
+		return fn(s)
+	}
+
+	return runInTx(ctx, s.pool, func(tx pgx.Tx) error {
+		return fn(&BookStore{Queries: s.Queries.WithTx(tx)})
+	})
+}
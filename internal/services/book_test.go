@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/ngenohkevin/lms/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockBookQuerier is a mock implementation of BookQuerier interface
@@ -22,6 +24,11 @@ func (m *MockBookQuerier) CreateBook(ctx context.Context, arg queries.CreateBook
 	return args.Get(0).(queries.Book), args.Error(1)
 }
 
+func (m *MockBookQuerier) CreateBookCopy(ctx context.Context, arg queries.CreateBookCopyParams) (queries.BookCopy, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.BookCopy), args.Error(1)
+}
+
 func (m *MockBookQuerier) GetBookByID(ctx context.Context, id int32) (queries.Book, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(queries.Book), args.Error(1)
@@ -82,6 +89,10 @@ func (m *MockBookQuerier) CountAvailableBooks(ctx context.Context) (int64, error
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockBookQuerier) ExecTx(ctx context.Context, fn func(BookQuerier) error) error {
+	return fn(m)
+}
+
 func TestBookService_CreateBook(t *testing.T) {
 	mockQuerier := new(MockBookQuerier)
 	service := NewBookService(mockQuerier)
@@ -135,6 +146,41 @@ func TestBookService_CreateBook(t *testing.T) {
 					CreatedAt:       pgtype.Timestamp{Time: time.Now(), Valid: true},
 					UpdatedAt:       pgtype.Timestamp{Time: time.Now(), Valid: true},
 				}, nil)
+				// One barcoded copy is registered per unit
+				for i := 1; i <= 5; i++ {
+					barcode := fmt.Sprintf("BK001-%03d", i)
+					mockQuerier.On("CreateBookCopy", mock.Anything, mock.MatchedBy(func(arg queries.CreateBookCopyParams) bool {
+						return arg.BookID == 1 && arg.Barcode == barcode && arg.Status.String == "available"
+					})).Return(queries.BookCopy{ID: int32(i), BookID: 1, Barcode: barcode}, nil).Once()
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "copies beyond available count are held back",
+			request: models.CreateBookRequest{
+				BookID:          "BK002",
+				Title:           "Test Book",
+				Author:          "Test Author",
+				TotalCopies:     int32Ptr(2),
+				AvailableCopies: int32Ptr(1),
+			},
+			setup: func() {
+				mockQuerier.On("GetBookByBookID", mock.Anything, "BK002").Return(queries.Book{}, assert.AnError)
+				mockQuerier.On("CreateBook", mock.Anything, mock.Anything).Return(queries.Book{
+					ID:              2,
+					BookID:          "BK002",
+					Title:           "Test Book",
+					Author:          "Test Author",
+					TotalCopies:     pgtype.Int4{Int32: 2, Valid: true},
+					AvailableCopies: pgtype.Int4{Int32: 1, Valid: true},
+				}, nil)
+				mockQuerier.On("CreateBookCopy", mock.Anything, mock.MatchedBy(func(arg queries.CreateBookCopyParams) bool {
+					return arg.Barcode == "BK002-001" && arg.Status.String == "available"
+				})).Return(queries.BookCopy{ID: 1}, nil).Once()
+				mockQuerier.On("CreateBookCopy", mock.Anything, mock.MatchedBy(func(arg queries.CreateBookCopyParams) bool {
+					return arg.Barcode == "BK002-002" && arg.Status.String == "maintenance"
+				})).Return(queries.BookCopy{ID: 2}, nil).Once()
 			},
 			wantErr: false,
 		},
@@ -412,6 +458,49 @@ func TestBookService_SearchBooks(t *testing.T) {
 	}
 }
 
+func TestBookService_UpdateBook(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("LeavesCopyCountsAlone", func(t *testing.T) {
+		mockQuerier := new(MockBookQuerier)
+		service := NewBookService(mockQuerier)
+
+		existing := queries.Book{
+			ID:              1,
+			BookID:          "BK001",
+			Title:           "Test Book",
+			Author:          "Test Author",
+			TotalCopies:     pgtype.Int4{Int32: 5, Valid: true},
+			AvailableCopies: pgtype.Int4{Int32: 3, Valid: true},
+		}
+		updated := existing
+		updated.Title = "Updated Book"
+
+		mockQuerier.On("GetBookByID", ctx, int32(1)).Return(existing, nil)
+		mockQuerier.On("UpdateBook", ctx, mock.MatchedBy(func(arg queries.UpdateBookParams) bool {
+			return arg.ID == 1 && arg.Title == "Updated Book"
+		})).Return(updated, nil)
+
+		book, err := service.UpdateBook(ctx, 1, models.UpdateBookRequest{Title: stringPtr("Updated Book")})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Updated Book", book.Title)
+		assert.Equal(t, int32(3), book.AvailableCopies)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("RejectsCopyCounts", func(t *testing.T) {
+		mockQuerier := new(MockBookQuerier)
+		service := NewBookService(mockQuerier)
+
+		_, err := service.UpdateBook(ctx, 1, models.UpdateBookRequest{AvailableCopies: int32Ptr(4)})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validation error")
+		mockQuerier.AssertNotCalled(t, "UpdateBook", mock.Anything, mock.Anything)
+	})
+}
+
 func TestBookService_UpdateBookAvailability(t *testing.T) {
 	mockQuerier := new(MockBookQuerier)
 	service := NewBookService(mockQuerier)
@@ -0,0 +1,417 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// maxCalendarSearchDays bounds the search for the next open day so a
+// misconfigured calendar (every day closed) cannot loop forever
+const maxCalendarSearchDays = 366
+
+// CalendarQuerier defines the interface for library calendar database operations
+type CalendarQuerier interface {
+	ListOpeningHours(ctx context.Context) ([]queries.LibraryOpeningHour, error)
+	UpsertOpeningHours(ctx context.Context, arg queries.UpsertOpeningHoursParams) (queries.LibraryOpeningHour, error)
+	CreateLibraryClosure(ctx context.Context, arg queries.CreateLibraryClosureParams) (queries.LibraryClosure, error)
+	GetLibraryClosureByID(ctx context.Context, id int32) (queries.LibraryClosure, error)
+	ListLibraryClosures(ctx context.Context) ([]queries.LibraryClosure, error)
+	ListLibraryClosuresBetween(ctx context.Context, arg queries.ListLibraryClosuresBetweenParams) ([]queries.LibraryClosure, error)
+	UpdateLibraryClosure(ctx context.Context, arg queries.UpdateLibraryClosureParams) (queries.LibraryClosure, error)
+	DeleteLibraryClosure(ctx context.Context, id int32) error
+}
+
+// LibraryCalendar answers whether the library is open on a given day
+type LibraryCalendar interface {
+	// NextOpenDay returns t if the library is open that day, otherwise the same
+	// time of day on the next open day
+	NextOpenDay(ctx context.Context, t time.Time) (time.Time, error)
+	// CountOpenDays counts the open days after from up to and including to
+	CountOpenDays(ctx context.Context, from, to time.Time) (int, error)
+}
+
+// CalendarServiceInterface defines the interface for library calendar service operations
+type CalendarServiceInterface interface {
+	LibraryCalendar
+	GetOpeningHours(ctx context.Context) ([]models.OpeningHoursResponse, error)
+	UpdateOpeningHours(ctx context.Context, dayOfWeek int, req models.UpdateOpeningHoursRequest) (*models.OpeningHoursResponse, error)
+	ListClosures(ctx context.Context) ([]models.LibraryClosureResponse, error)
+	GetClosure(ctx context.Context, id int32) (*models.LibraryClosureResponse, error)
+	CreateClosure(ctx context.Context, req models.CreateLibraryClosureRequest) (*models.LibraryClosureResponse, error)
+	UpdateClosure(ctx context.Context, id int32, req models.UpdateLibraryClosureRequest) (*models.LibraryClosureResponse, error)
+	DeleteClosure(ctx context.Context, id int32) error
+}
+
+// CalendarService manages opening hours and closures
+type CalendarService struct {
+	querier CalendarQuerier
+}
+
+// NewCalendarService creates a new library calendar service
+func NewCalendarService(querier CalendarQuerier) *CalendarService {
+	return &CalendarService{
+		querier: querier,
+	}
+}
+
+// librarySchedule is a snapshot of the calendar used to test individual days
+type librarySchedule struct {
+	closedWeekdays [7]bool
+	closures       []queries.LibraryClosure
+}
+
+// isClosed reports whether the library is closed on the given day
+func (ls *librarySchedule) isClosed(day time.Time) bool {
+	if ls.closedWeekdays[day.Weekday()] {
+		return true
+	}
+
+	d := calendarDate(day)
+	for _, closure := range ls.closures {
+		start := calendarDate(closure.StartDate.Time)
+		end := calendarDate(closure.EndDate.Time)
+
+		if !closure.RecursAnnually {
+			if !d.Before(start) && !d.After(end) {
+				return true
+			}
+			continue
+		}
+
+		// Annual closures compare month and day only and may wrap over the new year
+		md := monthDay(d)
+		startMD, endMD := monthDay(start), monthDay(end)
+		if startMD <= endMD {
+			if md >= startMD && md <= endMD {
+				return true
+			}
+		} else if md >= startMD || md <= endMD {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadSchedule reads the weekly hours and the closures touching [from, to]
+func (s *CalendarService) loadSchedule(ctx context.Context, from, to time.Time) (*librarySchedule, error) {
+	hours, err := s.querier.ListOpeningHours(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opening hours: %w", err)
+	}
+
+	closures, err := s.querier.ListLibraryClosuresBetween(ctx, queries.ListLibraryClosuresBetweenParams{
+		EndDate:   pgtype.Date{Time: calendarDate(from), Valid: true},
+		StartDate: pgtype.Date{Time: calendarDate(to), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get library closures: %w", err)
+	}
+
+	schedule := &librarySchedule{closures: closures}
+	for _, h := range hours {
+		if h.DayOfWeek >= 0 && h.DayOfWeek < 7 {
+			schedule.closedWeekdays[h.DayOfWeek] = h.IsClosed
+		}
+	}
+
+	return schedule, nil
+}
+
+// NextOpenDay returns t if the library is open that day, otherwise the same
+// time of day on the next open day
+func (s *CalendarService) NextOpenDay(ctx context.Context, t time.Time) (time.Time, error) {
+	schedule, err := s.loadSchedule(ctx, t, t.AddDate(0, 0, maxCalendarSearchDays))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for offset := 0; offset <= maxCalendarSearchDays; offset++ {
+		day := t.AddDate(0, 0, offset)
+		if !schedule.isClosed(day) {
+			return day, nil
+		}
+	}
+
+	// Every day is closed; leave the date unchanged rather than fail the loan
+	return t, nil
+}
+
+// CountOpenDays counts the open days after from up to and including to
+func (s *CalendarService) CountOpenDays(ctx context.Context, from, to time.Time) (int, error) {
+	start := calendarDate(from).AddDate(0, 0, 1)
+	end := calendarDate(to)
+	if end.Before(start) {
+		return 0, nil
+	}
+
+	schedule, err := s.loadSchedule(ctx, start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if !schedule.isClosed(day) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// GetOpeningHours returns the opening hours for each day of the week
+func (s *CalendarService) GetOpeningHours(ctx context.Context) ([]models.OpeningHoursResponse, error) {
+	hours, err := s.querier.ListOpeningHours(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opening hours: %w", err)
+	}
+
+	// Days without a row are treated as open all day
+	byDay := make(map[int32]queries.LibraryOpeningHour, len(hours))
+	for _, h := range hours {
+		byDay[h.DayOfWeek] = h
+	}
+
+	responses := make([]models.OpeningHoursResponse, 0, 7)
+	for day := int32(0); day < 7; day++ {
+		h, ok := byDay[day]
+		if !ok {
+			h = queries.LibraryOpeningHour{DayOfWeek: day}
+		}
+		responses = append(responses, convertToOpeningHoursResponse(h))
+	}
+
+	return responses, nil
+}
+
+// UpdateOpeningHours sets the opening hours for a day of the week (0 is Sunday)
+func (s *CalendarService) UpdateOpeningHours(ctx context.Context, dayOfWeek int, req models.UpdateOpeningHoursRequest) (*models.OpeningHoursResponse, error) {
+	if dayOfWeek < 0 || dayOfWeek > 6 {
+		return nil, fmt.Errorf("validation error: day_of_week must be between 0 (Sunday) and 6 (Saturday)")
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	params := queries.UpsertOpeningHoursParams{
+		DayOfWeek: int32(dayOfWeek),
+		IsClosed:  req.IsClosed,
+	}
+	if !req.IsClosed {
+		params.OpensAt = parseCalendarTime(req.OpensAt)
+		params.ClosesAt = parseCalendarTime(req.ClosesAt)
+	}
+
+	hours, err := s.querier.UpsertOpeningHours(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update opening hours: %w", err)
+	}
+
+	response := convertToOpeningHoursResponse(hours)
+	return &response, nil
+}
+
+// ListClosures returns all configured closures
+func (s *CalendarService) ListClosures(ctx context.Context) ([]models.LibraryClosureResponse, error) {
+	closures, err := s.querier.ListLibraryClosures(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list library closures: %w", err)
+	}
+
+	responses := make([]models.LibraryClosureResponse, 0, len(closures))
+	for _, closure := range closures {
+		responses = append(responses, convertToLibraryClosureResponse(closure))
+	}
+
+	return responses, nil
+}
+
+// GetClosure retrieves a closure by ID
+func (s *CalendarService) GetClosure(ctx context.Context, id int32) (*models.LibraryClosureResponse, error) {
+	closure, err := s.getClosure(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := convertToLibraryClosureResponse(closure)
+	return &response, nil
+}
+
+// CreateClosure adds a holiday or break
+func (s *CalendarService) CreateClosure(ctx context.Context, req models.CreateLibraryClosureRequest) (*models.LibraryClosureResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	closure, err := s.querier.CreateLibraryClosure(ctx, queries.CreateLibraryClosureParams{
+		Name:           req.Name,
+		Description:    optionalText(req.Description),
+		StartDate:      parseCalendarDate(req.StartDate),
+		EndDate:        parseCalendarDate(req.EndDate),
+		RecursAnnually: req.RecursAnnually,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create library closure: %w", err)
+	}
+
+	response := convertToLibraryClosureResponse(closure)
+	return &response, nil
+}
+
+// UpdateClosure applies the provided changes to a closure
+func (s *CalendarService) UpdateClosure(ctx context.Context, id int32, req models.UpdateLibraryClosureRequest) (*models.LibraryClosureResponse, error) {
+	existing, err := s.getClosure(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := req.Validate(
+		existing.StartDate.Time.Format(models.CalendarDateLayout),
+		existing.EndDate.Time.Format(models.CalendarDateLayout),
+		existing.RecursAnnually,
+	); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	params := queries.UpdateLibraryClosureParams{
+		ID:             id,
+		Name:           existing.Name,
+		Description:    existing.Description,
+		StartDate:      existing.StartDate,
+		EndDate:        existing.EndDate,
+		RecursAnnually: existing.RecursAnnually,
+	}
+	if req.Name != nil {
+		params.Name = *req.Name
+	}
+	if req.Description != nil {
+		params.Description = optionalText(req.Description)
+	}
+	if req.StartDate != nil {
+		params.StartDate = parseCalendarDate(*req.StartDate)
+	}
+	if req.EndDate != nil {
+		params.EndDate = parseCalendarDate(*req.EndDate)
+	}
+	if req.RecursAnnually != nil {
+		params.RecursAnnually = *req.RecursAnnually
+	}
+
+	closure, err := s.querier.UpdateLibraryClosure(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update library closure: %w", err)
+	}
+
+	response := convertToLibraryClosureResponse(closure)
+	return &response, nil
+}
+
+// DeleteClosure removes a closure
+func (s *CalendarService) DeleteClosure(ctx context.Context, id int32) error {
+	if _, err := s.getClosure(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.querier.DeleteLibraryClosure(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete library closure: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CalendarService) getClosure(ctx context.Context, id int32) (queries.LibraryClosure, error) {
+	closure, err := s.querier.GetLibraryClosureByID(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return queries.LibraryClosure{}, fmt.Errorf("library closure not found")
+		}
+		return queries.LibraryClosure{}, fmt.Errorf("failed to get library closure: %w", err)
+	}
+	return closure, nil
+}
+
+// convertToOpeningHoursResponse converts a queries.LibraryOpeningHour to OpeningHoursResponse
+func convertToOpeningHoursResponse(h queries.LibraryOpeningHour) models.OpeningHoursResponse {
+	response := models.OpeningHoursResponse{
+		DayOfWeek: int(h.DayOfWeek),
+		Day:       time.Weekday(h.DayOfWeek).String(),
+		IsClosed:  h.IsClosed,
+	}
+
+	if h.OpensAt.Valid {
+		opensAt := formatCalendarTime(h.OpensAt)
+		response.OpensAt = &opensAt
+	}
+	if h.ClosesAt.Valid {
+		closesAt := formatCalendarTime(h.ClosesAt)
+		response.ClosesAt = &closesAt
+	}
+
+	return response
+}
+
+// convertToLibraryClosureResponse converts a queries.LibraryClosure to LibraryClosureResponse
+func convertToLibraryClosureResponse(closure queries.LibraryClosure) models.LibraryClosureResponse {
+	response := models.LibraryClosureResponse{
+		ID:             closure.ID,
+		Name:           closure.Name,
+		StartDate:      closure.StartDate.Time.Format(models.CalendarDateLayout),
+		EndDate:        closure.EndDate.Time.Format(models.CalendarDateLayout),
+		RecursAnnually: closure.RecursAnnually,
+		CreatedAt:      closure.CreatedAt.Time,
+		UpdatedAt:      closure.UpdatedAt.Time,
+	}
+
+	if closure.Description.Valid {
+		response.Description = &closure.Description.String
+	}
+
+	return response
+}
+
+// calendarDate truncates t to its calendar day
+func calendarDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// monthDay encodes the month and day of t for annual comparisons
+func monthDay(t time.Time) int {
+	return int(t.Month())*100 + t.Day()
+}
+
+// parseCalendarDate converts a validated YYYY-MM-DD string to a date column value
+func parseCalendarDate(value string) pgtype.Date {
+	t, err := time.Parse(models.CalendarDateLayout, value)
+	if err != nil {
+		return pgtype.Date{}
+	}
+	return pgtype.Date{Time: t, Valid: true}
+}
+
+// parseCalendarTime converts a validated HH:MM string to a time column value
+func parseCalendarTime(value string) pgtype.Time {
+	t, err := time.Parse(models.CalendarTimeLayout, value)
+	if err != nil {
+		return pgtype.Time{}
+	}
+	return pgtype.Time{
+		Microseconds: int64(t.Hour())*int64(time.Hour/time.Microsecond) + int64(t.Minute())*int64(time.Minute/time.Microsecond),
+		Valid:        true,
+	}
+}
+
+// formatCalendarTime renders a time column value as HH:MM
+func formatCalendarTime(t pgtype.Time) string {
+	d := time.Duration(t.Microseconds) * time.Microsecond
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
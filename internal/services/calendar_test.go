@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockCalendarQuerier is a mock implementation of CalendarQuerier interface
+type MockCalendarQuerier struct {
+	mock.Mock
+}
+
+func (m *MockCalendarQuerier) ListOpeningHours(ctx context.Context) ([]queries.LibraryOpeningHour, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]queries.LibraryOpeningHour), args.Error(1)
+}
+
+func (m *MockCalendarQuerier) UpsertOpeningHours(ctx context.Context, arg queries.UpsertOpeningHoursParams) (queries.LibraryOpeningHour, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.LibraryOpeningHour), args.Error(1)
+}
+
+func (m *MockCalendarQuerier) CreateLibraryClosure(ctx context.Context, arg queries.CreateLibraryClosureParams) (queries.LibraryClosure, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.LibraryClosure), args.Error(1)
+}
+
+func (m *MockCalendarQuerier) GetLibraryClosureByID(ctx context.Context, id int32) (queries.LibraryClosure, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.LibraryClosure), args.Error(1)
+}
+
+func (m *MockCalendarQuerier) ListLibraryClosures(ctx context.Context) ([]queries.LibraryClosure, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]queries.LibraryClosure), args.Error(1)
+}
+
+func (m *MockCalendarQuerier) ListLibraryClosuresBetween(ctx context.Context, arg queries.ListLibraryClosuresBetweenParams) ([]queries.LibraryClosure, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]queries.LibraryClosure), args.Error(1)
+}
+
+func (m *MockCalendarQuerier) UpdateLibraryClosure(ctx context.Context, arg queries.UpdateLibraryClosureParams) (queries.LibraryClosure, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.LibraryClosure), args.Error(1)
+}
+
+func (m *MockCalendarQuerier) DeleteLibraryClosure(ctx context.Context, id int32) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func testDate(value string) pgtype.Date {
+	t, _ := time.Parse(models.CalendarDateLayout, value)
+	return pgtype.Date{Time: t, Valid: true}
+}
+
+// newTestCalendar returns a calendar closed at weekends, over Christmas and
+// New Year, and for a one-off break from 29 to 31 December 2025
+func newTestCalendar() *CalendarService {
+	mockQuerier := new(MockCalendarQuerier)
+	mockQuerier.On("ListOpeningHours", mock.Anything).Return([]queries.LibraryOpeningHour{
+		{DayOfWeek: 0, IsClosed: true},
+		{DayOfWeek: 6, IsClosed: true},
+	}, nil)
+	mockQuerier.On("ListLibraryClosuresBetween", mock.Anything, mock.Anything).Return([]queries.LibraryClosure{
+		{ID: 1, Name: "Christmas", StartDate: testDate("2000-12-25"), EndDate: testDate("2000-12-26"), RecursAnnually: true},
+		{ID: 2, Name: "New Year's Day", StartDate: testDate("2000-01-01"), EndDate: testDate("2000-01-01"), RecursAnnually: true},
+		{ID: 3, Name: "Term break", StartDate: testDate("2025-12-29"), EndDate: testDate("2025-12-31")},
+	}, nil)
+	return NewCalendarService(mockQuerier)
+}
+
+func TestCalendarService_NextOpenDay(t *testing.T) {
+	ctx := context.Background()
+	service := newTestCalendar()
+
+	t.Run("OpenDayUnchanged", func(t *testing.T) {
+		day := time.Date(2025, 12, 24, 10, 0, 0, 0, time.UTC)
+
+		next, err := service.NextOpenDay(ctx, day)
+
+		require.NoError(t, err)
+		assert.Equal(t, day, next)
+	})
+
+	t.Run("RollsPastHolidaysWeekendAndBreak", func(t *testing.T) {
+		day := time.Date(2025, 12, 25, 10, 0, 0, 0, time.UTC)
+
+		next, err := service.NextOpenDay(ctx, day)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC), next)
+	})
+}
+
+func TestCalendarService_CountOpenDays(t *testing.T) {
+	ctx := context.Background()
+	service := newTestCalendar()
+
+	testCases := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		expected int
+	}{
+		{"OverWeekend", time.Date(2025, 12, 19, 17, 0, 0, 0, time.UTC), time.Date(2025, 12, 22, 9, 0, 0, 0, time.UTC), 1},
+		{"OverHolidays", time.Date(2025, 12, 24, 17, 0, 0, 0, time.UTC), time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC), 1},
+		{"SameDay", time.Date(2025, 12, 22, 9, 0, 0, 0, time.UTC), time.Date(2025, 12, 22, 17, 0, 0, 0, time.UTC), 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			count, err := service.CountOpenDays(ctx, tc.from, tc.to)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, count)
+		})
+	}
+}
+
+func TestTransactionService_CalculateFine_SkipsClosedDays(t *testing.T) {
+	service := NewTransactionService(&MockTransactionQueries{}).WithCalendar(newTestCalendar())
+	policy := &CirculationPolicy{FinePerDay: decimal.NewFromFloat(1.00)}
+
+	// Nine calendar days late but only one of them was an open day
+	dueDate := time.Date(2025, 12, 24, 17, 0, 0, 0, time.UTC)
+	returnDate := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	fine, err := service.calculateFine(context.Background(), dueDate, returnDate, policy)
+
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromFloat(1.00).Equal(fine))
+}
+
+func TestCalendarService_UpdateOpeningHours(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		mockQuerier := new(MockCalendarQuerier)
+		service := NewCalendarService(mockQuerier)
+
+		mockQuerier.On("UpsertOpeningHours", ctx, queries.UpsertOpeningHoursParams{
+			DayOfWeek: 6,
+			OpensAt:   pgtype.Time{Microseconds: 9 * int64(time.Hour/time.Microsecond), Valid: true},
+			ClosesAt:  pgtype.Time{Microseconds: (13*60 + 30) * int64(time.Minute/time.Microsecond), Valid: true},
+		}).Return(queries.LibraryOpeningHour{
+			DayOfWeek: 6,
+			OpensAt:   pgtype.Time{Microseconds: 9 * int64(time.Hour/time.Microsecond), Valid: true},
+			ClosesAt:  pgtype.Time{Microseconds: (13*60 + 30) * int64(time.Minute/time.Microsecond), Valid: true},
+		}, nil)
+
+		result, err := service.UpdateOpeningHours(ctx, 6, models.UpdateOpeningHoursRequest{OpensAt: "09:00", ClosesAt: "13:30"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Saturday", result.Day)
+		require.NotNil(t, result.ClosesAt)
+		assert.Equal(t, "13:30", *result.ClosesAt)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("InvalidDay", func(t *testing.T) {
+		service := NewCalendarService(new(MockCalendarQuerier))
+
+		_, err := service.UpdateOpeningHours(ctx, 7, models.UpdateOpeningHoursRequest{IsClosed: true})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validation error")
+	})
+
+	t.Run("ClosingBeforeOpening", func(t *testing.T) {
+		service := NewCalendarService(new(MockCalendarQuerier))
+
+		_, err := service.UpdateOpeningHours(ctx, 1, models.UpdateOpeningHoursRequest{OpensAt: "17:00", ClosesAt: "08:00"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "closes_at must be after opens_at")
+	})
+}
+
+func TestCalendarService_CreateClosure(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SingleDay", func(t *testing.T) {
+		mockQuerier := new(MockCalendarQuerier)
+		service := NewCalendarService(mockQuerier)
+
+		mockQuerier.On("CreateLibraryClosure", ctx, queries.CreateLibraryClosureParams{
+			Name:      "Graduation",
+			StartDate: testDate("2026-11-20"),
+			EndDate:   testDate("2026-11-20"),
+		}).Return(queries.LibraryClosure{
+			ID:        8,
+			Name:      "Graduation",
+			StartDate: testDate("2026-11-20"),
+			EndDate:   testDate("2026-11-20"),
+		}, nil)
+
+		result, err := service.CreateClosure(ctx, models.CreateLibraryClosureRequest{Name: "Graduation", StartDate: "2026-11-20"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "2026-11-20", result.EndDate)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("EndBeforeStart", func(t *testing.T) {
+		service := NewCalendarService(new(MockCalendarQuerier))
+
+		_, err := service.CreateClosure(ctx, models.CreateLibraryClosureRequest{
+			Name:      "Term break",
+			StartDate: "2026-04-10",
+			EndDate:   "2026-04-01",
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "end_date cannot be before start_date")
+	})
+}
+
+func TestCalendarService_DeleteClosure_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockQuerier := new(MockCalendarQuerier)
+	service := NewCalendarService(mockQuerier)
+
+	mockQuerier.On("GetLibraryClosureByID", ctx, int32(99)).Return(queries.LibraryClosure{}, sql.ErrNoRows)
+
+	err := service.DeleteClosure(ctx, 99)
+
+	require.Error(t, err)
+	assert.Equal(t, "library closure not found", err.Error())
+	mockQuerier.AssertNotCalled(t, "DeleteLibraryClosure", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,486 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// CirculationPolicyQuerier defines the interface for circulation policy database operations
+type CirculationPolicyQuerier interface {
+	CreateCirculationPolicy(ctx context.Context, arg queries.CreateCirculationPolicyParams) (queries.CirculationPolicy, error)
+	GetCirculationPolicyByID(ctx context.Context, id int32) (queries.CirculationPolicy, error)
+	ListCirculationPolicies(ctx context.Context) ([]queries.CirculationPolicy, error)
+	UpdateCirculationPolicy(ctx context.Context, arg queries.UpdateCirculationPolicyParams) (queries.CirculationPolicy, error)
+	DeleteCirculationPolicy(ctx context.Context, id int32) error
+	ResolveCirculationPolicy(ctx context.Context, arg queries.ResolveCirculationPolicyParams) (queries.CirculationPolicy, error)
+}
+
+// CirculationPolicy is the set of circulation rules applied to a single decision
+type CirculationPolicy struct {
+	ID              int32 // zero for the built-in defaults
+	Name            string
+	LoanDays        int
+	MaxLoans        int
+	MaxRenewals     int
+	FinePerDay      decimal.Decimal
+	GracePeriodDays int
+	MaxReservations int
+	ReservationDays int
+	// Charges for copies that are lost or come back damaged
+	ReplacementCost     decimal.Decimal
+	ProcessingFee       decimal.Decimal
+	DamageChargePerStep decimal.Decimal
+	DamageStepThreshold int
+	// Guaranteed loan period and overdue rate once a loan has been recalled
+	RecallLoanDays   int
+	RecallFinePerDay decimal.Decimal
+	// Place in reservation queues; higher goes ahead of reservations placed under lower priorities
+	ReservationPriority int
+}
+
+// Charges used when a new policy does not set its own, matching the column defaults
+var (
+	defaultReplacementCost     = decimal.NewFromInt(50)
+	defaultProcessingFee       = decimal.NewFromInt(10)
+	defaultDamageChargePerStep = decimal.NewFromInt(5)
+	defaultDamageStepThreshold = 2
+	defaultRecallLoanDays      = 7
+	defaultRecallFinePerDay    = decimal.NewFromInt(1)
+)
+
+// fineForOverdueDays returns the fine for the given overdue days after the grace period
+func (p *CirculationPolicy) fineForOverdueDays(days int) decimal.Decimal {
+	if days <= p.GracePeriodDays {
+		return decimal.Zero
+	}
+	return p.FinePerDay.Mul(decimal.NewFromInt(int64(days - p.GracePeriodDays)))
+}
+
+// forRecall returns the policy with the recall overdue rate in place of the usual one
+func (p *CirculationPolicy) forRecall() *CirculationPolicy {
+	recall := *p
+	recall.FinePerDay = p.RecallFinePerDay
+	return &recall
+}
+
+// lostItemCharge returns what a student is charged for a copy that does not come back
+func (p *CirculationPolicy) lostItemCharge() decimal.Decimal {
+	return p.ReplacementCost.Add(p.ProcessingFee)
+}
+
+// damageCharge returns the charge for a copy whose condition dropped by the given
+// number of steps while on loan. Drops below the threshold are not charged and the
+// charge never exceeds the replacement cost.
+func (p *CirculationPolicy) damageCharge(steps int) decimal.Decimal {
+	if steps <= 0 || steps < p.DamageStepThreshold {
+		return decimal.Zero
+	}
+	charge := p.DamageChargePerStep.Mul(decimal.NewFromInt(int64(steps)))
+	if p.ReplacementCost.IsPositive() && charge.GreaterThan(p.ReplacementCost) {
+		return p.ReplacementCost
+	}
+	return charge
+}
+
+// PolicyContext identifies the patron and item a policy is resolved for
+type PolicyContext struct {
+	YearOfStudy int32
+	Department  string
+	UserType    models.PatronType
+	ItemType    string
+}
+
+// PolicyResolver resolves the circulation policy that applies to a patron and item.
+// It returns nil without error when no configured rule matches.
+type PolicyResolver interface {
+	ResolvePolicy(ctx context.Context, pc PolicyContext) (*CirculationPolicy, error)
+}
+
+// CirculationPolicyServiceInterface defines the interface for circulation policy service operations
+type CirculationPolicyServiceInterface interface {
+	PolicyResolver
+	CreatePolicy(ctx context.Context, req models.CreateCirculationPolicyRequest) (*models.CirculationPolicyResponse, error)
+	GetPolicy(ctx context.Context, id int32) (*models.CirculationPolicyResponse, error)
+	ListPolicies(ctx context.Context) ([]models.CirculationPolicyResponse, error)
+	UpdatePolicy(ctx context.Context, id int32, req models.UpdateCirculationPolicyRequest) (*models.CirculationPolicyResponse, error)
+	DeletePolicy(ctx context.Context, id int32) error
+}
+
+// CirculationPolicyService manages the circulation policy matrix
+type CirculationPolicyService struct {
+	querier CirculationPolicyQuerier
+}
+
+// NewCirculationPolicyService creates a new circulation policy service
+func NewCirculationPolicyService(querier CirculationPolicyQuerier) *CirculationPolicyService {
+	return &CirculationPolicyService{
+		querier: querier,
+	}
+}
+
+// studentPolicyContext builds the policy lookup for a student borrowing or reserving a book
+func studentPolicyContext(student queries.Student, book queries.Book) PolicyContext {
+	return PolicyContext{
+		YearOfStudy: student.YearOfStudy,
+		Department:  student.Department.String,
+		UserType:    models.PatronTypeStudent,
+		ItemType:    book.Genre.String,
+	}
+}
+
+// transactionPolicyContext builds the policy lookup for an existing loan
+func transactionPolicyContext(tx queries.GetTransactionByIDRow) PolicyContext {
+	return PolicyContext{
+		YearOfStudy: tx.YearOfStudy,
+		Department:  tx.Department.String,
+		UserType:    models.PatronTypeStudent,
+		ItemType:    tx.Genre.String,
+	}
+}
+
+// ResolvePolicy returns the most specific active policy matching the patron and item.
+// Higher priority wins first, then the rule with the most matching conditions.
+func (s *CirculationPolicyService) ResolvePolicy(ctx context.Context, pc PolicyContext) (*CirculationPolicy, error) {
+	policy, err := s.querier.ResolveCirculationPolicy(ctx, queries.ResolveCirculationPolicyParams{
+		YearOfStudy: pc.YearOfStudy,
+		Department:  pc.Department,
+		UserType:    string(pc.UserType),
+		ItemType:    pc.ItemType,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to resolve circulation policy: %w", err)
+	}
+
+	return &CirculationPolicy{
+		ID:              policy.ID,
+		Name:            policy.Name,
+		LoanDays:        int(policy.LoanDays),
+		MaxLoans:        int(policy.MaxLoans),
+		MaxRenewals:     int(policy.MaxRenewals),
+		FinePerDay:      numericToDecimal(policy.FinePerDay),
+		GracePeriodDays: int(policy.GracePeriodDays),
+		MaxReservations: int(policy.MaxReservations),
+		ReservationDays: int(policy.ReservationDays),
+
+		ReplacementCost:     numericToDecimal(policy.ReplacementCost),
+		ProcessingFee:       numericToDecimal(policy.ProcessingFee),
+		DamageChargePerStep: numericToDecimal(policy.DamageChargePerStep),
+		DamageStepThreshold: int(policy.DamageStepThreshold),
+		RecallLoanDays:      int(policy.RecallLoanDays),
+		RecallFinePerDay:    numericToDecimal(policy.RecallFinePerDay),
+		ReservationPriority: int(policy.ReservationPriority),
+	}, nil
+}
+
+// CreatePolicy creates a new circulation policy
+func (s *CirculationPolicyService) CreatePolicy(ctx context.Context, req models.CreateCirculationPolicyRequest) (*models.CirculationPolicyResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	params := queries.CreateCirculationPolicyParams{
+		Name:            req.Name,
+		Description:     optionalText(req.Description),
+		Department:      optionalText(req.Department),
+		UserType:        optionalText(req.UserType),
+		ItemType:        optionalText(req.ItemType),
+		LoanDays:        req.LoanDays,
+		MaxLoans:        req.MaxLoans,
+		MaxRenewals:     req.MaxRenewals,
+		FinePerDay:      decimalToNumeric(req.FinePerDay),
+		GracePeriodDays: req.GracePeriodDays,
+		MaxReservations: req.MaxReservations,
+		ReservationDays: req.ReservationDays,
+		Priority:        req.Priority,
+		IsActive:        pgtype.Bool{Bool: true, Valid: true},
+
+		ReplacementCost:     decimalToNumeric(defaultReplacementCost),
+		ProcessingFee:       decimalToNumeric(defaultProcessingFee),
+		DamageChargePerStep: decimalToNumeric(defaultDamageChargePerStep),
+		DamageStepThreshold: int32(defaultDamageStepThreshold),
+		RecallLoanDays:      int32(defaultRecallLoanDays),
+		RecallFinePerDay:    decimalToNumeric(defaultRecallFinePerDay),
+	}
+	if req.YearOfStudy != nil {
+		params.YearOfStudy = pgtype.Int4{Int32: *req.YearOfStudy, Valid: true}
+	}
+	if req.ReplacementCost != nil {
+		params.ReplacementCost = decimalToNumeric(*req.ReplacementCost)
+	}
+	if req.ProcessingFee != nil {
+		params.ProcessingFee = decimalToNumeric(*req.ProcessingFee)
+	}
+	if req.DamageChargePerStep != nil {
+		params.DamageChargePerStep = decimalToNumeric(*req.DamageChargePerStep)
+	}
+	if req.DamageStepThreshold != nil {
+		params.DamageStepThreshold = *req.DamageStepThreshold
+	}
+	if req.RecallLoanDays != nil {
+		params.RecallLoanDays = *req.RecallLoanDays
+	}
+	if req.RecallFinePerDay != nil {
+		params.RecallFinePerDay = decimalToNumeric(*req.RecallFinePerDay)
+	}
+	if req.ReservationPriority != nil {
+		params.ReservationPriority = *req.ReservationPriority
+	}
+	if req.IsActive != nil {
+		params.IsActive = pgtype.Bool{Bool: *req.IsActive, Valid: true}
+	}
+
+	policy, err := s.querier.CreateCirculationPolicy(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create circulation policy: %w", err)
+	}
+
+	response := convertToCirculationPolicyResponse(policy)
+	return &response, nil
+}
+
+// GetPolicy retrieves a circulation policy by ID
+func (s *CirculationPolicyService) GetPolicy(ctx context.Context, id int32) (*models.CirculationPolicyResponse, error) {
+	policy, err := s.getPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := convertToCirculationPolicyResponse(policy)
+	return &response, nil
+}
+
+// ListPolicies returns all circulation policies in resolution order
+func (s *CirculationPolicyService) ListPolicies(ctx context.Context) ([]models.CirculationPolicyResponse, error) {
+	policies, err := s.querier.ListCirculationPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list circulation policies: %w", err)
+	}
+
+	responses := make([]models.CirculationPolicyResponse, 0, len(policies))
+	for _, policy := range policies {
+		responses = append(responses, convertToCirculationPolicyResponse(policy))
+	}
+
+	return responses, nil
+}
+
+// UpdatePolicy applies the provided changes to a circulation policy
+func (s *CirculationPolicyService) UpdatePolicy(ctx context.Context, id int32, req models.UpdateCirculationPolicyRequest) (*models.CirculationPolicyResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	existing, err := s.getPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	params := queries.UpdateCirculationPolicyParams{
+		ID:              id,
+		Name:            existing.Name,
+		Description:     existing.Description,
+		YearOfStudy:     existing.YearOfStudy,
+		Department:      existing.Department,
+		UserType:        existing.UserType,
+		ItemType:        existing.ItemType,
+		LoanDays:        existing.LoanDays,
+		MaxLoans:        existing.MaxLoans,
+		MaxRenewals:     existing.MaxRenewals,
+		FinePerDay:      existing.FinePerDay,
+		GracePeriodDays: existing.GracePeriodDays,
+		MaxReservations: existing.MaxReservations,
+		ReservationDays: existing.ReservationDays,
+		Priority:        existing.Priority,
+		IsActive:        existing.IsActive,
+
+		ReplacementCost:     existing.ReplacementCost,
+		ProcessingFee:       existing.ProcessingFee,
+		DamageChargePerStep: existing.DamageChargePerStep,
+		DamageStepThreshold: existing.DamageStepThreshold,
+		RecallLoanDays:      existing.RecallLoanDays,
+		RecallFinePerDay:    existing.RecallFinePerDay,
+		ReservationPriority: existing.ReservationPriority,
+	}
+
+	if req.Name != nil {
+		params.Name = *req.Name
+	}
+	if req.Description != nil {
+		params.Description = optionalText(req.Description)
+	}
+	if req.YearOfStudy != nil {
+		params.YearOfStudy = pgtype.Int4{Int32: *req.YearOfStudy, Valid: *req.YearOfStudy != 0}
+	}
+	if req.Department != nil {
+		params.Department = optionalText(req.Department)
+	}
+	if req.UserType != nil {
+		params.UserType = optionalText(req.UserType)
+	}
+	if req.ItemType != nil {
+		params.ItemType = optionalText(req.ItemType)
+	}
+	if req.LoanDays != nil {
+		params.LoanDays = *req.LoanDays
+	}
+	if req.MaxLoans != nil {
+		params.MaxLoans = *req.MaxLoans
+	}
+	if req.MaxRenewals != nil {
+		params.MaxRenewals = *req.MaxRenewals
+	}
+	if req.FinePerDay != nil {
+		params.FinePerDay = decimalToNumeric(*req.FinePerDay)
+	}
+	if req.GracePeriodDays != nil {
+		params.GracePeriodDays = *req.GracePeriodDays
+	}
+	if req.MaxReservations != nil {
+		params.MaxReservations = *req.MaxReservations
+	}
+	if req.ReservationDays != nil {
+		params.ReservationDays = *req.ReservationDays
+	}
+	if req.Priority != nil {
+		params.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		params.IsActive = pgtype.Bool{Bool: *req.IsActive, Valid: true}
+	}
+	if req.ReplacementCost != nil {
+		params.ReplacementCost = decimalToNumeric(*req.ReplacementCost)
+	}
+	if req.ProcessingFee != nil {
+		params.ProcessingFee = decimalToNumeric(*req.ProcessingFee)
+	}
+	if req.DamageChargePerStep != nil {
+		params.DamageChargePerStep = decimalToNumeric(*req.DamageChargePerStep)
+	}
+	if req.DamageStepThreshold != nil {
+		params.DamageStepThreshold = *req.DamageStepThreshold
+	}
+	if req.RecallLoanDays != nil {
+		params.RecallLoanDays = *req.RecallLoanDays
+	}
+	if req.RecallFinePerDay != nil {
+		params.RecallFinePerDay = decimalToNumeric(*req.RecallFinePerDay)
+	}
+	if req.ReservationPriority != nil {
+		params.ReservationPriority = *req.ReservationPriority
+	}
+
+	policy, err := s.querier.UpdateCirculationPolicy(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update circulation policy: %w", err)
+	}
+
+	response := convertToCirculationPolicyResponse(policy)
+	return &response, nil
+}
+
+// DeletePolicy removes a circulation policy
+func (s *CirculationPolicyService) DeletePolicy(ctx context.Context, id int32) error {
+	if _, err := s.getPolicy(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.querier.DeleteCirculationPolicy(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete circulation policy: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CirculationPolicyService) getPolicy(ctx context.Context, id int32) (queries.CirculationPolicy, error) {
+	policy, err := s.querier.GetCirculationPolicyByID(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return queries.CirculationPolicy{}, fmt.Errorf("circulation policy not found")
+		}
+		return queries.CirculationPolicy{}, fmt.Errorf("failed to get circulation policy: %w", err)
+	}
+	return policy, nil
+}
+
+// convertToCirculationPolicyResponse converts a queries.CirculationPolicy to CirculationPolicyResponse
+func convertToCirculationPolicyResponse(policy queries.CirculationPolicy) models.CirculationPolicyResponse {
+	response := models.CirculationPolicyResponse{
+		ID:              policy.ID,
+		Name:            policy.Name,
+		LoanDays:        policy.LoanDays,
+		MaxLoans:        policy.MaxLoans,
+		MaxRenewals:     policy.MaxRenewals,
+		FinePerDay:      numericToDecimal(policy.FinePerDay),
+		GracePeriodDays: policy.GracePeriodDays,
+		MaxReservations: policy.MaxReservations,
+		ReservationDays: policy.ReservationDays,
+		Priority:        policy.Priority,
+		IsActive:        policy.IsActive.Bool,
+		CreatedAt:       policy.CreatedAt.Time,
+		UpdatedAt:       policy.UpdatedAt.Time,
+
+		ReplacementCost:     numericToDecimal(policy.ReplacementCost),
+		ProcessingFee:       numericToDecimal(policy.ProcessingFee),
+		DamageChargePerStep: numericToDecimal(policy.DamageChargePerStep),
+		DamageStepThreshold: policy.DamageStepThreshold,
+		RecallLoanDays:      policy.RecallLoanDays,
+		RecallFinePerDay:    numericToDecimal(policy.RecallFinePerDay),
+		ReservationPriority: policy.ReservationPriority,
+	}
+
+	if policy.Description.Valid {
+		response.Description = &policy.Description.String
+	}
+	if policy.YearOfStudy.Valid {
+		response.YearOfStudy = &policy.YearOfStudy.Int32
+	}
+	if policy.Department.Valid {
+		response.Department = &policy.Department.String
+	}
+	if policy.UserType.Valid {
+		response.UserType = &policy.UserType.String
+	}
+	if policy.ItemType.Valid {
+		response.ItemType = &policy.ItemType.String
+	}
+
+	return response
+}
+
+// optionalText converts an optional request string to a nullable column value;
+// empty strings are stored as NULL
+func optionalText(value *string) pgtype.Text {
+	if value == nil || *value == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *value, Valid: true}
+}
+
+// numericToDecimal converts a database numeric to a decimal, treating NULL as zero
+func numericToDecimal(n pgtype.Numeric) decimal.Decimal {
+	if !n.Valid || n.Int == nil {
+		return decimal.Zero
+	}
+	return decimal.NewFromBigInt(n.Int, n.Exp)
+}
+
+// decimalToNumeric converts a decimal to a database numeric with two decimal places
+func decimalToNumeric(d decimal.Decimal) pgtype.Numeric {
+	return pgtype.Numeric{
+		Int:   new(big.Int).Set(d.Round(2).Shift(2).BigInt()),
+		Exp:   -2,
+		Valid: true,
+	}
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockCirculationPolicyQuerier is a mock implementation of CirculationPolicyQuerier interface
+type MockCirculationPolicyQuerier struct {
+	mock.Mock
+}
+
+func (m *MockCirculationPolicyQuerier) CreateCirculationPolicy(ctx context.Context, arg queries.CreateCirculationPolicyParams) (queries.CirculationPolicy, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.CirculationPolicy), args.Error(1)
+}
+
+func (m *MockCirculationPolicyQuerier) GetCirculationPolicyByID(ctx context.Context, id int32) (queries.CirculationPolicy, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.CirculationPolicy), args.Error(1)
+}
+
+func (m *MockCirculationPolicyQuerier) ListCirculationPolicies(ctx context.Context) ([]queries.CirculationPolicy, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]queries.CirculationPolicy), args.Error(1)
+}
+
+func (m *MockCirculationPolicyQuerier) UpdateCirculationPolicy(ctx context.Context, arg queries.UpdateCirculationPolicyParams) (queries.CirculationPolicy, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.CirculationPolicy), args.Error(1)
+}
+
+func (m *MockCirculationPolicyQuerier) DeleteCirculationPolicy(ctx context.Context, id int32) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockCirculationPolicyQuerier) ResolveCirculationPolicy(ctx context.Context, arg queries.ResolveCirculationPolicyParams) (queries.CirculationPolicy, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.CirculationPolicy), args.Error(1)
+}
+
+// MockPolicyResolver is a mock implementation of PolicyResolver interface
+type MockPolicyResolver struct {
+	mock.Mock
+}
+
+func (m *MockPolicyResolver) ResolvePolicy(ctx context.Context, pc PolicyContext) (*CirculationPolicy, error) {
+	args := m.Called(ctx, pc)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*CirculationPolicy), args.Error(1)
+}
+
+func createTestCirculationPolicy() queries.CirculationPolicy {
+	return queries.CirculationPolicy{
+		ID:              2,
+		Name:            "Year 1 reference",
+		YearOfStudy:     pgtype.Int4{Int32: 1, Valid: true},
+		ItemType:        pgtype.Text{String: "Reference", Valid: true},
+		LoanDays:        3,
+		MaxLoans:        1,
+		MaxRenewals:     0,
+		FinePerDay:      decimalToNumeric(decimal.NewFromFloat(2.50)),
+		GracePeriodDays: 1,
+		MaxReservations: 2,
+		ReservationDays: 3,
+		Priority:        10,
+		IsActive:        pgtype.Bool{Bool: true, Valid: true},
+	}
+}
+
+func TestCirculationPolicyService_ResolvePolicy(t *testing.T) {
+	ctx := context.Background()
+	pc := PolicyContext{
+		YearOfStudy: 1,
+		Department:  "Science",
+		UserType:    models.PatronTypeStudent,
+		ItemType:    "Reference",
+	}
+	params := queries.ResolveCirculationPolicyParams{
+		YearOfStudy: 1,
+		Department:  "Science",
+		UserType:    "student",
+		ItemType:    "Reference",
+	}
+
+	t.Run("MatchingRule", func(t *testing.T) {
+		mockQuerier := new(MockCirculationPolicyQuerier)
+		service := NewCirculationPolicyService(mockQuerier)
+
+		mockQuerier.On("ResolveCirculationPolicy", ctx, params).Return(createTestCirculationPolicy(), nil)
+
+		policy, err := service.ResolvePolicy(ctx, pc)
+
+		require.NoError(t, err)
+		require.NotNil(t, policy)
+		assert.Equal(t, int32(2), policy.ID)
+		assert.Equal(t, 3, policy.LoanDays)
+		assert.Equal(t, 1, policy.MaxLoans)
+		assert.Equal(t, 1, policy.GracePeriodDays)
+		assert.True(t, decimal.NewFromFloat(2.50).Equal(policy.FinePerDay))
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("NoMatchingRule", func(t *testing.T) {
+		mockQuerier := new(MockCirculationPolicyQuerier)
+		service := NewCirculationPolicyService(mockQuerier)
+
+		mockQuerier.On("ResolveCirculationPolicy", ctx, params).Return(queries.CirculationPolicy{}, sql.ErrNoRows)
+
+		policy, err := service.ResolvePolicy(ctx, pc)
+
+		require.NoError(t, err)
+		assert.Nil(t, policy)
+	})
+}
+
+func TestCirculationPolicyService_CreatePolicy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		mockQuerier := new(MockCirculationPolicyQuerier)
+		service := NewCirculationPolicyService(mockQuerier)
+
+		year := int32(1)
+		itemType := "Reference"
+		mockQuerier.On("CreateCirculationPolicy", ctx, queries.CreateCirculationPolicyParams{
+			Name:            "Year 1 reference",
+			YearOfStudy:     pgtype.Int4{Int32: 1, Valid: true},
+			ItemType:        pgtype.Text{String: "Reference", Valid: true},
+			LoanDays:        3,
+			MaxLoans:        1,
+			FinePerDay:      decimalToNumeric(decimal.NewFromFloat(2.50)),
+			GracePeriodDays: 1,
+			MaxReservations: 2,
+			ReservationDays: 3,
+			Priority:        10,
+			IsActive:        pgtype.Bool{Bool: true, Valid: true},
+
+			ReplacementCost:     decimalToNumeric(defaultReplacementCost),
+			ProcessingFee:       decimalToNumeric(defaultProcessingFee),
+			DamageChargePerStep: decimalToNumeric(defaultDamageChargePerStep),
+			DamageStepThreshold: 2,
+			RecallLoanDays:      7,
+			RecallFinePerDay:    decimalToNumeric(defaultRecallFinePerDay),
+		}).Return(createTestCirculationPolicy(), nil)
+
+		result, err := service.CreatePolicy(ctx, models.CreateCirculationPolicyRequest{
+			Name:            " Year 1 reference ",
+			YearOfStudy:     &year,
+			ItemType:        &itemType,
+			LoanDays:        3,
+			MaxLoans:        1,
+			FinePerDay:      decimal.NewFromFloat(2.50),
+			GracePeriodDays: 1,
+			MaxReservations: 2,
+			ReservationDays: 3,
+			Priority:        10,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), result.ID)
+		require.NotNil(t, result.YearOfStudy)
+		assert.Equal(t, int32(1), *result.YearOfStudy)
+		assert.Nil(t, result.Department)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("InvalidUserType", func(t *testing.T) {
+		service := NewCirculationPolicyService(new(MockCirculationPolicyQuerier))
+
+		_, err := service.CreatePolicy(ctx, models.CreateCirculationPolicyRequest{
+			Name:            "Visitors",
+			UserType:        stringPtr("visitor"),
+			LoanDays:        7,
+			ReservationDays: 7,
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validation error")
+	})
+}
+
+func TestCirculationPolicyService_UpdatePolicy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ClearsMatchingFields", func(t *testing.T) {
+		mockQuerier := new(MockCirculationPolicyQuerier)
+		service := NewCirculationPolicyService(mockQuerier)
+
+		existing := createTestCirculationPolicy()
+		updated := existing
+		updated.YearOfStudy = pgtype.Int4{}
+		updated.ItemType = pgtype.Text{}
+		updated.LoanDays = 7
+
+		mockQuerier.On("GetCirculationPolicyByID", ctx, int32(2)).Return(existing, nil)
+		mockQuerier.On("UpdateCirculationPolicy", ctx, mock.MatchedBy(func(arg queries.UpdateCirculationPolicyParams) bool {
+			return arg.ID == 2 &&
+				!arg.YearOfStudy.Valid &&
+				!arg.ItemType.Valid &&
+				arg.LoanDays == 7 &&
+				arg.MaxLoans == existing.MaxLoans &&
+				arg.Name == existing.Name
+		})).Return(updated, nil)
+
+		year := int32(0)
+		loanDays := int32(7)
+		result, err := service.UpdatePolicy(ctx, 2, models.UpdateCirculationPolicyRequest{
+			YearOfStudy: &year,
+			ItemType:    stringPtr(""),
+			LoanDays:    &loanDays,
+		})
+
+		require.NoError(t, err)
+		assert.Nil(t, result.YearOfStudy)
+		assert.Nil(t, result.ItemType)
+		assert.Equal(t, int32(7), result.LoanDays)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockQuerier := new(MockCirculationPolicyQuerier)
+		service := NewCirculationPolicyService(mockQuerier)
+
+		mockQuerier.On("GetCirculationPolicyByID", ctx, int32(99)).Return(queries.CirculationPolicy{}, sql.ErrNoRows)
+
+		_, err := service.UpdatePolicy(ctx, 99, models.UpdateCirculationPolicyRequest{})
+
+		require.Error(t, err)
+		assert.Equal(t, "circulation policy not found", err.Error())
+	})
+}
+
+func TestCirculationPolicyService_DeletePolicy(t *testing.T) {
+	ctx := context.Background()
+	mockQuerier := new(MockCirculationPolicyQuerier)
+	service := NewCirculationPolicyService(mockQuerier)
+
+	mockQuerier.On("GetCirculationPolicyByID", ctx, int32(2)).Return(createTestCirculationPolicy(), nil)
+	mockQuerier.On("DeleteCirculationPolicy", ctx, int32(2)).Return(nil)
+
+	err := service.DeletePolicy(ctx, 2)
+
+	require.NoError(t, err)
+	mockQuerier.AssertExpectations(t)
+}
+
+func TestCirculationPolicy_Charges(t *testing.T) {
+	policy := &CirculationPolicy{
+		ReplacementCost:     decimal.NewFromInt(12),
+		ProcessingFee:       decimal.NewFromInt(3),
+		DamageChargePerStep: decimal.NewFromInt(5),
+		DamageStepThreshold: 2,
+	}
+
+	assert.True(t, policy.lostItemCharge().Equal(decimal.NewFromInt(15)))
+	assert.True(t, policy.damageCharge(1).IsZero(), "drops below the threshold are free")
+	assert.True(t, policy.damageCharge(2).Equal(decimal.NewFromInt(10)))
+	assert.True(t, policy.damageCharge(4).Equal(decimal.NewFromInt(12)), "capped at the replacement cost")
+	assert.Equal(t, 3, conditionStepsLost("good", "damaged"))
+	assert.Equal(t, 0, conditionStepsLost("fair", "good"))
+}
+
+func TestCirculationPolicy_ForRecall(t *testing.T) {
+	policy := &CirculationPolicy{
+		FinePerDay:       decimal.NewFromFloat(0.50),
+		GracePeriodDays:  1,
+		RecallFinePerDay: decimal.NewFromInt(2),
+	}
+
+	recall := policy.forRecall()
+
+	assert.True(t, recall.fineForOverdueDays(4).Equal(decimal.NewFromInt(6)), "three days past the grace period at the recall rate")
+	assert.True(t, policy.FinePerDay.Equal(decimal.NewFromFloat(0.50)), "the resolved policy is left unchanged")
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	valid := []string{"* * * * *", "*/15 * * * *", "0 8 * * 1-5", "0,30 9-17 * * *", "5/20 * * * *", "0 0 * * 7", "@daily", "@Weekly"}
+	for _, expr := range valid {
+		_, err := ParseCron(expr)
+		assert.NoError(t, err, expr)
+	}
+
+	invalid := []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@often"}
+	for _, expr := range invalid {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2025, time.January, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		expr     string
+		from     time.Time
+		expected time.Time
+	}{
+		{"EveryMinute", "* * * * *", from, time.Date(2025, 1, 15, 10, 8, 0, 0, time.UTC)},
+		{"Step", "*/15 * * * *", from, time.Date(2025, 1, 15, 10, 15, 0, 0, time.UTC)},
+		{"LaterToday", "30 14 * * *", from, time.Date(2025, 1, 15, 14, 30, 0, 0, time.UTC)},
+		{"Tomorrow", "0 8 * * *", from, time.Date(2025, 1, 16, 8, 0, 0, 0, time.UTC)},
+		{"NeverTheSameMinute", "7 10 * * *", from, time.Date(2025, 1, 16, 10, 7, 0, 0, time.UTC)},
+		{"NextMonday", "0 10 * * 1", from, time.Date(2025, 1, 20, 10, 0, 0, 0, time.UTC)},
+		{"SundayAsSeven", "0 0 * * 7", from, time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"NextYear", "0 0 1 1 *", from, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"LeapDay", "0 0 29 2 *", from, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// With both day fields restricted either one matching fires
+		{"DayOfMonthOrWeek", "0 0 1 * 5", from, time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{"DayOfMonthStep", "0 0 */10 * *", from, time.Date(2025, 1, 21, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule.Next(tt.from))
+		})
+	}
+
+	t.Run("NeverFires", func(t *testing.T) {
+		schedule, err := ParseCron("0 0 30 2 *")
+		require.NoError(t, err)
+		assert.True(t, schedule.Next(from).IsZero())
+	})
+
+	t.Run("KeepsLocation", func(t *testing.T) {
+		nairobi := time.FixedZone("EAT", 3*60*60)
+		schedule, err := ParseCron("0 8 * * *")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 1, 16, 8, 0, 0, 0, nairobi), schedule.Next(from.In(nairobi)))
+	})
+}
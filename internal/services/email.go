@@ -1,10 +1,15 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
+	"mime"
 	"net/smtp"
 	"strings"
 	"time"
@@ -15,6 +20,7 @@ import (
 // EmailServiceInterface defines the interface for email service operations
 type EmailServiceInterface interface {
 	SendEmail(ctx context.Context, to, subject, body string, isHTML bool) error
+	SendMessage(ctx context.Context, message *EmailMessage) error
 	SendTemplatedEmail(ctx context.Context, to string, template *models.EmailTemplate, data map[string]interface{}) error
 	SendBatchEmails(ctx context.Context, emails []EmailRequest) error
 	ValidateEmail(email string) error
@@ -32,6 +38,31 @@ type EmailRequest struct {
 	Data     map[string]interface{} `json:"data,omitempty"`
 }
 
+// EmailMessage is an email with a plain-text body, an HTML body or both. An
+// email with only an HTML body is sent with a plain-text version of it too.
+type EmailMessage struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+	// Attachments are files sent with the email, such as receipts and report
+	// exports, and images shown inline by the HTML body
+	Attachments []EmailAttachment
+	// ListUnsubscribe is the one-click unsubscribe URL of a notice the recipient
+	// can turn off
+	ListUnsubscribe string
+}
+
+// EmailAttachment is a file sent with an email. An attachment with a ContentID
+// is an inline image, shown where the HTML body refers to cid:<ContentID>.
+type EmailAttachment struct {
+	Filename string
+	// ContentType is guessed from the filename when empty
+	ContentType string
+	Data        []byte
+	ContentID   string
+}
+
 // EmailDeliveryStatus represents the delivery status of an email
 type EmailDeliveryStatus struct {
 	MessageID     string                    `json:"message_id"`
@@ -41,10 +72,40 @@ type EmailDeliveryStatus struct {
 	RetryCount    int                       `json:"retry_count"`
 }
 
+// EmailTracking ties an email to its delivery record so that a bounce can be
+// matched back to it. The email is sent with a return path carrying DeliveryID,
+// and MessageID is set to the Message-ID it was sent with.
+type EmailTracking struct {
+	DeliveryID int32
+	MessageID  string
+}
+
+type emailTrackingKey struct{}
+
+// WithEmailTracking returns a context whose email is tracked by tracking
+func WithEmailTracking(ctx context.Context, tracking *EmailTracking) context.Context {
+	return context.WithValue(ctx, emailTrackingKey{}, tracking)
+}
+
+type listUnsubscribeKey struct{}
+
+// WithListUnsubscribe returns a context whose email is sent with a
+// List-Unsubscribe header for the one-click unsubscribe URL url
+func WithListUnsubscribe(ctx context.Context, url string) context.Context {
+	return context.WithValue(ctx, listUnsubscribeKey{}, url)
+}
+
+// LogoContentID is the Content-ID HTML emails show the configured logo with,
+// as <img src="cid:logo">
+const LogoContentID = "logo"
+
 // EmailService handles email-related operations
 type EmailService struct {
-	config *models.EmailConfig
-	logger *slog.Logger
+	config    *models.EmailConfig
+	templates TemplateResolver
+	dkim      *dkimSigner
+	logo      *EmailAttachment
+	logger    *slog.Logger
 }
 
 // NewEmailService creates a new email service
@@ -59,40 +120,148 @@ func NewEmailService(config *models.EmailConfig, logger *slog.Logger) *EmailServ
 		logger.Warn("Email service created with invalid configuration", "error", err)
 	}
 
+	if config != nil && config.DKIMDomain != "" && config.DKIMSelector != "" && config.DKIMPrivateKeyPath != "" {
+		signer, err := loadDKIMSigner(config.DKIMDomain, config.DKIMSelector, config.DKIMPrivateKeyPath)
+		if err != nil {
+			logger.Error("Failed to load DKIM key, email will not be signed", "error", err)
+		} else {
+			service.dkim = signer
+		}
+	}
+
+	if config != nil && config.LogoPath != "" {
+		logo, err := loadInlineImage(config.LogoPath, LogoContentID)
+		if err != nil {
+			logger.Error("Failed to load email logo", "path", config.LogoPath, "error", err)
+		} else {
+			service.logo = logo
+		}
+	}
+
 	return service
 }
 
+// WithTemplateStore makes SendTemplatedEmail use the published version of
+// built-in templates from the template store when there is one
+func (s *EmailService) WithTemplateStore(templates TemplateResolver) *EmailService {
+	s.templates = templates
+	return s
+}
+
 // SendEmail sends a simple email
 func (s *EmailService) SendEmail(ctx context.Context, to, subject, body string, isHTML bool) error {
-	if err := s.ValidateEmail(to); err != nil {
+	message := &EmailMessage{To: to, Subject: subject}
+	if isHTML {
+		message.HTMLBody = body
+	} else {
+		message.TextBody = body
+	}
+	return s.SendMessage(ctx, message)
+}
+
+// SendMessage sends an email with its attachments, signed with DKIM when a key
+// is configured
+func (s *EmailService) SendMessage(ctx context.Context, message *EmailMessage) error {
+	if err := s.ValidateEmail(message.To); err != nil {
 		return fmt.Errorf("invalid recipient email: %w", err)
 	}
 
+	tracking, _ := ctx.Value(emailTrackingKey{}).(*EmailTracking)
+	messageID, err := s.newMessageID()
+	if err != nil {
+		return fmt.Errorf("failed to create message ID: %w", err)
+	}
+
+	outgoing := *message
+	if outgoing.ListUnsubscribe == "" {
+		outgoing.ListUnsubscribe, _ = ctx.Value(listUnsubscribeKey{}).(string)
+	}
+	if s.logo != nil && strings.Contains(outgoing.HTMLBody, "cid:"+LogoContentID) && !hasContentID(outgoing.Attachments, LogoContentID) {
+		outgoing.Attachments = append(append([]EmailAttachment(nil), outgoing.Attachments...), *s.logo)
+	}
+
 	// Create message
-	message := s.buildMessage(s.config.FromEmail, to, subject, body, isHTML)
+	now := time.Now()
+	raw, err := s.buildMessage(&outgoing, messageID, now)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	if s.dkim != nil {
+		signature, err := s.dkim.sign(raw, now)
+		if err != nil {
+			return fmt.Errorf("failed to sign email: %w", err)
+		}
+		raw = append([]byte(signature), raw...)
+	}
 
 	// Send email
-	if err := s.sendSMTP(to, message); err != nil {
+	if err := s.sendSMTP(s.returnPath(tracking), message.To, raw); err != nil {
 		s.logger.Error("Failed to send email",
-			"to", to,
-			"subject", subject,
+			"to", message.To,
+			"subject", message.Subject,
 			"error", err)
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
+	if tracking != nil {
+		tracking.MessageID = messageID
+	}
+
 	s.logger.Info("Email sent successfully",
-		"to", to,
-		"subject", subject)
+		"to", message.To,
+		"subject", message.Subject,
+		"message_id", messageID,
+		"attachments", len(outgoing.Attachments))
 
 	return nil
 }
 
+// newMessageID creates a unique Message-ID, without angle brackets, in the
+// domain of the from address
+func (s *EmailService) newMessageID() (string, error) {
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	domain := "localhost"
+	if at := strings.LastIndex(s.config.FromEmail, "@"); at >= 0 && at < len(s.config.FromEmail)-1 {
+		domain = s.config.FromEmail[at+1:]
+	}
+	return fmt.Sprintf("%d.%s@%s", time.Now().UnixNano(), hex.EncodeToString(random), domain), nil
+}
+
+// returnPath is the envelope sender an email is sent with, where its bounces
+// are delivered. A tracked email's return path carries its signed delivery ID.
+func (s *EmailService) returnPath(tracking *EmailTracking) string {
+	if s.config.BounceAddress == "" {
+		return s.config.FromEmail
+	}
+	if tracking != nil && tracking.DeliveryID > 0 && s.config.BounceSecret != "" {
+		return verpAddress(s.config.BounceAddress, s.config.BounceSecret, tracking.DeliveryID)
+	}
+	return s.config.BounceAddress
+}
+
 // SendTemplatedEmail sends an email using a template
 func (s *EmailService) SendTemplatedEmail(ctx context.Context, to string, template *models.EmailTemplate, data map[string]interface{}) error {
 	if template == nil {
 		return fmt.Errorf("template cannot be nil")
 	}
 
+	// Templates that did not come from the store give way to its published version
+	if template.ID == 0 && s.templates != nil {
+		stored, err := s.templates.ResolveTemplate(ctx, template.Name, NormalizeLocale(template.Locale))
+		switch {
+		case err == nil:
+			template = stored
+		case !errors.Is(err, ErrTemplateNotFound):
+			s.logger.Warn("Failed to resolve stored email template, using the one given",
+				"template", template.Name,
+				"error", err)
+		}
+	}
+
 	if !template.IsActive {
 		return fmt.Errorf("template is not active")
 	}
@@ -187,28 +356,39 @@ func (s *EmailService) GetDeliveryStatus(ctx context.Context, messageID string)
 	}, nil
 }
 
-// buildMessage constructs the email message
-func (s *EmailService) buildMessage(from, to, subject, body string, isHTML bool) string {
-	headers := make(map[string]string)
-	headers["From"] = fmt.Sprintf("%s <%s>", s.config.FromName, from)
-	headers["To"] = to
-	headers["Subject"] = subject
-	headers["MIME-Version"] = "1.0"
-
-	if isHTML {
-		headers["Content-Type"] = "text/html; charset=UTF-8"
-	} else {
-		headers["Content-Type"] = "text/plain; charset=UTF-8"
-	}
-
-	var message strings.Builder
-	for key, value := range headers {
-		message.WriteString(fmt.Sprintf("%s: %s\r\n", key, value))
+// buildMessage constructs the email message. Its body is a text/plain part, or
+// a multipart/alternative of text and HTML, wrapped in multipart/related with
+// any inline images and then in multipart/mixed with any attachments.
+func (s *EmailService) buildMessage(message *EmailMessage, messageID string, date time.Time) ([]byte, error) {
+	body, err := buildMessageBody(message)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw bytes.Buffer
+	writeHeader := func(key, value string) {
+		raw.WriteString(key + ": " + value + "\r\n")
+	}
+	writeHeader("Date", date.Format(time.RFC1123Z))
+	writeHeader("From", formatAddress(s.config.FromName, s.config.FromEmail))
+	writeHeader("To", message.To)
+	writeHeader("Subject", mime.QEncoding.Encode("UTF-8", message.Subject))
+	writeHeader("Message-ID", "<"+messageID+">")
+	writeHeader("MIME-Version", "1.0")
+	if message.ListUnsubscribe != "" {
+		// One-click unsubscribe (RFC 8058) posts to the URL without a visit
+		writeHeader("List-Unsubscribe", "<"+message.ListUnsubscribe+">")
+		writeHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	for _, key := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+		if value := body.header.Get(key); value != "" {
+			writeHeader(key, value)
+		}
 	}
-	message.WriteString("\r\n")
-	message.WriteString(body)
+	raw.WriteString("\r\n")
+	raw.Write(body.body)
 
-	return message.String()
+	return raw.Bytes(), nil
 }
 
 // processTemplate processes template variables
@@ -226,8 +406,8 @@ func (s *EmailService) processTemplate(template string, data map[string]interfac
 	return result, nil
 }
 
-// sendSMTP sends email via SMTP
-func (s *EmailService) sendSMTP(to, message string) error {
+// sendSMTP sends email via SMTP from the envelope sender from
+func (s *EmailService) sendSMTP(from, to string, message []byte) error {
 	// Set up authentication
 	auth := smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
 
@@ -241,13 +421,13 @@ func (s *EmailService) sendSMTP(to, message string) error {
 
 	if s.config.UseSSL {
 		// SSL connection
-		err = s.sendWithSSL(addr, auth, s.config.FromEmail, recipients, []byte(message))
+		err = s.sendWithSSL(addr, auth, from, recipients, message)
 	} else if s.config.UseTLS {
 		// TLS connection
-		err = s.sendWithTLS(addr, auth, s.config.FromEmail, recipients, []byte(message))
+		err = s.sendWithTLS(addr, auth, from, recipients, message)
 	} else {
 		// Plain connection (not recommended for production)
-		err = smtp.SendMail(addr, auth, s.config.FromEmail, recipients, []byte(message))
+		err = smtp.SendMail(addr, auth, from, recipients, message)
 	}
 
 	return err
@@ -452,17 +632,17 @@ var defaultTemplates = map[string]*models.EmailTemplate{
 	"overdue_reminder": {
 		Name:      "overdue_reminder",
 		Subject:   "Book Overdue - {{.BookTitle}}",
-		Body:      "Dear {{.StudentName}},\n\nYour book \"{{.BookTitle}}\" is overdue. Please return it as soon as possible to avoid additional fines.\n\nDue Date: {{.DueDate}}\nFine Amount: {{.FineAmount}}\n\nThank you,\nLibrary Management System",
+		Body:      "Dear {{.StudentName}},\n\nYour book \"{{.BookTitle}}\" by {{.BookAuthor}} is overdue. Please return it as soon as possible to avoid additional fines.\n\nDue Date: {{.DueDate}}\nDays Overdue: {{.DaysOverdue}}\nFine Amount: {{.FineAmount}}\n\nThank you,\nLibrary Management System",
 		IsHTML:    false,
-		Variables: []string{"BookTitle", "StudentName", "DueDate", "FineAmount"},
+		Variables: []string{"BookTitle", "BookAuthor", "StudentName", "DueDate", "DaysOverdue", "FineAmount"},
 		IsActive:  true,
 	},
 	"due_soon": {
 		Name:      "due_soon",
 		Subject:   "Book Due Soon - {{.BookTitle}}",
-		Body:      "Dear {{.StudentName}},\n\nThis is a reminder that your book \"{{.BookTitle}}\" is due soon.\n\nDue Date: {{.DueDate}}\n\nPlease return it on time to avoid fines.\n\nThank you,\nLibrary Management System",
+		Body:      "Dear {{.StudentName}},\n\nThis is a reminder that your book \"{{.BookTitle}}\" by {{.BookAuthor}} is due soon. It is due for return in {{.DaysUntilDue}} day(s).\n\nDue Date: {{.DueDate}}\n\nPlease return it on time to avoid fines.\n\nThank you,\nLibrary Management System",
 		IsHTML:    false,
-		Variables: []string{"BookTitle", "StudentName", "DueDate"},
+		Variables: []string{"BookTitle", "BookAuthor", "StudentName", "DueDate", "DaysUntilDue"},
 		IsActive:  true,
 	},
 	"book_available": {
@@ -481,11 +661,155 @@ var defaultTemplates = map[string]*models.EmailTemplate{
 		Variables: []string{"BookTitle", "StudentName", "FineAmount", "FineReason"},
 		IsActive:  true,
 	},
+	"recall_notice": {
+		Name:      "recall_notice",
+		Subject:   "Book Recalled - {{.BookTitle}}",
+		Body:      "Dear {{.StudentName}},\n\nThe book \"{{.BookTitle}}\" by {{.BookAuthor}} that you have on loan has been recalled because another reader needs it.\n\nReason: {{.RecallReason}}\nNew Due Date: {{.DueDate}}\n\nThe loan can no longer be renewed. If the book is returned late, the overdue fine is {{.FinePerDay}} a day.\n\nThank you,\nLibrary Management System",
+		IsHTML:    false,
+		Variables: []string{"BookTitle", "BookAuthor", "StudentName", "RecallReason", "DueDate", "FinePerDay"},
+		IsActive:  true,
+	},
+	"reservation_ready": {
+		Name:      "reservation_ready",
+		Subject:   "Reserved Book Ready - {{.BookTitle}}",
+		Body:      "Dear {{.StudentName}},\n\nThe book \"{{.BookTitle}}\" by {{.BookAuthor}} that you reserved has been returned and is being held for you at the library.\n\nCollect By: {{.PickupDeadline}}\n\nIf it is not collected by then, it will be offered to the next reader waiting for it.\n\nThank you,\nLibrary Management System",
+		IsHTML:    false,
+		Variables: []string{"BookTitle", "BookAuthor", "StudentName", "PickupDeadline"},
+		IsActive:  true,
+	},
+	"reservation_expired": {
+		Name:      "reservation_expired",
+		Subject:   "Reservation Expired - {{.BookTitle}}",
+		Body:      "Dear {{.StudentName}},\n\nYour reservation for the book \"{{.BookTitle}}\" by {{.BookAuthor}} has expired before a copy became available.\n\nIf you still need the book, you can reserve it again.\n\nThank you,\nLibrary Management System",
+		IsHTML:    false,
+		Variables: []string{"BookTitle", "BookAuthor", "StudentName"},
+		IsActive:  true,
+	},
+	"account_blocked": {
+		Name:      "account_blocked",
+		Subject:   "Library Account Blocked",
+		Body:      "Dear {{.StudentName}},\n\nYour library account has been blocked. You cannot borrow, renew or reserve books until the block is lifted.\n\nReason: {{.BlockReason}}\n\nPlease contact the library if you have any questions.\n\nThank you,\nLibrary Management System",
+		IsHTML:    false,
+		Variables: []string{"StudentName", "BlockReason"},
+		IsActive:  true,
+	},
+	"announcement": {
+		Name:      "announcement",
+		Subject:   "{{.NotificationTitle}}",
+		Body:      "{{.NotificationMessage}}\n\nThank you,\nLibrary Management System",
+		IsHTML:    false,
+		Variables: []string{"NotificationTitle", "NotificationMessage"},
+		IsActive:  true,
+	},
+	DigestTemplateName: {
+		Name:      DigestTemplateName,
+		Subject:   "Your {{.DigestPeriod}} library summary: {{.ItemCount}} update(s)",
+		Body:      "Hello,\n\nHere is your {{.DigestPeriod}} summary from the library:\n\n{{.DigestItems}}\n\nYou can change how often you receive these summaries in your notification preferences.\n\nThank you,\nLibrary Management System",
+		IsHTML:    false,
+		Variables: []string{"DigestPeriod", "ItemCount", "DigestItems"},
+		IsActive:  true,
+	},
+}
+
+// Swahili versions of the default email templates
+var swahiliTemplates = map[string]*models.EmailTemplate{
+	"overdue_reminder": {
+		Name:      "overdue_reminder",
+		Subject:   "Kitabu Kimechelewa - {{.BookTitle}}",
+		Body:      "Mpendwa {{.StudentName}},\n\nKitabu chako \"{{.BookTitle}}\" cha {{.BookAuthor}} kimepitisha tarehe ya kurudishwa. Tafadhali kirudishe haraka iwezekanavyo ili kuepuka faini zaidi.\n\nTarehe ya Kurudisha: {{.DueDate}}\nSiku za Kuchelewa: {{.DaysOverdue}}\nKiasi cha Faini: {{.FineAmount}}\n\nAsante,\nMfumo wa Usimamizi wa Maktaba",
+		IsHTML:    false,
+		Variables: []string{"BookTitle", "BookAuthor", "StudentName", "DueDate", "DaysOverdue", "FineAmount"},
+		IsActive:  true,
+	},
+	"due_soon": {
+		Name:      "due_soon",
+		Subject:   "Kitabu Kinakaribia Kurudishwa - {{.BookTitle}}",
+		Body:      "Mpendwa {{.StudentName}},\n\nHuu ni ukumbusho kwamba kitabu chako \"{{.BookTitle}}\" cha {{.BookAuthor}} kinapaswa kurudishwa baada ya siku {{.DaysUntilDue}}.\n\nTarehe ya Kurudisha: {{.DueDate}}\n\nTafadhali kirudishe kwa wakati ili kuepuka faini.\n\nAsante,\nMfumo wa Usimamizi wa Maktaba",
+		IsHTML:    false,
+		Variables: []string{"BookTitle", "BookAuthor", "StudentName", "DueDate", "DaysUntilDue"},
+		IsActive:  true,
+	},
+	"book_available": {
+		Name:      "book_available",
+		Subject:   "Kitabu Ulichohifadhi Kinapatikana - {{.BookTitle}}",
+		Body:      "Mpendwa {{.StudentName}},\n\nKitabu \"{{.BookTitle}}\" ulichohifadhi sasa kinapatikana.\n\nTafadhali fika maktaba ndani ya siku {{.ExpirationDays}} kukichukua.\n\nAsante,\nMfumo wa Usimamizi wa Maktaba",
+		IsHTML:    false,
+		Variables: []string{"BookTitle", "StudentName", "ExpirationDays"},
+		IsActive:  true,
+	},
+	"fine_notice": {
+		Name:      "fine_notice",
+		Subject:   "Taarifa ya Faini - {{.BookTitle}}",
+		Body:      "Mpendwa {{.StudentName}},\n\nUna faini ambayo bado haijalipwa kwa kitabu \"{{.BookTitle}}\".\n\nKiasi cha Faini: {{.FineAmount}}\nSababu: {{.FineReason}}\n\nTafadhali lipa faini hii mapema iwezekanavyo.\n\nAsante,\nMfumo wa Usimamizi wa Maktaba",
+		IsHTML:    false,
+		Variables: []string{"BookTitle", "StudentName", "FineAmount", "FineReason"},
+		IsActive:  true,
+	},
+	"recall_notice": {
+		Name:      "recall_notice",
+		Subject:   "Kitabu Kimerudishwa Mapema - {{.BookTitle}}",
+		Body:      "Mpendwa {{.StudentName}},\n\nKitabu \"{{.BookTitle}}\" cha {{.BookAuthor}} ulichoazima kinahitajika kurudishwa mapema kwa sababu msomaji mwingine anakihitaji.\n\nSababu: {{.RecallReason}}\nTarehe Mpya ya Kurudisha: {{.DueDate}}\n\nMuda wa mkopo huu hauwezi kuongezwa tena. Kikirudishwa kwa kuchelewa, faini ni {{.FinePerDay}} kwa siku.\n\nAsante,\nMfumo wa Usimamizi wa Maktaba",
+		IsHTML:    false,
+		Variables: []string{"BookTitle", "BookAuthor", "StudentName", "RecallReason", "DueDate", "FinePerDay"},
+		IsActive:  true,
+	},
+	"reservation_ready": {
+		Name:      "reservation_ready",
+		Subject:   "Kitabu Ulichohifadhi Kiko Tayari - {{.BookTitle}}",
+		Body:      "Mpendwa {{.StudentName}},\n\nKitabu \"{{.BookTitle}}\" cha {{.BookAuthor}} ulichohifadhi kimerudishwa na kinakusubiri maktaba.\n\nKichukue Kabla ya: {{.PickupDeadline}}\n\nKisipochukuliwa kufikia wakati huo, kitapewa msomaji anayefuata anayekisubiri.\n\nAsante,\nMfumo wa Usimamizi wa Maktaba",
+		IsHTML:    false,
+		Variables: []string{"BookTitle", "BookAuthor", "StudentName", "PickupDeadline"},
+		IsActive:  true,
+	},
+	"reservation_expired": {
+		Name:      "reservation_expired",
+		Subject:   "Muda wa Uhifadhi Umekwisha - {{.BookTitle}}",
+		Body:      "Mpendwa {{.StudentName}},\n\nMuda wa uhifadhi wako wa kitabu \"{{.BookTitle}}\" cha {{.BookAuthor}} umekwisha kabla nakala haijapatikana.\n\nIkiwa bado unakihitaji, unaweza kukihifadhi tena.\n\nAsante,\nMfumo wa Usimamizi wa Maktaba",
+		IsHTML:    false,
+		Variables: []string{"BookTitle", "BookAuthor", "StudentName"},
+		IsActive:  true,
+	},
+	"account_blocked": {
+		Name:      "account_blocked",
+		Subject:   "Akaunti Yako ya Maktaba Imezuiwa",
+		Body:      "Mpendwa {{.StudentName}},\n\nAkaunti yako ya maktaba imezuiwa. Huwezi kuazima, kuongeza muda au kuhifadhi vitabu hadi kizuizi kiondolewe.\n\nSababu: {{.BlockReason}}\n\nTafadhali wasiliana na maktaba ikiwa una maswali.\n\nAsante,\nMfumo wa Usimamizi wa Maktaba",
+		IsHTML:    false,
+		Variables: []string{"StudentName", "BlockReason"},
+		IsActive:  true,
+	},
+	"announcement": {
+		Name:      "announcement",
+		Subject:   "{{.NotificationTitle}}",
+		Body:      "{{.NotificationMessage}}\n\nAsante,\nMfumo wa Usimamizi wa Maktaba",
+		IsHTML:    false,
+		Variables: []string{"NotificationTitle", "NotificationMessage"},
+		IsActive:  true,
+	},
+	DigestTemplateName: {
+		Name:      DigestTemplateName,
+		Subject:   "Muhtasari wako wa {{.DigestPeriod}} wa maktaba: taarifa {{.ItemCount}}",
+		Body:      "Habari,\n\nHuu ni muhtasari wako wa {{.DigestPeriod}} kutoka maktaba:\n\n{{.DigestItems}}\n\nUnaweza kubadilisha mara unazopokea muhtasari huu katika mapendeleo yako ya arifa.\n\nAsante,\nMfumo wa Usimamizi wa Maktaba",
+		IsHTML:    false,
+		Variables: []string{"DigestPeriod", "ItemCount", "DigestItems"},
+		IsActive:  true,
+	},
+}
+
+// builtInTemplates holds the templates shipped with the system by locale
+var builtInTemplates = map[string]map[string]*models.EmailTemplate{
+	models.LocaleEnglish: defaultTemplates,
+	models.LocaleSwahili: swahiliTemplates,
 }
 
 // GetDefaultTemplate returns a default template by name
 func GetDefaultTemplate(name string) *models.EmailTemplate {
-	template, exists := defaultTemplates[name]
+	return GetBuiltInTemplate(name, models.DefaultLocale)
+}
+
+// GetBuiltInTemplate returns the template shipped with the system for a name and
+// locale, or nil if it has not been written in that locale
+func GetBuiltInTemplate(name, locale string) *models.EmailTemplate {
+	template, exists := builtInTemplates[locale][name]
 	if !exists {
 		return nil
 	}
@@ -493,6 +817,7 @@ func GetDefaultTemplate(name string) *models.EmailTemplate {
 	// Return a copy to avoid modification of the original
 	return &models.EmailTemplate{
 		Name:      template.Name,
+		Locale:    locale,
 		Subject:   template.Subject,
 		Body:      template.Body,
 		IsHTML:    template.IsHTML,
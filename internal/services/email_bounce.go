@@ -0,0 +1,407 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+var (
+	ErrEmailSuppressed           = errors.New("email address is suppressed after repeated bounces")
+	ErrInvalidBounceMessage      = errors.New("invalid bounce message")
+	ErrNotBounceReport           = errors.New("message is not a delivery status notification")
+	ErrInvalidBounceWebhookToken = errors.New("invalid bounce webhook token")
+	ErrEmailSuppressionNotFound  = errors.New("email address is not suppressed")
+)
+
+// defaultHardBounceThreshold is how many hard bounces suppress an address when
+// no threshold is configured
+const defaultHardBounceThreshold = 3
+
+// EmailBounceQuerier defines the database operations bounce processing needs
+type EmailBounceQuerier interface {
+	GetEmailDelivery(ctx context.Context, id int32) (queries.EmailDelivery, error)
+	GetEmailDeliveryByProviderMessageID(ctx context.Context, providerMessageID pgtype.Text) (queries.EmailDelivery, error)
+	MarkEmailDeliveryBounced(ctx context.Context, arg queries.MarkEmailDeliveryBouncedParams) (queries.EmailDelivery, error)
+	RecordEmailHardBounce(ctx context.Context, arg queries.RecordEmailHardBounceParams) (queries.EmailSuppression, error)
+	IsEmailSuppressed(ctx context.Context, lower string) (bool, error)
+	ClearEmailSuppression(ctx context.Context, lower string) (int64, error)
+	ListStudentsWithSuppressedEmail(ctx context.Context, arg queries.ListStudentsWithSuppressedEmailParams) ([]queries.ListStudentsWithSuppressedEmailRow, error)
+	CountStudentsWithSuppressedEmail(ctx context.Context) (int64, error)
+	GetStudentByID(ctx context.Context, id int32) (queries.Student, error)
+}
+
+// EmailBounceServiceInterface defines the interface for bounce processing
+type EmailBounceServiceInterface interface {
+	HandleWebhook(ctx context.Context, token string, message io.Reader) (*models.EmailBounceResult, error)
+	ProcessMaildir(ctx context.Context) error
+	IsSuppressed(ctx context.Context, email string) (bool, error)
+	ListStudentEmailIssues(ctx context.Context, page, limit int) (*models.StudentEmailIssueListResponse, error)
+	ClearStudentSuppression(ctx context.Context, studentID int32) error
+}
+
+// EmailBounceService reads bounce reports, marks the deliveries they are about
+// as bounced and suppresses addresses that keep hard bouncing
+type EmailBounceService struct {
+	querier EmailBounceQuerier
+	config  *models.EmailBounceConfig
+	logger  *slog.Logger
+}
+
+// NewEmailBounceService creates a new bounce service
+func NewEmailBounceService(querier EmailBounceQuerier, config *models.EmailBounceConfig, logger *slog.Logger) *EmailBounceService {
+	if config.HardBounceThreshold <= 0 {
+		config.HardBounceThreshold = defaultHardBounceThreshold
+	}
+	return &EmailBounceService{
+		querier: querier,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// HandleWebhook processes a raw bounce message posted to the bounce webhook
+func (s *EmailBounceService) HandleWebhook(ctx context.Context, token string, message io.Reader) (*models.EmailBounceResult, error) {
+	if s.config.WebhookToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.config.WebhookToken)) != 1 {
+		return nil, ErrInvalidBounceWebhookToken
+	}
+	return s.ProcessBounce(ctx, message)
+}
+
+// ProcessBounce reads a raw bounce message and acts on each recipient it
+// reports as failed. Each is matched to its email delivery by the delivery ID
+// in the VERP address the bounce was sent to, or by the Message-ID of the
+// bounced email, and the delivery is marked bounced. A hard bounce counts
+// against the address, which is suppressed once it reaches the threshold.
+// Bounces that match no delivery, or name a recipient the matched email was
+// not sent to, are ignored, so a forged or stray report cannot suppress an
+// address.
+func (s *EmailBounceService) ProcessBounce(ctx context.Context, message io.Reader) (*models.EmailBounceResult, error) {
+	report, err := parseBounce(message)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.EmailBounceResult{Bounces: []models.EmailBounce{}}
+	seen := make(map[int32]bool)
+	for _, recipient := range report.Recipients {
+		bounce := models.EmailBounce{
+			EmailAddress: recipient.Address,
+			Type:         models.BounceTypeSoft,
+			Status:       recipient.Status,
+			Diagnostic:   recipient.Diagnostic,
+		}
+		if recipient.hard() {
+			bounce.Type = models.BounceTypeHard
+		}
+
+		delivery, err := s.matchDelivery(ctx, report, recipient)
+		if err != nil {
+			return nil, err
+		}
+		if delivery == nil {
+			s.logger.Info("Ignoring bounce that matches no email delivery",
+				"email", recipient.Address,
+				"status", recipient.Status)
+			result.Bounces = append(result.Bounces, bounce)
+			continue
+		}
+
+		bounce.DeliveryID = &delivery.ID
+		bounce.EmailAddress = delivery.EmailAddress
+		// A report is only acted on once, however often it is received
+		if !seen[delivery.ID] && delivery.Status != string(models.EmailDeliveryStatusBounced) {
+			if err := s.recordBounce(ctx, delivery, recipient); err != nil {
+				return nil, err
+			}
+		}
+		seen[delivery.ID] = true
+
+		suppressed, err := s.IsSuppressed(ctx, delivery.EmailAddress)
+		if err != nil {
+			return nil, err
+		}
+		bounce.Suppressed = suppressed
+		result.Bounces = append(result.Bounces, bounce)
+	}
+
+	return result, nil
+}
+
+// matchDelivery finds the email delivery a failed recipient is about, or nil
+// if there is none or it was sent to another address
+func (s *EmailBounceService) matchDelivery(ctx context.Context, report *bounceReport, recipient bounceRecipient) (*queries.EmailDelivery, error) {
+	delivery, err := s.findDelivery(ctx, report)
+	if err != nil || delivery == nil {
+		return nil, err
+	}
+	if !recipient.isFor(delivery.EmailAddress) {
+		s.logger.Warn("Ignoring bounce for an address the email was not sent to",
+			"delivery_id", delivery.ID,
+			"email", recipient.Address)
+		return nil, nil
+	}
+	return delivery, nil
+}
+
+// findDelivery finds the email delivery a report is about by the VERP address
+// it was sent to or the Message-ID of the bounced email, or nil if there is none
+func (s *EmailBounceService) findDelivery(ctx context.Context, report *bounceReport) (*queries.EmailDelivery, error) {
+	if s.config.BounceAddress != "" {
+		for _, to := range report.To {
+			id, ok := parseVERPAddress(s.config.BounceAddress, s.config.BounceSecret, to)
+			if !ok {
+				continue
+			}
+			delivery, err := s.querier.GetEmailDelivery(ctx, id)
+			if err == nil {
+				return &delivery, nil
+			}
+			if err != sql.ErrNoRows && err != pgx.ErrNoRows {
+				return nil, fmt.Errorf("failed to get email delivery: %w", err)
+			}
+		}
+	}
+
+	if report.OriginalMessageID != "" {
+		delivery, err := s.querier.GetEmailDeliveryByProviderMessageID(ctx, pgtype.Text{String: report.OriginalMessageID, Valid: true})
+		if err == nil {
+			return &delivery, nil
+		}
+		if err != sql.ErrNoRows && err != pgx.ErrNoRows {
+			return nil, fmt.Errorf("failed to get email delivery: %w", err)
+		}
+	}
+
+	return nil, nil
+}
+
+// recordBounce marks a delivery bounced and counts a hard bounce against its
+// address
+func (s *EmailBounceService) recordBounce(ctx context.Context, delivery *queries.EmailDelivery, recipient bounceRecipient) error {
+	reason := recipient.Diagnostic
+	if reason == "" {
+		reason = fmt.Sprintf("bounced with status %s", recipient.Status)
+	}
+
+	// Record the bounce even if ctx ends part way, or it would be half counted
+	recordCtx := context.WithoutCancel(ctx)
+	if _, err := s.querier.MarkEmailDeliveryBounced(recordCtx, queries.MarkEmailDeliveryBouncedParams{
+		ID:           delivery.ID,
+		ErrorMessage: pgtype.Text{String: reason, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("failed to mark email delivery bounced: %w", err)
+	}
+
+	if !recipient.hard() {
+		s.logger.Info("Email soft bounced", "delivery_id", delivery.ID, "status", recipient.Status)
+		return nil
+	}
+
+	suppression, err := s.querier.RecordEmailHardBounce(recordCtx, queries.RecordEmailHardBounceParams{
+		Lower:            delivery.EmailAddress,
+		LastBounceStatus: pgtype.Text{String: recipient.Status, Valid: recipient.Status != ""},
+		LastBounceReason: pgtype.Text{String: recipient.Diagnostic, Valid: recipient.Diagnostic != ""},
+		Column_4:         int32(s.config.HardBounceThreshold),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record hard bounce: %w", err)
+	}
+
+	s.logger.Info("Email hard bounced",
+		"delivery_id", delivery.ID,
+		"status", recipient.Status,
+		"hard_bounces", suppression.HardBounceCount)
+	if suppression.SuppressedAt.Valid && suppression.HardBounceCount == int32(s.config.HardBounceThreshold) {
+		s.logger.Warn("Email address suppressed after repeated hard bounces",
+			"email", delivery.EmailAddress,
+			"hard_bounces", suppression.HardBounceCount)
+	}
+	return nil
+}
+
+// ProcessMaildir processes the bounce messages delivered to the bounce maildir
+// since it was last polled. Each message is moved from new to cur once it has
+// been dealt with. One that could not be read as a bounce is moved too, but one
+// that failed for a reason worth retrying is left for the next poll.
+func (s *EmailBounceService) ProcessMaildir(ctx context.Context) error {
+	if s.config.Maildir == "" {
+		// Bounces only arrive through the webhook
+		return nil
+	}
+
+	newDir := filepath.Join(s.config.Maildir, "new")
+	entries, err := os.ReadDir(newDir)
+	if err != nil {
+		return fmt.Errorf("failed to read bounce maildir: %w", err)
+	}
+
+	var processed, failed int
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(newDir, entry.Name())
+		if err := s.processMaildirMessage(ctx, path); err != nil {
+			if !isPermanentBounceError(err) {
+				s.logger.Error("Failed to process bounce message", "file", entry.Name(), "error", err)
+				failed++
+				continue
+			}
+			s.logger.Warn("Skipping unreadable bounce message", "file", entry.Name(), "error", err)
+		}
+
+		// Maildir readers mark a message seen by moving it to cur with an S flag
+		if err := os.Rename(path, filepath.Join(s.config.Maildir, "cur", entry.Name()+":2,S")); err != nil {
+			s.logger.Error("Failed to move processed bounce message", "file", entry.Name(), "error", err)
+			failed++
+			continue
+		}
+		processed++
+	}
+
+	if processed > 0 {
+		s.logger.Info("Processed bounce messages", "processed", processed, "failed", failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d bounce messages could not be processed", failed, processed+failed)
+	}
+	return nil
+}
+
+// processMaildirMessage processes one message file from the bounce maildir
+func (s *EmailBounceService) processMaildirMessage(ctx context.Context, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open bounce message: %w", err)
+	}
+	defer file.Close()
+
+	_, err = s.ProcessBounce(ctx, file)
+	return err
+}
+
+// IsSuppressed reports whether email to an address is suppressed
+func (s *EmailBounceService) IsSuppressed(ctx context.Context, email string) (bool, error) {
+	suppressed, err := s.querier.IsEmailSuppressed(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return false, fmt.Errorf("failed to check email suppression: %w", err)
+	}
+	return suppressed, nil
+}
+
+// ListStudentEmailIssues lists students whose email address on file has been
+// suppressed after bouncing, most recently suppressed first. A student drops off
+// the list once their address is corrected.
+func (s *EmailBounceService) ListStudentEmailIssues(ctx context.Context, page, limit int) (*models.StudentEmailIssueListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	rows, err := s.querier.ListStudentsWithSuppressedEmail(ctx, queries.ListStudentsWithSuppressedEmailParams{
+		Limit:  int32(limit),
+		Offset: int32((page - 1) * limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list students with suppressed email: %w", err)
+	}
+	total, err := s.querier.CountStudentsWithSuppressedEmail(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count students with suppressed email: %w", err)
+	}
+
+	students := make([]models.StudentEmailIssue, 0, len(rows))
+	for _, row := range rows {
+		students = append(students, convertToStudentEmailIssue(row))
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit != 0 {
+		totalPages++
+	}
+
+	return &models.StudentEmailIssueListResponse{
+		Students: students,
+		Pagination: models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
+
+// ClearStudentSuppression lets email go to a student's address again, for when
+// the address turns out to be working without being changed. Its bounce count
+// starts again from nothing.
+func (s *EmailBounceService) ClearStudentSuppression(ctx context.Context, studentID int32) error {
+	student, err := s.querier.GetStudentByID(ctx, studentID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return fmt.Errorf("student not found")
+		}
+		return fmt.Errorf("failed to get student: %w", err)
+	}
+	if !student.Email.Valid || student.Email.String == "" {
+		return fmt.Errorf("%w: student has no email address", ErrEmailSuppressionNotFound)
+	}
+
+	cleared, err := s.querier.ClearEmailSuppression(ctx, student.Email.String)
+	if err != nil {
+		return fmt.Errorf("failed to clear email suppression: %w", err)
+	}
+	if cleared == 0 {
+		return ErrEmailSuppressionNotFound
+	}
+
+	s.logger.Info("Email suppression cleared", "student_id", studentID, "email", student.Email.String)
+	return nil
+}
+
+func convertToStudentEmailIssue(row queries.ListStudentsWithSuppressedEmailRow) models.StudentEmailIssue {
+	issue := models.StudentEmailIssue{
+		ID:              row.ID,
+		StudentID:       row.StudentID,
+		FirstName:       row.FirstName,
+		LastName:        row.LastName,
+		Email:           row.Email.String,
+		YearOfStudy:     row.YearOfStudy,
+		HardBounceCount: row.HardBounceCount,
+		SuppressedAt:    row.SuppressedAt.Time,
+	}
+	if row.Phone.Valid {
+		issue.Phone = &row.Phone.String
+	}
+	if row.Department.Valid {
+		issue.Department = &row.Department.String
+	}
+	if row.LastBounceStatus.Valid {
+		issue.LastBounceStatus = &row.LastBounceStatus.String
+	}
+	if row.LastBounceReason.Valid {
+		issue.LastBounceReason = &row.LastBounceReason.String
+	}
+	if row.LastBouncedAt.Valid {
+		issue.LastBouncedAt = &row.LastBouncedAt.Time
+	}
+	return issue
+}
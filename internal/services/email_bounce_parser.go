@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// bounceReport is what a delivery status notification (RFC 3464) says about a
+// message that could not be delivered
+type bounceReport struct {
+	// To are the addresses the report was delivered to. A VERP return path that
+	// identifies the bounced email shows up here.
+	To []string
+	// OriginalMessageID is the Message-ID of the bounced message, without angle
+	// brackets
+	OriginalMessageID string
+	// Recipients are the recipients the message failed for; delayed and
+	// delivered recipients are left out
+	Recipients []bounceRecipient
+}
+
+// bounceRecipient is one failed recipient of a delivery status notification
+type bounceRecipient struct {
+	Address string
+	// OriginalAddress is the address the message was sent to, when the report
+	// gives it
+	OriginalAddress string
+	Status          string
+	Diagnostic      string
+}
+
+// isFor reports whether the recipient is the address an email was sent to. A
+// server that forwards mail may report the address it forwarded to as the
+// final recipient, so the original recipient is checked too.
+func (r bounceRecipient) isFor(address string) bool {
+	return strings.EqualFold(r.Address, address) ||
+		(r.OriginalAddress != "" && strings.EqualFold(r.OriginalAddress, address))
+}
+
+// hard reports whether the failure is permanent, which a 5.x.x status says. A
+// server giving up on a 4.x.x temporary failure is a soft bounce.
+func (r bounceRecipient) hard() bool {
+	return strings.HasPrefix(r.Status, "5.")
+}
+
+// parseBounce reads a raw bounce message. Only delivery status notifications
+// are understood; anything else is reported as ErrNotBounceReport.
+func parseBounce(r io.Reader) (*bounceReport, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBounceMessage, err)
+	}
+
+	report := &bounceReport{}
+	for _, field := range []string{"X-Original-To", "Delivered-To", "Envelope-To", "To"} {
+		for _, value := range msg.Header[field] {
+			report.To = append(report.To, headerAddresses(value)...)
+		}
+	}
+
+	found, err := report.readPart(msg.Header.Get("Content-Type"), msg.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrNotBounceReport
+	}
+	return report, nil
+}
+
+// readPart reads one MIME part of the message, descending into multiparts.
+// It reports whether a delivery-status part was found.
+func (b *bounceReport) readPart(contentType string, body io.Reader) (bool, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// A part without a usable content type is plain text
+		return false, nil
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		if params["boundary"] == "" {
+			return false, fmt.Errorf("%w: multipart without a boundary", ErrInvalidBounceMessage)
+		}
+		found := false
+		parts := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := parts.NextPart()
+			if err == io.EOF {
+				return found, nil
+			}
+			if err != nil {
+				return found, fmt.Errorf("%w: %v", ErrInvalidBounceMessage, err)
+			}
+			partFound, err := b.readPart(part.Header.Get("Content-Type"), part)
+			if err != nil {
+				return found, err
+			}
+			found = found || partFound
+		}
+
+	case mediaType == "message/delivery-status" || mediaType == "message/global-delivery-status":
+		return true, b.readDeliveryStatus(body)
+
+	case mediaType == "text/rfc822-headers" || mediaType == "message/rfc822" ||
+		mediaType == "message/rfc822-headers" || mediaType == "message/global-headers":
+		if original, err := mail.ReadMessage(body); err == nil {
+			b.OriginalMessageID = strings.Trim(strings.TrimSpace(original.Header.Get("Message-Id")), "<>")
+		}
+	}
+	return false, nil
+}
+
+// readDeliveryStatus reads the per-recipient fields of a delivery-status part.
+// The first block of fields is about the message and is skipped.
+func (b *bounceReport) readDeliveryStatus(body io.Reader) error {
+	fields := textproto.NewReader(bufio.NewReader(body))
+	for block := 0; ; block++ {
+		header, err := fields.ReadMIMEHeader()
+		if len(header) > 0 && block > 0 {
+			b.addRecipient(header)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBounceMessage, err)
+		}
+	}
+}
+
+// addRecipient keeps a recipient whose delivery failed
+func (b *bounceReport) addRecipient(header textproto.MIMEHeader) {
+	if !strings.EqualFold(strings.TrimSpace(header.Get("Action")), "failed") {
+		return
+	}
+
+	original := strings.Trim(typedValue(header.Get("Original-Recipient")), "<>")
+	address := strings.Trim(typedValue(header.Get("Final-Recipient")), "<>")
+	if address == "" {
+		address = original
+	}
+	if address == "" {
+		return
+	}
+
+	status := strings.Fields(header.Get("Status"))
+	recipient := bounceRecipient{
+		Address:         address,
+		OriginalAddress: original,
+		Diagnostic:      typedValue(header.Get("Diagnostic-Code")),
+	}
+	if len(status) > 0 {
+		recipient.Status = status[0]
+	}
+	b.Recipients = append(b.Recipients, recipient)
+}
+
+// typedValue strips the type from a DSN field value such as "rfc822; a@b.c"
+func typedValue(value string) string {
+	if semi := strings.Index(value, ";"); semi >= 0 {
+		value = value[semi+1:]
+	}
+	return strings.TrimSpace(value)
+}
+
+// headerAddresses reads the addresses from an address header, falling back to
+// the bare value when it is not a valid address list
+func headerAddresses(value string) []string {
+	list, err := mail.ParseAddressList(value)
+	if err != nil {
+		value = strings.Trim(strings.TrimSpace(value), "<>")
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	}
+	addresses := make([]string, 0, len(list))
+	for _, address := range list {
+		addresses = append(addresses, address.Address)
+	}
+	return addresses
+}
+
+// verpSignatureBytes is how much of the HMAC a VERP return path carries
+const verpSignatureBytes = 10
+
+// verpAddress is the return path for a delivery: the bounce address with the
+// delivery ID and its signature after a plus, so bounces+42-<signature>@example.com
+// for delivery 42. The signature stops anyone who has seen one return path
+// writing bounce reports for other deliveries.
+func verpAddress(bounceAddress, secret string, deliveryID int32) string {
+	at := strings.LastIndex(bounceAddress, "@")
+	if at < 0 {
+		return bounceAddress
+	}
+	return fmt.Sprintf("%s+%d-%s%s", bounceAddress[:at], deliveryID, verpSignature(secret, deliveryID), bounceAddress[at:])
+}
+
+// verpSignature signs a delivery ID. It is hex so that mail servers changing
+// the case of the address do not break it.
+func verpSignature(secret string, deliveryID int32) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.Itoa(int(deliveryID))))
+	return hex.EncodeToString(mac.Sum(nil)[:verpSignatureBytes])
+}
+
+// parseVERPAddress returns the delivery ID a VERP return path made from
+// bounceAddress carries, if it is signed with secret
+func parseVERPAddress(bounceAddress, secret, address string) (int32, bool) {
+	if secret == "" {
+		return 0, false
+	}
+	at := strings.LastIndex(bounceAddress, "@")
+	addressAt := strings.LastIndex(address, "@")
+	if at < 0 || addressAt < 0 || !strings.EqualFold(address[addressAt:], bounceAddress[at:]) {
+		return 0, false
+	}
+
+	prefix := bounceAddress[:at] + "+"
+	local := address[:addressAt]
+	if len(local) <= len(prefix) || !strings.EqualFold(local[:len(prefix)], prefix) {
+		return 0, false
+	}
+	idText, signature, found := strings.Cut(local[len(prefix):], "-")
+	if !found {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(idText, 10, 32)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(verpSignature(secret, int32(id)))) {
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// isPermanentBounceError reports whether a bounce message failed in a way that
+// processing it again would not fix
+func isPermanentBounceError(err error) bool {
+	return errors.Is(err, ErrInvalidBounceMessage) || errors.Is(err, ErrNotBounceReport)
+}
@@ -0,0 +1,470 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockEmailBounceQuerier is a mock implementation of EmailBounceQuerier
+type MockEmailBounceQuerier struct {
+	mock.Mock
+}
+
+func (m *MockEmailBounceQuerier) GetEmailDelivery(ctx context.Context, id int32) (queries.EmailDelivery, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.EmailDelivery), args.Error(1)
+}
+
+func (m *MockEmailBounceQuerier) GetEmailDeliveryByProviderMessageID(ctx context.Context, providerMessageID pgtype.Text) (queries.EmailDelivery, error) {
+	args := m.Called(ctx, providerMessageID)
+	return args.Get(0).(queries.EmailDelivery), args.Error(1)
+}
+
+func (m *MockEmailBounceQuerier) MarkEmailDeliveryBounced(ctx context.Context, arg queries.MarkEmailDeliveryBouncedParams) (queries.EmailDelivery, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.EmailDelivery), args.Error(1)
+}
+
+func (m *MockEmailBounceQuerier) RecordEmailHardBounce(ctx context.Context, arg queries.RecordEmailHardBounceParams) (queries.EmailSuppression, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.EmailSuppression), args.Error(1)
+}
+
+func (m *MockEmailBounceQuerier) IsEmailSuppressed(ctx context.Context, lower string) (bool, error) {
+	args := m.Called(ctx, lower)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockEmailBounceQuerier) ClearEmailSuppression(ctx context.Context, lower string) (int64, error) {
+	args := m.Called(ctx, lower)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockEmailBounceQuerier) ListStudentsWithSuppressedEmail(ctx context.Context, arg queries.ListStudentsWithSuppressedEmailParams) ([]queries.ListStudentsWithSuppressedEmailRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]queries.ListStudentsWithSuppressedEmailRow), args.Error(1)
+}
+
+func (m *MockEmailBounceQuerier) CountStudentsWithSuppressedEmail(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockEmailBounceQuerier) GetStudentByID(ctx context.Context, id int32) (queries.Student, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Student), args.Error(1)
+}
+
+// sampleBounce is a delivery status notification as Postfix writes it. The
+// DSN Status and Action can be replaced to try other outcomes.
+func sampleBounce(to, status, action string) string {
+	return strings.Join([]string{
+		"Return-Path: <>",
+		"Delivered-To: " + to,
+		"From: MAILER-DAEMON@mail.library.example.com (Mail Delivery System)",
+		"To: " + to,
+		"Subject: Undelivered Mail Returned to Sender",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/report; report-type=delivery-status; boundary="BOUNDARY"`,
+		"",
+		"--BOUNDARY",
+		"Content-Type: text/plain; charset=us-ascii",
+		"",
+		"I'm sorry to have to inform you that your message could not",
+		"be delivered to one or more recipients.",
+		"",
+		"--BOUNDARY",
+		"Content-Type: message/delivery-status",
+		"",
+		"Reporting-MTA: dns; mail.library.example.com",
+		"Arrival-Date: Mon, 12 Oct 2026 08:00:00 +0300",
+		"",
+		"Final-Recipient: rfc822; john.doe@student.example.com",
+		"Original-Recipient: rfc822;john.doe@student.example.com",
+		"Action: " + action,
+		"Status: " + status,
+		"Diagnostic-Code: smtp; 550 5.1.1 <john.doe@student.example.com>: Recipient",
+		"    address rejected: User unknown",
+		"",
+		"--BOUNDARY",
+		"Content-Type: text/rfc822-headers",
+		"",
+		"From: Library Management System <library@library.example.com>",
+		"To: john.doe@student.example.com",
+		"Subject: Overdue book",
+		"Message-ID: <1760245200.abc123@library.example.com>",
+		"",
+		"--BOUNDARY--",
+		"",
+	}, "\r\n")
+}
+
+func TestParseBounce(t *testing.T) {
+	t.Run("reads a delivery status notification", func(t *testing.T) {
+		report, err := parseBounce(strings.NewReader(sampleBounce("bounces+42@library.example.com", "5.1.1", "failed")))
+
+		require.NoError(t, err)
+		assert.Contains(t, report.To, "bounces+42@library.example.com")
+		assert.Equal(t, "1760245200.abc123@library.example.com", report.OriginalMessageID)
+		require.Len(t, report.Recipients, 1)
+		assert.Equal(t, "john.doe@student.example.com", report.Recipients[0].Address)
+		assert.Equal(t, "john.doe@student.example.com", report.Recipients[0].OriginalAddress)
+		assert.Equal(t, "5.1.1", report.Recipients[0].Status)
+		assert.Equal(t, "550 5.1.1 <john.doe@student.example.com>: Recipient address rejected: User unknown", report.Recipients[0].Diagnostic)
+		assert.True(t, report.Recipients[0].hard())
+	})
+
+	t.Run("temporary failure is a soft bounce", func(t *testing.T) {
+		report, err := parseBounce(strings.NewReader(sampleBounce("bounces@library.example.com", "4.2.2", "failed")))
+
+		require.NoError(t, err)
+		require.Len(t, report.Recipients, 1)
+		assert.False(t, report.Recipients[0].hard())
+	})
+
+	t.Run("delayed recipients are left out", func(t *testing.T) {
+		report, err := parseBounce(strings.NewReader(sampleBounce("bounces@library.example.com", "4.4.1", "delayed")))
+
+		require.NoError(t, err)
+		assert.Empty(t, report.Recipients)
+	})
+
+	t.Run("report forwarded inside another message", func(t *testing.T) {
+		forwarded := strings.Join([]string{
+			"To: bounces+7@library.example.com",
+			`Content-Type: multipart/mixed; boundary="OUTER"`,
+			"",
+			"--OUTER",
+			"Content-Type: text/plain",
+			"",
+			"Forwarded bounce",
+			"--OUTER",
+			`Content-Type: multipart/report; report-type=delivery-status; boundary="BOUNDARY"`,
+			"",
+			strings.SplitN(sampleBounce("", "5.1.1", "failed"), "\r\n\r\n", 2)[1],
+			"--OUTER--",
+			"",
+		}, "\r\n")
+
+		report, err := parseBounce(strings.NewReader(forwarded))
+
+		require.NoError(t, err)
+		assert.Contains(t, report.To, "bounces+7@library.example.com")
+		assert.Len(t, report.Recipients, 1)
+	})
+
+	t.Run("plain message is not a bounce", func(t *testing.T) {
+		_, err := parseBounce(strings.NewReader("From: someone@example.com\r\nSubject: Hello\r\n\r\nHi there\r\n"))
+
+		assert.ErrorIs(t, err, ErrNotBounceReport)
+	})
+
+	t.Run("unreadable message", func(t *testing.T) {
+		_, err := parseBounce(strings.NewReader(""))
+
+		assert.ErrorIs(t, err, ErrInvalidBounceMessage)
+	})
+}
+
+func TestBounceRecipient_IsFor(t *testing.T) {
+	forwarded := bounceRecipient{Address: "jdoe@mail.example.com", OriginalAddress: "John.Doe@student.example.com"}
+
+	assert.True(t, forwarded.isFor("jdoe@mail.example.com"))
+	assert.True(t, forwarded.isFor("john.doe@student.example.com"))
+	assert.False(t, forwarded.isFor("jane.roe@student.example.com"))
+	assert.False(t, bounceRecipient{Address: "jdoe@mail.example.com"}.isFor(""))
+}
+
+func TestVERPAddress(t *testing.T) {
+	address := verpAddress("bounces@library.example.com", "bounce-secret", 42)
+	assert.Regexp(t, `^bounces\+42-[0-9a-f]{20}@library\.example\.com$`, address)
+
+	id, ok := parseVERPAddress("bounces@library.example.com", "bounce-secret", strings.ToUpper(address))
+	assert.True(t, ok)
+	assert.Equal(t, int32(42), id)
+
+	_, ok = parseVERPAddress("bounces@library.example.com", "", address)
+	assert.False(t, ok, "no secret")
+	_, ok = parseVERPAddress("bounces@library.example.com", "other-secret", address)
+	assert.False(t, ok, "other secret")
+
+	signature := verpSignature("bounce-secret", 42)
+	for _, address := range []string{
+		"bounces@library.example.com",
+		"bounces+42@library.example.com",
+		"bounces+43-" + signature + "@library.example.com",
+		"bounces+abc-" + signature + "@library.example.com",
+		"bounces+42-" + signature + "@other.example.com",
+		"other+42-" + signature + "@library.example.com",
+	} {
+		_, ok := parseVERPAddress("bounces@library.example.com", "bounce-secret", address)
+		assert.False(t, ok, address)
+	}
+}
+
+func TestEmailService_ReturnPath(t *testing.T) {
+	config := &models.EmailConfig{FromEmail: "library@library.example.com"}
+	service := &EmailService{config: config}
+
+	assert.Equal(t, "library@library.example.com", service.returnPath(&EmailTracking{DeliveryID: 42}))
+
+	config.BounceAddress = "bounces@library.example.com"
+	assert.Equal(t, "bounces@library.example.com", service.returnPath(&EmailTracking{DeliveryID: 42}))
+
+	config.BounceSecret = "bounce-secret"
+	assert.Equal(t, testVERPAddress(42), service.returnPath(&EmailTracking{DeliveryID: 42}))
+	assert.Equal(t, "bounces@library.example.com", service.returnPath(nil))
+
+	messageID, err := service.newMessageID()
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(messageID, "@library.example.com"), messageID)
+}
+
+// testVERPAddress is the return path of a delivery sent by newTestBounceService's configuration
+func testVERPAddress(deliveryID int32) string {
+	return verpAddress("bounces@library.example.com", "bounce-secret", deliveryID)
+}
+
+func newTestBounceService() (*EmailBounceService, *MockEmailBounceQuerier) {
+	querier := &MockEmailBounceQuerier{}
+	service := NewEmailBounceService(querier, &models.EmailBounceConfig{
+		BounceAddress: "bounces@library.example.com",
+		BounceSecret:  "bounce-secret",
+		WebhookToken:  "secret",
+	}, slog.Default())
+	return service, querier
+}
+
+func TestEmailBounceService_ProcessBounce(t *testing.T) {
+	ctx := context.Background()
+	delivery := queries.EmailDelivery{ID: 42, EmailAddress: "John.Doe@student.example.com", Status: "sent"}
+
+	t.Run("hard bounce matched by VERP address is counted", func(t *testing.T) {
+		service, querier := newTestBounceService()
+
+		querier.On("GetEmailDelivery", ctx, int32(42)).Return(delivery, nil)
+		querier.On("MarkEmailDeliveryBounced", mock.Anything, queries.MarkEmailDeliveryBouncedParams{
+			ID:           42,
+			ErrorMessage: pgtype.Text{String: "550 5.1.1 <john.doe@student.example.com>: Recipient address rejected: User unknown", Valid: true},
+		}).Return(delivery, nil)
+		querier.On("RecordEmailHardBounce", mock.Anything, queries.RecordEmailHardBounceParams{
+			Lower:            "John.Doe@student.example.com",
+			LastBounceStatus: pgtype.Text{String: "5.1.1", Valid: true},
+			LastBounceReason: pgtype.Text{String: "550 5.1.1 <john.doe@student.example.com>: Recipient address rejected: User unknown", Valid: true},
+			Column_4:         3,
+		}).Return(queries.EmailSuppression{HardBounceCount: 3, SuppressedAt: pgtype.Timestamp{Time: time.Now(), Valid: true}}, nil)
+		querier.On("IsEmailSuppressed", ctx, "John.Doe@student.example.com").Return(true, nil)
+
+		result, err := service.ProcessBounce(ctx, strings.NewReader(sampleBounce(testVERPAddress(42), "5.1.1", "failed")))
+
+		require.NoError(t, err)
+		require.Len(t, result.Bounces, 1)
+		assert.Equal(t, models.BounceTypeHard, result.Bounces[0].Type)
+		assert.Equal(t, int32(42), *result.Bounces[0].DeliveryID)
+		assert.True(t, result.Bounces[0].Suppressed)
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("matched by Message-ID without a VERP address", func(t *testing.T) {
+		service, querier := newTestBounceService()
+
+		querier.On("GetEmailDeliveryByProviderMessageID", ctx, pgtype.Text{String: "1760245200.abc123@library.example.com", Valid: true}).Return(delivery, nil)
+		querier.On("MarkEmailDeliveryBounced", mock.Anything, mock.Anything).Return(delivery, nil)
+		querier.On("RecordEmailHardBounce", mock.Anything, mock.Anything).Return(queries.EmailSuppression{HardBounceCount: 1}, nil)
+		querier.On("IsEmailSuppressed", ctx, "John.Doe@student.example.com").Return(false, nil)
+
+		result, err := service.ProcessBounce(ctx, strings.NewReader(sampleBounce("bounces@library.example.com", "5.1.1", "failed")))
+
+		require.NoError(t, err)
+		require.Len(t, result.Bounces, 1)
+		assert.Equal(t, int32(42), *result.Bounces[0].DeliveryID)
+		assert.False(t, result.Bounces[0].Suppressed)
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("soft bounce marks the delivery without counting", func(t *testing.T) {
+		service, querier := newTestBounceService()
+
+		querier.On("GetEmailDelivery", ctx, int32(42)).Return(delivery, nil)
+		querier.On("MarkEmailDeliveryBounced", mock.Anything, mock.Anything).Return(delivery, nil)
+		querier.On("IsEmailSuppressed", ctx, "John.Doe@student.example.com").Return(false, nil)
+
+		result, err := service.ProcessBounce(ctx, strings.NewReader(sampleBounce(testVERPAddress(42), "4.2.2", "failed")))
+
+		require.NoError(t, err)
+		assert.Equal(t, models.BounceTypeSoft, result.Bounces[0].Type)
+		querier.AssertNotCalled(t, "RecordEmailHardBounce", mock.Anything, mock.Anything)
+	})
+
+	t.Run("bounce received again is not counted twice", func(t *testing.T) {
+		service, querier := newTestBounceService()
+		bounced := delivery
+		bounced.Status = string(models.EmailDeliveryStatusBounced)
+
+		querier.On("GetEmailDelivery", ctx, int32(42)).Return(bounced, nil)
+		querier.On("IsEmailSuppressed", ctx, "John.Doe@student.example.com").Return(false, nil)
+
+		_, err := service.ProcessBounce(ctx, strings.NewReader(sampleBounce(testVERPAddress(42), "5.1.1", "failed")))
+
+		require.NoError(t, err)
+		querier.AssertNotCalled(t, "MarkEmailDeliveryBounced", mock.Anything, mock.Anything)
+		querier.AssertNotCalled(t, "RecordEmailHardBounce", mock.Anything, mock.Anything)
+	})
+
+	t.Run("bounce matching no delivery is ignored", func(t *testing.T) {
+		service, querier := newTestBounceService()
+
+		querier.On("GetEmailDelivery", ctx, int32(42)).Return(queries.EmailDelivery{}, pgx.ErrNoRows)
+		querier.On("GetEmailDeliveryByProviderMessageID", ctx, mock.Anything).Return(queries.EmailDelivery{}, pgx.ErrNoRows)
+
+		result, err := service.ProcessBounce(ctx, strings.NewReader(sampleBounce(testVERPAddress(42), "5.1.1", "failed")))
+
+		require.NoError(t, err)
+		require.Len(t, result.Bounces, 1)
+		assert.Nil(t, result.Bounces[0].DeliveryID)
+		querier.AssertNotCalled(t, "MarkEmailDeliveryBounced", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unsigned VERP address is not trusted", func(t *testing.T) {
+		service, querier := newTestBounceService()
+
+		querier.On("GetEmailDeliveryByProviderMessageID", ctx, mock.Anything).Return(queries.EmailDelivery{}, pgx.ErrNoRows)
+
+		result, err := service.ProcessBounce(ctx, strings.NewReader(sampleBounce("bounces+42@library.example.com", "5.1.1", "failed")))
+
+		require.NoError(t, err)
+		assert.Nil(t, result.Bounces[0].DeliveryID)
+		querier.AssertNotCalled(t, "GetEmailDelivery", mock.Anything, mock.Anything)
+		querier.AssertNotCalled(t, "MarkEmailDeliveryBounced", mock.Anything, mock.Anything)
+	})
+
+	t.Run("report about another address is ignored", func(t *testing.T) {
+		service, querier := newTestBounceService()
+		other := delivery
+		other.EmailAddress = "jane.roe@student.example.com"
+
+		querier.On("GetEmailDelivery", ctx, int32(42)).Return(other, nil)
+
+		result, err := service.ProcessBounce(ctx, strings.NewReader(sampleBounce(testVERPAddress(42), "5.1.1", "failed")))
+
+		require.NoError(t, err)
+		assert.Nil(t, result.Bounces[0].DeliveryID)
+		querier.AssertNotCalled(t, "MarkEmailDeliveryBounced", mock.Anything, mock.Anything)
+		querier.AssertNotCalled(t, "RecordEmailHardBounce", mock.Anything, mock.Anything)
+	})
+}
+
+func TestEmailBounceService_HandleWebhook(t *testing.T) {
+	service, _ := newTestBounceService()
+
+	_, err := service.HandleWebhook(context.Background(), "wrong", strings.NewReader(sampleBounce("bounces@library.example.com", "5.1.1", "failed")))
+
+	assert.ErrorIs(t, err, ErrInvalidBounceWebhookToken)
+}
+
+func TestEmailBounceService_ProcessMaildir(t *testing.T) {
+	ctx := context.Background()
+	maildir := t.TempDir()
+	for _, dir := range []string{"new", "cur", "tmp"} {
+		require.NoError(t, os.Mkdir(filepath.Join(maildir, dir), 0o755))
+	}
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(maildir, "new", name), []byte(content), 0o600))
+	}
+	write("1760245200.1.mail", sampleBounce(testVERPAddress(42), "5.1.1", "failed"))
+	write("1760245200.2.mail", "From: someone@example.com\r\nSubject: Hello\r\n\r\nHi there\r\n")
+	write("1760245200.3.mail", sampleBounce(testVERPAddress(43), "5.1.1", "failed"))
+
+	service, querier := newTestBounceService()
+	service.config.Maildir = maildir
+	delivery := queries.EmailDelivery{ID: 42, EmailAddress: "john.doe@student.example.com", Status: "bounced"}
+	querier.On("GetEmailDelivery", ctx, int32(42)).Return(delivery, nil)
+	querier.On("GetEmailDelivery", ctx, int32(43)).Return(queries.EmailDelivery{}, fmt.Errorf("connection refused"))
+	querier.On("IsEmailSuppressed", ctx, "john.doe@student.example.com").Return(false, nil)
+
+	err := service.ProcessMaildir(ctx)
+
+	// The message that failed on the database is left for the next poll; the
+	// bounce and the message that is not a bounce are moved to cur
+	assert.Error(t, err)
+	assert.FileExists(t, filepath.Join(maildir, "cur", "1760245200.1.mail:2,S"))
+	assert.FileExists(t, filepath.Join(maildir, "cur", "1760245200.2.mail:2,S"))
+	assert.FileExists(t, filepath.Join(maildir, "new", "1760245200.3.mail"))
+}
+
+func TestEmailBounceService_ListStudentEmailIssues(t *testing.T) {
+	ctx := context.Background()
+	service, querier := newTestBounceService()
+	suppressedAt := time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC)
+
+	querier.On("ListStudentsWithSuppressedEmail", ctx, queries.ListStudentsWithSuppressedEmailParams{Limit: 20, Offset: 20}).Return([]queries.ListStudentsWithSuppressedEmailRow{
+		{
+			ID:               5,
+			StudentID:        "STU2024005",
+			FirstName:        "John",
+			LastName:         "Doe",
+			Email:            pgtype.Text{String: "john.doe@student.example.com", Valid: true},
+			HardBounceCount:  3,
+			LastBounceStatus: pgtype.Text{String: "5.1.1", Valid: true},
+			SuppressedAt:     pgtype.Timestamp{Time: suppressedAt, Valid: true},
+		},
+	}, nil)
+	querier.On("CountStudentsWithSuppressedEmail", ctx).Return(int64(21), nil)
+
+	issues, err := service.ListStudentEmailIssues(ctx, 2, 20)
+
+	require.NoError(t, err)
+	require.Len(t, issues.Students, 1)
+	assert.Equal(t, "john.doe@student.example.com", issues.Students[0].Email)
+	assert.Equal(t, "5.1.1", *issues.Students[0].LastBounceStatus)
+	assert.Nil(t, issues.Students[0].LastBounceReason)
+	assert.Equal(t, suppressedAt, issues.Students[0].SuppressedAt)
+	assert.Equal(t, models.Pagination{Page: 2, Limit: 20, Total: 21, TotalPages: 2}, issues.Pagination)
+}
+
+func TestEmailBounceService_ClearStudentSuppression(t *testing.T) {
+	ctx := context.Background()
+	student := queries.Student{ID: 5, Email: pgtype.Text{String: "john.doe@student.example.com", Valid: true}}
+
+	t.Run("clears the student's address", func(t *testing.T) {
+		service, querier := newTestBounceService()
+		querier.On("GetStudentByID", ctx, int32(5)).Return(student, nil)
+		querier.On("ClearEmailSuppression", ctx, "john.doe@student.example.com").Return(int64(1), nil)
+
+		require.NoError(t, service.ClearStudentSuppression(ctx, 5))
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("address that is not suppressed", func(t *testing.T) {
+		service, querier := newTestBounceService()
+		querier.On("GetStudentByID", ctx, int32(5)).Return(student, nil)
+		querier.On("ClearEmailSuppression", ctx, "john.doe@student.example.com").Return(int64(0), nil)
+
+		assert.ErrorIs(t, service.ClearStudentSuppression(ctx, 5), ErrEmailSuppressionNotFound)
+	})
+
+	t.Run("student not found", func(t *testing.T) {
+		service, querier := newTestBounceService()
+		querier.On("GetStudentByID", ctx, int32(5)).Return(queries.Student{}, pgx.ErrNoRows)
+
+		err := service.ClearStudentSuppression(ctx, 5)
+
+		require.Error(t, err)
+		assert.Equal(t, "student not found", err.Error())
+	})
+}
@@ -23,6 +23,10 @@ func (m *mockEmailService) SendEmail(ctx context.Context, to, subject, body stri
 	return nil
 }
 
+func (m *mockEmailService) SendMessage(ctx context.Context, message *EmailMessage) error {
+	return nil
+}
+
 func (m *mockEmailService) SendTemplatedEmail(ctx context.Context, to string, template *models.EmailTemplate, data map[string]interface{}) error {
 	return nil
 }
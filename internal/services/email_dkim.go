@@ -0,0 +1,215 @@
+package services
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// dkimSignedHeaders are the headers a DKIM signature covers when an email has
+// them. Headers a relaying server may add or rewrite are left out.
+var dkimSignedHeaders = []string{
+	"From",
+	"To",
+	"Subject",
+	"Date",
+	"Message-ID",
+	"MIME-Version",
+	"Content-Type",
+	"Content-Transfer-Encoding",
+	"List-Unsubscribe",
+	"List-Unsubscribe-Post",
+}
+
+// dkimSigner signs emails with DKIM (RFC 6376) using rsa-sha256 and relaxed
+// canonicalization of both the headers and the body
+type dkimSigner struct {
+	domain   string
+	selector string
+	key      *rsa.PrivateKey
+}
+
+// loadDKIMSigner reads the PEM private key at keyPath, whose public key is
+// published in DNS at <selector>._domainkey.<domain>
+func loadDKIMSigner(domain, selector, keyPath string) (*dkimSigner, error) {
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DKIM key: %w", err)
+	}
+	return newDKIMSigner(domain, selector, keyPEM)
+}
+
+// newDKIMSigner creates a signer from an RSA private key in PKCS #1 or PKCS #8
+// PEM form
+func newDKIMSigner(domain, selector string, keyPEM []byte) (*dkimSigner, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("DKIM key is not PEM encoded")
+	}
+
+	var key *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DKIM key: %w", err)
+		}
+		key = parsed
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DKIM key: %w", err)
+		}
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("DKIM key must be an RSA key")
+		}
+		key = rsaKey
+	default:
+		return nil, fmt.Errorf("unsupported DKIM key type %q", block.Type)
+	}
+
+	return &dkimSigner{
+		domain:   domain,
+		selector: selector,
+		key:      key,
+	}, nil
+}
+
+// sign returns the DKIM-Signature header for a complete email, to go before its
+// other headers
+func (d *dkimSigner) sign(message []byte, now time.Time) (string, error) {
+	headerEnd := bytes.Index(message, []byte("\r\n\r\n"))
+	if headerEnd < 0 {
+		return "", errors.New("email has no body")
+	}
+	fields := splitHeaderFields(message[:headerEnd+2])
+	bodyHash := sha256.Sum256(relaxedBody(message[headerEnd+4:]))
+
+	var signedNames []string
+	signed := sha256.New()
+	for _, name := range dkimSignedHeaders {
+		field, ok := fields[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		signedNames = append(signedNames, strings.ToLower(name))
+		signed.Write([]byte(relaxedHeader(field)))
+	}
+
+	value := fmt.Sprintf("v=1; a=rsa-sha256; c=relaxed/relaxed; d=%s; s=%s;\r\n\tt=%d; h=%s;\r\n\tbh=%s;\r\n\tb=",
+		d.domain, d.selector, now.Unix(),
+		strings.Join(signedNames, ":"),
+		base64.StdEncoding.EncodeToString(bodyHash[:]))
+
+	// The signature covers its own header with the b= tag still empty
+	signed.Write([]byte(strings.TrimSuffix(relaxedHeader("DKIM-Signature: "+value+"\r\n"), "\r\n")))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, d.key, crypto.SHA256, signed.Sum(nil))
+	if err != nil {
+		return "", err
+	}
+
+	return "DKIM-Signature: " + value + foldBase64(base64.StdEncoding.EncodeToString(signature)) + "\r\n", nil
+}
+
+// splitHeaderFields returns each header field of an email by lowercased name,
+// with any continuation lines. Only the last instance of a repeated field is
+// kept, the one a verifier checks first.
+func splitHeaderFields(header []byte) map[string]string {
+	fields := make(map[string]string)
+	var name string
+	var field strings.Builder
+	flush := func() {
+		if name != "" {
+			fields[name] = field.String()
+		}
+		field.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(string(header), "\r\n") {
+		if line == "" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			field.WriteString(line)
+			continue
+		}
+		flush()
+		name = ""
+		if colon := strings.Index(line, ":"); colon > 0 {
+			name = strings.ToLower(strings.TrimSpace(line[:colon]))
+		}
+		field.WriteString(line)
+	}
+	flush()
+	return fields
+}
+
+// relaxedHeader canonicalizes a header field the relaxed way: a lowercased name,
+// the value unfolded with runs of whitespace made one space, and no whitespace
+// around the colon or at the end
+func relaxedHeader(field string) string {
+	colon := strings.Index(field, ":")
+	if colon < 0 {
+		return field
+	}
+	name := strings.ToLower(strings.TrimSpace(field[:colon]))
+	value := strings.NewReplacer("\r\n", "", "\n", "").Replace(field[colon+1:])
+	return name + ":" + strings.Join(strings.Fields(value), " ") + "\r\n"
+}
+
+// relaxedBody canonicalizes a body the relaxed way: runs of whitespace in a line
+// made one space, no whitespace at the ends of lines and no empty lines at the
+// end of the body
+func relaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(collapseWhitespace(line), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+// collapseWhitespace makes each run of spaces and tabs in a line one space
+func collapseWhitespace(line string) string {
+	var collapsed strings.Builder
+	inSpace := false
+	for _, r := range line {
+		if r == ' ' || r == '\t' {
+			if !inSpace {
+				collapsed.WriteByte(' ')
+			}
+			inSpace = true
+			continue
+		}
+		inSpace = false
+		collapsed.WriteRune(r)
+	}
+	return collapsed.String()
+}
+
+// foldBase64 breaks a long base64 value over folded header lines
+func foldBase64(value string) string {
+	var folded strings.Builder
+	for len(value) > 72 {
+		folded.WriteString(value[:72] + "\r\n\t")
+		value = value[72:]
+	}
+	folded.WriteString(value)
+	return folded.String()
+}
@@ -0,0 +1,447 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// receivedEmail is an email as the test SMTP server received it
+type receivedEmail struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// startTestSMTPServer runs an SMTP server on a local port that accepts any
+// login and hands each email it receives to the returned channel
+func startTestSMTPServer(t *testing.T) (string, int, <-chan receivedEmail) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan receivedEmail, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSMTP(conn, received)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, received
+}
+
+func serveTestSMTP(conn net.Conn, received chan<- receivedEmail) {
+	text := textproto.NewConn(conn)
+	defer text.Close()
+
+	text.PrintfLine("220 localhost ESMTP")
+	var email receivedEmail
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			text.PrintfLine("250-localhost")
+			text.PrintfLine("250 AUTH PLAIN")
+		case "HELO", "RSET", "NOOP":
+			text.PrintfLine("250 OK")
+		case "AUTH":
+			text.PrintfLine("235 2.7.0 Authentication successful")
+		case "MAIL":
+			email.From = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			text.PrintfLine("250 OK")
+		case "RCPT":
+			email.To = append(email.To, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			// The dot reader leaves bare line feeds; put the CRLFs back
+			email.Data = bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+			received <- email
+			email = receivedEmail{}
+			text.PrintfLine("250 OK")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func newTestDKIMKey(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "dkim.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(path, keyPEM, 0o600))
+	return key, path
+}
+
+// The DKIM checks below follow RFC 6376 on their own rather than reusing the
+// signer's canonicalization, so a mistake there cannot cancel itself out
+
+var (
+	foldingWhitespace = regexp.MustCompile(`\r\n([ \t])`)
+	whitespaceRun     = regexp.MustCompile(`[ \t]+`)
+	signatureValue    = regexp.MustCompile(`([;\s])b=[^;]*`)
+)
+
+// testHeaderField is a header field with its value as written
+type testHeaderField struct {
+	name, value string
+}
+
+// unfoldHeader returns the fields of an email header in order
+func unfoldHeader(header string) []testHeaderField {
+	var fields []testHeaderField
+	for _, line := range strings.Split(foldingWhitespace.ReplaceAllString(header, "$1"), "\r\n") {
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			fields = append(fields, testHeaderField{name: name, value: value})
+		}
+	}
+	return fields
+}
+
+// canonicalHeader is the relaxed header canonicalization of RFC 6376 section 3.4.2
+func canonicalHeader(field testHeaderField) string {
+	return strings.ToLower(strings.TrimSpace(field.name)) + ":" +
+		strings.TrimSpace(whitespaceRun.ReplaceAllString(field.value, " ")) + "\r\n"
+}
+
+// canonicalBody is the relaxed body canonicalization of RFC 6376 section 3.4.4
+func canonicalBody(body string) string {
+	lines := strings.Split(body, "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(whitespaceRun.ReplaceAllString(line, " "), " ")
+	}
+	canonical := strings.TrimRight(strings.Join(lines, "\r\n"), "\r\n")
+	if canonical == "" {
+		return ""
+	}
+	return canonical + "\r\n"
+}
+
+// verifyDKIM checks an email's DKIM signature against the signer's public key
+func verifyDKIM(raw []byte, publicKey *rsa.PublicKey) error {
+	header, body, ok := strings.Cut(string(raw), "\r\n\r\n")
+	if !ok {
+		return errors.New("no body")
+	}
+	fields := unfoldHeader(header)
+
+	var signatureField *testHeaderField
+	for i := range fields {
+		if strings.EqualFold(strings.TrimSpace(fields[i].name), "DKIM-Signature") {
+			signatureField = &fields[i]
+			break
+		}
+	}
+	if signatureField == nil {
+		return errors.New("not signed")
+	}
+
+	tags := map[string]string{}
+	for _, tag := range strings.Split(signatureField.value, ";") {
+		name, value, _ := strings.Cut(tag, "=")
+		tags[strings.TrimSpace(name)] = strings.Join(strings.Fields(value), "")
+	}
+	if tags["v"] != "1" || tags["a"] != "rsa-sha256" || tags["c"] != "relaxed/relaxed" {
+		return fmt.Errorf("unexpected signature tags v=%s a=%s c=%s", tags["v"], tags["a"], tags["c"])
+	}
+
+	bodyHash := sha256.Sum256([]byte(canonicalBody(body)))
+	if tags["bh"] != base64.StdEncoding.EncodeToString(bodyHash[:]) {
+		return errors.New("body hash does not match")
+	}
+
+	// Each name in h= takes the last instance of the field not yet used
+	signed := sha256.New()
+	used := make(map[int]bool)
+	for _, name := range strings.Split(tags["h"], ":") {
+		for i := len(fields) - 1; i >= 0; i-- {
+			if !used[i] && strings.EqualFold(strings.TrimSpace(fields[i].name), name) {
+				used[i] = true
+				signed.Write([]byte(canonicalHeader(fields[i])))
+				break
+			}
+		}
+	}
+	unsigned := testHeaderField{name: signatureField.name, value: signatureValue.ReplaceAllString(signatureField.value, "${1}b=")}
+	signed.Write([]byte(strings.TrimSuffix(canonicalHeader(unsigned), "\r\n")))
+
+	signature, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		return err
+	}
+	return rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, signed.Sum(nil), signature)
+}
+
+// readParts reads the parts of a multipart body
+func readParts(t *testing.T, contentType string, body io.Reader) ([]*multipart.Part, [][]byte) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(mediaType, "multipart/"), "got %s", mediaType)
+
+	var parts []*multipart.Part
+	var bodies [][]byte
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			return parts, bodies
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(part)
+		require.NoError(t, err)
+		parts = append(parts, part)
+		bodies = append(bodies, data)
+	}
+}
+
+func decodeQuotedPrintable(t *testing.T, body []byte) string {
+	decoded, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(body)))
+	require.NoError(t, err)
+	return string(decoded)
+}
+
+func TestEmailService_SendMessage(t *testing.T) {
+	host, port, received := startTestSMTPServer(t)
+	key, keyPath := newTestDKIMKey(t)
+	logoPath := filepath.Join(t.TempDir(), "logo.png")
+	logo := []byte("\x89PNG\r\n\x1a\nlogo")
+	require.NoError(t, os.WriteFile(logoPath, logo, 0o600))
+
+	service := NewEmailService(&models.EmailConfig{
+		SMTPHost:           host,
+		SMTPPort:           port,
+		SMTPUsername:       "library",
+		SMTPPassword:       "password",
+		FromEmail:          "library@example.com",
+		FromName:           "Library System",
+		UseTLS:             true,
+		BounceAddress:      "bounces@example.com",
+		BounceSecret:       "bounce-secret",
+		DKIMDomain:         "example.com",
+		DKIMSelector:       "lms",
+		DKIMPrivateKeyPath: keyPath,
+		LogoPath:           logoPath,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NotNil(t, service.dkim)
+	require.NotNil(t, service.logo)
+
+	receive := func(t *testing.T) receivedEmail {
+		select {
+		case email := <-received:
+			return email
+		case <-time.After(5 * time.Second):
+			t.Fatal("no email received")
+			return receivedEmail{}
+		}
+	}
+
+	t.Run("HTML with inline logo and attachment", func(t *testing.T) {
+		receipt := bytes.Repeat([]byte("%PDF-1.4 receipt "), 20)
+		tracking := &EmailTracking{DeliveryID: 42}
+		ctx := WithListUnsubscribe(WithEmailTracking(context.Background(), tracking), "https://library.example.com/unsubscribe?token=abc")
+
+		err := service.SendMessage(ctx, &EmailMessage{
+			To:       "student@example.com",
+			Subject:  "Malipo yamepokelewa – receipt",
+			HTMLBody: `<img src="cid:logo"><p>Dear Jane,</p><p>Your payment was <strong>received</strong>. <a href="https://library.example.com/fines">View fines</a></p>`,
+			Attachments: []EmailAttachment{
+				{Filename: "receipt.pdf", Data: receipt},
+			},
+		})
+		require.NoError(t, err)
+
+		email := receive(t)
+		assert.Equal(t, verpAddress("bounces@example.com", "bounce-secret", 42), email.From)
+		assert.Equal(t, []string{"student@example.com"}, email.To)
+		require.NoError(t, verifyDKIM(email.Data, &key.PublicKey))
+
+		msg, err := mail.ReadMessage(bytes.NewReader(email.Data))
+		require.NoError(t, err)
+		subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+		require.NoError(t, err)
+		assert.Equal(t, "Malipo yamepokelewa – receipt", subject)
+		assert.Equal(t, "<"+tracking.MessageID+">", msg.Header.Get("Message-Id"))
+		assert.Equal(t, "<https://library.example.com/unsubscribe?token=abc>", msg.Header.Get("List-Unsubscribe"))
+		assert.Equal(t, "List-Unsubscribe=One-Click", msg.Header.Get("List-Unsubscribe-Post"))
+		assert.Contains(t, msg.Header.Get("Dkim-Signature"), "d=example.com; s=lms;")
+
+		// multipart/mixed holds the body and the attachment
+		mixed, mixedBodies := readParts(t, msg.Header.Get("Content-Type"), msg.Body)
+		require.Len(t, mixed, 2)
+		assert.Equal(t, "application/pdf", mixed[1].Header.Get("Content-Type"))
+		assert.Equal(t, "receipt.pdf", mixed[1].FileName())
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.ReplaceAll(mixedBodies[1], []byte("\r\n"), nil)))
+		require.NoError(t, err)
+		assert.Equal(t, receipt, decoded)
+
+		// multipart/related holds the alternatives and the inline logo
+		related, relatedBodies := readParts(t, mixed[0].Header.Get("Content-Type"), bytes.NewReader(mixedBodies[0]))
+		require.Len(t, related, 2)
+		assert.Equal(t, "<logo>", related[1].Header.Get("Content-ID"))
+		assert.Equal(t, "image/png", related[1].Header.Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(related[1].Header.Get("Content-Disposition"), "inline"))
+
+		// multipart/alternative offers plain text before HTML
+		alternative, alternativeBodies := readParts(t, related[0].Header.Get("Content-Type"), bytes.NewReader(relatedBodies[0]))
+		require.Len(t, alternative, 2)
+		assert.Equal(t, "text/plain; charset=UTF-8", alternative[0].Header.Get("Content-Type"))
+		assert.Equal(t, "text/html; charset=UTF-8", alternative[1].Header.Get("Content-Type"))
+		assert.Equal(t, "quoted-printable", alternative[0].Header.Get("Content-Transfer-Encoding"))
+		assert.Contains(t, decodeQuotedPrintable(t, alternativeBodies[0]), "Dear Jane,\r\n\r\nYour payment was received. View fines (https://library.example.com/fines)")
+		assert.Contains(t, decodeQuotedPrintable(t, alternativeBodies[1]), "<strong>received</strong>")
+	})
+
+	t.Run("plain text is a single part", func(t *testing.T) {
+		err := service.SendEmail(context.Background(), "student@example.com", "Book Due Soon", "Dear Jane,\n\nYour book is due tomorrow.", false)
+		require.NoError(t, err)
+
+		email := receive(t)
+		assert.Equal(t, "bounces@example.com", email.From)
+		require.NoError(t, verifyDKIM(email.Data, &key.PublicKey))
+
+		msg, err := mail.ReadMessage(bytes.NewReader(email.Data))
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain; charset=UTF-8", msg.Header.Get("Content-Type"))
+		assert.Empty(t, msg.Header.Get("List-Unsubscribe"))
+		body, err := io.ReadAll(msg.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "Dear Jane,\r\n\r\nYour book is due tomorrow.\r\n", string(body))
+	})
+
+	t.Run("altered email fails verification", func(t *testing.T) {
+		err := service.SendEmail(context.Background(), "student@example.com", "Fine Notice", "Fine Amount: KES 50", false)
+		require.NoError(t, err)
+
+		email := receive(t)
+		require.NoError(t, verifyDKIM(email.Data, &key.PublicKey))
+		assert.Error(t, verifyDKIM(bytes.Replace(email.Data, []byte("KES 50"), []byte("KES 5"), 1), &key.PublicKey))
+		assert.Error(t, verifyDKIM(bytes.Replace(email.Data, []byte("Subject: Fine Notice"), []byte("Subject: Fine Paid"), 1), &key.PublicKey))
+	})
+
+	t.Run("attachment without a filename", func(t *testing.T) {
+		err := service.SendMessage(context.Background(), &EmailMessage{
+			To:          "student@example.com",
+			Subject:     "Report",
+			TextBody:    "Attached",
+			Attachments: []EmailAttachment{{Data: []byte("a,b")}},
+		})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDKIMCanonicalization(t *testing.T) {
+	// The example from RFC 6376 section 3.4.5
+	message := "A: X\r\nB : Y\t\r\n\tZ  \r\n\r\n C \r\nD \t E\r\n\r\n\r\n"
+	headerEnd := strings.Index(message, "\r\n\r\n")
+	fields := splitHeaderFields([]byte(message[:headerEnd+2]))
+
+	assert.Equal(t, "a:X\r\n", relaxedHeader(fields["a"]))
+	assert.Equal(t, "b:Y Z\r\n", relaxedHeader(fields["b"]))
+	assert.Equal(t, " C\r\nD E\r\n", string(relaxedBody([]byte(message[headerEnd+4:]))))
+	assert.Empty(t, relaxedBody([]byte("\r\n\r\n")))
+
+	// The test's own canonicalization, which verifyDKIM relies on, gives the same
+	testFields := unfoldHeader(message[:headerEnd])
+	require.Len(t, testFields, 2)
+	assert.Equal(t, "a:X\r\n", canonicalHeader(testFields[0]))
+	assert.Equal(t, "b:Y Z\r\n", canonicalHeader(testFields[1]))
+	assert.Equal(t, " C\r\nD E\r\n", canonicalBody(message[headerEnd+4:]))
+	assert.Empty(t, canonicalBody("\r\n\r\n"))
+}
+
+func TestNewDKIMSigner(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	signer, err := newDKIMSigner("example.com", "lms", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+	require.NoError(t, err)
+	assert.Equal(t, key.N, signer.key.N)
+
+	_, err = newDKIMSigner("example.com", "lms", []byte("not a key"))
+	assert.Error(t, err)
+}
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "paragraphs and inline markup",
+			html: "<p>Dear   Jane,</p><p>Your book is <em>overdue</em>.</p>",
+			want: "Dear Jane,\n\nYour book is overdue.",
+		},
+		{
+			name: "lists, breaks and links",
+			html: `<ul><li>One</li><li>Two</li></ul>Line<br>Next <a href="https://example.com">here</a>`,
+			want: "- One\n- Two\n\nLine\nNext here (https://example.com)",
+		},
+		{
+			name: "head and styles are left out",
+			html: "<html><head><title>T</title><style>p{}</style></head><body><div>Hello</div></body></html>",
+			want: "Hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, htmlToText(tt.html))
+		})
+	}
+}
+
+func TestFormatAddress(t *testing.T) {
+	assert.Equal(t, "Library System <library@example.com>", formatAddress("Library System", "library@example.com"))
+	assert.Equal(t, `"Library, Main" <library@example.com>`, formatAddress("Library, Main", "library@example.com"))
+	assert.Equal(t, "=?utf-8?q?Maktaba_Kuu_=E2=80=93_LMS?= <library@example.com>", formatAddress("Maktaba Kuu – LMS", "library@example.com"))
+	assert.Equal(t, "library@example.com", formatAddress("", "library@example.com"))
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// mimePart is one part of an email body with the headers that describe it
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// buildMessageBody builds the body of an email with the headers that go on the
+// message to describe it
+func buildMessageBody(message *EmailMessage) (mimePart, error) {
+	text := message.TextBody
+	if text == "" && message.HTMLBody != "" {
+		text = htmlToText(message.HTMLBody)
+	}
+
+	body := textPart("text/plain", text)
+	if message.HTMLBody != "" {
+		var err error
+		body, err = multipartOf("alternative", body, textPart("text/html", message.HTMLBody))
+		if err != nil {
+			return mimePart{}, err
+		}
+	}
+
+	var inline, attached []mimePart
+	for i, attachment := range message.Attachments {
+		if attachment.ContentID == "" && attachment.Filename == "" {
+			return mimePart{}, fmt.Errorf("attachment %d has no filename", i)
+		}
+		if attachment.ContentID != "" {
+			inline = append(inline, attachmentPart(attachment))
+		} else {
+			attached = append(attached, attachmentPart(attachment))
+		}
+	}
+
+	var err error
+	if len(inline) > 0 {
+		if body, err = multipartOf("related", append([]mimePart{body}, inline...)...); err != nil {
+			return mimePart{}, err
+		}
+	}
+	if len(attached) > 0 {
+		if body, err = multipartOf("mixed", append([]mimePart{body}, attached...)...); err != nil {
+			return mimePart{}, err
+		}
+	}
+	return body, nil
+}
+
+// textPart is a UTF-8 text part in quoted-printable, which keeps lines short
+// and is left alone by servers relaying it, so its DKIM signature holds
+func textPart(mediaType, text string) mimePart {
+	var body bytes.Buffer
+	writer := quotedprintable.NewWriter(&body)
+	writer.Write([]byte(text))
+	writer.Close()
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mediaType+"; charset=UTF-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	return mimePart{header: header, body: body.Bytes()}
+}
+
+// attachmentPart is a file in base64, inline when it has a Content-ID
+func attachmentPart(attachment EmailAttachment) mimePart {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(attachment.Filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	disposition := "attachment"
+	header := textproto.MIMEHeader{}
+	if attachment.ContentID != "" {
+		disposition = "inline"
+		header.Set("Content-ID", "<"+attachment.ContentID+">")
+	}
+	if attachment.Filename != "" {
+		disposition = mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename})
+	}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Disposition", disposition)
+	header.Set("Content-Transfer-Encoding", "base64")
+	return mimePart{header: header, body: base64Lines(attachment.Data)}
+}
+
+// multipartOf joins parts into a multipart part of the given subtype
+func multipartOf(subtype string, parts ...mimePart) (mimePart, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, part := range parts {
+		partWriter, err := writer.CreatePart(part.header)
+		if err != nil {
+			return mimePart{}, err
+		}
+		if _, err := partWriter.Write(part.body); err != nil {
+			return mimePart{}, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return mimePart{}, err
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": writer.Boundary()}))
+	return mimePart{header: header, body: body.Bytes()}, nil
+}
+
+// base64Lines encodes data in base64 broken into lines of 76 characters
+func base64Lines(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var lines bytes.Buffer
+	for len(encoded) > 76 {
+		lines.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	if encoded != "" {
+		lines.WriteString(encoded + "\r\n")
+	}
+	return lines.Bytes()
+}
+
+// formatAddress writes an address with a display name, quoting or encoding the
+// name only when it needs it
+func formatAddress(name, address string) string {
+	if name == "" {
+		return address
+	}
+	if strings.ContainsAny(name, "()<>[]:;@\\,.\"") || strings.IndexFunc(name, func(r rune) bool { return r > 126 || r < 32 }) >= 0 {
+		return (&mail.Address{Name: name, Address: address}).String()
+	}
+	return fmt.Sprintf("%s <%s>", name, address)
+}
+
+func hasContentID(attachments []EmailAttachment, contentID string) bool {
+	for _, attachment := range attachments {
+		if attachment.ContentID == contentID {
+			return true
+		}
+	}
+	return false
+}
+
+// loadInlineImage reads an image to show inline in HTML emails as
+// cid:<contentID>
+func loadInlineImage(path, contentID string) (*EmailAttachment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("%s is not an image", path)
+	}
+
+	return &EmailAttachment{
+		Filename:    filepath.Base(path),
+		ContentType: contentType,
+		Data:        data,
+		ContentID:   contentID,
+	}, nil
+}
+
+// htmlToText writes the text of an HTML body for its plain-text alternative.
+// Blocks become paragraphs, list items are bulleted and links are followed by
+// their address.
+func htmlToText(body string) string {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return body
+	}
+
+	var text strings.Builder
+	space := func() {
+		if current := text.String(); current != "" && !strings.HasSuffix(current, " ") && !strings.HasSuffix(current, "\n") {
+			text.WriteString(" ")
+		}
+	}
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		switch node.Type {
+		case html.TextNode:
+			// Runs of whitespace read as one space, as a browser shows them
+			words := strings.Fields(node.Data)
+			if len(words) == 0 || unicode.IsSpace(rune(node.Data[0])) {
+				space()
+			}
+			if len(words) > 0 {
+				text.WriteString(strings.Join(words, " "))
+				if unicode.IsSpace(rune(node.Data[len(node.Data)-1])) {
+					space()
+				}
+			}
+			return
+		case html.ElementNode:
+			switch node.Data {
+			case "head", "script", "style", "title":
+				return
+			case "br":
+				text.WriteString("\n")
+				return
+			case "li":
+				text.WriteString("\n- ")
+			case "p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "table", "tr", "ul", "ol", "blockquote":
+				text.WriteString("\n\n")
+			}
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+
+		if node.Type == html.ElementNode {
+			switch node.Data {
+			case "a":
+				for _, attr := range node.Attr {
+					if attr.Key == "href" && (strings.HasPrefix(attr.Val, "http://") || strings.HasPrefix(attr.Val, "https://")) {
+						text.WriteString(" (" + attr.Val + ")")
+					}
+				}
+			case "p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "table", "tr", "ul", "ol", "blockquote":
+				text.WriteString("\n\n")
+			}
+		}
+	}
+	walk(doc)
+
+	// Tidy the spacing the walk left between and around blocks
+	lines := strings.Split(text.String(), "\n")
+	var tidied []string
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			blank = len(tidied) > 0
+			continue
+		}
+		if blank {
+			tidied = append(tidied, "")
+			blank = false
+		}
+		tidied = append(tidied, line)
+	}
+	return strings.Join(tidied, "\n")
+}
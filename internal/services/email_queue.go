@@ -2,26 +2,66 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/ngenohkevin/lms/internal/database/queries"
 	"github.com/ngenohkevin/lms/internal/models"
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	emailQueueRedisKey            = "email_queue"
+	defaultEmailQueueBatchSize    = 10
+	defaultEmailQueuePollInterval = 5 * time.Second
+	// emailQueueUpdateTimeout is how long recording an item's outcome may take
+	// after its send was cancelled
+	emailQueueUpdateTimeout = 10 * time.Second
+)
+
+var (
+	// ErrQueueItemNotFound is returned for an email queue item that does not exist
+	ErrQueueItemNotFound = errors.New("email queue item not found")
+	// ErrQueueItemNotCancellable is returned when cancelling an item that is no longer pending
+	ErrQueueItemNotCancellable = errors.New("only pending emails can be cancelled")
+	// ErrQueueItemNotRetryable is returned when retrying an item that has not failed or been cancelled
+	ErrQueueItemNotRetryable = errors.New("only failed or cancelled emails can be retried")
+	// ErrEmailQueueStopped is returned when a worker is started during shutdown
+	ErrEmailQueueStopped = errors.New("email queue is shutting down")
+)
+
+// NotificationDeliverer sends the email for a queued notification. attempt
+// counts from 1 up to maxAttempts, the most the queue will make.
+type NotificationDeliverer interface {
+	DeliverEmail(ctx context.Context, notificationID int32, attempt, maxAttempts int) error
+}
+
 // EmailQueueService handles email queue processing with Redis and PostgreSQL
 type EmailQueueService struct {
-	queries     *queries.Queries
-	redisClient *redis.Client
-	logger      *slog.Logger
-	workerID    string
-	workers     map[string]*EmailWorker
-	mu          sync.RWMutex
+	queries      *queries.Queries
+	redisClient  *redis.Client
+	logger       *slog.Logger
+	deliverer    NotificationDeliverer
+	workerID     string
+	batchSize    int32
+	pollInterval time.Duration
+	workers      map[string]*EmailWorker
+	mu           sync.RWMutex
+	stopping     bool
+	// loops tracks worker loops; sends outlive their loop's context so a worker
+	// told to stop finishes the email it is on until sendCtx is cancelled
+	loops       sync.WaitGroup
+	sendCtx     context.Context
+	cancelSends context.CancelFunc
 }
 
 // EmailWorker represents a worker processing emails
@@ -44,10 +84,13 @@ type EmailQueueServiceInterface interface {
 	CompleteQueueItem(ctx context.Context, id int32) (*models.EmailQueueItem, error)
 	FailQueueItem(ctx context.Context, id int32, errorMsg string) (*models.EmailQueueItem, error)
 	CancelQueueItem(ctx context.Context, id int32) (*models.EmailQueueItem, error)
+	RetryQueueItem(ctx context.Context, id int32) (*models.EmailQueueItem, error)
 
 	// Worker management
 	StartWorker(ctx context.Context, workerID string) error
 	StopWorker(ctx context.Context, workerID string) error
+	StartWorkers(ctx context.Context, count int) error
+	Shutdown(ctx context.Context) error
 	GetActiveWorkers() []*EmailWorker
 	ProcessNextBatch(ctx context.Context, batchSize int32) error
 
@@ -68,15 +111,43 @@ type EmailQueueServiceInterface interface {
 
 // NewEmailQueueService creates a new email queue service
 func NewEmailQueueService(queries *queries.Queries, redisClient *redis.Client, logger *slog.Logger) EmailQueueServiceInterface {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	sendCtx, cancelSends := context.WithCancel(context.Background())
 	return &EmailQueueService{
-		queries:     queries,
-		redisClient: redisClient,
-		logger:      logger,
-		workerID:    fmt.Sprintf("worker-%d", time.Now().Unix()),
-		workers:     make(map[string]*EmailWorker),
+		queries:      queries,
+		redisClient:  redisClient,
+		logger:       logger,
+		workerID:     fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		batchSize:    defaultEmailQueueBatchSize,
+		pollInterval: defaultEmailQueuePollInterval,
+		workers:      make(map[string]*EmailWorker),
+		sendCtx:      sendCtx,
+		cancelSends:  cancelSends,
 	}
 }
 
+// WithDeliverer sets what sends the email for each queued notification
+func (s *EmailQueueService) WithDeliverer(deliverer NotificationDeliverer) *EmailQueueService {
+	s.deliverer = deliverer
+	return s
+}
+
+// WithBatchSize sets how many items a worker claims at a time
+func (s *EmailQueueService) WithBatchSize(size int) *EmailQueueService {
+	s.batchSize = int32(size)
+	return s
+}
+
+// WithPollInterval sets how often an idle worker checks for due items
+func (s *EmailQueueService) WithPollInterval(interval time.Duration) *EmailQueueService {
+	s.pollInterval = interval
+	return s
+}
+
 // QueueEmail adds an email to the processing queue
 func (s *EmailQueueService) QueueEmail(ctx context.Context, req *models.EmailQueueRequest) (*models.EmailQueueItem, error) {
 	if err := s.ValidateQueueRequest(req); err != nil {
@@ -239,16 +310,55 @@ func (s *EmailQueueService) FailQueueItem(ctx context.Context, id int32, errorMs
 	return queueItem, nil
 }
 
-// CancelQueueItem cancels a queue item
+// CancelQueueItem cancels a queue item that has not been picked up yet
 func (s *EmailQueueService) CancelQueueItem(ctx context.Context, id int32) (*models.EmailQueueItem, error) {
 	dbItem, err := s.queries.CancelQueueItem(ctx, id)
 	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, s.queueItemStateError(ctx, id, ErrQueueItemNotCancellable)
+		}
 		s.logger.Error("Failed to cancel queue item", "error", err, "id", id)
 		return nil, fmt.Errorf("failed to cancel queue item: %w", err)
 	}
 
+	queueItem := s.convertToEmailQueueItem(&dbItem)
+	s.removeFromRedisQueue(ctx, queueItem)
+
 	s.logger.Info("Queue item cancelled", "id", id)
-	return s.convertToEmailQueueItem(&dbItem), nil
+	return queueItem, nil
+}
+
+// RetryQueueItem puts a failed or cancelled item back in the queue with a fresh
+// set of attempts
+func (s *EmailQueueService) RetryQueueItem(ctx context.Context, id int32) (*models.EmailQueueItem, error) {
+	dbItem, err := s.queries.RetryQueueItem(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, s.queueItemStateError(ctx, id, ErrQueueItemNotRetryable)
+		}
+		s.logger.Error("Failed to retry queue item", "error", err, "id", id)
+		return nil, fmt.Errorf("failed to retry queue item: %w", err)
+	}
+
+	queueItem := s.convertToEmailQueueItem(&dbItem)
+	if err := s.PushToRedisQueue(ctx, queueItem); err != nil {
+		s.logger.Error("Failed to push to Redis queue", "error", err, "queue_id", queueItem.ID)
+	}
+
+	s.logger.Info("Queue item requeued", "id", id)
+	return queueItem, nil
+}
+
+// queueItemStateError tells an item that does not exist apart from one whose
+// status does not allow the change
+func (s *EmailQueueService) queueItemStateError(ctx context.Context, id int32, stateErr error) error {
+	if _, err := s.queries.GetEmailQueueItem(ctx, id); err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return ErrQueueItemNotFound
+		}
+		return fmt.Errorf("failed to get queue item: %w", err)
+	}
+	return stateErr
 }
 
 // StartWorker starts a worker to process email queue
@@ -256,6 +366,9 @@ func (s *EmailQueueService) StartWorker(ctx context.Context, workerID string) er
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.stopping {
+		return ErrEmailQueueStopped
+	}
 	if _, exists := s.workers[workerID]; exists {
 		return fmt.Errorf("worker %s already exists", workerID)
 	}
@@ -272,6 +385,7 @@ func (s *EmailQueueService) StartWorker(ctx context.Context, workerID string) er
 	s.workers[workerID] = worker
 
 	// Start worker goroutine
+	s.loops.Add(1)
 	go s.workerLoop(workerCtx, worker)
 
 	s.logger.Info("Started email worker", "worker_id", workerID)
@@ -296,6 +410,51 @@ func (s *EmailQueueService) StopWorker(ctx context.Context, workerID string) err
 	return nil
 }
 
+// StartWorkers starts count workers named after this server
+func (s *EmailQueueService) StartWorkers(ctx context.Context, count int) error {
+	for i := 1; i <= count; i++ {
+		if err := s.StartWorker(ctx, fmt.Sprintf("%s-%d", s.workerID, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown stops the workers claiming items and waits for the emails they are
+// sending. Items claimed but not started go back to pending. If ctx ends first
+// the sends are cancelled and their items retried later.
+func (s *EmailQueueService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopping = true
+	for workerID, worker := range s.workers {
+		worker.cancel()
+		worker.IsProcessing = false
+		delete(s.workers, workerID)
+	}
+	s.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		s.cancelSends()
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.cancelSends()
+	select {
+	case <-drained:
+	case <-time.After(emailQueueUpdateTimeout):
+		return fmt.Errorf("email workers still running after sends were cancelled: %w", ctx.Err())
+	}
+	return fmt.Errorf("cancelled email sends: %w", ctx.Err())
+}
+
 // GetActiveWorkers returns list of active workers
 func (s *EmailQueueService) GetActiveWorkers() []*EmailWorker {
 	s.mu.RLock()
@@ -311,45 +470,72 @@ func (s *EmailQueueService) GetActiveWorkers() []*EmailWorker {
 
 // ProcessNextBatch processes the next batch of emails
 func (s *EmailQueueService) ProcessNextBatch(ctx context.Context, batchSize int32) error {
-	// Get next items from database
-	items, err := s.GetNextQueueItems(ctx, batchSize)
+	_, err := s.processBatch(ctx, ctx, s.workerID, batchSize)
+	return err
+}
+
+// processBatch claims up to batchSize due items for workerID and sends them with
+// sendCtx. Once ctx ends the items not yet sent are released. It returns how
+// many items it claimed.
+func (s *EmailQueueService) processBatch(ctx, sendCtx context.Context, workerID string, batchSize int32) (int, error) {
+	if s.deliverer == nil {
+		return 0, fmt.Errorf("no notification deliverer configured")
+	}
+
+	dbItems, err := s.queries.ClaimNextQueueItems(ctx, queries.ClaimNextQueueItemsParams{
+		WorkerID: pgtype.Text{String: workerID, Valid: true},
+		Limit:    batchSize,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get next queue items: %w", err)
+		return 0, fmt.Errorf("failed to claim queue items: %w", err)
 	}
 
-	if len(items) == 0 {
-		return nil // No items to process
+	if len(dbItems) == 0 {
+		return 0, nil // No items to process
 	}
 
-	s.logger.Info("Processing email batch", "batch_size", len(items))
+	items := make([]*models.EmailQueueItem, len(dbItems))
+	for i, dbItem := range dbItems {
+		items[i] = s.convertToEmailQueueItem(&dbItem)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Priority != items[j].Priority {
+			return items[i].Priority < items[j].Priority
+		}
+		return items[i].ScheduledFor.Before(items[j].ScheduledFor)
+	})
 
-	for _, item := range items {
-		// Mark as processing
-		_, err := s.UpdateQueueItemStatus(ctx, item.ID, models.EmailQueueStatusProcessing, s.workerID)
-		if err != nil {
-			s.logger.Error("Failed to mark item as processing", "error", err, "id", item.ID)
-			continue
+	s.logger.Info("Processing email batch", "batch_size", len(items), "worker_id", workerID)
+
+	// Outcomes are recorded even when the send was cancelled part way
+	updateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), emailQueueUpdateTimeout)
+	defer cancel()
+
+	for i, item := range items {
+		if ctx.Err() != nil {
+			for _, unsent := range items[i:] {
+				if err := s.queries.ReleaseQueueItem(updateCtx, unsent.ID); err != nil {
+					s.logger.Error("Failed to release queue item", "error", err, "id", unsent.ID)
+				}
+			}
+			s.logger.Info("Released unsent queue items", "count", len(items)-i, "worker_id", workerID)
+			break
 		}
 
-		// Process the item (placeholder - in real implementation, this would send the email)
-		err = s.processEmailItem(ctx, item)
-		if err != nil {
-			// Mark as failed
-			_, failErr := s.FailQueueItem(ctx, item.ID, err.Error())
-			if failErr != nil {
+		s.removeFromRedisQueue(updateCtx, item)
+		if err := s.processEmailItem(sendCtx, item); err != nil {
+			if _, failErr := s.FailQueueItem(updateCtx, item.ID, err.Error()); failErr != nil {
 				s.logger.Error("Failed to mark item as failed", "error", failErr, "id", item.ID)
 			}
 			continue
 		}
 
-		// Mark as completed
-		_, err = s.CompleteQueueItem(ctx, item.ID)
-		if err != nil {
+		if _, err := s.CompleteQueueItem(updateCtx, item.ID); err != nil {
 			s.logger.Error("Failed to mark item as completed", "error", err, "id", item.ID)
 		}
 	}
 
-	return nil
+	return len(items), nil
 }
 
 // GetQueueStats retrieves queue statistics
@@ -424,21 +610,14 @@ func (s *EmailQueueService) GetQueueLength(ctx context.Context) (int64, error) {
 
 // PushToRedisQueue adds an item to Redis queue
 func (s *EmailQueueService) PushToRedisQueue(ctx context.Context, queueItem *models.EmailQueueItem) error {
-	// Create a task for Redis (simplified version with ID and priority)
-	task := map[string]interface{}{
-		"id":            queueItem.ID,
-		"priority":      queueItem.Priority,
-		"scheduled_for": queueItem.ScheduledFor.Unix(),
-	}
-
-	taskJSON, err := json.Marshal(task)
+	taskJSON, err := redisQueueMember(queueItem)
 	if err != nil {
-		return fmt.Errorf("failed to marshal task: %w", err)
+		return err
 	}
 
 	// Use sorted set with priority as score (lower number = higher priority)
 	score := float64(queueItem.Priority) + float64(queueItem.ScheduledFor.Unix())/1000000000 // Add timestamp for FIFO within same priority
-	err = s.redisClient.ZAdd(ctx, "email_queue", redis.Z{
+	err = s.redisClient.ZAdd(ctx, emailQueueRedisKey, redis.Z{
 		Score:  score,
 		Member: taskJSON,
 	}).Err()
@@ -450,10 +629,38 @@ func (s *EmailQueueService) PushToRedisQueue(ctx context.Context, queueItem *mod
 	return nil
 }
 
+// removeFromRedisQueue drops an item's Redis entry once it has left the pending
+// queue. The database is the source of truth, so a failure is only logged.
+func (s *EmailQueueService) removeFromRedisQueue(ctx context.Context, queueItem *models.EmailQueueItem) {
+	member, err := redisQueueMember(queueItem)
+	if err == nil {
+		err = s.redisClient.ZRem(ctx, emailQueueRedisKey, member).Err()
+	}
+	if err != nil {
+		s.logger.Warn("Failed to remove item from Redis queue", "error", err, "queue_id", queueItem.ID)
+	}
+}
+
+// redisQueueMember is the entry an item has in the Redis queue
+func redisQueueMember(queueItem *models.EmailQueueItem) ([]byte, error) {
+	// Create a task for Redis (simplified version with ID and priority)
+	task := map[string]interface{}{
+		"id":            queueItem.ID,
+		"priority":      queueItem.Priority,
+		"scheduled_for": queueItem.ScheduledFor.Unix(),
+	}
+
+	taskJSON, err := json.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task: %w", err)
+	}
+	return taskJSON, nil
+}
+
 // PopFromRedisQueue gets the next item from Redis queue
 func (s *EmailQueueService) PopFromRedisQueue(ctx context.Context) (*models.EmailQueueItem, error) {
 	// Get the item with lowest score (highest priority, earliest time)
-	result, err := s.redisClient.ZPopMin(ctx, "email_queue", 1).Result()
+	result, err := s.redisClient.ZPopMin(ctx, emailQueueRedisKey, 1).Result()
 	if err != nil {
 		return nil, fmt.Errorf("failed to pop from Redis queue: %w", err)
 	}
@@ -475,7 +682,7 @@ func (s *EmailQueueService) PopFromRedisQueue(ctx context.Context) (*models.Emai
 
 // GetRedisQueueLength returns the length of Redis queue
 func (s *EmailQueueService) GetRedisQueueLength(ctx context.Context) (int64, error) {
-	return s.redisClient.ZCard(ctx, "email_queue").Result()
+	return s.redisClient.ZCard(ctx, emailQueueRedisKey).Result()
 }
 
 // ValidateQueueRequest validates an email queue request
@@ -505,38 +712,54 @@ func (s *EmailQueueService) ValidateQueueRequest(req *models.EmailQueueRequest)
 
 // workerLoop is the main loop for a worker
 func (s *EmailQueueService) workerLoop(ctx context.Context, worker *EmailWorker) {
-	ticker := time.NewTicker(5 * time.Second) // Process every 5 seconds
+	defer s.loops.Done()
+
+	ticker := time.NewTicker(s.pollInterval)
 	defer ticker.Stop()
 
 	for {
+		// Keep taking batches while they come back full, so a backlog drains
+		// without waiting for the ticker
+		for ctx.Err() == nil {
+			processed, err := s.processBatch(ctx, s.sendCtx, worker.ID, s.batchSize)
+			if err != nil {
+				s.logger.Error("Error processing batch", "error", err, "worker_id", worker.ID)
+				break
+			}
+			if processed > 0 {
+				s.recordProcessed(worker, processed)
+			}
+			if processed < int(s.batchSize) {
+				break
+			}
+		}
+
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			// Process next batch
-			err := s.ProcessNextBatch(ctx, 10) // Process up to 10 items at a time
-			if err != nil {
-				s.logger.Error("Error processing batch", "error", err, "worker_id", worker.ID)
-			}
 		}
 	}
 }
 
-// processEmailItem processes a single email item (placeholder implementation)
-func (s *EmailQueueService) processEmailItem(ctx context.Context, item *models.EmailQueueItem) error {
-	// In a real implementation, this would:
-	// 1. Get the notification details
-	// 2. Get the email template
-	// 3. Render the email content
-	// 4. Send the email via SMTP
-	// 5. Update delivery tracking
+func (s *EmailQueueService) recordProcessed(worker *EmailWorker, processed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	worker.ProcessedJobs += processed
+	worker.LastJobAt = &now
+}
 
+// processEmailItem sends the email for a single queue item
+func (s *EmailQueueService) processEmailItem(ctx context.Context, item *models.EmailQueueItem) error {
 	s.logger.Info("Processing email item", "id", item.ID, "notification_id", item.NotificationID)
 
-	// Simulate processing time
-	time.Sleep(100 * time.Millisecond)
+	if err := s.deliverer.DeliverEmail(ctx, item.NotificationID, item.Attempts+1, item.MaxAttempts); err != nil {
+		s.logger.Warn("Email item failed", "id", item.ID, "attempt", item.Attempts+1, "error", err)
+		return err
+	}
 
-	// For now, just log success
 	s.logger.Info("Email item processed successfully", "id", item.ID)
 	return nil
 }
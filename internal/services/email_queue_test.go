@@ -23,6 +23,10 @@ func (m *mockEmailServiceQueue) SendEmail(ctx context.Context, to, subject, body
 	return nil
 }
 
+func (m *mockEmailServiceQueue) SendMessage(ctx context.Context, message *EmailMessage) error {
+	return nil
+}
+
 func (m *mockEmailServiceQueue) SendTemplatedEmail(ctx context.Context, to string, template *models.EmailTemplate, data map[string]interface{}) error {
 	return nil
 }
@@ -191,8 +191,13 @@ func (m *EmailTemplateManager) TestTemplate(ctx context.Context, templateName st
 		return nil, err
 	}
 
+	return m.PreviewTemplate(template, testData), nil
+}
+
+// PreviewTemplate renders a template that need not be managed here with sample data
+func (m *EmailTemplateManager) PreviewTemplate(template *models.EmailTemplate, testData map[string]interface{}) *models.TemplateTestResult {
 	result := &models.TemplateTestResult{
-		TemplateName: templateName,
+		TemplateName: template.Name,
 		TestData:     testData,
 		Success:      true,
 		TestedAt:     time.Now(),
@@ -203,7 +208,7 @@ func (m *EmailTemplateManager) TestTemplate(ctx context.Context, templateName st
 	if err != nil {
 		result.Success = false
 		result.ErrorMessage = fmt.Sprintf("Subject processing failed: %v", err)
-		return result, nil
+		return result
 	}
 	result.ProcessedSubject = processedSubject
 
@@ -212,7 +217,7 @@ func (m *EmailTemplateManager) TestTemplate(ctx context.Context, templateName st
 	if err != nil {
 		result.Success = false
 		result.ErrorMessage = fmt.Sprintf("Body processing failed: %v", err)
-		return result, nil
+		return result
 	}
 	result.ProcessedBody = processedBody
 
@@ -222,7 +227,7 @@ func (m *EmailTemplateManager) TestTemplate(ctx context.Context, templateName st
 		result.Warnings = append(result.Warnings, fmt.Sprintf("Unresolved variables: %v", unresolvedVars))
 	}
 
-	return result, nil
+	return result
 }
 
 // DuplicateTemplate creates a copy of an existing template
@@ -0,0 +1,682 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// Email template store errors
+var (
+	// ErrTemplateNotFound is returned when no stored template has the given name
+	ErrTemplateNotFound = errors.New("email template not found")
+	// ErrTemplateVersionNotFound is returned when a template has no such version
+	ErrTemplateVersionNotFound = errors.New("email template version not found")
+	// ErrTemplateExists is returned when creating a template whose name is taken
+	ErrTemplateExists = errors.New("email template already exists")
+	// ErrTemplateVersionPublished is returned when rolling back to the version already published
+	ErrTemplateVersionPublished = errors.New("email template version is already published")
+	// ErrDefaultTemplate is returned when deleting one of the built-in templates
+	ErrDefaultTemplate = errors.New("built-in email templates cannot be deleted")
+	// ErrInvalidTemplate wraps template content that fails validation
+	ErrInvalidTemplate = errors.New("invalid email template")
+)
+
+// EmailTemplateQuerier defines the database operations used by the email template service
+type EmailTemplateQuerier interface {
+	CreateEmailTemplate(ctx context.Context, arg queries.CreateEmailTemplateParams) (queries.EmailTemplate, error)
+	GetEmailTemplateByName(ctx context.Context, arg queries.GetEmailTemplateByNameParams) (queries.EmailTemplate, error)
+	ListEmailTemplates(ctx context.Context) ([]queries.EmailTemplate, error)
+	NextEmailTemplateVersion(ctx context.Context, id int32) (queries.EmailTemplate, error)
+	SetEmailTemplatePublishedVersion(ctx context.Context, arg queries.SetEmailTemplatePublishedVersionParams) (queries.EmailTemplate, error)
+	DeleteEmailTemplate(ctx context.Context, id int32) error
+	CreateEmailTemplateVersion(ctx context.Context, arg queries.CreateEmailTemplateVersionParams) (queries.EmailTemplateVersion, error)
+	GetEmailTemplateVersion(ctx context.Context, arg queries.GetEmailTemplateVersionParams) (queries.EmailTemplateVersion, error)
+	ListEmailTemplateVersions(ctx context.Context, templateID int32) ([]queries.EmailTemplateVersion, error)
+	ListPublishedEmailTemplateVersions(ctx context.Context) ([]queries.EmailTemplateVersion, error)
+	GetPublishedEmailTemplateVersion(ctx context.Context, arg queries.GetPublishedEmailTemplateVersionParams) (queries.EmailTemplateVersion, error)
+	ArchivePublishedEmailTemplateVersion(ctx context.Context, templateID int32) error
+	PublishEmailTemplateVersion(ctx context.Context, id int32) (queries.EmailTemplateVersion, error)
+	// ExecTx runs fn inside a single database transaction, committing only if fn succeeds
+	ExecTx(ctx context.Context, fn func(EmailTemplateQuerier) error) error
+}
+
+// TemplateResolver looks up the published content of an email template by name
+// and locale; EmailTemplateService implements it
+type TemplateResolver interface {
+	ResolveTemplate(ctx context.Context, name, locale string) (*models.EmailTemplate, error)
+}
+
+// EmailTemplateServiceInterface defines the interface for managing stored email templates
+type EmailTemplateServiceInterface interface {
+	TemplateResolver
+	ListTemplates(ctx context.Context) ([]models.EmailTemplateResponse, error)
+	GetTemplate(ctx context.Context, name, locale string) (*models.EmailTemplateResponse, error)
+	CreateTemplate(ctx context.Context, req models.CreateEmailTemplateRequest, userID int32) (*models.EmailTemplateResponse, error)
+	CreateVersion(ctx context.Context, name, locale string, req models.EmailTemplateVersionRequest, userID int32) (*models.EmailTemplateVersionResponse, error)
+	DeleteTemplate(ctx context.Context, name, locale string) error
+	ListVersions(ctx context.Context, name, locale string) ([]models.EmailTemplateVersionResponse, error)
+	GetVersion(ctx context.Context, name, locale string, version int32) (*models.EmailTemplateVersionResponse, error)
+	PublishVersion(ctx context.Context, name, locale string, version int32) (*models.EmailTemplateVersionResponse, error)
+	RollbackToVersion(ctx context.Context, name, locale string, version int32, userID int32) (*models.EmailTemplateVersionResponse, error)
+	PreviewTemplate(ctx context.Context, name, locale string, req models.PreviewEmailTemplateRequest) (*models.TemplateTestResult, error)
+	DiffVersions(ctx context.Context, name, locale string, from, to int32) (*models.EmailTemplateDiffResponse, error)
+	SeedDefaults(ctx context.Context) error
+}
+
+// EmailTemplateService keeps email templates in the database so every instance
+// sends the same content. A template is identified by its name and locale. Each
+// edit is saved as a new version; only the published version is used for
+// sending, and older versions can be published again.
+type EmailTemplateService struct {
+	queries EmailTemplateQuerier
+	manager *EmailTemplateManager
+	logger  *slog.Logger
+}
+
+// NewEmailTemplateService creates a new email template service. The manager is
+// used to validate template content and render previews.
+func NewEmailTemplateService(querier EmailTemplateQuerier, manager *EmailTemplateManager, logger *slog.Logger) *EmailTemplateService {
+	return &EmailTemplateService{
+		queries: querier,
+		manager: manager,
+		logger:  logger,
+	}
+}
+
+// ListTemplates returns every stored template with its published content
+func (s *EmailTemplateService) ListTemplates(ctx context.Context) ([]models.EmailTemplateResponse, error) {
+	templates, err := s.queries.ListEmailTemplates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email templates: %w", err)
+	}
+
+	published, err := s.queries.ListPublishedEmailTemplateVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list published template versions: %w", err)
+	}
+	publishedByTemplate := make(map[int32]queries.EmailTemplateVersion, len(published))
+	for _, version := range published {
+		publishedByTemplate[version.TemplateID] = version
+	}
+
+	responses := make([]models.EmailTemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		response := convertToEmailTemplateResponse(template)
+		if version, ok := publishedByTemplate[template.ID]; ok {
+			versionResponse := convertToEmailTemplateVersionResponse(version)
+			response.Published = &versionResponse
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// GetTemplate returns a stored template with its published content
+func (s *EmailTemplateService) GetTemplate(ctx context.Context, name, locale string) (*models.EmailTemplateResponse, error) {
+	template, err := s.getTemplate(ctx, s.queries, name, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	response := convertToEmailTemplateResponse(template)
+	if template.PublishedVersion.Valid {
+		version, err := s.getVersion(ctx, s.queries, template.ID, template.PublishedVersion.Int32)
+		if err != nil {
+			return nil, err
+		}
+		versionResponse := convertToEmailTemplateVersionResponse(version)
+		response.Published = &versionResponse
+	}
+	return &response, nil
+}
+
+// CreateTemplate stores a new template with its content as version 1
+func (s *EmailTemplateService) CreateTemplate(ctx context.Context, req models.CreateEmailTemplateRequest, userID int32) (*models.EmailTemplateResponse, error) {
+	if err := s.validate(ctx, req.Name, req.EmailTemplateVersionRequest); err != nil {
+		return nil, err
+	}
+
+	locale := req.Locale
+	if locale == "" {
+		locale = models.DefaultLocale
+	}
+
+	var response models.EmailTemplateResponse
+	err := s.queries.ExecTx(ctx, func(q EmailTemplateQuerier) error {
+		template, err := q.CreateEmailTemplate(ctx, queries.CreateEmailTemplateParams{
+			Name:        req.Name,
+			Locale:      locale,
+			Description: optionalText(req.Description),
+			CreatedBy:   optionalUserID(userID),
+		})
+		if err != nil {
+			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+				return ErrTemplateExists
+			}
+			return fmt.Errorf("failed to create email template: %w", err)
+		}
+
+		version, err := s.addVersion(ctx, q, template.ID, req.EmailTemplateVersionRequest, userID)
+		if err != nil {
+			return err
+		}
+
+		response = convertToEmailTemplateResponse(template)
+		response.LatestVersion = version.Version
+		if version.Status == models.TemplateVersionPublished {
+			versionResponse := convertToEmailTemplateVersionResponse(version)
+			response.PublishedVersion = &versionResponse.Version
+			response.Published = &versionResponse
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Email template created", "template", req.Name, "locale", locale, "published", req.Publish, "user_id", userID)
+	return &response, nil
+}
+
+// CreateVersion saves new content for a template as a draft, or publishes it
+// straight away if the request asks to
+func (s *EmailTemplateService) CreateVersion(ctx context.Context, name, locale string, req models.EmailTemplateVersionRequest, userID int32) (*models.EmailTemplateVersionResponse, error) {
+	if err := s.validate(ctx, name, req); err != nil {
+		return nil, err
+	}
+
+	var version queries.EmailTemplateVersion
+	err := s.queries.ExecTx(ctx, func(q EmailTemplateQuerier) error {
+		template, err := s.getTemplate(ctx, q, name, locale)
+		if err != nil {
+			return err
+		}
+
+		version, err = s.addVersion(ctx, q, template.ID, req, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Email template version created", "template", name, "locale", locale, "version", version.Version, "status", version.Status, "user_id", userID)
+	response := convertToEmailTemplateVersionResponse(version)
+	return &response, nil
+}
+
+// DeleteTemplate removes a stored template and all its versions. The built-in
+// templates cannot be deleted since notifications depend on them.
+func (s *EmailTemplateService) DeleteTemplate(ctx context.Context, name, locale string) error {
+	if GetBuiltInTemplate(name, locale) != nil {
+		return ErrDefaultTemplate
+	}
+
+	template, err := s.getTemplate(ctx, s.queries, name, locale)
+	if err != nil {
+		return err
+	}
+
+	if err := s.queries.DeleteEmailTemplate(ctx, template.ID); err != nil {
+		return fmt.Errorf("failed to delete email template: %w", err)
+	}
+
+	s.logger.Info("Email template deleted", "template", name, "locale", locale)
+	return nil
+}
+
+// ListVersions returns every version of a template, newest first
+func (s *EmailTemplateService) ListVersions(ctx context.Context, name, locale string) ([]models.EmailTemplateVersionResponse, error) {
+	template, err := s.getTemplate(ctx, s.queries, name, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.queries.ListEmailTemplateVersions(ctx, template.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email template versions: %w", err)
+	}
+
+	responses := make([]models.EmailTemplateVersionResponse, len(versions))
+	for i, version := range versions {
+		responses[i] = convertToEmailTemplateVersionResponse(version)
+	}
+	return responses, nil
+}
+
+// GetVersion returns one version of a template
+func (s *EmailTemplateService) GetVersion(ctx context.Context, name, locale string, version int32) (*models.EmailTemplateVersionResponse, error) {
+	template, err := s.getTemplate(ctx, s.queries, name, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	templateVersion, err := s.getVersion(ctx, s.queries, template.ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	response := convertToEmailTemplateVersionResponse(templateVersion)
+	return &response, nil
+}
+
+// PublishVersion makes a version the one used for sending, archiving the version
+// it replaces. Publishing the version already published changes nothing.
+func (s *EmailTemplateService) PublishVersion(ctx context.Context, name, locale string, version int32) (*models.EmailTemplateVersionResponse, error) {
+	var published queries.EmailTemplateVersion
+	err := s.queries.ExecTx(ctx, func(q EmailTemplateQuerier) error {
+		template, err := s.getTemplate(ctx, q, name, locale)
+		if err != nil {
+			return err
+		}
+
+		published, err = s.getVersion(ctx, q, template.ID, version)
+		if err != nil {
+			return err
+		}
+		if published.Status == models.TemplateVersionPublished {
+			return nil
+		}
+
+		published, err = s.publish(ctx, q, published)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Email template version published", "template", name, "locale", locale, "version", version)
+	response := convertToEmailTemplateVersionResponse(published)
+	return &response, nil
+}
+
+// RollbackToVersion publishes the content of an earlier version as a new version,
+// so the history keeps a record of the rollback
+func (s *EmailTemplateService) RollbackToVersion(ctx context.Context, name, locale string, version int32, userID int32) (*models.EmailTemplateVersionResponse, error) {
+	var rolledBack queries.EmailTemplateVersion
+	err := s.queries.ExecTx(ctx, func(q EmailTemplateQuerier) error {
+		template, err := s.getTemplate(ctx, q, name, locale)
+		if err != nil {
+			return err
+		}
+
+		target, err := s.getVersion(ctx, q, template.ID, version)
+		if err != nil {
+			return err
+		}
+		if target.Status == models.TemplateVersionPublished {
+			return ErrTemplateVersionPublished
+		}
+
+		note := fmt.Sprintf("Rolled back to version %d", target.Version)
+		rolledBack, err = s.addVersion(ctx, q, template.ID, models.EmailTemplateVersionRequest{
+			Subject:    target.Subject,
+			Body:       target.Body,
+			IsHTML:     target.IsHtml,
+			Variables:  target.Variables,
+			ChangeNote: &note,
+			Publish:    true,
+		}, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Email template rolled back", "template", name, "locale", locale, "to_version", version, "new_version", rolledBack.Version, "user_id", userID)
+	response := convertToEmailTemplateVersionResponse(rolledBack)
+	return &response, nil
+}
+
+// PreviewTemplate renders a version of a template with sample data. Variables the
+// data leaves out are shown as placeholders.
+func (s *EmailTemplateService) PreviewTemplate(ctx context.Context, name, locale string, req models.PreviewEmailTemplateRequest) (*models.TemplateTestResult, error) {
+	template, err := s.getTemplate(ctx, s.queries, name, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	number := req.Version
+	if number == 0 {
+		number = template.LatestVersion
+	}
+	version, err := s.getVersion(ctx, s.queries, template.ID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]interface{}, len(req.Data)+len(version.Variables))
+	for key, value := range req.Data {
+		data[key] = value
+	}
+	for _, variable := range version.Variables {
+		if _, ok := data[variable]; !ok {
+			data[variable] = "[" + variable + "]"
+		}
+	}
+
+	return s.manager.PreviewTemplate(convertToEmailTemplate(template.Name, template.Locale, version), data), nil
+}
+
+// DiffVersions compares the content of two versions of a template line by line
+func (s *EmailTemplateService) DiffVersions(ctx context.Context, name, locale string, from, to int32) (*models.EmailTemplateDiffResponse, error) {
+	template, err := s.getTemplate(ctx, s.queries, name, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	fromVersion, err := s.getVersion(ctx, s.queries, template.ID, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.getVersion(ctx, s.queries, template.ID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EmailTemplateDiffResponse{
+		Name:             template.Name,
+		Locale:           template.Locale,
+		FromVersion:      from,
+		ToVersion:        to,
+		Subject:          diffLines(fromVersion.Subject, toVersion.Subject),
+		Body:             diffLines(fromVersion.Body, toVersion.Body),
+		IsHTMLChanged:    fromVersion.IsHtml != toVersion.IsHtml,
+		AddedVariables:   missingFrom(toVersion.Variables, fromVersion.Variables),
+		RemovedVariables: missingFrom(fromVersion.Variables, toVersion.Variables),
+	}, nil
+}
+
+// ResolveTemplate returns the published content of a stored template in exactly
+// the locale given, or ErrTemplateNotFound if the template is missing or has
+// nothing published. Falling back to other locales is left to the caller.
+func (s *EmailTemplateService) ResolveTemplate(ctx context.Context, name, locale string) (*models.EmailTemplate, error) {
+	version, err := s.queries.GetPublishedEmailTemplateVersion(ctx, queries.GetPublishedEmailTemplateVersionParams{
+		Name:   name,
+		Locale: locale,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to resolve email template: %w", err)
+	}
+	return convertToEmailTemplate(name, locale, version), nil
+}
+
+// SeedDefaults stores the built-in templates in every locale as published
+// version 1 if they are not in the database yet. Templates already stored are
+// left alone, so edits made through the API survive restarts.
+func (s *EmailTemplateService) SeedDefaults(ctx context.Context) error {
+	for _, locale := range models.SupportedLocales {
+		names := make([]string, 0, len(builtInTemplates[locale]))
+		for name := range builtInTemplates[locale] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if err := s.seedTemplate(ctx, GetBuiltInTemplate(name, locale)); err != nil {
+				return fmt.Errorf("failed to seed email template %s (%s): %w", name, locale, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *EmailTemplateService) seedTemplate(ctx context.Context, builtIn *models.EmailTemplate) error {
+	note := "Built-in template"
+	return s.queries.ExecTx(ctx, func(q EmailTemplateQuerier) error {
+		template, err := q.CreateEmailTemplate(ctx, queries.CreateEmailTemplateParams{
+			Name:   builtIn.Name,
+			Locale: builtIn.Locale,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+				return nil
+			}
+			return fmt.Errorf("failed to create email template: %w", err)
+		}
+
+		_, err = s.addVersion(ctx, q, template.ID, models.EmailTemplateVersionRequest{
+			Subject:    builtIn.Subject,
+			Body:       builtIn.Body,
+			IsHTML:     builtIn.IsHTML,
+			Variables:  builtIn.Variables,
+			ChangeNote: &note,
+			Publish:    true,
+		}, 0)
+		return err
+	})
+}
+
+// validate checks template content with the same rules the template manager uses
+func (s *EmailTemplateService) validate(ctx context.Context, name string, req models.EmailTemplateVersionRequest) error {
+	err := s.manager.ValidateTemplate(ctx, &models.EmailTemplate{
+		Name:      name,
+		Subject:   req.Subject,
+		Body:      req.Body,
+		IsHTML:    req.IsHTML,
+		Variables: req.Variables,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return nil
+}
+
+func (s *EmailTemplateService) getTemplate(ctx context.Context, q EmailTemplateQuerier, name, locale string) (queries.EmailTemplate, error) {
+	template, err := q.GetEmailTemplateByName(ctx, queries.GetEmailTemplateByNameParams{
+		Name:   name,
+		Locale: locale,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return queries.EmailTemplate{}, ErrTemplateNotFound
+		}
+		return queries.EmailTemplate{}, fmt.Errorf("failed to get email template: %w", err)
+	}
+	return template, nil
+}
+
+func (s *EmailTemplateService) getVersion(ctx context.Context, q EmailTemplateQuerier, templateID, version int32) (queries.EmailTemplateVersion, error) {
+	templateVersion, err := q.GetEmailTemplateVersion(ctx, queries.GetEmailTemplateVersionParams{
+		TemplateID: templateID,
+		Version:    version,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return queries.EmailTemplateVersion{}, ErrTemplateVersionNotFound
+		}
+		return queries.EmailTemplateVersion{}, fmt.Errorf("failed to get email template version: %w", err)
+	}
+	return templateVersion, nil
+}
+
+// addVersion saves content as the template's next version, publishing it if asked
+func (s *EmailTemplateService) addVersion(ctx context.Context, q EmailTemplateQuerier, templateID int32, req models.EmailTemplateVersionRequest, userID int32) (queries.EmailTemplateVersion, error) {
+	template, err := q.NextEmailTemplateVersion(ctx, templateID)
+	if err != nil {
+		return queries.EmailTemplateVersion{}, fmt.Errorf("failed to allocate email template version: %w", err)
+	}
+
+	variables := req.Variables
+	if variables == nil {
+		variables = []string{}
+	}
+
+	version, err := q.CreateEmailTemplateVersion(ctx, queries.CreateEmailTemplateVersionParams{
+		TemplateID: templateID,
+		Version:    template.LatestVersion,
+		Subject:    req.Subject,
+		Body:       req.Body,
+		IsHtml:     req.IsHTML,
+		Variables:  variables,
+		ChangeNote: optionalText(req.ChangeNote),
+		CreatedBy:  optionalUserID(userID),
+	})
+	if err != nil {
+		return queries.EmailTemplateVersion{}, fmt.Errorf("failed to create email template version: %w", err)
+	}
+
+	if req.Publish {
+		return s.publish(ctx, q, version)
+	}
+	return version, nil
+}
+
+// publish archives the template's published version and publishes this one in its place
+func (s *EmailTemplateService) publish(ctx context.Context, q EmailTemplateQuerier, version queries.EmailTemplateVersion) (queries.EmailTemplateVersion, error) {
+	if err := q.ArchivePublishedEmailTemplateVersion(ctx, version.TemplateID); err != nil {
+		return queries.EmailTemplateVersion{}, fmt.Errorf("failed to archive published template version: %w", err)
+	}
+
+	published, err := q.PublishEmailTemplateVersion(ctx, version.ID)
+	if err != nil {
+		return queries.EmailTemplateVersion{}, fmt.Errorf("failed to publish email template version: %w", err)
+	}
+
+	_, err = q.SetEmailTemplatePublishedVersion(ctx, queries.SetEmailTemplatePublishedVersionParams{
+		ID:               version.TemplateID,
+		PublishedVersion: pgtype.Int4{Int32: published.Version, Valid: true},
+	})
+	if err != nil {
+		return queries.EmailTemplateVersion{}, fmt.Errorf("failed to set published template version: %w", err)
+	}
+	return published, nil
+}
+
+// optionalUserID records who made a change, leaving it empty for system changes
+func optionalUserID(userID int32) pgtype.Int4 {
+	return pgtype.Int4{Int32: userID, Valid: userID > 0}
+}
+
+// diffLines compares two texts line by line using their longest common subsequence
+func diffLines(from, to string) []models.TemplateDiffLine {
+	a := strings.Split(from, "\n")
+	b := strings.Split(to, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]models.TemplateDiffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, models.TemplateDiffLine{Op: models.TemplateDiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, models.TemplateDiffLine{Op: models.TemplateDiffRemoved, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, models.TemplateDiffLine{Op: models.TemplateDiffAdded, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, models.TemplateDiffLine{Op: models.TemplateDiffRemoved, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, models.TemplateDiffLine{Op: models.TemplateDiffAdded, Text: b[j]})
+	}
+	return lines
+}
+
+// missingFrom returns the values in list that are not in other
+func missingFrom(list, other []string) []string {
+	seen := make(map[string]bool, len(other))
+	for _, value := range other {
+		seen[value] = true
+	}
+
+	missing := []string{}
+	for _, value := range list {
+		if !seen[value] {
+			missing = append(missing, value)
+		}
+	}
+	return missing
+}
+
+func convertToEmailTemplate(name, locale string, version queries.EmailTemplateVersion) *models.EmailTemplate {
+	return &models.EmailTemplate{
+		ID:        version.TemplateID,
+		Name:      name,
+		Locale:    locale,
+		Subject:   version.Subject,
+		Body:      version.Body,
+		IsHTML:    version.IsHtml,
+		Variables: version.Variables,
+		IsActive:  true,
+		CreatedAt: version.CreatedAt.Time,
+		UpdatedAt: version.PublishedAt.Time,
+	}
+}
+
+func convertToEmailTemplateResponse(template queries.EmailTemplate) models.EmailTemplateResponse {
+	response := models.EmailTemplateResponse{
+		ID:            template.ID,
+		Name:          template.Name,
+		Locale:        template.Locale,
+		LatestVersion: template.LatestVersion,
+		CreatedAt:     template.CreatedAt.Time,
+		UpdatedAt:     template.UpdatedAt.Time,
+	}
+	if template.Description.Valid {
+		response.Description = &template.Description.String
+	}
+	if template.PublishedVersion.Valid {
+		response.PublishedVersion = &template.PublishedVersion.Int32
+	}
+	if template.CreatedBy.Valid {
+		response.CreatedBy = &template.CreatedBy.Int32
+	}
+	return response
+}
+
+func convertToEmailTemplateVersionResponse(version queries.EmailTemplateVersion) models.EmailTemplateVersionResponse {
+	response := models.EmailTemplateVersionResponse{
+		ID:        version.ID,
+		Version:   version.Version,
+		Subject:   version.Subject,
+		Body:      version.Body,
+		IsHTML:    version.IsHtml,
+		Variables: version.Variables,
+		Status:    version.Status,
+		CreatedAt: version.CreatedAt.Time,
+	}
+	if version.ChangeNote.Valid {
+		response.ChangeNote = &version.ChangeNote.String
+	}
+	if version.CreatedBy.Valid {
+		response.CreatedBy = &version.CreatedBy.Int32
+	}
+	if version.PublishedAt.Valid {
+		response.PublishedAt = &version.PublishedAt.Time
+	}
+	return response
+}
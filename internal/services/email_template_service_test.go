@@ -0,0 +1,440 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockEmailTemplateQuerier is a mock implementation of EmailTemplateQuerier
+type MockEmailTemplateQuerier struct {
+	mock.Mock
+}
+
+func (m *MockEmailTemplateQuerier) CreateEmailTemplate(ctx context.Context, arg queries.CreateEmailTemplateParams) (queries.EmailTemplate, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.EmailTemplate), args.Error(1)
+}
+
+func (m *MockEmailTemplateQuerier) GetEmailTemplateByName(ctx context.Context, arg queries.GetEmailTemplateByNameParams) (queries.EmailTemplate, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.EmailTemplate), args.Error(1)
+}
+
+func (m *MockEmailTemplateQuerier) ListEmailTemplates(ctx context.Context) ([]queries.EmailTemplate, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]queries.EmailTemplate), args.Error(1)
+}
+
+func (m *MockEmailTemplateQuerier) NextEmailTemplateVersion(ctx context.Context, id int32) (queries.EmailTemplate, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.EmailTemplate), args.Error(1)
+}
+
+func (m *MockEmailTemplateQuerier) SetEmailTemplatePublishedVersion(ctx context.Context, arg queries.SetEmailTemplatePublishedVersionParams) (queries.EmailTemplate, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.EmailTemplate), args.Error(1)
+}
+
+func (m *MockEmailTemplateQuerier) DeleteEmailTemplate(ctx context.Context, id int32) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockEmailTemplateQuerier) CreateEmailTemplateVersion(ctx context.Context, arg queries.CreateEmailTemplateVersionParams) (queries.EmailTemplateVersion, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.EmailTemplateVersion), args.Error(1)
+}
+
+func (m *MockEmailTemplateQuerier) GetEmailTemplateVersion(ctx context.Context, arg queries.GetEmailTemplateVersionParams) (queries.EmailTemplateVersion, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.EmailTemplateVersion), args.Error(1)
+}
+
+func (m *MockEmailTemplateQuerier) ListEmailTemplateVersions(ctx context.Context, templateID int32) ([]queries.EmailTemplateVersion, error) {
+	args := m.Called(ctx, templateID)
+	return args.Get(0).([]queries.EmailTemplateVersion), args.Error(1)
+}
+
+func (m *MockEmailTemplateQuerier) ListPublishedEmailTemplateVersions(ctx context.Context) ([]queries.EmailTemplateVersion, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]queries.EmailTemplateVersion), args.Error(1)
+}
+
+func (m *MockEmailTemplateQuerier) GetPublishedEmailTemplateVersion(ctx context.Context, arg queries.GetPublishedEmailTemplateVersionParams) (queries.EmailTemplateVersion, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.EmailTemplateVersion), args.Error(1)
+}
+
+func (m *MockEmailTemplateQuerier) ArchivePublishedEmailTemplateVersion(ctx context.Context, templateID int32) error {
+	args := m.Called(ctx, templateID)
+	return args.Error(0)
+}
+
+func (m *MockEmailTemplateQuerier) PublishEmailTemplateVersion(ctx context.Context, id int32) (queries.EmailTemplateVersion, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.EmailTemplateVersion), args.Error(1)
+}
+
+// ExecTx runs fn directly against the mock; transactional behaviour is covered by integration tests
+func (m *MockEmailTemplateQuerier) ExecTx(ctx context.Context, fn func(EmailTemplateQuerier) error) error {
+	return fn(m)
+}
+
+func createTestEmailTemplateService() (*EmailTemplateService, *MockEmailTemplateQuerier) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mockQuerier := &MockEmailTemplateQuerier{}
+	return NewEmailTemplateService(mockQuerier, NewEmailTemplateManager(logger), logger), mockQuerier
+}
+
+// welcomeEn looks up the English "welcome" template most tests work with
+var welcomeEn = queries.GetEmailTemplateByNameParams{Name: "welcome", Locale: "en"}
+
+func createTestStoredTemplate(latest int32, published int32) queries.EmailTemplate {
+	template := queries.EmailTemplate{ID: 4, Name: "welcome", Locale: "en", LatestVersion: latest}
+	if published > 0 {
+		template.PublishedVersion = pgtype.Int4{Int32: published, Valid: true}
+	}
+	return template
+}
+
+func createTestTemplateVersion(version int32, status string, body string) queries.EmailTemplateVersion {
+	return queries.EmailTemplateVersion{
+		ID:         10 + version,
+		TemplateID: 4,
+		Version:    version,
+		Subject:    "Welcome {{.StudentName}}",
+		Body:       body,
+		Variables:  []string{"StudentName"},
+		Status:     status,
+	}
+}
+
+func TestEmailTemplateService_CreateTemplate(t *testing.T) {
+	ctx := context.Background()
+	req := models.CreateEmailTemplateRequest{
+		Name: "welcome",
+		EmailTemplateVersionRequest: models.EmailTemplateVersionRequest{
+			Subject:   "Welcome {{.StudentName}}",
+			Body:      "Hello {{.StudentName}}",
+			Variables: []string{"StudentName"},
+			Publish:   true,
+		},
+	}
+
+	t.Run("saves version 1 and publishes it", func(t *testing.T) {
+		service, mockQuerier := createTestEmailTemplateService()
+		version := createTestTemplateVersion(1, models.TemplateVersionDraft, "Hello {{.StudentName}}")
+		published := version
+		published.Status = models.TemplateVersionPublished
+
+		mockQuerier.On("CreateEmailTemplate", ctx, queries.CreateEmailTemplateParams{
+			Name:      "welcome",
+			Locale:    "en",
+			CreatedBy: pgtype.Int4{Int32: 9, Valid: true},
+		}).Return(createTestStoredTemplate(0, 0), nil)
+		mockQuerier.On("NextEmailTemplateVersion", ctx, int32(4)).Return(createTestStoredTemplate(1, 0), nil)
+		mockQuerier.On("CreateEmailTemplateVersion", ctx, queries.CreateEmailTemplateVersionParams{
+			TemplateID: 4,
+			Version:    1,
+			Subject:    "Welcome {{.StudentName}}",
+			Body:       "Hello {{.StudentName}}",
+			Variables:  []string{"StudentName"},
+			CreatedBy:  pgtype.Int4{Int32: 9, Valid: true},
+		}).Return(version, nil)
+		mockQuerier.On("ArchivePublishedEmailTemplateVersion", ctx, int32(4)).Return(nil)
+		mockQuerier.On("PublishEmailTemplateVersion", ctx, version.ID).Return(published, nil)
+		mockQuerier.On("SetEmailTemplatePublishedVersion", ctx, queries.SetEmailTemplatePublishedVersionParams{
+			ID:               4,
+			PublishedVersion: pgtype.Int4{Int32: 1, Valid: true},
+		}).Return(createTestStoredTemplate(1, 1), nil)
+
+		template, err := service.CreateTemplate(ctx, req, 9)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), template.LatestVersion)
+		require.NotNil(t, template.PublishedVersion)
+		assert.Equal(t, int32(1), *template.PublishedVersion)
+		require.NotNil(t, template.Published)
+		assert.Equal(t, models.TemplateVersionPublished, template.Published.Status)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("name taken", func(t *testing.T) {
+		service, mockQuerier := createTestEmailTemplateService()
+
+		mockQuerier.On("CreateEmailTemplate", ctx, mock.Anything).Return(queries.EmailTemplate{}, pgx.ErrNoRows)
+
+		_, err := service.CreateTemplate(ctx, req, 9)
+
+		assert.ErrorIs(t, err, ErrTemplateExists)
+		mockQuerier.AssertNotCalled(t, "CreateEmailTemplateVersion", mock.Anything, mock.Anything)
+	})
+
+	t.Run("undeclared variable", func(t *testing.T) {
+		service, mockQuerier := createTestEmailTemplateService()
+		invalid := req
+		invalid.Body = "Hello {{.StudentName}}, your card {{.CardNumber}} is ready"
+
+		_, err := service.CreateTemplate(ctx, invalid, 9)
+
+		assert.ErrorIs(t, err, ErrInvalidTemplate)
+		assert.Contains(t, err.Error(), "CardNumber")
+		mockQuerier.AssertNotCalled(t, "CreateEmailTemplate", mock.Anything, mock.Anything)
+	})
+}
+
+func TestEmailTemplateService_CreateVersion(t *testing.T) {
+	ctx := context.Background()
+	service, mockQuerier := createTestEmailTemplateService()
+	draft := createTestTemplateVersion(3, models.TemplateVersionDraft, "Hi {{.StudentName}}")
+
+	mockQuerier.On("GetEmailTemplateByName", ctx, welcomeEn).Return(createTestStoredTemplate(2, 2), nil)
+	mockQuerier.On("NextEmailTemplateVersion", ctx, int32(4)).Return(createTestStoredTemplate(3, 2), nil)
+	mockQuerier.On("CreateEmailTemplateVersion", ctx, mock.MatchedBy(func(arg queries.CreateEmailTemplateVersionParams) bool {
+		return arg.Version == 3 && arg.Body == "Hi {{.StudentName}}"
+	})).Return(draft, nil)
+
+	version, err := service.CreateVersion(ctx, "welcome", "en", models.EmailTemplateVersionRequest{
+		Subject:   "Welcome {{.StudentName}}",
+		Body:      "Hi {{.StudentName}}",
+		Variables: []string{"StudentName"},
+	}, 9)
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), version.Version)
+	assert.Equal(t, models.TemplateVersionDraft, version.Status)
+	mockQuerier.AssertNotCalled(t, "PublishEmailTemplateVersion", mock.Anything, mock.Anything)
+	mockQuerier.AssertExpectations(t)
+}
+
+func TestEmailTemplateService_PublishVersion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("archives the old version", func(t *testing.T) {
+		service, mockQuerier := createTestEmailTemplateService()
+		draft := createTestTemplateVersion(3, models.TemplateVersionDraft, "Hi {{.StudentName}}")
+		published := draft
+		published.Status = models.TemplateVersionPublished
+
+		mockQuerier.On("GetEmailTemplateByName", ctx, welcomeEn).Return(createTestStoredTemplate(3, 2), nil)
+		mockQuerier.On("GetEmailTemplateVersion", ctx, queries.GetEmailTemplateVersionParams{TemplateID: 4, Version: 3}).Return(draft, nil)
+		mockQuerier.On("ArchivePublishedEmailTemplateVersion", ctx, int32(4)).Return(nil)
+		mockQuerier.On("PublishEmailTemplateVersion", ctx, draft.ID).Return(published, nil)
+		mockQuerier.On("SetEmailTemplatePublishedVersion", ctx, queries.SetEmailTemplatePublishedVersionParams{
+			ID:               4,
+			PublishedVersion: pgtype.Int4{Int32: 3, Valid: true},
+		}).Return(createTestStoredTemplate(3, 3), nil)
+
+		version, err := service.PublishVersion(ctx, "welcome", "en", 3)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.TemplateVersionPublished, version.Status)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		service, mockQuerier := createTestEmailTemplateService()
+
+		mockQuerier.On("GetEmailTemplateByName", ctx, welcomeEn).Return(createTestStoredTemplate(3, 2), nil)
+		mockQuerier.On("GetEmailTemplateVersion", ctx, mock.Anything).Return(queries.EmailTemplateVersion{}, pgx.ErrNoRows)
+
+		_, err := service.PublishVersion(ctx, "welcome", "en", 7)
+
+		assert.ErrorIs(t, err, ErrTemplateVersionNotFound)
+	})
+}
+
+func TestEmailTemplateService_RollbackToVersion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("publishes the old content as a new version", func(t *testing.T) {
+		service, mockQuerier := createTestEmailTemplateService()
+		old := createTestTemplateVersion(1, models.TemplateVersionArchived, "Hello {{.StudentName}}")
+		rolledBack := createTestTemplateVersion(4, models.TemplateVersionDraft, "Hello {{.StudentName}}")
+		published := rolledBack
+		published.Status = models.TemplateVersionPublished
+
+		mockQuerier.On("GetEmailTemplateByName", ctx, welcomeEn).Return(createTestStoredTemplate(3, 3), nil)
+		mockQuerier.On("GetEmailTemplateVersion", ctx, queries.GetEmailTemplateVersionParams{TemplateID: 4, Version: 1}).Return(old, nil)
+		mockQuerier.On("NextEmailTemplateVersion", ctx, int32(4)).Return(createTestStoredTemplate(4, 3), nil)
+		mockQuerier.On("CreateEmailTemplateVersion", ctx, queries.CreateEmailTemplateVersionParams{
+			TemplateID: 4,
+			Version:    4,
+			Subject:    old.Subject,
+			Body:       old.Body,
+			Variables:  old.Variables,
+			ChangeNote: pgtype.Text{String: "Rolled back to version 1", Valid: true},
+			CreatedBy:  pgtype.Int4{Int32: 9, Valid: true},
+		}).Return(rolledBack, nil)
+		mockQuerier.On("ArchivePublishedEmailTemplateVersion", ctx, int32(4)).Return(nil)
+		mockQuerier.On("PublishEmailTemplateVersion", ctx, rolledBack.ID).Return(published, nil)
+		mockQuerier.On("SetEmailTemplatePublishedVersion", ctx, mock.Anything).Return(createTestStoredTemplate(4, 4), nil)
+
+		version, err := service.RollbackToVersion(ctx, "welcome", "en", 1, 9)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), version.Version)
+		assert.Equal(t, models.TemplateVersionPublished, version.Status)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("version already published", func(t *testing.T) {
+		service, mockQuerier := createTestEmailTemplateService()
+
+		mockQuerier.On("GetEmailTemplateByName", ctx, welcomeEn).Return(createTestStoredTemplate(3, 3), nil)
+		mockQuerier.On("GetEmailTemplateVersion", ctx, mock.Anything).
+			Return(createTestTemplateVersion(3, models.TemplateVersionPublished, "Hi"), nil)
+
+		_, err := service.RollbackToVersion(ctx, "welcome", "en", 3, 9)
+
+		assert.ErrorIs(t, err, ErrTemplateVersionPublished)
+		mockQuerier.AssertNotCalled(t, "NextEmailTemplateVersion", mock.Anything, mock.Anything)
+	})
+}
+
+func TestEmailTemplateService_DeleteTemplate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("built-in template", func(t *testing.T) {
+		service, mockQuerier := createTestEmailTemplateService()
+
+		err := service.DeleteTemplate(ctx, "overdue_reminder", "sw")
+
+		assert.ErrorIs(t, err, ErrDefaultTemplate)
+		mockQuerier.AssertNotCalled(t, "DeleteEmailTemplate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("custom template", func(t *testing.T) {
+		service, mockQuerier := createTestEmailTemplateService()
+
+		mockQuerier.On("GetEmailTemplateByName", ctx, welcomeEn).Return(createTestStoredTemplate(1, 1), nil)
+		mockQuerier.On("DeleteEmailTemplate", ctx, int32(4)).Return(nil)
+
+		err := service.DeleteTemplate(ctx, "welcome", "en")
+
+		require.NoError(t, err)
+		mockQuerier.AssertExpectations(t)
+	})
+}
+
+func TestEmailTemplateService_PreviewTemplate(t *testing.T) {
+	ctx := context.Background()
+	service, mockQuerier := createTestEmailTemplateService()
+	version := createTestTemplateVersion(2, models.TemplateVersionDraft, "Hello {{.StudentName}}")
+	version.Variables = []string{"StudentName", "LibraryName"}
+	version.Body = "Hello {{.StudentName}}, welcome to {{.LibraryName}}"
+
+	mockQuerier.On("GetEmailTemplateByName", ctx, welcomeEn).Return(createTestStoredTemplate(2, 1), nil)
+	mockQuerier.On("GetEmailTemplateVersion", ctx, queries.GetEmailTemplateVersionParams{TemplateID: 4, Version: 2}).Return(version, nil)
+
+	result, err := service.PreviewTemplate(ctx, "welcome", "en", models.PreviewEmailTemplateRequest{
+		Data: map[string]interface{}{"StudentName": "Amina"},
+	})
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "Welcome Amina", result.ProcessedSubject)
+	assert.Equal(t, "Hello Amina, welcome to [LibraryName]", result.ProcessedBody)
+}
+
+func TestEmailTemplateService_DiffVersions(t *testing.T) {
+	ctx := context.Background()
+	service, mockQuerier := createTestEmailTemplateService()
+	from := createTestTemplateVersion(1, models.TemplateVersionArchived, "Hello {{.StudentName}}\nSee you soon")
+	to := createTestTemplateVersion(2, models.TemplateVersionPublished, "Hello {{.StudentName}}\nYour card is {{.CardNumber}}\nSee you soon")
+	to.Variables = []string{"StudentName", "CardNumber"}
+
+	mockQuerier.On("GetEmailTemplateByName", ctx, welcomeEn).Return(createTestStoredTemplate(2, 2), nil)
+	mockQuerier.On("GetEmailTemplateVersion", ctx, queries.GetEmailTemplateVersionParams{TemplateID: 4, Version: 1}).Return(from, nil)
+	mockQuerier.On("GetEmailTemplateVersion", ctx, queries.GetEmailTemplateVersionParams{TemplateID: 4, Version: 2}).Return(to, nil)
+
+	diff, err := service.DiffVersions(ctx, "welcome", "en", 1, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.TemplateDiffLine{{Op: models.TemplateDiffEqual, Text: "Welcome {{.StudentName}}"}}, diff.Subject)
+	assert.Equal(t, []models.TemplateDiffLine{
+		{Op: models.TemplateDiffEqual, Text: "Hello {{.StudentName}}"},
+		{Op: models.TemplateDiffAdded, Text: "Your card is {{.CardNumber}}"},
+		{Op: models.TemplateDiffEqual, Text: "See you soon"},
+	}, diff.Body)
+	assert.Equal(t, []string{"CardNumber"}, diff.AddedVariables)
+	assert.Empty(t, diff.RemovedVariables)
+}
+
+func TestEmailTemplateService_ResolveTemplate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("published version", func(t *testing.T) {
+		service, mockQuerier := createTestEmailTemplateService()
+
+		mockQuerier.On("GetPublishedEmailTemplateVersion", ctx, queries.GetPublishedEmailTemplateVersionParams{Name: "welcome", Locale: "sw"}).
+			Return(createTestTemplateVersion(2, models.TemplateVersionPublished, "Hi {{.StudentName}}"), nil)
+
+		template, err := service.ResolveTemplate(ctx, "welcome", "sw")
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), template.ID)
+		assert.Equal(t, "sw", template.Locale)
+		assert.Equal(t, "Hi {{.StudentName}}", template.Body)
+		assert.True(t, template.IsActive)
+	})
+
+	t.Run("nothing published", func(t *testing.T) {
+		service, mockQuerier := createTestEmailTemplateService()
+
+		mockQuerier.On("GetPublishedEmailTemplateVersion", ctx, queries.GetPublishedEmailTemplateVersionParams{Name: "welcome", Locale: "sw"}).Return(queries.EmailTemplateVersion{}, pgx.ErrNoRows)
+
+		_, err := service.ResolveTemplate(ctx, "welcome", "sw")
+
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+	})
+}
+
+func TestEmailTemplateService_SeedDefaults(t *testing.T) {
+	ctx := context.Background()
+	service, mockQuerier := createTestEmailTemplateService()
+
+	// Only the Swahili fine_notice is missing from the store
+	mockQuerier.On("CreateEmailTemplate", ctx, mock.MatchedBy(func(arg queries.CreateEmailTemplateParams) bool {
+		return arg.Name != "fine_notice" || arg.Locale != "sw"
+	})).Return(queries.EmailTemplate{}, pgx.ErrNoRows)
+	mockQuerier.On("CreateEmailTemplate", ctx, queries.CreateEmailTemplateParams{Name: "fine_notice", Locale: "sw"}).
+		Return(queries.EmailTemplate{ID: 8, Name: "fine_notice", Locale: "sw"}, nil)
+	mockQuerier.On("NextEmailTemplateVersion", ctx, int32(8)).Return(queries.EmailTemplate{ID: 8, LatestVersion: 1}, nil)
+	mockQuerier.On("CreateEmailTemplateVersion", ctx, mock.MatchedBy(func(arg queries.CreateEmailTemplateVersionParams) bool {
+		return arg.TemplateID == 8 && arg.Version == 1 && arg.Subject == GetBuiltInTemplate("fine_notice", "sw").Subject && !arg.CreatedBy.Valid
+	})).Return(queries.EmailTemplateVersion{ID: 20, TemplateID: 8, Version: 1}, nil)
+	mockQuerier.On("ArchivePublishedEmailTemplateVersion", ctx, int32(8)).Return(nil)
+	mockQuerier.On("PublishEmailTemplateVersion", ctx, int32(20)).Return(queries.EmailTemplateVersion{ID: 20, TemplateID: 8, Version: 1}, nil)
+	mockQuerier.On("SetEmailTemplatePublishedVersion", ctx, mock.Anything).Return(queries.EmailTemplate{ID: 8}, nil)
+
+	err := service.SeedDefaults(ctx)
+
+	require.NoError(t, err)
+	mockQuerier.AssertNumberOfCalls(t, "CreateEmailTemplate", 20)
+	mockQuerier.AssertNumberOfCalls(t, "CreateEmailTemplateVersion", 1)
+	mockQuerier.AssertExpectations(t)
+
+	t.Run("database error", func(t *testing.T) {
+		service, mockQuerier := createTestEmailTemplateService()
+
+		mockQuerier.On("CreateEmailTemplate", ctx, mock.Anything).Return(queries.EmailTemplate{}, fmt.Errorf("connection refused"))
+
+		err := service.SeedDefaults(ctx)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "connection refused")
+	})
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+)
+
+// EmailTemplateStore provides the email template queries backed by a connection
+// pool and the ability to run several of them inside one database transaction
+type EmailTemplateStore struct {
+	*queries.Queries
+	pool *pgxpool.Pool
+}
+
+// NewEmailTemplateStore creates a new email template store for the given pool
+func NewEmailTemplateStore(pool *pgxpool.Pool) *EmailTemplateStore {
+	return &EmailTemplateStore{
+		Queries: queries.New(pool),
+		pool:    pool,
+	}
+}
+
+// ExecTx runs fn inside a database transaction, committing only if fn succeeds.
+// Calls made on a store that is already bound to a transaction reuse it.
+func (s *EmailTemplateStore) ExecTx(ctx context.Context, fn func(EmailTemplateQuerier) error) error {
+	if s.pool == nil {
+		return fn(s)
+	}
+
+	return runInTx(ctx, s.pool, func(tx pgx.Tx) error {
+		return fn(&EmailTemplateStore{Queries: s.Queries.WithTx(tx)})
+	})
+}
@@ -104,13 +104,13 @@ func TestEmailService_BuildMessage(t *testing.T) {
 	service := createTestEmailService()
 
 	t.Run("plain text message", func(t *testing.T) {
-		from := "library@example.com"
 		to := "user@example.com"
 		subject := "Test Subject"
 		body := "This is a test message"
-		isHTML := false
 
-		message := service.buildMessage(from, to, subject, body, isHTML)
+		raw, err := service.buildMessage(&EmailMessage{To: to, Subject: subject, TextBody: body}, "1.abc@example.com", time.Now())
+		require.NoError(t, err)
+		message := string(raw)
 
 		assert.Contains(t, message, "From: Library System <library@example.com>")
 		assert.Contains(t, message, "To: user@example.com")
@@ -120,13 +120,13 @@ func TestEmailService_BuildMessage(t *testing.T) {
 	})
 
 	t.Run("HTML message", func(t *testing.T) {
-		from := "library@example.com"
 		to := "user@example.com"
 		subject := "Test Subject"
 		body := "<p>This is a <strong>test</strong> message</p>"
-		isHTML := true
 
-		message := service.buildMessage(from, to, subject, body, isHTML)
+		raw, err := service.buildMessage(&EmailMessage{To: to, Subject: subject, HTMLBody: body}, "1.abc@example.com", time.Now())
+		require.NoError(t, err)
+		message := string(raw)
 
 		assert.Contains(t, message, "From: Library System <library@example.com>")
 		assert.Contains(t, message, "To: user@example.com")
@@ -434,6 +434,32 @@ func TestGetDefaultTemplate(t *testing.T) {
 	})
 }
 
+func TestGetBuiltInTemplate(t *testing.T) {
+	t.Run("every template has a Swahili version with the same variables", func(t *testing.T) {
+		for name := range defaultTemplates {
+			english := GetBuiltInTemplate(name, models.LocaleEnglish)
+			swahili := GetBuiltInTemplate(name, models.LocaleSwahili)
+
+			require.NotNil(t, swahili, name)
+			assert.Equal(t, models.LocaleSwahili, swahili.Locale)
+			assert.ElementsMatch(t, english.Variables, swahili.Variables, name)
+			assert.NotEqual(t, english.Body, swahili.Body, name)
+		}
+	})
+
+	t.Run("get Swahili due_soon template", func(t *testing.T) {
+		template := GetBuiltInTemplate("due_soon", models.LocaleSwahili)
+
+		require.NotNil(t, template)
+		assert.Contains(t, template.Subject, "Kitabu Kinakaribia Kurudishwa")
+		assert.Contains(t, template.Body, "Mpendwa {{.StudentName}}")
+	})
+
+	t.Run("unsupported locale", func(t *testing.T) {
+		assert.Nil(t, GetBuiltInTemplate("due_soon", "fr"))
+	})
+}
+
 func TestEmailRequest_Validation(t *testing.T) {
 	t.Run("valid email request", func(t *testing.T) {
 		req := EmailRequest{
@@ -543,7 +569,9 @@ func TestEmailService_Integration(t *testing.T) {
 		assert.Contains(t, body, "$5.00")
 
 		// Build message
-		message := service.buildMessage(service.config.FromEmail, "user@example.com", subject, body, template.IsHTML)
+		raw, err := service.buildMessage(&EmailMessage{To: "user@example.com", Subject: subject, TextBody: body}, "1.abc@example.com", time.Now())
+		require.NoError(t, err)
+		message := string(raw)
 		assert.Contains(t, message, "From: Library System")
 		assert.Contains(t, message, "To: user@example.com")
 		assert.Contains(t, message, "Subject: Book Overdue - The Great Gatsby")
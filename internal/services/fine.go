@@ -0,0 +1,635 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// FineQuerier defines the interface for fine ledger database operations
+type FineQuerier interface {
+	CreateFine(ctx context.Context, arg queries.CreateFineParams) (queries.Fine, error)
+	GetFineByID(ctx context.Context, id int32) (queries.Fine, error)
+	GetFineByIDForUpdate(ctx context.Context, id int32) (queries.Fine, error)
+	ListFinesByStudent(ctx context.Context, studentID int32) ([]queries.Fine, error)
+	ListFinesByTransaction(ctx context.Context, transactionID pgtype.Int4) ([]queries.Fine, error)
+	ListOutstandingFinesByStudent(ctx context.Context, studentID int32) ([]queries.Fine, error)
+	UpdateFineTotals(ctx context.Context, arg queries.UpdateFineTotalsParams) (queries.Fine, error)
+	GetStudentFineBalance(ctx context.Context, studentID int32) (queries.GetStudentFineBalanceRow, error)
+	NextFineReceiptNumber(ctx context.Context) (int64, error)
+	CreateFineLedgerEntry(ctx context.Context, arg queries.CreateFineLedgerEntryParams) (queries.FineLedgerEntry, error)
+	ListFineLedgerEntriesByFine(ctx context.Context, fineID int32) ([]queries.FineLedgerEntry, error)
+	GetFineLedgerEntryByReceipt(ctx context.Context, receiptNumber pgtype.Text) (queries.FineLedgerEntry, error)
+	ListFineLedgerEntriesBetween(ctx context.Context, arg queries.ListFineLedgerEntriesBetweenParams) ([]queries.ListFineLedgerEntriesBetweenRow, error)
+	SetTransactionFinePaid(ctx context.Context, arg queries.SetTransactionFinePaidParams) error
+	// Mobile payment requests
+	CreateFinePaymentRequest(ctx context.Context, arg queries.CreateFinePaymentRequestParams) (queries.FinePaymentRequest, error)
+	GetFinePaymentRequestByID(ctx context.Context, id int32) (queries.FinePaymentRequest, error)
+	GetFinePaymentRequestByCheckoutIDForUpdate(ctx context.Context, checkoutRequestID pgtype.Text) (queries.FinePaymentRequest, error)
+	SetFinePaymentRequestCheckout(ctx context.Context, arg queries.SetFinePaymentRequestCheckoutParams) (queries.FinePaymentRequest, error)
+	CompleteFinePaymentRequest(ctx context.Context, arg queries.CompleteFinePaymentRequestParams) (queries.FinePaymentRequest, error)
+	GetStudentByID(ctx context.Context, id int32) (queries.Student, error)
+	CreateAuditLog(ctx context.Context, arg queries.CreateAuditLogParams) error
+	// ExecTx runs fn inside a single database transaction, committing only if fn succeeds
+	ExecTx(ctx context.Context, fn func(FineQuerier) error) error
+}
+
+// FineServiceInterface defines the interface for fine ledger service operations
+type FineServiceInterface interface {
+	AssessFine(ctx context.Context, req models.CreateFineRequest, actor AuditActor) (*models.FineResponse, error)
+	GetFine(ctx context.Context, id int32) (*models.FineResponse, error)
+	GetFineLedger(ctx context.Context, id int32) ([]models.FineLedgerEntryResponse, error)
+	GetStudentBalance(ctx context.Context, studentID int32) (*models.StudentFineBalanceResponse, error)
+	RecordPayment(ctx context.Context, fineID int32, req models.RecordFinePaymentRequest, actor AuditActor) (*models.FineLedgerResultResponse, error)
+	WaiveFine(ctx context.Context, fineID int32, req models.WaiveFineRequest, actor AuditActor) (*models.FineLedgerResultResponse, error)
+	RefundPayment(ctx context.Context, fineID int32, req models.RefundFineRequest, actor AuditActor) (*models.FineLedgerResultResponse, error)
+	AdjustFine(ctx context.Context, fineID int32, req models.AdjustFineRequest, actor AuditActor) (*models.FineLedgerResultResponse, error)
+	GetReceipt(ctx context.Context, receiptNumber string) (*models.FineLedgerEntryResponse, error)
+	GetCashReconciliation(ctx context.Context, date time.Time) (*models.CashReconciliationResponse, error)
+	InitiateMobilePayment(ctx context.Context, studentID int32, req models.InitiateMobilePaymentRequest, actor AuditActor) (*models.FinePaymentRequestResponse, error)
+	HandlePaymentCallback(ctx context.Context, token string, body []byte) (*models.FinePaymentRequestResponse, error)
+	GetPaymentRequest(ctx context.Context, id int32) (*models.FinePaymentRequestResponse, error)
+}
+
+// FineService manages fines and the ledger of money movements against them
+type FineService struct {
+	queries  FineQuerier
+	payments PaymentProvider
+}
+
+// NewFineService creates a new fine ledger service
+func NewFineService(queries FineQuerier) *FineService {
+	return &FineService{
+		queries: queries,
+	}
+}
+
+// WithPaymentProvider enables mobile payments through the given provider
+func (s *FineService) WithPaymentProvider(provider PaymentProvider) *FineService {
+	s.payments = provider
+	return s
+}
+
+// withQuerier returns a copy of the service bound to the given querier,
+// used to run the service logic against an open database transaction
+func (s *FineService) withQuerier(q FineQuerier) *FineService {
+	txService := *s
+	txService.queries = q
+	return &txService
+}
+
+// fineLedgerWriter is implemented by queriers that can record ledger entries
+// against a fine, so other services can settle or reverse charges in their own
+// database transactions
+type fineLedgerWriter interface {
+	auditLogWriter
+	NextFineReceiptNumber(ctx context.Context) (int64, error)
+	CreateFineLedgerEntry(ctx context.Context, arg queries.CreateFineLedgerEntryParams) (queries.FineLedgerEntry, error)
+	UpdateFineTotals(ctx context.Context, arg queries.UpdateFineTotalsParams) (queries.Fine, error)
+	ListFinesByTransaction(ctx context.Context, transactionID pgtype.Int4) ([]queries.Fine, error)
+	SetTransactionFinePaid(ctx context.Context, arg queries.SetTransactionFinePaidParams) error
+}
+
+// ledgerEntry describes a ledger entry about to be recorded
+type ledgerEntry struct {
+	entryType     models.FineLedgerEntryType
+	amount        decimal.Decimal
+	paymentMethod string
+	reference     *string
+	reason        string
+}
+
+// AssessFine charges a student a fine, for example for a damaged book
+func (s *FineService) AssessFine(ctx context.Context, req models.CreateFineRequest, actor AuditActor) (*models.FineResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	var response models.FineResponse
+	err := s.queries.ExecTx(ctx, func(q FineQuerier) error {
+		if _, err := q.GetStudentByID(ctx, req.StudentID); err != nil {
+			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+				return fmt.Errorf("student not found")
+			}
+			return fmt.Errorf("failed to get student: %w", err)
+		}
+
+		params := queries.CreateFineParams{
+			StudentID:   req.StudentID,
+			FineType:    req.FineType,
+			Description: optionalText(req.Description),
+			Amount:      decimalToNumeric(req.Amount),
+		}
+		if req.TransactionID != nil {
+			params.TransactionID = pgtype.Int4{Int32: *req.TransactionID, Valid: true}
+		}
+		if actor.UserID > 0 {
+			params.CreatedBy = pgtype.Int4{Int32: actor.UserID, Valid: true}
+		}
+
+		fine, err := q.CreateFine(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to create fine: %w", err)
+		}
+
+		response = convertToFineResponse(fine)
+		if err := writeAuditLog(ctx, q, actor, "fines", fine.ID, "CREATE", nil, response); err != nil {
+			return err
+		}
+
+		return syncTransactionFinePaid(ctx, q, fine.TransactionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetFine retrieves a fine by ID
+func (s *FineService) GetFine(ctx context.Context, id int32) (*models.FineResponse, error) {
+	fine, err := s.queries.GetFineByID(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("fine not found")
+		}
+		return nil, fmt.Errorf("failed to get fine: %w", err)
+	}
+
+	response := convertToFineResponse(fine)
+	return &response, nil
+}
+
+// GetFineLedger returns the ledger entries recorded against a fine, oldest first
+func (s *FineService) GetFineLedger(ctx context.Context, id int32) ([]models.FineLedgerEntryResponse, error) {
+	if _, err := s.GetFine(ctx, id); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.queries.ListFineLedgerEntriesByFine(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fine ledger: %w", err)
+	}
+
+	responses := make([]models.FineLedgerEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, convertToFineLedgerEntryResponse(entry))
+	}
+
+	return responses, nil
+}
+
+// GetStudentBalance returns a student's outstanding balance and fines
+func (s *FineService) GetStudentBalance(ctx context.Context, studentID int32) (*models.StudentFineBalanceResponse, error) {
+	if _, err := s.queries.GetStudentByID(ctx, studentID); err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("student not found")
+		}
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+
+	balance, err := s.queries.GetStudentFineBalance(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fine balance: %w", err)
+	}
+
+	fines, err := s.queries.ListFinesByStudent(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fines: %w", err)
+	}
+
+	response := &models.StudentFineBalanceResponse{
+		StudentID:        studentID,
+		Balance:          numericToDecimal(balance.Balance),
+		OutstandingFines: balance.OutstandingFines,
+		Fines:            make([]models.FineResponse, 0, len(fines)),
+	}
+	for _, fine := range fines {
+		response.Fines = append(response.Fines, convertToFineResponse(fine))
+	}
+
+	return response, nil
+}
+
+// RecordPayment records money taken against a fine and issues a receipt
+func (s *FineService) RecordPayment(ctx context.Context, fineID int32, req models.RecordFinePaymentRequest, actor AuditActor) (*models.FineLedgerResultResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	return s.recordEntry(ctx, fineID, ledgerEntry{
+		entryType:     models.FineLedgerEntryPayment,
+		amount:        req.Amount,
+		paymentMethod: req.PaymentMethod,
+		reference:     req.Reference,
+	}, actor)
+}
+
+// WaiveFine writes off part or all of a fine's outstanding balance
+func (s *FineService) WaiveFine(ctx context.Context, fineID int32, req models.WaiveFineRequest, actor AuditActor) (*models.FineLedgerResultResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	// A zero amount is resolved to the whole balance once the fine is locked
+	amount := decimal.Zero
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+
+	return s.recordEntry(ctx, fineID, ledgerEntry{
+		entryType: models.FineLedgerEntryWaiver,
+		amount:    amount,
+		reason:    req.Reason,
+	}, actor)
+}
+
+// RefundPayment returns money previously paid against a fine and issues a receipt
+func (s *FineService) RefundPayment(ctx context.Context, fineID int32, req models.RefundFineRequest, actor AuditActor) (*models.FineLedgerResultResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	return s.recordEntry(ctx, fineID, ledgerEntry{
+		entryType:     models.FineLedgerEntryRefund,
+		amount:        req.Amount,
+		paymentMethod: req.PaymentMethod,
+		reference:     req.Reference,
+		reason:        req.Reason,
+	}, actor)
+}
+
+// AdjustFine corrects the amount charged for a fine
+func (s *FineService) AdjustFine(ctx context.Context, fineID int32, req models.AdjustFineRequest, actor AuditActor) (*models.FineLedgerResultResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	return s.recordEntry(ctx, fineID, ledgerEntry{
+		entryType: models.FineLedgerEntryAdjustment,
+		amount:    req.Amount,
+		reason:    req.Reason,
+	}, actor)
+}
+
+// recordEntry locks the fine, applies the entry to its totals and writes the
+// ledger entry and audit logs in one database transaction
+func (s *FineService) recordEntry(ctx context.Context, fineID int32, entry ledgerEntry, actor AuditActor) (*models.FineLedgerResultResponse, error) {
+	var result models.FineLedgerResultResponse
+	err := s.queries.ExecTx(ctx, func(q FineQuerier) error {
+		var err error
+		result, err = s.withQuerier(q).recordEntryTx(ctx, fineID, entry, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *FineService) recordEntryTx(ctx context.Context, fineID int32, entry ledgerEntry, actor AuditActor) (models.FineLedgerResultResponse, error) {
+	fine, err := s.queries.GetFineByIDForUpdate(ctx, fineID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return models.FineLedgerResultResponse{}, fmt.Errorf("fine not found")
+		}
+		return models.FineLedgerResultResponse{}, fmt.Errorf("failed to get fine: %w", err)
+	}
+
+	return recordLedgerEntry(ctx, s.queries, fine, entry, actor)
+}
+
+// recordLedgerEntry records an entry against a fine the caller has already locked
+func recordLedgerEntry(ctx context.Context, w fineLedgerWriter, fine queries.Fine, entry ledgerEntry, actor AuditActor) (models.FineLedgerResultResponse, error) {
+	before := convertToFineResponse(fine)
+	amount, paid, waived, err := applyLedgerEntry(before, &entry)
+	if err != nil {
+		return models.FineLedgerResultResponse{}, fmt.Errorf("validation error: %w", err)
+	}
+
+	params := queries.CreateFineLedgerEntryParams{
+		FineID:    fine.ID,
+		StudentID: fine.StudentID,
+		EntryType: string(entry.entryType),
+		Amount:    decimalToNumeric(entry.amount),
+		Reference: optionalText(entry.reference),
+		Reason:    pgtype.Text{String: entry.reason, Valid: entry.reason != ""},
+	}
+	if entry.paymentMethod != "" {
+		params.PaymentMethod = pgtype.Text{String: entry.paymentMethod, Valid: true}
+	}
+	if actor.UserID > 0 {
+		params.RecordedBy = pgtype.Int4{Int32: actor.UserID, Valid: true}
+	}
+
+	// Money movements get a numbered receipt
+	if entry.entryType == models.FineLedgerEntryPayment || entry.entryType == models.FineLedgerEntryRefund {
+		seq, err := w.NextFineReceiptNumber(ctx)
+		if err != nil {
+			return models.FineLedgerResultResponse{}, fmt.Errorf("failed to allocate receipt number: %w", err)
+		}
+		params.ReceiptNumber = pgtype.Text{String: formatReceiptNumber(time.Now(), seq), Valid: true}
+	}
+
+	ledgerRow, err := w.CreateFineLedgerEntry(ctx, params)
+	if err != nil {
+		return models.FineLedgerResultResponse{}, fmt.Errorf("failed to record fine ledger entry: %w", err)
+	}
+
+	updated, err := w.UpdateFineTotals(ctx, queries.UpdateFineTotalsParams{
+		ID:           fine.ID,
+		Amount:       decimalToNumeric(amount),
+		AmountPaid:   decimalToNumeric(paid),
+		AmountWaived: decimalToNumeric(waived),
+		Status:       string(fineStatus(amount, paid, waived)),
+	})
+	if err != nil {
+		return models.FineLedgerResultResponse{}, fmt.Errorf("failed to update fine: %w", err)
+	}
+
+	result := models.FineLedgerResultResponse{
+		Entry: convertToFineLedgerEntryResponse(ledgerRow),
+		Fine:  convertToFineResponse(updated),
+	}
+
+	if err := writeAuditLog(ctx, w, actor, "fine_ledger_entries", ledgerRow.ID, "CREATE", nil, result.Entry); err != nil {
+		return models.FineLedgerResultResponse{}, err
+	}
+	if err := writeAuditLog(ctx, w, actor, "fines", fine.ID, "UPDATE", before, result.Fine); err != nil {
+		return models.FineLedgerResultResponse{}, err
+	}
+
+	if err := syncTransactionFinePaid(ctx, w, updated.TransactionID); err != nil {
+		return models.FineLedgerResultResponse{}, err
+	}
+
+	return result, nil
+}
+
+// applyLedgerEntry returns the fine totals after the entry is applied. A waiver
+// without an amount is resolved to the whole outstanding balance.
+func applyLedgerEntry(fine models.FineResponse, entry *ledgerEntry) (amount, paid, waived decimal.Decimal, err error) {
+	amount, paid, waived = fine.Amount, fine.AmountPaid, fine.AmountWaived
+	balance := fine.Balance
+
+	switch entry.entryType {
+	case models.FineLedgerEntryPayment:
+		if entry.amount.GreaterThan(balance) {
+			return amount, paid, waived, fmt.Errorf("payment of %s exceeds the outstanding balance of %s", entry.amount.StringFixed(2), balance.StringFixed(2))
+		}
+		paid = paid.Add(entry.amount)
+	case models.FineLedgerEntryWaiver:
+		if !balance.IsPositive() {
+			return amount, paid, waived, fmt.Errorf("fine has no outstanding balance to waive")
+		}
+		if entry.amount.IsZero() {
+			entry.amount = balance
+		}
+		if entry.amount.GreaterThan(balance) {
+			return amount, paid, waived, fmt.Errorf("waiver of %s exceeds the outstanding balance of %s", entry.amount.StringFixed(2), balance.StringFixed(2))
+		}
+		waived = waived.Add(entry.amount)
+	case models.FineLedgerEntryRefund:
+		if entry.amount.GreaterThan(paid) {
+			return amount, paid, waived, fmt.Errorf("refund of %s exceeds the amount paid of %s", entry.amount.StringFixed(2), paid.StringFixed(2))
+		}
+		paid = paid.Sub(entry.amount)
+	case models.FineLedgerEntryAdjustment:
+		amount = amount.Add(entry.amount)
+		if amount.LessThan(paid.Add(waived)) {
+			return fine.Amount, paid, waived, fmt.Errorf("adjustment would reduce the fine below the %s already paid or waived", paid.Add(waived).StringFixed(2))
+		}
+	default:
+		return amount, paid, waived, fmt.Errorf("unknown ledger entry type %q", entry.entryType)
+	}
+
+	return amount, paid, waived, nil
+}
+
+// fineStatus derives a fine's status from its totals
+func fineStatus(amount, paid, waived decimal.Decimal) models.FineStatus {
+	balance := amount.Sub(paid).Sub(waived)
+	switch {
+	case balance.IsPositive() && (paid.IsPositive() || waived.IsPositive()):
+		return models.FineStatusPartiallyPaid
+	case balance.IsPositive():
+		return models.FineStatusOutstanding
+	case paid.IsZero():
+		return models.FineStatusWaived
+	default:
+		return models.FineStatusPaid
+	}
+}
+
+// syncTransactionFinePaid keeps transactions.fine_paid in step with the ledger:
+// it is true once every fine raised for the loan has been settled
+func syncTransactionFinePaid(ctx context.Context, w fineLedgerWriter, transactionID pgtype.Int4) error {
+	if !transactionID.Valid {
+		return nil
+	}
+
+	fines, err := w.ListFinesByTransaction(ctx, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to list transaction fines: %w", err)
+	}
+
+	settled := true
+	for _, fine := range fines {
+		if convertToFineResponse(fine).Balance.IsPositive() {
+			settled = false
+			break
+		}
+	}
+
+	err = w.SetTransactionFinePaid(ctx, queries.SetTransactionFinePaidParams{
+		ID:       transactionID.Int32,
+		FinePaid: pgtype.Bool{Bool: settled, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update transaction fine status: %w", err)
+	}
+	return nil
+}
+
+// GetReceipt retrieves the ledger entry for a receipt number
+func (s *FineService) GetReceipt(ctx context.Context, receiptNumber string) (*models.FineLedgerEntryResponse, error) {
+	entry, err := s.queries.GetFineLedgerEntryByReceipt(ctx, pgtype.Text{String: receiptNumber, Valid: true})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("receipt not found")
+		}
+		return nil, fmt.Errorf("failed to get receipt: %w", err)
+	}
+
+	response := convertToFineLedgerEntryResponse(entry)
+	return &response, nil
+}
+
+// GetCashReconciliation summarises the payments and refunds recorded on a day,
+// per staff member and payment method, so desk takings can be balanced
+func (s *FineService) GetCashReconciliation(ctx context.Context, date time.Time) (*models.CashReconciliationResponse, error) {
+	from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	rows, err := s.queries.ListFineLedgerEntriesBetween(ctx, queries.ListFineLedgerEntriesBetweenParams{
+		CreatedAt:   pgtype.Timestamp{Time: from, Valid: true},
+		CreatedAt_2: pgtype.Timestamp{Time: to, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fine ledger entries: %w", err)
+	}
+
+	response := &models.CashReconciliationResponse{
+		Date:      from.Format(models.CalendarDateLayout),
+		Collected: decimal.Zero,
+		Refunded:  decimal.Zero,
+		Net:       decimal.Zero,
+		Totals:    []models.CashReconciliationTotal{},
+		Entries:   []models.FineLedgerEntryResponse{},
+	}
+
+	type totalKey struct {
+		recordedBy int32
+		method     string
+	}
+	totals := make(map[totalKey]*models.CashReconciliationTotal)
+
+	for _, row := range rows {
+		entryType := models.FineLedgerEntryType(row.EntryType)
+		if entryType != models.FineLedgerEntryPayment && entryType != models.FineLedgerEntryRefund {
+			continue
+		}
+
+		entry := convertToFineLedgerEntryResponse(queries.FineLedgerEntry{
+			ID:            row.ID,
+			FineID:        row.FineID,
+			StudentID:     row.StudentID,
+			EntryType:     row.EntryType,
+			Amount:        row.Amount,
+			PaymentMethod: row.PaymentMethod,
+			Reference:     row.Reference,
+			Reason:        row.Reason,
+			ReceiptNumber: row.ReceiptNumber,
+			RecordedBy:    row.RecordedBy,
+			CreatedAt:     row.CreatedAt,
+		})
+		if row.RecordedByUsername.Valid {
+			entry.RecordedByUsername = &row.RecordedByUsername.String
+		}
+		response.Entries = append(response.Entries, entry)
+
+		key := totalKey{recordedBy: row.RecordedBy.Int32, method: row.PaymentMethod.String}
+		total, ok := totals[key]
+		if !ok {
+			total = &models.CashReconciliationTotal{
+				RecordedBy:         entry.RecordedBy,
+				RecordedByUsername: entry.RecordedByUsername,
+				PaymentMethod:      row.PaymentMethod.String,
+				Collected:          decimal.Zero,
+				Refunded:           decimal.Zero,
+			}
+			totals[key] = total
+		}
+
+		if entryType == models.FineLedgerEntryPayment {
+			total.PaymentCount++
+			total.Collected = total.Collected.Add(entry.Amount)
+			response.Collected = response.Collected.Add(entry.Amount)
+		} else {
+			total.RefundCount++
+			total.Refunded = total.Refunded.Add(entry.Amount)
+			response.Refunded = response.Refunded.Add(entry.Amount)
+		}
+		total.Net = total.Collected.Sub(total.Refunded)
+	}
+
+	for _, total := range totals {
+		response.Totals = append(response.Totals, *total)
+	}
+	sort.Slice(response.Totals, func(i, j int) bool {
+		a, b := response.Totals[i], response.Totals[j]
+		if ai, bi := int32Value(a.RecordedBy), int32Value(b.RecordedBy); ai != bi {
+			return ai < bi
+		}
+		return a.PaymentMethod < b.PaymentMethod
+	})
+	response.Net = response.Collected.Sub(response.Refunded)
+
+	return response, nil
+}
+
+// formatReceiptNumber renders a receipt number such as RCP-20260116-000042
+func formatReceiptNumber(issuedAt time.Time, seq int64) string {
+	return fmt.Sprintf("RCP-%s-%06d", issuedAt.Format("20060102"), seq)
+}
+
+// convertToFineResponse converts a queries.Fine to FineResponse
+func convertToFineResponse(fine queries.Fine) models.FineResponse {
+	amount := numericToDecimal(fine.Amount)
+	paid := numericToDecimal(fine.AmountPaid)
+	waived := numericToDecimal(fine.AmountWaived)
+
+	response := models.FineResponse{
+		ID:           fine.ID,
+		StudentID:    fine.StudentID,
+		FineType:     models.FineType(fine.FineType),
+		Amount:       amount,
+		AmountPaid:   paid,
+		AmountWaived: waived,
+		Balance:      amount.Sub(paid).Sub(waived),
+		Status:       models.FineStatus(fine.Status),
+		CreatedAt:    fine.CreatedAt.Time,
+		UpdatedAt:    fine.UpdatedAt.Time,
+	}
+
+	if fine.TransactionID.Valid {
+		response.TransactionID = &fine.TransactionID.Int32
+	}
+	if fine.Description.Valid {
+		response.Description = &fine.Description.String
+	}
+	if fine.CreatedBy.Valid {
+		response.CreatedBy = &fine.CreatedBy.Int32
+	}
+
+	return response
+}
+
+// convertToFineLedgerEntryResponse converts a queries.FineLedgerEntry to FineLedgerEntryResponse
+func convertToFineLedgerEntryResponse(entry queries.FineLedgerEntry) models.FineLedgerEntryResponse {
+	response := models.FineLedgerEntryResponse{
+		ID:        entry.ID,
+		FineID:    entry.FineID,
+		StudentID: entry.StudentID,
+		EntryType: models.FineLedgerEntryType(entry.EntryType),
+		Amount:    numericToDecimal(entry.Amount),
+		CreatedAt: entry.CreatedAt.Time,
+	}
+
+	if entry.PaymentMethod.Valid {
+		response.PaymentMethod = &entry.PaymentMethod.String
+	}
+	if entry.Reference.Valid {
+		response.Reference = &entry.Reference.String
+	}
+	if entry.Reason.Valid {
+		response.Reason = &entry.Reason.String
+	}
+	if entry.ReceiptNumber.Valid {
+		response.ReceiptNumber = &entry.ReceiptNumber.String
+	}
+	if entry.RecordedBy.Valid {
+		response.RecordedBy = &entry.RecordedBy.Int32
+	}
+
+	return response
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// FineAccrualJobName identifies the overdue fine accrual job in background_jobs
+const FineAccrualJobName = "fine_accrual"
+
+// ErrJobAlreadyRunning is returned when another instance holds a job's run lease
+var ErrJobAlreadyRunning = errors.New("job is already running")
+
+const (
+	// defaultFineAccrualLease bounds how long a crashed instance can block the job
+	defaultFineAccrualLease = 30 * time.Minute
+	// jobFinishTimeout is how long recording a run's outcome may take after the run's context ends
+	jobFinishTimeout = 10 * time.Second
+)
+
+// FineAccrualQuerier defines the queries needed to accrue running fines on overdue loans
+type FineAccrualQuerier interface {
+	ListOpenOverdueLoans(ctx context.Context) ([]queries.ListOpenOverdueLoansRow, error)
+	AccrueTransactionFine(ctx context.Context, arg queries.AccrueTransactionFineParams) (int64, error)
+	ClaimBackgroundJob(ctx context.Context, arg queries.ClaimBackgroundJobParams) (queries.BackgroundJob, error)
+	FinishBackgroundJob(ctx context.Context, arg queries.FinishBackgroundJobParams) (queries.BackgroundJob, error)
+	GetBackgroundJob(ctx context.Context, name string) (queries.BackgroundJob, error)
+}
+
+// FineAccrualServiceInterface defines the interface for the fine accrual job
+type FineAccrualServiceInterface interface {
+	AccrueOverdueFines(ctx context.Context) (*models.FineAccrualResult, error)
+	GetStatus(ctx context.Context) (*models.FineAccrualStatusResponse, error)
+}
+
+// FineAccrualService keeps the fine on each open overdue loan up to date so overdue
+// lists and fine notices show what is owed before the book comes back. Fines are
+// recalculated from the due date on every run, so running it again changes nothing.
+type FineAccrualService struct {
+	queries    FineAccrualQuerier
+	rules      *TransactionService
+	logger     *slog.Logger
+	instanceID string
+	lease      time.Duration
+}
+
+// NewFineAccrualService creates a fine accrual service. Fines are calculated with
+// the circulation policies and calendar configured on rules.
+func NewFineAccrualService(querier FineAccrualQuerier, rules *TransactionService, logger *slog.Logger) *FineAccrualService {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return &FineAccrualService{
+		queries:    querier,
+		rules:      rules,
+		logger:     logger,
+		instanceID: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		lease:      defaultFineAccrualLease,
+	}
+}
+
+// WithInstanceID sets the name this server records when it holds the run lease
+func (s *FineAccrualService) WithInstanceID(instanceID string) *FineAccrualService {
+	s.instanceID = instanceID
+	return s
+}
+
+// WithLease sets how long a run may hold the lease before another instance can take over
+func (s *FineAccrualService) WithLease(lease time.Duration) *FineAccrualService {
+	s.lease = lease
+	return s
+}
+
+// AccrueOverdueFines recalculates the running fine on every open overdue loan.
+// It returns ErrJobAlreadyRunning if another instance is part way through a run.
+func (s *FineAccrualService) AccrueOverdueFines(ctx context.Context) (*models.FineAccrualResult, error) {
+	_, err := s.queries.ClaimBackgroundJob(ctx, queries.ClaimBackgroundJobParams{
+		Name:        FineAccrualJobName,
+		LockedBy:    pgtype.Text{String: s.instanceID, Valid: true},
+		LockedUntil: pgtype.Timestamp{Time: time.Now().Add(s.lease), Valid: true},
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, ErrJobAlreadyRunning
+		}
+		return nil, fmt.Errorf("failed to claim fine accrual job: %w", err)
+	}
+
+	result, runErr := s.accrue(ctx)
+
+	// Record the outcome even if the run was cancelled part way through
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jobFinishTimeout)
+	defer cancel()
+	if err := s.finish(finishCtx, result, runErr); err != nil {
+		s.logger.Error("Failed to record fine accrual run", "error", err)
+	}
+
+	if runErr != nil {
+		return result, runErr
+	}
+	return result, nil
+}
+
+// accrue updates each loan in turn; a loan that fails is counted and retried on the next run
+func (s *FineAccrualService) accrue(ctx context.Context) (*models.FineAccrualResult, error) {
+	now := time.Now()
+	result := &models.FineAccrualResult{
+		TotalAccrued: decimal.Zero,
+		StartedAt:    now,
+	}
+
+	loans, err := s.queries.ListOpenOverdueLoans(ctx)
+	if err != nil {
+		result.FinishedAt = time.Now()
+		return result, fmt.Errorf("failed to list overdue loans: %w", err)
+	}
+
+	policies := make(map[PolicyContext]*CirculationPolicy)
+	for _, loan := range loans {
+		if err := ctx.Err(); err != nil {
+			result.FinishedAt = time.Now()
+			return result, err
+		}
+		result.LoansChecked++
+
+		fine, err := s.runningFine(ctx, loan, now, policies)
+		if err == nil {
+			var updated int64
+			updated, err = s.queries.AccrueTransactionFine(ctx, queries.AccrueTransactionFineParams{
+				ID:         loan.ID,
+				FineAmount: fineToNumeric(fine),
+			})
+			if updated > 0 {
+				result.LoansUpdated++
+			}
+		}
+		if err != nil {
+			s.logger.Warn("Failed to accrue fine", "transaction_id", loan.ID, "error", err)
+			result.LoansFailed++
+			continue
+		}
+
+		result.TotalAccrued = result.TotalAccrued.Add(fine)
+	}
+
+	result.FinishedAt = time.Now()
+	s.logger.Info("Fine accrual completed",
+		"loans_checked", result.LoansChecked,
+		"loans_updated", result.LoansUpdated,
+		"loans_failed", result.LoansFailed,
+		"total_accrued", result.TotalAccrued.StringFixed(2))
+
+	if result.LoansFailed > 0 {
+		return result, fmt.Errorf("failed to accrue fines on %d of %d loans", result.LoansFailed, result.LoansChecked)
+	}
+	return result, nil
+}
+
+// runningFine applies the loan's circulation policy to the days it has been overdue so far
+func (s *FineAccrualService) runningFine(ctx context.Context, loan queries.ListOpenOverdueLoansRow, now time.Time, policies map[PolicyContext]*CirculationPolicy) (decimal.Decimal, error) {
+	pc := PolicyContext{
+		YearOfStudy: loan.YearOfStudy,
+		Department:  loan.Department.String,
+		UserType:    models.PatronTypeStudent,
+		ItemType:    loan.Genre.String,
+	}
+
+	policy, ok := policies[pc]
+	if !ok {
+		var err error
+		if policy, err = s.rules.resolvePolicy(ctx, pc); err != nil {
+			return decimal.Zero, err
+		}
+		policies[pc] = policy
+	}
+	if loan.RecalledAt.Valid {
+		policy = policy.forRecall()
+	}
+
+	return s.rules.calculateFine(ctx, loan.DueDate.Time, now, policy)
+}
+
+func (s *FineAccrualService) finish(ctx context.Context, result *models.FineAccrualResult, runErr error) error {
+	summary, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode fine accrual result: %w", err)
+	}
+
+	params := queries.FinishBackgroundJobParams{
+		Name:       FineAccrualJobName,
+		LockedBy:   pgtype.Text{String: s.instanceID, Valid: true},
+		LastStatus: pgtype.Text{String: "succeeded", Valid: true},
+		LastResult: summary,
+	}
+	if runErr != nil {
+		params.LastStatus = pgtype.Text{String: "failed", Valid: true}
+		params.LastError = pgtype.Text{String: runErr.Error(), Valid: true}
+	}
+
+	if _, err := s.queries.FinishBackgroundJob(ctx, params); err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return fmt.Errorf("run lease expired before the run finished")
+		}
+		return err
+	}
+	return nil
+}
+
+// GetStatus reports whether the job is running and how its last run went
+func (s *FineAccrualService) GetStatus(ctx context.Context) (*models.FineAccrualStatusResponse, error) {
+	response := &models.FineAccrualStatusResponse{
+		BackgroundJobStatus: models.BackgroundJobStatus{Name: FineAccrualJobName},
+	}
+
+	job, err := s.queries.GetBackgroundJob(ctx, FineAccrualJobName)
+	if err != nil {
+		// The job has never run
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return response, nil
+		}
+		return nil, fmt.Errorf("failed to get fine accrual job: %w", err)
+	}
+
+	response.BackgroundJobStatus = convertToBackgroundJobStatus(job, time.Now())
+	if len(job.LastResult) > 0 {
+		var result models.FineAccrualResult
+		if err := json.Unmarshal(job.LastResult, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode fine accrual result: %w", err)
+		}
+		response.LastResult = &result
+	}
+
+	return response, nil
+}
+
+// Run accrues overdue fines for the job scheduler. A run started by hand that is
+// still going is left to finish rather than counted as a failure.
+func (s *FineAccrualService) Run(ctx context.Context) error {
+	if _, err := s.AccrueOverdueFines(ctx); err != nil {
+		if errors.Is(err, ErrJobAlreadyRunning) {
+			s.logger.Info("Fine accrual skipped, another run is in progress")
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func convertToBackgroundJobStatus(job queries.BackgroundJob, now time.Time) models.BackgroundJobStatus {
+	status := models.BackgroundJobStatus{
+		Name:    job.Name,
+		Running: job.LockedUntil.Valid && job.LockedUntil.Time.After(now),
+	}
+
+	if job.LockedBy.Valid {
+		status.LockedBy = &job.LockedBy.String
+	}
+	if job.LockedUntil.Valid {
+		status.LockedUntil = &job.LockedUntil.Time
+	}
+	if job.LastStatus.Valid {
+		status.LastStatus = &job.LastStatus.String
+	}
+	if job.LastStartedAt.Valid {
+		status.LastStartedAt = &job.LastStartedAt.Time
+	}
+	if job.LastFinishedAt.Valid {
+		status.LastFinishedAt = &job.LastFinishedAt.Time
+	}
+	if job.LastSuccessAt.Valid {
+		status.LastSuccessAt = &job.LastSuccessAt.Time
+	}
+	if job.LastError.Valid {
+		status.LastError = &job.LastError.String
+	}
+
+	return status
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockFineAccrualQuerier is a mock implementation of FineAccrualQuerier
+type MockFineAccrualQuerier struct {
+	mock.Mock
+}
+
+func (m *MockFineAccrualQuerier) ListOpenOverdueLoans(ctx context.Context) ([]queries.ListOpenOverdueLoansRow, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]queries.ListOpenOverdueLoansRow), args.Error(1)
+}
+
+func (m *MockFineAccrualQuerier) AccrueTransactionFine(ctx context.Context, arg queries.AccrueTransactionFineParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockFineAccrualQuerier) ClaimBackgroundJob(ctx context.Context, arg queries.ClaimBackgroundJobParams) (queries.BackgroundJob, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.BackgroundJob), args.Error(1)
+}
+
+func (m *MockFineAccrualQuerier) FinishBackgroundJob(ctx context.Context, arg queries.FinishBackgroundJobParams) (queries.BackgroundJob, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.BackgroundJob), args.Error(1)
+}
+
+func (m *MockFineAccrualQuerier) GetBackgroundJob(ctx context.Context, name string) (queries.BackgroundJob, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(queries.BackgroundJob), args.Error(1)
+}
+
+func newTestFineAccrualService(querier *MockFineAccrualQuerier) *FineAccrualService {
+	rules := NewTransactionService(&MockTransactionQueries{})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewFineAccrualService(querier, rules, logger).WithInstanceID("test-instance")
+}
+
+func TestFineAccrualService_AccrueOverdueFines(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("RecalculatesRunningFines", func(t *testing.T) {
+		querier := &MockFineAccrualQuerier{}
+		service := newTestFineAccrualService(querier)
+
+		querier.On("ClaimBackgroundJob", ctx, mock.MatchedBy(func(arg queries.ClaimBackgroundJobParams) bool {
+			return arg.Name == FineAccrualJobName && arg.LockedBy.String == "test-instance" && arg.LockedUntil.Time.After(now)
+		})).Return(queries.BackgroundJob{Name: FineAccrualJobName}, nil)
+		querier.On("ListOpenOverdueLoans", ctx).Return([]queries.ListOpenOverdueLoansRow{
+			{ID: 1, StudentID: 1, BookID: 1, YearOfStudy: 1, DueDate: pgtype.Timestamp{Time: now.AddDate(0, 0, -4), Valid: true}},
+			{ID: 2, StudentID: 2, BookID: 2, YearOfStudy: 1, DueDate: pgtype.Timestamp{Time: now.AddDate(0, 0, -2), Valid: true}},
+		}, nil)
+		// Four days at the default 0.50 a day; the second loan already carries its fine
+		querier.On("AccrueTransactionFine", ctx, queries.AccrueTransactionFineParams{ID: 1, FineAmount: fineToNumeric(decimal.NewFromFloat(2))}).Return(int64(1), nil)
+		querier.On("AccrueTransactionFine", ctx, queries.AccrueTransactionFineParams{ID: 2, FineAmount: fineToNumeric(decimal.NewFromFloat(1))}).Return(int64(0), nil)
+		querier.On("FinishBackgroundJob", mock.Anything, mock.MatchedBy(func(arg queries.FinishBackgroundJobParams) bool {
+			var result models.FineAccrualResult
+			return arg.LastStatus.String == "succeeded" && !arg.LastError.Valid &&
+				json.Unmarshal(arg.LastResult, &result) == nil && result.LoansUpdated == 1
+		})).Return(queries.BackgroundJob{}, nil)
+
+		result, err := service.AccrueOverdueFines(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.LoansChecked)
+		assert.Equal(t, 1, result.LoansUpdated)
+		assert.Equal(t, 0, result.LoansFailed)
+		assert.True(t, decimal.NewFromFloat(3).Equal(result.TotalAccrued))
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("SkipsWhenAnotherInstanceHoldsTheLease", func(t *testing.T) {
+		querier := &MockFineAccrualQuerier{}
+		service := newTestFineAccrualService(querier)
+
+		querier.On("ClaimBackgroundJob", ctx, mock.Anything).Return(queries.BackgroundJob{}, pgx.ErrNoRows)
+
+		_, err := service.AccrueOverdueFines(ctx)
+
+		assert.ErrorIs(t, err, ErrJobAlreadyRunning)
+		querier.AssertNotCalled(t, "ListOpenOverdueLoans", mock.Anything)
+	})
+
+	t.Run("RecordsFailedLoans", func(t *testing.T) {
+		querier := &MockFineAccrualQuerier{}
+		service := newTestFineAccrualService(querier)
+
+		querier.On("ClaimBackgroundJob", ctx, mock.Anything).Return(queries.BackgroundJob{Name: FineAccrualJobName}, nil)
+		querier.On("ListOpenOverdueLoans", ctx).Return([]queries.ListOpenOverdueLoansRow{
+			{ID: 1, YearOfStudy: 1, DueDate: pgtype.Timestamp{Time: now.AddDate(0, 0, -4), Valid: true}},
+		}, nil)
+		querier.On("AccrueTransactionFine", ctx, mock.Anything).Return(int64(0), errors.New("connection reset"))
+		querier.On("FinishBackgroundJob", mock.Anything, mock.MatchedBy(func(arg queries.FinishBackgroundJobParams) bool {
+			return arg.LastStatus.String == "failed" && arg.LastError.String == "failed to accrue fines on 1 of 1 loans"
+		})).Return(queries.BackgroundJob{}, nil)
+
+		result, err := service.AccrueOverdueFines(ctx)
+
+		require.Error(t, err)
+		assert.Equal(t, 1, result.LoansFailed)
+		querier.AssertExpectations(t)
+	})
+}
+
+func TestFineAccrualService_GetStatus(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("NeverRun", func(t *testing.T) {
+		querier := &MockFineAccrualQuerier{}
+		service := newTestFineAccrualService(querier)
+
+		querier.On("GetBackgroundJob", ctx, FineAccrualJobName).Return(queries.BackgroundJob{}, pgx.ErrNoRows)
+
+		status, err := service.GetStatus(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, FineAccrualJobName, status.Name)
+		assert.False(t, status.Running)
+		assert.Nil(t, status.LastResult)
+	})
+
+	t.Run("LastRun", func(t *testing.T) {
+		querier := &MockFineAccrualQuerier{}
+		service := newTestFineAccrualService(querier)
+
+		lastResult, _ := json.Marshal(models.FineAccrualResult{LoansChecked: 5, LoansUpdated: 3})
+		querier.On("GetBackgroundJob", ctx, FineAccrualJobName).Return(queries.BackgroundJob{
+			Name:           FineAccrualJobName,
+			LastStatus:     pgtype.Text{String: "succeeded", Valid: true},
+			LastFinishedAt: pgtype.Timestamp{Time: now.Add(-time.Hour), Valid: true},
+			LastSuccessAt:  pgtype.Timestamp{Time: now.Add(-time.Hour), Valid: true},
+			LastResult:     lastResult,
+		}, nil)
+
+		status, err := service.GetStatus(ctx)
+
+		require.NoError(t, err)
+		assert.False(t, status.Running)
+		require.NotNil(t, status.LastStatus)
+		assert.Equal(t, "succeeded", *status.LastStatus)
+		require.NotNil(t, status.LastResult)
+		assert.Equal(t, 3, status.LastResult.LoansUpdated)
+	})
+}
+
+func TestFineAccrualService_Run(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SkipsWhileAnotherRunHoldsTheLease", func(t *testing.T) {
+		querier := &MockFineAccrualQuerier{}
+		service := newTestFineAccrualService(querier)
+
+		querier.On("ClaimBackgroundJob", ctx, mock.Anything).Return(queries.BackgroundJob{}, pgx.ErrNoRows)
+
+		assert.NoError(t, service.Run(ctx))
+		querier.AssertNotCalled(t, "ListOpenOverdueLoans", mock.Anything)
+	})
+
+	t.Run("ReportsFailures", func(t *testing.T) {
+		querier := &MockFineAccrualQuerier{}
+		service := newTestFineAccrualService(querier)
+
+		querier.On("ClaimBackgroundJob", ctx, mock.Anything).Return(queries.BackgroundJob{}, errors.New("connection refused"))
+
+		assert.Error(t, service.Run(ctx))
+	})
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// mobilePaymentActor records ledger entries made when the provider confirms a payment
+var mobilePaymentActor = AuditActor{UserType: "system"}
+
+// InitiateMobilePayment prompts the student's phone to pay their outstanding balance.
+// The fines are settled later, when the provider's callback confirms the payment.
+func (s *FineService) InitiateMobilePayment(ctx context.Context, studentID int32, req models.InitiateMobilePaymentRequest, actor AuditActor) (*models.FinePaymentRequestResponse, error) {
+	if s.payments == nil {
+		return nil, ErrPaymentsNotConfigured
+	}
+
+	student, err := s.queries.GetStudentByID(ctx, studentID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("student not found")
+		}
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+
+	phone := req.Phone
+	if phone == "" {
+		if !student.Phone.Valid || student.Phone.String == "" {
+			return nil, fmt.Errorf("validation error: student has no phone number on record")
+		}
+		phone = student.Phone.String
+	}
+	phone, err = normalizeMpesaPhone(phone)
+	if err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	balanceRow, err := s.queries.GetStudentFineBalance(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fine balance: %w", err)
+	}
+	balance := numericToDecimal(balanceRow.Balance)
+	if !balance.IsPositive() {
+		return nil, fmt.Errorf("validation error: student has no outstanding fines")
+	}
+
+	params := queries.CreateFinePaymentRequestParams{
+		StudentID: studentID,
+		Provider:  s.payments.Name(),
+		Phone:     phone,
+		// Mobile money is charged in whole shillings
+		Amount: decimalToNumeric(balance.Ceil()),
+	}
+	if actor.UserID > 0 {
+		params.RequestedBy = pgtype.Int4{Int32: actor.UserID, Valid: true}
+	}
+
+	request, err := s.queries.CreateFinePaymentRequest(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment request: %w", err)
+	}
+
+	initiation, err := s.payments.InitiatePayment(ctx, PaymentRequest{
+		Phone:            phone,
+		Amount:           balance.Ceil(),
+		AccountReference: student.StudentID,
+		Description:      "Library fines",
+	})
+	if err != nil {
+		_, updateErr := s.queries.CompleteFinePaymentRequest(ctx, queries.CompleteFinePaymentRequestParams{
+			ID:            request.ID,
+			Status:        string(models.PaymentRequestFailed),
+			AmountApplied: decimalToNumeric(decimal.Zero),
+			ResultDesc:    pgtype.Text{String: err.Error(), Valid: true},
+		})
+		if updateErr != nil {
+			return nil, fmt.Errorf("failed to record failed payment request: %w", updateErr)
+		}
+		return nil, fmt.Errorf("failed to initiate payment: %w", err)
+	}
+
+	request, err = s.queries.SetFinePaymentRequestCheckout(ctx, queries.SetFinePaymentRequestCheckoutParams{
+		ID:                request.ID,
+		MerchantRequestID: pgtype.Text{String: initiation.MerchantRequestID, Valid: initiation.MerchantRequestID != ""},
+		CheckoutRequestID: pgtype.Text{String: initiation.CheckoutRequestID, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update payment request: %w", err)
+	}
+
+	response := convertToFinePaymentRequestResponse(request)
+	response.CustomerMessage = initiation.CustomerMessage
+	return &response, nil
+}
+
+// HandlePaymentCallback applies the provider's result for a payment request. A
+// successful payment is applied to the student's outstanding fines, oldest first,
+// up to the amount requested.
+// Callbacks for requests that are no longer pending are ignored, so provider
+// retries never settle a payment twice.
+func (s *FineService) HandlePaymentCallback(ctx context.Context, token string, body []byte) (*models.FinePaymentRequestResponse, error) {
+	if s.payments == nil {
+		return nil, ErrPaymentsNotConfigured
+	}
+
+	result, err := s.payments.ParseCallback(token, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.FinePaymentRequestResponse
+	err = s.queries.ExecTx(ctx, func(q FineQuerier) error {
+		request, err := q.GetFinePaymentRequestByCheckoutIDForUpdate(ctx, pgtype.Text{String: result.CheckoutRequestID, Valid: true})
+		if err != nil {
+			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+				return fmt.Errorf("payment request not found")
+			}
+			return fmt.Errorf("failed to get payment request: %w", err)
+		}
+
+		if models.PaymentRequestStatus(request.Status) != models.PaymentRequestPending {
+			response = convertToFinePaymentRequestResponse(request)
+			return nil
+		}
+
+		params := queries.CompleteFinePaymentRequestParams{
+			ID:            request.ID,
+			Status:        string(models.PaymentRequestFailed),
+			AmountApplied: decimalToNumeric(decimal.Zero),
+			ResultCode:    pgtype.Int4{Int32: int32(result.ResultCode), Valid: true},
+			ResultDesc:    pgtype.Text{String: result.ResultDesc, Valid: result.ResultDesc != ""},
+		}
+
+		if result.Succeeded() {
+			// Never settle more than was asked for; a mismatch is kept on the request for review
+			requested := numericToDecimal(request.Amount)
+			applied, err := s.withQuerier(q).settleStudentFines(ctx, request.StudentID, decimal.Min(result.Amount, requested), result.Receipt)
+			if err != nil {
+				return err
+			}
+			if !result.Amount.Equal(requested) {
+				params.ResultDesc = pgtype.Text{
+					String: fmt.Sprintf("%s (amount mismatch: paid %s, requested %s)", result.ResultDesc, result.Amount.StringFixed(2), requested.StringFixed(2)),
+					Valid:  true,
+				}
+			}
+			params.Status = string(models.PaymentRequestCompleted)
+			params.AmountApplied = decimalToNumeric(applied)
+			params.ProviderReceipt = pgtype.Text{String: result.Receipt, Valid: true}
+		}
+
+		request, err = q.CompleteFinePaymentRequest(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to complete payment request: %w", err)
+		}
+
+		response = convertToFinePaymentRequestResponse(request)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// settleStudentFines records mobile payments against the student's outstanding
+// fines, oldest first, and returns how much of the amount was applied
+func (s *FineService) settleStudentFines(ctx context.Context, studentID int32, amount decimal.Decimal, receipt string) (decimal.Decimal, error) {
+	fines, err := s.queries.ListOutstandingFinesByStudent(ctx, studentID)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to list outstanding fines: %w", err)
+	}
+
+	remaining := amount
+	for _, listed := range fines {
+		if !remaining.IsPositive() {
+			break
+		}
+
+		// Re-read under lock; the desk may have taken a payment since the prompt was sent
+		fine, err := s.queries.GetFineByIDForUpdate(ctx, listed.ID)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("failed to get fine: %w", err)
+		}
+
+		balance := convertToFineResponse(fine).Balance
+		if !balance.IsPositive() {
+			continue
+		}
+
+		payment := decimal.Min(remaining, balance)
+		_, err = recordLedgerEntry(ctx, s.queries, fine, ledgerEntry{
+			entryType:     models.FineLedgerEntryPayment,
+			amount:        payment,
+			paymentMethod: string(models.PaymentMethodMpesa),
+			reference:     &receipt,
+		}, mobilePaymentActor)
+		if err != nil {
+			return decimal.Zero, err
+		}
+
+		remaining = remaining.Sub(payment)
+	}
+
+	return amount.Sub(remaining), nil
+}
+
+// GetPaymentRequest retrieves a mobile payment request so its outcome can be checked
+func (s *FineService) GetPaymentRequest(ctx context.Context, id int32) (*models.FinePaymentRequestResponse, error) {
+	request, err := s.queries.GetFinePaymentRequestByID(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("payment request not found")
+		}
+		return nil, fmt.Errorf("failed to get payment request: %w", err)
+	}
+
+	response := convertToFinePaymentRequestResponse(request)
+	return &response, nil
+}
+
+// convertToFinePaymentRequestResponse converts a queries.FinePaymentRequest to FinePaymentRequestResponse
+func convertToFinePaymentRequestResponse(request queries.FinePaymentRequest) models.FinePaymentRequestResponse {
+	response := models.FinePaymentRequestResponse{
+		ID:            request.ID,
+		StudentID:     request.StudentID,
+		Provider:      request.Provider,
+		Phone:         request.Phone,
+		Amount:        numericToDecimal(request.Amount),
+		AmountApplied: numericToDecimal(request.AmountApplied),
+		Status:        models.PaymentRequestStatus(request.Status),
+		CreatedAt:     request.CreatedAt.Time,
+	}
+
+	if request.CheckoutRequestID.Valid {
+		response.CheckoutRequestID = &request.CheckoutRequestID.String
+	}
+	if request.ProviderReceipt.Valid {
+		response.ProviderReceipt = &request.ProviderReceipt.String
+	}
+	if request.ResultCode.Valid {
+		response.ResultCode = &request.ResultCode.Int32
+	}
+	if request.ResultDesc.Valid {
+		response.ResultDesc = &request.ResultDesc.String
+	}
+	if request.RequestedBy.Valid {
+		response.RequestedBy = &request.RequestedBy.Int32
+	}
+	if request.CompletedAt.Valid {
+		response.CompletedAt = &request.CompletedAt.Time
+	}
+
+	return response
+}
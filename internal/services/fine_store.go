@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+)
+
+// FineStore provides the fine ledger queries backed by a connection pool
+// and the ability to run several of them inside one database transaction
+type FineStore struct {
+	*queries.Queries
+	pool *pgxpool.Pool
+}
+
+// NewFineStore creates a new fine store for the given pool
+func NewFineStore(pool *pgxpool.Pool) *FineStore {
+	return &FineStore{
+		Queries: queries.New(pool),
+		pool:    pool,
+	}
+}
+
+// ExecTx runs fn inside a database transaction, committing only if fn succeeds.
+// Calls made on a store that is already bound to a transaction reuse it.
+func (s *FineStore) ExecTx(ctx context.Context, fn func(FineQuerier) error) error {
+	if s.pool == nil {
+		return fn(s)
+	}
+
+	return runInTx(ctx, s.pool, func(tx pgx.Tx) error {
+		return fn(&FineStore{Queries: s.Queries.WithTx(tx)})
+	})
+}
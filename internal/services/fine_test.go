@@ -0,0 +1,402 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockFineQuerier is a mock implementation of FineQuerier interface
+type MockFineQuerier struct {
+	mock.Mock
+}
+
+func (m *MockFineQuerier) CreateFine(ctx context.Context, arg queries.CreateFineParams) (queries.Fine, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Fine), args.Error(1)
+}
+
+func (m *MockFineQuerier) GetFineByID(ctx context.Context, id int32) (queries.Fine, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Fine), args.Error(1)
+}
+
+func (m *MockFineQuerier) GetFineByIDForUpdate(ctx context.Context, id int32) (queries.Fine, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Fine), args.Error(1)
+}
+
+func (m *MockFineQuerier) ListFinesByStudent(ctx context.Context, studentID int32) ([]queries.Fine, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]queries.Fine), args.Error(1)
+}
+
+func (m *MockFineQuerier) ListFinesByTransaction(ctx context.Context, transactionID pgtype.Int4) ([]queries.Fine, error) {
+	args := m.Called(ctx, transactionID)
+	return args.Get(0).([]queries.Fine), args.Error(1)
+}
+
+func (m *MockFineQuerier) ListOutstandingFinesByStudent(ctx context.Context, studentID int32) ([]queries.Fine, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]queries.Fine), args.Error(1)
+}
+
+func (m *MockFineQuerier) UpdateFineTotals(ctx context.Context, arg queries.UpdateFineTotalsParams) (queries.Fine, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Fine), args.Error(1)
+}
+
+func (m *MockFineQuerier) GetStudentFineBalance(ctx context.Context, studentID int32) (queries.GetStudentFineBalanceRow, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).(queries.GetStudentFineBalanceRow), args.Error(1)
+}
+
+func (m *MockFineQuerier) NextFineReceiptNumber(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockFineQuerier) CreateFineLedgerEntry(ctx context.Context, arg queries.CreateFineLedgerEntryParams) (queries.FineLedgerEntry, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.FineLedgerEntry), args.Error(1)
+}
+
+func (m *MockFineQuerier) ListFineLedgerEntriesByFine(ctx context.Context, fineID int32) ([]queries.FineLedgerEntry, error) {
+	args := m.Called(ctx, fineID)
+	return args.Get(0).([]queries.FineLedgerEntry), args.Error(1)
+}
+
+func (m *MockFineQuerier) GetFineLedgerEntryByReceipt(ctx context.Context, receiptNumber pgtype.Text) (queries.FineLedgerEntry, error) {
+	args := m.Called(ctx, receiptNumber)
+	return args.Get(0).(queries.FineLedgerEntry), args.Error(1)
+}
+
+func (m *MockFineQuerier) ListFineLedgerEntriesBetween(ctx context.Context, arg queries.ListFineLedgerEntriesBetweenParams) ([]queries.ListFineLedgerEntriesBetweenRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]queries.ListFineLedgerEntriesBetweenRow), args.Error(1)
+}
+
+func (m *MockFineQuerier) SetTransactionFinePaid(ctx context.Context, arg queries.SetTransactionFinePaidParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockFineQuerier) CreateFinePaymentRequest(ctx context.Context, arg queries.CreateFinePaymentRequestParams) (queries.FinePaymentRequest, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.FinePaymentRequest), args.Error(1)
+}
+
+func (m *MockFineQuerier) GetFinePaymentRequestByID(ctx context.Context, id int32) (queries.FinePaymentRequest, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.FinePaymentRequest), args.Error(1)
+}
+
+func (m *MockFineQuerier) GetFinePaymentRequestByCheckoutIDForUpdate(ctx context.Context, checkoutRequestID pgtype.Text) (queries.FinePaymentRequest, error) {
+	args := m.Called(ctx, checkoutRequestID)
+	return args.Get(0).(queries.FinePaymentRequest), args.Error(1)
+}
+
+func (m *MockFineQuerier) SetFinePaymentRequestCheckout(ctx context.Context, arg queries.SetFinePaymentRequestCheckoutParams) (queries.FinePaymentRequest, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.FinePaymentRequest), args.Error(1)
+}
+
+func (m *MockFineQuerier) CompleteFinePaymentRequest(ctx context.Context, arg queries.CompleteFinePaymentRequestParams) (queries.FinePaymentRequest, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.FinePaymentRequest), args.Error(1)
+}
+
+func (m *MockFineQuerier) GetStudentByID(ctx context.Context, id int32) (queries.Student, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Student), args.Error(1)
+}
+
+func (m *MockFineQuerier) CreateAuditLog(ctx context.Context, arg queries.CreateAuditLogParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+// ExecTx runs fn directly against the mock; transactional behaviour is covered by integration tests
+func (m *MockFineQuerier) ExecTx(ctx context.Context, fn func(FineQuerier) error) error {
+	return fn(m)
+}
+
+func testMoney(value string) pgtype.Numeric {
+	return decimalToNumeric(decimal.RequireFromString(value))
+}
+
+// createTestFine returns a 100.00 overdue fine for transaction 7 with the given amounts settled
+func createTestFine(paid, waived string) queries.Fine {
+	return queries.Fine{
+		ID:            1,
+		StudentID:     3,
+		TransactionID: pgtype.Int4{Int32: 7, Valid: true},
+		FineType:      "overdue",
+		Amount:        testMoney("100.00"),
+		AmountPaid:    testMoney(paid),
+		AmountWaived:  testMoney(waived),
+		Status:        "outstanding",
+	}
+}
+
+// expectFineUpdate mocks the writes that follow a successful ledger entry and
+// returns the fine as it is stored afterwards
+func expectFineUpdate(mockQuerier *MockFineQuerier, ctx context.Context, fine queries.Fine, amount, paid, waived string, status models.FineStatus) {
+	updated := fine
+	updated.Amount = testMoney(amount)
+	updated.AmountPaid = testMoney(paid)
+	updated.AmountWaived = testMoney(waived)
+	updated.Status = string(status)
+
+	mockQuerier.On("UpdateFineTotals", ctx, mock.MatchedBy(func(arg queries.UpdateFineTotalsParams) bool {
+		return arg.ID == fine.ID && arg.Status == string(status) &&
+			numericToDecimal(arg.Amount).Equal(decimal.RequireFromString(amount)) &&
+			numericToDecimal(arg.AmountPaid).Equal(decimal.RequireFromString(paid)) &&
+			numericToDecimal(arg.AmountWaived).Equal(decimal.RequireFromString(waived))
+	})).Return(updated, nil)
+	mockQuerier.On("CreateAuditLog", ctx, mock.AnythingOfType("queries.CreateAuditLogParams")).Return(nil)
+	mockQuerier.On("ListFinesByTransaction", ctx, fine.TransactionID).Return([]queries.Fine{updated}, nil)
+	mockQuerier.On("SetTransactionFinePaid", ctx, queries.SetTransactionFinePaidParams{
+		ID:       fine.TransactionID.Int32,
+		FinePaid: pgtype.Bool{Bool: status == models.FineStatusPaid || status == models.FineStatusWaived, Valid: true},
+	}).Return(nil)
+}
+
+func TestFineService_RecordPayment(t *testing.T) {
+	ctx := context.Background()
+	actor := AuditActor{UserID: 2, UserType: "librarian", IPAddress: "10.0.0.5"}
+
+	t.Run("PartialPaymentIssuesReceipt", func(t *testing.T) {
+		mockQuerier := new(MockFineQuerier)
+		service := NewFineService(mockQuerier)
+		fine := createTestFine("0", "0")
+
+		mockQuerier.On("GetFineByIDForUpdate", ctx, int32(1)).Return(fine, nil)
+		mockQuerier.On("NextFineReceiptNumber", ctx).Return(int64(42), nil)
+		mockQuerier.On("CreateFineLedgerEntry", ctx, mock.MatchedBy(func(arg queries.CreateFineLedgerEntryParams) bool {
+			return arg.EntryType == "payment" && arg.PaymentMethod.String == "cash" &&
+				strings.HasSuffix(arg.ReceiptNumber.String, "-000042") && arg.RecordedBy.Int32 == 2
+		})).Return(queries.FineLedgerEntry{
+			ID:            10,
+			FineID:        1,
+			StudentID:     3,
+			EntryType:     "payment",
+			Amount:        testMoney("40.00"),
+			PaymentMethod: pgtype.Text{String: "cash", Valid: true},
+			ReceiptNumber: pgtype.Text{String: "RCP-20260116-000042", Valid: true},
+		}, nil)
+		expectFineUpdate(mockQuerier, ctx, fine, "100.00", "40.00", "0", models.FineStatusPartiallyPaid)
+
+		result, err := service.RecordPayment(ctx, 1, models.RecordFinePaymentRequest{
+			Amount:        decimal.RequireFromString("40.00"),
+			PaymentMethod: "cash",
+		}, actor)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.FineStatusPartiallyPaid, result.Fine.Status)
+		assert.True(t, decimal.RequireFromString("60.00").Equal(result.Fine.Balance))
+		require.NotNil(t, result.Entry.ReceiptNumber)
+		assert.Equal(t, "RCP-20260116-000042", *result.Entry.ReceiptNumber)
+		mockQuerier.AssertNumberOfCalls(t, "CreateAuditLog", 2)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("ExceedsBalance", func(t *testing.T) {
+		mockQuerier := new(MockFineQuerier)
+		service := NewFineService(mockQuerier)
+
+		mockQuerier.On("GetFineByIDForUpdate", ctx, int32(1)).Return(createTestFine("80.00", "0"), nil)
+
+		_, err := service.RecordPayment(ctx, 1, models.RecordFinePaymentRequest{
+			Amount:        decimal.RequireFromString("25.00"),
+			PaymentMethod: "mpesa",
+		}, actor)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validation error")
+		assert.Contains(t, err.Error(), "exceeds the outstanding balance of 20.00")
+		mockQuerier.AssertNotCalled(t, "CreateFineLedgerEntry", mock.Anything, mock.Anything)
+	})
+
+	t.Run("FineNotFound", func(t *testing.T) {
+		mockQuerier := new(MockFineQuerier)
+		service := NewFineService(mockQuerier)
+
+		mockQuerier.On("GetFineByIDForUpdate", ctx, int32(99)).Return(queries.Fine{}, sql.ErrNoRows)
+
+		_, err := service.RecordPayment(ctx, 99, models.RecordFinePaymentRequest{
+			Amount:        decimal.RequireFromString("5.00"),
+			PaymentMethod: "cash",
+		}, actor)
+
+		require.Error(t, err)
+		assert.Equal(t, "fine not found", err.Error())
+	})
+}
+
+func TestFineService_WaiveFine(t *testing.T) {
+	ctx := context.Background()
+	actor := AuditActor{UserID: 2, UserType: "librarian"}
+
+	t.Run("WaivesRemainingBalance", func(t *testing.T) {
+		mockQuerier := new(MockFineQuerier)
+		service := NewFineService(mockQuerier)
+		fine := createTestFine("30.00", "0")
+
+		mockQuerier.On("GetFineByIDForUpdate", ctx, int32(1)).Return(fine, nil)
+		mockQuerier.On("CreateFineLedgerEntry", ctx, mock.MatchedBy(func(arg queries.CreateFineLedgerEntryParams) bool {
+			return arg.EntryType == "waiver" && !arg.ReceiptNumber.Valid && arg.Reason.String == "Hardship" &&
+				numericToDecimal(arg.Amount).Equal(decimal.RequireFromString("70.00"))
+		})).Return(queries.FineLedgerEntry{ID: 11, FineID: 1, EntryType: "waiver", Amount: testMoney("70.00")}, nil)
+		expectFineUpdate(mockQuerier, ctx, fine, "100.00", "30.00", "70.00", models.FineStatusPaid)
+
+		result, err := service.WaiveFine(ctx, 1, models.WaiveFineRequest{Reason: "Hardship"}, actor)
+
+		require.NoError(t, err)
+		assert.True(t, result.Fine.Balance.IsZero())
+		mockQuerier.AssertNotCalled(t, "NextFineReceiptNumber", mock.Anything)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("ReasonRequired", func(t *testing.T) {
+		service := NewFineService(new(MockFineQuerier))
+
+		_, err := service.WaiveFine(ctx, 1, models.WaiveFineRequest{Reason: "  "}, actor)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "reason is required")
+	})
+}
+
+func TestFineService_RefundPayment_ExceedsAmountPaid(t *testing.T) {
+	ctx := context.Background()
+	mockQuerier := new(MockFineQuerier)
+	service := NewFineService(mockQuerier)
+
+	mockQuerier.On("GetFineByIDForUpdate", ctx, int32(1)).Return(createTestFine("10.00", "0"), nil)
+
+	_, err := service.RefundPayment(ctx, 1, models.RefundFineRequest{
+		Amount:        decimal.RequireFromString("15.00"),
+		PaymentMethod: "cash",
+		Reason:        "Book found on shelf",
+	}, AuditActor{UserID: 2, UserType: "librarian"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the amount paid of 10.00")
+}
+
+func TestFineService_AdjustFine(t *testing.T) {
+	ctx := context.Background()
+	actor := AuditActor{UserID: 2, UserType: "librarian"}
+
+	t.Run("ReducesToAmountAlreadyPaid", func(t *testing.T) {
+		mockQuerier := new(MockFineQuerier)
+		service := NewFineService(mockQuerier)
+		fine := createTestFine("60.00", "0")
+
+		mockQuerier.On("GetFineByIDForUpdate", ctx, int32(1)).Return(fine, nil)
+		mockQuerier.On("CreateFineLedgerEntry", ctx, mock.MatchedBy(func(arg queries.CreateFineLedgerEntryParams) bool {
+			return arg.EntryType == "adjustment" && numericToDecimal(arg.Amount).Equal(decimal.RequireFromString("-40.00"))
+		})).Return(queries.FineLedgerEntry{ID: 12, FineID: 1, EntryType: "adjustment", Amount: testMoney("-40.00")}, nil)
+		expectFineUpdate(mockQuerier, ctx, fine, "60.00", "60.00", "0", models.FineStatusPaid)
+
+		result, err := service.AdjustFine(ctx, 1, models.AdjustFineRequest{
+			Amount: decimal.RequireFromString("-40.00"),
+			Reason: "Charged for closed days",
+		}, actor)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.FineStatusPaid, result.Fine.Status)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("BelowSettledAmount", func(t *testing.T) {
+		mockQuerier := new(MockFineQuerier)
+		service := NewFineService(mockQuerier)
+
+		mockQuerier.On("GetFineByIDForUpdate", ctx, int32(1)).Return(createTestFine("60.00", "0"), nil)
+
+		_, err := service.AdjustFine(ctx, 1, models.AdjustFineRequest{
+			Amount: decimal.RequireFromString("-50.00"),
+			Reason: "Charged for closed days",
+		}, actor)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validation error")
+	})
+}
+
+func TestFineStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		paid     string
+		waived   string
+		expected models.FineStatus
+	}{
+		{"Outstanding", "0", "0", models.FineStatusOutstanding},
+		{"PartiallyPaid", "20", "0", models.FineStatusPartiallyPaid},
+		{"PartiallyWaived", "0", "20", models.FineStatusPartiallyPaid},
+		{"Paid", "100", "0", models.FineStatusPaid},
+		{"PaidAndWaived", "50", "50", models.FineStatusPaid},
+		{"Waived", "0", "100", models.FineStatusWaived},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := fineStatus(decimal.NewFromInt(100), decimal.RequireFromString(tc.paid), decimal.RequireFromString(tc.waived))
+			assert.Equal(t, tc.expected, status)
+		})
+	}
+}
+
+func TestFineService_GetCashReconciliation(t *testing.T) {
+	ctx := context.Background()
+	mockQuerier := new(MockFineQuerier)
+	service := NewFineService(mockQuerier)
+
+	day := time.Date(2026, 1, 16, 15, 30, 0, 0, time.UTC)
+	librarian := pgtype.Int4{Int32: 2, Valid: true}
+	username := pgtype.Text{String: "desk1", Valid: true}
+	cash := pgtype.Text{String: "cash", Valid: true}
+	mpesa := pgtype.Text{String: "mpesa", Valid: true}
+
+	mockQuerier.On("ListFineLedgerEntriesBetween", ctx, queries.ListFineLedgerEntriesBetweenParams{
+		CreatedAt:   pgtype.Timestamp{Time: time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC), Valid: true},
+		CreatedAt_2: pgtype.Timestamp{Time: time.Date(2026, 1, 17, 0, 0, 0, 0, time.UTC), Valid: true},
+	}).Return([]queries.ListFineLedgerEntriesBetweenRow{
+		{ID: 1, EntryType: "payment", Amount: testMoney("50.00"), PaymentMethod: cash, RecordedBy: librarian, RecordedByUsername: username},
+		{ID: 2, EntryType: "payment", Amount: testMoney("30.00"), PaymentMethod: cash, RecordedBy: librarian, RecordedByUsername: username},
+		{ID: 3, EntryType: "refund", Amount: testMoney("10.00"), PaymentMethod: cash, RecordedBy: librarian, RecordedByUsername: username},
+		{ID: 4, EntryType: "payment", Amount: testMoney("20.00"), PaymentMethod: mpesa, RecordedBy: librarian, RecordedByUsername: username},
+		{ID: 5, EntryType: "waiver", Amount: testMoney("15.00"), RecordedBy: librarian, RecordedByUsername: username},
+	}, nil)
+
+	report, err := service.GetCashReconciliation(ctx, day)
+
+	require.NoError(t, err)
+	assert.Equal(t, "2026-01-16", report.Date)
+	assert.True(t, decimal.RequireFromString("100.00").Equal(report.Collected))
+	assert.True(t, decimal.RequireFromString("10.00").Equal(report.Refunded))
+	assert.True(t, decimal.RequireFromString("90.00").Equal(report.Net))
+	assert.Len(t, report.Entries, 4)
+
+	require.Len(t, report.Totals, 2)
+	assert.Equal(t, "cash", report.Totals[0].PaymentMethod)
+	assert.Equal(t, 2, report.Totals[0].PaymentCount)
+	assert.Equal(t, 1, report.Totals[0].RefundCount)
+	assert.True(t, decimal.RequireFromString("70.00").Equal(report.Totals[0].Net))
+	assert.Equal(t, "mpesa", report.Totals[1].PaymentMethod)
+	require.NotNil(t, report.Totals[1].RecordedByUsername)
+	assert.Equal(t, "desk1", *report.Totals[1].RecordedByUsername)
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// defaultPickupDays is how long a returned book waits on the hold shelf for the student who reserved it
+const defaultPickupDays = 3
+
+// HoldShelf passes on copies set aside for reservations that will not collect them
+type HoldShelf interface {
+	CancelReservation(ctx context.Context, reservationID int32) (queries.Reservation, error)
+	ExpireUncollectedHolds(ctx context.Context) (int, error)
+}
+
+// holdForNextReservation sets a returned copy aside for the next student in the
+// book's reservation queue and tells them it is ready to collect. It returns nil
+// when nobody is waiting. Loans issued before copies were tracked have no copy to
+// set aside, so the reservation is made ready without one.
+func (s *TransactionService) holdForNextReservation(ctx context.Context, bookID int32, copyID pgtype.Int4) (*queries.Reservation, error) {
+	next, err := s.queries.GetNextReservationForBook(ctx, bookID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get next reservation for book: %w", err)
+	}
+
+	deadline, err := s.pickupDeadline(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, err := s.queries.MarkReservationReady(ctx, queries.MarkReservationReadyParams{
+		ID:             next.ID,
+		CopyID:         copyID,
+		PickupDeadline: pgtype.Timestamp{Time: deadline, Valid: true},
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("reservation %d changed while the copy was being held", next.ID)
+		}
+		return nil, fmt.Errorf("failed to hold copy for reservation %d: %w", next.ID, err)
+	}
+
+	if copyID.Valid {
+		err = s.queries.UpdateBookCopyStatus(ctx, queries.UpdateBookCopyStatusParams{
+			ID:     copyID.Int32,
+			Status: pgtype.Text{String: string(models.BookCopyStatusOnHold), Valid: true},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update book copy status: %w", err)
+		}
+		if err := s.queries.SyncBookCopyCounts(ctx, bookID); err != nil {
+			return nil, fmt.Errorf("failed to update book availability: %w", err)
+		}
+	}
+
+	book, err := s.queries.GetBookByID(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+
+	notice, err := holdNotification(next, book, deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	s.announceReservation(reservation)
+	s.notifyStudent(ctx, notice)
+	return &reservation, nil
+}
+
+// pickupDeadline returns the last day to collect a held book, rolled forward to a day the library is open
+func (s *TransactionService) pickupDeadline(ctx context.Context) (time.Time, error) {
+	deadline := time.Now().AddDate(0, 0, s.pickupDays)
+	if s.calendar == nil {
+		return deadline, nil
+	}
+
+	deadline, err := s.calendar.NextOpenDay(ctx, deadline)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to calculate pickup deadline: %w", err)
+	}
+	return deadline, nil
+}
+
+// readyHold locks the student's ready reservation for a book, returning nil if nothing is held for them
+func (s *TransactionService) readyHold(ctx context.Context, studentID, bookID int32) (*queries.Reservation, error) {
+	hold, err := s.queries.GetReadyReservationForStudent(ctx, queries.GetReadyReservationForStudentParams{
+		StudentID: studentID,
+		BookID:    bookID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to check hold shelf: %w", err)
+	}
+	return &hold, nil
+}
+
+// collectHold fulfils the reservation a student is borrowing against. If the
+// student was issued a different copy, the one held for them is passed on.
+func (s *TransactionService) collectHold(ctx context.Context, hold queries.Reservation, issuedCopyID int32) error {
+	fulfilled, err := s.queries.UpdateReservationStatus(ctx, queries.UpdateReservationStatusParams{
+		ID:          hold.ID,
+		Status:      pgtype.Text{String: models.ReservationStatusFulfilled, Valid: true},
+		FulfilledAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fulfill reservation %d: %w", hold.ID, err)
+	}
+	s.announceReservation(fulfilled)
+
+	if hold.CopyID.Valid && hold.CopyID.Int32 != issuedCopyID {
+		return s.releaseHeldCopy(ctx, hold.BookID, hold.CopyID.Int32)
+	}
+	return nil
+}
+
+// CancelReservation cancels a reservation and passes on the copy waiting on the
+// hold shelf for it in the same database transaction, so a cancelled hold never
+// leaves its copy set aside for nobody
+func (s *TransactionService) CancelReservation(ctx context.Context, reservationID int32) (queries.Reservation, error) {
+	var reservation queries.Reservation
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		var err error
+		reservation, err = tx.queries.CancelReservation(ctx, reservationID)
+		if err != nil {
+			return err
+		}
+		tx.announceReservation(reservation)
+
+		if reservation.CopyID.Valid && !reservation.FulfilledAt.Valid {
+			return tx.releaseHeldCopy(ctx, reservation.BookID, reservation.CopyID.Int32)
+		}
+		return nil
+	})
+	return reservation, err
+}
+
+// releaseHeldCopy offers a held copy to the next reservation in the queue, or
+// puts it back on the shelf when nobody else is waiting
+func (s *TransactionService) releaseHeldCopy(ctx context.Context, bookID, copyID int32) error {
+	bookCopy, err := s.queries.GetBookCopyByIDForUpdate(ctx, copyID)
+	if err != nil {
+		return fmt.Errorf("failed to get book copy: %w", err)
+	}
+
+	// The copy may have been withdrawn or reported lost while it was on the shelf
+	if bookCopy.Status.String != string(models.BookCopyStatusOnHold) {
+		return nil
+	}
+
+	hold, err := s.holdForNextReservation(ctx, bookID, pgtype.Int4{Int32: copyID, Valid: true})
+	if err != nil || hold != nil {
+		return err
+	}
+
+	err = s.queries.UpdateBookCopyStatus(ctx, queries.UpdateBookCopyStatusParams{
+		ID:     copyID,
+		Status: pgtype.Text{String: string(models.BookCopyStatusAvailable), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update book copy status: %w", err)
+	}
+
+	if err := s.queries.SyncBookCopyCounts(ctx, bookID); err != nil {
+		return fmt.Errorf("failed to update book availability: %w", err)
+	}
+	s.announceCounters()
+	return nil
+}
+
+// ExpireUncollectedHolds expires ready reservations whose pickup deadline has
+// passed and offers each held copy to the next student waiting for the book.
+// Each hold is expired in its own database transaction.
+func (s *TransactionService) ExpireUncollectedHolds(ctx context.Context) (int, error) {
+	holds, err := s.queries.ListExpiredHolds(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired holds: %w", err)
+	}
+
+	expiredCount := 0
+	for _, hold := range holds {
+		var expired bool
+		err := s.execTx(ctx, func(tx *TransactionService) error {
+			var err error
+			expired, err = tx.expireHold(ctx, hold.ID)
+			return err
+		})
+		if err != nil {
+			return expiredCount, fmt.Errorf("failed to expire hold %d: %w", hold.ID, err)
+		}
+		if expired {
+			expiredCount++
+		}
+	}
+
+	return expiredCount, nil
+}
+
+// expireHold expires one uncollected hold, reporting false if it was collected or cancelled since it was listed
+func (s *TransactionService) expireHold(ctx context.Context, reservationID int32) (bool, error) {
+	reservation, err := s.queries.ExpireHold(ctx, reservationID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	s.announceReservation(reservation)
+
+	if reservation.CopyID.Valid {
+		return true, s.releaseHeldCopy(ctx, reservation.BookID, reservation.CopyID.Int32)
+	}
+
+	_, err = s.holdForNextReservation(ctx, reservation.BookID, reservation.CopyID)
+	return true, err
+}
+
+// holdNotification builds the notice telling a student their reserved book is ready to collect
+func holdNotification(reservation queries.GetNextReservationForBookRow, book queries.Book, deadline time.Time) (*models.NotificationRequest, error) {
+	return studentNotice(reservation.StudentID, models.NotificationTypeReservationReady, models.NotificationPriorityHigh, reservation.Locale, map[string]interface{}{
+		"StudentName":    reservation.FirstName + " " + reservation.LastName,
+		"BookTitle":      book.Title,
+		"BookAuthor":     book.Author,
+		"PickupDeadline": FormatDate(deadline, reservation.Locale),
+	})
+}
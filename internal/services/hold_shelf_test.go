@@ -0,0 +1,385 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockHoldShelf is a mock implementation of HoldShelf
+type MockHoldShelf struct {
+	mock.Mock
+}
+
+func (m *MockHoldShelf) CancelReservation(ctx context.Context, reservationID int32) (queries.Reservation, error) {
+	args := m.Called(ctx, reservationID)
+	return args.Get(0).(queries.Reservation), args.Error(1)
+}
+
+func (m *MockHoldShelf) ExpireUncollectedHolds(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+// createTestHold returns a reservation for book 1 with copy 10 waiting on the hold shelf
+func createTestHold(now time.Time) queries.Reservation {
+	return queries.Reservation{
+		ID:             40,
+		StudentID:      2,
+		BookID:         1,
+		Status:         pgtype.Text{String: "ready", Valid: true},
+		CopyID:         pgtype.Int4{Int32: 10, Valid: true},
+		ReadyAt:        pgtype.Timestamp{Time: now, Valid: true},
+		PickupDeadline: pgtype.Timestamp{Time: now.AddDate(0, 0, defaultPickupDays), Valid: true},
+	}
+}
+
+func expectCopyStatus(mockQueries *MockTransactionQueries, ctx context.Context, copyID int32, status string) {
+	mockQueries.On("UpdateBookCopyStatus", ctx, queries.UpdateBookCopyStatusParams{
+		ID:     copyID,
+		Status: pgtype.Text{String: status, Valid: true},
+	}).Return(nil)
+}
+
+// expectReturnHoldsCopy sets up the return of copy 10 straight onto the hold shelf
+// for reservation 40, and the notice telling the student it is ready
+func expectReturnHoldsCopy(mockQueries *MockTransactionQueries, notifier *MockStudentNotifier, ctx context.Context, now time.Time) {
+	bookCopy := createTestBookCopy()
+	bookCopy.Status = pgtype.Text{String: "borrowed", Valid: true}
+
+	returned := createTestTransaction()
+	returned.CopyID = pgtype.Int4{Int32: bookCopy.ID, Valid: true}
+	returned.ReturnedDate = pgtype.Timestamp{Time: now, Valid: true}
+
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(createTestLoanRow(bookCopy.ID), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, int32(1)).Return(int32(1), nil)
+	mockQueries.On("ReturnBook", ctx, mock.AnythingOfType("queries.ReturnBookParams")).Return(returned, nil)
+	mockQueries.On("CloseRenewedLoanTransactions", ctx, mock.AnythingOfType("int32")).Return(nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(createTestBook(), nil)
+	mockQueries.On("GetBookCopyByIDForUpdate", ctx, bookCopy.ID).Return(bookCopy, nil)
+	expectCopyStatus(mockQueries, ctx, bookCopy.ID, "available")
+	mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+
+	mockQueries.On("GetNextReservationForBook", ctx, int32(1)).Return(queries.GetNextReservationForBookRow{
+		ID: 40, StudentID: 2, BookID: 1, FirstName: "Jane", LastName: "Roe", Locale: "sw",
+	}, nil)
+	mockQueries.On("MarkReservationReady", ctx, mock.MatchedBy(func(arg queries.MarkReservationReadyParams) bool {
+		return arg.ID == 40 && arg.CopyID.Int32 == bookCopy.ID &&
+			arg.PickupDeadline.Time.After(now.AddDate(0, 0, defaultPickupDays-1))
+	})).Return(createTestHold(now), nil)
+	expectCopyStatus(mockQueries, ctx, bookCopy.ID, "on_hold")
+	mockQueries.On("GetBookByID", ctx, int32(1)).Return(createTestBook(), nil)
+	notifier.On("CreateNotification", ctx, mock.MatchedBy(func(req *models.NotificationRequest) bool {
+		return req.RecipientID == 2 && req.Type == models.NotificationTypeReservationReady && req.Locale == "sw" &&
+			req.Priority == models.NotificationPriorityHigh &&
+			assert.ObjectsAreEqual(emailAndSMS, req.Channels) &&
+			req.Title == "Kitabu Ulichohifadhi Kiko Tayari - Test Book" &&
+			strings.Contains(req.Message, "Mpendwa Jane Roe") &&
+			req.TemplateData["PickupDeadline"] != nil
+	})).Return(&models.NotificationResponse{ID: 77}, nil)
+}
+
+func TestEnhancedTransactionService_ReturnBook_HoldsCopy(t *testing.T) {
+	ctx := context.Background()
+	mockQueries := &MockTransactionQueries{}
+	notifier := &MockStudentNotifier{}
+	service := NewEnhancedTransactionService(mockQueries, nil)
+	service.WithNotifier(notifier)
+
+	expectReturnHoldsCopy(mockQueries, notifier, ctx, time.Now())
+
+	result, err := service.ReturnBook(ctx, 1, testLibrarian)
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), result.ID)
+	mockQueries.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestEnhancedTransactionService_ReturnBook_AnnouncesHold(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("announces after commit", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		publisher := &MockRealtimePublisher{}
+		notifier := &MockStudentNotifier{}
+		service := NewEnhancedTransactionService(mockQueries, nil)
+		service.WithRealtime(publisher).WithNotifier(notifier)
+
+		now := time.Now()
+		expectReturnHoldsCopy(mockQueries, notifier, ctx, now)
+		publisher.On("ReservationChanged", ctx, createTestHold(now)).Return()
+		publisher.On("CountersChanged", ctx).Return()
+
+		_, err := service.ReturnBook(ctx, 1, testLibrarian)
+
+		require.NoError(t, err)
+		publisher.AssertExpectations(t)
+		notifier.AssertExpectations(t)
+		publisher.AssertNumberOfCalls(t, "CountersChanged", 1)
+	})
+
+	t.Run("announces nothing when the return rolls back", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		publisher := &MockRealtimePublisher{}
+		notifier := &MockStudentNotifier{}
+		service := NewEnhancedTransactionService(mockQueries, nil)
+		service.WithRealtime(publisher).WithNotifier(notifier)
+
+		now := time.Now()
+		bookCopy := createTestBookCopy()
+		bookCopy.Status = pgtype.Text{String: "borrowed", Valid: true}
+		returned := createTestTransaction()
+		returned.CopyID = pgtype.Int4{Int32: bookCopy.ID, Valid: true}
+		returned.ReturnedDate = pgtype.Timestamp{Time: now, Valid: true}
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(createTestLoanRow(bookCopy.ID), nil)
+		mockQueries.On("GetCurrentLoanTransactionID", ctx, int32(1)).Return(int32(1), nil)
+		mockQueries.On("ReturnBook", ctx, mock.AnythingOfType("queries.ReturnBookParams")).Return(returned, nil)
+		mockQueries.On("CloseRenewedLoanTransactions", ctx, mock.AnythingOfType("int32")).Return(nil)
+		mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(createTestBook(), nil)
+		mockQueries.On("GetBookCopyByIDForUpdate", ctx, bookCopy.ID).Return(bookCopy, nil)
+		expectCopyStatus(mockQueries, ctx, bookCopy.ID, "available")
+		mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+		mockQueries.On("GetNextReservationForBook", ctx, int32(1)).Return(queries.GetNextReservationForBookRow{
+			ID: 40, StudentID: 2, BookID: 1,
+		}, nil)
+		mockQueries.On("MarkReservationReady", ctx, mock.Anything).Return(queries.Reservation{}, assert.AnError)
+
+		_, err := service.ReturnBook(ctx, 1, testLibrarian)
+
+		require.Error(t, err)
+		notifier.AssertNotCalled(t, "CreateNotification", mock.Anything, mock.Anything)
+		publisher.AssertNotCalled(t, "ReservationChanged", mock.Anything, mock.Anything)
+		publisher.AssertNotCalled(t, "CountersChanged", mock.Anything)
+	})
+}
+
+func TestTransactionService_BorrowBook_CollectsHold(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("IssuesHeldCopy", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		// The only copy is on the hold shelf, so the title shows none available
+		book := createTestBook()
+		book.AvailableCopies = pgtype.Int4{Int32: 0, Valid: true}
+		student := createTestStudent()
+		student.ID = 2
+		heldCopy := createTestBookCopy()
+		heldCopy.Status = pgtype.Text{String: "on_hold", Valid: true}
+
+		mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(book, nil)
+		mockQueries.On("GetStudentByIDForUpdate", ctx, int32(2)).Return(student, nil)
+		mockQueries.On("GetReadyReservationForStudent", ctx, queries.GetReadyReservationForStudentParams{
+			StudentID: 2,
+			BookID:    1,
+		}).Return(createTestHold(time.Now()), nil)
+		mockQueries.On("ListActiveTransactionsByStudent", ctx, int32(2)).Return([]queries.ListActiveTransactionsByStudentRow{}, nil)
+		mockQueries.On("GetBookCopyByIDForUpdate", ctx, heldCopy.ID).Return(heldCopy, nil)
+		mockQueries.On("CreateTransaction", ctx, mock.MatchedBy(func(arg queries.CreateTransactionParams) bool {
+			return arg.CopyID.Int32 == heldCopy.ID
+		})).Return(createTestTransaction(), nil)
+		expectCopyStatus(mockQueries, ctx, heldCopy.ID, "borrowed")
+		mockQueries.On("UpdateReservationStatus", ctx, mock.MatchedBy(func(arg queries.UpdateReservationStatusParams) bool {
+			return arg.ID == 40 && arg.Status.String == "fulfilled" && arg.FulfilledAt.Valid
+		})).Return(queries.Reservation{}, nil)
+		mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+
+		result, err := service.BorrowBook(ctx, 2, 1, 1, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, heldCopy.Barcode, result.Barcode)
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("HeldCopyRefusedToOtherStudent", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		heldCopy := createTestBookCopy()
+		heldCopy.Status = pgtype.Text{String: "on_hold", Valid: true}
+
+		mockQueries.On("GetBookCopyByBarcode", ctx, heldCopy.Barcode).Return(heldCopy, nil)
+		mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(createTestBook(), nil)
+		mockQueries.On("GetStudentByIDForUpdate", ctx, int32(1)).Return(createTestStudent(), nil)
+		mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, pgx.ErrNoRows)
+		mockQueries.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{}, nil)
+		mockQueries.On("GetBookCopyByBarcodeForUpdate", ctx, heldCopy.Barcode).Return(heldCopy, nil)
+
+		_, err := service.BorrowBookByBarcode(ctx, 1, heldCopy.Barcode, 1, "")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "on the hold shelf for another student")
+		mockQueries.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTransactionService_ExpireUncollectedHolds(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("PassesCopyToNextReservation", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		notifier := &MockStudentNotifier{}
+		service := NewTransactionService(mockQueries).WithNotifier(notifier)
+
+		now := time.Now()
+		hold := createTestHold(now.AddDate(0, 0, -4))
+		heldCopy := createTestBookCopy()
+		heldCopy.Status = pgtype.Text{String: "on_hold", Valid: true}
+
+		mockQueries.On("ListExpiredHolds", ctx).Return([]queries.Reservation{hold}, nil)
+		mockQueries.On("ExpireHold", ctx, hold.ID).Return(hold, nil)
+		mockQueries.On("GetBookCopyByIDForUpdate", ctx, heldCopy.ID).Return(heldCopy, nil)
+		mockQueries.On("GetNextReservationForBook", ctx, int32(1)).Return(queries.GetNextReservationForBookRow{
+			ID: 41, StudentID: 3, BookID: 1,
+		}, nil)
+		mockQueries.On("MarkReservationReady", ctx, mock.MatchedBy(func(arg queries.MarkReservationReadyParams) bool {
+			return arg.ID == 41 && arg.CopyID.Int32 == heldCopy.ID
+		})).Return(queries.Reservation{ID: 41}, nil)
+		expectCopyStatus(mockQueries, ctx, heldCopy.ID, "on_hold")
+		mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+		mockQueries.On("GetBookByID", ctx, int32(1)).Return(createTestBook(), nil)
+		notifier.On("CreateNotification", ctx, mock.MatchedBy(func(req *models.NotificationRequest) bool {
+			return req.RecipientID == 3
+		})).Return(&models.NotificationResponse{}, nil)
+
+		count, err := service.ExpireUncollectedHolds(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		mockQueries.AssertExpectations(t)
+		notifier.AssertExpectations(t)
+	})
+
+	t.Run("ReshelvesCopyWhenNobodyIsWaiting", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		hold := createTestHold(time.Now().AddDate(0, 0, -4))
+		heldCopy := createTestBookCopy()
+		heldCopy.Status = pgtype.Text{String: "on_hold", Valid: true}
+
+		mockQueries.On("ListExpiredHolds", ctx).Return([]queries.Reservation{hold}, nil)
+		mockQueries.On("ExpireHold", ctx, hold.ID).Return(hold, nil)
+		mockQueries.On("GetBookCopyByIDForUpdate", ctx, heldCopy.ID).Return(heldCopy, nil)
+		mockQueries.On("GetNextReservationForBook", ctx, int32(1)).Return(queries.GetNextReservationForBookRow{}, pgx.ErrNoRows)
+		expectCopyStatus(mockQueries, ctx, heldCopy.ID, "available")
+		mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+
+		count, err := service.ExpireUncollectedHolds(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("SkipsHoldCollectedSinceListed", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		hold := createTestHold(time.Now().AddDate(0, 0, -4))
+		mockQueries.On("ListExpiredHolds", ctx).Return([]queries.Reservation{hold}, nil)
+		mockQueries.On("ExpireHold", ctx, hold.ID).Return(queries.Reservation{}, pgx.ErrNoRows)
+
+		count, err := service.ExpireUncollectedHolds(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		mockQueries.AssertNotCalled(t, "GetBookCopyByIDForUpdate", mock.Anything, mock.Anything)
+	})
+}
+
+func TestReservationService_CancelReservation_ReleasesHold(t *testing.T) {
+	ctx := context.Background()
+	mockQuerier := &MockReservationQuerier{}
+	holds := &MockHoldShelf{}
+	service := NewReservationService(mockQuerier).WithHoldShelf(holds)
+
+	hold := createTestHold(time.Now())
+	hold.Status = pgtype.Text{String: "cancelled", Valid: true}
+
+	holds.On("CancelReservation", ctx, hold.ID).Return(hold, nil)
+
+	result, err := service.CancelReservation(ctx, hold.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", result.Status)
+	holds.AssertExpectations(t)
+	mockQuerier.AssertNotCalled(t, "CancelReservation", mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_CancelReservation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ReshelvesHeldCopy", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		hold := createTestHold(time.Now())
+		hold.Status = pgtype.Text{String: "cancelled", Valid: true}
+		heldCopy := createTestBookCopy()
+		heldCopy.Status = pgtype.Text{String: "on_hold", Valid: true}
+
+		mockQueries.On("CancelReservation", ctx, hold.ID).Return(hold, nil)
+		mockQueries.On("GetBookCopyByIDForUpdate", ctx, heldCopy.ID).Return(heldCopy, nil)
+		mockQueries.On("GetNextReservationForBook", ctx, int32(1)).Return(queries.GetNextReservationForBookRow{}, pgx.ErrNoRows)
+		expectCopyStatus(mockQueries, ctx, heldCopy.ID, "available")
+		mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+
+		reservation, err := service.CancelReservation(ctx, hold.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, "cancelled", reservation.Status.String)
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("FailedReleaseFailsCancellation", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		hold := createTestHold(time.Now())
+		mockQueries.On("CancelReservation", ctx, hold.ID).Return(hold, nil)
+		mockQueries.On("GetBookCopyByIDForUpdate", ctx, int32(10)).Return(queries.BookCopy{}, pgx.ErrTxClosed)
+
+		_, err := service.CancelReservation(ctx, hold.ID)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get book copy")
+	})
+
+	t.Run("NoCopyHeld", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		reservation := queries.Reservation{ID: 41, BookID: 1, Status: pgtype.Text{String: "cancelled", Valid: true}}
+		mockQueries.On("CancelReservation", ctx, reservation.ID).Return(reservation, nil)
+
+		_, err := service.CancelReservation(ctx, reservation.ID)
+
+		require.NoError(t, err)
+		mockQueries.AssertNotCalled(t, "GetBookCopyByIDForUpdate", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHoldNotification(t *testing.T) {
+	next := queries.GetNextReservationForBookRow{StudentID: 2, FirstName: "Jane", LastName: "Roe", Locale: "sw"}
+	deadline := time.Date(2025, time.March, 14, 17, 0, 0, 0, time.UTC)
+
+	notice, err := holdNotification(next, createTestBook(), deadline)
+
+	require.NoError(t, err)
+	assert.Equal(t, "14 Machi 2025", notice.TemplateData["PickupDeadline"])
+	assert.Contains(t, notice.Message, "Kichukue Kabla ya: 14 Machi 2025")
+}
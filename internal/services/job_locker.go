@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireLockScript sets the lock if it is free and extends it if the owner
+// already holds it, in one step so two instances cannot both win
+var acquireLockScript = redis.NewScript(`
+local holder = redis.call('GET', KEYS[1])
+if holder == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+if not holder then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseLockScript deletes the lock only if the owner still holds it, so an
+// instance whose lock lapsed cannot release someone else's
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisJobLocker keeps job locks in Redis so every instance sees the same holder
+type RedisJobLocker struct {
+	redis *redis.Client
+}
+
+// NewRedisJobLocker creates a job locker backed by Redis
+func NewRedisJobLocker(redisClient *redis.Client) *RedisJobLocker {
+	return &RedisJobLocker{
+		redis: redisClient,
+	}
+}
+
+// Acquire takes the lock for owner, or extends it if owner already holds it
+func (l *RedisJobLocker) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	acquired, err := acquireLockScript.Run(ctx, l.redis, []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return acquired == 1, nil
+}
+
+// Release gives up the lock if owner still holds it
+func (l *RedisJobLocker) Release(ctx context.Context, key, owner string) error {
+	return releaseLockScript.Run(ctx, l.redis, []string{key}, owner).Err()
+}
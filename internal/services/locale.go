@@ -0,0 +1,74 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+var swahiliMonths = [...]string{
+	"Januari", "Februari", "Machi", "Aprili", "Mei", "Juni",
+	"Julai", "Agosti", "Septemba", "Oktoba", "Novemba", "Desemba",
+}
+
+// NormalizeLocale maps a language tag such as "sw-KE" or "en_GB" onto a supported
+// locale. Empty and unsupported tags map to the default locale.
+func NormalizeLocale(tag string) string {
+	language := strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	if models.IsSupportedLocale(language) {
+		return language
+	}
+	return models.DefaultLocale
+}
+
+// localeFallbacks lists the locales to look for a template in, most preferred
+// first: the recipient's own locale, then the default locale
+func localeFallbacks(locale string) []string {
+	locale = NormalizeLocale(locale)
+	if locale == models.DefaultLocale {
+		return []string{locale}
+	}
+	return []string{locale, models.DefaultLocale}
+}
+
+// FormatDate writes a date the way it is written in locale, as in
+// "March 5, 2025" or "5 Machi 2025"
+func FormatDate(t time.Time, locale string) string {
+	if NormalizeLocale(locale) == models.LocaleSwahili {
+		return fmt.Sprintf("%d %s %d", t.Day(), swahiliMonths[t.Month()-1], t.Year())
+	}
+	return t.Format("January 2, 2006")
+}
+
+// FormatMoney writes an amount in Kenya shillings the way it is written in
+// locale, as in "KES 1,250.00" or "KSh 1,250.00"
+func FormatMoney(amount decimal.Decimal, locale string) string {
+	symbol := "KES"
+	if NormalizeLocale(locale) == models.LocaleSwahili {
+		symbol = "KSh"
+	}
+
+	fixed := amount.Abs().StringFixed(2)
+	whole, fraction := fixed[:len(fixed)-3], fixed[len(fixed)-3:]
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	sign := ""
+	if amount.IsNegative() {
+		sign = "-"
+	}
+	return sign + symbol + " " + grouped.String() + fraction
+}
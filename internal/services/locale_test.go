@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"sw", "sw"},
+		{"sw-KE", "sw"},
+		{"SW_ke", "sw"},
+		{"en-GB", "en"},
+		{"fr", "en"},
+		{"", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeLocale(tt.tag))
+		})
+	}
+}
+
+func TestLocaleFallbacks(t *testing.T) {
+	assert.Equal(t, []string{"sw", "en"}, localeFallbacks("sw"))
+	assert.Equal(t, []string{"en"}, localeFallbacks("en"))
+	assert.Equal(t, []string{"en"}, localeFallbacks("fr"))
+}
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2025, time.March, 5, 14, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "March 5, 2025", FormatDate(date, "en"))
+	assert.Equal(t, "5 Machi 2025", FormatDate(date, "sw"))
+	assert.Equal(t, "March 5, 2025", FormatDate(date, ""))
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount string
+		locale string
+		want   string
+	}{
+		{"english", "1250", "en", "KES 1,250.00"},
+		{"swahili", "1250", "sw", "KSh 1,250.00"},
+		{"small amount", "7.5", "en", "KES 7.50"},
+		{"millions", "1234567.891", "sw", "KSh 1,234,567.89"},
+		{"negative", "-300", "en", "-KES 300.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FormatMoney(decimal.RequireFromString(tt.amount), tt.locale))
+		})
+	}
+}
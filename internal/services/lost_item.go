@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// foundItemReason is recorded on the ledger entries that reverse a lost item charge
+const foundItemReason = "Lost item found"
+
+// MarkLost closes a loan whose copy the student has lost. Overdue fines stop at
+// this point, the student is charged the replacement cost and processing fee, and
+// the copy no longer counts towards the title's total copies.
+func (s *TransactionService) MarkLost(ctx context.Context, transactionID int32, notes string, actor AuditActor) (*TransactionResponse, error) {
+	return s.closeMissingLoan(ctx, transactionID, models.LossStatusLost, notes, actor)
+}
+
+// MarkClaimedReturned closes a loan the student says was returned but that the
+// library cannot find. It is charged and counted like a lost copy until found.
+func (s *TransactionService) MarkClaimedReturned(ctx context.Context, transactionID int32, notes string, actor AuditActor) (*TransactionResponse, error) {
+	return s.closeMissingLoan(ctx, transactionID, models.LossStatusClaimedReturned, notes, actor)
+}
+
+func (s *TransactionService) closeMissingLoan(ctx context.Context, transactionID int32, status models.LossStatus, notes string, actor AuditActor) (*TransactionResponse, error) {
+	var response *TransactionResponse
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		var err error
+		response, err = tx.closeMissingLoanTx(ctx, transactionID, status, notes, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// closeMissingLoanTx performs the lost or claimed-returned steps; it must run inside ExecTx
+func (s *TransactionService) closeMissingLoanTx(ctx context.Context, transactionID int32, status models.LossStatus, notes string, actor AuditActor) (*TransactionResponse, error) {
+	transactionRow, err := s.lockLoan(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateReturnTransaction(transactionRow); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.resolvePolicy(ctx, transactionPolicyContext(transactionRow))
+	if err != nil {
+		return nil, err
+	}
+
+	// Overdue fines accrue only until the copy is reported missing
+	fine, err := s.overdueFine(ctx, transactionRow, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.queries.CloseTransactionAsMissing(ctx, queries.CloseTransactionAsMissingParams{
+		ID:             transactionRow.ID,
+		FineAmount:     fineToNumeric(fine),
+		LossStatus:     pgtype.Text{String: string(status), Valid: true},
+		ConditionNotes: pgtype.Text{String: notes, Valid: notes != ""},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to close loan: %w", err)
+	}
+	if err := s.queries.CloseRenewedLoanTransactions(ctx, transactionRow.ID); err != nil {
+		return nil, fmt.Errorf("failed to close renewed loan: %w", err)
+	}
+	s.announceCounters()
+
+	var charges []models.FineResponse
+	if fine.IsPositive() {
+		charge, err := s.chargeLoan(ctx, transactionRow, models.FineTypeOverdue, fine, "", actor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record overdue fine: %w", err)
+		}
+		charges = append(charges, charge)
+	}
+
+	if replacement := policy.lostItemCharge(); replacement.IsPositive() {
+		description := fmt.Sprintf("Replacement cost %s + processing fee %s", policy.ReplacementCost.StringFixed(2), policy.ProcessingFee.StringFixed(2))
+		charge, err := s.chargeLoan(ctx, transactionRow, models.FineTypeLost, replacement, description, actor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record replacement charge: %w", err)
+		}
+		charges = append(charges, charge)
+	}
+
+	barcode, err := s.setMissingCopyStatus(ctx, transactionRow, "lost")
+	if err != nil {
+		return nil, err
+	}
+
+	response := s.convertToTransactionResponse(transaction)
+	response.Barcode = barcode
+	response.Charges = charges
+
+	if err := writeAuditLog(ctx, s.queries, actor, "transactions", transactionRow.ID, "UPDATE", s.convertToTransactionResponse(lockedRowTransaction(queries.GetTransactionByIDForUpdateRow(transactionRow))), response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// MarkFound restores a lost or claimed-returned copy that has turned up. The copy
+// is put back into circulation, going to the hold shelf first when a reservation
+// is waiting for the book, and the replacement charge is reversed; money already
+// paid towards it is refunded with the given method.
+func (s *TransactionService) MarkFound(ctx context.Context, transactionID int32, req models.MarkLoanFoundRequest, actor AuditActor) (*TransactionResponse, error) {
+	if req.ReturnCondition != "" {
+		if err := s.validateReturnCondition(req.ReturnCondition); err != nil {
+			return nil, err
+		}
+	}
+
+	var response *TransactionResponse
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		var err error
+		response, err = tx.markFoundTx(ctx, transactionID, req, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// markFoundTx performs the found item steps; it must run inside ExecTx
+func (s *TransactionService) markFoundTx(ctx context.Context, transactionID int32, req models.MarkLoanFoundRequest, actor AuditActor) (*TransactionResponse, error) {
+	lockedRow, err := s.queries.GetTransactionByIDForUpdate(ctx, transactionID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("transaction not found")
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	transactionRow := queries.GetTransactionByIDRow(lockedRow)
+
+	status := models.LossStatus(transactionRow.LossStatus.String)
+	if status != models.LossStatusLost && status != models.LossStatusClaimedReturned {
+		return nil, fmt.Errorf("loan is not marked lost or claimed returned")
+	}
+
+	charges, err := s.reverseLostItemCharges(ctx, transactionRow, req, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.queries.SetTransactionLossStatus(ctx, queries.SetTransactionLossStatusParams{
+		ID:         transactionID,
+		LossStatus: pgtype.Text{String: string(models.LossStatusFound), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update loan: %w", err)
+	}
+
+	barcode, err := s.setMissingCopyStatus(ctx, transactionRow, "available")
+	if err != nil {
+		return nil, err
+	}
+	s.announceCounters()
+
+	// A student waiting for the book gets the copy before it goes back on the shelf
+	if _, err := s.holdForNextReservation(ctx, transactionRow.BookID, transactionRow.CopyID); err != nil {
+		return nil, err
+	}
+
+	if req.ReturnCondition != "" && transactionRow.CopyID.Valid {
+		bookCopy, err := s.queries.GetBookCopyByIDForUpdate(ctx, transactionRow.CopyID.Int32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get book copy: %w", err)
+		}
+		if err := s.updateBookConditionIfNeeded(ctx, bookCopy, req.ReturnCondition); err != nil {
+			return nil, fmt.Errorf("failed to update book condition: %w", err)
+		}
+	}
+
+	response := s.convertToTransactionResponse(transaction)
+	response.Barcode = barcode
+	response.Charges = charges
+
+	if err := writeAuditLog(ctx, s.queries, actor, "transactions", transactionRow.ID, "UPDATE", s.convertToTransactionResponse(lockedRowTransaction(queries.GetTransactionByIDForUpdateRow(transactionRow))), response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// reverseLostItemCharges cancels the replacement charges raised for a loan.
+// Payments are refunded first, then the charge is adjusted down to what was waived.
+func (s *TransactionService) reverseLostItemCharges(ctx context.Context, tx queries.GetTransactionByIDRow, req models.MarkLoanFoundRequest, actor AuditActor) ([]models.FineResponse, error) {
+	fines, err := s.queries.ListFinesByTransaction(ctx, pgtype.Int4{Int32: tx.ID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loan fines: %w", err)
+	}
+
+	var reversed []models.FineResponse
+	for _, listed := range fines {
+		if models.FineType(listed.FineType) != models.FineTypeLost {
+			continue
+		}
+
+		fine, err := s.queries.GetFineByIDForUpdate(ctx, listed.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fine: %w", err)
+		}
+
+		current := convertToFineResponse(fine)
+		if current.AmountPaid.IsPositive() {
+			if req.RefundMethod == nil {
+				return nil, fmt.Errorf("validation error: %s has been paid towards fine %d; a refund_method is required to reverse it", current.AmountPaid.StringFixed(2), fine.ID)
+			}
+
+			result, err := recordLedgerEntry(ctx, s.queries, fine, ledgerEntry{
+				entryType:     models.FineLedgerEntryRefund,
+				amount:        current.AmountPaid,
+				paymentMethod: *req.RefundMethod,
+				reference:     req.Reference,
+				reason:        foundItemReason,
+			}, actor)
+			if err != nil {
+				return nil, err
+			}
+			current = result.Fine
+
+			// Re-read the row the refund updated before adjusting it
+			fine, err = s.queries.GetFineByIDForUpdate(ctx, fine.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get fine: %w", err)
+			}
+		}
+
+		remaining := current.Amount.Sub(current.AmountWaived)
+		if remaining.IsPositive() {
+			result, err := recordLedgerEntry(ctx, s.queries, fine, ledgerEntry{
+				entryType: models.FineLedgerEntryAdjustment,
+				amount:    remaining.Neg(),
+				reason:    foundItemReason,
+			}, actor)
+			if err != nil {
+				return nil, err
+			}
+			current = result.Fine
+		}
+
+		reversed = append(reversed, current)
+	}
+
+	return reversed, nil
+}
+
+// setMissingCopyStatus moves the loan's copy in or out of circulation and returns
+// its barcode. Loans issued before copies were tracked adjust the title counters.
+func (s *TransactionService) setMissingCopyStatus(ctx context.Context, tx queries.GetTransactionByIDRow, status string) (string, error) {
+	if !tx.CopyID.Valid {
+		book, err := s.queries.GetBookByIDForUpdate(ctx, tx.BookID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get book for availability update: %w", err)
+		}
+
+		delta := int32(1)
+		available := book.AvailableCopies.Int32 + 1
+		if status == "lost" {
+			delta = -1
+			available = book.AvailableCopies.Int32
+		}
+
+		err = s.queries.UpdateBookCopyTotals(ctx, queries.UpdateBookCopyTotalsParams{
+			ID:              tx.BookID,
+			TotalCopies:     pgtype.Int4{Int32: book.TotalCopies.Int32 + delta, Valid: true},
+			AvailableCopies: pgtype.Int4{Int32: available, Valid: true},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to update book copies: %w", err)
+		}
+		return "", nil
+	}
+
+	bookCopy, err := s.queries.GetBookCopyByIDForUpdate(ctx, tx.CopyID.Int32)
+	if err != nil {
+		return "", fmt.Errorf("failed to get book copy: %w", err)
+	}
+
+	err = s.queries.UpdateBookCopyStatus(ctx, queries.UpdateBookCopyStatusParams{
+		ID:     bookCopy.ID,
+		Status: pgtype.Text{String: status, Valid: true},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to update book copy status: %w", err)
+	}
+
+	if err := s.queries.SyncBookCopyCounts(ctx, tx.BookID); err != nil {
+		return "", fmt.Errorf("failed to update book availability: %w", err)
+	}
+
+	return bookCopy.Barcode, nil
+}
+
+// lockedRowTransaction extracts the loan columns from a locked transaction row
+func lockedRowTransaction(row queries.GetTransactionByIDForUpdateRow) queries.Transaction {
+	return queries.Transaction{
+		ID:              row.ID,
+		StudentID:       row.StudentID,
+		BookID:          row.BookID,
+		TransactionType: row.TransactionType,
+		TransactionDate: row.TransactionDate,
+		DueDate:         row.DueDate,
+		ReturnedDate:    row.ReturnedDate,
+		LibrarianID:     row.LibrarianID,
+		FineAmount:      row.FineAmount,
+		FinePaid:        row.FinePaid,
+		Notes:           row.Notes,
+		CreatedAt:       row.CreatedAt,
+		UpdatedAt:       row.UpdatedAt,
+		ReturnCondition: row.ReturnCondition,
+		ConditionNotes:  row.ConditionNotes,
+		CopyID:          row.CopyID,
+		LossStatus:      row.LossStatus,
+		LossReportedAt:  row.LossReportedAt,
+		RecalledAt:      row.RecalledAt,
+		RecalledBy:      row.RecalledBy,
+		OriginalDueDate: row.OriginalDueDate,
+	}
+}
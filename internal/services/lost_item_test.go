@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+func createTestLoanRow(copyID int32) queries.GetTransactionByIDForUpdateRow {
+	row := queries.GetTransactionByIDForUpdateRow{
+		ID:              1,
+		StudentID:       1,
+		BookID:          1,
+		TransactionType: "borrow",
+		DueDate:         pgtype.Timestamp{Time: time.Now().AddDate(0, 0, 3), Valid: true},
+	}
+	if copyID > 0 {
+		row.CopyID = pgtype.Int4{Int32: copyID, Valid: true}
+	}
+	return row
+}
+
+func TestTransactionService_MarkLost(t *testing.T) {
+	ctx := context.Background()
+	actor := AuditActor{UserID: 2, UserType: "librarian"}
+
+	t.Run("ChargesReplacementAndRemovesCopy", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries).WithReplacementCharges(decimal.NewFromInt(40), decimal.NewFromInt(5))
+
+		closed := createTestTransaction()
+		closed.ReturnedDate = pgtype.Timestamp{Time: time.Now(), Valid: true}
+		closed.LossStatus = pgtype.Text{String: "lost", Valid: true}
+
+		bookCopy := createTestBookCopy()
+		bookCopy.Status = pgtype.Text{String: "borrowed", Valid: true}
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(createTestLoanRow(bookCopy.ID), nil)
+		mockQueries.On("GetCurrentLoanTransactionID", ctx, int32(1)).Return(int32(1), nil)
+		mockQueries.On("CloseTransactionAsMissing", ctx, queries.CloseTransactionAsMissingParams{
+			ID:             1,
+			LossStatus:     pgtype.Text{String: "lost", Valid: true},
+			ConditionNotes: pgtype.Text{String: "Left on a bus", Valid: true},
+		}).Return(closed, nil)
+		mockQueries.On("CloseRenewedLoanTransactions", ctx, int32(1)).Return(nil)
+		mockQueries.On("CreateFine", ctx, mock.MatchedBy(func(arg queries.CreateFineParams) bool {
+			return arg.FineType == "lost" && numericToDecimal(arg.Amount).Equal(decimal.NewFromInt(45)) &&
+				arg.CreatedBy.Int32 == 2
+		})).Return(queries.Fine{ID: 5, StudentID: 1, FineType: "lost", Amount: decimalToNumeric(decimal.NewFromInt(45))}, nil)
+		mockQueries.On("GetBookCopyByIDForUpdate", ctx, bookCopy.ID).Return(bookCopy, nil)
+		mockQueries.On("UpdateBookCopyStatus", ctx, queries.UpdateBookCopyStatusParams{
+			ID:     bookCopy.ID,
+			Status: pgtype.Text{String: "lost", Valid: true},
+		}).Return(nil)
+		mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+		mockQueries.On("CreateAuditLog", ctx, mock.AnythingOfType("queries.CreateAuditLogParams")).Return(nil)
+
+		result, err := service.MarkLost(ctx, 1, "Left on a bus", actor)
+
+		require.NoError(t, err)
+		assert.Equal(t, "lost", result.LossStatus)
+		assert.Equal(t, "BK001-001", result.Barcode)
+		require.Len(t, result.Charges, 1)
+		assert.True(t, result.Charges[0].Amount.Equal(decimal.NewFromInt(45)))
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("LegacyLoanReducesTotalCopies", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		closed := createTestTransaction()
+		closed.LossStatus = pgtype.Text{String: "claimed_returned", Valid: true}
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(createTestLoanRow(0), nil)
+		mockQueries.On("GetCurrentLoanTransactionID", ctx, int32(1)).Return(int32(1), nil)
+		mockQueries.On("CloseTransactionAsMissing", ctx, mock.AnythingOfType("queries.CloseTransactionAsMissingParams")).Return(closed, nil)
+		mockQueries.On("CloseRenewedLoanTransactions", ctx, mock.AnythingOfType("int32")).Return(nil)
+		mockQueries.On("CreateFine", ctx, mock.AnythingOfType("queries.CreateFineParams")).Return(queries.Fine{ID: 5}, nil)
+		mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(createTestBook(), nil)
+		mockQueries.On("UpdateBookCopyTotals", ctx, queries.UpdateBookCopyTotalsParams{
+			ID:              1,
+			TotalCopies:     pgtype.Int4{Int32: 4, Valid: true},
+			AvailableCopies: pgtype.Int4{Int32: 3, Valid: true},
+		}).Return(nil)
+		mockQueries.On("CreateAuditLog", ctx, mock.AnythingOfType("queries.CreateAuditLogParams")).Return(nil)
+
+		result, err := service.MarkClaimedReturned(ctx, 1, "", actor)
+
+		require.NoError(t, err)
+		assert.Equal(t, "claimed_returned", result.LossStatus)
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("AlreadyReturned", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		row := createTestLoanRow(10)
+		row.ReturnedDate = pgtype.Timestamp{Time: time.Now(), Valid: true}
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(row, nil)
+
+		_, err := service.MarkLost(ctx, 1, "", actor)
+
+		require.Error(t, err)
+		mockQueries.AssertNotCalled(t, "CloseTransactionAsMissing", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTransactionService_MarkFound(t *testing.T) {
+	ctx := context.Background()
+	actor := AuditActor{UserID: 2, UserType: "librarian"}
+
+	lostRow := createTestLoanRow(10)
+	lostRow.ReturnedDate = pgtype.Timestamp{Time: time.Now(), Valid: true}
+	lostRow.LossStatus = pgtype.Text{String: "lost", Valid: true}
+
+	paidFine := queries.Fine{
+		ID:            5,
+		StudentID:     1,
+		TransactionID: pgtype.Int4{Int32: 1, Valid: true},
+		FineType:      "lost",
+		Amount:        decimalToNumeric(decimal.NewFromInt(60)),
+		AmountPaid:    decimalToNumeric(decimal.NewFromInt(20)),
+		AmountWaived:  decimalToNumeric(decimal.Zero),
+	}
+
+	t.Run("RefundMethodRequiredWhenPaid", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(lostRow, nil)
+		mockQueries.On("ListFinesByTransaction", ctx, pgtype.Int4{Int32: 1, Valid: true}).Return([]queries.Fine{paidFine}, nil)
+		mockQueries.On("GetFineByIDForUpdate", ctx, int32(5)).Return(paidFine, nil)
+
+		_, err := service.MarkFound(ctx, 1, models.MarkLoanFoundRequest{}, actor)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refund_method is required")
+	})
+
+	t.Run("RefundsAndReversesChargeAndRestoresCopy", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		refunded := paidFine
+		refunded.AmountPaid = decimalToNumeric(decimal.Zero)
+		reversed := refunded
+		reversed.Amount = decimalToNumeric(decimal.Zero)
+		reversed.Status = "paid"
+
+		found := createTestTransaction()
+		found.LossStatus = pgtype.Text{String: "found", Valid: true}
+
+		bookCopy := createTestBookCopy()
+		bookCopy.Status = pgtype.Text{String: "lost", Valid: true}
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(lostRow, nil)
+		mockQueries.On("ListFinesByTransaction", ctx, pgtype.Int4{Int32: 1, Valid: true}).Return([]queries.Fine{paidFine}, nil).Once()
+		mockQueries.On("GetFineByIDForUpdate", ctx, int32(5)).Return(paidFine, nil).Once()
+		mockQueries.On("GetFineByIDForUpdate", ctx, int32(5)).Return(refunded, nil).Once()
+		mockQueries.On("NextFineReceiptNumber", ctx).Return(int64(9), nil)
+		mockQueries.On("CreateFineLedgerEntry", ctx, mock.MatchedBy(func(arg queries.CreateFineLedgerEntryParams) bool {
+			return arg.EntryType == "refund" && numericToDecimal(arg.Amount).Equal(decimal.NewFromInt(20)) &&
+				arg.PaymentMethod.String == "mpesa"
+		})).Return(queries.FineLedgerEntry{ID: 1, FineID: 5, EntryType: "refund"}, nil)
+		mockQueries.On("CreateFineLedgerEntry", ctx, mock.MatchedBy(func(arg queries.CreateFineLedgerEntryParams) bool {
+			return arg.EntryType == "adjustment" && numericToDecimal(arg.Amount).Equal(decimal.NewFromInt(-60))
+		})).Return(queries.FineLedgerEntry{ID: 2, FineID: 5, EntryType: "adjustment"}, nil)
+		mockQueries.On("UpdateFineTotals", ctx, mock.MatchedBy(func(arg queries.UpdateFineTotalsParams) bool {
+			return numericToDecimal(arg.Amount).Equal(decimal.NewFromInt(60))
+		})).Return(refunded, nil)
+		mockQueries.On("UpdateFineTotals", ctx, mock.MatchedBy(func(arg queries.UpdateFineTotalsParams) bool {
+			return numericToDecimal(arg.Amount).IsZero()
+		})).Return(reversed, nil)
+		mockQueries.On("ListFinesByTransaction", ctx, pgtype.Int4{Int32: 1, Valid: true}).Return([]queries.Fine{reversed}, nil)
+		mockQueries.On("SetTransactionFinePaid", ctx, mock.AnythingOfType("queries.SetTransactionFinePaidParams")).Return(nil)
+		mockQueries.On("CreateAuditLog", ctx, mock.AnythingOfType("queries.CreateAuditLogParams")).Return(nil)
+		mockQueries.On("SetTransactionLossStatus", ctx, queries.SetTransactionLossStatusParams{
+			ID:         1,
+			LossStatus: pgtype.Text{String: "found", Valid: true},
+		}).Return(found, nil)
+		mockQueries.On("GetBookCopyByIDForUpdate", ctx, int32(10)).Return(bookCopy, nil)
+		mockQueries.On("UpdateBookCopyStatus", ctx, queries.UpdateBookCopyStatusParams{
+			ID:     10,
+			Status: pgtype.Text{String: "available", Valid: true},
+		}).Return(nil)
+		mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+		mockQueries.On("GetNextReservationForBook", ctx, int32(1)).Return(queries.GetNextReservationForBookRow{}, pgx.ErrNoRows)
+
+		method := "mpesa"
+		result, err := service.MarkFound(ctx, 1, models.MarkLoanFoundRequest{RefundMethod: &method}, actor)
+
+		require.NoError(t, err)
+		assert.Equal(t, "found", result.LossStatus)
+		require.Len(t, result.Charges, 1)
+		assert.True(t, result.Charges[0].Amount.IsZero())
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("HoldsCopyForWaitingReservation", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		realtime := &MockRealtimePublisher{}
+		notifier := &MockStudentNotifier{}
+		service := NewTransactionService(mockQueries).WithRealtime(realtime).WithNotifier(notifier)
+
+		now := time.Now()
+		found := createTestTransaction()
+		found.LossStatus = pgtype.Text{String: "found", Valid: true}
+
+		bookCopy := createTestBookCopy()
+		bookCopy.Status = pgtype.Text{String: "lost", Valid: true}
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(lostRow, nil)
+		mockQueries.On("ListFinesByTransaction", ctx, pgtype.Int4{Int32: 1, Valid: true}).Return([]queries.Fine{}, nil)
+		mockQueries.On("SetTransactionLossStatus", ctx, mock.AnythingOfType("queries.SetTransactionLossStatusParams")).Return(found, nil)
+		mockQueries.On("GetBookCopyByIDForUpdate", ctx, int32(10)).Return(bookCopy, nil)
+		expectCopyStatus(mockQueries, ctx, 10, "available")
+		mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+		mockQueries.On("GetNextReservationForBook", ctx, int32(1)).Return(queries.GetNextReservationForBookRow{
+			ID: 40, StudentID: 2, BookID: 1, FirstName: "Jane", LastName: "Roe", Locale: "en",
+		}, nil)
+		mockQueries.On("MarkReservationReady", ctx, mock.MatchedBy(func(arg queries.MarkReservationReadyParams) bool {
+			return arg.ID == 40 && arg.CopyID.Int32 == 10
+		})).Return(createTestHold(now), nil)
+		expectCopyStatus(mockQueries, ctx, 10, "on_hold")
+		mockQueries.On("GetBookByID", ctx, int32(1)).Return(createTestBook(), nil)
+		mockQueries.On("CreateAuditLog", ctx, mock.AnythingOfType("queries.CreateAuditLogParams")).Return(nil)
+		notifier.On("CreateNotification", ctx, mock.MatchedBy(func(req *models.NotificationRequest) bool {
+			return req.RecipientID == 2 && req.Type == models.NotificationTypeReservationReady
+		})).Return(&models.NotificationResponse{ID: 77}, nil)
+		realtime.On("ReservationChanged", ctx, createTestHold(now)).Return()
+		realtime.On("CountersChanged", ctx).Return()
+
+		result, err := service.MarkFound(ctx, 1, models.MarkLoanFoundRequest{}, actor)
+
+		require.NoError(t, err)
+		assert.Equal(t, "found", result.LossStatus)
+		mockQueries.AssertExpectations(t)
+		realtime.AssertExpectations(t)
+		notifier.AssertExpectations(t)
+	})
+
+	t.Run("LoanNotMissing", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(createTestLoanRow(10), nil)
+
+		_, err := service.MarkFound(ctx, 1, models.MarkLoanFoundRequest{}, actor)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not marked lost")
+	})
+}
+
+func TestTransactionService_ReturnBookWithCondition_DamageCharge(t *testing.T) {
+	mockQueries := &MockTransactionQueries{}
+	service := NewTransactionService(mockQueries).WithDamageCharges(decimal.NewFromInt(5), 2)
+
+	ctx := context.Background()
+
+	returned := createTestTransaction()
+	returned.ReturnedDate = pgtype.Timestamp{Time: time.Now(), Valid: true}
+	returned.CopyID = pgtype.Int4{Int32: 10, Valid: true}
+
+	bookCopy := createTestBookCopy()
+	bookCopy.Status = pgtype.Text{String: "borrowed", Valid: true}
+
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(createTestLoanRow(10), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, int32(1)).Return(int32(1), nil)
+	mockQueries.On("ReturnBook", ctx, mock.AnythingOfType("queries.ReturnBookParams")).Return(returned, nil)
+	mockQueries.On("CloseRenewedLoanTransactions", ctx, mock.AnythingOfType("int32")).Return(nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(createTestBook(), nil)
+	mockQueries.On("GetBookCopyByIDForUpdate", ctx, int32(10)).Return(bookCopy, nil)
+	// good -> poor is two steps
+	mockQueries.On("CreateFine", ctx, mock.MatchedBy(func(arg queries.CreateFineParams) bool {
+		return arg.FineType == "damage" && numericToDecimal(arg.Amount).Equal(decimal.NewFromInt(10))
+	})).Return(queries.Fine{ID: 3, FineType: "damage", Amount: decimalToNumeric(decimal.NewFromInt(10))}, nil)
+	mockQueries.On("CreateAuditLog", ctx, mock.MatchedBy(func(arg queries.CreateAuditLogParams) bool {
+		return arg.TableName == "fines" && arg.Action == "CREATE" && arg.UserID.Int32 == testLibrarian.UserID
+	})).Return(nil)
+	mockQueries.On("UpdateBookCopyStatus", ctx, mock.AnythingOfType("queries.UpdateBookCopyStatusParams")).Return(nil)
+	mockQueries.On("UpdateBookCopyCondition", ctx, queries.UpdateBookCopyConditionParams{
+		ID:        10,
+		Condition: pgtype.Text{String: "poor", Valid: true},
+	}).Return(nil)
+	mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+
+	result, err := service.ReturnBookWithCondition(ctx, 1, "poor", "Water damage", testLibrarian)
+
+	require.NoError(t, err)
+	require.Len(t, result.Charges, 1)
+	assert.Equal(t, models.FineTypeDamage, result.Charges[0].FineType)
+	mockQueries.AssertExpectations(t)
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+const (
+	darajaTimestampLayout = "20060102150405"
+	darajaTransactionType = "CustomerPayBillOnline"
+	// Daraja limits the account reference to 12 characters and the description to 13
+	darajaMaxAccountReference = 12
+	darajaMaxDescription      = 13
+)
+
+// Daraja timestamps are in East Africa Time
+var darajaLocation = time.FixedZone("EAT", 3*60*60)
+
+var mpesaPhonePattern = regexp.MustCompile(`^254[17]\d{8}$`)
+
+// DarajaProvider implements PaymentProvider with M-Pesa STK push through Safaricom's Daraja API
+type DarajaProvider struct {
+	config *models.MpesaConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewDarajaProvider creates a Daraja payment provider
+func NewDarajaProvider(config *models.MpesaConfig) *DarajaProvider {
+	timeout := time.Duration(config.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &DarajaProvider{
+		config: config,
+		client: &http.Client{Timeout: timeout},
+		now:    time.Now,
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to call Daraja
+func (p *DarajaProvider) WithHTTPClient(client *http.Client) *DarajaProvider {
+	p.client = client
+	return p
+}
+
+// Name identifies the provider
+func (p *DarajaProvider) Name() string {
+	return "mpesa"
+}
+
+type darajaTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   string `json:"expires_in"`
+}
+
+type darajaSTKPushRequest struct {
+	BusinessShortCode string `json:"BusinessShortCode"`
+	Password          string `json:"Password"`
+	Timestamp         string `json:"Timestamp"`
+	TransactionType   string `json:"TransactionType"`
+	Amount            int64  `json:"Amount"`
+	PartyA            string `json:"PartyA"`
+	PartyB            string `json:"PartyB"`
+	PhoneNumber       string `json:"PhoneNumber"`
+	CallBackURL       string `json:"CallBackURL"`
+	AccountReference  string `json:"AccountReference"`
+	TransactionDesc   string `json:"TransactionDesc"`
+}
+
+type darajaSTKPushResponse struct {
+	MerchantRequestID   string `json:"MerchantRequestID"`
+	CheckoutRequestID   string `json:"CheckoutRequestID"`
+	ResponseCode        string `json:"ResponseCode"`
+	ResponseDescription string `json:"ResponseDescription"`
+	CustomerMessage     string `json:"CustomerMessage"`
+	ErrorCode           string `json:"errorCode"`
+	ErrorMessage        string `json:"errorMessage"`
+}
+
+type darajaCallback struct {
+	Body struct {
+		STKCallback struct {
+			MerchantRequestID string `json:"MerchantRequestID"`
+			CheckoutRequestID string `json:"CheckoutRequestID"`
+			ResultCode        int    `json:"ResultCode"`
+			ResultDesc        string `json:"ResultDesc"`
+			CallbackMetadata  struct {
+				Item []struct {
+					Name  string      `json:"Name"`
+					Value interface{} `json:"Value"`
+				} `json:"Item"`
+			} `json:"CallbackMetadata"`
+		} `json:"stkCallback"`
+	} `json:"Body"`
+}
+
+// InitiatePayment sends an STK push prompting the phone to pay. M-Pesa only
+// accepts whole shillings, so the amount is rounded up.
+func (p *DarajaProvider) InitiatePayment(ctx context.Context, req PaymentRequest) (*PaymentInitiation, error) {
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := p.now().In(darajaLocation).Format(darajaTimestampLayout)
+	password := base64.StdEncoding.EncodeToString([]byte(p.config.ShortCode + p.config.PassKey + timestamp))
+
+	payload, err := json.Marshal(darajaSTKPushRequest{
+		BusinessShortCode: p.config.ShortCode,
+		Password:          password,
+		Timestamp:         timestamp,
+		TransactionType:   darajaTransactionType,
+		Amount:            req.Amount.Ceil().IntPart(),
+		PartyA:            req.Phone,
+		PartyB:            p.config.ShortCode,
+		PhoneNumber:       req.Phone,
+		CallBackURL:       p.config.CallbackURL,
+		AccountReference:  truncate(req.AccountReference, darajaMaxAccountReference),
+		TransactionDesc:   truncate(req.Description, darajaMaxDescription),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode STK push request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url("/mpesa/stkpush/v1/processrequest"), bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create STK push request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send STK push: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result darajaSTKPushResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode STK push response (status %d): %w", resp.StatusCode, err)
+	}
+
+	if resp.StatusCode != http.StatusOK || result.ResponseCode != "0" {
+		message := result.ErrorMessage
+		if message == "" {
+			message = result.ResponseDescription
+		}
+		return nil, fmt.Errorf("STK push rejected (status %d): %s", resp.StatusCode, message)
+	}
+
+	return &PaymentInitiation{
+		MerchantRequestID: result.MerchantRequestID,
+		CheckoutRequestID: result.CheckoutRequestID,
+		CustomerMessage:   result.CustomerMessage,
+	}, nil
+}
+
+// ParseCallback decodes an STK push result. The callback URL must carry the
+// configured callback token, since Daraja does not sign its callbacks.
+func (p *DarajaProvider) ParseCallback(token string, body []byte) (*PaymentResult, error) {
+	if p.config.CallbackToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(p.config.CallbackToken)) != 1 {
+		return nil, ErrInvalidCallbackToken
+	}
+
+	var callback darajaCallback
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&callback); err != nil {
+		return nil, fmt.Errorf("validation error: invalid callback body: %w", err)
+	}
+
+	stk := callback.Body.STKCallback
+	if stk.CheckoutRequestID == "" {
+		return nil, fmt.Errorf("validation error: callback is missing CheckoutRequestID")
+	}
+
+	result := &PaymentResult{
+		MerchantRequestID: stk.MerchantRequestID,
+		CheckoutRequestID: stk.CheckoutRequestID,
+		ResultCode:        stk.ResultCode,
+		ResultDesc:        stk.ResultDesc,
+	}
+
+	// Metadata values are a mix of JSON numbers and strings
+	for _, item := range stk.CallbackMetadata.Item {
+		value := fmt.Sprint(item.Value)
+		switch item.Name {
+		case "Amount":
+			amount, err := decimal.NewFromString(value)
+			if err != nil {
+				return nil, fmt.Errorf("validation error: invalid callback amount: %w", err)
+			}
+			result.Amount = amount
+		case "MpesaReceiptNumber":
+			result.Receipt = value
+		case "PhoneNumber":
+			result.Phone = value
+		}
+	}
+
+	if result.Succeeded() && (result.Receipt == "" || !result.Amount.IsPositive()) {
+		return nil, fmt.Errorf("validation error: successful callback is missing the amount or receipt number")
+	}
+
+	return result, nil
+}
+
+// accessToken returns a cached OAuth token, fetching a new one when it is about to expire
+func (p *DarajaProvider) accessToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && p.now().Before(p.tokenExpiry) {
+		return p.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url("/oauth/v1/generate?grant_type=client_credentials"), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.SetBasicAuth(p.config.ConsumerKey, p.config.ConsumerSecret)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get M-Pesa access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("failed to get M-Pesa access token (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var token darajaTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode M-Pesa access token: %w", err)
+	}
+
+	expiresIn, err := strconv.Atoi(token.ExpiresIn)
+	if err != nil || expiresIn <= 0 {
+		expiresIn = 3599
+	}
+
+	// Refresh a minute early so a token never expires mid-request
+	p.token = token.AccessToken
+	p.tokenExpiry = p.now().Add(time.Duration(expiresIn)*time.Second - time.Minute)
+
+	return p.token, nil
+}
+
+func (p *DarajaProvider) url(path string) string {
+	return strings.TrimRight(p.config.BaseURL, "/") + path
+}
+
+// normalizeMpesaPhone converts a Kenyan mobile number such as 0712345678 or
+// +254712345678 to the 254XXXXXXXXX form M-Pesa expects
+func normalizeMpesaPhone(phone string) (string, error) {
+	cleaned := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(strings.TrimSpace(phone))
+	cleaned = strings.TrimPrefix(cleaned, "+")
+
+	switch {
+	case strings.HasPrefix(cleaned, "0") && len(cleaned) == 10:
+		cleaned = "254" + cleaned[1:]
+	case len(cleaned) == 9:
+		cleaned = "254" + cleaned
+	}
+
+	if !mpesaPhonePattern.MatchString(cleaned) {
+		return "", fmt.Errorf("%q is not a valid M-Pesa phone number", phone)
+	}
+	return cleaned, nil
+}
+
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	return value[:max]
+}
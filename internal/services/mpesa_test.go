@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/mpesamock"
+)
+
+// newTestDaraja starts a fake Daraja server and returns a provider pointed at it,
+// along with a counter of OAuth token requests
+func newTestDaraja(t *testing.T) (*DarajaProvider, *mpesamock.Server, *int32) {
+	fake := mpesamock.New("key", "secret", "passkey")
+	var tokenRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/v1/generate" {
+			atomic.AddInt32(&tokenRequests, 1)
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewDarajaProvider(&models.MpesaConfig{
+		BaseURL:        server.URL,
+		ConsumerKey:    "key",
+		ConsumerSecret: "secret",
+		ShortCode:      "174379",
+		PassKey:        "passkey",
+		CallbackURL:    "https://library.example.com/api/v1/payments/mpesa/callback?token=s3cret",
+		CallbackToken:  "s3cret",
+	})
+	return provider, fake, &tokenRequests
+}
+
+func TestNormalizeMpesaPhone(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"0712345678", "254712345678"},
+		{"+254 712 345 678", "254712345678"},
+		{"254112345678", "254112345678"},
+		{"712345678", "254712345678"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			phone, err := normalizeMpesaPhone(tc.input)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, phone)
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := normalizeMpesaPhone("0201234567")
+		require.Error(t, err)
+	})
+}
+
+func TestDarajaProvider_InitiatePayment(t *testing.T) {
+	ctx := context.Background()
+	provider, fake, tokenRequests := newTestDaraja(t)
+
+	initiation, err := provider.InitiatePayment(ctx, PaymentRequest{
+		Phone:            "254712345678",
+		Amount:           decimal.RequireFromString("12.50"),
+		AccountReference: "STU2024001XYZ",
+		Description:      "Library fines",
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, initiation.CheckoutRequestID)
+
+	pushes := fake.Pushes()
+	require.Len(t, pushes, 1)
+	assert.Equal(t, "13", pushes[0].Amount.String())
+	assert.Equal(t, "254712345678", pushes[0].PhoneNumber)
+	assert.Equal(t, "174379", pushes[0].PartyB)
+	assert.Equal(t, "STU2024001XY", pushes[0].AccountReference)
+
+	// The access token is reused for the next push
+	_, err = provider.InitiatePayment(ctx, PaymentRequest{Phone: "254712345678", Amount: decimal.NewFromInt(5)})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(tokenRequests))
+}
+
+func TestDarajaProvider_InitiatePayment_BadCredentials(t *testing.T) {
+	provider, _, _ := newTestDaraja(t)
+	provider.config.ConsumerSecret = "wrong"
+
+	_, err := provider.InitiatePayment(context.Background(), PaymentRequest{Phone: "254712345678", Amount: decimal.NewFromInt(5)})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access token")
+}
+
+func TestDarajaProvider_ParseCallback(t *testing.T) {
+	provider, fake, _ := newTestDaraja(t)
+	initiation, err := provider.InitiatePayment(context.Background(), PaymentRequest{Phone: "254712345678", Amount: decimal.NewFromInt(40)})
+	require.NoError(t, err)
+
+	t.Run("Paid", func(t *testing.T) {
+		body, err := fake.CallbackBody(initiation.CheckoutRequestID, mpesamock.ResultSuccess)
+		require.NoError(t, err)
+
+		result, err := provider.ParseCallback("s3cret", body)
+
+		require.NoError(t, err)
+		assert.True(t, result.Succeeded())
+		assert.Equal(t, initiation.CheckoutRequestID, result.CheckoutRequestID)
+		assert.True(t, decimal.NewFromInt(40).Equal(result.Amount))
+		assert.Equal(t, "MCK0000001", result.Receipt)
+		assert.Equal(t, "254712345678", result.Phone)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		body, err := fake.CallbackBody(initiation.CheckoutRequestID, mpesamock.ResultCancelledByUser)
+		require.NoError(t, err)
+
+		result, err := provider.ParseCallback("s3cret", body)
+
+		require.NoError(t, err)
+		assert.False(t, result.Succeeded())
+		assert.Equal(t, mpesamock.ResultCancelledByUser, result.ResultCode)
+	})
+
+	t.Run("WrongToken", func(t *testing.T) {
+		body, err := fake.CallbackBody(initiation.CheckoutRequestID, mpesamock.ResultSuccess)
+		require.NoError(t, err)
+
+		_, err = provider.ParseCallback("guess", body)
+
+		assert.ErrorIs(t, err, ErrInvalidCallbackToken)
+	})
+
+	t.Run("NoTokenConfigured", func(t *testing.T) {
+		body, err := fake.CallbackBody(initiation.CheckoutRequestID, mpesamock.ResultSuccess)
+		require.NoError(t, err)
+
+		unguarded := NewDarajaProvider(&models.MpesaConfig{BaseURL: provider.config.BaseURL})
+		_, err = unguarded.ParseCallback("", body)
+
+		assert.ErrorIs(t, err, ErrInvalidCallbackToken)
+		assert.False(t, unguarded.config.Enabled())
+	})
+}
+
+func TestFineService_MobilePayment(t *testing.T) {
+	ctx := context.Background()
+	actor := AuditActor{UserID: 2, UserType: "librarian"}
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		service := NewFineService(new(MockFineQuerier))
+
+		_, err := service.InitiateMobilePayment(ctx, 3, models.InitiateMobilePaymentRequest{}, actor)
+
+		assert.ErrorIs(t, err, ErrPaymentsNotConfigured)
+	})
+
+	t.Run("NoOutstandingFines", func(t *testing.T) {
+		provider, fake, _ := newTestDaraja(t)
+		mockQuerier := new(MockFineQuerier)
+		service := NewFineService(mockQuerier).WithPaymentProvider(provider)
+
+		mockQuerier.On("GetStudentByID", ctx, int32(3)).Return(queries.Student{ID: 3, StudentID: "STU2024003", Phone: pgtype.Text{String: "0712345678", Valid: true}}, nil)
+		mockQuerier.On("GetStudentFineBalance", ctx, int32(3)).Return(queries.GetStudentFineBalanceRow{Balance: testMoney("0")}, nil)
+
+		_, err := service.InitiateMobilePayment(ctx, 3, models.InitiateMobilePaymentRequest{}, actor)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no outstanding fines")
+		assert.Empty(t, fake.Pushes())
+	})
+
+	t.Run("PushThenCallbackSettlesOldestFinesFirst", func(t *testing.T) {
+		provider, fake, _ := newTestDaraja(t)
+		mockQuerier := new(MockFineQuerier)
+		service := NewFineService(mockQuerier).WithPaymentProvider(provider)
+
+		mockQuerier.On("GetStudentByID", ctx, int32(3)).Return(queries.Student{ID: 3, StudentID: "STU2024003", Phone: pgtype.Text{String: "0712345678", Valid: true}}, nil)
+		mockQuerier.On("GetStudentFineBalance", ctx, int32(3)).Return(queries.GetStudentFineBalanceRow{Balance: testMoney("119.50"), OutstandingFines: 2}, nil)
+
+		pending := queries.FinePaymentRequest{ID: 5, StudentID: 3, Provider: "mpesa", Phone: "254712345678", Amount: testMoney("120.00"), Status: "pending"}
+		mockQuerier.On("CreateFinePaymentRequest", ctx, mock.MatchedBy(func(arg queries.CreateFinePaymentRequestParams) bool {
+			return arg.Phone == "254712345678" && numericToDecimal(arg.Amount).Equal(decimal.NewFromInt(120)) && arg.RequestedBy.Int32 == 2
+		})).Return(pending, nil)
+		checkoutID := pgtype.Text{String: "ws_CO_mock_000001", Valid: true}
+		pendingWithCheckout := pending
+		pendingWithCheckout.CheckoutRequestID = checkoutID
+		mockQuerier.On("SetFinePaymentRequestCheckout", ctx, queries.SetFinePaymentRequestCheckoutParams{
+			ID:                5,
+			MerchantRequestID: pgtype.Text{String: "mock-1", Valid: true},
+			CheckoutRequestID: checkoutID,
+		}).Return(pendingWithCheckout, nil)
+
+		request, err := service.InitiateMobilePayment(ctx, 3, models.InitiateMobilePaymentRequest{}, actor)
+		require.NoError(t, err)
+		require.NotNil(t, request.CheckoutRequestID)
+		assert.Equal(t, models.PaymentRequestPending, request.Status)
+
+		pushes := fake.Pushes()
+		require.Len(t, pushes, 1)
+		assert.Equal(t, "120", pushes[0].Amount.String())
+
+		// The student pays; two fines are outstanding, 100.00 then 19.50
+		older := createTestFine("0", "0")
+		newer := createTestFine("0", "0")
+		newer.ID = 2
+		newer.TransactionID = pgtype.Int4{}
+		newer.Amount = testMoney("19.50")
+
+		mockQuerier.On("GetFinePaymentRequestByCheckoutIDForUpdate", ctx, pgtype.Text{String: *request.CheckoutRequestID, Valid: true}).Return(pending, nil).Once()
+		mockQuerier.On("ListOutstandingFinesByStudent", ctx, int32(3)).Return([]queries.Fine{older, newer}, nil)
+		mockQuerier.On("GetFineByIDForUpdate", ctx, int32(1)).Return(older, nil)
+		mockQuerier.On("GetFineByIDForUpdate", ctx, int32(2)).Return(newer, nil)
+		mockQuerier.On("NextFineReceiptNumber", ctx).Return(int64(7), nil)
+		mockQuerier.On("CreateFineLedgerEntry", ctx, mock.MatchedBy(func(arg queries.CreateFineLedgerEntryParams) bool {
+			return arg.EntryType == "payment" && arg.PaymentMethod.String == "mpesa" &&
+				arg.Reference.String == "MCK0000001" && !arg.RecordedBy.Valid
+		})).Return(queries.FineLedgerEntry{ID: 20, EntryType: "payment"}, nil)
+		expectFineUpdate(mockQuerier, ctx, older, "100.00", "100.00", "0", models.FineStatusPaid)
+		mockQuerier.On("UpdateFineTotals", ctx, mock.MatchedBy(func(arg queries.UpdateFineTotalsParams) bool {
+			return arg.ID == 2 && numericToDecimal(arg.AmountPaid).Equal(decimal.RequireFromString("19.50"))
+		})).Return(newer, nil)
+		mockQuerier.On("CompleteFinePaymentRequest", ctx, mock.MatchedBy(func(arg queries.CompleteFinePaymentRequestParams) bool {
+			return arg.ID == 5 && arg.Status == "completed" && arg.ProviderReceipt.String == "MCK0000001" &&
+				numericToDecimal(arg.AmountApplied).Equal(decimal.RequireFromString("119.50"))
+		})).Return(queries.FinePaymentRequest{ID: 5, Status: "completed", AmountApplied: testMoney("119.50")}, nil)
+
+		body, err := fake.CallbackBody(*request.CheckoutRequestID, mpesamock.ResultSuccess)
+		require.NoError(t, err)
+
+		completed, err := service.HandlePaymentCallback(ctx, "s3cret", body)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.PaymentRequestCompleted, completed.Status)
+		mockQuerier.AssertNumberOfCalls(t, "CreateFineLedgerEntry", 2)
+		mockQuerier.AssertExpectations(t)
+
+		// A retried callback finds the request already completed and changes nothing
+		mockQuerier.On("GetFinePaymentRequestByCheckoutIDForUpdate", ctx, mock.Anything).Return(queries.FinePaymentRequest{ID: 5, Status: "completed"}, nil)
+
+		_, err = service.HandlePaymentCallback(ctx, "s3cret", body)
+
+		require.NoError(t, err)
+		mockQuerier.AssertNumberOfCalls(t, "CreateFineLedgerEntry", 2)
+		mockQuerier.AssertNumberOfCalls(t, "CompleteFinePaymentRequest", 1)
+	})
+
+	t.Run("CallbackForMoreThanRequestedSettlesOnlyTheRequest", func(t *testing.T) {
+		provider, fake, _ := newTestDaraja(t)
+		mockQuerier := new(MockFineQuerier)
+		service := NewFineService(mockQuerier).WithPaymentProvider(provider)
+
+		// The callback reports 500 against a request for 40
+		initiation, err := provider.InitiatePayment(ctx, PaymentRequest{Phone: "254712345678", Amount: decimal.NewFromInt(500)})
+		require.NoError(t, err)
+
+		fine := createTestFine("0", "0")
+		mockQuerier.On("GetFinePaymentRequestByCheckoutIDForUpdate", ctx, mock.Anything).Return(queries.FinePaymentRequest{ID: 7, StudentID: 3, Amount: testMoney("40.00"), Status: "pending"}, nil)
+		mockQuerier.On("ListOutstandingFinesByStudent", ctx, int32(3)).Return([]queries.Fine{fine}, nil)
+		mockQuerier.On("GetFineByIDForUpdate", ctx, int32(1)).Return(fine, nil)
+		mockQuerier.On("NextFineReceiptNumber", ctx).Return(int64(8), nil)
+		mockQuerier.On("CreateFineLedgerEntry", ctx, mock.MatchedBy(func(arg queries.CreateFineLedgerEntryParams) bool {
+			return arg.EntryType == "payment" && numericToDecimal(arg.Amount).Equal(decimal.NewFromInt(40))
+		})).Return(queries.FineLedgerEntry{ID: 21, EntryType: "payment"}, nil)
+		expectFineUpdate(mockQuerier, ctx, fine, "100.00", "40.00", "0", models.FineStatusPartiallyPaid)
+		mockQuerier.On("CompleteFinePaymentRequest", ctx, mock.MatchedBy(func(arg queries.CompleteFinePaymentRequestParams) bool {
+			return arg.Status == "completed" && numericToDecimal(arg.AmountApplied).Equal(decimal.NewFromInt(40)) &&
+				strings.Contains(arg.ResultDesc.String, "amount mismatch: paid 500.00, requested 40.00")
+		})).Return(queries.FinePaymentRequest{ID: 7, Status: "completed", AmountApplied: testMoney("40.00")}, nil)
+
+		body, err := fake.CallbackBody(initiation.CheckoutRequestID, mpesamock.ResultSuccess)
+		require.NoError(t, err)
+
+		result, err := service.HandlePaymentCallback(ctx, "s3cret", body)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.PaymentRequestCompleted, result.Status)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("CancelledPaymentFailsRequest", func(t *testing.T) {
+		provider, fake, _ := newTestDaraja(t)
+		mockQuerier := new(MockFineQuerier)
+		service := NewFineService(mockQuerier).WithPaymentProvider(provider)
+
+		initiation, err := provider.InitiatePayment(ctx, PaymentRequest{Phone: "254712345678", Amount: decimal.NewFromInt(10)})
+		require.NoError(t, err)
+
+		mockQuerier.On("GetFinePaymentRequestByCheckoutIDForUpdate", ctx, mock.Anything).Return(queries.FinePaymentRequest{ID: 6, Status: "pending"}, nil)
+		mockQuerier.On("CompleteFinePaymentRequest", ctx, mock.MatchedBy(func(arg queries.CompleteFinePaymentRequestParams) bool {
+			return arg.Status == "failed" && arg.ResultCode.Int32 == mpesamock.ResultCancelledByUser
+		})).Return(queries.FinePaymentRequest{ID: 6, Status: "failed"}, nil)
+
+		body, err := fake.CallbackBody(initiation.CheckoutRequestID, mpesamock.ResultCancelledByUser)
+		require.NoError(t, err)
+
+		result, err := service.HandlePaymentCallback(ctx, "s3cret", body)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.PaymentRequestFailed, result.Status)
+		mockQuerier.AssertNotCalled(t, "ListOutstandingFinesByStudent", mock.Anything, mock.Anything)
+	})
+}
@@ -2,11 +2,17 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/ngenohkevin/lms/internal/database/queries"
 	"github.com/ngenohkevin/lms/internal/models"
@@ -18,11 +24,14 @@ type NotificationQuerier interface {
 	GetNotificationByID(ctx context.Context, id int32) (queries.Notification, error)
 	MarkNotificationAsRead(ctx context.Context, id int32) error
 	MarkNotificationAsSent(ctx context.Context, id int32) error
+	ListDeliveredNotificationChannels(ctx context.Context, notificationID int32) ([]string, error)
+	RecordNotificationChannelDelivered(ctx context.Context, arg queries.RecordNotificationChannelDeliveredParams) error
 	ListNotifications(ctx context.Context, arg queries.ListNotificationsParams) ([]queries.Notification, error)
 	ListNotificationsByRecipient(ctx context.Context, arg queries.ListNotificationsByRecipientParams) ([]queries.Notification, error)
 	ListUnreadNotificationsByRecipient(ctx context.Context, arg queries.ListUnreadNotificationsByRecipientParams) ([]queries.Notification, error)
 	ListNotificationsByType(ctx context.Context, arg queries.ListNotificationsByTypeParams) ([]queries.Notification, error)
 	ListUnsentNotifications(ctx context.Context, limit int32) ([]queries.Notification, error)
+	ListPendingDigestNotifications(ctx context.Context, arg queries.ListPendingDigestNotificationsParams) ([]queries.Notification, error)
 	CountUnreadNotificationsByRecipient(ctx context.Context, arg queries.CountUnreadNotificationsByRecipientParams) (int64, error)
 	CountNotificationsByType(ctx context.Context, type_ string) (int64, error)
 	DeleteNotification(ctx context.Context, id int32) error
@@ -35,6 +44,13 @@ type NotificationQuerier interface {
 	ListActiveReservationsForAvailableBook(ctx context.Context, bookID int32) ([]queries.ListActiveReservationsForAvailableBookRow, error)
 }
 
+// StudentNotifier sends the notices other services write to students about their
+// loans, reservations and account, applying the student's delivery preferences;
+// NotificationService implements it. Failures are logged by the notifier.
+type StudentNotifier interface {
+	CreateNotification(ctx context.Context, req *models.NotificationRequest) (*models.NotificationResponse, error)
+}
+
 // NotificationServiceInterface defines the interface for notification service operations
 type NotificationServiceInterface interface {
 	CreateNotification(ctx context.Context, req *models.NotificationRequest) (*models.NotificationResponse, error)
@@ -53,26 +69,133 @@ type NotificationServiceInterface interface {
 	SendOverdueReminders(ctx context.Context) error
 	SendBookAvailableNotifications(ctx context.Context, bookID int32) error
 	SendFineNotices(ctx context.Context) error
+	SendDailyDigests(ctx context.Context) error
+	SendWeeklyDigests(ctx context.Context) error
+}
+
+// defaultEmailMaxAttempts is how many times a queued email is tried before it is
+// left failed
+const defaultEmailMaxAttempts = 3
+
+// EmailQueuer puts a notification's email on the delivery queue
+type EmailQueuer interface {
+	QueueEmail(ctx context.Context, req *models.EmailQueueRequest) (*models.EmailQueueItem, error)
+}
+
+// DeliveryRecorder keeps a record of each attempt to send an email
+type DeliveryRecorder interface {
+	CreateDelivery(ctx context.Context, req *models.EmailDeliveryRequest) (*models.EmailDelivery, error)
+	UpdateDeliveryStatus(ctx context.Context, id int32, status models.EmailDeliveryStatus) (*models.EmailDelivery, error)
+	UpdateDeliveryError(ctx context.Context, id int32, errorMsg string) (*models.EmailDelivery, error)
+	UpdateProviderInfo(ctx context.Context, id int32, messageID string, metadata map[string]interface{}) (*models.EmailDelivery, error)
+}
+
+// EmailSuppressionChecker reports whether email to an address is suppressed
+// after it bounced too often
+type EmailSuppressionChecker interface {
+	IsSuppressed(ctx context.Context, email string) (bool, error)
+}
+
+// UnsubscribeLinker makes the links recipients use to stop email of a
+// notification type
+type UnsubscribeLinker interface {
+	UnsubscribeURL(recipientID int32, recipientType models.RecipientType, notificationType models.NotificationType) string
+}
+
+// SMSSender texts a notification to a phone number
+type SMSSender interface {
+	SendNotificationSMS(ctx context.Context, notificationID int32, phone, message string) error
 }
 
 // NotificationService handles notification-related business logic
 type NotificationService struct {
-	querier      NotificationQuerier
-	emailService EmailServiceInterface
-	queueService QueueServiceInterface
-	logger       *slog.Logger
+	querier          NotificationQuerier
+	emailService     EmailServiceInterface
+	queueService     QueueServiceInterface
+	emailQueue       EmailQueuer
+	deliveries       DeliveryRecorder
+	templates        TemplateResolver
+	sms              SMSSender
+	realtime         RealtimePublisher
+	preferences      PreferenceResolver
+	suppressions     EmailSuppressionChecker
+	unsubscribe      UnsubscribeLinker
+	emailMaxAttempts int
+	logger           *slog.Logger
 }
 
 // NewNotificationService creates a new notification service
 func NewNotificationService(querier NotificationQuerier, emailService EmailServiceInterface, queueService QueueServiceInterface, logger *slog.Logger) *NotificationService {
 	return &NotificationService{
-		querier:      querier,
-		emailService: emailService,
-		queueService: queueService,
-		logger:       logger,
+		querier:          querier,
+		emailService:     emailService,
+		queueService:     queueService,
+		emailMaxAttempts: defaultEmailMaxAttempts,
+		logger:           logger,
 	}
 }
 
+// WithEmailQueue sends new notifications through the email queue instead of the
+// Redis notification queue
+func (s *NotificationService) WithEmailQueue(emailQueue EmailQueuer) *NotificationService {
+	s.emailQueue = emailQueue
+	return s
+}
+
+// WithEmailMaxAttempts sets how many times a queued email is tried
+func (s *NotificationService) WithEmailMaxAttempts(attempts int) *NotificationService {
+	s.emailMaxAttempts = attempts
+	return s
+}
+
+// WithDeliveryRecorder records every attempt DeliverEmail makes
+func (s *NotificationService) WithDeliveryRecorder(deliveries DeliveryRecorder) *NotificationService {
+	s.deliveries = deliveries
+	return s
+}
+
+// WithTemplateStore makes notification emails use the templates published in the
+// template store, falling back to the built-in templates
+func (s *NotificationService) WithTemplateStore(templates TemplateResolver) *NotificationService {
+	s.templates = templates
+	return s
+}
+
+// WithSMS delivers notifications on the SMS channel. Without it notifications
+// only go out by email.
+func (s *NotificationService) WithSMS(sms SMSSender) *NotificationService {
+	s.sms = sms
+	return s
+}
+
+// WithRealtime pushes new notifications and read receipts to the recipient's
+// open sessions
+func (s *NotificationService) WithRealtime(realtime RealtimePublisher) *NotificationService {
+	s.realtime = realtime
+	return s
+}
+
+// WithPreferences applies each recipient's channel, quiet hour and digest
+// preferences to their new notifications
+func (s *NotificationService) WithPreferences(preferences PreferenceResolver) *NotificationService {
+	s.preferences = preferences
+	return s
+}
+
+// WithSuppressions stops email to addresses suppressed after repeated hard
+// bounces. Notifications for them still go out on the other channels.
+func (s *NotificationService) WithSuppressions(suppressions EmailSuppressionChecker) *NotificationService {
+	s.suppressions = suppressions
+	return s
+}
+
+// WithUnsubscribeLinks adds a List-Unsubscribe link to the email of notices
+// that can be turned off
+func (s *NotificationService) WithUnsubscribeLinks(unsubscribe UnsubscribeLinker) *NotificationService {
+	s.unsubscribe = unsubscribe
+	return s
+}
+
 // CreateNotification creates a new notification
 func (s *NotificationService) CreateNotification(ctx context.Context, req *models.NotificationRequest) (*models.NotificationResponse, error) {
 	// Validate the request
@@ -81,6 +204,42 @@ func (s *NotificationService) CreateNotification(ctx context.Context, req *model
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
+	locale := req.Locale
+	if locale == "" {
+		locale = s.getRecipientLocale(ctx, req.RecipientID, req.RecipientType)
+	}
+
+	var templateData []byte
+	if len(req.TemplateData) > 0 {
+		var err error
+		templateData, err = json.Marshal(req.TemplateData)
+		if err != nil {
+			s.logger.Error("Failed to encode notification template data", "error", err)
+			return nil, fmt.Errorf("failed to encode template data: %w", err)
+		}
+	}
+
+	channels := []string{string(models.NotificationChannelEmail)}
+	if len(req.Channels) > 0 {
+		channels = channelNames(req.Channels)
+	}
+
+	scheduledFor := req.ScheduledFor
+	var digest bool
+	if s.preferences != nil {
+		plan, err := s.preferences.PlanDelivery(ctx, req, time.Now())
+		if err != nil {
+			// Better to send as usual than to lose the notification
+			s.logger.Warn("Failed to apply notification preferences", "recipient_id", req.RecipientID, "error", err)
+		} else {
+			channels = channelNames(plan.Channels)
+			digest = plan.Digest
+			if plan.SendAt != nil {
+				scheduledFor = plan.SendAt
+			}
+		}
+	}
+
 	// Convert to database parameters
 	params := queries.CreateNotificationParams{
 		RecipientID:   req.RecipientID,
@@ -88,6 +247,10 @@ func (s *NotificationService) CreateNotification(ctx context.Context, req *model
 		Type:          string(req.Type),
 		Title:         req.Title,
 		Message:       req.Message,
+		Locale:        locale,
+		TemplateData:  templateData,
+		Channels:      channels,
+		Digest:        digest,
 	}
 
 	// Create the notification in database
@@ -102,11 +265,26 @@ func (s *NotificationService) CreateNotification(ctx context.Context, req *model
 		"recipient_id", notification.RecipientID,
 		"type", notification.Type)
 
+	if s.realtime != nil {
+		s.realtime.NotificationCreated(ctx, notification)
+	}
+
 	// Convert to response format
 	response := s.convertToResponse(notification)
 
-	// Queue notification for delivery if not scheduled for future
-	if req.ScheduledFor == nil || req.ScheduledFor.Before(time.Now().Add(time.Minute)) {
+	switch {
+	case digest:
+		// Held back for the recipient's next digest email
+	case len(channels) == 0:
+		// The recipient turned off every channel, so it is only shown in the app
+		if err := s.querier.MarkNotificationAsSent(ctx, notification.ID); err != nil {
+			s.logger.Warn("Failed to mark in-app notification as sent", "notification_id", notification.ID, "error", err)
+		}
+	case s.emailQueue != nil:
+		// The email queue holds scheduled emails until they are due
+		s.queueEmail(ctx, notification.ID, req.Priority, scheduledFor)
+	case scheduledFor == nil || scheduledFor.Before(time.Now().Add(time.Minute)):
+		// Queue notification for delivery if not scheduled for future
 		if err := s.queueService.QueueNotification(ctx, notification.ID); err != nil {
 			s.logger.Warn("Failed to queue notification for delivery", "notification_id", notification.ID, "error", err)
 		}
@@ -115,6 +293,47 @@ func (s *NotificationService) CreateNotification(ctx context.Context, req *model
 	return response, nil
 }
 
+// channelNames converts channels to the names stored on a notification
+func channelNames(channels []models.NotificationChannel) []string {
+	names := make([]string, len(channels))
+	for i, channel := range channels {
+		names[i] = string(channel)
+	}
+	return names
+}
+
+// queueEmail puts the notification's email on the email queue, to be sent no
+// earlier than scheduledFor
+func (s *NotificationService) queueEmail(ctx context.Context, notificationID int32, priority models.NotificationPriority, scheduledFor *time.Time) {
+	queueReq := &models.EmailQueueRequest{
+		NotificationID: notificationID,
+		Priority:       emailQueuePriority(priority),
+		MaxAttempts:    s.emailMaxAttempts,
+	}
+	if scheduledFor != nil && scheduledFor.After(time.Now()) {
+		queueReq.ScheduledFor = scheduledFor
+	}
+
+	if _, err := s.emailQueue.QueueEmail(ctx, queueReq); err != nil {
+		s.logger.Warn("Failed to queue notification email", "notification_id", notificationID, "error", err)
+	}
+}
+
+// emailQueuePriority maps a notification priority onto the email queue's 1
+// (first) to 10 (last) scale
+func emailQueuePriority(priority models.NotificationPriority) int {
+	switch priority {
+	case models.NotificationPriorityUrgent:
+		return 1
+	case models.NotificationPriorityHigh:
+		return 3
+	case models.NotificationPriorityLow:
+		return 8
+	default:
+		return 5
+	}
+}
+
 // CreateBatchNotifications creates multiple notifications from a batch request
 func (s *NotificationService) CreateBatchNotifications(ctx context.Context, batch *models.NotificationBatch) ([]*models.NotificationResponse, error) {
 	if len(batch.Recipients) == 0 {
@@ -143,6 +362,7 @@ func (s *NotificationService) CreateBatchNotifications(ctx context.Context, batc
 			Priority:      batch.Priority,
 			Metadata:      batch.Metadata,
 			ScheduledFor:  batch.ScheduledFor,
+			Channels:      batch.Channels,
 		}
 
 		// Create the notification
@@ -191,6 +411,16 @@ func (s *NotificationService) MarkAsRead(ctx context.Context, id int32) error {
 	}
 
 	s.logger.Info("Notification marked as read", "id", id)
+
+	// The recipient's other sessions update their unread count
+	if s.realtime != nil {
+		notification, err := s.querier.GetNotificationByID(ctx, id)
+		if err != nil {
+			s.logger.Warn("Failed to load read notification for realtime update", "id", id, "error", err)
+			return nil
+		}
+		s.realtime.NotificationRead(ctx, notification)
+	}
 	return nil
 }
 
@@ -341,14 +571,13 @@ func (s *NotificationService) GetNotificationStats(ctx context.Context, filter *
 	}
 
 	// Get counts by type
-	types := []string{"overdue_reminder", "due_soon", "book_available", "fine_notice"}
-	for _, notificationType := range types {
-		count, err := s.querier.CountNotificationsByType(ctx, notificationType)
+	for _, notificationType := range models.NotificationTypes {
+		count, err := s.querier.CountNotificationsByType(ctx, string(notificationType))
 		if err != nil {
 			s.logger.Warn("Failed to get count for notification type", "type", notificationType, "error", err)
 			continue
 		}
-		stats.NotificationsByType[notificationType] = count
+		stats.NotificationsByType[string(notificationType)] = count
 		stats.TotalNotifications += count
 	}
 
@@ -409,6 +638,11 @@ func (s *NotificationService) CleanupOldNotifications(ctx context.Context, reten
 
 // convertToResponse converts a database notification to response format
 func (s *NotificationService) convertToResponse(notification queries.Notification) *models.NotificationResponse {
+	return convertToNotificationResponse(notification)
+}
+
+// convertToNotificationResponse converts a database notification to response format
+func convertToNotificationResponse(notification queries.Notification) *models.NotificationResponse {
 	response := &models.NotificationResponse{
 		ID:            notification.ID,
 		RecipientID:   notification.RecipientID,
@@ -416,10 +650,16 @@ func (s *NotificationService) convertToResponse(notification queries.Notificatio
 		Type:          models.NotificationType(notification.Type),
 		Title:         notification.Title,
 		Message:       notification.Message,
+		Locale:        notification.Locale,
+		Channels:      make([]models.NotificationChannel, len(notification.Channels)),
 		IsRead:        notification.IsRead.Bool,
 		CreatedAt:     notification.CreatedAt.Time,
 	}
 
+	for i, channel := range notification.Channels {
+		response.Channels[i] = models.NotificationChannel(channel)
+	}
+
 	if notification.SentAt.Valid {
 		response.SentAt = &notification.SentAt.Time
 	}
@@ -435,7 +675,7 @@ func (s *NotificationService) processMessageTemplate(template string, data map[s
 		for key, value := range data {
 			placeholder := fmt.Sprintf("{{.%s}}", key)
 			replacement := fmt.Sprintf("%v", value)
-			message = fmt.Sprintf(strings.ReplaceAll(message, placeholder, replacement))
+			message = strings.ReplaceAll(message, placeholder, replacement)
 		}
 	}
 	return message, nil
@@ -443,25 +683,118 @@ func (s *NotificationService) processMessageTemplate(template string, data map[s
 
 // processNotification processes a single notification for delivery
 func (s *NotificationService) processNotification(ctx context.Context, notification queries.Notification) error {
-	// Determine delivery method based on recipient type and notification type
-	switch models.RecipientType(notification.RecipientType) {
-	case models.RecipientTypeStudent, models.RecipientTypeLibrarian:
-		// Send email notification
-		if err := s.sendEmailNotification(ctx, notification); err != nil {
-			return fmt.Errorf("failed to send email: %w", err)
-		}
-	default:
+	if !models.RecipientType(notification.RecipientType).IsValid() {
 		return fmt.Errorf("unsupported recipient type: %s", notification.RecipientType)
 	}
 
-	// Mark as sent
-	if err := s.MarkAsSent(ctx, notification.ID); err != nil {
-		return fmt.Errorf("failed to mark as sent: %w", err)
+	reached, err := s.deliverOnChannels(ctx, notification, s.sendEmailNotification)
+	if reached {
+		if err := s.MarkAsSent(ctx, notification.ID); err != nil {
+			return fmt.Errorf("failed to mark as sent: %w", err)
+		}
+	}
+	return err
+}
+
+// deliverOnChannels sends the notification on each of its channels it has not
+// yet been delivered on, using sendEmail for the email channel. Each channel
+// that gets through is recorded so a retry goes out again only on the channels
+// that failed. Email to an address suppressed after hard bounces is given up on
+// rather than returned as a failure, since no retry would get through. It
+// reports whether the notification has reached the recipient on any channel, on
+// this attempt or an earlier one, and returns the failures.
+func (s *NotificationService) deliverOnChannels(ctx context.Context, notification queries.Notification, sendEmail func(context.Context, queries.Notification) error) (bool, error) {
+	channels := notification.Channels
+	if len(channels) == 0 {
+		channels = []string{string(models.NotificationChannelEmail)}
+	}
+
+	delivered, err := s.querier.ListDeliveredNotificationChannels(ctx, notification.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get delivered channels: %w", err)
+	}
+	reached := len(delivered) > 0
+	suppressed := false
+
+	var errs []error
+	for _, channel := range channels {
+		if slices.Contains(delivered, channel) {
+			continue
+		}
+
+		var err error
+		switch models.NotificationChannel(channel) {
+		case models.NotificationChannelEmail:
+			err = sendEmail(ctx, notification)
+		case models.NotificationChannelSMS:
+			if s.sms == nil {
+				s.logger.Debug("SMS is not configured, skipping channel", "notification_id", notification.ID)
+				continue
+			}
+			err = s.sendSMSNotification(ctx, notification)
+		default:
+			err = fmt.Errorf("unsupported notification channel: %s", channel)
+		}
+
+		if errors.Is(err, ErrEmailSuppressed) {
+			s.logger.Info("Not emailing suppressed address", "notification_id", notification.ID)
+			suppressed = true
+			continue
+		}
+		if err != nil {
+			s.logger.Warn("Failed to deliver notification on channel",
+				"notification_id", notification.ID,
+				"channel", channel,
+				"error", err)
+			errs = append(errs, err)
+			continue
+		}
+		reached = true
+
+		// Recorded even if ctx ended as the send finished, or the channel would be sent again
+		if err := s.querier.RecordNotificationChannelDelivered(context.WithoutCancel(ctx), queries.RecordNotificationChannelDeliveredParams{
+			NotificationID: notification.ID,
+			Channel:        channel,
+		}); err != nil {
+			s.logger.Error("Failed to record notification channel delivery",
+				"notification_id", notification.ID,
+				"channel", channel,
+				"error", err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return reached, errors.Join(errs...)
+	}
+	if !reached && !suppressed {
+		return false, ErrSMSNotConfigured
 	}
+	return reached, nil
+}
 
+// sendSMSNotification texts the notification to the recipient's phone
+func (s *NotificationService) sendSMSNotification(ctx context.Context, notification queries.Notification) error {
+	phone, err := s.getRecipientPhone(ctx, notification.RecipientID, models.RecipientType(notification.RecipientType))
+	if err != nil {
+		return fmt.Errorf("failed to get recipient phone: %w", err)
+	}
+
+	if err := s.sms.SendNotificationSMS(ctx, notification.ID, phone, s.renderSMS(notification)); err != nil {
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
 	return nil
 }
 
+// renderSMS writes the text message for a notification from the short template
+// for its type, or from its title when the type has none
+func (s *NotificationService) renderSMS(notification queries.Notification) string {
+	template := GetSMSTemplate(notification.Type, notification.Locale)
+	if template == "" {
+		return RenderSMS(notification.Title, nil)
+	}
+	return RenderSMS(template, s.extractTemplateData(notification))
+}
+
 // sendEmailNotification sends an email notification
 func (s *NotificationService) sendEmailNotification(ctx context.Context, notification queries.Notification) error {
 	// Get recipient email based on type
@@ -470,8 +803,20 @@ func (s *NotificationService) sendEmailNotification(ctx context.Context, notific
 		return fmt.Errorf("failed to get recipient email: %w", err)
 	}
 
-	// Get default template for this notification type
-	template := GetDefaultTemplate(notification.Type)
+	return s.sendEmailTo(ctx, notification, recipientEmail)
+}
+
+// sendEmailTo sends the notification's email to recipientEmail, with an
+// unsubscribe link when recipients can turn its type off
+func (s *NotificationService) sendEmailTo(ctx context.Context, notification queries.Notification, recipientEmail string) error {
+	if s.unsubscribe != nil {
+		link := s.unsubscribe.UnsubscribeURL(notification.RecipientID, models.RecipientType(notification.RecipientType), models.NotificationType(notification.Type))
+		if link != "" {
+			ctx = WithListUnsubscribe(ctx, link)
+		}
+	}
+
+	template := s.resolveTemplate(ctx, notification.Type, notification.Locale)
 	if template == nil {
 		// Fall back to simple email if no template found
 		return s.emailService.SendEmail(ctx, recipientEmail, notification.Title, notification.Message, false)
@@ -481,7 +826,7 @@ func (s *NotificationService) sendEmailNotification(ctx context.Context, notific
 	templateData := s.extractTemplateData(notification)
 
 	// Send templated email
-	err = s.emailService.SendTemplatedEmail(ctx, recipientEmail, template, templateData)
+	err := s.emailService.SendTemplatedEmail(ctx, recipientEmail, template, templateData)
 	if err != nil {
 		s.logger.Error("Failed to send email notification",
 			"notification_id", notification.ID,
@@ -499,6 +844,170 @@ func (s *NotificationService) sendEmailNotification(ctx context.Context, notific
 	return nil
 }
 
+// resolveTemplate returns the template for a notification type in the
+// recipient's locale, falling back to English. For each locale the version
+// published in the template store is preferred over the built-in one.
+func (s *NotificationService) resolveTemplate(ctx context.Context, name, locale string) *models.EmailTemplate {
+	for _, candidate := range localeFallbacks(NormalizeLocale(locale)) {
+		if s.templates != nil {
+			template, err := s.templates.ResolveTemplate(ctx, name, candidate)
+			if err == nil {
+				return template
+			}
+			if !errors.Is(err, ErrTemplateNotFound) {
+				s.logger.Warn("Failed to resolve stored email template, using the built-in one",
+					"template", name,
+					"locale", candidate,
+					"error", err)
+			}
+		}
+		if template := GetBuiltInTemplate(name, candidate); template != nil {
+			return template
+		}
+	}
+	return nil
+}
+
+// createLocalizedNotification writes the notification's title and message from
+// the subject and body of its type's template in req.Locale, then creates it.
+// The data is kept with the notification so its email is rendered the same way.
+func (s *NotificationService) createLocalizedNotification(ctx context.Context, req *models.NotificationRequest, data map[string]interface{}) (*models.NotificationResponse, error) {
+	template := s.resolveTemplate(ctx, string(req.Type), req.Locale)
+	if template == nil {
+		return nil, fmt.Errorf("no template for notification type %s", req.Type)
+	}
+
+	title, err := s.processMessageTemplate(template.Subject, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process title template: %w", err)
+	}
+	message, err := s.processMessageTemplate(template.Body, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process message template: %w", err)
+	}
+
+	req.Title = title
+	req.Message = message
+	req.TemplateData = data
+	return s.CreateNotification(ctx, req)
+}
+
+// studentNotice builds a notice telling a student about a change to their loans,
+// reservations or account, to go out by email and SMS. The title and message are
+// written from the built-in template for its type in the student's locale, and
+// the data is kept so its email and SMS are rendered the same way.
+func studentNotice(studentID int32, notificationType models.NotificationType, priority models.NotificationPriority, locale string, data map[string]interface{}) (*models.NotificationRequest, error) {
+	locale = NormalizeLocale(locale)
+	req := &models.NotificationRequest{
+		RecipientID:   studentID,
+		RecipientType: models.RecipientTypeStudent,
+		Type:          notificationType,
+		Priority:      priority,
+		Locale:        locale,
+		TemplateData:  data,
+		Channels:      emailAndSMS,
+	}
+	for _, candidate := range localeFallbacks(locale) {
+		if template := GetBuiltInTemplate(string(notificationType), candidate); template != nil {
+			req.Title = fillTemplate(template.Subject, data)
+			req.Message = fillTemplate(template.Body, data)
+			return req, nil
+		}
+	}
+	return nil, fmt.Errorf("no template for notification type %s", notificationType)
+}
+
+// sendStudentNotice creates a notice once the change it tells the student about
+// has been made. The change stands if the notice fails, so the failure is left to
+// the notifier to log rather than returned. Nothing is sent without a notifier.
+func sendStudentNotice(ctx context.Context, notifier StudentNotifier, notice *models.NotificationRequest) {
+	if notifier == nil {
+		return
+	}
+	_, _ = notifier.CreateNotification(ctx, notice)
+}
+
+// DeliverEmail delivers a notification taken off the email queue on each of its
+// channels. Each email attempt is recorded as an email delivery, and channels an
+// earlier attempt got through on are not sent again. The notification is marked
+// sent once it reaches the recipient on any channel, and an error is returned
+// while any channel is still failing so the queue tries that channel again. A
+// suppressed address is not a failure, so its queue item is not retried.
+func (s *NotificationService) DeliverEmail(ctx context.Context, notificationID int32, attempt, maxAttempts int) error {
+	notification, err := s.querier.GetNotificationByID(ctx, notificationID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return fmt.Errorf("notification %d not found", notificationID)
+		}
+		return fmt.Errorf("failed to get notification: %w", err)
+	}
+
+	reached, err := s.deliverOnChannels(ctx, notification, func(ctx context.Context, notification queries.Notification) error {
+		return s.deliverQueuedEmail(ctx, notification, attempt, maxAttempts)
+	})
+	if reached && !notification.SentAt.Valid {
+		if err := s.MarkAsSent(context.WithoutCancel(ctx), notificationID); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// deliverQueuedEmail sends a queued notification's email, recording the attempt
+func (s *NotificationService) deliverQueuedEmail(ctx context.Context, notification queries.Notification, attempt, maxAttempts int) error {
+	notificationID := notification.ID
+	recipientEmail, err := s.getRecipientEmail(ctx, notification.RecipientID, models.RecipientType(notification.RecipientType))
+	if err != nil {
+		return fmt.Errorf("failed to get recipient email: %w", err)
+	}
+
+	var delivery *models.EmailDelivery
+	if s.deliveries != nil {
+		delivery, err = s.deliveries.CreateDelivery(ctx, &models.EmailDeliveryRequest{
+			NotificationID: notificationID,
+			EmailAddress:   recipientEmail,
+			Status:         models.EmailDeliveryStatusPending,
+			RetryCount:     attempt - 1,
+			MaxRetries:     maxAttempts - 1,
+		})
+		if err != nil {
+			// The email matters more than its record
+			s.logger.Error("Failed to record email delivery", "notification_id", notificationID, "error", err)
+		}
+	}
+
+	// A tracked email's bounce can be matched back to its delivery
+	var tracking *EmailTracking
+	if delivery != nil {
+		tracking = &EmailTracking{DeliveryID: delivery.ID}
+		ctx = WithEmailTracking(ctx, tracking)
+	}
+
+	sendErr := s.sendEmailTo(ctx, notification, recipientEmail)
+
+	// Record the outcome even if ctx ended as the send finished, or the email
+	// would be sent again
+	recordCtx := context.WithoutCancel(ctx)
+	if delivery != nil {
+		if sendErr != nil {
+			_, err = s.deliveries.UpdateDeliveryError(recordCtx, delivery.ID, sendErr.Error())
+		} else {
+			_, err = s.deliveries.UpdateDeliveryStatus(recordCtx, delivery.ID, models.EmailDeliveryStatusSent)
+		}
+		if err != nil {
+			s.logger.Error("Failed to update email delivery", "delivery_id", delivery.ID, "error", err)
+		}
+
+		if sendErr == nil && tracking.MessageID != "" {
+			if _, err := s.deliveries.UpdateProviderInfo(recordCtx, delivery.ID, tracking.MessageID, nil); err != nil {
+				s.logger.Error("Failed to record email message ID", "delivery_id", delivery.ID, "error", err)
+			}
+		}
+	}
+
+	return sendErr
+}
+
 // Automated notification methods (to be implemented in Phase 7.2)
 
 // SendDueSoonReminders sends reminders for books due soon (within 3 days)
@@ -521,30 +1030,16 @@ func (s *NotificationService) SendDueSoonReminders(ctx context.Context) error {
 	for _, transaction := range dueSoonTransactions {
 		// Calculate days until due
 		daysUntilDue := int(transaction.DueDate.Time.Sub(time.Now()).Hours() / 24)
-
-		// Create personalized notification
-		title := fmt.Sprintf("Book Due Soon: %s", transaction.Title)
-		message := fmt.Sprintf("Dear %s %s,\n\n"+
-			"This is a reminder that the book \"%s\" by %s is due for return "+
-			"in %d day(s) on %s.\n\n"+
-			"Please return the book to avoid late fees.\n\n"+
-			"Book ID: %s\n"+
-			"Student ID: %s\n\n"+
-			"Thank you,\nLibrary Management System",
-			transaction.FirstName, transaction.LastName,
-			transaction.Title, transaction.Author,
-			daysUntilDue,
-			transaction.DueDate.Time.Format("January 2, 2006"),
-			transaction.BookID_2, transaction.StudentID_2)
+		locale := NormalizeLocale(transaction.Locale)
 
 		// Create notification request
 		req := &models.NotificationRequest{
 			RecipientID:   transaction.StudentID,
 			RecipientType: models.RecipientTypeStudent,
 			Type:          models.NotificationTypeDueSoon,
-			Title:         title,
-			Message:       message,
 			Priority:      models.NotificationPriorityMedium,
+			Locale:        locale,
+			Channels:      emailAndSMS,
 			Metadata: map[string]interface{}{
 				"transaction_id": transaction.ID,
 				"book_id":        transaction.BookID,
@@ -552,9 +1047,16 @@ func (s *NotificationService) SendDueSoonReminders(ctx context.Context) error {
 				"days_until_due": daysUntilDue,
 			},
 		}
+		data := map[string]interface{}{
+			"StudentName":  transaction.FirstName + " " + transaction.LastName,
+			"BookTitle":    transaction.Title,
+			"BookAuthor":   transaction.Author,
+			"DueDate":      FormatDate(transaction.DueDate.Time, locale),
+			"DaysUntilDue": daysUntilDue,
+		}
 
 		// Create the notification
-		notification, err := s.CreateNotification(ctx, req)
+		notification, err := s.createLocalizedNotification(ctx, req, data)
 		if err != nil {
 			s.logger.Warn("Failed to create due soon notification",
 				"student_id", transaction.StudentID,
@@ -602,57 +1104,35 @@ func (s *NotificationService) SendOverdueReminders(ctx context.Context) error {
 	for _, transaction := range overdueTransactions {
 		// Calculate days overdue
 		daysOverdue := int(time.Since(transaction.DueDate.Time).Hours() / 24)
-
-		// Format fine amount
-		fineAmount := "0.00"
-		if transaction.FineAmount.Valid {
-			if jsonBytes, err := transaction.FineAmount.MarshalJSON(); err == nil {
-				fineAmount = string(jsonBytes)
-				// Remove quotes if present
-				if len(fineAmount) >= 2 && fineAmount[0] == '"' && fineAmount[len(fineAmount)-1] == '"' {
-					fineAmount = fineAmount[1 : len(fineAmount)-1]
-				}
-			}
-		}
-
-		// Create personalized notification
-		title := fmt.Sprintf("Overdue Book: %s", transaction.Title)
-		message := fmt.Sprintf("Dear %s %s,\n\n"+
-			"This is an urgent reminder that the book \"%s\" by %s is overdue.\n\n"+
-			"Due Date: %s\n"+
-			"Days Overdue: %d\n"+
-			"Fine Amount: $%s\n\n"+
-			"Please return the book immediately to avoid additional fees.\n\n"+
-			"Book ID: %s\n"+
-			"Student ID: %s\n\n"+
-			"Contact the library for assistance.\n\n"+
-			"Thank you,\nLibrary Management System",
-			transaction.FirstName, transaction.LastName,
-			transaction.Title, transaction.Author,
-			transaction.DueDate.Time.Format("January 2, 2006"),
-			daysOverdue,
-			fineAmount,
-			transaction.BookID_2, transaction.StudentID_2)
+		locale := NormalizeLocale(transaction.Locale)
+		fineAmount := numericToDecimal(transaction.FineAmount)
 
 		// Create notification request
 		req := &models.NotificationRequest{
 			RecipientID:   transaction.StudentID,
 			RecipientType: models.RecipientTypeStudent,
 			Type:          models.NotificationTypeOverdueReminder,
-			Title:         title,
-			Message:       message,
 			Priority:      models.NotificationPriorityHigh,
+			Locale:        locale,
 			Metadata: map[string]interface{}{
 				"transaction_id": transaction.ID,
 				"book_id":        transaction.BookID,
 				"due_date":       transaction.DueDate.Time.Format("2006-01-02"),
 				"days_overdue":   daysOverdue,
-				"fine_amount":    fineAmount,
+				"fine_amount":    fineAmount.StringFixed(2),
 			},
 		}
+		data := map[string]interface{}{
+			"StudentName": transaction.FirstName + " " + transaction.LastName,
+			"BookTitle":   transaction.Title,
+			"BookAuthor":  transaction.Author,
+			"DueDate":     FormatDate(transaction.DueDate.Time, locale),
+			"DaysOverdue": daysOverdue,
+			"FineAmount":  FormatMoney(fineAmount, locale),
+		}
 
 		// Create the notification
-		notification, err := s.CreateNotification(ctx, req)
+		notification, err := s.createLocalizedNotification(ctx, req, data)
 		if err != nil {
 			s.logger.Warn("Failed to create overdue notification",
 				"student_id", transaction.StudentID,
@@ -668,7 +1148,7 @@ func (s *NotificationService) SendOverdueReminders(ctx context.Context) error {
 			"student_code", transaction.StudentID_2,
 			"book_title", transaction.Title,
 			"days_overdue", daysOverdue,
-			"fine_amount", fineAmount)
+			"fine_amount", fineAmount.StringFixed(2))
 
 		successCount++
 	}
@@ -701,32 +1181,16 @@ func (s *NotificationService) SendBookAvailableNotifications(ctx context.Context
 	for _, reservation := range reservations {
 		// Calculate how long the book has been reserved
 		daysReserved := int(time.Since(reservation.ReservedAt.Time).Hours() / 24)
-
-		// Create personalized notification
-		title := fmt.Sprintf("Reserved Book Available: %s", reservation.Title)
-		message := fmt.Sprintf("Dear %s %s,\n\n"+
-			"Great news! The book \"%s\" by %s that you reserved is now available.\n\n"+
-			"Reserved on: %s\n"+
-			"Days waited: %d\n\n"+
-			"Please visit the library to collect your book within 24 hours, "+
-			"or your reservation will expire.\n\n"+
-			"Book ID: %s\n"+
-			"Student ID: %s\n\n"+
-			"Thank you,\nLibrary Management System",
-			reservation.FirstName, reservation.LastName,
-			reservation.Title, reservation.Author,
-			reservation.ReservedAt.Time.Format("January 2, 2006"),
-			daysReserved,
-			reservation.BookCode, reservation.StudentCode)
+		locale := NormalizeLocale(reservation.Locale)
 
 		// Create notification request
 		req := &models.NotificationRequest{
 			RecipientID:   reservation.StudentID,
 			RecipientType: models.RecipientTypeStudent,
 			Type:          models.NotificationTypeBookAvailable,
-			Title:         title,
-			Message:       message,
 			Priority:      models.NotificationPriorityHigh,
+			Locale:        locale,
+			Channels:      emailAndSMS,
 			Metadata: map[string]interface{}{
 				"reservation_id": reservation.ID,
 				"book_id":        reservation.BookID,
@@ -734,9 +1198,15 @@ func (s *NotificationService) SendBookAvailableNotifications(ctx context.Context
 				"days_waited":    daysReserved,
 			},
 		}
+		data := map[string]interface{}{
+			"StudentName":    reservation.FirstName + " " + reservation.LastName,
+			"BookTitle":      reservation.Title,
+			"BookAuthor":     reservation.Author,
+			"ExpirationDays": reservationExpirationDays(reservation.ExpiresAt),
+		}
 
 		// Create the notification
-		notification, err := s.CreateNotification(ctx, req)
+		notification, err := s.createLocalizedNotification(ctx, req, data)
 		if err != nil {
 			s.logger.Warn("Failed to create book available notification",
 				"student_id", reservation.StudentID,
@@ -784,70 +1254,48 @@ func (s *NotificationService) SendFineNotices(ctx context.Context) error {
 
 	var successCount, failureCount int
 	for _, transaction := range fineTransactions {
-		// Format fine amount
-		fineAmount := "0.00"
-		if transaction.FineAmount.Valid {
-			if jsonBytes, err := transaction.FineAmount.MarshalJSON(); err == nil {
-				fineAmount = string(jsonBytes)
-				// Remove quotes if present
-				if len(fineAmount) >= 2 && fineAmount[0] == '"' && fineAmount[len(fineAmount)-1] == '"' {
-					fineAmount = fineAmount[1 : len(fineAmount)-1]
-				}
-			}
-		}
+		locale := NormalizeLocale(transaction.Locale)
+		fineAmount := numericToDecimal(transaction.FineAmount)
 
 		// Determine if the book is still overdue or returned
 		bookStatus := "returned"
-		statusMessage := "Although the book has been returned, there is still an outstanding fine."
+		fineReason := fineReasons[locale][bookStatus]
 		if !transaction.ReturnedDate.Valid {
 			bookStatus = "overdue"
 			daysOverdue := int(time.Since(transaction.DueDate.Time).Hours() / 24)
-			statusMessage = fmt.Sprintf("The book is still %d days overdue. Please return it immediately.", daysOverdue)
-		}
-
-		// Create personalized notification
-		title := fmt.Sprintf("Outstanding Fine: $%s", fineAmount)
-		message := fmt.Sprintf("Dear %s %s,\n\n"+
-			"You have an outstanding fine of $%s for the book \"%s\" by %s.\n\n"+
-			"Book Status: %s\n"+
-			"%s\n\n"+
-			"Please pay this fine at the library as soon as possible.\n\n"+
-			"Book ID: %s\n"+
-			"Student ID: %s\n"+
-			"Fine Amount: $%s\n\n"+
-			"Contact the library for payment options.\n\n"+
-			"Thank you,\nLibrary Management System",
-			transaction.FirstName, transaction.LastName,
-			fineAmount,
-			transaction.Title, transaction.Author,
-			bookStatus,
-			statusMessage,
-			transaction.BookID_2, transaction.StudentID_2,
-			fineAmount)
+			// Fines on loans still out are accrued nightly, so the amount is the fine so far
+			fineReason = fmt.Sprintf(fineReasons[locale][bookStatus], daysOverdue)
+		}
 
 		// Create notification request
 		req := &models.NotificationRequest{
 			RecipientID:   transaction.StudentID,
 			RecipientType: models.RecipientTypeStudent,
 			Type:          models.NotificationTypeFineNotice,
-			Title:         title,
-			Message:       message,
 			Priority:      models.NotificationPriorityHigh,
+			Locale:        locale,
 			Metadata: map[string]interface{}{
 				"transaction_id": transaction.ID,
 				"book_id":        transaction.BookID,
-				"fine_amount":    fineAmount,
+				"fine_amount":    fineAmount.StringFixed(2),
 				"book_status":    bookStatus,
 			},
 		}
+		data := map[string]interface{}{
+			"StudentName": transaction.FirstName + " " + transaction.LastName,
+			"BookTitle":   transaction.Title,
+			"BookAuthor":  transaction.Author,
+			"FineAmount":  FormatMoney(fineAmount, locale),
+			"FineReason":  fineReason,
+		}
 
 		// Create the notification
-		notification, err := s.CreateNotification(ctx, req)
+		notification, err := s.createLocalizedNotification(ctx, req, data)
 		if err != nil {
 			s.logger.Warn("Failed to create fine notice notification",
 				"student_id", transaction.StudentID,
 				"book_id", transaction.BookID,
-				"fine_amount", fineAmount,
+				"fine_amount", fineAmount.StringFixed(2),
 				"error", err)
 			failureCount++
 			continue
@@ -858,7 +1306,7 @@ func (s *NotificationService) SendFineNotices(ctx context.Context) error {
 			"student_id", transaction.StudentID,
 			"student_code", transaction.StudentID_2,
 			"book_title", transaction.Title,
-			"fine_amount", fineAmount)
+			"fine_amount", fineAmount.StringFixed(2))
 
 		successCount++
 	}
@@ -871,8 +1319,28 @@ func (s *NotificationService) SendFineNotices(ctx context.Context) error {
 	return nil
 }
 
-// getRecipientEmail retrieves the email address for a recipient
+// getRecipientEmail retrieves the email address to send a recipient's email
+// to. An address suppressed after repeated hard bounces is not returned.
 func (s *NotificationService) getRecipientEmail(ctx context.Context, recipientID int32, recipientType models.RecipientType) (string, error) {
+	email, err := s.lookupRecipientEmail(ctx, recipientID, recipientType)
+	if err != nil {
+		return "", err
+	}
+
+	if s.suppressions != nil {
+		suppressed, err := s.suppressions.IsSuppressed(ctx, email)
+		if err != nil {
+			return "", fmt.Errorf("failed to check email suppression: %w", err)
+		}
+		if suppressed {
+			return "", fmt.Errorf("%w: %s", ErrEmailSuppressed, email)
+		}
+	}
+	return email, nil
+}
+
+// lookupRecipientEmail retrieves the email address on file for a recipient
+func (s *NotificationService) lookupRecipientEmail(ctx context.Context, recipientID int32, recipientType models.RecipientType) (string, error) {
 	switch recipientType {
 	case models.RecipientTypeStudent:
 		// Cast querier to include student queries
@@ -908,14 +1376,103 @@ func (s *NotificationService) getRecipientEmail(ctx context.Context, recipientID
 	}
 }
 
-// extractTemplateData extracts template data from notification metadata
+// getRecipientPhone retrieves the phone number for a recipient. Only students
+// have one on record.
+func (s *NotificationService) getRecipientPhone(ctx context.Context, recipientID int32, recipientType models.RecipientType) (string, error) {
+	if recipientType != models.RecipientTypeStudent {
+		return "", ErrSMSChannelNotSupported
+	}
+
+	studentQuerier, ok := s.querier.(interface {
+		GetStudentByID(ctx context.Context, id int32) (queries.Student, error)
+	})
+	if !ok {
+		return "", fmt.Errorf("querier does not support student queries")
+	}
+
+	student, err := studentQuerier.GetStudentByID(ctx, recipientID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get student: %w", err)
+	}
+	if !student.Phone.Valid || strings.TrimSpace(student.Phone.String) == "" {
+		return "", ErrRecipientHasNoPhone
+	}
+	return student.Phone.String, nil
+}
+
+// extractTemplateData extracts template data from the notification, including
+// the data its title and message were rendered with
 func (s *NotificationService) extractTemplateData(notification queries.Notification) map[string]interface{} {
-	// For now, return basic template data
-	// In a real implementation, this would parse the metadata field
-	return map[string]interface{}{
+	data := map[string]interface{}{
 		"NotificationTitle":   notification.Title,
 		"NotificationMessage": notification.Message,
 		"NotificationID":      notification.ID,
 		"RecipientID":         notification.RecipientID,
 	}
+
+	if len(notification.TemplateData) > 0 {
+		var stored map[string]interface{}
+		if err := json.Unmarshal(notification.TemplateData, &stored); err != nil {
+			s.logger.Warn("Failed to decode notification template data",
+				"notification_id", notification.ID,
+				"error", err)
+		}
+		for key, value := range stored {
+			data[key] = value
+		}
+	}
+	return data
+}
+
+// getRecipientLocale returns the locale a recipient prefers, or the default
+// locale if it cannot be looked up
+func (s *NotificationService) getRecipientLocale(ctx context.Context, recipientID int32, recipientType models.RecipientType) string {
+	switch recipientType {
+	case models.RecipientTypeStudent:
+		if studentQuerier, ok := s.querier.(interface {
+			GetStudentByID(ctx context.Context, id int32) (queries.Student, error)
+		}); ok {
+			if student, err := studentQuerier.GetStudentByID(ctx, recipientID); err == nil {
+				return NormalizeLocale(student.Locale)
+			}
+		}
+	case models.RecipientTypeLibrarian:
+		if userQuerier, ok := s.querier.(interface {
+			GetUserByID(ctx context.Context, id int32) (queries.User, error)
+		}); ok {
+			if user, err := userQuerier.GetUserByID(ctx, recipientID); err == nil {
+				return NormalizeLocale(user.Locale)
+			}
+		}
+	}
+	return models.DefaultLocale
+}
+
+// reservationExpirationDays returns the whole days left to collect a reserved
+// book, at least one
+func reservationExpirationDays(expiresAt pgtype.Timestamp) int {
+	if !expiresAt.Valid {
+		return 1
+	}
+	days := int(math.Ceil(time.Until(expiresAt.Time).Hours() / 24))
+	if days < 1 {
+		return 1
+	}
+	return days
+}
+
+// emailAndSMS are the channels for notices students need to act on quickly
+var emailAndSMS = []models.NotificationChannel{models.NotificationChannelEmail, models.NotificationChannelSMS}
+
+// fineReasons explains an unpaid fine by book status, in each locale. The
+// overdue reason takes the number of days overdue.
+var fineReasons = map[string]map[string]string{
+	models.LocaleEnglish: {
+		"returned": "The book has been returned but the fine is still unpaid.",
+		"overdue":  "The book is still %d days overdue. The fine shown is what has accrued so far and will keep increasing until the book is returned.",
+	},
+	models.LocaleSwahili: {
+		"returned": "Kitabu kimerudishwa lakini faini bado haijalipwa.",
+		"overdue":  "Kitabu bado kimechelewa kwa siku %d. Faini iliyoonyeshwa ni ile iliyolimbikizwa hadi sasa na itaendelea kuongezeka hadi kitabu kirudishwe.",
+	},
 }
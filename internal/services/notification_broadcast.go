@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+var (
+	ErrInvalidBroadcast      = errors.New("invalid broadcast")
+	ErrNoBroadcastRecipients = errors.New("no students match the broadcast segment")
+	ErrBroadcastNotFound     = errors.New("broadcast not found")
+)
+
+// BroadcastQuerier defines the database operations behind broadcasts
+type BroadcastQuerier interface {
+	ListBroadcastStudents(ctx context.Context, arg queries.ListBroadcastStudentsParams) ([]queries.ListBroadcastStudentsRow, error)
+	CreateNotificationBroadcast(ctx context.Context, arg queries.CreateNotificationBroadcastParams) (queries.NotificationBroadcast, error)
+	GetNotificationBroadcast(ctx context.Context, id int32) (queries.NotificationBroadcast, error)
+	ListNotificationBroadcasts(ctx context.Context, arg queries.ListNotificationBroadcastsParams) ([]queries.ListNotificationBroadcastsRow, error)
+	CountNotificationBroadcasts(ctx context.Context) (int64, error)
+	CreateBroadcastRecipient(ctx context.Context, arg queries.CreateBroadcastRecipientParams) error
+	ListBroadcastRecipients(ctx context.Context, broadcastID int32) ([]queries.ListBroadcastRecipientsRow, error)
+}
+
+// BatchNotifier creates one notification for each recipient of a batch
+type BatchNotifier interface {
+	CreateBatchNotifications(ctx context.Context, batch *models.NotificationBatch) ([]*models.NotificationResponse, error)
+}
+
+// BroadcastServiceInterface defines the librarian broadcast operations
+type BroadcastServiceInterface interface {
+	CreateBroadcast(ctx context.Context, req *models.CreateBroadcastRequest, createdBy int32) (*models.Broadcast, error)
+	GetBroadcast(ctx context.Context, id int32) (*models.Broadcast, error)
+	ListBroadcasts(ctx context.Context, page, limit int) (*models.BroadcastListResponse, error)
+}
+
+// BroadcastService sends announcements to segments of students. Each student
+// gets their own announcement notification, delivered under their preferences,
+// and the broadcast keeps a record of every recipient so its delivery can be
+// followed.
+type BroadcastService struct {
+	querier       BroadcastQuerier
+	notifications BatchNotifier
+}
+
+// NewBroadcastService creates a new broadcast service
+func NewBroadcastService(querier BroadcastQuerier, notifications BatchNotifier) *BroadcastService {
+	return &BroadcastService{
+		querier:       querier,
+		notifications: notifications,
+	}
+}
+
+// CreateBroadcast sends an announcement to every active student in the
+// request's segment. The message may use {{.StudentName}}, {{.FirstName}} and
+// {{.LastName}}. Students whose notification could not be created are recorded
+// as failed rather than failing the broadcast.
+func (s *BroadcastService) CreateBroadcast(ctx context.Context, req *models.CreateBroadcastRequest, createdBy int32) (*models.Broadcast, error) {
+	if err := validateBroadcast(req); err != nil {
+		return nil, err
+	}
+
+	students, err := s.querier.ListBroadcastStudents(ctx, queries.ListBroadcastStudentsParams{
+		YearOfStudy:      req.Segment.YearOfStudy,
+		Department:       req.Segment.Department,
+		ActiveBorrowers:  req.Segment.ActiveBorrowers,
+		WithOverdueLoans: req.Segment.WithOverdueLoans,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list broadcast students: %w", err)
+	}
+	if len(students) == 0 {
+		return nil, ErrNoBroadcastRecipients
+	}
+
+	segment, err := json.Marshal(req.Segment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal segment: %w", err)
+	}
+	params := queries.CreateNotificationBroadcastParams{
+		Title:    req.Title,
+		Message:  req.Message,
+		Priority: string(req.Priority),
+		Channels: channelNames(req.Channels),
+		Segment:  segment,
+	}
+	if req.ScheduledFor != nil {
+		params.ScheduledFor = pgtype.Timestamp{Time: *req.ScheduledFor, Valid: true}
+	}
+	if createdBy > 0 {
+		params.CreatedBy = pgtype.Int4{Int32: createdBy, Valid: true}
+	}
+	broadcast, err := s.querier.CreateNotificationBroadcast(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create broadcast: %w", err)
+	}
+
+	batch := &models.NotificationBatch{
+		Type:            models.NotificationTypeAnnouncement,
+		Title:           req.Title,
+		MessageTemplate: req.Message,
+		Priority:        req.Priority,
+		Recipients:      make([]models.NotificationRecipient, len(students)),
+		ScheduledFor:    req.ScheduledFor,
+		Channels:        req.Channels,
+	}
+	for i, student := range students {
+		batch.Recipients[i] = models.NotificationRecipient{
+			ID:   student.ID,
+			Type: models.RecipientTypeStudent,
+			MessageData: map[string]interface{}{
+				"StudentName": student.FirstName + " " + student.LastName,
+				"FirstName":   student.FirstName,
+				"LastName":    student.LastName,
+			},
+		}
+	}
+
+	// The batch only fails outright when no notification could be created, in
+	// which case every student is recorded as failed below
+	responses, batchErr := s.notifications.CreateBatchNotifications(ctx, batch)
+	created := make(map[int32]int32, len(responses))
+	for _, response := range responses {
+		created[response.RecipientID] = response.ID
+	}
+
+	// The recipients are recorded even if the request is cancelled part way, as
+	// their notifications have already been created
+	ctx = context.WithoutCancel(ctx)
+	for _, student := range students {
+		recipient := queries.CreateBroadcastRecipientParams{
+			BroadcastID: broadcast.ID,
+			StudentID:   student.ID,
+		}
+		if notificationID, ok := created[student.ID]; ok {
+			recipient.NotificationID = pgtype.Int4{Int32: notificationID, Valid: true}
+		} else {
+			recipient.ErrorMessage = pgtype.Text{String: "notification could not be created", Valid: true}
+		}
+		if err := s.querier.CreateBroadcastRecipient(ctx, recipient); err != nil {
+			return nil, fmt.Errorf("failed to record broadcast recipient %d: %w", student.ID, err)
+		}
+	}
+	if batchErr != nil && len(responses) == 0 {
+		return nil, fmt.Errorf("failed to send broadcast %d: %w", broadcast.ID, batchErr)
+	}
+
+	return s.GetBroadcast(ctx, broadcast.ID)
+}
+
+// GetBroadcast returns a broadcast with the delivery status of each recipient
+func (s *BroadcastService) GetBroadcast(ctx context.Context, id int32) (*models.Broadcast, error) {
+	row, err := s.querier.GetNotificationBroadcast(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, ErrBroadcastNotFound
+		}
+		return nil, fmt.Errorf("failed to get broadcast: %w", err)
+	}
+
+	recipients, err := s.querier.ListBroadcastRecipients(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list broadcast recipients: %w", err)
+	}
+
+	broadcast := convertToBroadcast(row)
+	broadcast.Recipients = make([]models.BroadcastRecipient, len(recipients))
+	for i, recipient := range recipients {
+		status := models.NotificationStatus(recipient.Status)
+		broadcast.Recipients[i] = models.BroadcastRecipient{
+			StudentID: recipient.StudentID,
+			Name:      recipient.FirstName + " " + recipient.LastName,
+			Status:    status,
+			Error:     recipient.ErrorMessage,
+		}
+		if recipient.NotificationID.Valid {
+			notificationID := recipient.NotificationID.Int32
+			broadcast.Recipients[i].NotificationID = &notificationID
+		}
+		if recipient.SentAt.Valid {
+			sentAt := recipient.SentAt.Time
+			broadcast.Recipients[i].SentAt = &sentAt
+		}
+
+		broadcast.Summary.Recipients++
+		switch status {
+		case models.NotificationStatusSent:
+			broadcast.Summary.Sent++
+		case models.NotificationStatusFailed:
+			broadcast.Summary.Failed++
+		default:
+			broadcast.Summary.Pending++
+		}
+	}
+
+	return broadcast, nil
+}
+
+// ListBroadcasts lists broadcasts newest first with how many students each went to
+func (s *BroadcastService) ListBroadcasts(ctx context.Context, page, limit int) (*models.BroadcastListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	rows, err := s.querier.ListNotificationBroadcasts(ctx, queries.ListNotificationBroadcastsParams{
+		Limit:  int32(limit),
+		Offset: int32((page - 1) * limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list broadcasts: %w", err)
+	}
+
+	total, err := s.querier.CountNotificationBroadcasts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count broadcasts: %w", err)
+	}
+
+	broadcasts := make([]models.Broadcast, len(rows))
+	for i, row := range rows {
+		broadcast := convertToBroadcast(queries.NotificationBroadcast{
+			ID:           row.ID,
+			Title:        row.Title,
+			Message:      row.Message,
+			Priority:     row.Priority,
+			Channels:     row.Channels,
+			Segment:      row.Segment,
+			ScheduledFor: row.ScheduledFor,
+			CreatedBy:    row.CreatedBy,
+			CreatedAt:    row.CreatedAt,
+		})
+		broadcast.Summary.Recipients = int(row.RecipientCount)
+		broadcasts[i] = *broadcast
+	}
+
+	totalPages := int(total) / limit
+	if int(total)%limit != 0 {
+		totalPages++
+	}
+
+	return &models.BroadcastListResponse{
+		Broadcasts: broadcasts,
+		Pagination: models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
+
+// validateBroadcast checks a broadcast request, trimming its text and filling
+// in the default priority and channel
+func validateBroadcast(req *models.CreateBroadcastRequest) error {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Title == "" || req.Message == "" {
+		return fmt.Errorf("%w: title and message are required", ErrInvalidBroadcast)
+	}
+
+	if req.Priority == "" {
+		req.Priority = models.NotificationPriorityMedium
+	}
+	if !req.Priority.IsValid() {
+		return fmt.Errorf("%w: unknown priority %q", ErrInvalidBroadcast, req.Priority)
+	}
+
+	if len(req.Channels) == 0 {
+		req.Channels = []models.NotificationChannel{models.NotificationChannelEmail}
+	}
+	for _, channel := range req.Channels {
+		if !channel.IsValid() {
+			return fmt.Errorf("%w: unknown channel %q", ErrInvalidBroadcast, channel)
+		}
+	}
+
+	req.Segment.Department = strings.TrimSpace(req.Segment.Department)
+	if req.Segment.YearOfStudy < 0 {
+		return fmt.Errorf("%w: year_of_study must be positive", ErrInvalidBroadcast)
+	}
+	return nil
+}
+
+// convertToBroadcast converts a broadcast row to its response without recipients
+func convertToBroadcast(row queries.NotificationBroadcast) *models.Broadcast {
+	broadcast := &models.Broadcast{
+		ID:        row.ID,
+		Title:     row.Title,
+		Message:   row.Message,
+		Priority:  models.NotificationPriority(row.Priority),
+		Channels:  make([]models.NotificationChannel, len(row.Channels)),
+		CreatedAt: row.CreatedAt.Time,
+	}
+	for i, channel := range row.Channels {
+		broadcast.Channels[i] = models.NotificationChannel(channel)
+	}
+	if len(row.Segment) > 0 {
+		// The segment was written by CreateBroadcast, so a bad one is left empty
+		_ = json.Unmarshal(row.Segment, &broadcast.Segment)
+	}
+	if row.ScheduledFor.Valid {
+		scheduledFor := row.ScheduledFor.Time
+		broadcast.ScheduledFor = &scheduledFor
+	}
+	if row.CreatedBy.Valid {
+		createdBy := row.CreatedBy.Int32
+		broadcast.CreatedBy = &createdBy
+	}
+	return broadcast
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockBroadcastQuerier is a mock implementation of BroadcastQuerier
+type MockBroadcastQuerier struct {
+	mock.Mock
+}
+
+func (m *MockBroadcastQuerier) ListBroadcastStudents(ctx context.Context, arg queries.ListBroadcastStudentsParams) ([]queries.ListBroadcastStudentsRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]queries.ListBroadcastStudentsRow), args.Error(1)
+}
+
+func (m *MockBroadcastQuerier) CreateNotificationBroadcast(ctx context.Context, arg queries.CreateNotificationBroadcastParams) (queries.NotificationBroadcast, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.NotificationBroadcast), args.Error(1)
+}
+
+func (m *MockBroadcastQuerier) GetNotificationBroadcast(ctx context.Context, id int32) (queries.NotificationBroadcast, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.NotificationBroadcast), args.Error(1)
+}
+
+func (m *MockBroadcastQuerier) ListNotificationBroadcasts(ctx context.Context, arg queries.ListNotificationBroadcastsParams) ([]queries.ListNotificationBroadcastsRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]queries.ListNotificationBroadcastsRow), args.Error(1)
+}
+
+func (m *MockBroadcastQuerier) CountNotificationBroadcasts(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBroadcastQuerier) CreateBroadcastRecipient(ctx context.Context, arg queries.CreateBroadcastRecipientParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockBroadcastQuerier) ListBroadcastRecipients(ctx context.Context, broadcastID int32) ([]queries.ListBroadcastRecipientsRow, error) {
+	args := m.Called(ctx, broadcastID)
+	return args.Get(0).([]queries.ListBroadcastRecipientsRow), args.Error(1)
+}
+
+// MockBatchNotifier is a mock implementation of BatchNotifier
+type MockBatchNotifier struct {
+	mock.Mock
+}
+
+func (m *MockBatchNotifier) CreateBatchNotifications(ctx context.Context, batch *models.NotificationBatch) ([]*models.NotificationResponse, error) {
+	args := m.Called(ctx, batch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.NotificationResponse), args.Error(1)
+}
+
+func TestBroadcastService_CreateBroadcast(t *testing.T) {
+	ctx := context.Background()
+	students := []queries.ListBroadcastStudentsRow{
+		{ID: 1, FirstName: "John", LastName: "Doe"},
+		{ID: 2, FirstName: "Jane", LastName: "Roe"},
+	}
+	request := func() *models.CreateBroadcastRequest {
+		return &models.CreateBroadcastRequest{
+			Title:   " Early closing on Friday ",
+			Message: "Dear {{.FirstName}}, the library closes at 1pm on Friday.",
+			Segment: models.BroadcastSegment{YearOfStudy: 2, Department: " Physics ", WithOverdueLoans: true},
+		}
+	}
+	broadcastRow := queries.NotificationBroadcast{
+		ID:        5,
+		Title:     "Early closing on Friday",
+		Message:   "Dear {{.FirstName}}, the library closes at 1pm on Friday.",
+		Priority:  "medium",
+		Channels:  []string{"email"},
+		Segment:   []byte(`{"year_of_study":2,"department":"Physics","with_overdue_loans":true}`),
+		CreatedBy: pgtype.Int4{Int32: 9, Valid: true},
+	}
+
+	t.Run("sends an announcement to each student in the segment", func(t *testing.T) {
+		querier := &MockBroadcastQuerier{}
+		notifier := &MockBatchNotifier{}
+		service := NewBroadcastService(querier, notifier)
+
+		querier.On("ListBroadcastStudents", ctx, queries.ListBroadcastStudentsParams{
+			YearOfStudy:      2,
+			Department:       "Physics",
+			WithOverdueLoans: true,
+		}).Return(students, nil)
+		querier.On("CreateNotificationBroadcast", ctx, mock.MatchedBy(func(arg queries.CreateNotificationBroadcastParams) bool {
+			return arg.Title == "Early closing on Friday" &&
+				arg.Priority == "medium" &&
+				assert.ObjectsAreEqual([]string{"email"}, arg.Channels) &&
+				string(arg.Segment) == `{"year_of_study":2,"department":"Physics","with_overdue_loans":true}` &&
+				arg.CreatedBy == pgtype.Int4{Int32: 9, Valid: true}
+		})).Return(broadcastRow, nil)
+		notifier.On("CreateBatchNotifications", ctx, mock.MatchedBy(func(batch *models.NotificationBatch) bool {
+			return batch.Type == models.NotificationTypeAnnouncement &&
+				len(batch.Recipients) == 2 &&
+				batch.Recipients[1].MessageData["FirstName"] == "Jane" &&
+				assert.ObjectsAreEqual([]models.NotificationChannel{models.NotificationChannelEmail}, batch.Channels)
+		})).Return([]*models.NotificationResponse{{ID: 31, RecipientID: 1}}, nil)
+		querier.On("CreateBroadcastRecipient", mock.Anything, queries.CreateBroadcastRecipientParams{
+			BroadcastID:    5,
+			StudentID:      1,
+			NotificationID: pgtype.Int4{Int32: 31, Valid: true},
+		}).Return(nil)
+		querier.On("CreateBroadcastRecipient", mock.Anything, queries.CreateBroadcastRecipientParams{
+			BroadcastID:  5,
+			StudentID:    2,
+			ErrorMessage: pgtype.Text{String: "notification could not be created", Valid: true},
+		}).Return(nil)
+		querier.On("GetNotificationBroadcast", mock.Anything, int32(5)).Return(broadcastRow, nil)
+		querier.On("ListBroadcastRecipients", mock.Anything, int32(5)).Return([]queries.ListBroadcastRecipientsRow{
+			{StudentID: 1, FirstName: "John", LastName: "Doe", NotificationID: pgtype.Int4{Int32: 31, Valid: true}, Status: "pending"},
+			{StudentID: 2, FirstName: "Jane", LastName: "Roe", Status: "failed", ErrorMessage: "notification could not be created"},
+		}, nil)
+
+		broadcast, err := service.CreateBroadcast(ctx, request(), 9)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.BroadcastSummary{Recipients: 2, Pending: 1, Failed: 1}, broadcast.Summary)
+		assert.Equal(t, "Physics", broadcast.Segment.Department)
+		require.Len(t, broadcast.Recipients, 2)
+		require.NotNil(t, broadcast.Recipients[0].NotificationID)
+		assert.Equal(t, int32(31), *broadcast.Recipients[0].NotificationID)
+		assert.Equal(t, models.NotificationStatusFailed, broadcast.Recipients[1].Status)
+		querier.AssertExpectations(t)
+		notifier.AssertExpectations(t)
+	})
+
+	t.Run("no students in the segment", func(t *testing.T) {
+		querier := &MockBroadcastQuerier{}
+		service := NewBroadcastService(querier, &MockBatchNotifier{})
+		querier.On("ListBroadcastStudents", ctx, mock.Anything).Return([]queries.ListBroadcastStudentsRow{}, nil)
+
+		_, err := service.CreateBroadcast(ctx, request(), 9)
+
+		assert.ErrorIs(t, err, ErrNoBroadcastRecipients)
+		querier.AssertNotCalled(t, "CreateNotificationBroadcast", mock.Anything, mock.Anything)
+	})
+
+	t.Run("every notification fails", func(t *testing.T) {
+		querier := &MockBroadcastQuerier{}
+		notifier := &MockBatchNotifier{}
+		service := NewBroadcastService(querier, notifier)
+		querier.On("ListBroadcastStudents", ctx, mock.Anything).Return(students, nil)
+		querier.On("CreateNotificationBroadcast", ctx, mock.Anything).Return(broadcastRow, nil)
+		notifier.On("CreateBatchNotifications", ctx, mock.Anything).Return(nil, fmt.Errorf("all notifications in batch failed"))
+		querier.On("CreateBroadcastRecipient", mock.Anything, mock.MatchedBy(func(arg queries.CreateBroadcastRecipientParams) bool {
+			return !arg.NotificationID.Valid && arg.ErrorMessage.Valid
+		})).Return(nil).Twice()
+
+		_, err := service.CreateBroadcast(ctx, request(), 9)
+
+		assert.Error(t, err)
+		querier.AssertExpectations(t)
+	})
+
+	invalid := []struct {
+		name string
+		edit func(*models.CreateBroadcastRequest)
+	}{
+		{name: "blank title", edit: func(req *models.CreateBroadcastRequest) { req.Title = "  " }},
+		{name: "unknown priority", edit: func(req *models.CreateBroadcastRequest) { req.Priority = "critical" }},
+		{name: "unknown channel", edit: func(req *models.CreateBroadcastRequest) {
+			req.Channels = []models.NotificationChannel{"pigeon"}
+		}},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			querier := &MockBroadcastQuerier{}
+			service := NewBroadcastService(querier, &MockBatchNotifier{})
+			req := request()
+			tc.edit(req)
+
+			_, err := service.CreateBroadcast(ctx, req, 9)
+
+			assert.ErrorIs(t, err, ErrInvalidBroadcast)
+			querier.AssertNotCalled(t, "ListBroadcastStudents", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestBroadcastService_GetBroadcast(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not found", func(t *testing.T) {
+		querier := &MockBroadcastQuerier{}
+		service := NewBroadcastService(querier, &MockBatchNotifier{})
+		querier.On("GetNotificationBroadcast", ctx, int32(5)).Return(queries.NotificationBroadcast{}, pgx.ErrNoRows)
+
+		_, err := service.GetBroadcast(ctx, 5)
+
+		assert.ErrorIs(t, err, ErrBroadcastNotFound)
+	})
+}
+
+func TestBroadcastService_ListBroadcasts(t *testing.T) {
+	ctx := context.Background()
+	querier := &MockBroadcastQuerier{}
+	service := NewBroadcastService(querier, &MockBatchNotifier{})
+	querier.On("ListNotificationBroadcasts", ctx, queries.ListNotificationBroadcastsParams{Limit: 20, Offset: 20}).Return([]queries.ListNotificationBroadcastsRow{
+		{ID: 5, Title: "Early closing on Friday", Priority: "medium", Channels: []string{"email", "sms"}, RecipientCount: 120},
+	}, nil)
+	querier.On("CountNotificationBroadcasts", ctx).Return(int64(21), nil)
+
+	list, err := service.ListBroadcasts(ctx, 2, 20)
+
+	require.NoError(t, err)
+	require.Len(t, list.Broadcasts, 1)
+	assert.Equal(t, 120, list.Broadcasts[0].Summary.Recipients)
+	assert.Equal(t, []models.NotificationChannel{models.NotificationChannelEmail, models.NotificationChannelSMS}, list.Broadcasts[0].Channels)
+	assert.Equal(t, 2, list.Pagination.TotalPages)
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// DigestTemplateName is the email template that digests are sent with
+const DigestTemplateName = "notification_digest"
+
+// digestPeriods names each digest mode in the digest email, by locale
+var digestPeriods = map[string]map[models.DigestMode]string{
+	"en": {
+		models.DigestModeDaily:  "daily",
+		models.DigestModeWeekly: "weekly",
+	},
+	"sw": {
+		models.DigestModeDaily:  "kila siku",
+		models.DigestModeWeekly: "kila wiki",
+	},
+}
+
+// SendDailyDigests sends the daily digest to every recipient with notifications
+// waiting for one
+func (s *NotificationService) SendDailyDigests(ctx context.Context) error {
+	return s.SendDigests(ctx, models.DigestModeDaily)
+}
+
+// SendWeeklyDigests sends the weekly digest to every recipient with notifications
+// waiting for one
+func (s *NotificationService) SendWeeklyDigests(ctx context.Context) error {
+	return s.SendDigests(ctx, models.DigestModeWeekly)
+}
+
+// SendDigests rolls each recipient's held notifications into one digest email.
+// Recipients who have since turned digests off are included so nothing they
+// were holding is lost.
+func (s *NotificationService) SendDigests(ctx context.Context, mode models.DigestMode) error {
+	if s.preferences == nil {
+		return nil
+	}
+	s.logger.Info("Starting digest process", "mode", mode)
+
+	recipients, err := s.preferences.DigestRecipients(ctx, mode)
+	if err != nil {
+		s.logger.Error("Failed to get digest recipients", "mode", mode, "error", err)
+		return err
+	}
+
+	var successCount, failureCount int
+	for _, recipient := range recipients {
+		if err := s.sendDigest(ctx, recipient, mode); err != nil {
+			s.logger.Warn("Failed to send digest",
+				"recipient_id", recipient.RecipientID,
+				"recipient_type", recipient.RecipientType,
+				"error", err)
+			failureCount++
+			continue
+		}
+		successCount++
+	}
+
+	s.logger.Info("Digest process completed",
+		"mode", mode,
+		"total_recipients", len(recipients),
+		"successful_digests", successCount,
+		"failed_digests", failureCount)
+
+	return nil
+}
+
+// sendDigest emails one recipient their held notifications and marks them sent
+func (s *NotificationService) sendDigest(ctx context.Context, recipient *models.NotificationPreferences, mode models.DigestMode) error {
+	notifications, err := s.querier.ListPendingDigestNotifications(ctx, queries.ListPendingDigestNotificationsParams{
+		RecipientID:   recipient.RecipientID,
+		RecipientType: string(recipient.RecipientType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get digest notifications: %w", err)
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	recipientEmail, err := s.getRecipientEmail(ctx, recipient.RecipientID, recipient.RecipientType)
+	if err != nil {
+		return fmt.Errorf("failed to get recipient email: %w", err)
+	}
+
+	locale := NormalizeLocale(s.getRecipientLocale(ctx, recipient.RecipientID, recipient.RecipientType))
+	template := s.resolveTemplate(ctx, DigestTemplateName, locale)
+	if template == nil {
+		return fmt.Errorf("no template for %s", DigestTemplateName)
+	}
+
+	period := digestPeriods[locale][mode]
+	if period == "" {
+		period = digestPeriods["en"][mode]
+	}
+	data := map[string]interface{}{
+		"DigestPeriod": period,
+		"ItemCount":    len(notifications),
+		"DigestItems":  digestItems(notifications),
+	}
+
+	if err := s.emailService.SendTemplatedEmail(ctx, recipientEmail, template, data); err != nil {
+		return fmt.Errorf("failed to send digest email: %w", err)
+	}
+
+	// The digest is out, so record it even if ctx ends now or it would be sent again
+	recordCtx := context.WithoutCancel(ctx)
+	for _, notification := range notifications {
+		if err := s.MarkAsSent(recordCtx, notification.ID); err != nil {
+			s.logger.Error("Failed to mark digest notification as sent", "notification_id", notification.ID, "error", err)
+		}
+	}
+	if err := s.preferences.RecordDigestSent(recordCtx, recipient.RecipientID, recipient.RecipientType); err != nil {
+		s.logger.Error("Failed to record digest", "recipient_id", recipient.RecipientID, "error", err)
+	}
+
+	s.logger.Info("Digest sent",
+		"recipient_id", recipient.RecipientID,
+		"recipient_type", recipient.RecipientType,
+		"notifications", len(notifications))
+	return nil
+}
+
+// digestItems lists the notifications in a digest by their titles, which are
+// already in the recipient's language
+func digestItems(notifications []queries.Notification) string {
+	items := make([]string, len(notifications))
+	for i, notification := range notifications {
+		items[i] = "- " + notification.Title
+	}
+	return strings.Join(items, "\n")
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockPreferenceResolver is a mock implementation of PreferenceResolver
+type MockPreferenceResolver struct {
+	mock.Mock
+}
+
+func (m *MockPreferenceResolver) PlanDelivery(ctx context.Context, req *models.NotificationRequest, now time.Time) (*DeliveryPlan, error) {
+	args := m.Called(ctx, req, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*DeliveryPlan), args.Error(1)
+}
+
+func (m *MockPreferenceResolver) DigestRecipients(ctx context.Context, mode models.DigestMode) ([]*models.NotificationPreferences, error) {
+	args := m.Called(ctx, mode)
+	return args.Get(0).([]*models.NotificationPreferences), args.Error(1)
+}
+
+func (m *MockPreferenceResolver) RecordDigestSent(ctx context.Context, recipientID int32, recipientType models.RecipientType) error {
+	args := m.Called(ctx, recipientID, recipientType)
+	return args.Error(0)
+}
+
+func TestNotificationService_SendDigests(t *testing.T) {
+	ctx := context.Background()
+	recipient := &models.NotificationPreferences{RecipientID: 1, RecipientType: models.RecipientTypeStudent, DigestMode: models.DigestModeDaily}
+	digestParams := queries.ListPendingDigestNotificationsParams{RecipientID: 1, RecipientType: "student"}
+	held := []queries.Notification{
+		{ID: 21, RecipientID: 1, RecipientType: "student", Type: "due_soon", Title: "Kitabu Kinakaribia Muda - Dune", Digest: true},
+		{ID: 22, RecipientID: 1, RecipientType: "student", Type: "due_soon", Title: "Kitabu Kinakaribia Muda - Emma", Digest: true},
+	}
+
+	setup := func() (*NotificationService, *MockStudentNotificationQuerier, *MockEmailService, *MockPreferenceResolver) {
+		service, mockQuerier, mockEmailService, _ := createTestNotificationService()
+		querier := &MockStudentNotificationQuerier{mockQuerier}
+		service.querier = querier
+		preferences := &MockPreferenceResolver{}
+		service.WithPreferences(preferences)
+		return service, querier, mockEmailService, preferences
+	}
+
+	t.Run("rolls held notifications into one email", func(t *testing.T) {
+		service, querier, mockEmailService, preferences := setup()
+		preferences.On("DigestRecipients", ctx, models.DigestModeDaily).Return([]*models.NotificationPreferences{recipient}, nil)
+		querier.On("ListPendingDigestNotifications", ctx, digestParams).Return(held, nil)
+		querier.On("GetStudentByID", ctx, int32(1)).Return(queries.Student{
+			ID:     1,
+			Email:  pgtype.Text{String: "student@example.com", Valid: true},
+			Locale: "sw",
+		}, nil)
+		mockEmailService.On("SendTemplatedEmail", ctx, "student@example.com",
+			mock.MatchedBy(func(template *models.EmailTemplate) bool {
+				return template.Name == DigestTemplateName && template.Locale == "sw"
+			}),
+			map[string]interface{}{
+				"DigestPeriod": "kila siku",
+				"ItemCount":    2,
+				"DigestItems":  "- Kitabu Kinakaribia Muda - Dune\n- Kitabu Kinakaribia Muda - Emma",
+			}).Return(nil).Once()
+		querier.On("MarkNotificationAsSent", mock.Anything, int32(21)).Return(nil)
+		querier.On("MarkNotificationAsSent", mock.Anything, int32(22)).Return(nil)
+		preferences.On("RecordDigestSent", mock.Anything, int32(1), models.RecipientTypeStudent).Return(nil)
+
+		err := service.SendDailyDigests(ctx)
+
+		require.NoError(t, err)
+		querier.AssertExpectations(t)
+		mockEmailService.AssertExpectations(t)
+		preferences.AssertExpectations(t)
+	})
+
+	t.Run("failed email leaves notifications held", func(t *testing.T) {
+		service, querier, mockEmailService, preferences := setup()
+		preferences.On("DigestRecipients", ctx, models.DigestModeWeekly).Return([]*models.NotificationPreferences{recipient}, nil)
+		querier.On("ListPendingDigestNotifications", ctx, digestParams).Return(held, nil)
+		querier.On("GetStudentByID", ctx, int32(1)).Return(queries.Student{ID: 1, Email: pgtype.Text{String: "student@example.com", Valid: true}}, nil)
+		mockEmailService.On("SendTemplatedEmail", ctx, "student@example.com", mock.Anything, mock.Anything).Return(fmt.Errorf("smtp unavailable"))
+
+		err := service.SendWeeklyDigests(ctx)
+
+		require.NoError(t, err)
+		querier.AssertNotCalled(t, "MarkNotificationAsSent", mock.Anything, mock.Anything)
+		preferences.AssertNotCalled(t, "RecordDigestSent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("recipient lookup failure", func(t *testing.T) {
+		service, _, _, preferences := setup()
+		preferences.On("DigestRecipients", ctx, models.DigestModeDaily).Return([]*models.NotificationPreferences(nil), fmt.Errorf("connection refused"))
+
+		err := service.SendDailyDigests(ctx)
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,426 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+var (
+	ErrInvalidPreferences        = errors.New("invalid notification preferences")
+	ErrMandatoryNotificationType = errors.New("mandatory notifications cannot be turned off")
+	ErrInvalidUnsubscribeToken   = errors.New("invalid unsubscribe link")
+)
+
+// NotificationPreferenceQuerier defines the database operations behind notification preferences
+type NotificationPreferenceQuerier interface {
+	GetNotificationPreferences(ctx context.Context, arg queries.GetNotificationPreferencesParams) (queries.NotificationPreference, error)
+	UpsertNotificationPreferences(ctx context.Context, arg queries.UpsertNotificationPreferencesParams) (queries.NotificationPreference, error)
+	ListDigestRecipients(ctx context.Context, digestMode string) ([]queries.NotificationPreference, error)
+	MarkDigestSent(ctx context.Context, arg queries.MarkDigestSentParams) error
+}
+
+// NotificationPreferenceServiceInterface defines the self-service preference operations
+type NotificationPreferenceServiceInterface interface {
+	GetPreferences(ctx context.Context, recipientID int32, recipientType models.RecipientType) (*models.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, recipientID int32, recipientType models.RecipientType, req *models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error)
+	Unsubscribe(ctx context.Context, token string) error
+}
+
+// PreferenceResolver applies recipients' preferences to their notifications
+type PreferenceResolver interface {
+	PlanDelivery(ctx context.Context, req *models.NotificationRequest, now time.Time) (*DeliveryPlan, error)
+	DigestRecipients(ctx context.Context, mode models.DigestMode) ([]*models.NotificationPreferences, error)
+	RecordDigestSent(ctx context.Context, recipientID int32, recipientType models.RecipientType) error
+}
+
+// DeliveryPlan is how a new notification goes out under its recipient's preferences
+type DeliveryPlan struct {
+	// Channels are the notification's channels the recipient has kept on. When
+	// empty the notification is only shown in the app.
+	Channels []models.NotificationChannel
+	// Digest holds the notification back for the recipient's next digest email
+	Digest bool
+	// SendAt delays delivery until the recipient's quiet hours end; nil sends now
+	SendAt *time.Time
+}
+
+// NotificationPreferenceService manages how each student and librarian receives
+// notifications. Recipients without saved preferences get every channel, no quiet
+// hours and no digest. Mandatory notices ignore the channel and digest settings.
+type NotificationPreferenceService struct {
+	querier           NotificationPreferenceQuerier
+	location          *time.Location
+	unsubscribeURL    string
+	unsubscribeSecret []byte
+}
+
+// NewNotificationPreferenceService creates a new notification preference service.
+// Quiet hours are read in the library's time zone, location.
+func NewNotificationPreferenceService(querier NotificationPreferenceQuerier, location *time.Location) *NotificationPreferenceService {
+	if location == nil {
+		location = time.Local
+	}
+	return &NotificationPreferenceService{
+		querier:  querier,
+		location: location,
+	}
+}
+
+// WithUnsubscribeLinks makes one-click unsubscribe links to baseURL, the
+// unsubscribe endpoint as reached from outside, signed with secret
+func (s *NotificationPreferenceService) WithUnsubscribeLinks(baseURL, secret string) *NotificationPreferenceService {
+	s.unsubscribeURL = baseURL
+	s.unsubscribeSecret = []byte(secret)
+	return s
+}
+
+// GetPreferences returns a recipient's notification preferences, or the defaults
+// if they have not saved any
+func (s *NotificationPreferenceService) GetPreferences(ctx context.Context, recipientID int32, recipientType models.RecipientType) (*models.NotificationPreferences, error) {
+	preference, err := s.querier.GetNotificationPreferences(ctx, queries.GetNotificationPreferencesParams{
+		RecipientID:   recipientID,
+		RecipientType: string(recipientType),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return defaultNotificationPreferences(recipientID, recipientType), nil
+		}
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return convertToNotificationPreferences(preference)
+}
+
+// UpdatePreferences replaces a recipient's notification preferences
+func (s *NotificationPreferenceService) UpdatePreferences(ctx context.Context, recipientID int32, recipientType models.RecipientType, req *models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error) {
+	if !recipientType.IsValid() {
+		return nil, fmt.Errorf("%w: unsupported recipient type %q", ErrInvalidPreferences, recipientType)
+	}
+	if err := validatePreferences(req); err != nil {
+		return nil, err
+	}
+
+	channels := req.Channels
+	if channels == nil {
+		channels = map[models.NotificationType][]models.NotificationChannel{}
+	}
+	encoded, err := json.Marshal(channels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode channel preferences: %w", err)
+	}
+
+	params := queries.UpsertNotificationPreferencesParams{
+		RecipientID:   recipientID,
+		RecipientType: string(recipientType),
+		Channels:      encoded,
+		DigestMode:    string(req.DigestMode),
+	}
+	if req.QuietHours != nil {
+		params.QuietHoursStart = parseCalendarTime(req.QuietHours.Start)
+		params.QuietHoursEnd = parseCalendarTime(req.QuietHours.End)
+	}
+
+	preference, err := s.querier.UpsertNotificationPreferences(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return convertToNotificationPreferences(preference)
+}
+
+// PlanDelivery works out how a new notification goes out under its recipient's
+// preferences. Channels the recipient turned off for its type are dropped, and
+// routine (low and medium priority) email notifications are held for the digest
+// of a recipient who has one. Anything still to be sent during quiet hours waits
+// for them to end, unless it is urgent.
+func (s *NotificationPreferenceService) PlanDelivery(ctx context.Context, req *models.NotificationRequest, now time.Time) (*DeliveryPlan, error) {
+	preferences, err := s.GetPreferences(ctx, req.RecipientID, req.RecipientType)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := req.Channels
+	if len(channels) == 0 {
+		channels = []models.NotificationChannel{models.NotificationChannelEmail}
+	}
+	plan := &DeliveryPlan{Channels: channels}
+
+	if !req.Type.IsMandatory() {
+		if enabled, ok := preferences.Channels[req.Type]; ok {
+			plan.Channels = keepChannels(channels, enabled)
+		}
+		if preferences.DigestMode != models.DigestModeOff && isDigestPriority(req.Priority) &&
+			hasChannel(plan.Channels, models.NotificationChannelEmail) {
+			plan.Digest = true
+			return plan, nil
+		}
+	}
+
+	if len(plan.Channels) > 0 && req.Priority != models.NotificationPriorityUrgent && preferences.QuietHours != nil {
+		if end, quiet := s.quietHoursEnd(preferences.QuietHours, now); quiet {
+			plan.SendAt = &end
+		}
+	}
+	return plan, nil
+}
+
+// DigestRecipients returns the recipients with notifications waiting for a digest
+// of the given mode, including any who have since turned digests off
+func (s *NotificationPreferenceService) DigestRecipients(ctx context.Context, mode models.DigestMode) ([]*models.NotificationPreferences, error) {
+	rows, err := s.querier.ListDigestRecipients(ctx, string(mode))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list digest recipients: %w", err)
+	}
+
+	recipients := make([]*models.NotificationPreferences, 0, len(rows))
+	for _, row := range rows {
+		preferences, err := convertToNotificationPreferences(row)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, preferences)
+	}
+	return recipients, nil
+}
+
+// RecordDigestSent notes that a recipient has just been sent a digest
+func (s *NotificationPreferenceService) RecordDigestSent(ctx context.Context, recipientID int32, recipientType models.RecipientType) error {
+	err := s.querier.MarkDigestSent(ctx, queries.MarkDigestSentParams{
+		RecipientID:   recipientID,
+		RecipientType: string(recipientType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record digest: %w", err)
+	}
+	return nil
+}
+
+// UnsubscribeURL returns the link that turns off email of a notification type for
+// a recipient, or "" when unsubscribe links are not set up. Mandatory notices
+// cannot be unsubscribed from.
+func (s *NotificationPreferenceService) UnsubscribeURL(recipientID int32, recipientType models.RecipientType, notificationType models.NotificationType) string {
+	if s.unsubscribeURL == "" || len(s.unsubscribeSecret) == 0 || notificationType.IsMandatory() {
+		return ""
+	}
+
+	link, err := url.Parse(s.unsubscribeURL)
+	if err != nil {
+		return ""
+	}
+	query := link.Query()
+	query.Set("token", s.unsubscribeToken(fmt.Sprintf("%s:%d:%s", recipientType, recipientID, notificationType)))
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// Unsubscribe turns off email of the notification type an unsubscribe link was
+// made for. The recipient's other channels for the type are left as they were.
+func (s *NotificationPreferenceService) Unsubscribe(ctx context.Context, token string) error {
+	recipientID, recipientType, notificationType, err := s.parseUnsubscribeToken(token)
+	if err != nil {
+		return err
+	}
+
+	preferences, err := s.GetPreferences(ctx, recipientID, recipientType)
+	if err != nil {
+		return err
+	}
+
+	channels, ok := preferences.Channels[notificationType]
+	if !ok {
+		channels = models.NotificationChannels
+	}
+	kept := []models.NotificationChannel{}
+	for _, channel := range channels {
+		if channel != models.NotificationChannelEmail {
+			kept = append(kept, channel)
+		}
+	}
+	preferences.Channels[notificationType] = kept
+
+	_, err = s.UpdatePreferences(ctx, recipientID, recipientType, &models.UpdateNotificationPreferencesRequest{
+		Channels:   preferences.Channels,
+		QuietHours: preferences.QuietHours,
+		DigestMode: preferences.DigestMode,
+	})
+	return err
+}
+
+// unsubscribeToken signs payload into a token of the payload and its signature
+func (s *NotificationPreferenceService) unsubscribeToken(payload string) string {
+	mac := hmac.New(sha256.New, s.unsubscribeSecret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseUnsubscribeToken checks an unsubscribe token's signature and returns the
+// recipient and notification type it was made for
+func (s *NotificationPreferenceService) parseUnsubscribeToken(token string) (int32, models.RecipientType, models.NotificationType, error) {
+	if len(s.unsubscribeSecret) == 0 {
+		return 0, "", "", ErrInvalidUnsubscribeToken
+	}
+
+	encoded, _, found := strings.Cut(token, ".")
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if !found || err != nil || !hmac.Equal([]byte(s.unsubscribeToken(string(payload))), []byte(token)) {
+		return 0, "", "", ErrInvalidUnsubscribeToken
+	}
+
+	parts := strings.Split(string(payload), ":")
+	if len(parts) != 3 {
+		return 0, "", "", ErrInvalidUnsubscribeToken
+	}
+	recipientType := models.RecipientType(parts[0])
+	recipientID, err := strconv.ParseInt(parts[1], 10, 32)
+	notificationType := models.NotificationType(parts[2])
+	if err != nil || !recipientType.IsValid() || !notificationType.IsValid() || notificationType.IsMandatory() {
+		return 0, "", "", ErrInvalidUnsubscribeToken
+	}
+	return int32(recipientID), recipientType, notificationType, nil
+}
+
+// quietHoursEnd reports whether now falls within the quiet hours and, if so, when
+// they end
+func (s *NotificationPreferenceService) quietHoursEnd(quietHours *models.QuietHours, now time.Time) (time.Time, bool) {
+	start, err := time.Parse(models.CalendarTimeLayout, quietHours.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := time.Parse(models.CalendarTimeLayout, quietHours.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(s.location)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	var quiet bool
+	if startMinute < endMinute {
+		quiet = minute >= startMinute && minute < endMinute
+	} else {
+		// The window spans midnight
+		quiet = minute >= startMinute || minute < endMinute
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, s.location)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+// isDigestPriority reports whether notifications of a priority are routine
+// enough to wait for a digest
+func isDigestPriority(priority models.NotificationPriority) bool {
+	return priority == models.NotificationPriorityLow || priority == models.NotificationPriorityMedium
+}
+
+func hasChannel(channels []models.NotificationChannel, channel models.NotificationChannel) bool {
+	for _, c := range channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// keepChannels returns the channels that are also in enabled, in their order
+func keepChannels(channels, enabled []models.NotificationChannel) []models.NotificationChannel {
+	kept := []models.NotificationChannel{}
+	for _, channel := range channels {
+		for _, on := range enabled {
+			if channel == on {
+				kept = append(kept, channel)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+func validatePreferences(req *models.UpdateNotificationPreferencesRequest) error {
+	if !req.DigestMode.IsValid() {
+		return fmt.Errorf("%w: unknown digest mode %q", ErrInvalidPreferences, req.DigestMode)
+	}
+
+	for notificationType, channels := range req.Channels {
+		if !notificationType.IsValid() {
+			return fmt.Errorf("%w: unknown notification type %q", ErrInvalidPreferences, notificationType)
+		}
+		if len(channels) == 0 && notificationType.IsMandatory() {
+			return fmt.Errorf("%w: %s", ErrMandatoryNotificationType, notificationType)
+		}
+		for _, channel := range channels {
+			if !channel.IsValid() {
+				return fmt.Errorf("%w: unknown channel %q", ErrInvalidPreferences, channel)
+			}
+		}
+	}
+
+	if req.QuietHours != nil {
+		start, err := time.Parse(models.CalendarTimeLayout, req.QuietHours.Start)
+		if err != nil {
+			return fmt.Errorf("%w: quiet hours start must be HH:MM", ErrInvalidPreferences)
+		}
+		end, err := time.Parse(models.CalendarTimeLayout, req.QuietHours.End)
+		if err != nil {
+			return fmt.Errorf("%w: quiet hours end must be HH:MM", ErrInvalidPreferences)
+		}
+		if start.Equal(end) {
+			return fmt.Errorf("%w: quiet hours must start and end at different times", ErrInvalidPreferences)
+		}
+	}
+	return nil
+}
+
+func defaultNotificationPreferences(recipientID int32, recipientType models.RecipientType) *models.NotificationPreferences {
+	return &models.NotificationPreferences{
+		RecipientID:    recipientID,
+		RecipientType:  recipientType,
+		Channels:       map[models.NotificationType][]models.NotificationChannel{},
+		DigestMode:     models.DigestModeOff,
+		MandatoryTypes: models.MandatoryNotificationTypes,
+	}
+}
+
+func convertToNotificationPreferences(preference queries.NotificationPreference) (*models.NotificationPreferences, error) {
+	preferences := defaultNotificationPreferences(preference.RecipientID, models.RecipientType(preference.RecipientType))
+	preferences.DigestMode = models.DigestMode(preference.DigestMode)
+
+	if len(preference.Channels) > 0 {
+		if err := json.Unmarshal(preference.Channels, &preferences.Channels); err != nil {
+			return nil, fmt.Errorf("failed to decode channel preferences: %w", err)
+		}
+	}
+	if preference.QuietHoursStart.Valid && preference.QuietHoursEnd.Valid {
+		preferences.QuietHours = &models.QuietHours{
+			Start: formatCalendarTime(preference.QuietHoursStart),
+			End:   formatCalendarTime(preference.QuietHoursEnd),
+		}
+	}
+	if preference.LastDigestAt.Valid {
+		preferences.LastDigestAt = &preference.LastDigestAt.Time
+	}
+	if preference.UpdatedAt.Valid {
+		preferences.UpdatedAt = &preference.UpdatedAt.Time
+	}
+	return preferences, nil
+}
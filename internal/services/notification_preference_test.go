@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockNotificationPreferenceQuerier is a mock implementation of NotificationPreferenceQuerier
+type MockNotificationPreferenceQuerier struct {
+	mock.Mock
+}
+
+func (m *MockNotificationPreferenceQuerier) GetNotificationPreferences(ctx context.Context, arg queries.GetNotificationPreferencesParams) (queries.NotificationPreference, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.NotificationPreference), args.Error(1)
+}
+
+func (m *MockNotificationPreferenceQuerier) UpsertNotificationPreferences(ctx context.Context, arg queries.UpsertNotificationPreferencesParams) (queries.NotificationPreference, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.NotificationPreference), args.Error(1)
+}
+
+func (m *MockNotificationPreferenceQuerier) ListDigestRecipients(ctx context.Context, digestMode string) ([]queries.NotificationPreference, error) {
+	args := m.Called(ctx, digestMode)
+	return args.Get(0).([]queries.NotificationPreference), args.Error(1)
+}
+
+func (m *MockNotificationPreferenceQuerier) MarkDigestSent(ctx context.Context, arg queries.MarkDigestSentParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+// nairobi is a fixed East Africa Time zone, so tests do not need the tz database
+var nairobi = time.FixedZone("EAT", 3*60*60)
+
+func newTestPreferenceService() (*NotificationPreferenceService, *MockNotificationPreferenceQuerier) {
+	querier := &MockNotificationPreferenceQuerier{}
+	return NewNotificationPreferenceService(querier, nairobi), querier
+}
+
+// storedPreferences is a student's saved preferences: due soon reminders by SMS
+// only, quiet from 22:00 to 07:00 and a daily digest
+func storedPreferences() queries.NotificationPreference {
+	return queries.NotificationPreference{
+		ID:              1,
+		RecipientID:     1,
+		RecipientType:   "student",
+		Channels:        []byte(`{"due_soon":["sms"],"book_available":[]}`),
+		QuietHoursStart: parseCalendarTime("22:00"),
+		QuietHoursEnd:   parseCalendarTime("07:00"),
+		DigestMode:      "daily",
+	}
+}
+
+func TestNotificationPreferenceService_GetPreferences(t *testing.T) {
+	ctx := context.Background()
+	params := queries.GetNotificationPreferencesParams{RecipientID: 1, RecipientType: "student"}
+
+	t.Run("defaults when none saved", func(t *testing.T) {
+		service, querier := newTestPreferenceService()
+		querier.On("GetNotificationPreferences", ctx, params).Return(queries.NotificationPreference{}, pgx.ErrNoRows)
+
+		preferences, err := service.GetPreferences(ctx, 1, models.RecipientTypeStudent)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.DigestModeOff, preferences.DigestMode)
+		assert.Empty(t, preferences.Channels)
+		assert.Nil(t, preferences.QuietHours)
+		assert.Equal(t, models.MandatoryNotificationTypes, preferences.MandatoryTypes)
+	})
+
+	t.Run("decodes saved preferences", func(t *testing.T) {
+		service, querier := newTestPreferenceService()
+		querier.On("GetNotificationPreferences", ctx, params).Return(storedPreferences(), nil)
+
+		preferences, err := service.GetPreferences(ctx, 1, models.RecipientTypeStudent)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.DigestModeDaily, preferences.DigestMode)
+		assert.Equal(t, []models.NotificationChannel{models.NotificationChannelSMS}, preferences.Channels[models.NotificationTypeDueSoon])
+		assert.Equal(t, &models.QuietHours{Start: "22:00", End: "07:00"}, preferences.QuietHours)
+	})
+}
+
+func TestNotificationPreferenceService_UpdatePreferences(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("saves preferences", func(t *testing.T) {
+		service, querier := newTestPreferenceService()
+		querier.On("UpsertNotificationPreferences", ctx, mock.MatchedBy(func(params queries.UpsertNotificationPreferencesParams) bool {
+			return params.RecipientID == 1 &&
+				params.RecipientType == "student" &&
+				string(params.Channels) == `{"due_soon":["sms"]}` &&
+				params.QuietHoursStart == parseCalendarTime("22:00") &&
+				params.QuietHoursEnd == parseCalendarTime("07:00") &&
+				params.DigestMode == "daily"
+		})).Return(storedPreferences(), nil)
+
+		preferences, err := service.UpdatePreferences(ctx, 1, models.RecipientTypeStudent, &models.UpdateNotificationPreferencesRequest{
+			Channels: map[models.NotificationType][]models.NotificationChannel{
+				models.NotificationTypeDueSoon: {models.NotificationChannelSMS},
+			},
+			QuietHours: &models.QuietHours{Start: "22:00", End: "07:00"},
+			DigestMode: models.DigestModeDaily,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.DigestModeDaily, preferences.DigestMode)
+		querier.AssertExpectations(t)
+	})
+
+	invalid := []struct {
+		name    string
+		req     models.UpdateNotificationPreferencesRequest
+		wantErr error
+	}{
+		{
+			name: "fine notices cannot be turned off",
+			req: models.UpdateNotificationPreferencesRequest{
+				Channels:   map[models.NotificationType][]models.NotificationChannel{models.NotificationTypeFineNotice: {}},
+				DigestMode: models.DigestModeOff,
+			},
+			wantErr: ErrMandatoryNotificationType,
+		},
+		{
+			name:    "unknown digest mode",
+			req:     models.UpdateNotificationPreferencesRequest{DigestMode: "hourly"},
+			wantErr: ErrInvalidPreferences,
+		},
+		{
+			name: "unknown channel",
+			req: models.UpdateNotificationPreferencesRequest{
+				Channels:   map[models.NotificationType][]models.NotificationChannel{models.NotificationTypeDueSoon: {"pigeon"}},
+				DigestMode: models.DigestModeOff,
+			},
+			wantErr: ErrInvalidPreferences,
+		},
+		{
+			name: "quiet hours that start and end together",
+			req: models.UpdateNotificationPreferencesRequest{
+				QuietHours: &models.QuietHours{Start: "22:00", End: "22:00"},
+				DigestMode: models.DigestModeOff,
+			},
+			wantErr: ErrInvalidPreferences,
+		},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			service, querier := newTestPreferenceService()
+
+			_, err := service.UpdatePreferences(ctx, 1, models.RecipientTypeStudent, &tc.req)
+
+			assert.ErrorIs(t, err, tc.wantErr)
+			querier.AssertNotCalled(t, "UpsertNotificationPreferences", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestNotificationPreferenceService_PlanDelivery(t *testing.T) {
+	ctx := context.Background()
+	// 23:30 in Nairobi, inside the stored quiet hours
+	lateEvening := time.Date(2025, 3, 4, 23, 30, 0, 0, nairobi)
+	afternoon := time.Date(2025, 3, 4, 15, 0, 0, 0, nairobi)
+	morningAfter := time.Date(2025, 3, 5, 7, 0, 0, 0, nairobi)
+
+	request := func(notificationType models.NotificationType, priority models.NotificationPriority, channels ...models.NotificationChannel) *models.NotificationRequest {
+		return &models.NotificationRequest{
+			RecipientID:   1,
+			RecipientType: models.RecipientTypeStudent,
+			Type:          notificationType,
+			Priority:      priority,
+			Channels:      channels,
+		}
+	}
+	withPreferences := func(preference queries.NotificationPreference) *NotificationPreferenceService {
+		service, querier := newTestPreferenceService()
+		querier.On("GetNotificationPreferences", ctx, mock.Anything).Return(preference, nil)
+		return service
+	}
+
+	t.Run("routine email is held for the digest", func(t *testing.T) {
+		service := withPreferences(storedPreferences())
+
+		plan, err := service.PlanDelivery(ctx, request(models.NotificationTypeOverdueReminder, models.NotificationPriorityMedium), afternoon)
+
+		require.NoError(t, err)
+		assert.True(t, plan.Digest)
+		assert.Equal(t, []models.NotificationChannel{models.NotificationChannelEmail}, plan.Channels)
+	})
+
+	t.Run("drops channels turned off for the type", func(t *testing.T) {
+		service := withPreferences(storedPreferences())
+
+		plan, err := service.PlanDelivery(ctx, request(models.NotificationTypeDueSoon, models.NotificationPriorityMedium, emailAndSMS...), afternoon)
+
+		require.NoError(t, err)
+		// Only SMS is left, which a digest email cannot carry
+		assert.Equal(t, []models.NotificationChannel{models.NotificationChannelSMS}, plan.Channels)
+		assert.False(t, plan.Digest)
+		assert.Nil(t, plan.SendAt)
+	})
+
+	t.Run("type turned off is only shown in the app", func(t *testing.T) {
+		service := withPreferences(storedPreferences())
+
+		plan, err := service.PlanDelivery(ctx, request(models.NotificationTypeBookAvailable, models.NotificationPriorityHigh), lateEvening)
+
+		require.NoError(t, err)
+		assert.Empty(t, plan.Channels)
+		assert.False(t, plan.Digest)
+		assert.Nil(t, plan.SendAt)
+	})
+
+	t.Run("mandatory notice ignores channels and digest", func(t *testing.T) {
+		preference := storedPreferences()
+		preference.Channels = []byte(`{"fine_notice":[]}`)
+		service := withPreferences(preference)
+
+		plan, err := service.PlanDelivery(ctx, request(models.NotificationTypeFineNotice, models.NotificationPriorityMedium), afternoon)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.NotificationChannel{models.NotificationChannelEmail}, plan.Channels)
+		assert.False(t, plan.Digest)
+	})
+
+	t.Run("quiet hours spanning midnight hold until morning", func(t *testing.T) {
+		preference := storedPreferences()
+		preference.DigestMode = "off"
+		service := withPreferences(preference)
+
+		plan, err := service.PlanDelivery(ctx, request(models.NotificationTypeOverdueReminder, models.NotificationPriorityHigh), lateEvening)
+
+		require.NoError(t, err)
+		require.NotNil(t, plan.SendAt)
+		assert.True(t, morningAfter.Equal(*plan.SendAt))
+
+		plan, err = service.PlanDelivery(ctx, request(models.NotificationTypeOverdueReminder, models.NotificationPriorityHigh), morningAfter.Add(-2*time.Hour))
+
+		require.NoError(t, err)
+		require.NotNil(t, plan.SendAt)
+		assert.True(t, morningAfter.Equal(*plan.SendAt))
+	})
+
+	t.Run("urgent notifications ignore quiet hours", func(t *testing.T) {
+		service := withPreferences(storedPreferences())
+
+		plan, err := service.PlanDelivery(ctx, request(models.NotificationTypeRecallNotice, models.NotificationPriorityUrgent), lateEvening)
+
+		require.NoError(t, err)
+		assert.Nil(t, plan.SendAt)
+		assert.False(t, plan.Digest)
+	})
+
+	t.Run("lookup failure", func(t *testing.T) {
+		service, querier := newTestPreferenceService()
+		querier.On("GetNotificationPreferences", ctx, mock.Anything).Return(queries.NotificationPreference{}, fmt.Errorf("connection refused"))
+
+		_, err := service.PlanDelivery(ctx, request(models.NotificationTypeDueSoon, models.NotificationPriorityMedium), afternoon)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestNotificationPreferenceService_Unsubscribe(t *testing.T) {
+	ctx := context.Background()
+	newService := func() (*NotificationPreferenceService, *MockNotificationPreferenceQuerier) {
+		service, querier := newTestPreferenceService()
+		service.WithUnsubscribeLinks("https://library.example.com/api/v1/notifications/unsubscribe", "unsubscribe-secret")
+		return service, querier
+	}
+	tokenOf := func(t *testing.T, link string) string {
+		parsed, err := url.Parse(link)
+		require.NoError(t, err)
+		return parsed.Query().Get("token")
+	}
+
+	t.Run("turns off email for the type and keeps other settings", func(t *testing.T) {
+		service, querier := newService()
+		link := service.UnsubscribeURL(1, models.RecipientTypeStudent, models.NotificationTypeOverdueReminder)
+		require.True(t, strings.HasPrefix(link, "https://library.example.com/api/v1/notifications/unsubscribe?token="))
+
+		querier.On("GetNotificationPreferences", ctx, queries.GetNotificationPreferencesParams{RecipientID: 1, RecipientType: "student"}).
+			Return(storedPreferences(), nil)
+		querier.On("UpsertNotificationPreferences", ctx, mock.MatchedBy(func(params queries.UpsertNotificationPreferencesParams) bool {
+			return params.RecipientID == 1 &&
+				string(params.Channels) == `{"book_available":[],"due_soon":["sms"],"overdue_reminder":["sms"]}` &&
+				params.QuietHoursStart == parseCalendarTime("22:00") &&
+				params.DigestMode == "daily"
+		})).Return(storedPreferences(), nil)
+
+		err := service.Unsubscribe(ctx, tokenOf(t, link))
+
+		require.NoError(t, err)
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("no link for mandatory notices", func(t *testing.T) {
+		service, _ := newService()
+
+		assert.Empty(t, service.UnsubscribeURL(1, models.RecipientTypeStudent, models.NotificationTypeFineNotice))
+	})
+
+	t.Run("no link when not set up", func(t *testing.T) {
+		service, _ := newTestPreferenceService()
+
+		assert.Empty(t, service.UnsubscribeURL(1, models.RecipientTypeStudent, models.NotificationTypeDueSoon))
+	})
+
+	t.Run("rejects a tampered token", func(t *testing.T) {
+		service, querier := newService()
+		token := tokenOf(t, service.UnsubscribeURL(1, models.RecipientTypeStudent, models.NotificationTypeDueSoon))
+		_, signature, _ := strings.Cut(token, ".")
+		forged := base64.RawURLEncoding.EncodeToString([]byte("student:2:due_soon")) + "." + signature
+
+		for _, bad := range []string{"", "garbage", forged} {
+			assert.ErrorIs(t, service.Unsubscribe(ctx, bad), ErrInvalidUnsubscribeToken)
+		}
+		querier.AssertNotCalled(t, "UpsertNotificationPreferences", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a token signed with another secret", func(t *testing.T) {
+		service, querier := newService()
+		other, _ := newTestPreferenceService()
+		other.WithUnsubscribeLinks("https://library.example.com/api/v1/notifications/unsubscribe", "another-secret")
+
+		err := service.Unsubscribe(ctx, tokenOf(t, other.UnsubscribeURL(1, models.RecipientTypeStudent, models.NotificationTypeDueSoon)))
+
+		assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+		querier.AssertNotCalled(t, "GetNotificationPreferences", mock.Anything, mock.Anything)
+	})
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"math/big"
@@ -42,6 +43,16 @@ func (m *MockNotificationQuerier) MarkNotificationAsSent(ctx context.Context, id
 	return args.Error(0)
 }
 
+func (m *MockNotificationQuerier) ListDeliveredNotificationChannels(ctx context.Context, notificationID int32) ([]string, error) {
+	args := m.Called(ctx, notificationID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockNotificationQuerier) RecordNotificationChannelDelivered(ctx context.Context, arg queries.RecordNotificationChannelDeliveredParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
 func (m *MockNotificationQuerier) ListNotifications(ctx context.Context, arg queries.ListNotificationsParams) ([]queries.Notification, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).([]queries.Notification), args.Error(1)
@@ -67,6 +78,11 @@ func (m *MockNotificationQuerier) ListUnsentNotifications(ctx context.Context, l
 	return args.Get(0).([]queries.Notification), args.Error(1)
 }
 
+func (m *MockNotificationQuerier) ListPendingDigestNotifications(ctx context.Context, arg queries.ListPendingDigestNotificationsParams) ([]queries.Notification, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]queries.Notification), args.Error(1)
+}
+
 func (m *MockNotificationQuerier) CountUnreadNotificationsByRecipient(ctx context.Context, arg queries.CountUnreadNotificationsByRecipientParams) (int64, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(int64), args.Error(1)
@@ -118,6 +134,11 @@ func (m *MockEmailService) SendEmail(ctx context.Context, to, subject, body stri
 	return args.Error(0)
 }
 
+func (m *MockEmailService) SendMessage(ctx context.Context, message *EmailMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
 func (m *MockEmailService) SendTemplatedEmail(ctx context.Context, to string, template *models.EmailTemplate, data map[string]interface{}) error {
 	args := m.Called(ctx, to, template, data)
 	return args.Error(0)
@@ -242,6 +263,8 @@ func TestNotificationService_CreateNotification(t *testing.T) {
 			Type:          string(req.Type),
 			Title:         req.Title,
 			Message:       req.Message,
+			Locale:        models.DefaultLocale,
+			Channels:      []string{"email"},
 		}
 
 		mockQuerier.On("CreateNotification", ctx, expectedParams).Return(dbNotification, nil)
@@ -290,6 +313,8 @@ func TestNotificationService_CreateNotification(t *testing.T) {
 			Type:          string(req.Type),
 			Title:         req.Title,
 			Message:       req.Message,
+			Locale:        models.DefaultLocale,
+			Channels:      []string{"email"},
 		}
 
 		mockQuerier.On("CreateNotification", ctx, expectedParams).Return(queries.Notification{}, fmt.Errorf("database error"))
@@ -314,6 +339,8 @@ func TestNotificationService_CreateNotification(t *testing.T) {
 			Type:          string(req.Type),
 			Title:         req.Title,
 			Message:       req.Message,
+			Locale:        models.DefaultLocale,
+			Channels:      []string{"email"},
 		}
 
 		mockQuerier.On("CreateNotification", ctx, expectedParams).Return(dbNotification, nil)
@@ -342,6 +369,8 @@ func TestNotificationService_CreateNotification(t *testing.T) {
 			Type:          string(req.Type),
 			Title:         req.Title,
 			Message:       req.Message,
+			Locale:        models.DefaultLocale,
+			Channels:      []string{"email"},
 		}
 
 		mockQuerier.On("CreateNotification", ctx, expectedParams).Return(dbNotification, nil)
@@ -399,6 +428,8 @@ func TestNotificationService_CreateBatchNotifications(t *testing.T) {
 				Type:          string(batch.Type),
 				Title:         batch.Title,
 				Message:       expectedMessage,
+				Locale:        models.DefaultLocale,
+				Channels:      []string{"email"},
 			}
 
 			mockQuerier.On("CreateNotification", ctx, expectedParams).Return(dbNotification, nil)
@@ -520,6 +551,22 @@ func TestNotificationService_MarkAsRead(t *testing.T) {
 		mockQuerier.AssertExpectations(t)
 	})
 
+	t.Run("publishes read receipt", func(t *testing.T) {
+		service, mockQuerier, _, _ := createTestNotificationService()
+		publisher := &MockRealtimePublisher{}
+		service.WithRealtime(publisher)
+		notification := queries.Notification{ID: 1, RecipientID: 2, RecipientType: "student"}
+
+		mockQuerier.On("MarkNotificationAsRead", ctx, notification.ID).Return(nil)
+		mockQuerier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		publisher.On("NotificationRead", ctx, notification).Return()
+
+		err := service.MarkAsRead(ctx, notification.ID)
+
+		assert.NoError(t, err)
+		publisher.AssertExpectations(t)
+	})
+
 	t.Run("database error", func(t *testing.T) {
 		service, mockQuerier, _, _ := createTestNotificationService()
 		notificationID := int32(1)
@@ -744,6 +791,11 @@ func TestNotificationService_GetNotificationStats(t *testing.T) {
 		mockQuerier.On("CountNotificationsByType", ctx, "due_soon").Return(int64(5), nil)
 		mockQuerier.On("CountNotificationsByType", ctx, "book_available").Return(int64(3), nil)
 		mockQuerier.On("CountNotificationsByType", ctx, "fine_notice").Return(int64(2), nil)
+		mockQuerier.On("CountNotificationsByType", ctx, "recall_notice").Return(int64(0), nil)
+		mockQuerier.On("CountNotificationsByType", ctx, "reservation_ready").Return(int64(0), nil)
+		mockQuerier.On("CountNotificationsByType", ctx, "reservation_expired").Return(int64(0), nil)
+		mockQuerier.On("CountNotificationsByType", ctx, "account_blocked").Return(int64(0), nil)
+		mockQuerier.On("CountNotificationsByType", ctx, "announcement").Return(int64(0), nil)
 
 		stats, err := service.GetNotificationStats(ctx, nil)
 
@@ -764,6 +816,11 @@ func TestNotificationService_GetNotificationStats(t *testing.T) {
 		mockQuerier.On("CountNotificationsByType", ctx, "due_soon").Return(int64(0), fmt.Errorf("database error"))
 		mockQuerier.On("CountNotificationsByType", ctx, "book_available").Return(int64(3), nil)
 		mockQuerier.On("CountNotificationsByType", ctx, "fine_notice").Return(int64(2), nil)
+		mockQuerier.On("CountNotificationsByType", ctx, "recall_notice").Return(int64(0), nil)
+		mockQuerier.On("CountNotificationsByType", ctx, "reservation_ready").Return(int64(0), nil)
+		mockQuerier.On("CountNotificationsByType", ctx, "reservation_expired").Return(int64(0), nil)
+		mockQuerier.On("CountNotificationsByType", ctx, "account_blocked").Return(int64(0), nil)
+		mockQuerier.On("CountNotificationsByType", ctx, "announcement").Return(int64(0), nil)
 
 		stats, err := service.GetNotificationStats(ctx, nil)
 
@@ -1097,6 +1154,52 @@ func TestNotificationService_SendOverdueReminders(t *testing.T) {
 		assert.Contains(t, err.Error(), "failed to get overdue transactions")
 		mockQuerier.AssertExpectations(t)
 	})
+
+	t.Run("written in the student's locale", func(t *testing.T) {
+		service, mockQuerier, _, mockQueueService := createTestNotificationService()
+
+		dueDate := time.Date(2025, time.March, 5, 12, 0, 0, 0, time.UTC)
+		overdueTransactions := []queries.ListTransactionsOverdueRow{
+			{
+				ID:          1,
+				StudentID:   1,
+				BookID:      1,
+				DueDate:     pgtype.Timestamp{Time: dueDate, Valid: true},
+				FineAmount:  createNumeric("125000"),
+				FirstName:   "Amina",
+				LastName:    "Wanjiku",
+				StudentID_2: "STU001",
+				Locale:      "sw",
+				Title:       "Kidagaa Kimemwozea",
+				Author:      "Ken Walibora",
+				BookID_2:    "BOOK001",
+			},
+		}
+
+		mockQuerier.On("ListTransactionsOverdue", ctx).Return(overdueTransactions, nil)
+
+		var created queries.CreateNotificationParams
+		dbNotification := createSampleDBNotification()
+		mockQuerier.On("CreateNotification", ctx, mock.AnythingOfType("queries.CreateNotificationParams")).
+			Run(func(args mock.Arguments) {
+				created = args.Get(1).(queries.CreateNotificationParams)
+			}).
+			Return(dbNotification, nil)
+		mockQueueService.On("QueueNotification", ctx, dbNotification.ID).Return(nil)
+
+		err := service.SendOverdueReminders(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, "sw", created.Locale)
+		assert.Equal(t, "Kitabu Kimechelewa - Kidagaa Kimemwozea", created.Title)
+		assert.Contains(t, created.Message, "Mpendwa Amina Wanjiku")
+		assert.Contains(t, created.Message, "Tarehe ya Kurudisha: 5 Machi 2025")
+		assert.Contains(t, created.Message, "Kiasi cha Faini: KSh 1,250.00")
+
+		var data map[string]interface{}
+		require.NoError(t, json.Unmarshal(created.TemplateData, &data))
+		assert.Equal(t, "KSh 1,250.00", data["FineAmount"])
+	})
 }
 
 func TestNotificationService_SendBookAvailableNotifications(t *testing.T) {
@@ -1260,3 +1363,640 @@ func TestNotificationService_SendFineNotices(t *testing.T) {
 		mockQuerier.AssertExpectations(t)
 	})
 }
+
+// MockEmailQueuer is a mock implementation of EmailQueuer
+type MockEmailQueuer struct {
+	mock.Mock
+}
+
+func (m *MockEmailQueuer) QueueEmail(ctx context.Context, req *models.EmailQueueRequest) (*models.EmailQueueItem, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmailQueueItem), args.Error(1)
+}
+
+// MockDeliveryRecorder is a mock implementation of DeliveryRecorder
+type MockDeliveryRecorder struct {
+	mock.Mock
+}
+
+func (m *MockDeliveryRecorder) CreateDelivery(ctx context.Context, req *models.EmailDeliveryRequest) (*models.EmailDelivery, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmailDelivery), args.Error(1)
+}
+
+func (m *MockDeliveryRecorder) UpdateDeliveryStatus(ctx context.Context, id int32, status models.EmailDeliveryStatus) (*models.EmailDelivery, error) {
+	args := m.Called(ctx, id, status)
+	return &models.EmailDelivery{ID: id, Status: status}, args.Error(0)
+}
+
+func (m *MockDeliveryRecorder) UpdateDeliveryError(ctx context.Context, id int32, errorMsg string) (*models.EmailDelivery, error) {
+	args := m.Called(ctx, id, errorMsg)
+	return &models.EmailDelivery{ID: id, Status: models.EmailDeliveryStatusFailed}, args.Error(0)
+}
+
+func (m *MockDeliveryRecorder) UpdateProviderInfo(ctx context.Context, id int32, messageID string, metadata map[string]interface{}) (*models.EmailDelivery, error) {
+	args := m.Called(ctx, id, messageID, metadata)
+	return &models.EmailDelivery{ID: id, ProviderMessageID: &messageID}, args.Error(0)
+}
+
+// MockSuppressionChecker is a mock implementation of EmailSuppressionChecker
+type MockSuppressionChecker struct {
+	mock.Mock
+}
+
+func (m *MockSuppressionChecker) IsSuppressed(ctx context.Context, email string) (bool, error) {
+	args := m.Called(ctx, email)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockTemplateResolver is a mock implementation of TemplateResolver
+type MockTemplateResolver struct {
+	mock.Mock
+}
+
+func (m *MockTemplateResolver) ResolveTemplate(ctx context.Context, name, locale string) (*models.EmailTemplate, error) {
+	args := m.Called(ctx, name, locale)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmailTemplate), args.Error(1)
+}
+
+// MockStudentNotifier is a mock implementation of StudentNotifier
+type MockStudentNotifier struct {
+	mock.Mock
+}
+
+func (m *MockStudentNotifier) CreateNotification(ctx context.Context, req *models.NotificationRequest) (*models.NotificationResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationResponse), args.Error(1)
+}
+
+// MockStudentNotificationQuerier also looks up students, so recipient emails resolve
+type MockStudentNotificationQuerier struct {
+	*MockNotificationQuerier
+}
+
+func (m *MockStudentNotificationQuerier) GetStudentByID(ctx context.Context, id int32) (queries.Student, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Student), args.Error(1)
+}
+
+func TestNotificationService_CreateNotification_EmailQueue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queues email instead of redis notification", func(t *testing.T) {
+		service, mockQuerier, _, mockQueueService := createTestNotificationService()
+		emailQueue := &MockEmailQueuer{}
+		service.WithEmailQueue(emailQueue).WithEmailMaxAttempts(4)
+
+		req := createSampleNotificationRequest()
+		req.Priority = models.NotificationPriorityUrgent
+		dbNotification := createSampleDBNotification()
+
+		mockQuerier.On("CreateNotification", ctx, mock.Anything).Return(dbNotification, nil)
+		emailQueue.On("QueueEmail", ctx, &models.EmailQueueRequest{
+			NotificationID: dbNotification.ID,
+			Priority:       1,
+			MaxAttempts:    4,
+		}).Return(&models.EmailQueueItem{ID: 7}, nil)
+
+		response, err := service.CreateNotification(ctx, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, dbNotification.ID, response.ID)
+		emailQueue.AssertExpectations(t)
+		mockQueueService.AssertNotCalled(t, "QueueNotification", mock.Anything, mock.Anything)
+	})
+
+	t.Run("scheduled notification waits in the email queue", func(t *testing.T) {
+		service, mockQuerier, _, _ := createTestNotificationService()
+		emailQueue := &MockEmailQueuer{}
+		service.WithEmailQueue(emailQueue)
+
+		scheduledFor := time.Now().Add(2 * time.Hour)
+		req := createSampleNotificationRequest()
+		req.ScheduledFor = &scheduledFor
+
+		mockQuerier.On("CreateNotification", ctx, mock.Anything).Return(createSampleDBNotification(), nil)
+		emailQueue.On("QueueEmail", ctx, mock.MatchedBy(func(queueReq *models.EmailQueueRequest) bool {
+			return queueReq.ScheduledFor != nil && queueReq.ScheduledFor.Equal(scheduledFor) && queueReq.Priority == 5
+		})).Return(&models.EmailQueueItem{ID: 7}, nil)
+
+		_, err := service.CreateNotification(ctx, req)
+
+		require.NoError(t, err)
+		emailQueue.AssertExpectations(t)
+	})
+
+	t.Run("queue failure does not fail creation", func(t *testing.T) {
+		service, mockQuerier, _, _ := createTestNotificationService()
+		emailQueue := &MockEmailQueuer{}
+		service.WithEmailQueue(emailQueue)
+
+		mockQuerier.On("CreateNotification", ctx, mock.Anything).Return(createSampleDBNotification(), nil)
+		emailQueue.On("QueueEmail", ctx, mock.Anything).Return(nil, fmt.Errorf("database error"))
+
+		response, err := service.CreateNotification(ctx, createSampleNotificationRequest())
+
+		require.NoError(t, err)
+		assert.NotNil(t, response)
+	})
+}
+
+func TestNotificationService_CreateNotification_Preferences(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(plan *DeliveryPlan, planErr error) (*NotificationService, *MockNotificationQuerier, *MockEmailQueuer) {
+		service, mockQuerier, _, _ := createTestNotificationService()
+		emailQueue := &MockEmailQueuer{}
+		preferences := &MockPreferenceResolver{}
+		preferences.On("PlanDelivery", ctx, mock.Anything, mock.Anything).Return(plan, planErr)
+		service.WithEmailQueue(emailQueue).WithPreferences(preferences)
+		return service, mockQuerier, emailQueue
+	}
+
+	t.Run("digest notification is held back", func(t *testing.T) {
+		service, mockQuerier, emailQueue := setup(&DeliveryPlan{Channels: []models.NotificationChannel{models.NotificationChannelEmail}, Digest: true}, nil)
+		mockQuerier.On("CreateNotification", ctx, mock.MatchedBy(func(params queries.CreateNotificationParams) bool {
+			return params.Digest
+		})).Return(createSampleDBNotification(), nil)
+
+		_, err := service.CreateNotification(ctx, createSampleNotificationRequest())
+
+		require.NoError(t, err)
+		mockQuerier.AssertExpectations(t)
+		emailQueue.AssertNotCalled(t, "QueueEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("every channel off leaves it in the app", func(t *testing.T) {
+		service, mockQuerier, emailQueue := setup(&DeliveryPlan{Channels: []models.NotificationChannel{}}, nil)
+		dbNotification := createSampleDBNotification()
+		mockQuerier.On("CreateNotification", ctx, mock.MatchedBy(func(params queries.CreateNotificationParams) bool {
+			return len(params.Channels) == 0 && params.Channels != nil && !params.Digest
+		})).Return(dbNotification, nil)
+		mockQuerier.On("MarkNotificationAsSent", ctx, dbNotification.ID).Return(nil)
+
+		_, err := service.CreateNotification(ctx, createSampleNotificationRequest())
+
+		require.NoError(t, err)
+		mockQuerier.AssertExpectations(t)
+		emailQueue.AssertNotCalled(t, "QueueEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("quiet hours delay the email", func(t *testing.T) {
+		sendAt := time.Now().Add(6 * time.Hour)
+		service, mockQuerier, emailQueue := setup(&DeliveryPlan{Channels: []models.NotificationChannel{models.NotificationChannelEmail}, SendAt: &sendAt}, nil)
+		mockQuerier.On("CreateNotification", ctx, mock.Anything).Return(createSampleDBNotification(), nil)
+		emailQueue.On("QueueEmail", ctx, mock.MatchedBy(func(queueReq *models.EmailQueueRequest) bool {
+			return queueReq.ScheduledFor != nil && queueReq.ScheduledFor.Equal(sendAt)
+		})).Return(&models.EmailQueueItem{ID: 7}, nil)
+
+		req := createSampleNotificationRequest()
+		_, err := service.CreateNotification(ctx, req)
+
+		require.NoError(t, err)
+		emailQueue.AssertExpectations(t)
+		assert.Nil(t, req.ScheduledFor)
+	})
+
+	t.Run("preference failure sends as usual", func(t *testing.T) {
+		service, mockQuerier, emailQueue := setup(nil, fmt.Errorf("connection refused"))
+		mockQuerier.On("CreateNotification", ctx, mock.MatchedBy(func(params queries.CreateNotificationParams) bool {
+			return assert.ObjectsAreEqual([]string{"email"}, params.Channels) && !params.Digest
+		})).Return(createSampleDBNotification(), nil)
+		emailQueue.On("QueueEmail", ctx, mock.Anything).Return(&models.EmailQueueItem{ID: 7}, nil)
+
+		_, err := service.CreateNotification(ctx, createSampleNotificationRequest())
+
+		require.NoError(t, err)
+		mockQuerier.AssertExpectations(t)
+		emailQueue.AssertExpectations(t)
+	})
+}
+
+// expectChannelDeliveries sets up the channels a notification was delivered on
+// by earlier attempts and records the channels it gets through on now
+func expectChannelDeliveries(querier *MockStudentNotificationQuerier, notificationID int32, delivered ...string) {
+	querier.On("ListDeliveredNotificationChannels", mock.Anything, notificationID).Return(append([]string{}, delivered...), nil)
+	querier.On("RecordNotificationChannelDelivered", mock.Anything, mock.MatchedBy(func(arg queries.RecordNotificationChannelDeliveredParams) bool {
+		return arg.NotificationID == notificationID
+	})).Return(nil).Maybe()
+}
+
+func TestNotificationService_DeliverEmail(t *testing.T) {
+	ctx := context.Background()
+	student := queries.Student{ID: 1, Email: pgtype.Text{String: "student@example.com", Valid: true}}
+
+	setup := func() (*NotificationService, *MockStudentNotificationQuerier, *MockEmailService, *MockDeliveryRecorder) {
+		service, mockQuerier, mockEmailService, _ := createTestNotificationService()
+		querier := &MockStudentNotificationQuerier{mockQuerier}
+		service.querier = querier
+		deliveries := &MockDeliveryRecorder{}
+		service.WithDeliveryRecorder(deliveries)
+		return service, querier, mockEmailService, deliveries
+	}
+
+	t.Run("sends and records delivery", func(t *testing.T) {
+		service, querier, mockEmailService, deliveries := setup()
+		notification := createSampleDBNotification()
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+		deliveries.On("CreateDelivery", ctx, &models.EmailDeliveryRequest{
+			NotificationID: notification.ID,
+			EmailAddress:   "student@example.com",
+			Status:         models.EmailDeliveryStatusPending,
+			RetryCount:     1,
+			MaxRetries:     2,
+		}).Return(&models.EmailDelivery{ID: 11}, nil)
+		mockEmailService.On("SendTemplatedEmail", mock.Anything, "student@example.com", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				tracking := args.Get(0).(context.Context).Value(emailTrackingKey{}).(*EmailTracking)
+				assert.Equal(t, int32(11), tracking.DeliveryID)
+				tracking.MessageID = "1.abc@library.example.com"
+			}).
+			Return(nil)
+		deliveries.On("UpdateDeliveryStatus", mock.Anything, int32(11), models.EmailDeliveryStatusSent).Return(nil)
+		deliveries.On("UpdateProviderInfo", mock.Anything, int32(11), "1.abc@library.example.com", map[string]interface{}(nil)).Return(nil)
+		querier.On("MarkNotificationAsSent", mock.Anything, notification.ID).Return(nil)
+
+		err := service.DeliverEmail(ctx, notification.ID, 2, 3)
+
+		require.NoError(t, err)
+		querier.AssertExpectations(t)
+		deliveries.AssertExpectations(t)
+		mockEmailService.AssertExpectations(t)
+	})
+
+	t.Run("send failure is recorded and returned", func(t *testing.T) {
+		service, querier, mockEmailService, deliveries := setup()
+		notification := createSampleDBNotification()
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+		deliveries.On("CreateDelivery", ctx, mock.Anything).Return(&models.EmailDelivery{ID: 11}, nil)
+		mockEmailService.On("SendTemplatedEmail", mock.Anything, "student@example.com", mock.Anything, mock.Anything).Return(fmt.Errorf("connection refused"))
+		deliveries.On("UpdateDeliveryError", mock.Anything, int32(11), "failed to send email: connection refused").Return(nil)
+
+		err := service.DeliverEmail(ctx, notification.ID, 1, 3)
+
+		assert.Error(t, err)
+		deliveries.AssertExpectations(t)
+		querier.AssertNotCalled(t, "MarkNotificationAsSent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("channel already delivered is skipped", func(t *testing.T) {
+		service, querier, mockEmailService, deliveries := setup()
+		notification := createSampleDBNotification()
+		notification.SentAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID, "email")
+
+		err := service.DeliverEmail(ctx, notification.ID, 2, 3)
+
+		require.NoError(t, err)
+		mockEmailService.AssertNotCalled(t, "SendTemplatedEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		deliveries.AssertNotCalled(t, "CreateDelivery", mock.Anything, mock.Anything)
+	})
+
+	t.Run("recipient without email fails", func(t *testing.T) {
+		service, querier, _, deliveries := setup()
+		notification := createSampleDBNotification()
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(queries.Student{ID: 1}, nil)
+
+		err := service.DeliverEmail(ctx, notification.ID, 1, 3)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no email address")
+		deliveries.AssertNotCalled(t, "CreateDelivery", mock.Anything, mock.Anything)
+	})
+
+	t.Run("suppressed address is not emailed or retried", func(t *testing.T) {
+		service, querier, mockEmailService, deliveries := setup()
+		suppressions := &MockSuppressionChecker{}
+		service.WithSuppressions(suppressions)
+		notification := createSampleDBNotification()
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+		suppressions.On("IsSuppressed", ctx, "student@example.com").Return(true, nil)
+
+		err := service.DeliverEmail(ctx, notification.ID, 1, 3)
+
+		require.NoError(t, err)
+		deliveries.AssertNotCalled(t, "CreateDelivery", mock.Anything, mock.Anything)
+		mockEmailService.AssertNotCalled(t, "SendTemplatedEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		querier.AssertNotCalled(t, "MarkNotificationAsSent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("only notices that can be turned off carry an unsubscribe link", func(t *testing.T) {
+		for notificationType, wantLink := range map[string]bool{"overdue_reminder": true, "fine_notice": false} {
+			service, querier, mockEmailService, deliveries := setup()
+			service.WithUnsubscribeLinks(NewNotificationPreferenceService(nil, nairobi).
+				WithUnsubscribeLinks("https://library.example.com/api/v1/notifications/unsubscribe", "unsubscribe-secret"))
+			notification := createSampleDBNotification()
+			notification.Type = notificationType
+
+			querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+			expectChannelDeliveries(querier, notification.ID)
+			querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+			deliveries.On("CreateDelivery", ctx, mock.Anything).Return(&models.EmailDelivery{ID: 11}, nil)
+			mockEmailService.On("SendTemplatedEmail", mock.Anything, "student@example.com", mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					link, _ := args.Get(0).(context.Context).Value(listUnsubscribeKey{}).(string)
+					if wantLink {
+						assert.Contains(t, link, "/notifications/unsubscribe?token=", notificationType)
+					} else {
+						assert.Empty(t, link, notificationType)
+					}
+				}).
+				Return(nil)
+			deliveries.On("UpdateDeliveryStatus", mock.Anything, int32(11), models.EmailDeliveryStatusSent).Return(nil)
+			querier.On("MarkNotificationAsSent", mock.Anything, notification.ID).Return(nil)
+
+			err := service.DeliverEmail(ctx, notification.ID, 1, 3)
+
+			require.NoError(t, err)
+			mockEmailService.AssertExpectations(t)
+		}
+	})
+
+	t.Run("uses the template published in the store", func(t *testing.T) {
+		service, querier, mockEmailService, deliveries := setup()
+		templates := &MockTemplateResolver{}
+		service.WithTemplateStore(templates)
+		notification := createSampleDBNotification()
+		stored := &models.EmailTemplate{ID: 4, Name: "overdue_reminder", Subject: "Please return {{.BookTitle}}", IsActive: true}
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+		templates.On("ResolveTemplate", mock.Anything, "overdue_reminder", "en").Return(stored, nil)
+		deliveries.On("CreateDelivery", ctx, mock.Anything).Return(&models.EmailDelivery{ID: 11}, nil)
+		mockEmailService.On("SendTemplatedEmail", mock.Anything, "student@example.com", stored, mock.Anything).Return(nil)
+		deliveries.On("UpdateDeliveryStatus", mock.Anything, int32(11), models.EmailDeliveryStatusSent).Return(nil)
+		querier.On("MarkNotificationAsSent", mock.Anything, notification.ID).Return(nil)
+
+		err := service.DeliverEmail(ctx, notification.ID, 1, 3)
+
+		require.NoError(t, err)
+		mockEmailService.AssertExpectations(t)
+	})
+
+	t.Run("falls back to the built-in template", func(t *testing.T) {
+		service, querier, mockEmailService, deliveries := setup()
+		templates := &MockTemplateResolver{}
+		service.WithTemplateStore(templates)
+		notification := createSampleDBNotification()
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+		templates.On("ResolveTemplate", mock.Anything, "overdue_reminder", "en").Return(nil, fmt.Errorf("connection refused"))
+		deliveries.On("CreateDelivery", ctx, mock.Anything).Return(&models.EmailDelivery{ID: 11}, nil)
+		mockEmailService.On("SendTemplatedEmail", mock.Anything, "student@example.com", GetDefaultTemplate("overdue_reminder"), mock.Anything).Return(nil)
+		deliveries.On("UpdateDeliveryStatus", mock.Anything, int32(11), models.EmailDeliveryStatusSent).Return(nil)
+		querier.On("MarkNotificationAsSent", mock.Anything, notification.ID).Return(nil)
+
+		err := service.DeliverEmail(ctx, notification.ID, 1, 3)
+
+		require.NoError(t, err)
+		mockEmailService.AssertExpectations(t)
+	})
+}
+
+// MockSMSSender is a mock implementation of SMSSender
+type MockSMSSender struct {
+	mock.Mock
+}
+
+func (m *MockSMSSender) SendNotificationSMS(ctx context.Context, notificationID int32, phone, message string) error {
+	args := m.Called(ctx, notificationID, phone, message)
+	return args.Error(0)
+}
+
+func TestNotificationService_SMSChannel(t *testing.T) {
+	ctx := context.Background()
+	student := queries.Student{
+		ID:    1,
+		Email: pgtype.Text{String: "student@example.com", Valid: true},
+		Phone: pgtype.Text{String: "0712345678", Valid: true},
+	}
+
+	setup := func() (*NotificationService, *MockStudentNotificationQuerier, *MockEmailService, *MockSMSSender) {
+		service, mockQuerier, mockEmailService, _ := createTestNotificationService()
+		querier := &MockStudentNotificationQuerier{mockQuerier}
+		service.querier = querier
+		sms := &MockSMSSender{}
+		service.WithSMS(sms)
+		return service, querier, mockEmailService, sms
+	}
+
+	bookAvailable := func(channels ...string) queries.Notification {
+		notification := createSampleDBNotification()
+		notification.Type = "book_available"
+		notification.Channels = channels
+		notification.TemplateData = []byte(`{"BookTitle":"Dune","ExpirationDays":3}`)
+		return notification
+	}
+
+	t.Run("sends email and SMS", func(t *testing.T) {
+		service, querier, mockEmailService, sms := setup()
+		notification := bookAvailable("email", "sms")
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+		mockEmailService.On("SendTemplatedEmail", mock.Anything, "student@example.com", mock.Anything, mock.Anything).Return(nil)
+		sms.On("SendNotificationSMS", ctx, notification.ID, "0712345678",
+			`Library: "Dune" that you reserved is ready. Please collect it within 3 day(s).`).Return(nil)
+		querier.On("MarkNotificationAsSent", mock.Anything, notification.ID).Return(nil)
+
+		err := service.DeliverEmail(ctx, notification.ID, 1, 3)
+
+		require.NoError(t, err)
+		mockEmailService.AssertExpectations(t)
+		sms.AssertExpectations(t)
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("SMS failure does not hold back a sent email", func(t *testing.T) {
+		service, querier, mockEmailService, sms := setup()
+		notification := bookAvailable("email", "sms")
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+		mockEmailService.On("SendTemplatedEmail", mock.Anything, "student@example.com", mock.Anything, mock.Anything).Return(nil)
+		sms.On("SendNotificationSMS", ctx, notification.ID, mock.Anything, mock.Anything).Return(fmt.Errorf("insufficient balance"))
+		querier.On("MarkNotificationAsSent", mock.Anything, notification.ID).Return(nil)
+
+		err := service.DeliverEmail(ctx, notification.ID, 1, 3)
+
+		require.Error(t, err, "the SMS is tried again")
+		assert.Contains(t, err.Error(), "insufficient balance")
+		querier.AssertCalled(t, "RecordNotificationChannelDelivered", mock.Anything, queries.RecordNotificationChannelDeliveredParams{
+			NotificationID: notification.ID,
+			Channel:        "email",
+		})
+		querier.AssertNotCalled(t, "RecordNotificationChannelDelivered", mock.Anything, queries.RecordNotificationChannelDeliveredParams{
+			NotificationID: notification.ID,
+			Channel:        "sms",
+		})
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("retry only goes out on the failed channel", func(t *testing.T) {
+		service, querier, mockEmailService, sms := setup()
+		notification := bookAvailable("email", "sms")
+		notification.SentAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID, "email")
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+		sms.On("SendNotificationSMS", ctx, notification.ID, "0712345678", mock.Anything).Return(nil)
+
+		err := service.DeliverEmail(ctx, notification.ID, 2, 3)
+
+		require.NoError(t, err)
+		sms.AssertExpectations(t)
+		querier.AssertCalled(t, "RecordNotificationChannelDelivered", mock.Anything, queries.RecordNotificationChannelDeliveredParams{
+			NotificationID: notification.ID,
+			Channel:        "sms",
+		})
+		mockEmailService.AssertNotCalled(t, "SendTemplatedEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		querier.AssertNotCalled(t, "MarkNotificationAsSent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("suppressed address still gets the SMS", func(t *testing.T) {
+		service, querier, mockEmailService, sms := setup()
+		suppressions := &MockSuppressionChecker{}
+		service.WithSuppressions(suppressions)
+		notification := bookAvailable("email", "sms")
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+		suppressions.On("IsSuppressed", ctx, "student@example.com").Return(true, nil)
+		sms.On("SendNotificationSMS", ctx, notification.ID, "0712345678", mock.Anything).Return(nil)
+		querier.On("MarkNotificationAsSent", mock.Anything, notification.ID).Return(nil)
+
+		err := service.DeliverEmail(ctx, notification.ID, 1, 3)
+
+		require.NoError(t, err)
+		sms.AssertExpectations(t)
+		mockEmailService.AssertNotCalled(t, "SendTemplatedEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		querier.AssertCalled(t, "MarkNotificationAsSent", mock.Anything, notification.ID)
+	})
+
+	t.Run("fails when every channel fails", func(t *testing.T) {
+		service, querier, mockEmailService, sms := setup()
+		notification := bookAvailable("email", "sms")
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+		mockEmailService.On("SendTemplatedEmail", mock.Anything, "student@example.com", mock.Anything, mock.Anything).Return(fmt.Errorf("connection refused"))
+		sms.On("SendNotificationSMS", ctx, notification.ID, mock.Anything, mock.Anything).Return(fmt.Errorf("insufficient balance"))
+
+		err := service.DeliverEmail(ctx, notification.ID, 1, 3)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "connection refused")
+		assert.Contains(t, err.Error(), "insufficient balance")
+		querier.AssertNotCalled(t, "MarkNotificationAsSent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("SMS only notification from the pending list", func(t *testing.T) {
+		service, querier, mockEmailService, sms := setup()
+		notification := bookAvailable("sms")
+		notification.Locale = models.LocaleSwahili
+
+		querier.On("ListUnsentNotifications", ctx, int32(10)).Return([]queries.Notification{notification}, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+		sms.On("SendNotificationSMS", ctx, notification.ID, "0712345678",
+			`Maktaba: "Dune" ulichohifadhi kiko tayari. Tafadhali kichukue ndani ya siku 3.`).Return(nil)
+		querier.On("MarkNotificationAsSent", ctx, notification.ID).Return(nil)
+
+		err := service.ProcessPendingNotifications(ctx, 10)
+
+		require.NoError(t, err)
+		sms.AssertExpectations(t)
+		querier.AssertExpectations(t)
+		mockEmailService.AssertNotCalled(t, "SendTemplatedEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SMS channel is skipped when SMS is not configured", func(t *testing.T) {
+		service, querier, mockEmailService, _ := setup()
+		service.sms = nil
+		notification := bookAvailable("email", "sms")
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(student, nil)
+		mockEmailService.On("SendTemplatedEmail", mock.Anything, "student@example.com", mock.Anything, mock.Anything).Return(nil)
+		querier.On("MarkNotificationAsSent", mock.Anything, notification.ID).Return(nil)
+
+		err := service.DeliverEmail(ctx, notification.ID, 1, 3)
+
+		require.NoError(t, err)
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("student without a phone", func(t *testing.T) {
+		service, querier, _, sms := setup()
+		notification := bookAvailable("sms")
+
+		querier.On("GetNotificationByID", ctx, notification.ID).Return(notification, nil)
+		expectChannelDeliveries(querier, notification.ID)
+		querier.On("GetStudentByID", ctx, notification.RecipientID).Return(queries.Student{ID: 1}, nil)
+
+		err := service.DeliverEmail(ctx, notification.ID, 1, 3)
+
+		assert.ErrorIs(t, err, ErrRecipientHasNoPhone)
+		sms.AssertNotCalled(t, "SendNotificationSMS", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("due soon reminders go out by email and SMS", func(t *testing.T) {
+		service, querier, _, _ := setup()
+		emailQueue := &MockEmailQueuer{}
+		service.WithEmailQueue(emailQueue)
+
+		querier.On("ListTransactionsDueSoon", ctx).Return([]queries.ListTransactionsDueSoonRow{{
+			ID:        1,
+			StudentID: 1,
+			BookID:    1,
+			DueDate:   pgtype.Timestamp{Time: time.Now().Add(24 * time.Hour), Valid: true},
+			FirstName: "John",
+			LastName:  "Doe",
+			Title:     "Dune",
+		}}, nil)
+		querier.On("CreateNotification", ctx, mock.MatchedBy(func(params queries.CreateNotificationParams) bool {
+			return assert.ObjectsAreEqual([]string{"email", "sms"}, params.Channels)
+		})).Return(createSampleDBNotification(), nil)
+		emailQueue.On("QueueEmail", ctx, mock.Anything).Return(&models.EmailQueueItem{ID: 7}, nil)
+
+		err := service.SendDueSoonReminders(ctx)
+
+		require.NoError(t, err)
+		querier.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrPaymentsNotConfigured = errors.New("mobile payments are not configured")
+	ErrInvalidCallbackToken  = errors.New("invalid payment callback token")
+)
+
+// PaymentProvider initiates mobile money payments and interprets the
+// provider's asynchronous result callbacks
+type PaymentProvider interface {
+	// Name identifies the provider, and is stored on payment requests
+	Name() string
+	// InitiatePayment asks the provider to prompt the payer's phone for the amount
+	InitiatePayment(ctx context.Context, req PaymentRequest) (*PaymentInitiation, error)
+	// ParseCallback authenticates and decodes a result callback
+	ParseCallback(token string, body []byte) (*PaymentResult, error)
+}
+
+// PaymentRequest describes a payment to collect from a phone
+type PaymentRequest struct {
+	Phone            string
+	Amount           decimal.Decimal
+	AccountReference string
+	Description      string
+}
+
+// PaymentInitiation is the provider's acknowledgement of a payment request
+type PaymentInitiation struct {
+	MerchantRequestID string
+	CheckoutRequestID string
+	CustomerMessage   string
+}
+
+// PaymentResult is the outcome of a payment request reported by the provider
+type PaymentResult struct {
+	MerchantRequestID string
+	CheckoutRequestID string
+	ResultCode        int
+	ResultDesc        string
+	Amount            decimal.Decimal
+	Receipt           string
+	Phone             string
+}
+
+// Succeeded reports whether the payer completed the payment
+func (r *PaymentResult) Succeeded() bool {
+	return r.ResultCode == 0
+}
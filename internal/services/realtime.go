@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+var (
+	ErrUnknownStreamUser = errors.New("event streams are only available to students and librarians")
+)
+
+// staffEventChannel reaches every connected librarian
+const staffEventChannel = "lms:events:staff"
+
+// RealtimeQuerier defines the database operations behind the realtime events
+type RealtimeQuerier interface {
+	CountUnreadNotificationsByRecipient(ctx context.Context, arg queries.CountUnreadNotificationsByRecipientParams) (int64, error)
+	CountOverdueTransactions(ctx context.Context) (int64, error)
+	CountHoldShelf(ctx context.Context) (int64, error)
+}
+
+// RealtimePublisher pushes changes to the clients connected to the event stream.
+// Publishing is best effort: failures are logged rather than returned, so the
+// change being announced never fails because the stream is unavailable.
+type RealtimePublisher interface {
+	NotificationCreated(ctx context.Context, notification queries.Notification)
+	NotificationRead(ctx context.Context, notification queries.Notification)
+	ReservationChanged(ctx context.Context, reservation queries.Reservation)
+	CountersChanged(ctx context.Context)
+}
+
+// RealtimeServiceInterface defines the operations behind the event stream endpoints
+type RealtimeServiceInterface interface {
+	Subscribe(ctx context.Context, userType string, userID int32) (EventSubscription, error)
+	Snapshot(ctx context.Context, userType string, userID int32) ([]models.RealtimeEvent, error)
+}
+
+// RealtimeService publishes notification, reservation and desk counter events,
+// and subscribes users to the events meant for them. Students receive their own
+// notifications and reservations; librarians also receive every reservation
+// change and the desk counters.
+type RealtimeService struct {
+	querier RealtimeQuerier
+	broker  EventBroker
+	logger  *slog.Logger
+}
+
+// NewRealtimeService creates a new realtime service
+func NewRealtimeService(querier RealtimeQuerier, broker EventBroker, logger *slog.Logger) *RealtimeService {
+	return &RealtimeService{
+		querier: querier,
+		broker:  broker,
+		logger:  logger,
+	}
+}
+
+// userEventChannel reaches every open session of one user
+func userEventChannel(userType string, userID int32) string {
+	return fmt.Sprintf("lms:events:%s:%d", userType, userID)
+}
+
+// Subscribe starts a feed of the events meant for the user
+func (s *RealtimeService) Subscribe(ctx context.Context, userType string, userID int32) (EventSubscription, error) {
+	channels := []string{userEventChannel(userType, userID)}
+	switch models.RecipientType(userType) {
+	case models.RecipientTypeStudent:
+	case models.RecipientTypeLibrarian:
+		channels = append(channels, staffEventChannel)
+	default:
+		return nil, ErrUnknownStreamUser
+	}
+
+	return s.broker.Subscribe(ctx, channels...)
+}
+
+// Snapshot returns the current state a client shows before any change arrives:
+// the user's unread count and, for librarians, the desk counters
+func (s *RealtimeService) Snapshot(ctx context.Context, userType string, userID int32) ([]models.RealtimeEvent, error) {
+	recipientType := models.RecipientType(userType)
+	if !recipientType.IsValid() {
+		return nil, ErrUnknownStreamUser
+	}
+
+	unread, err := s.unreadCount(ctx, userID, userType)
+	if err != nil {
+		return nil, err
+	}
+	unreadEvent, err := models.NewRealtimeEvent(models.EventUnreadCount, models.NotificationEvent{UnreadCount: unread})
+	if err != nil {
+		return nil, err
+	}
+	events := []models.RealtimeEvent{unreadEvent}
+
+	if recipientType == models.RecipientTypeLibrarian {
+		counters, err := s.LiveCounters(ctx)
+		if err != nil {
+			return nil, err
+		}
+		countersEvent, err := models.NewRealtimeEvent(models.EventCountersUpdated, counters)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, countersEvent)
+	}
+
+	return events, nil
+}
+
+// LiveCounters returns the desk counters. A renewed loan counts as overdue only
+// once its latest renewal is past due.
+func (s *RealtimeService) LiveCounters(ctx context.Context) (*models.LiveCounters, error) {
+	overdue, err := s.querier.CountOverdueTransactions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count overdue loans: %w", err)
+	}
+
+	holdShelf, err := s.querier.CountHoldShelf(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count hold shelf: %w", err)
+	}
+
+	return &models.LiveCounters{
+		OverdueLoans: overdue,
+		HoldShelf:    holdShelf,
+	}, nil
+}
+
+// PublishCounters sends the current desk counters to every connected librarian.
+// Loans become overdue with the passing of time rather than with any change, so
+// it also runs on a schedule.
+func (s *RealtimeService) PublishCounters(ctx context.Context) error {
+	counters, err := s.LiveCounters(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.publish(ctx, staffEventChannel, models.EventCountersUpdated, counters)
+}
+
+// NotificationCreated sends a new notification to its recipient's sessions
+func (s *RealtimeService) NotificationCreated(ctx context.Context, notification queries.Notification) {
+	unread, err := s.unreadCount(ctx, notification.RecipientID, notification.RecipientType)
+	if err != nil {
+		s.logger.Warn("Failed to publish new notification", "notification_id", notification.ID, "error", err)
+		return
+	}
+
+	err = s.publish(ctx, userEventChannel(notification.RecipientType, notification.RecipientID), models.EventNotificationCreated, models.NotificationEvent{
+		NotificationID: notification.ID,
+		Notification:   convertToNotificationResponse(notification),
+		UnreadCount:    unread,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to publish new notification", "notification_id", notification.ID, "error", err)
+	}
+}
+
+// NotificationRead tells the recipient's other sessions that a notification was
+// read, with the unread count that leaves
+func (s *RealtimeService) NotificationRead(ctx context.Context, notification queries.Notification) {
+	unread, err := s.unreadCount(ctx, notification.RecipientID, notification.RecipientType)
+	if err != nil {
+		s.logger.Warn("Failed to publish notification read", "notification_id", notification.ID, "error", err)
+		return
+	}
+
+	err = s.publish(ctx, userEventChannel(notification.RecipientType, notification.RecipientID), models.EventNotificationRead, models.NotificationEvent{
+		NotificationID: notification.ID,
+		UnreadCount:    unread,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to publish notification read", "notification_id", notification.ID, "error", err)
+	}
+}
+
+// ReservationChanged sends a reservation's new status to the student who holds it
+// and to the librarians
+func (s *RealtimeService) ReservationChanged(ctx context.Context, reservation queries.Reservation) {
+	event := models.ReservationEvent{
+		ReservationID: reservation.ID,
+		StudentID:     reservation.StudentID,
+		BookID:        reservation.BookID,
+		Status:        reservation.Status.String,
+	}
+	if reservation.CopyID.Valid {
+		event.CopyID = &reservation.CopyID.Int32
+	}
+	if reservation.PickupDeadline.Valid {
+		event.PickupDeadline = &reservation.PickupDeadline.Time
+	}
+
+	for _, channel := range []string{userEventChannel(string(models.RecipientTypeStudent), reservation.StudentID), staffEventChannel} {
+		if err := s.publish(ctx, channel, models.EventReservationStatusChanged, event); err != nil {
+			s.logger.Warn("Failed to publish reservation change", "reservation_id", reservation.ID, "error", err)
+		}
+	}
+}
+
+// CountersChanged republishes the desk counters after a change that may move them
+func (s *RealtimeService) CountersChanged(ctx context.Context) {
+	if err := s.PublishCounters(ctx); err != nil {
+		s.logger.Warn("Failed to publish desk counters", "error", err)
+	}
+}
+
+func (s *RealtimeService) unreadCount(ctx context.Context, recipientID int32, recipientType string) (int64, error) {
+	unread, err := s.querier.CountUnreadNotificationsByRecipient(ctx, queries.CountUnreadNotificationsByRecipientParams{
+		RecipientID:   recipientID,
+		RecipientType: recipientType,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return unread, nil
+}
+
+func (s *RealtimeService) publish(ctx context.Context, channel, eventType string, data interface{}) error {
+	event, err := models.NewRealtimeEvent(eventType, data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	return s.broker.Publish(ctx, channel, event)
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// eventBufferSize is how many events a subscriber may fall behind by before
+// further events to it are dropped
+const eventBufferSize = 64
+
+// EventBroker carries realtime events between the API instances and the clients
+// connected to them
+type EventBroker interface {
+	Publish(ctx context.Context, channel string, event models.RealtimeEvent) error
+	Subscribe(ctx context.Context, channels ...string) (EventSubscription, error)
+}
+
+// EventSubscription is a client's feed of events from one or more channels
+type EventSubscription interface {
+	// Events delivers the events published to the subscribed channels. It is
+	// closed when the subscription ends.
+	Events() <-chan models.RealtimeEvent
+	Close() error
+}
+
+// RedisEventBroker implements EventBroker with Redis pub/sub, so an event
+// published by one instance reaches clients connected to any instance
+type RedisEventBroker struct {
+	redis  *redis.Client
+	logger *slog.Logger
+}
+
+// NewRedisEventBroker creates a Redis pub/sub event broker
+func NewRedisEventBroker(redisClient *redis.Client, logger *slog.Logger) *RedisEventBroker {
+	return &RedisEventBroker{
+		redis:  redisClient,
+		logger: logger,
+	}
+}
+
+// Publish sends an event to everyone subscribed to the channel
+func (b *RedisEventBroker) Publish(ctx context.Context, channel string, event models.RealtimeEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	if err := b.redis.Publish(ctx, channel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// Subscribe listens on the channels until the subscription is closed
+func (b *RedisEventBroker) Subscribe(ctx context.Context, channels ...string) (EventSubscription, error) {
+	pubsub := b.redis.Subscribe(ctx, channels...)
+
+	// Wait for Redis to confirm so no event published after Subscribe returns is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+
+	sub := &redisSubscription{
+		pubsub: pubsub,
+		events: make(chan models.RealtimeEvent, eventBufferSize),
+		done:   make(chan struct{}),
+	}
+	go sub.forward(b.logger)
+
+	return sub, nil
+}
+
+type redisSubscription struct {
+	pubsub *redis.PubSub
+	events chan models.RealtimeEvent
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (s *redisSubscription) forward(logger *slog.Logger) {
+	defer close(s.events)
+
+	for msg := range s.pubsub.Channel() {
+		var event models.RealtimeEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			logger.Warn("Dropping undecodable realtime event", "channel", msg.Channel, "error", err)
+			continue
+		}
+
+		select {
+		case s.events <- event:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *redisSubscription) Events() <-chan models.RealtimeEvent {
+	return s.events
+}
+
+func (s *redisSubscription) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		err = s.pubsub.Close()
+	})
+	return err
+}
+
+// LocalEventBroker implements EventBroker in memory. Events only reach clients
+// connected to the same instance, so it suits tests and single-instance setups.
+type LocalEventBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*localSubscription]struct{}
+}
+
+// NewLocalEventBroker creates an in-memory event broker
+func NewLocalEventBroker() *LocalEventBroker {
+	return &LocalEventBroker{
+		subscribers: make(map[string]map[*localSubscription]struct{}),
+	}
+}
+
+// Publish sends an event to the channel's subscribers, skipping any whose buffer is full
+func (b *LocalEventBroker) Publish(ctx context.Context, channel string, event models.RealtimeEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers[channel] {
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe listens on the channels until the subscription is closed
+func (b *LocalEventBroker) Subscribe(ctx context.Context, channels ...string) (EventSubscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &localSubscription{
+		broker:   b,
+		channels: channels,
+		events:   make(chan models.RealtimeEvent, eventBufferSize),
+	}
+	for _, channel := range channels {
+		if b.subscribers[channel] == nil {
+			b.subscribers[channel] = make(map[*localSubscription]struct{})
+		}
+		b.subscribers[channel][sub] = struct{}{}
+	}
+
+	return sub, nil
+}
+
+type localSubscription struct {
+	broker   *LocalEventBroker
+	channels []string
+	events   chan models.RealtimeEvent
+	once     sync.Once
+}
+
+func (s *localSubscription) Events() <-chan models.RealtimeEvent {
+	return s.events
+}
+
+func (s *localSubscription) Close() error {
+	s.once.Do(func() {
+		s.broker.mu.Lock()
+		defer s.broker.mu.Unlock()
+
+		for _, channel := range s.channels {
+			delete(s.broker.subscribers[channel], s)
+			if len(s.broker.subscribers[channel]) == 0 {
+				delete(s.broker.subscribers, channel)
+			}
+		}
+		close(s.events)
+	})
+	return nil
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockRealtimeQuerier is a mock implementation of RealtimeQuerier
+type MockRealtimeQuerier struct {
+	mock.Mock
+}
+
+func (m *MockRealtimeQuerier) CountUnreadNotificationsByRecipient(ctx context.Context, arg queries.CountUnreadNotificationsByRecipientParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRealtimeQuerier) CountOverdueTransactions(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRealtimeQuerier) CountHoldShelf(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockRealtimePublisher is a mock implementation of RealtimePublisher
+type MockRealtimePublisher struct {
+	mock.Mock
+}
+
+func (m *MockRealtimePublisher) NotificationCreated(ctx context.Context, notification queries.Notification) {
+	m.Called(ctx, notification)
+}
+
+func (m *MockRealtimePublisher) NotificationRead(ctx context.Context, notification queries.Notification) {
+	m.Called(ctx, notification)
+}
+
+func (m *MockRealtimePublisher) ReservationChanged(ctx context.Context, reservation queries.Reservation) {
+	m.Called(ctx, reservation)
+}
+
+func (m *MockRealtimePublisher) CountersChanged(ctx context.Context) {
+	m.Called(ctx)
+}
+
+func newTestRealtimeService() (*RealtimeService, *MockRealtimeQuerier, *LocalEventBroker) {
+	querier := &MockRealtimeQuerier{}
+	broker := NewLocalEventBroker()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	return NewRealtimeService(querier, broker, logger), querier, broker
+}
+
+// nextEvent waits briefly for the subscription's next event
+func nextEvent(t *testing.T, subscription EventSubscription) models.RealtimeEvent {
+	t.Helper()
+	select {
+	case event := <-subscription.Events():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return models.RealtimeEvent{}
+	}
+}
+
+func assertNoEvent(t *testing.T, subscription EventSubscription) {
+	t.Helper()
+	select {
+	case event := <-subscription.Events():
+		t.Fatalf("unexpected %s event", event.Type)
+	default:
+	}
+}
+
+func decodeEventData(t *testing.T, event models.RealtimeEvent, v interface{}) {
+	t.Helper()
+	require.NoError(t, json.Unmarshal(event.Data, v))
+}
+
+func TestRealtimeService_Subscribe(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unknown user type", func(t *testing.T) {
+		service, _, _ := newTestRealtimeService()
+
+		_, err := service.Subscribe(ctx, "", 1)
+
+		assert.ErrorIs(t, err, ErrUnknownStreamUser)
+	})
+
+	t.Run("closing ends the feed", func(t *testing.T) {
+		service, _, broker := newTestRealtimeService()
+
+		subscription, err := service.Subscribe(ctx, "librarian", 1)
+		require.NoError(t, err)
+		require.NoError(t, subscription.Close())
+
+		_, open := <-subscription.Events()
+		assert.False(t, open)
+		assert.Empty(t, broker.subscribers)
+	})
+}
+
+func TestRealtimeService_Snapshot(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("student gets unread count", func(t *testing.T) {
+		service, querier, _ := newTestRealtimeService()
+		querier.On("CountUnreadNotificationsByRecipient", ctx, queries.CountUnreadNotificationsByRecipientParams{
+			RecipientID:   2,
+			RecipientType: "student",
+		}).Return(int64(4), nil)
+
+		events, err := service.Snapshot(ctx, "student", 2)
+
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, models.EventUnreadCount, events[0].Type)
+		var data models.NotificationEvent
+		decodeEventData(t, events[0], &data)
+		assert.Equal(t, int64(4), data.UnreadCount)
+		querier.AssertNotCalled(t, "CountHoldShelf", mock.Anything)
+	})
+
+	t.Run("librarian also gets counters", func(t *testing.T) {
+		service, querier, _ := newTestRealtimeService()
+		querier.On("CountUnreadNotificationsByRecipient", ctx, mock.Anything).Return(int64(0), nil)
+		querier.On("CountOverdueTransactions", ctx).Return(int64(7), nil)
+		querier.On("CountHoldShelf", ctx).Return(int64(3), nil)
+
+		events, err := service.Snapshot(ctx, "librarian", 1)
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, models.EventCountersUpdated, events[1].Type)
+		var counters models.LiveCounters
+		decodeEventData(t, events[1], &counters)
+		assert.Equal(t, models.LiveCounters{OverdueLoans: 7, HoldShelf: 3}, counters)
+	})
+}
+
+func TestRealtimeService_NotificationEvents(t *testing.T) {
+	ctx := context.Background()
+	notification := queries.Notification{
+		ID:            11,
+		RecipientID:   2,
+		RecipientType: "student",
+		Type:          "book_available",
+		Title:         "Reserved Book Ready: Dune",
+		Channels:      []string{"email"},
+	}
+	unreadParams := queries.CountUnreadNotificationsByRecipientParams{RecipientID: 2, RecipientType: "student"}
+
+	t.Run("new notification reaches every session of the recipient only", func(t *testing.T) {
+		service, querier, _ := newTestRealtimeService()
+		querier.On("CountUnreadNotificationsByRecipient", ctx, unreadParams).Return(int64(3), nil)
+
+		phone, err := service.Subscribe(ctx, "student", 2)
+		require.NoError(t, err)
+		laptop, err := service.Subscribe(ctx, "student", 2)
+		require.NoError(t, err)
+		otherStudent, err := service.Subscribe(ctx, "student", 3)
+		require.NoError(t, err)
+		librarian, err := service.Subscribe(ctx, "librarian", 2)
+		require.NoError(t, err)
+
+		service.NotificationCreated(ctx, notification)
+
+		for _, session := range []EventSubscription{phone, laptop} {
+			event := nextEvent(t, session)
+			assert.Equal(t, models.EventNotificationCreated, event.Type)
+			var data models.NotificationEvent
+			decodeEventData(t, event, &data)
+			assert.Equal(t, int32(11), data.NotificationID)
+			assert.Equal(t, int64(3), data.UnreadCount)
+			require.NotNil(t, data.Notification)
+			assert.Equal(t, "Reserved Book Ready: Dune", data.Notification.Title)
+		}
+		assertNoEvent(t, otherStudent)
+		assertNoEvent(t, librarian)
+	})
+
+	t.Run("read receipt carries the new unread count", func(t *testing.T) {
+		service, querier, _ := newTestRealtimeService()
+		querier.On("CountUnreadNotificationsByRecipient", ctx, unreadParams).Return(int64(2), nil)
+
+		session, err := service.Subscribe(ctx, "student", 2)
+		require.NoError(t, err)
+
+		service.NotificationRead(ctx, notification)
+
+		event := nextEvent(t, session)
+		assert.Equal(t, models.EventNotificationRead, event.Type)
+		var data models.NotificationEvent
+		decodeEventData(t, event, &data)
+		assert.Equal(t, int32(11), data.NotificationID)
+		assert.Equal(t, int64(2), data.UnreadCount)
+		assert.Nil(t, data.Notification)
+	})
+}
+
+func TestRealtimeService_ReservationChanged(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newTestRealtimeService()
+
+	student, err := service.Subscribe(ctx, "student", 2)
+	require.NoError(t, err)
+	otherStudent, err := service.Subscribe(ctx, "student", 3)
+	require.NoError(t, err)
+	librarian, err := service.Subscribe(ctx, "librarian", 9)
+	require.NoError(t, err)
+
+	deadline := time.Date(2025, 3, 5, 17, 0, 0, 0, time.UTC)
+	service.ReservationChanged(ctx, queries.Reservation{
+		ID:             40,
+		StudentID:      2,
+		BookID:         1,
+		Status:         pgtype.Text{String: "ready", Valid: true},
+		CopyID:         pgtype.Int4{Int32: 10, Valid: true},
+		PickupDeadline: pgtype.Timestamp{Time: deadline, Valid: true},
+	})
+
+	for _, session := range []EventSubscription{student, librarian} {
+		event := nextEvent(t, session)
+		assert.Equal(t, models.EventReservationStatusChanged, event.Type)
+		var data models.ReservationEvent
+		decodeEventData(t, event, &data)
+		assert.Equal(t, int32(40), data.ReservationID)
+		assert.Equal(t, "ready", data.Status)
+		require.NotNil(t, data.CopyID)
+		assert.Equal(t, int32(10), *data.CopyID)
+		require.NotNil(t, data.PickupDeadline)
+		assert.True(t, deadline.Equal(*data.PickupDeadline))
+	}
+	assertNoEvent(t, otherStudent)
+}
+
+func TestRealtimeService_PublishCounters(t *testing.T) {
+	ctx := context.Background()
+	service, querier, _ := newTestRealtimeService()
+	querier.On("CountOverdueTransactions", ctx).Return(int64(5), nil)
+	querier.On("CountHoldShelf", ctx).Return(int64(2), nil)
+
+	student, err := service.Subscribe(ctx, "student", 2)
+	require.NoError(t, err)
+	librarian, err := service.Subscribe(ctx, "librarian", 9)
+	require.NoError(t, err)
+
+	require.NoError(t, service.PublishCounters(ctx))
+
+	event := nextEvent(t, librarian)
+	assert.Equal(t, models.EventCountersUpdated, event.Type)
+	var counters models.LiveCounters
+	decodeEventData(t, event, &counters)
+	assert.Equal(t, models.LiveCounters{OverdueLoans: 5, HoldShelf: 2}, counters)
+	assertNoEvent(t, student)
+}
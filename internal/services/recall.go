@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// defaultRecallNoticeDays is the least time a student is given to bring back a recalled book
+const defaultRecallNoticeDays = 3
+
+// errRecallTooLate is returned when a loan is already due before a recall could take effect
+var errRecallTooLate = errors.New("loan is due before a recall would take effect")
+
+// LoanRecaller recalls a loan of a book that reservations are waiting for
+type LoanRecaller interface {
+	RecallForReservations(ctx context.Context, bookID int32) (*TransactionResponse, error)
+}
+
+// RecallServiceInterface defines the interface for recall operations
+type RecallServiceInterface interface {
+	LoanRecaller
+	RecallLoan(ctx context.Context, transactionID int32, reason string, actor AuditActor) (*TransactionResponse, error)
+}
+
+// RecallService recalls loans of books that other students are waiting for. A
+// recalled loan is given a shorter due date, can no longer be renewed, and is
+// fined at the policy's recall rate once that date passes.
+type RecallService struct {
+	transactions *TransactionService
+	logger       *slog.Logger
+	noticeDays   int
+}
+
+// NewRecallService creates a recall service. Due dates and fines follow the
+// circulation policies and calendar configured on transactions.
+func NewRecallService(transactions *TransactionService, logger *slog.Logger) *RecallService {
+	return &RecallService{
+		transactions: transactions,
+		logger:       logger,
+		noticeDays:   defaultRecallNoticeDays,
+	}
+}
+
+// WithNoticeDays sets the least number of days a student has to return a recalled book
+func (s *RecallService) WithNoticeDays(days int) *RecallService {
+	s.noticeDays = days
+	return s
+}
+
+// RecallLoan brings the due date of an open loan forward and tells the student.
+// The student keeps the book for at least the policy's recall loan period from
+// checkout and the notice period from now, and never past the original due date.
+func (s *RecallService) RecallLoan(ctx context.Context, transactionID int32, reason string, actor AuditActor) (*TransactionResponse, error) {
+	var response *TransactionResponse
+	err := s.transactions.execTx(ctx, func(tx *TransactionService) error {
+		var err error
+		response, err = s.recallLoanTx(ctx, tx, transactionID, reason, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// recallLoanTx performs the recall on a service bound to the open database transaction
+func (s *RecallService) recallLoanTx(ctx context.Context, tx *TransactionService, transactionID int32, reason string, actor AuditActor) (*TransactionResponse, error) {
+	lockedRow, err := tx.queries.GetTransactionByIDForUpdate(ctx, transactionID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("transaction not found")
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	transactionRow := queries.GetTransactionByIDRow(lockedRow)
+
+	if transactionRow.ReturnedDate.Valid {
+		return nil, fmt.Errorf("cannot recall returned book")
+	}
+	if transactionRow.TransactionType != "borrow" && transactionRow.TransactionType != "renew" {
+		return nil, fmt.Errorf("invalid transaction type for recall")
+	}
+	if transactionRow.RecalledAt.Valid {
+		return nil, fmt.Errorf("loan has already been recalled")
+	}
+
+	// A renewed borrow stays open alongside its renewal; only the renewal carries the due date
+	loans, err := tx.queries.ListActiveTransactionsByStudent(ctx, transactionRow.StudentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check active transactions: %w", err)
+	}
+	for _, loan := range currentLoans(loans) {
+		if loan.BookID == transactionRow.BookID && loan.ID != transactionRow.ID {
+			return nil, fmt.Errorf("loan has been renewed, recall transaction %d instead", loan.ID)
+		}
+	}
+
+	policy, err := tx.resolvePolicy(ctx, transactionPolicyContext(transactionRow))
+	if err != nil {
+		return nil, err
+	}
+
+	dueDate, err := s.recallDueDate(ctx, tx, transactionRow, policy)
+	if err != nil {
+		return nil, err
+	}
+	if transactionRow.DueDate.Valid && !dueDate.Before(transactionRow.DueDate.Time) {
+		return nil, fmt.Errorf("%w: it is due on %s", errRecallTooLate, transactionRow.DueDate.Time.Format("2006-01-02"))
+	}
+
+	transaction, err := tx.queries.RecallTransaction(ctx, queries.RecallTransactionParams{
+		ID:         transactionID,
+		DueDate:    pgtype.Timestamp{Time: dueDate, Valid: true},
+		RecalledBy: pgtype.Int4{Int32: actor.UserID, Valid: actor.UserID != 0},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to recall loan: %w", err)
+	}
+
+	notice, err := recallNotification(transactionRow, dueDate, policy, reason)
+	if err != nil {
+		return nil, err
+	}
+	tx.notifyStudent(ctx, notice)
+
+	response := tx.convertToTransactionResponse(transaction)
+	if err := writeAuditLog(ctx, tx.queries, actor, "transactions", transactionID, "UPDATE", tx.convertToTransactionResponse(lockedRowTransaction(lockedRow)), response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// recallDueDate returns the due date a recall gives the loan, rolled forward to a day the library is open
+func (s *RecallService) recallDueDate(ctx context.Context, tx *TransactionService, transactionRow queries.GetTransactionByIDRow, policy *CirculationPolicy) (time.Time, error) {
+	dueDate := transactionRow.TransactionDate.Time.AddDate(0, 0, policy.RecallLoanDays)
+	if notice := time.Now().AddDate(0, 0, s.noticeDays); dueDate.Before(notice) {
+		dueDate = notice
+	}
+
+	if tx.calendar == nil {
+		return dueDate, nil
+	}
+	dueDate, err := tx.calendar.NextOpenDay(ctx, dueDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to calculate recall due date: %w", err)
+	}
+	return dueDate, nil
+}
+
+// RecallForReservations recalls the longest running loan of a book when more
+// reservations are waiting for it than loans already recalled. It returns nil
+// when no recall is needed or no loan can be recalled.
+func (s *RecallService) RecallForReservations(ctx context.Context, bookID int32) (*TransactionResponse, error) {
+	q := s.transactions.queries
+
+	waiting, err := q.CountActiveReservationsByBook(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reservations: %w", err)
+	}
+	recalled, err := q.CountOpenRecallsByBook(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recalls: %w", err)
+	}
+	if waiting <= recalled {
+		return nil, nil
+	}
+
+	transactionID, err := q.GetRecallableLoanByBook(ctx, bookID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find a loan to recall: %w", err)
+	}
+
+	response, err := s.RecallLoan(ctx, transactionID, "", AuditActor{UserType: "system"})
+	if err != nil {
+		if errors.Is(err, errRecallTooLate) {
+			return nil, nil
+		}
+		s.logger.Warn("Failed to recall loan for reservation", "book_id", bookID, "transaction_id", transactionID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Loan recalled for reservation", "book_id", bookID, "transaction_id", transactionID, "due_date", response.DueDate)
+	return response, nil
+}
+
+// recallNotification builds the notice telling a student their loan has been recalled
+func recallNotification(transactionRow queries.GetTransactionByIDRow, dueDate time.Time, policy *CirculationPolicy, reason string) (*models.NotificationRequest, error) {
+	return studentNotice(transactionRow.StudentID, models.NotificationTypeRecallNotice, models.NotificationPriorityUrgent, transactionRow.Locale, map[string]interface{}{
+		"StudentName":  transactionRow.FirstName + " " + transactionRow.LastName,
+		"BookTitle":    transactionRow.Title,
+		"BookAuthor":   transactionRow.Author,
+		"RecallReason": recallReason(reason, transactionRow.Locale),
+		"DueDate":      FormatDate(dueDate, transactionRow.Locale),
+		"FinePerDay":   FormatMoney(policy.RecallFinePerDay, transactionRow.Locale),
+	})
+}
+
+// recallReason is the reason a notice gives for a recall, which is a reservation
+// waiting for the book unless the librarian gave one
+func recallReason(reason, locale string) string {
+	if reason != "" {
+		return reason
+	}
+	if NormalizeLocale(locale) == models.LocaleSwahili {
+		return "Kimehifadhiwa na msomaji mwingine"
+	}
+	return "Reserved by another reader"
+}
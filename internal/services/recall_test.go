@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockLoanRecaller is a mock implementation of LoanRecaller
+type MockLoanRecaller struct {
+	mock.Mock
+}
+
+func (m *MockLoanRecaller) RecallForReservations(ctx context.Context, bookID int32) (*TransactionResponse, error) {
+	args := m.Called(ctx, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*TransactionResponse), args.Error(1)
+}
+
+func newTestRecallService(mockQueries *MockTransactionQueries) *RecallService {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewRecallService(NewTransactionService(mockQueries), logger)
+}
+
+// createTestLoan returns an open loan taken out four days ago and due in ten
+func createTestLoan(now time.Time) queries.GetTransactionByIDForUpdateRow {
+	return queries.GetTransactionByIDForUpdateRow{
+		ID:              1,
+		StudentID:       1,
+		BookID:          1,
+		TransactionType: "borrow",
+		TransactionDate: pgtype.Timestamp{Time: now.AddDate(0, 0, -4), Valid: true},
+		DueDate:         pgtype.Timestamp{Time: now.AddDate(0, 0, 10), Valid: true},
+		FirstName:       "John",
+		LastName:        "Doe",
+		Title:           "Test Book",
+		Author:          "Test Author",
+		YearOfStudy:     1,
+	}
+}
+
+func TestRecallService_RecallLoan(t *testing.T) {
+	ctx := context.Background()
+	librarian := AuditActor{UserID: 7, UserType: "librarian"}
+
+	t.Run("ShortensDueDateAndNotifiesStudent", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		notifier := &MockStudentNotifier{}
+		service := newTestRecallService(mockQueries)
+		service.transactions.WithNotifier(notifier)
+
+		now := time.Now()
+		loan := createTestLoan(now)
+		recalled := createTestTransaction()
+		recalled.DueDate = pgtype.Timestamp{Time: now.AddDate(0, 0, 3), Valid: true}
+		recalled.RecalledAt = pgtype.Timestamp{Time: now, Valid: true}
+		recalled.OriginalDueDate = loan.DueDate
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(loan, nil)
+		mockQueries.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{
+			{ID: 1, StudentID: 1, BookID: 1, TransactionType: "borrow"},
+		}, nil)
+		// Borrowed four days ago, so the 7 day recall period and 3 days notice both end in three days
+		mockQueries.On("RecallTransaction", ctx, mock.MatchedBy(func(arg queries.RecallTransactionParams) bool {
+			days := arg.DueDate.Time.Sub(now).Hours() / 24
+			return arg.ID == 1 && arg.RecalledBy.Int32 == 7 && days > 2.9 && days < 3.1
+		})).Return(recalled, nil)
+		notifier.On("CreateNotification", ctx, mock.MatchedBy(func(req *models.NotificationRequest) bool {
+			return req.RecipientID == 1 && req.Type == models.NotificationTypeRecallNotice &&
+				req.Title == "Book Recalled - Test Book" &&
+				strings.Contains(req.Message, "Reason: Needed for a course")
+		})).Return(&models.NotificationResponse{ID: 30}, nil)
+		mockQueries.On("CreateAuditLog", ctx, mock.MatchedBy(func(arg queries.CreateAuditLogParams) bool {
+			return arg.TableName == "transactions" && arg.Action == "UPDATE"
+		})).Return(nil)
+
+		result, err := service.RecallLoan(ctx, 1, "Needed for a course", librarian)
+
+		require.NoError(t, err)
+		require.NotNil(t, result.RecalledAt)
+		require.NotNil(t, result.OriginalDueDate)
+		assert.True(t, result.DueDate.Before(*result.OriginalDueDate))
+		mockQueries.AssertExpectations(t)
+		notifier.AssertExpectations(t)
+	})
+
+	t.Run("AlreadyRecalled", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := newTestRecallService(mockQueries)
+
+		loan := createTestLoan(time.Now())
+		loan.RecalledAt = pgtype.Timestamp{Time: time.Now().AddDate(0, 0, -1), Valid: true}
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(loan, nil)
+
+		_, err := service.RecallLoan(ctx, 1, "", librarian)
+
+		require.Error(t, err)
+		assert.Equal(t, "loan has already been recalled", err.Error())
+		mockQueries.AssertNotCalled(t, "RecallTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("RenewedBorrowPointsAtRenewal", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := newTestRecallService(mockQueries)
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(createTestLoan(time.Now()), nil)
+		mockQueries.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{
+			{ID: 1, StudentID: 1, BookID: 1, TransactionType: "borrow"},
+			{ID: 4, StudentID: 1, BookID: 1, TransactionType: "renew"},
+		}, nil)
+
+		_, err := service.RecallLoan(ctx, 1, "", librarian)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "recall transaction 4 instead")
+	})
+
+	t.Run("DueBeforeRecallWouldTakeEffect", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := newTestRecallService(mockQueries)
+
+		loan := createTestLoan(time.Now())
+		loan.DueDate = pgtype.Timestamp{Time: time.Now().AddDate(0, 0, 1), Valid: true}
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(loan, nil)
+		mockQueries.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{
+			{ID: 1, StudentID: 1, BookID: 1, TransactionType: "borrow"},
+		}, nil)
+
+		_, err := service.RecallLoan(ctx, 1, "", librarian)
+
+		assert.ErrorIs(t, err, errRecallTooLate)
+		mockQueries.AssertNotCalled(t, "RecallTransaction", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRecallService_RecallForReservations(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SkipsWhenRecallsCoverReservations", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := newTestRecallService(mockQueries)
+
+		mockQueries.On("CountActiveReservationsByBook", ctx, int32(1)).Return(int64(1), nil)
+		mockQueries.On("CountOpenRecallsByBook", ctx, int32(1)).Return(int64(1), nil)
+
+		result, err := service.RecallForReservations(ctx, 1)
+
+		require.NoError(t, err)
+		assert.Nil(t, result)
+		mockQueries.AssertNotCalled(t, "GetRecallableLoanByBook", mock.Anything, mock.Anything)
+	})
+
+	t.Run("NoLoanToRecall", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := newTestRecallService(mockQueries)
+
+		mockQueries.On("CountActiveReservationsByBook", ctx, int32(1)).Return(int64(2), nil)
+		mockQueries.On("CountOpenRecallsByBook", ctx, int32(1)).Return(int64(1), nil)
+		mockQueries.On("GetRecallableLoanByBook", ctx, int32(1)).Return(int32(0), pgx.ErrNoRows)
+
+		result, err := service.RecallForReservations(ctx, 1)
+
+		require.NoError(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run("RecallsOldestLoanAsSystem", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		notifier := &MockStudentNotifier{}
+		service := newTestRecallService(mockQueries)
+		service.transactions.WithNotifier(notifier)
+
+		now := time.Now()
+		recalled := createTestTransaction()
+		recalled.RecalledAt = pgtype.Timestamp{Time: now, Valid: true}
+
+		mockQueries.On("CountActiveReservationsByBook", ctx, int32(1)).Return(int64(1), nil)
+		mockQueries.On("CountOpenRecallsByBook", ctx, int32(1)).Return(int64(0), nil)
+		mockQueries.On("GetRecallableLoanByBook", ctx, int32(1)).Return(int32(1), nil)
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(createTestLoan(now), nil)
+		mockQueries.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{
+			{ID: 1, StudentID: 1, BookID: 1, TransactionType: "borrow"},
+		}, nil)
+		mockQueries.On("RecallTransaction", ctx, mock.MatchedBy(func(arg queries.RecallTransactionParams) bool {
+			return arg.ID == 1 && !arg.RecalledBy.Valid
+		})).Return(recalled, nil)
+		notifier.On("CreateNotification", ctx, mock.AnythingOfType("*models.NotificationRequest")).Return(&models.NotificationResponse{}, nil)
+		mockQueries.On("CreateAuditLog", ctx, mock.MatchedBy(func(arg queries.CreateAuditLogParams) bool {
+			return arg.UserType.String == "system"
+		})).Return(nil)
+
+		result, err := service.RecallForReservations(ctx, 1)
+
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.NotNil(t, result.RecalledAt)
+		mockQueries.AssertExpectations(t)
+		notifier.AssertExpectations(t)
+	})
+}
+
+func TestRecalledLoans(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	recalledLoan := queries.GetTransactionByIDRow{
+		ID:              1,
+		StudentID:       1,
+		BookID:          1,
+		TransactionType: "borrow",
+		DueDate:         pgtype.Timestamp{Time: now.AddDate(0, 0, 2), Valid: true},
+		RecalledAt:      pgtype.Timestamp{Time: now.AddDate(0, 0, -1), Valid: true},
+	}
+
+	t.Run("RenewalRefused", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(queries.GetTransactionByIDForUpdateRow(recalledLoan), nil)
+		mockQueries.On("GetCurrentLoanTransactionID", ctx, int32(1)).Return(int32(1), nil)
+
+		_, err := service.RenewBook(ctx, 1, 1)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot renew recalled book")
+		mockQueries.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CanRenewReportsRecall", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		mockQueries.On("GetTransactionByID", ctx, int32(1)).Return(recalledLoan, nil)
+
+		canRenew, reason, err := service.CanBookBeRenewed(ctx, 1)
+
+		require.NoError(t, err)
+		assert.False(t, canRenew)
+		assert.Equal(t, "Book has been recalled and must be returned by "+recalledLoan.DueDate.Time.Format("2006-01-02"), reason)
+	})
+
+	t.Run("AccruesAtRecallRate", func(t *testing.T) {
+		querier := &MockFineAccrualQuerier{}
+		service := newTestFineAccrualService(querier)
+
+		querier.On("ClaimBackgroundJob", ctx, mock.Anything).Return(queries.BackgroundJob{Name: FineAccrualJobName}, nil)
+		querier.On("ListOpenOverdueLoans", ctx).Return([]queries.ListOpenOverdueLoansRow{
+			{ID: 1, YearOfStudy: 1, DueDate: pgtype.Timestamp{Time: now.AddDate(0, 0, -4), Valid: true}, RecalledAt: pgtype.Timestamp{Time: now.AddDate(0, 0, -8), Valid: true}},
+		}, nil)
+		// Four days at the default recall rate of 1.00 a day rather than 0.50
+		querier.On("AccrueTransactionFine", ctx, queries.AccrueTransactionFineParams{ID: 1, FineAmount: fineToNumeric(decimal.NewFromInt(4))}).Return(int64(1), nil)
+		querier.On("FinishBackgroundJob", mock.Anything, mock.Anything).Return(queries.BackgroundJob{}, nil)
+
+		result, err := service.AccrueOverdueFines(ctx)
+
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(4).Equal(result.TotalAccrued))
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("ReservationTriggersRecall", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		recaller := &MockLoanRecaller{}
+		service := NewReservationService(mockQuerier).WithRecaller(recaller)
+
+		mockQuerier.On("GetStudentByID", ctx, int32(1)).Return(queries.Student{ID: 1, IsActive: pgtype.Bool{Bool: true, Valid: true}}, nil)
+		mockQuerier.On("GetBookByID", ctx, int32(2)).Return(queries.Book{ID: 2, IsActive: pgtype.Bool{Bool: true, Valid: true}, AvailableCopies: pgtype.Int4{Int32: 0, Valid: true}}, nil)
+		mockQuerier.On("CountActiveReservationsByStudent", ctx, int32(1)).Return(int64(0), nil)
+		mockQuerier.On("ListReservationsByStudent", ctx, mock.AnythingOfType("queries.ListReservationsByStudentParams")).Return([]queries.ListReservationsByStudentRow{}, nil)
+		mockQuerier.On("CreateReservation", ctx, mock.AnythingOfType("queries.CreateReservationParams")).Return(queries.Reservation{ID: 5, StudentID: 1, BookID: 2}, nil)
+		mockQuerier.On("ListReservationsByBook", ctx, int32(2)).Return([]queries.ListReservationsByBookRow{{ID: 5, BookID: 2}}, nil)
+		// A failed recall does not undo the reservation
+		recaller.On("RecallForReservations", ctx, int32(2)).Return(nil, errors.New("connection reset"))
+
+		result, err := service.ReserveBook(ctx, 1, 2)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(5), result.ID)
+		recaller.AssertExpectations(t)
+	})
+}
+
+func TestRecallNotification(t *testing.T) {
+	loan := queries.GetTransactionByIDRow{
+		StudentID: 1,
+		FirstName: "Amina",
+		LastName:  "Wanjiru",
+		Title:     "Test Book",
+		Author:    "Test Author",
+		Locale:    "sw",
+	}
+	dueDate := time.Date(2025, time.March, 14, 0, 0, 0, 0, time.UTC)
+	policy := &CirculationPolicy{RecallFinePerDay: decimal.NewFromInt(1500)}
+
+	notice, err := recallNotification(loan, dueDate, policy, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificationTypeRecallNotice, notice.Type)
+	assert.Equal(t, models.NotificationPriorityUrgent, notice.Priority)
+	assert.Equal(t, emailAndSMS, notice.Channels)
+	assert.Equal(t, "sw", notice.Locale)
+	assert.Equal(t, "Kitabu Kimerudishwa Mapema - Test Book", notice.Title)
+	assert.Contains(t, notice.Message, "Mpendwa Amina Wanjiru")
+	assert.Contains(t, notice.Message, "Tarehe Mpya ya Kurudisha: 14 Machi 2025")
+	assert.Contains(t, notice.Message, "faini ni KSh 1,500.00 kwa siku")
+	assert.Contains(t, notice.Message, "Sababu: Kimehifadhiwa na msomaji mwingine")
+	assert.Equal(t, "14 Machi 2025", notice.TemplateData["DueDate"])
+}
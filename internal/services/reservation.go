@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
 )
 
 // ReservationQuerier defines the interface for reservation database operations
@@ -28,6 +30,15 @@ type ReservationQuerier interface {
 	GetStudentReservationForBook(ctx context.Context, arg queries.GetStudentReservationForBookParams) (queries.GetStudentReservationForBookRow, error)
 	GetBookByID(ctx context.Context, id int32) (queries.Book, error)
 	GetStudentByID(ctx context.Context, id int32) (queries.Student, error)
+	ListHoldShelf(ctx context.Context) ([]queries.ListHoldShelfRow, error)
+	SuspendReservation(ctx context.Context, arg queries.SuspendReservationParams) (queries.Reservation, error)
+	ResumeReservation(ctx context.Context, arg queries.ResumeReservationParams) (queries.Reservation, error)
+	MoveReservationInQueue(ctx context.Context, arg queries.MoveReservationInQueueParams) (queries.Reservation, error)
+	// Wait time estimates
+	ListOpenLoanDueDatesByBook(ctx context.Context, bookID int32) ([]pgtype.Timestamp, error)
+	ListHoldReadyTimesByBook(ctx context.Context, bookID int32) ([]pgtype.Timestamp, error)
+	GetBookReturnHistory(ctx context.Context, arg queries.GetBookReturnHistoryParams) (queries.GetBookReturnHistoryRow, error)
+	GetLibraryReturnHistory(ctx context.Context, returnedDate pgtype.Timestamp) (queries.GetLibraryReturnHistoryRow, error)
 }
 
 // ReservationService handles all business logic related to book reservations
@@ -35,6 +46,14 @@ type ReservationService struct {
 	queries                   ReservationQuerier
 	maxReservationsPerStudent int
 	defaultReservationDays    int
+	maxSuspensionDays         int
+	policies                  PolicyResolver
+	calendar                  LibraryCalendar
+	blocks                    BlockChecker
+	recaller                  LoanRecaller
+	holds                     HoldShelf
+	realtime                  RealtimePublisher
+	notifier                  StudentNotifier
 }
 
 // NewReservationService creates a new reservation service with default settings
@@ -43,6 +62,7 @@ func NewReservationService(queries ReservationQuerier) *ReservationService {
 		queries:                   queries,
 		maxReservationsPerStudent: 5, // Max 5 reservations per student
 		defaultReservationDays:    7, // Reservations expire after 7 days
+		maxSuspensionDays:         30,
 	}
 }
 
@@ -58,6 +78,65 @@ func (s *ReservationService) WithDefaultReservationDays(days int) *ReservationSe
 	return s
 }
 
+// WithMaxSuspensionDays sets how far ahead a student may suspend a reservation
+func (s *ReservationService) WithMaxSuspensionDays(days int) *ReservationService {
+	s.maxSuspensionDays = days
+	return s
+}
+
+// WithPolicyResolver resolves reservation limits from the circulation policy matrix;
+// the settings above remain the fallback when no rule matches
+func (s *ReservationService) WithPolicyResolver(policies PolicyResolver) *ReservationService {
+	s.policies = policies
+	return s
+}
+
+// WithCalendar makes reservations expire on a day the library is open
+func (s *ReservationService) WithCalendar(calendar LibraryCalendar) *ReservationService {
+	s.calendar = calendar
+	return s
+}
+
+// WithBlockChecker refuses reservations to students whose accounts are blocked
+func (s *ReservationService) WithBlockChecker(blocks BlockChecker) *ReservationService {
+	s.blocks = blocks
+	return s
+}
+
+// WithRecaller recalls a loan of the book whenever a new reservation is placed
+// and no recalled copy is already on its way back for it
+func (s *ReservationService) WithRecaller(recaller LoanRecaller) *ReservationService {
+	s.recaller = recaller
+	return s
+}
+
+// WithHoldShelf passes on the copy held for a reservation when it is cancelled
+// or left uncollected past its pickup deadline
+func (s *ReservationService) WithHoldShelf(holds HoldShelf) *ReservationService {
+	s.holds = holds
+	return s
+}
+
+// WithRealtime announces reservation status changes to the student and to the
+// librarians' screens
+func (s *ReservationService) WithRealtime(realtime RealtimePublisher) *ReservationService {
+	s.realtime = realtime
+	return s
+}
+
+// WithNotifier sends students the notices for expired reservations
+func (s *ReservationService) WithNotifier(notifier StudentNotifier) *ReservationService {
+	s.notifier = notifier
+	return s
+}
+
+// reservationChanged announces a reservation's new status
+func (s *ReservationService) reservationChanged(ctx context.Context, reservation queries.Reservation) {
+	if s.realtime != nil {
+		s.realtime.ReservationChanged(ctx, reservation)
+	}
+}
+
 // ReserveBookRequest represents a book reservation request
 type ReserveBookRequest struct {
 	StudentID int32 `json:"student_id" validate:"required"`
@@ -75,6 +154,16 @@ type ReservationResponse struct {
 	FulfilledAt *time.Time `json:"fulfilled_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
+	// Hold shelf fields, set once the reserved book is waiting to be collected
+	ReadyAt        *time.Time `json:"ready_at,omitempty"`
+	PickupDeadline *time.Time `json:"pickup_deadline,omitempty"`
+	CopyID         *int32     `json:"copy_id,omitempty"`
+	Barcode        string     `json:"barcode,omitempty"`
+	// Queue fields; the queue passes over a reservation until its suspension ends
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	Priority       int32      `json:"priority,omitempty"`
+	// When a reservation still in the queue is expected to be ready to collect
+	EstimatedWait *WaitEstimate `json:"estimated_wait,omitempty"`
 	// Additional fields for extended responses
 	StudentName   string `json:"student_name,omitempty"`
 	StudentIDCode string `json:"student_id_code,omitempty"`
@@ -104,23 +193,41 @@ func (s *ReservationService) ReserveBook(ctx context.Context, studentID, bookID
 		return nil, fmt.Errorf("failed to get book: %w", err)
 	}
 
+	policy, err := s.resolvePolicy(ctx, studentPolicyContext(student, book))
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate reservation eligibility
-	if err := s.validateReservationEligibility(ctx, student, book, studentID, bookID); err != nil {
+	if err := s.validateReservationEligibility(ctx, student, book, policy, studentID, bookID); err != nil {
 		return nil, err
 	}
 
 	// Calculate expiration date
-	expiresAt := time.Now().UTC().AddDate(0, 0, s.defaultReservationDays)
+	expiresAt := time.Now().UTC().AddDate(0, 0, policy.ReservationDays)
+	if s.calendar != nil {
+		expiresAt, err = s.calendar.NextOpenDay(ctx, expiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate reservation expiry: %w", err)
+		}
+	}
 
 	// Create reservation
 	reservation, err := s.queries.CreateReservation(ctx, queries.CreateReservationParams{
 		StudentID: studentID,
 		BookID:    bookID,
 		ExpiresAt: pgtype.Timestamp{Time: expiresAt, Valid: true},
+		Priority:  int32(policy.ReservationPriority),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create reservation: %w", err)
 	}
+	s.reservationChanged(ctx, reservation)
+
+	// The reservation stands even if no loan could be recalled; the recaller logs why
+	if s.recaller != nil {
+		_, _ = s.recaller.RecallForReservations(ctx, bookID)
+	}
 
 	// Get queue position
 	queuePosition, err := s.getQueuePosition(ctx, bookID, reservation.ID)
@@ -155,15 +262,26 @@ func (s *ReservationService) GetReservationByID(ctx context.Context, id int32) (
 	return &response, nil
 }
 
-// CancelReservation cancels a reservation
+// CancelReservation cancels a reservation. With a hold shelf, a copy waiting
+// for the reservation goes to the next student in the queue in the same
+// database transaction as the cancellation.
 func (s *ReservationService) CancelReservation(ctx context.Context, id int32) (*ReservationResponse, error) {
-	reservation, err := s.queries.CancelReservation(ctx, id)
+	var reservation queries.Reservation
+	var err error
+	if s.holds != nil {
+		reservation, err = s.holds.CancelReservation(ctx, id)
+	} else {
+		reservation, err = s.queries.CancelReservation(ctx, id)
+	}
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("reservation not found")
 		}
 		return nil, fmt.Errorf("failed to cancel reservation: %w", err)
 	}
+	if s.holds == nil {
+		s.reservationChanged(ctx, reservation)
+	}
 
 	return s.convertToReservationResponse(reservation, 0), nil
 }
@@ -182,10 +300,194 @@ func (s *ReservationService) FulfillReservation(ctx context.Context, reservation
 		}
 		return nil, fmt.Errorf("failed to fulfill reservation: %w", err)
 	}
+	s.reservationChanged(ctx, reservation)
 
 	return s.convertToReservationResponse(reservation, 0), nil
 }
 
+// SuspendReservation pauses an active reservation until the given time. It keeps
+// its place in the queue but is passed over until then, and its expiry is pushed
+// back by the time it spends suspended. A non-zero studentID restricts it to
+// that student's own reservations.
+func (s *ReservationService) SuspendReservation(ctx context.Context, id, studentID int32, until time.Time) (*ReservationResponse, error) {
+	reservation, err := s.queries.GetReservationByID(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("reservation not found")
+		}
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+
+	// Other students' reservations are reported as missing rather than forbidden
+	if studentID != 0 && reservation.StudentID != studentID {
+		return nil, fmt.Errorf("reservation not found")
+	}
+	if reservation.Status.String != models.ReservationStatusActive {
+		return nil, fmt.Errorf("only active reservations can be suspended")
+	}
+
+	now := time.Now().UTC()
+	until = until.UTC()
+	if !until.After(now) {
+		return nil, fmt.Errorf("suspension must end in the future")
+	}
+	if until.After(now.AddDate(0, 0, s.maxSuspensionDays)) {
+		return nil, fmt.Errorf("reservations cannot be suspended for more than %d days", s.maxSuspensionDays)
+	}
+
+	// Extending or shortening a suspension only moves the expiry by the difference
+	pausedFrom := now
+	if reservation.SuspendedUntil.Valid && reservation.SuspendedUntil.Time.After(now) {
+		pausedFrom = reservation.SuspendedUntil.Time
+	}
+
+	updated, err := s.queries.SuspendReservation(ctx, queries.SuspendReservationParams{
+		ID:             id,
+		SuspendedUntil: pgtype.Timestamp{Time: until, Valid: true},
+		ExpiresAt:      pgtype.Timestamp{Time: reservation.ExpiresAt.Time.Add(until.Sub(pausedFrom)), Valid: true},
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("only active reservations can be suspended")
+		}
+		return nil, fmt.Errorf("failed to suspend reservation: %w", err)
+	}
+
+	return s.queuedReservationResponse(ctx, updated)
+}
+
+// ResumeReservation ends a suspension early. The expiry is brought forward by the
+// suspended time that was not used. A non-zero studentID restricts it to that
+// student's own reservations.
+func (s *ReservationService) ResumeReservation(ctx context.Context, id, studentID int32) (*ReservationResponse, error) {
+	reservation, err := s.queries.GetReservationByID(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("reservation not found")
+		}
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+
+	// Other students' reservations are reported as missing rather than forbidden
+	if studentID != 0 && reservation.StudentID != studentID {
+		return nil, fmt.Errorf("reservation not found")
+	}
+
+	now := time.Now().UTC()
+	if reservation.Status.String != models.ReservationStatusActive ||
+		!reservation.SuspendedUntil.Valid || !reservation.SuspendedUntil.Time.After(now) {
+		return nil, fmt.Errorf("reservation is not suspended")
+	}
+
+	unused := reservation.SuspendedUntil.Time.Sub(now)
+	updated, err := s.queries.ResumeReservation(ctx, queries.ResumeReservationParams{
+		ID:        id,
+		ExpiresAt: pgtype.Timestamp{Time: reservation.ExpiresAt.Time.Add(-unused), Valid: true},
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("reservation is not suspended")
+		}
+		return nil, fmt.Errorf("failed to resume reservation: %w", err)
+	}
+
+	return s.queuedReservationResponse(ctx, updated)
+}
+
+// MoveReservation moves an active reservation to a position in its book's queue.
+// It takes the priority of the reservations around its new place, so it stays
+// there as new reservations arrive.
+func (s *ReservationService) MoveReservation(ctx context.Context, id int32, position int) (*ReservationResponse, error) {
+	if position < 1 {
+		return nil, fmt.Errorf("queue position must be at least 1")
+	}
+
+	reservation, err := s.queries.GetReservationByID(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("reservation not found")
+		}
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+	if reservation.Status.String != models.ReservationStatusActive {
+		return nil, fmt.Errorf("only active reservations can be moved in the queue")
+	}
+
+	queue, err := s.queries.ListReservationsByBook(ctx, reservation.BookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book reservations: %w", err)
+	}
+
+	priority, queuedAt, ok := queueSlot(queue, id, position)
+	if !ok {
+		return nil, fmt.Errorf("reservation not found in queue")
+	}
+
+	updated, err := s.queries.MoveReservationInQueue(ctx, queries.MoveReservationInQueueParams{
+		ID:       id,
+		Priority: priority,
+		QueuedAt: pgtype.Timestamp{Time: queuedAt, Valid: true},
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("only active reservations can be moved in the queue")
+		}
+		return nil, fmt.Errorf("failed to move reservation: %w", err)
+	}
+
+	return s.queuedReservationResponse(ctx, updated)
+}
+
+// queueSlot returns the priority and queue time that place a reservation at the
+// given position among the others in its queue, after them all if the position
+// is past the end. It reports false if the reservation is not in the queue.
+func queueSlot(queue []queries.ListReservationsByBookRow, id int32, position int) (int32, time.Time, bool) {
+	var moved *queries.ListReservationsByBookRow
+	others := make([]queries.ListReservationsByBookRow, 0, len(queue))
+	for i := range queue {
+		if queue[i].ID == id {
+			moved = &queue[i]
+			continue
+		}
+		others = append(others, queue[i])
+	}
+	if moved == nil {
+		return 0, time.Time{}, false
+	}
+
+	i := position - 1
+	if i > len(others) {
+		i = len(others)
+	}
+
+	switch {
+	case len(others) == 0:
+		return moved.Priority, moved.QueuedAt.Time, true
+	case i == len(others):
+		last := others[i-1]
+		return last.Priority, last.QueuedAt.Time.Add(time.Microsecond), true
+	}
+
+	next := others[i]
+	if i == 0 || others[i-1].Priority != next.Priority {
+		return next.Priority, next.QueuedAt.Time.Add(-time.Microsecond), true
+	}
+
+	// Queue times are stored to the microsecond
+	prev := others[i-1]
+	gap := next.QueuedAt.Time.Sub(prev.QueuedAt.Time)
+	return next.Priority, prev.QueuedAt.Time.Add(gap / 2).Truncate(time.Microsecond), true
+}
+
+// queuedReservationResponse converts a reservation still in the queue, with its position
+func (s *ReservationService) queuedReservationResponse(ctx context.Context, reservation queries.Reservation) (*ReservationResponse, error) {
+	queuePosition, err := s.getQueuePosition(ctx, reservation.BookID, reservation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate queue position: %w", err)
+	}
+	return s.convertToReservationResponse(reservation, queuePosition), nil
+}
+
 // GetStudentReservations retrieves all reservations for a student
 func (s *ReservationService) GetStudentReservations(ctx context.Context, studentID int32, limit, offset int32) ([]ReservationResponse, error) {
 	reservations, err := s.queries.ListReservationsByStudent(ctx, queries.ListReservationsByStudentParams{
@@ -198,8 +500,15 @@ func (s *ReservationService) GetStudentReservations(ctx context.Context, student
 	}
 
 	responses := make([]ReservationResponse, 0, len(reservations))
+	queues := make(map[int32]*bookQueue)
 	for _, reservation := range reservations {
-		responses = append(responses, s.convertToStudentReservationResponse(reservation))
+		response := s.convertToStudentReservationResponse(reservation)
+		if response.Status == models.ReservationStatusActive {
+			if err := s.estimateQueuedWait(ctx, &response, queues); err != nil {
+				return nil, err
+			}
+		}
+		responses = append(responses, response)
 	}
 
 	return responses, nil
@@ -212,10 +521,18 @@ func (s *ReservationService) GetBookReservations(ctx context.Context, bookID int
 		return nil, fmt.Errorf("failed to get book reservations: %w", err)
 	}
 
+	estimates, err := s.estimateWaits(ctx, bookID, reservations)
+	if err != nil {
+		return nil, err
+	}
+
 	responses := make([]ReservationResponse, 0, len(reservations))
 	for i, reservation := range reservations {
 		response := s.convertToBookReservationResponse(reservation)
 		response.QueuePosition = i + 1 // Position in queue (1-based)
+		if estimate, ok := estimates[reservation.ID]; ok {
+			response.EstimatedWait = &estimate
+		}
 		responses = append(responses, response)
 	}
 
@@ -246,7 +563,7 @@ func (s *ReservationService) ExpireReservations(ctx context.Context) (int, error
 
 	expiredCount := 0
 	for _, reservation := range expiredReservations {
-		_, err := s.queries.UpdateReservationStatus(ctx, queries.UpdateReservationStatusParams{
+		expired, err := s.queries.UpdateReservationStatus(ctx, queries.UpdateReservationStatusParams{
 			ID:          reservation.ID,
 			Status:      pgtype.Text{String: "expired", Valid: true},
 			FulfilledAt: pgtype.Timestamp{Valid: false},
@@ -254,12 +571,63 @@ func (s *ReservationService) ExpireReservations(ctx context.Context) (int, error
 		if err != nil {
 			return expiredCount, fmt.Errorf("failed to expire reservation %d: %w", reservation.ID, err)
 		}
+		s.reservationChanged(ctx, expired)
 		expiredCount++
+
+		notice, err := studentNotice(reservation.StudentID, models.NotificationTypeReservationExpired, models.NotificationPriorityMedium, reservation.Locale, map[string]interface{}{
+			"StudentName": reservation.FirstName + " " + reservation.LastName,
+			"BookTitle":   reservation.Title,
+			"BookAuthor":  reservation.Author,
+		})
+		if err != nil {
+			return expiredCount, err
+		}
+		sendStudentNotice(ctx, s.notifier, notice)
+	}
+
+	// Holds left uncollected past their pickup deadline pass to the next student
+	if s.holds != nil {
+		expiredHolds, err := s.holds.ExpireUncollectedHolds(ctx)
+		expiredCount += expiredHolds
+		if err != nil {
+			return expiredCount, err
+		}
 	}
 
 	return expiredCount, nil
 }
 
+// ListHoldShelf lists the reserved copies waiting to be collected, soonest pickup deadline first
+func (s *ReservationService) ListHoldShelf(ctx context.Context) ([]ReservationResponse, error) {
+	holds, err := s.queries.ListHoldShelf(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hold shelf: %w", err)
+	}
+
+	responses := make([]ReservationResponse, len(holds))
+	for i, hold := range holds {
+		responses[i] = ReservationResponse{
+			ID:            hold.ID,
+			StudentID:     hold.StudentID,
+			BookID:        hold.BookID,
+			ReservedAt:    hold.ReservedAt.Time,
+			ExpiresAt:     hold.ExpiresAt.Time,
+			Status:        hold.Status.String,
+			CreatedAt:     hold.CreatedAt.Time,
+			UpdatedAt:     hold.UpdatedAt.Time,
+			Barcode:       hold.Barcode.String,
+			StudentName:   hold.FirstName + " " + hold.LastName,
+			StudentIDCode: hold.StudentCode,
+			BookTitle:     hold.Title,
+			BookAuthor:    hold.Author,
+			BookIDCode:    hold.BookCode,
+		}
+		responses[i].setHold(hold.ReadyAt, hold.PickupDeadline, hold.CopyID)
+	}
+
+	return responses, nil
+}
+
 // GetAllReservations retrieves all reservations with pagination
 func (s *ReservationService) GetAllReservations(ctx context.Context, limit, offset int32) ([]ReservationResponse, error) {
 	reservations, err := s.queries.ListReservations(ctx, queries.ListReservationsParams{
@@ -279,16 +647,17 @@ func (s *ReservationService) GetAllReservations(ctx context.Context, limit, offs
 	return responses, nil
 }
 
-// HasStudentFulfilledReservation checks if a student has a fulfilled reservation for a book
-func (s *ReservationService) HasStudentFulfilledReservation(ctx context.Context, studentID, bookID int32) (*ReservationResponse, error) {
+// HasStudentReadyReservation returns the student's reservation for a book that is
+// waiting on the hold shelf, or nil if nothing is held for them
+func (s *ReservationService) HasStudentReadyReservation(ctx context.Context, studentID, bookID int32) (*ReservationResponse, error) {
 	reservationRow, err := s.queries.GetStudentReservationForBook(ctx, queries.GetStudentReservationForBookParams{
 		StudentID: studentID,
 		BookID:    bookID,
-		Status:    pgtype.Text{String: "fulfilled", Valid: true},
+		Status:    pgtype.Text{String: models.ReservationStatusReady, Valid: true},
 	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil // No fulfilled reservation found
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, nil // Nothing held for the student
 		}
 		return nil, fmt.Errorf("failed to get student reservation: %w", err)
 	}
@@ -310,17 +679,44 @@ func (s *ReservationService) HasStudentFulfilledReservation(ctx context.Context,
 	if reservationRow.FulfilledAt.Valid {
 		response.FulfilledAt = &reservationRow.FulfilledAt.Time
 	}
+	response.setHold(reservationRow.ReadyAt, reservationRow.PickupDeadline, reservationRow.CopyID)
 
 	return &response, nil
 }
 
+// resolvePolicy returns the circulation policy for a patron and item, falling
+// back to the service defaults when no resolver is set or no rule matches
+func (s *ReservationService) resolvePolicy(ctx context.Context, pc PolicyContext) (*CirculationPolicy, error) {
+	if s.policies != nil {
+		policy, err := s.policies.ResolvePolicy(ctx, pc)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			return policy, nil
+		}
+	}
+	return &CirculationPolicy{
+		Name:            "default",
+		MaxReservations: s.maxReservationsPerStudent,
+		ReservationDays: s.defaultReservationDays,
+	}, nil
+}
+
 // validateReservationEligibility performs comprehensive validation for reservation eligibility
-func (s *ReservationService) validateReservationEligibility(ctx context.Context, student queries.Student, book queries.Book, studentID, bookID int32) error {
+func (s *ReservationService) validateReservationEligibility(ctx context.Context, student queries.Student, book queries.Book, policy *CirculationPolicy, studentID, bookID int32) error {
 	// Check if student is active
 	if !student.IsActive.Bool {
 		return fmt.Errorf("student account is not active")
 	}
 
+	// Check for fines, overdue and librarian blocks on the account
+	if s.blocks != nil {
+		if err := s.blocks.CheckNotBlocked(ctx, studentID); err != nil {
+			return err
+		}
+	}
+
 	// Check if book is active
 	if !book.IsActive.Bool {
 		return fmt.Errorf("book is not active")
@@ -337,14 +733,14 @@ func (s *ReservationService) validateReservationEligibility(ctx context.Context,
 		return fmt.Errorf("failed to check student reservations: %w", err)
 	}
 
-	if reservationCount >= int64(s.maxReservationsPerStudent) {
-		return fmt.Errorf("student has reached the maximum number of reservations (%d)", s.maxReservationsPerStudent)
+	if reservationCount >= int64(policy.MaxReservations) {
+		return fmt.Errorf("student has reached the maximum number of reservations (%d)", policy.MaxReservations)
 	}
 
 	// Check if student already has this book reserved
 	studentReservations, err := s.queries.ListReservationsByStudent(ctx, queries.ListReservationsByStudentParams{
 		StudentID: studentID,
-		Limit:     int32(s.maxReservationsPerStudent),
+		Limit:     int32(policy.MaxReservations),
 		Offset:    0,
 	})
 	if err != nil {
@@ -352,7 +748,7 @@ func (s *ReservationService) validateReservationEligibility(ctx context.Context,
 	}
 
 	for _, reservation := range studentReservations {
-		if reservation.BookID == bookID && reservation.Status.String == "active" {
+		if reservation.BookID == bookID && (reservation.Status.String == models.ReservationStatusActive || reservation.Status.String == models.ReservationStatusReady) {
 			return fmt.Errorf("student already has this book reserved")
 		}
 	}
@@ -393,6 +789,8 @@ func (s *ReservationService) convertToReservationResponse(reservation queries.Re
 	if reservation.FulfilledAt.Valid {
 		response.FulfilledAt = &reservation.FulfilledAt.Time
 	}
+	response.setHold(reservation.ReadyAt, reservation.PickupDeadline, reservation.CopyID)
+	response.setQueue(reservation.Priority, reservation.SuspendedUntil)
 
 	return response
 }
@@ -419,6 +817,8 @@ func (s *ReservationService) convertToExtendedReservationResponse(reservation qu
 	if reservation.FulfilledAt.Valid {
 		response.FulfilledAt = &reservation.FulfilledAt.Time
 	}
+	response.setHold(reservation.ReadyAt, reservation.PickupDeadline, reservation.CopyID)
+	response.setQueue(reservation.Priority, reservation.SuspendedUntil)
 
 	return response
 }
@@ -442,6 +842,8 @@ func (s *ReservationService) convertToStudentReservationResponse(reservation que
 	if reservation.FulfilledAt.Valid {
 		response.FulfilledAt = &reservation.FulfilledAt.Time
 	}
+	response.setHold(reservation.ReadyAt, reservation.PickupDeadline, reservation.CopyID)
+	response.setQueue(reservation.Priority, reservation.SuspendedUntil)
 
 	return response
 }
@@ -464,6 +866,7 @@ func (s *ReservationService) convertToBookReservationResponse(reservation querie
 	if reservation.FulfilledAt.Valid {
 		response.FulfilledAt = &reservation.FulfilledAt.Time
 	}
+	response.setQueue(reservation.Priority, reservation.SuspendedUntil)
 
 	return response
 }
@@ -486,6 +889,7 @@ func (s *ReservationService) convertToNextReservationResponse(reservation querie
 	if reservation.FulfilledAt.Valid {
 		response.FulfilledAt = &reservation.FulfilledAt.Time
 	}
+	response.setQueue(reservation.Priority, reservation.SuspendedUntil)
 
 	return response
 }
@@ -511,6 +915,29 @@ func (s *ReservationService) convertToListReservationResponse(reservation querie
 	if reservation.FulfilledAt.Valid {
 		response.FulfilledAt = &reservation.FulfilledAt.Time
 	}
+	response.setHold(reservation.ReadyAt, reservation.PickupDeadline, reservation.CopyID)
+	response.setQueue(reservation.Priority, reservation.SuspendedUntil)
 
 	return response
 }
+
+// setHold fills in the hold shelf fields of a reservation that has been made ready
+func (r *ReservationResponse) setHold(readyAt, pickupDeadline pgtype.Timestamp, copyID pgtype.Int4) {
+	if readyAt.Valid {
+		r.ReadyAt = &readyAt.Time
+	}
+	if pickupDeadline.Valid {
+		r.PickupDeadline = &pickupDeadline.Time
+	}
+	if copyID.Valid {
+		r.CopyID = &copyID.Int32
+	}
+}
+
+// setQueue fills in a reservation's queue priority and any suspension still in force
+func (r *ReservationResponse) setQueue(priority int32, suspendedUntil pgtype.Timestamp) {
+	r.Priority = priority
+	if suspendedUntil.Valid && suspendedUntil.Time.After(time.Now()) {
+		r.SuspendedUntil = &suspendedUntil.Time
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
 )
 
 // MockReservationQuerier is a mock implementation of ReservationQuerier
@@ -79,6 +80,46 @@ func (m *MockReservationQuerier) GetStudentReservationForBook(ctx context.Contex
 	return args.Get(0).(queries.GetStudentReservationForBookRow), args.Error(1)
 }
 
+func (m *MockReservationQuerier) ListHoldShelf(ctx context.Context) ([]queries.ListHoldShelfRow, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]queries.ListHoldShelfRow), args.Error(1)
+}
+
+func (m *MockReservationQuerier) SuspendReservation(ctx context.Context, arg queries.SuspendReservationParams) (queries.Reservation, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Reservation), args.Error(1)
+}
+
+func (m *MockReservationQuerier) ResumeReservation(ctx context.Context, arg queries.ResumeReservationParams) (queries.Reservation, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Reservation), args.Error(1)
+}
+
+func (m *MockReservationQuerier) MoveReservationInQueue(ctx context.Context, arg queries.MoveReservationInQueueParams) (queries.Reservation, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Reservation), args.Error(1)
+}
+
+func (m *MockReservationQuerier) ListOpenLoanDueDatesByBook(ctx context.Context, bookID int32) ([]pgtype.Timestamp, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).([]pgtype.Timestamp), args.Error(1)
+}
+
+func (m *MockReservationQuerier) ListHoldReadyTimesByBook(ctx context.Context, bookID int32) ([]pgtype.Timestamp, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).([]pgtype.Timestamp), args.Error(1)
+}
+
+func (m *MockReservationQuerier) GetBookReturnHistory(ctx context.Context, arg queries.GetBookReturnHistoryParams) (queries.GetBookReturnHistoryRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.GetBookReturnHistoryRow), args.Error(1)
+}
+
+func (m *MockReservationQuerier) GetLibraryReturnHistory(ctx context.Context, returnedDate pgtype.Timestamp) (queries.GetLibraryReturnHistoryRow, error) {
+	args := m.Called(ctx, returnedDate)
+	return args.Get(0).(queries.GetLibraryReturnHistoryRow), args.Error(1)
+}
+
 func (m *MockReservationQuerier) CancelReservation(ctx context.Context, id int32) (queries.Reservation, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(queries.Reservation), args.Error(1)
@@ -342,6 +383,44 @@ func TestReservationService_ReserveBook_MaxReservationsReached(t *testing.T) {
 	mockQuerier.AssertExpectations(t)
 }
 
+func TestReservationService_ReserveBook_PolicyReservationLimit(t *testing.T) {
+	mockQuerier := &MockReservationQuerier{}
+	mockPolicies := &MockPolicyResolver{}
+	service := NewReservationService(mockQuerier).WithPolicyResolver(mockPolicies)
+
+	studentID := int32(1)
+	bookID := int32(2)
+	ctx := context.Background()
+
+	student := queries.Student{
+		ID:          studentID,
+		YearOfStudy: 2,
+		IsActive:    pgtype.Bool{Bool: true, Valid: true},
+	}
+
+	book := queries.Book{
+		ID:              bookID,
+		IsActive:        pgtype.Bool{Bool: true, Valid: true},
+		AvailableCopies: pgtype.Int4{Int32: 0, Valid: true},
+	}
+
+	mockQuerier.On("GetStudentByID", ctx, studentID).Return(student, nil)
+	mockQuerier.On("GetBookByID", ctx, bookID).Return(book, nil)
+	mockQuerier.On("CountActiveReservationsByStudent", ctx, studentID).Return(int64(2), nil)
+	mockPolicies.On("ResolvePolicy", ctx, PolicyContext{
+		YearOfStudy: 2,
+		UserType:    models.PatronTypeStudent,
+	}).Return(&CirculationPolicy{ID: 3, MaxReservations: 2, ReservationDays: 3}, nil)
+
+	result, err := service.ReserveBook(ctx, studentID, bookID)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "maximum number of reservations (2)")
+	mockQuerier.AssertExpectations(t)
+	mockPolicies.AssertExpectations(t)
+}
+
 func TestReservationService_ReserveBook_DuplicateReservation(t *testing.T) {
 	mockQuerier := &MockReservationQuerier{}
 	service := NewReservationService(mockQuerier)
@@ -579,7 +658,8 @@ func TestReservationService_GetNextReservationForBook_NoReservations(t *testing.
 
 func TestReservationService_ExpireReservations_Success(t *testing.T) {
 	mockQuerier := &MockReservationQuerier{}
-	service := NewReservationService(mockQuerier)
+	notifier := &MockStudentNotifier{}
+	service := NewReservationService(mockQuerier).WithNotifier(notifier)
 
 	ctx := context.Background()
 
@@ -595,6 +675,11 @@ func TestReservationService_ExpireReservations_Success(t *testing.T) {
 			StudentID: 2,
 			BookID:    3,
 			Status:    pgtype.Text{String: "active", Valid: true},
+			FirstName: "Jane",
+			LastName:  "Roe",
+			Title:     "Dune",
+			Author:    "Frank Herbert",
+			Locale:    "sw",
 		},
 	}
 
@@ -607,12 +692,21 @@ func TestReservationService_ExpireReservations_Success(t *testing.T) {
 
 	mockQuerier.On("ListExpiredReservations", ctx).Return(expiredReservations, nil)
 	mockQuerier.On("UpdateReservationStatus", ctx, mock.AnythingOfType("queries.UpdateReservationStatusParams")).Return(reservation, nil).Times(2)
+	notifier.On("CreateNotification", ctx, mock.MatchedBy(func(req *models.NotificationRequest) bool {
+		return req.RecipientID == 1 && req.Type == models.NotificationTypeReservationExpired && req.Locale == "en" &&
+			assert.ObjectsAreEqual(emailAndSMS, req.Channels)
+	})).Return(&models.NotificationResponse{ID: 10}, nil).Once()
+	notifier.On("CreateNotification", ctx, mock.MatchedBy(func(req *models.NotificationRequest) bool {
+		return req.RecipientID == 2 && req.Type == models.NotificationTypeReservationExpired && req.Locale == "sw" &&
+			req.Title == "Muda wa Uhifadhi Umekwisha - Dune"
+	})).Return(&models.NotificationResponse{ID: 11}, nil).Once()
 
 	count, err := service.ExpireReservations(ctx)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 2, count)
 	mockQuerier.AssertExpectations(t)
+	notifier.AssertExpectations(t)
 }
 
 func TestReservationService_ExpireReservations_NoExpiredReservations(t *testing.T) {
@@ -654,6 +748,11 @@ func TestReservationService_GetStudentReservations_Success(t *testing.T) {
 	}
 
 	mockQuerier.On("ListReservationsByStudent", ctx, mock.AnythingOfType("queries.ListReservationsByStudentParams")).Return(reservations, nil)
+	mockQuerier.On("ListReservationsByBook", ctx, int32(2)).Return([]queries.ListReservationsByBookRow{
+		{ID: 6, StudentID: 4, BookID: 2},
+		{ID: 1, StudentID: studentID, BookID: 2},
+	}, nil)
+	expectWaitEstimate(mockQuerier, ctx, 2, time.Now().AddDate(0, 0, 5))
 
 	results, err := service.GetStudentReservations(ctx, studentID, 10, 0)
 
@@ -661,6 +760,41 @@ func TestReservationService_GetStudentReservations_Success(t *testing.T) {
 	assert.Len(t, results, 1)
 	assert.Equal(t, int32(1), results[0].ID)
 	assert.Equal(t, "Test Book", results[0].BookTitle)
+	assert.Equal(t, 2, results[0].QueuePosition)
+	assert.NotNil(t, results[0].EstimatedWait)
+	mockQuerier.AssertExpectations(t)
+}
+
+func TestReservationService_GetStudentReservations_EstimatesEachBookOnce(t *testing.T) {
+	mockQuerier := &MockReservationQuerier{}
+	service := NewReservationService(mockQuerier)
+
+	studentID := int32(1)
+	ctx := context.Background()
+
+	active := pgtype.Text{String: "active", Valid: true}
+	reservations := []queries.ListReservationsByStudentRow{
+		{ID: 1, StudentID: studentID, BookID: 2, Status: active, Title: "Test Book"},
+		{ID: 3, StudentID: studentID, BookID: 2, Status: active, Title: "Test Book"},
+	}
+
+	mockQuerier.On("ListReservationsByStudent", ctx, mock.AnythingOfType("queries.ListReservationsByStudentParams")).Return(reservations, nil)
+	mockQuerier.On("ListReservationsByBook", ctx, int32(2)).Return([]queries.ListReservationsByBookRow{
+		{ID: 1, StudentID: studentID, BookID: 2},
+		{ID: 6, StudentID: 4, BookID: 2},
+		{ID: 3, StudentID: studentID, BookID: 2},
+	}, nil).Once()
+	expectWaitEstimate(mockQuerier, ctx, 2, time.Now().AddDate(0, 0, 5))
+
+	results, err := service.GetStudentReservations(ctx, studentID, 10, 0)
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, 1, results[0].QueuePosition)
+	assert.Equal(t, 3, results[1].QueuePosition)
+	assert.NotNil(t, results[1].EstimatedWait)
+	mockQuerier.AssertNumberOfCalls(t, "ListReservationsByBook", 1)
+	mockQuerier.AssertNumberOfCalls(t, "GetBookByID", 1)
 	mockQuerier.AssertExpectations(t)
 }
 
@@ -701,6 +835,7 @@ func TestReservationService_GetBookReservations_Success(t *testing.T) {
 	}
 
 	mockQuerier.On("ListReservationsByBook", ctx, bookID).Return(reservations, nil)
+	expectWaitEstimate(mockQuerier, ctx, bookID, time.Now().AddDate(0, 0, 5))
 
 	results, err := service.GetBookReservations(ctx, bookID)
 
@@ -712,6 +847,9 @@ func TestReservationService_GetBookReservations_Success(t *testing.T) {
 	assert.Equal(t, int32(2), results[1].ID)
 	assert.Equal(t, "Jane Smith", results[1].StudentName)
 	assert.Equal(t, 2, results[1].QueuePosition)
+	if assert.NotNil(t, results[0].EstimatedWait) && assert.NotNil(t, results[1].EstimatedWait) {
+		assert.True(t, results[1].EstimatedWait.ExpectedAt.After(results[0].EstimatedWait.ExpectedAt))
+	}
 	mockQuerier.AssertExpectations(t)
 }
 
@@ -813,3 +951,265 @@ func TestReservationService_QueuePositionNotFound(t *testing.T) {
 	assert.Contains(t, err.Error(), "reservation not found in queue")
 	mockQuerier.AssertExpectations(t)
 }
+
+func TestReservationService_ReserveBook_PolicyPriority(t *testing.T) {
+	mockQuerier := &MockReservationQuerier{}
+	mockPolicies := &MockPolicyResolver{}
+	service := NewReservationService(mockQuerier).WithPolicyResolver(mockPolicies)
+
+	ctx := context.Background()
+	student := queries.Student{ID: 1, YearOfStudy: 4, IsActive: pgtype.Bool{Bool: true, Valid: true}}
+	book := queries.Book{
+		ID:              2,
+		Genre:           pgtype.Text{String: "Thesis", Valid: true},
+		IsActive:        pgtype.Bool{Bool: true, Valid: true},
+		AvailableCopies: pgtype.Int4{Int32: 0, Valid: true},
+	}
+	reservation := queries.Reservation{ID: 5, StudentID: 1, BookID: 2, Priority: 10, Status: pgtype.Text{String: "active", Valid: true}}
+
+	mockQuerier.On("GetStudentByID", ctx, int32(1)).Return(student, nil)
+	mockQuerier.On("GetBookByID", ctx, int32(2)).Return(book, nil)
+	mockPolicies.On("ResolvePolicy", ctx, PolicyContext{
+		YearOfStudy: 4,
+		UserType:    models.PatronTypeStudent,
+		ItemType:    "Thesis",
+	}).Return(&CirculationPolicy{ID: 6, MaxReservations: 5, ReservationDays: 7, ReservationPriority: 10}, nil)
+	mockQuerier.On("CountActiveReservationsByStudent", ctx, int32(1)).Return(int64(0), nil)
+	mockQuerier.On("ListReservationsByStudent", ctx, mock.AnythingOfType("queries.ListReservationsByStudentParams")).Return([]queries.ListReservationsByStudentRow{}, nil)
+	mockQuerier.On("CreateReservation", ctx, mock.MatchedBy(func(arg queries.CreateReservationParams) bool {
+		return arg.Priority == 10
+	})).Return(reservation, nil)
+	mockQuerier.On("ListReservationsByBook", ctx, int32(2)).Return([]queries.ListReservationsByBookRow{
+		{ID: 5, StudentID: 1, BookID: 2, Priority: 10},
+		{ID: 3, StudentID: 7, BookID: 2},
+	}, nil)
+
+	result, err := service.ReserveBook(ctx, 1, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(10), result.Priority)
+	assert.Equal(t, 1, result.QueuePosition, "the higher priority reservation goes ahead of the earlier one")
+	mockQuerier.AssertExpectations(t)
+}
+
+func TestReservationService_SuspendReservation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	expires := now.AddDate(0, 0, 5)
+
+	activeRow := func() queries.GetReservationByIDRow {
+		return queries.GetReservationByIDRow{
+			ID:        1,
+			StudentID: 1,
+			BookID:    2,
+			Status:    pgtype.Text{String: "active", Valid: true},
+			ExpiresAt: pgtype.Timestamp{Time: expires, Valid: true},
+		}
+	}
+
+	t.Run("PushesExpiryBack", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier)
+		until := now.AddDate(0, 0, 10)
+
+		mockQuerier.On("GetReservationByID", ctx, int32(1)).Return(activeRow(), nil)
+		mockQuerier.On("SuspendReservation", ctx, mock.MatchedBy(func(arg queries.SuspendReservationParams) bool {
+			extension := arg.ExpiresAt.Time.Sub(expires)
+			return arg.SuspendedUntil.Time.Equal(until) &&
+				extension > 10*24*time.Hour-time.Minute && extension <= 10*24*time.Hour
+		})).Return(queries.Reservation{
+			ID:             1,
+			BookID:         2,
+			Status:         pgtype.Text{String: "active", Valid: true},
+			SuspendedUntil: pgtype.Timestamp{Time: until, Valid: true},
+		}, nil)
+		mockQuerier.On("ListReservationsByBook", ctx, int32(2)).Return([]queries.ListReservationsByBookRow{
+			{ID: 4, BookID: 2},
+			{ID: 1, BookID: 2},
+		}, nil)
+
+		result, err := service.SuspendReservation(ctx, 1, 0, until)
+
+		assert.NoError(t, err)
+		if assert.NotNil(t, result.SuspendedUntil) {
+			assert.True(t, until.Equal(*result.SuspendedUntil))
+		}
+		assert.Equal(t, 2, result.QueuePosition, "a suspended reservation keeps its place")
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("TooLong", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier).WithMaxSuspensionDays(14)
+
+		mockQuerier.On("GetReservationByID", ctx, int32(1)).Return(activeRow(), nil)
+
+		_, err := service.SuspendReservation(ctx, 1, 0, now.AddDate(0, 0, 15))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "more than 14 days")
+	})
+
+	t.Run("OtherStudentsReservation", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier)
+
+		mockQuerier.On("GetReservationByID", ctx, int32(1)).Return(activeRow(), nil)
+
+		_, err := service.SuspendReservation(ctx, 1, 2, now.AddDate(0, 0, 3))
+
+		assert.Error(t, err)
+		assert.Equal(t, "reservation not found", err.Error())
+		mockQuerier.AssertNotCalled(t, "SuspendReservation", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ReadyReservation", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier)
+
+		ready := activeRow()
+		ready.Status = pgtype.Text{String: "ready", Valid: true}
+		mockQuerier.On("GetReservationByID", ctx, int32(1)).Return(ready, nil)
+
+		_, err := service.SuspendReservation(ctx, 1, 0, now.AddDate(0, 0, 3))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "only active reservations can be suspended")
+	})
+}
+
+func TestReservationService_ResumeReservation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	expires := now.AddDate(0, 0, 15)
+
+	t.Run("GivesBackUnusedTime", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier)
+
+		mockQuerier.On("GetReservationByID", ctx, int32(1)).Return(queries.GetReservationByIDRow{
+			ID:             1,
+			BookID:         2,
+			Status:         pgtype.Text{String: "active", Valid: true},
+			ExpiresAt:      pgtype.Timestamp{Time: expires, Valid: true},
+			SuspendedUntil: pgtype.Timestamp{Time: now.AddDate(0, 0, 4), Valid: true},
+		}, nil)
+		mockQuerier.On("ResumeReservation", ctx, mock.MatchedBy(func(arg queries.ResumeReservationParams) bool {
+			given := expires.Sub(arg.ExpiresAt.Time)
+			return given > 4*24*time.Hour-time.Minute && given <= 4*24*time.Hour
+		})).Return(queries.Reservation{ID: 1, BookID: 2, Status: pgtype.Text{String: "active", Valid: true}}, nil)
+		mockQuerier.On("ListReservationsByBook", ctx, int32(2)).Return([]queries.ListReservationsByBookRow{{ID: 1, BookID: 2}}, nil)
+
+		result, err := service.ResumeReservation(ctx, 1, 0)
+
+		assert.NoError(t, err)
+		assert.Nil(t, result.SuspendedUntil)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("OtherStudentsReservation", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier)
+
+		mockQuerier.On("GetReservationByID", ctx, int32(1)).Return(queries.GetReservationByIDRow{
+			ID:             1,
+			StudentID:      1,
+			Status:         pgtype.Text{String: "active", Valid: true},
+			SuspendedUntil: pgtype.Timestamp{Time: now.AddDate(0, 0, 4), Valid: true},
+		}, nil)
+
+		_, err := service.ResumeReservation(ctx, 1, 2)
+
+		assert.Error(t, err)
+		assert.Equal(t, "reservation not found", err.Error())
+		mockQuerier.AssertNotCalled(t, "ResumeReservation", mock.Anything, mock.Anything)
+	})
+
+	t.Run("SuspensionAlreadyEnded", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier)
+
+		mockQuerier.On("GetReservationByID", ctx, int32(1)).Return(queries.GetReservationByIDRow{
+			ID:             1,
+			Status:         pgtype.Text{String: "active", Valid: true},
+			SuspendedUntil: pgtype.Timestamp{Time: now.AddDate(0, 0, -1), Valid: true},
+		}, nil)
+
+		_, err := service.ResumeReservation(ctx, 1, 0)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "reservation is not suspended")
+	})
+}
+
+func TestReservationService_MoveReservation(t *testing.T) {
+	ctx := context.Background()
+	mockQuerier := &MockReservationQuerier{}
+	service := NewReservationService(mockQuerier)
+
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	queue := []queries.ListReservationsByBookRow{
+		{ID: 1, BookID: 2, Priority: 10, QueuedAt: pgtype.Timestamp{Time: base.Add(3 * time.Hour), Valid: true}},
+		{ID: 2, BookID: 2, QueuedAt: pgtype.Timestamp{Time: base, Valid: true}},
+		{ID: 3, BookID: 2, QueuedAt: pgtype.Timestamp{Time: base.Add(time.Hour), Valid: true}},
+	}
+
+	mockQuerier.On("GetReservationByID", ctx, int32(3)).Return(queries.GetReservationByIDRow{
+		ID:     3,
+		BookID: 2,
+		Status: pgtype.Text{String: "active", Valid: true},
+	}, nil)
+	mockQuerier.On("ListReservationsByBook", ctx, int32(2)).Return(queue, nil).Once()
+	mockQuerier.On("MoveReservationInQueue", ctx, queries.MoveReservationInQueueParams{
+		ID:       3,
+		Priority: 10,
+		QueuedAt: pgtype.Timestamp{Time: base.Add(3*time.Hour - time.Microsecond), Valid: true},
+	}).Return(queries.Reservation{ID: 3, BookID: 2, Priority: 10, Status: pgtype.Text{String: "active", Valid: true}}, nil)
+	mockQuerier.On("ListReservationsByBook", ctx, int32(2)).Return([]queries.ListReservationsByBookRow{queue[2], queue[0], queue[1]}, nil).Once()
+
+	result, err := service.MoveReservation(ctx, 3, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.QueuePosition)
+	assert.Equal(t, int32(10), result.Priority)
+	mockQuerier.AssertExpectations(t)
+}
+
+func TestQueueSlot(t *testing.T) {
+	base := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) pgtype.Timestamp {
+		return pgtype.Timestamp{Time: base.Add(d), Valid: true}
+	}
+	queue := []queries.ListReservationsByBookRow{
+		{ID: 1, Priority: 5, QueuedAt: at(0)},
+		{ID: 2, QueuedAt: at(time.Hour)},
+		{ID: 3, QueuedAt: at(3 * time.Hour)},
+		{ID: 4, QueuedAt: at(4 * time.Hour)},
+	}
+
+	testCases := []struct {
+		name         string
+		id           int32
+		position     int
+		wantPriority int32
+		wantQueuedAt time.Time
+	}{
+		{"BetweenSamePriority", 4, 3, 0, base.Add(2 * time.Hour)},
+		{"FrontOfPriorityBand", 4, 2, 0, base.Add(time.Hour - time.Microsecond)},
+		{"FrontOfQueue", 3, 1, 5, base.Add(-time.Microsecond)},
+		{"PastTheEnd", 1, 9, 0, base.Add(4*time.Hour + time.Microsecond)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			priority, queuedAt, ok := queueSlot(queue, tc.id, tc.position)
+
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantPriority, priority)
+			assert.True(t, tc.wantQueuedAt.Equal(queuedAt), "got %s", queuedAt)
+		})
+	}
+
+	_, _, ok := queueSlot(queue, 99, 1)
+	assert.False(t, ok)
+}
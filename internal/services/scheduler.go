@@ -0,0 +1,632 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// Names of the jobs run by the scheduler
+const (
+	DueSoonRemindersJobName       = "due_soon_reminders"
+	OverdueRemindersJobName       = "overdue_reminders"
+	FineNoticesJobName            = "fine_notices"
+	ExpireReservationsJobName     = "expire_reservations"
+	CleanupNotificationsJobName   = "cleanup_notifications"
+	ScheduledNotificationsJobName = "scheduled_notifications"
+	PublishLiveCountersJobName    = "publish_live_counters"
+	DailyDigestsJobName           = "daily_digests"
+	WeeklyDigestsJobName          = "weekly_digests"
+	ProcessBouncesJobName         = "process_bounces"
+)
+
+const (
+	schedulerLeaderKey         = "scheduler:leader"
+	schedulerJobLockPrefix     = "scheduler:job:"
+	defaultSchedulerLeaderTTL  = 30 * time.Second
+	defaultScheduledJobTimeout = 30 * time.Minute
+	// scheduledJobLockMargin keeps a job's run lock a little past its timeout so the
+	// run can record its outcome before another instance may start it again
+	scheduledJobLockMargin = time.Minute
+)
+
+// Run trigger types recorded in job_runs
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+var (
+	// ErrJobNotFound is returned for a job the scheduler does not know
+	ErrJobNotFound = errors.New("job not found")
+	// ErrSchedulerStopped is returned when a job is triggered during shutdown
+	ErrSchedulerStopped = errors.New("scheduler is shutting down")
+)
+
+// JobLocker hands out locks shared by every server instance. Locks expire after
+// their TTL so an instance that dies cannot hold one for ever.
+type JobLocker interface {
+	// Acquire takes the lock for owner, or extends it if owner already holds it.
+	// It reports false if someone else holds the lock.
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	// Release gives up the lock if owner still holds it
+	Release(ctx context.Context, key, owner string) error
+}
+
+// JobSchedulerQuerier defines the queries needed to pause jobs and keep their run history
+type JobSchedulerQuerier interface {
+	GetBackgroundJob(ctx context.Context, name string) (queries.BackgroundJob, error)
+	ListBackgroundJobs(ctx context.Context) ([]queries.BackgroundJob, error)
+	SetBackgroundJobPaused(ctx context.Context, arg queries.SetBackgroundJobPausedParams) (queries.BackgroundJob, error)
+	CreateJobRun(ctx context.Context, arg queries.CreateJobRunParams) (queries.JobRun, error)
+	FinishJobRun(ctx context.Context, arg queries.FinishJobRunParams) (queries.JobRun, error)
+	ListJobRuns(ctx context.Context, arg queries.ListJobRunsParams) ([]queries.JobRun, error)
+	ListLatestJobRuns(ctx context.Context) ([]queries.JobRun, error)
+}
+
+// JobSchedulerInterface defines the admin operations on scheduled jobs
+type JobSchedulerInterface interface {
+	ListJobs(ctx context.Context) ([]models.ScheduledJobResponse, error)
+	ListRuns(ctx context.Context, name string, limit, offset int32) ([]models.JobRunResponse, error)
+	Trigger(ctx context.Context, name string, userID int32) (*models.JobRunResponse, error)
+	Pause(ctx context.Context, name string, userID int32) (*models.ScheduledJobResponse, error)
+	Resume(ctx context.Context, name string) (*models.ScheduledJobResponse, error)
+}
+
+// ScheduledJob is a job for the scheduler to run
+type ScheduledJob struct {
+	Name string
+	// Schedule is a cron expression; an empty schedule leaves the job to be triggered by hand
+	Schedule string
+	// Timeout bounds a run; it defaults to 30 minutes
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+type registeredJob struct {
+	ScheduledJob
+	schedule *CronSchedule
+	nextRun  time.Time
+}
+
+// JobScheduler runs jobs on cron schedules. Every instance runs the scheduler but
+// only the one holding the leader lock fires schedules; a per-job run lock also
+// stops a manual run overlapping a scheduled one anywhere in the cluster. Runs
+// missed while no instance was leader are skipped rather than caught up.
+type JobScheduler struct {
+	queries    JobSchedulerQuerier
+	locker     JobLocker
+	logger     *slog.Logger
+	instanceID string
+	leaderTTL  time.Duration
+
+	mu       sync.Mutex
+	jobs     map[string]*registeredJob
+	leader   bool
+	stopping bool
+
+	// runCtx is the parent of every run; cancelling it aborts runs still going at shutdown
+	runCtx     context.Context
+	cancelRuns context.CancelFunc
+	runs       sync.WaitGroup
+	stop       chan struct{}
+	loopDone   chan struct{}
+}
+
+// NewJobScheduler creates a job scheduler. Jobs are added with Register and
+// schedules start firing once Start is called.
+func NewJobScheduler(querier JobSchedulerQuerier, locker JobLocker, logger *slog.Logger) *JobScheduler {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	runCtx, cancelRuns := context.WithCancel(context.Background())
+	return &JobScheduler{
+		queries:    querier,
+		locker:     locker,
+		logger:     logger,
+		instanceID: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		leaderTTL:  defaultSchedulerLeaderTTL,
+		jobs:       make(map[string]*registeredJob),
+		runCtx:     runCtx,
+		cancelRuns: cancelRuns,
+	}
+}
+
+// WithInstanceID sets the name this server records when it leads or runs a job
+func (s *JobScheduler) WithInstanceID(instanceID string) *JobScheduler {
+	s.instanceID = instanceID
+	return s
+}
+
+// WithLeaderTTL sets how long leadership lasts without being renewed, and so how
+// long schedules stop firing after the leader dies
+func (s *JobScheduler) WithLeaderTTL(ttl time.Duration) *JobScheduler {
+	s.leaderTTL = ttl
+	return s
+}
+
+// Register adds a job to the scheduler
+func (s *JobScheduler) Register(job ScheduledJob) error {
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("job must have a name and a run function")
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = defaultScheduledJobTimeout
+	}
+
+	registered := &registeredJob{ScheduledJob: job}
+	if job.Schedule != "" {
+		schedule, err := ParseCron(job.Schedule)
+		if err != nil {
+			return fmt.Errorf("invalid schedule for job %s: %w", job.Name, err)
+		}
+		registered.schedule = schedule
+		registered.nextRun = schedule.Next(time.Now())
+		if registered.nextRun.IsZero() {
+			return fmt.Errorf("schedule %q for job %s never fires", job.Schedule, job.Name)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+	s.jobs[job.Name] = registered
+	return nil
+}
+
+// Start begins firing schedules in the background until Shutdown is called
+func (s *JobScheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil || s.stopping {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.loopDone = make(chan struct{})
+	go s.loop()
+}
+
+func (s *JobScheduler) loop() {
+	defer close(s.loopDone)
+
+	for {
+		s.campaign()
+		s.fireDue(time.Now())
+
+		// Renew leadership well before it lapses and wake for the next due job
+		wait := s.leaderTTL / 3
+		if next := s.nextDue(); !next.IsZero() && time.Until(next) < wait {
+			wait = max(time.Until(next), 0)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// campaign takes or renews leadership
+func (s *JobScheduler) campaign() {
+	ctx, cancel := context.WithTimeout(s.runCtx, s.leaderTTL/3)
+	defer cancel()
+
+	leader, err := s.locker.Acquire(ctx, schedulerLeaderKey, s.instanceID, s.leaderTTL)
+	if err != nil {
+		// Without Redis nobody can tell who leads, so stand down
+		s.logger.Error("Failed to renew scheduler leadership", "error", err)
+		leader = false
+	}
+
+	s.mu.Lock()
+	if leader != s.leader {
+		s.logger.Info("Scheduler leadership changed", "instance_id", s.instanceID, "leader", leader)
+	}
+	s.leader = leader
+	s.mu.Unlock()
+}
+
+// resign gives up leadership so another instance takes over without waiting for it to lapse
+func (s *JobScheduler) resign() {
+	s.mu.Lock()
+	leader := s.leader
+	s.leader = false
+	s.mu.Unlock()
+	if !leader {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobFinishTimeout)
+	defer cancel()
+	if err := s.locker.Release(ctx, schedulerLeaderKey, s.instanceID); err != nil {
+		s.logger.Error("Failed to give up scheduler leadership", "error", err)
+	}
+}
+
+// fireDue starts every job whose schedule has come round. Followers move their
+// schedules on too so they do not fire a backlog if they become leader.
+func (s *JobScheduler) fireDue(now time.Time) {
+	s.mu.Lock()
+	leader := s.leader
+	var due []*registeredJob
+	for _, job := range s.jobs {
+		if job.schedule == nil || job.nextRun.After(now) {
+			continue
+		}
+		job.nextRun = job.schedule.Next(now)
+		due = append(due, job)
+	}
+	s.mu.Unlock()
+
+	if !leader {
+		return
+	}
+
+	for _, job := range due {
+		paused, err := s.isPaused(s.runCtx, job.Name)
+		if err != nil {
+			s.logger.Error("Failed to check whether job is paused", "job", job.Name, "error", err)
+			continue
+		}
+		if paused {
+			s.logger.Info("Scheduled job skipped, it is paused", "job", job.Name)
+			continue
+		}
+
+		if _, err := s.launch(s.runCtx, job, JobTriggerSchedule, pgtype.Int4{}); err != nil {
+			if errors.Is(err, ErrJobAlreadyRunning) {
+				s.logger.Info("Scheduled job skipped, it is still running", "job", job.Name)
+				continue
+			}
+			s.logger.Error("Failed to start scheduled job", "job", job.Name, "error", err)
+		}
+	}
+}
+
+func (s *JobScheduler) nextDue() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, job := range s.jobs {
+		if job.schedule != nil && (next.IsZero() || job.nextRun.Before(next)) {
+			next = job.nextRun
+		}
+	}
+	return next
+}
+
+func (s *JobScheduler) isPaused(ctx context.Context, name string) (bool, error) {
+	job, err := s.queries.GetBackgroundJob(ctx, name)
+	if err != nil {
+		// The job has never run or been paused
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return job.PausedAt.Valid, nil
+}
+
+// launch takes the job's run lock, records the run and starts it in the background
+func (s *JobScheduler) launch(ctx context.Context, job *registeredJob, trigger string, triggeredBy pgtype.Int4) (queries.JobRun, error) {
+	// Counted under the lock so Shutdown never stops waiting before a run it let start
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		return queries.JobRun{}, ErrSchedulerStopped
+	}
+	s.runs.Add(1)
+	s.mu.Unlock()
+
+	lockKey := schedulerJobLockPrefix + job.Name
+	locked, err := s.locker.Acquire(ctx, lockKey, s.instanceID, job.Timeout+scheduledJobLockMargin)
+	if err != nil {
+		s.runs.Done()
+		return queries.JobRun{}, fmt.Errorf("failed to lock job: %w", err)
+	}
+	if !locked {
+		s.runs.Done()
+		return queries.JobRun{}, ErrJobAlreadyRunning
+	}
+
+	run, err := s.queries.CreateJobRun(ctx, queries.CreateJobRunParams{
+		JobName:     job.Name,
+		TriggerType: trigger,
+		TriggeredBy: triggeredBy,
+		InstanceID:  s.instanceID,
+	})
+	if err != nil {
+		s.unlock(lockKey)
+		s.runs.Done()
+		return queries.JobRun{}, fmt.Errorf("failed to record job run: %w", err)
+	}
+
+	go func() {
+		defer s.runs.Done()
+		defer s.unlock(lockKey)
+
+		started := time.Now()
+		s.logger.Info("Job started", "job", job.Name, "run_id", run.ID, "trigger", trigger)
+		runErr := s.execute(job)
+		s.finishRun(run, started, runErr)
+	}()
+
+	return run, nil
+}
+
+// execute runs the job within its timeout, turning a panic into an error
+func (s *JobScheduler) execute(job *registeredJob) (err error) {
+	ctx, cancel := context.WithTimeout(s.runCtx, job.Timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// finishRun records how a run went
+func (s *JobScheduler) finishRun(run queries.JobRun, started time.Time, runErr error) {
+	finished := time.Now()
+	duration := finished.Sub(started)
+
+	params := queries.FinishJobRunParams{
+		ID:         run.ID,
+		Status:     "succeeded",
+		FinishedAt: pgtype.Timestamp{Time: finished, Valid: true},
+		DurationMs: pgtype.Int8{Int64: duration.Milliseconds(), Valid: true},
+	}
+	if runErr != nil {
+		params.Status = "failed"
+		params.Error = pgtype.Text{String: runErr.Error(), Valid: true}
+		s.logger.Error("Job failed", "job", run.JobName, "run_id", run.ID, "duration", duration, "error", runErr)
+	} else {
+		s.logger.Info("Job finished", "job", run.JobName, "run_id", run.ID, "duration", duration)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobFinishTimeout)
+	defer cancel()
+	if _, err := s.queries.FinishJobRun(ctx, params); err != nil {
+		s.logger.Error("Failed to record job run", "job", run.JobName, "run_id", run.ID, "error", err)
+	}
+}
+
+func (s *JobScheduler) unlock(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobFinishTimeout)
+	defer cancel()
+	if err := s.locker.Release(ctx, key, s.instanceID); err != nil {
+		s.logger.Error("Failed to release job lock", "key", key, "error", err)
+	}
+}
+
+// Shutdown stops firing schedules and waits for running jobs to finish. If ctx
+// ends first the runs are cancelled and given a moment to record their outcome.
+func (s *JobScheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopping = true
+	stop, loopDone := s.stop, s.loopDone
+	s.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-loopDone
+	}
+	// Another instance can take over the schedules while our runs finish;
+	// their run locks stop it starting them again
+	s.resign()
+
+	drained := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		s.cancelRuns()
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.cancelRuns()
+	select {
+	case <-drained:
+	case <-time.After(jobFinishTimeout):
+		return fmt.Errorf("jobs still running after being cancelled: %w", ctx.Err())
+	}
+	return fmt.Errorf("cancelled running jobs: %w", ctx.Err())
+}
+
+// ListJobs lists the registered jobs with their schedule and last run
+func (s *JobScheduler) ListJobs(ctx context.Context) ([]models.ScheduledJobResponse, error) {
+	states, err := s.queries.ListBackgroundJobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	latest, err := s.queries.ListLatestJobRuns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+
+	stateByName := make(map[string]queries.BackgroundJob, len(states))
+	for _, state := range states {
+		stateByName[state.Name] = state
+	}
+	lastRunByName := make(map[string]queries.JobRun, len(latest))
+	for _, run := range latest {
+		lastRunByName[run.JobName] = run
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	responses := make([]models.ScheduledJobResponse, 0, len(names))
+	for _, name := range names {
+		response := s.convertToScheduledJobResponse(s.jobs[name], stateByName[name])
+		if run, ok := lastRunByName[name]; ok {
+			lastRun := convertToJobRunResponse(run)
+			response.LastRun = &lastRun
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// ListRuns lists a job's runs, newest first
+func (s *JobScheduler) ListRuns(ctx context.Context, name string, limit, offset int32) ([]models.JobRunResponse, error) {
+	if _, err := s.job(name); err != nil {
+		return nil, err
+	}
+
+	runs, err := s.queries.ListJobRuns(ctx, queries.ListJobRunsParams{
+		JobName: name,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+
+	responses := make([]models.JobRunResponse, len(runs))
+	for i, run := range runs {
+		responses[i] = convertToJobRunResponse(run)
+	}
+	return responses, nil
+}
+
+// Trigger starts a run of the job now, even if its schedule is paused. The run
+// carries on in the background; its outcome is in the job's run history.
+// It returns ErrJobAlreadyRunning if the job is running anywhere in the cluster.
+func (s *JobScheduler) Trigger(ctx context.Context, name string, userID int32) (*models.JobRunResponse, error) {
+	job, err := s.job(name)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.launch(ctx, job, JobTriggerManual, pgtype.Int4{Int32: userID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	response := convertToJobRunResponse(run)
+	return &response, nil
+}
+
+// Pause stops the job's schedule firing until it is resumed. A run already
+// going is left to finish.
+func (s *JobScheduler) Pause(ctx context.Context, name string, userID int32) (*models.ScheduledJobResponse, error) {
+	return s.setPaused(ctx, name, pgtype.Timestamp{Time: time.Now(), Valid: true}, pgtype.Int4{Int32: userID, Valid: true})
+}
+
+// Resume lets a paused job's schedule fire again
+func (s *JobScheduler) Resume(ctx context.Context, name string) (*models.ScheduledJobResponse, error) {
+	return s.setPaused(ctx, name, pgtype.Timestamp{}, pgtype.Int4{})
+}
+
+func (s *JobScheduler) setPaused(ctx context.Context, name string, pausedAt pgtype.Timestamp, pausedBy pgtype.Int4) (*models.ScheduledJobResponse, error) {
+	job, err := s.job(name)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.queries.SetBackgroundJobPaused(ctx, queries.SetBackgroundJobPausedParams{
+		Name:     name,
+		PausedAt: pausedAt,
+		PausedBy: pausedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update job: %w", err)
+	}
+
+	s.mu.Lock()
+	response := s.convertToScheduledJobResponse(job, state)
+	s.mu.Unlock()
+	return &response, nil
+}
+
+func (s *JobScheduler) job(name string) (*registeredJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// convertToScheduledJobResponse must be called with s.mu held
+func (s *JobScheduler) convertToScheduledJobResponse(job *registeredJob, state queries.BackgroundJob) models.ScheduledJobResponse {
+	response := models.ScheduledJobResponse{
+		Name:     job.Name,
+		Schedule: job.Schedule,
+		Paused:   state.PausedAt.Valid,
+	}
+	if state.PausedAt.Valid {
+		response.PausedAt = &state.PausedAt.Time
+	}
+	if state.PausedBy.Valid {
+		response.PausedBy = &state.PausedBy.Int32
+	}
+	if job.schedule != nil {
+		nextRun := job.nextRun
+		response.NextRunAt = &nextRun
+	}
+	return response
+}
+
+func convertToJobRunResponse(run queries.JobRun) models.JobRunResponse {
+	response := models.JobRunResponse{
+		ID:          run.ID,
+		JobName:     run.JobName,
+		TriggerType: run.TriggerType,
+		InstanceID:  run.InstanceID,
+		Status:      run.Status,
+		StartedAt:   run.StartedAt.Time,
+	}
+	if run.TriggeredBy.Valid {
+		response.TriggeredBy = &run.TriggeredBy.Int32
+	}
+	if run.FinishedAt.Valid {
+		response.FinishedAt = &run.FinishedAt.Time
+	}
+	if run.DurationMs.Valid {
+		response.DurationMs = &run.DurationMs.Int64
+	}
+	if run.Error.Valid {
+		response.Error = &run.Error.String
+	}
+	return response
+}
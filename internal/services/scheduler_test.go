@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+)
+
+// MockJobSchedulerQuerier is a mock implementation of JobSchedulerQuerier
+type MockJobSchedulerQuerier struct {
+	mock.Mock
+}
+
+func (m *MockJobSchedulerQuerier) GetBackgroundJob(ctx context.Context, name string) (queries.BackgroundJob, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(queries.BackgroundJob), args.Error(1)
+}
+
+func (m *MockJobSchedulerQuerier) ListBackgroundJobs(ctx context.Context) ([]queries.BackgroundJob, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]queries.BackgroundJob), args.Error(1)
+}
+
+func (m *MockJobSchedulerQuerier) SetBackgroundJobPaused(ctx context.Context, arg queries.SetBackgroundJobPausedParams) (queries.BackgroundJob, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.BackgroundJob), args.Error(1)
+}
+
+func (m *MockJobSchedulerQuerier) CreateJobRun(ctx context.Context, arg queries.CreateJobRunParams) (queries.JobRun, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.JobRun), args.Error(1)
+}
+
+func (m *MockJobSchedulerQuerier) FinishJobRun(ctx context.Context, arg queries.FinishJobRunParams) (queries.JobRun, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.JobRun), args.Error(1)
+}
+
+func (m *MockJobSchedulerQuerier) ListJobRuns(ctx context.Context, arg queries.ListJobRunsParams) ([]queries.JobRun, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]queries.JobRun), args.Error(1)
+}
+
+func (m *MockJobSchedulerQuerier) ListLatestJobRuns(ctx context.Context) ([]queries.JobRun, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]queries.JobRun), args.Error(1)
+}
+
+// memoryJobLocker is a JobLocker shared by the schedulers of a test in place of Redis
+type memoryJobLocker struct {
+	mu      sync.Mutex
+	holders map[string]string
+}
+
+func newMemoryJobLocker() *memoryJobLocker {
+	return &memoryJobLocker{holders: make(map[string]string)}
+}
+
+func (l *memoryJobLocker) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if holder, ok := l.holders[key]; ok && holder != owner {
+		return false, nil
+	}
+	l.holders[key] = owner
+	return true, nil
+}
+
+func (l *memoryJobLocker) Release(ctx context.Context, key, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holders[key] == owner {
+		delete(l.holders, key)
+	}
+	return nil
+}
+
+func (l *memoryJobLocker) holder(key string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.holders[key]
+}
+
+func newTestJobScheduler(querier *MockJobSchedulerQuerier, locker JobLocker, instanceID string) *JobScheduler {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewJobScheduler(querier, locker, logger).WithInstanceID(instanceID)
+}
+
+// expectJobRun expects one run of the job to be recorded and finished with status
+func expectJobRun(querier *MockJobSchedulerQuerier, name, trigger, status string) {
+	querier.On("CreateJobRun", mock.Anything, mock.MatchedBy(func(arg queries.CreateJobRunParams) bool {
+		return arg.JobName == name && arg.TriggerType == trigger
+	})).Return(queries.JobRun{ID: 7, JobName: name, TriggerType: trigger, Status: "running"}, nil).Once()
+	querier.On("FinishJobRun", mock.Anything, mock.MatchedBy(func(arg queries.FinishJobRunParams) bool {
+		return arg.ID == 7 && arg.Status == status && arg.FinishedAt.Valid && arg.DurationMs.Valid
+	})).Return(queries.JobRun{}, nil).Once()
+}
+
+func TestJobScheduler_Register(t *testing.T) {
+	scheduler := newTestJobScheduler(&MockJobSchedulerQuerier{}, newMemoryJobLocker(), "a")
+	run := func(ctx context.Context) error { return nil }
+
+	assert.NoError(t, scheduler.Register(ScheduledJob{Name: "manual", Run: run}))
+	assert.NoError(t, scheduler.Register(ScheduledJob{Name: "hourly", Schedule: "@hourly", Run: run}))
+	assert.Error(t, scheduler.Register(ScheduledJob{Name: "hourly", Schedule: "@daily", Run: run}), "duplicate name")
+	assert.Error(t, scheduler.Register(ScheduledJob{Name: "bad", Schedule: "every day", Run: run}))
+	assert.Error(t, scheduler.Register(ScheduledJob{Name: "never", Schedule: "0 0 31 4 *", Run: run}))
+	assert.Error(t, scheduler.Register(ScheduledJob{Name: "nothing"}))
+}
+
+func TestJobScheduler_Trigger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("RecordsRun", func(t *testing.T) {
+		querier := &MockJobSchedulerQuerier{}
+		locker := newMemoryJobLocker()
+		scheduler := newTestJobScheduler(querier, locker, "a")
+		ran := make(chan struct{})
+		require.NoError(t, scheduler.Register(ScheduledJob{Name: "cleanup", Run: func(ctx context.Context) error {
+			close(ran)
+			return nil
+		}}))
+		expectJobRun(querier, "cleanup", JobTriggerManual, "succeeded")
+
+		run, err := scheduler.Trigger(ctx, "cleanup", 3)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(7), run.ID)
+		<-ran
+		require.NoError(t, scheduler.Shutdown(ctx))
+		querier.AssertCalled(t, "CreateJobRun", mock.Anything, queries.CreateJobRunParams{
+			JobName:     "cleanup",
+			TriggerType: JobTriggerManual,
+			TriggeredBy: pgtype.Int4{Int32: 3, Valid: true},
+			InstanceID:  "a",
+		})
+		querier.AssertExpectations(t)
+		assert.Empty(t, locker.holder(schedulerJobLockPrefix+"cleanup"), "run lock released")
+	})
+
+	t.Run("RecordsFailureAndPanic", func(t *testing.T) {
+		querier := &MockJobSchedulerQuerier{}
+		scheduler := newTestJobScheduler(querier, newMemoryJobLocker(), "a")
+		require.NoError(t, scheduler.Register(ScheduledJob{Name: "failing", Run: func(ctx context.Context) error {
+			return errors.New("smtp down")
+		}}))
+		require.NoError(t, scheduler.Register(ScheduledJob{Name: "panicking", Run: func(ctx context.Context) error {
+			panic("nil map")
+		}}))
+		querier.On("CreateJobRun", mock.Anything, mock.Anything).Return(queries.JobRun{ID: 7}, nil)
+		querier.On("FinishJobRun", mock.Anything, mock.MatchedBy(func(arg queries.FinishJobRunParams) bool {
+			return arg.Status == "failed" && arg.Error.String == "smtp down"
+		})).Return(queries.JobRun{}, nil).Once()
+		querier.On("FinishJobRun", mock.Anything, mock.MatchedBy(func(arg queries.FinishJobRunParams) bool {
+			return arg.Status == "failed" && arg.Error.String == "job panicked: nil map"
+		})).Return(queries.JobRun{}, nil).Once()
+
+		_, err := scheduler.Trigger(ctx, "failing", 3)
+		require.NoError(t, err)
+		_, err = scheduler.Trigger(ctx, "panicking", 3)
+		require.NoError(t, err)
+
+		require.NoError(t, scheduler.Shutdown(ctx))
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("AlreadyRunningElsewhere", func(t *testing.T) {
+		querier := &MockJobSchedulerQuerier{}
+		locker := newMemoryJobLocker()
+		scheduler := newTestJobScheduler(querier, locker, "a")
+		require.NoError(t, scheduler.Register(ScheduledJob{Name: "cleanup", Run: func(ctx context.Context) error { return nil }}))
+		_, _ = locker.Acquire(ctx, schedulerJobLockPrefix+"cleanup", "b", time.Minute)
+
+		_, err := scheduler.Trigger(ctx, "cleanup", 3)
+
+		assert.ErrorIs(t, err, ErrJobAlreadyRunning)
+		querier.AssertNotCalled(t, "CreateJobRun", mock.Anything, mock.Anything)
+	})
+
+	t.Run("UnknownJob", func(t *testing.T) {
+		scheduler := newTestJobScheduler(&MockJobSchedulerQuerier{}, newMemoryJobLocker(), "a")
+
+		_, err := scheduler.Trigger(ctx, "missing", 3)
+
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+}
+
+func TestJobScheduler_FireDue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("OnlyLeaderFires", func(t *testing.T) {
+		querier := &MockJobSchedulerQuerier{}
+		locker := newMemoryJobLocker()
+		leader := newTestJobScheduler(querier, locker, "a")
+		follower := newTestJobScheduler(querier, locker, "b")
+		runs := make(chan string, 2)
+		for _, scheduler := range []*JobScheduler{leader, follower} {
+			instance := scheduler.instanceID
+			require.NoError(t, scheduler.Register(ScheduledJob{Name: "reminders", Schedule: "* * * * *", Run: func(ctx context.Context) error {
+				runs <- instance
+				return nil
+			}}))
+			scheduler.jobs["reminders"].nextRun = now.Add(-time.Second)
+		}
+		querier.On("GetBackgroundJob", mock.Anything, "reminders").Return(queries.BackgroundJob{}, pgx.ErrNoRows)
+		expectJobRun(querier, "reminders", JobTriggerSchedule, "succeeded")
+
+		leader.campaign()
+		follower.campaign()
+		leader.fireDue(now)
+		follower.fireDue(now)
+		require.NoError(t, leader.Shutdown(ctx))
+		require.NoError(t, follower.Shutdown(ctx))
+
+		assert.Equal(t, []string{"a"}, drain(runs))
+		assert.True(t, follower.jobs["reminders"].nextRun.After(now), "follower moves its schedule on")
+		assert.Empty(t, locker.holder(schedulerLeaderKey), "leadership given up at shutdown")
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("PausedJobSkipped", func(t *testing.T) {
+		querier := &MockJobSchedulerQuerier{}
+		scheduler := newTestJobScheduler(querier, newMemoryJobLocker(), "a")
+		require.NoError(t, scheduler.Register(ScheduledJob{Name: "reminders", Schedule: "* * * * *", Run: func(ctx context.Context) error {
+			t.Error("paused job ran")
+			return nil
+		}}))
+		scheduler.jobs["reminders"].nextRun = now.Add(-time.Second)
+		querier.On("GetBackgroundJob", mock.Anything, "reminders").Return(queries.BackgroundJob{
+			Name:     "reminders",
+			PausedAt: pgtype.Timestamp{Time: now.Add(-time.Hour), Valid: true},
+		}, nil)
+
+		scheduler.campaign()
+		scheduler.fireDue(now)
+		require.NoError(t, scheduler.Shutdown(ctx))
+
+		querier.AssertNotCalled(t, "CreateJobRun", mock.Anything, mock.Anything)
+	})
+}
+
+func TestJobScheduler_Shutdown(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("WaitsForRuns", func(t *testing.T) {
+		querier := &MockJobSchedulerQuerier{}
+		scheduler := newTestJobScheduler(querier, newMemoryJobLocker(), "a")
+		started, release := make(chan struct{}), make(chan struct{})
+		finished := false
+		require.NoError(t, scheduler.Register(ScheduledJob{Name: "slow", Run: func(ctx context.Context) error {
+			close(started)
+			<-release
+			finished = true
+			return nil
+		}}))
+		expectJobRun(querier, "slow", JobTriggerManual, "succeeded")
+
+		_, err := scheduler.Trigger(ctx, "slow", 3)
+		require.NoError(t, err)
+		<-started
+		time.AfterFunc(20*time.Millisecond, func() { close(release) })
+
+		require.NoError(t, scheduler.Shutdown(ctx))
+		assert.True(t, finished)
+
+		_, err = scheduler.Trigger(ctx, "slow", 3)
+		assert.ErrorIs(t, err, ErrSchedulerStopped)
+	})
+
+	t.Run("CancelsRunsAtDeadline", func(t *testing.T) {
+		querier := &MockJobSchedulerQuerier{}
+		scheduler := newTestJobScheduler(querier, newMemoryJobLocker(), "a")
+		started := make(chan struct{})
+		require.NoError(t, scheduler.Register(ScheduledJob{Name: "stuck", Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}}))
+		expectJobRun(querier, "stuck", JobTriggerManual, "failed")
+
+		_, err := scheduler.Trigger(ctx, "stuck", 3)
+		require.NoError(t, err)
+		<-started
+
+		deadline, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		err = scheduler.Shutdown(deadline)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		querier.AssertExpectations(t)
+	})
+}
+
+func TestJobScheduler_ListJobs(t *testing.T) {
+	ctx := context.Background()
+	querier := &MockJobSchedulerQuerier{}
+	scheduler := newTestJobScheduler(querier, newMemoryJobLocker(), "a")
+	run := func(ctx context.Context) error { return nil }
+	require.NoError(t, scheduler.Register(ScheduledJob{Name: "reminders", Schedule: "0 8 * * *", Run: run}))
+	require.NoError(t, scheduler.Register(ScheduledJob{Name: "cleanup", Run: run}))
+
+	pausedAt := time.Now().Add(-time.Hour)
+	querier.On("ListBackgroundJobs", ctx).Return([]queries.BackgroundJob{
+		{Name: "reminders", PausedAt: pgtype.Timestamp{Time: pausedAt, Valid: true}, PausedBy: pgtype.Int4{Int32: 3, Valid: true}},
+		{Name: FineAccrualJobName},
+	}, nil)
+	querier.On("ListLatestJobRuns", ctx).Return([]queries.JobRun{
+		{ID: 4, JobName: "cleanup", Status: "succeeded", DurationMs: pgtype.Int8{Int64: 1200, Valid: true}},
+	}, nil)
+
+	jobs, err := scheduler.ListJobs(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "cleanup", jobs[0].Name)
+	assert.Nil(t, jobs[0].NextRunAt, "manual only")
+	require.NotNil(t, jobs[0].LastRun)
+	assert.Equal(t, int64(1200), *jobs[0].LastRun.DurationMs)
+
+	assert.Equal(t, "reminders", jobs[1].Name)
+	assert.True(t, jobs[1].Paused)
+	assert.Equal(t, int32(3), *jobs[1].PausedBy)
+	require.NotNil(t, jobs[1].NextRunAt)
+	assert.Equal(t, 8, jobs[1].NextRunAt.Hour())
+	assert.Nil(t, jobs[1].LastRun)
+}
+
+// drain returns what has been sent on a buffered channel so far
+func drain(ch chan string) []string {
+	var values []string
+	for {
+		select {
+		case v := <-ch:
+			values = append(values, v)
+		default:
+			return values
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// SMS encodings. Text made only of GSM 03.38 characters is sent as 7-bit GSM;
+// anything else makes the whole message UCS-2, which fits far less per segment.
+const (
+	SMSEncodingGSM7 = "gsm7"
+	SMSEncodingUCS2 = "ucs2"
+)
+
+// Characters per segment. A message longer than one segment is split, and each
+// part loses room to the header that joins the parts back up.
+const (
+	gsm7SingleSegment = 160
+	gsm7MultiSegment  = 153
+	ucs2SingleSegment = 70
+	ucs2MultiSegment  = 67
+)
+
+// smsMaxSegments is how many segments a notification SMS may use
+const smsMaxSegments = 1
+
+// smsMinTitleLength is the shortest a book title is cut to when fitting a message
+const smsMinTitleLength = 12
+
+const (
+	gsm7Basic    = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	gsm7Extended = "^{}\\[~]|€\f"
+)
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9]\d{7,14}$`)
+
+// SMSDeliveryQuerier defines the database operations SMSService needs
+type SMSDeliveryQuerier interface {
+	CreateSmsDelivery(ctx context.Context, arg queries.CreateSmsDeliveryParams) (queries.SmsDelivery, error)
+	HasSentSmsDelivery(ctx context.Context, notificationID int32) (bool, error)
+	UpdateSmsDeliveryToSent(ctx context.Context, arg queries.UpdateSmsDeliveryToSentParams) (queries.SmsDelivery, error)
+	UpdateSmsDeliveryError(ctx context.Context, arg queries.UpdateSmsDeliveryErrorParams) (queries.SmsDelivery, error)
+	UpdateSmsDeliveryReport(ctx context.Context, arg queries.UpdateSmsDeliveryReportParams) (queries.SmsDelivery, error)
+}
+
+// SMSServiceInterface defines the interface for SMS service operations
+type SMSServiceInterface interface {
+	SendNotificationSMS(ctx context.Context, notificationID int32, phone, message string) error
+	HandleDeliveryReport(ctx context.Context, token string, form url.Values) (*models.SMSDelivery, error)
+}
+
+// SMSService sends notification text messages through an SMS provider and
+// tracks each one as an SMS delivery
+type SMSService struct {
+	querier            SMSDeliveryQuerier
+	provider           SMSProvider
+	defaultCountryCode string
+	logger             *slog.Logger
+}
+
+// NewSMSService creates a new SMS service. Phone numbers stored without a
+// country code are taken to be in defaultCountryCode.
+func NewSMSService(querier SMSDeliveryQuerier, provider SMSProvider, defaultCountryCode string, logger *slog.Logger) *SMSService {
+	return &SMSService{
+		querier:            querier,
+		provider:           provider,
+		defaultCountryCode: defaultCountryCode,
+		logger:             logger,
+	}
+}
+
+// SendNotificationSMS texts message to phone for a notification. A notification
+// whose SMS has already gone out is not texted again, so retries are safe.
+func (s *SMSService) SendNotificationSMS(ctx context.Context, notificationID int32, phone, message string) error {
+	to, err := NormalizePhoneE164(phone, s.defaultCountryCode)
+	if err != nil {
+		return err
+	}
+
+	sent, err := s.querier.HasSentSmsDelivery(ctx, notificationID)
+	if err != nil {
+		return fmt.Errorf("failed to check SMS deliveries: %w", err)
+	}
+	if sent {
+		s.logger.Info("Notification SMS already sent, skipping", "notification_id", notificationID)
+		return nil
+	}
+
+	segments, encoding := SMSSegments(message)
+	delivery, err := s.querier.CreateSmsDelivery(ctx, queries.CreateSmsDeliveryParams{
+		NotificationID: notificationID,
+		PhoneNumber:    to,
+		Message:        message,
+		Segments:       int32(segments),
+		Encoding:       encoding,
+		Provider:       s.provider.Name(),
+	})
+	recorded := err == nil
+	if err != nil {
+		// The message matters more than its record
+		s.logger.Error("Failed to record SMS delivery", "notification_id", notificationID, "error", err)
+	}
+
+	result, sendErr := s.provider.Send(ctx, SMSMessage{To: to, Body: message})
+
+	// Record the outcome even if ctx ended as the send finished, or the message
+	// would be sent again
+	recordCtx := context.WithoutCancel(ctx)
+	if recorded {
+		if sendErr != nil {
+			_, err = s.querier.UpdateSmsDeliveryError(recordCtx, queries.UpdateSmsDeliveryErrorParams{
+				ID:           delivery.ID,
+				ErrorMessage: pgtype.Text{String: sendErr.Error(), Valid: true},
+			})
+		} else {
+			_, err = s.querier.UpdateSmsDeliveryToSent(recordCtx, queries.UpdateSmsDeliveryToSentParams{
+				ID:                delivery.ID,
+				ProviderMessageID: optionalText(&result.MessageID),
+				Cost:              optionalText(&result.Cost),
+			})
+		}
+		if err != nil {
+			s.logger.Error("Failed to update SMS delivery", "delivery_id", delivery.ID, "error", err)
+		}
+	}
+
+	if sendErr != nil {
+		s.logger.Error("Failed to send notification SMS",
+			"notification_id", notificationID,
+			"phone", to,
+			"error", sendErr)
+		return sendErr
+	}
+
+	s.logger.Info("Notification SMS sent",
+		"notification_id", notificationID,
+		"phone", to,
+		"segments", segments,
+		"message_id", result.MessageID)
+	return nil
+}
+
+// HandleDeliveryReport applies a delivery report from the SMS gateway to the
+// delivery it is about. Reports for unknown messages, or for deliveries that
+// already have a final status, are ignored and return nil.
+func (s *SMSService) HandleDeliveryReport(ctx context.Context, token string, form url.Values) (*models.SMSDelivery, error) {
+	report, err := s.provider.ParseDeliveryReport(token, form)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.querier.UpdateSmsDeliveryReport(ctx, queries.UpdateSmsDeliveryReportParams{
+		ProviderMessageID: pgtype.Text{String: report.MessageID, Valid: true},
+		Status:            string(report.Status),
+		ErrorMessage:      optionalText(&report.FailureReason),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			s.logger.Info("No pending SMS delivery for delivery report", "message_id", report.MessageID, "status", report.Status)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update SMS delivery: %w", err)
+	}
+
+	s.logger.Info("SMS delivery report applied",
+		"delivery_id", delivery.ID,
+		"notification_id", delivery.NotificationID,
+		"status", report.Status)
+
+	return convertToSMSDelivery(delivery), nil
+}
+
+// convertToSMSDelivery converts a database SMS delivery to the model
+func convertToSMSDelivery(dbDelivery queries.SmsDelivery) *models.SMSDelivery {
+	delivery := &models.SMSDelivery{
+		ID:             dbDelivery.ID,
+		NotificationID: dbDelivery.NotificationID,
+		PhoneNumber:    dbDelivery.PhoneNumber,
+		Message:        dbDelivery.Message,
+		Segments:       int(dbDelivery.Segments),
+		Encoding:       dbDelivery.Encoding,
+		Provider:       dbDelivery.Provider,
+		Status:         models.SMSDeliveryStatus(dbDelivery.Status),
+		CreatedAt:      dbDelivery.CreatedAt.Time,
+		UpdatedAt:      dbDelivery.UpdatedAt.Time,
+	}
+
+	if dbDelivery.ProviderMessageID.Valid {
+		delivery.ProviderMessageID = &dbDelivery.ProviderMessageID.String
+	}
+	if dbDelivery.Cost.Valid {
+		delivery.Cost = &dbDelivery.Cost.String
+	}
+	if dbDelivery.ErrorMessage.Valid {
+		delivery.ErrorMessage = &dbDelivery.ErrorMessage.String
+	}
+	if dbDelivery.SentAt.Valid {
+		delivery.SentAt = &dbDelivery.SentAt.Time
+	}
+	if dbDelivery.DeliveredAt.Valid {
+		delivery.DeliveredAt = &dbDelivery.DeliveredAt.Time
+	}
+	if dbDelivery.FailedAt.Valid {
+		delivery.FailedAt = &dbDelivery.FailedAt.Time
+	}
+
+	return delivery
+}
+
+// NormalizePhoneE164 converts a phone number to E.164 form, such as
+// +254712345678. Numbers written with a leading 0 trunk prefix, or with no
+// prefix at all, are taken to be in defaultCountryCode.
+func NormalizePhoneE164(phone, defaultCountryCode string) (string, error) {
+	cleaned := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(strings.TrimSpace(phone))
+	countryCode := strings.TrimPrefix(defaultCountryCode, "+")
+
+	switch {
+	case strings.HasPrefix(cleaned, "+"):
+	case strings.HasPrefix(cleaned, "00"):
+		cleaned = "+" + cleaned[2:]
+	case strings.HasPrefix(cleaned, "0"):
+		cleaned = "+" + countryCode + cleaned[1:]
+	case countryCode != "" && strings.HasPrefix(cleaned, countryCode) && len(cleaned) > len(countryCode)+8:
+		cleaned = "+" + cleaned
+	default:
+		cleaned = "+" + countryCode + cleaned
+	}
+
+	if !e164Pattern.MatchString(cleaned) {
+		return "", fmt.Errorf("%q is not a valid phone number", phone)
+	}
+	return cleaned, nil
+}
+
+// SMSSegments returns how many segments text is sent as, and its encoding
+func SMSSegments(text string) (int, string) {
+	length, encoding := smsLength(text)
+	single, multi := gsm7SingleSegment, gsm7MultiSegment
+	if encoding == SMSEncodingUCS2 {
+		single, multi = ucs2SingleSegment, ucs2MultiSegment
+	}
+
+	if length <= single {
+		return 1, encoding
+	}
+	return (length + multi - 1) / multi, encoding
+}
+
+// smsLength measures text in the units its encoding is billed in: GSM septets,
+// where extension characters take two, or UTF-16 code units for UCS-2
+func smsLength(text string) (int, string) {
+	length := 0
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune(gsm7Basic, r):
+			length++
+		case strings.ContainsRune(gsm7Extended, r):
+			length += 2
+		default:
+			return len(utf16.Encode([]rune(text))), SMSEncodingUCS2
+		}
+	}
+	return length, SMSEncodingGSM7
+}
+
+// smsCapacity is how many units fit in smsMaxSegments segments of an encoding
+func smsCapacity(encoding string) int {
+	if encoding == SMSEncodingUCS2 {
+		if smsMaxSegments == 1 {
+			return ucs2SingleSegment
+		}
+		return ucs2MultiSegment * smsMaxSegments
+	}
+	if smsMaxSegments == 1 {
+		return gsm7SingleSegment
+	}
+	return gsm7MultiSegment * smsMaxSegments
+}
+
+// GetSMSTemplate returns the short text message template for a notification
+// type in locale, falling back to English. It returns "" for types without one.
+func GetSMSTemplate(notificationType, locale string) string {
+	for _, candidate := range localeFallbacks(NormalizeLocale(locale)) {
+		if template, ok := smsTemplates[candidate][notificationType]; ok {
+			return template
+		}
+	}
+	return ""
+}
+
+// RenderSMS fills in an SMS template and fits the result into the SMS length
+// limit. The book title is the only open-ended value, so it is shortened first;
+// if the message still does not fit it is cut off.
+func RenderSMS(template string, data map[string]interface{}) string {
+	message := fillTemplate(template, data)
+
+	title, _ := data["BookTitle"].(string)
+	original := []rune(title)
+	keep := len(original)
+	for {
+		length, encoding := smsLength(message)
+		overflow := length - smsCapacity(encoding)
+		if overflow <= 0 {
+			return message
+		}
+		if keep <= smsMinTitleLength {
+			break
+		}
+
+		// The first cut also makes room for the ellipsis
+		next := keep - overflow
+		if keep == len(original) {
+			next -= len("...")
+		}
+		if next < smsMinTitleLength {
+			next = smsMinTitleLength
+		}
+		keep = next
+
+		shortened := make(map[string]interface{}, len(data))
+		for key, value := range data {
+			shortened[key] = value
+		}
+		shortened["BookTitle"] = string(original[:keep]) + "..."
+		message = fillTemplate(template, shortened)
+	}
+
+	// Only a very long value other than the title gets here
+	runes := []rune(message)
+	for len(runes) > 0 {
+		length, encoding := smsLength(string(runes))
+		if length <= smsCapacity(encoding) {
+			break
+		}
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
+
+// fillTemplate replaces each {{.Key}} placeholder in template with its value
+func fillTemplate(template string, data map[string]interface{}) string {
+	message := template
+	for key, value := range data {
+		message = strings.ReplaceAll(message, fmt.Sprintf("{{.%s}}", key), fmt.Sprintf("%v", value))
+	}
+	return message
+}
+
+// smsTemplates are the text message versions of the notification templates, by
+// locale. They are kept within one segment of GSM characters for typical values.
+var smsTemplates = map[string]map[string]string{
+	models.LocaleEnglish: {
+		"due_soon":            `Library: "{{.BookTitle}}" is due on {{.DueDate}}. Please return or renew it on time to avoid a fine.`,
+		"book_available":      `Library: "{{.BookTitle}}" that you reserved is ready. Please collect it within {{.ExpirationDays}} day(s).`,
+		"overdue_reminder":    `Library: "{{.BookTitle}}" was due on {{.DueDate}}. Fine so far: {{.FineAmount}}. Please return it.`,
+		"fine_notice":         `Library: you have an unpaid fine of {{.FineAmount}} for "{{.BookTitle}}". Please pay it at the library.`,
+		"reservation_ready":   `Library: "{{.BookTitle}}" that you reserved is ready. Please collect it by {{.PickupDeadline}}.`,
+		"reservation_expired": `Library: your reservation for "{{.BookTitle}}" has expired. You can reserve it again if you still need it.`,
+		"account_blocked":     `Library: your account has been blocked ({{.BlockReason}}). Please contact the library.`,
+		"announcement":        `Library: {{.NotificationTitle}}. {{.NotificationMessage}}`,
+	},
+	models.LocaleSwahili: {
+		"due_soon":            `Maktaba: "{{.BookTitle}}" kinarudishwa {{.DueDate}}. Tafadhali kirudishe au uongeze muda mapema ili kuepuka faini.`,
+		"book_available":      `Maktaba: "{{.BookTitle}}" ulichohifadhi kiko tayari. Tafadhali kichukue ndani ya siku {{.ExpirationDays}}.`,
+		"overdue_reminder":    `Maktaba: "{{.BookTitle}}" kilipaswa kurudishwa {{.DueDate}}. Faini hadi sasa: {{.FineAmount}}. Tafadhali kirudishe.`,
+		"fine_notice":         `Maktaba: una faini ya {{.FineAmount}} kwa "{{.BookTitle}}" ambayo haijalipwa. Tafadhali ilipe maktabani.`,
+		"reservation_ready":   `Maktaba: "{{.BookTitle}}" ulichohifadhi kiko tayari. Tafadhali kichukue kabla ya {{.PickupDeadline}}.`,
+		"reservation_expired": `Maktaba: muda wa uhifadhi wako wa "{{.BookTitle}}" umekwisha. Unaweza kukihifadhi tena ukikihitaji.`,
+		"account_blocked":     `Maktaba: akaunti yako imezuiwa ({{.BlockReason}}). Tafadhali wasiliana na maktaba.`,
+		"announcement":        `Maktaba: {{.NotificationTitle}}. {{.NotificationMessage}}`,
+	},
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+var (
+	ErrSMSNotConfigured         = errors.New("SMS is not configured")
+	ErrInvalidSMSCallbackToken  = errors.New("invalid SMS delivery report token")
+	ErrSMSDeliveryNotFound      = errors.New("SMS delivery not found")
+	ErrRecipientHasNoPhone      = errors.New("recipient has no phone number")
+	ErrSMSChannelNotSupported   = errors.New("SMS can only be sent to students")
+	ErrInvalidSMSDeliveryReport = errors.New("invalid SMS delivery report")
+)
+
+// SMSProvider sends text messages through an SMS gateway and interprets the
+// gateway's delivery reports
+type SMSProvider interface {
+	// Name identifies the provider, and is stored on SMS deliveries
+	Name() string
+	// Send submits a message to the gateway. The phone number is in E.164 form.
+	Send(ctx context.Context, msg SMSMessage) (*SMSSendResult, error)
+	// ParseDeliveryReport authenticates and decodes a delivery report callback
+	ParseDeliveryReport(token string, form url.Values) (*SMSDeliveryReport, error)
+}
+
+// SMSMessage is a text message to send
+type SMSMessage struct {
+	To   string
+	Body string
+}
+
+// SMSSendResult is the gateway's acknowledgement of a message
+type SMSSendResult struct {
+	MessageID string
+	Cost      string
+}
+
+// SMSDeliveryReport is the final or interim status of a message reported by the gateway
+type SMSDeliveryReport struct {
+	MessageID     string
+	Status        models.SMSDeliveryStatus
+	FailureReason string
+}
+
+// Africa's Talking recipient status codes that mean the message was accepted
+const (
+	atStatusProcessed = 100
+	atStatusSent      = 101
+	atStatusQueued    = 102
+)
+
+// AfricasTalkingProvider implements SMSProvider with the Africa's Talking bulk SMS API
+type AfricasTalkingProvider struct {
+	config *models.SMSConfig
+	client *http.Client
+}
+
+// NewAfricasTalkingProvider creates an Africa's Talking SMS provider
+func NewAfricasTalkingProvider(config *models.SMSConfig) *AfricasTalkingProvider {
+	timeout := time.Duration(config.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &AfricasTalkingProvider{
+		config: config,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to call Africa's Talking
+func (p *AfricasTalkingProvider) WithHTTPClient(client *http.Client) *AfricasTalkingProvider {
+	p.client = client
+	return p
+}
+
+// Name identifies the provider
+func (p *AfricasTalkingProvider) Name() string {
+	return "africastalking"
+}
+
+type atSendResponse struct {
+	SMSMessageData struct {
+		Message    string `json:"Message"`
+		Recipients []struct {
+			StatusCode int    `json:"statusCode"`
+			Number     string `json:"number"`
+			Status     string `json:"status"`
+			Cost       string `json:"cost"`
+			MessageID  string `json:"messageId"`
+		} `json:"Recipients"`
+	} `json:"SMSMessageData"`
+}
+
+// Send submits the message to Africa's Talking. A message the gateway refuses
+// for its recipient, for example an invalid number or an empty balance, is an
+// error.
+func (p *AfricasTalkingProvider) Send(ctx context.Context, msg SMSMessage) (*SMSSendResult, error) {
+	form := url.Values{}
+	form.Set("username", p.config.Username)
+	form.Set("to", msg.To)
+	form.Set("message", msg.Body)
+	if p.config.SenderID != "" {
+		form.Set("from", p.config.SenderID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url("/version1/messaging"), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SMS request: %w", err)
+	}
+	req.Header.Set("apiKey", p.config.APIKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send SMS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Errors such as bad credentials come back as plain text
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("SMS rejected (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result atSendResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode SMS response: %w", err)
+	}
+
+	if len(result.SMSMessageData.Recipients) == 0 {
+		return nil, fmt.Errorf("SMS rejected: %s", result.SMSMessageData.Message)
+	}
+
+	recipient := result.SMSMessageData.Recipients[0]
+	switch recipient.StatusCode {
+	case atStatusProcessed, atStatusSent, atStatusQueued:
+		return &SMSSendResult{
+			MessageID: recipient.MessageID,
+			Cost:      recipient.Cost,
+		}, nil
+	default:
+		return nil, fmt.Errorf("SMS rejected (code %d): %s", recipient.StatusCode, recipient.Status)
+	}
+}
+
+// ParseDeliveryReport decodes an Africa's Talking delivery report. When a
+// callback token is configured the callback URL must carry it, since Africa's
+// Talking does not sign its callbacks.
+func (p *AfricasTalkingProvider) ParseDeliveryReport(token string, form url.Values) (*SMSDeliveryReport, error) {
+	if p.config.CallbackToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(p.config.CallbackToken)) != 1 {
+		return nil, ErrInvalidSMSCallbackToken
+	}
+
+	messageID := form.Get("id")
+	if messageID == "" {
+		return nil, fmt.Errorf("%w: missing message id", ErrInvalidSMSDeliveryReport)
+	}
+
+	report := &SMSDeliveryReport{
+		MessageID:     messageID,
+		FailureReason: form.Get("failureReason"),
+	}
+
+	switch form.Get("status") {
+	case "Success":
+		report.Status = models.SMSDeliveryStatusDelivered
+	case "Failed", "Rejected":
+		report.Status = models.SMSDeliveryStatusFailed
+		if report.FailureReason == "" {
+			report.FailureReason = form.Get("status")
+		}
+	case "Sent", "Submitted", "Buffered":
+		report.Status = models.SMSDeliveryStatusSent
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidSMSDeliveryReport, form.Get("status"))
+	}
+
+	return report, nil
+}
+
+func (p *AfricasTalkingProvider) url(path string) string {
+	return strings.TrimRight(p.config.BaseURL, "/") + path
+}
+
+// FakeSMSProvider is an SMSProvider that keeps messages in memory instead of
+// sending them, for tests and for running locally without a gateway account
+type FakeSMSProvider struct {
+	mu       sync.Mutex
+	messages []SMSMessage
+	err      error
+}
+
+// NewFakeSMSProvider creates a fake SMS provider
+func NewFakeSMSProvider() *FakeSMSProvider {
+	return &FakeSMSProvider{}
+}
+
+// Name identifies the provider
+func (p *FakeSMSProvider) Name() string {
+	return "fake"
+}
+
+// Send records the message, or fails with the error set by FailWith
+func (p *FakeSMSProvider) Send(ctx context.Context, msg SMSMessage) (*SMSSendResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.messages = append(p.messages, msg)
+	return &SMSSendResult{MessageID: fmt.Sprintf("fake-%d", len(p.messages))}, nil
+}
+
+// ParseDeliveryReport decodes a report with the same fields Africa's Talking uses
+func (p *FakeSMSProvider) ParseDeliveryReport(token string, form url.Values) (*SMSDeliveryReport, error) {
+	return NewAfricasTalkingProvider(&models.SMSConfig{}).ParseDeliveryReport(token, form)
+}
+
+// FailWith makes every following Send fail with err; nil makes sends succeed again
+func (p *FakeSMSProvider) FailWith(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// Messages returns the messages sent so far
+func (p *FakeSMSProvider) Messages() []SMSMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]SMSMessage(nil), p.messages...)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+func newTestAfricasTalking(t *testing.T, handler http.HandlerFunc) *AfricasTalkingProvider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewAfricasTalkingProvider(&models.SMSConfig{
+		BaseURL:       server.URL,
+		Username:      "sandbox",
+		APIKey:        "atsk_test",
+		SenderID:      "LIBRARY",
+		CallbackToken: "s3cret",
+	})
+}
+
+func TestAfricasTalkingProvider_Send(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("accepted message", func(t *testing.T) {
+		var form url.Values
+		provider := newTestAfricasTalking(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/version1/messaging", r.URL.Path)
+			assert.Equal(t, "atsk_test", r.Header.Get("apiKey"))
+			assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+			require.NoError(t, r.ParseForm())
+			form = r.PostForm
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"SMSMessageData":{"Message":"Sent to 1/1 Total Cost: KES 0.8000","Recipients":[{"statusCode":101,"number":"+254712345678","status":"Success","cost":"KES 0.8000","messageId":"ATXid_1"}]}}`))
+		})
+
+		result, err := provider.Send(ctx, SMSMessage{To: "+254712345678", Body: "Your book is ready"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "ATXid_1", result.MessageID)
+		assert.Equal(t, "KES 0.8000", result.Cost)
+		assert.Equal(t, "sandbox", form.Get("username"))
+		assert.Equal(t, "+254712345678", form.Get("to"))
+		assert.Equal(t, "Your book is ready", form.Get("message"))
+		assert.Equal(t, "LIBRARY", form.Get("from"))
+	})
+
+	t.Run("recipient rejected", func(t *testing.T) {
+		provider := newTestAfricasTalking(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"SMSMessageData":{"Message":"Sent to 0/1 Total Cost: 0","Recipients":[{"statusCode":403,"number":"+254712345678","status":"InvalidPhoneNumber","cost":"0","messageId":"None"}]}}`))
+		})
+
+		_, err := provider.Send(ctx, SMSMessage{To: "+254712345678", Body: "Hello"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "InvalidPhoneNumber")
+	})
+
+	t.Run("bad credentials", func(t *testing.T) {
+		provider := newTestAfricasTalking(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("The supplied authentication is invalid"))
+		})
+
+		_, err := provider.Send(ctx, SMSMessage{To: "+254712345678", Body: "Hello"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "status 401")
+		assert.Contains(t, err.Error(), "authentication is invalid")
+	})
+}
+
+func TestAfricasTalkingProvider_ParseDeliveryReport(t *testing.T) {
+	provider := NewAfricasTalkingProvider(&models.SMSConfig{CallbackToken: "s3cret"})
+
+	testCases := []struct {
+		status   string
+		expected models.SMSDeliveryStatus
+	}{
+		{"Success", models.SMSDeliveryStatusDelivered},
+		{"Failed", models.SMSDeliveryStatusFailed},
+		{"Rejected", models.SMSDeliveryStatusFailed},
+		{"Buffered", models.SMSDeliveryStatusSent},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.status, func(t *testing.T) {
+			report, err := provider.ParseDeliveryReport("s3cret", url.Values{
+				"id":     {"ATXid_1"},
+				"status": {tc.status},
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, "ATXid_1", report.MessageID)
+			assert.Equal(t, tc.expected, report.Status)
+		})
+	}
+
+	t.Run("failure reason", func(t *testing.T) {
+		report, err := provider.ParseDeliveryReport("s3cret", url.Values{
+			"id":            {"ATXid_1"},
+			"status":        {"Failed"},
+			"failureReason": {"AbsentSubscriber"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "AbsentSubscriber", report.FailureReason)
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		_, err := provider.ParseDeliveryReport("guess", url.Values{"id": {"ATXid_1"}, "status": {"Success"}})
+
+		assert.ErrorIs(t, err, ErrInvalidSMSCallbackToken)
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		_, err := provider.ParseDeliveryReport("s3cret", url.Values{"id": {"ATXid_1"}, "status": {"Lost"}})
+
+		assert.ErrorIs(t, err, ErrInvalidSMSDeliveryReport)
+	})
+}
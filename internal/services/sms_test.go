@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockSMSDeliveryQuerier is a mock implementation of SMSDeliveryQuerier
+type MockSMSDeliveryQuerier struct {
+	mock.Mock
+}
+
+func (m *MockSMSDeliveryQuerier) CreateSmsDelivery(ctx context.Context, arg queries.CreateSmsDeliveryParams) (queries.SmsDelivery, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.SmsDelivery), args.Error(1)
+}
+
+func (m *MockSMSDeliveryQuerier) HasSentSmsDelivery(ctx context.Context, notificationID int32) (bool, error) {
+	args := m.Called(ctx, notificationID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSMSDeliveryQuerier) UpdateSmsDeliveryToSent(ctx context.Context, arg queries.UpdateSmsDeliveryToSentParams) (queries.SmsDelivery, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.SmsDelivery), args.Error(1)
+}
+
+func (m *MockSMSDeliveryQuerier) UpdateSmsDeliveryError(ctx context.Context, arg queries.UpdateSmsDeliveryErrorParams) (queries.SmsDelivery, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.SmsDelivery), args.Error(1)
+}
+
+func (m *MockSMSDeliveryQuerier) UpdateSmsDeliveryReport(ctx context.Context, arg queries.UpdateSmsDeliveryReportParams) (queries.SmsDelivery, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.SmsDelivery), args.Error(1)
+}
+
+func newTestSMSService() (*SMSService, *MockSMSDeliveryQuerier, *FakeSMSProvider) {
+	querier := &MockSMSDeliveryQuerier{}
+	provider := NewFakeSMSProvider()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	return NewSMSService(querier, provider, "254", logger), querier, provider
+}
+
+func TestNormalizePhoneE164(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"0712345678", "+254712345678"},
+		{"0712 345 678", "+254712345678"},
+		{"712345678", "+254712345678"},
+		{"254712345678", "+254712345678"},
+		{"+254 (712) 345-678", "+254712345678"},
+		{"00256712345678", "+256712345678"},
+		{"+447911123456", "+447911123456"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			phone, err := NormalizePhoneE164(tc.input, "254")
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, phone)
+		})
+	}
+
+	for _, input := range []string{"", "123", "+0712345678", "07123abc78"} {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, err := NormalizePhoneE164(input, "254")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSMSSegments(t *testing.T) {
+	testCases := []struct {
+		name             string
+		text             string
+		expectedSegments int
+		expectedEncoding string
+	}{
+		{"short GSM", "Your book is ready", 1, SMSEncodingGSM7},
+		{"full GSM segment", strings.Repeat("a", 160), 1, SMSEncodingGSM7},
+		{"two GSM segments", strings.Repeat("a", 161), 2, SMSEncodingGSM7},
+		{"extension characters count twice", strings.Repeat("{", 81), 2, SMSEncodingGSM7},
+		{"full UCS-2 segment", strings.Repeat("ā", 70), 1, SMSEncodingUCS2},
+		{"two UCS-2 segments", strings.Repeat("ā", 71), 2, SMSEncodingUCS2},
+		{"one emoji makes it UCS-2", "Your book is ready 📚", 1, SMSEncodingUCS2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			segments, encoding := SMSSegments(tc.text)
+
+			assert.Equal(t, tc.expectedSegments, segments)
+			assert.Equal(t, tc.expectedEncoding, encoding)
+		})
+	}
+}
+
+func TestGetSMSTemplate(t *testing.T) {
+	assert.Contains(t, GetSMSTemplate("due_soon", "en"), "is due on")
+	assert.Contains(t, GetSMSTemplate("due_soon", "sw"), "kinarudishwa")
+	assert.Contains(t, GetSMSTemplate("book_available", "fr"), "ready")
+	assert.Empty(t, GetSMSTemplate("recall_notice", "en"))
+}
+
+func TestRenderSMS(t *testing.T) {
+	t.Run("short values fill the template", func(t *testing.T) {
+		message := RenderSMS(GetSMSTemplate("book_available", "en"), map[string]interface{}{
+			"BookTitle":      "Dune",
+			"ExpirationDays": 3,
+		})
+
+		assert.Equal(t, `Library: "Dune" that you reserved is ready. Please collect it within 3 day(s).`, message)
+	})
+
+	t.Run("long title is shortened to fit one segment", func(t *testing.T) {
+		title := "The Remarkable and Extremely Long Account of a Library Book Whose Title Never Seems to End"
+		for _, locale := range []string{models.LocaleEnglish, models.LocaleSwahili} {
+			message := RenderSMS(GetSMSTemplate("due_soon", locale), map[string]interface{}{
+				"BookTitle": title,
+				"DueDate":   "March 5, 2025",
+			})
+
+			segments, encoding := SMSSegments(message)
+			assert.Equal(t, 1, segments, locale)
+			assert.Equal(t, SMSEncodingGSM7, encoding, locale)
+			assert.Contains(t, message, `"The Remarkable and`, locale)
+			assert.Contains(t, message, `..."`, locale)
+			assert.Contains(t, message, "March 5, 2025", locale)
+		}
+	})
+
+	t.Run("message without a title is cut off", func(t *testing.T) {
+		message := RenderSMS(strings.Repeat("Please return your books. ", 10), nil)
+
+		segments, _ := SMSSegments(message)
+		assert.Equal(t, 1, segments)
+		assert.Len(t, message, 160)
+	})
+}
+
+func TestSMSService_SendNotificationSMS(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends and records delivery", func(t *testing.T) {
+		service, querier, provider := newTestSMSService()
+
+		querier.On("HasSentSmsDelivery", ctx, int32(5)).Return(false, nil)
+		querier.On("CreateSmsDelivery", ctx, queries.CreateSmsDeliveryParams{
+			NotificationID: 5,
+			PhoneNumber:    "+254712345678",
+			Message:        "Your book is ready",
+			Segments:       1,
+			Encoding:       SMSEncodingGSM7,
+			Provider:       "fake",
+		}).Return(queries.SmsDelivery{ID: 9}, nil)
+		querier.On("UpdateSmsDeliveryToSent", mock.Anything, queries.UpdateSmsDeliveryToSentParams{
+			ID:                9,
+			ProviderMessageID: pgtype.Text{String: "fake-1", Valid: true},
+		}).Return(queries.SmsDelivery{ID: 9}, nil)
+
+		err := service.SendNotificationSMS(ctx, 5, "0712345678", "Your book is ready")
+
+		require.NoError(t, err)
+		assert.Equal(t, []SMSMessage{{To: "+254712345678", Body: "Your book is ready"}}, provider.Messages())
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("already sent is not sent again", func(t *testing.T) {
+		service, querier, provider := newTestSMSService()
+
+		querier.On("HasSentSmsDelivery", ctx, int32(5)).Return(true, nil)
+
+		err := service.SendNotificationSMS(ctx, 5, "0712345678", "Your book is ready")
+
+		require.NoError(t, err)
+		assert.Empty(t, provider.Messages())
+		querier.AssertNotCalled(t, "CreateSmsDelivery", mock.Anything, mock.Anything)
+	})
+
+	t.Run("send failure is recorded and returned", func(t *testing.T) {
+		service, querier, provider := newTestSMSService()
+		provider.FailWith(fmt.Errorf("insufficient balance"))
+
+		querier.On("HasSentSmsDelivery", ctx, int32(5)).Return(false, nil)
+		querier.On("CreateSmsDelivery", ctx, mock.Anything).Return(queries.SmsDelivery{ID: 9}, nil)
+		querier.On("UpdateSmsDeliveryError", mock.Anything, queries.UpdateSmsDeliveryErrorParams{
+			ID:           9,
+			ErrorMessage: pgtype.Text{String: "insufficient balance", Valid: true},
+		}).Return(queries.SmsDelivery{ID: 9}, nil)
+
+		err := service.SendNotificationSMS(ctx, 5, "0712345678", "Your book is ready")
+
+		assert.EqualError(t, err, "insufficient balance")
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("invalid phone is not sent", func(t *testing.T) {
+		service, querier, provider := newTestSMSService()
+
+		err := service.SendNotificationSMS(ctx, 5, "123", "Your book is ready")
+
+		assert.Error(t, err)
+		assert.Empty(t, provider.Messages())
+		querier.AssertNotCalled(t, "HasSentSmsDelivery", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSMSService_HandleDeliveryReport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("marks delivery delivered", func(t *testing.T) {
+		service, querier, _ := newTestSMSService()
+
+		querier.On("UpdateSmsDeliveryReport", ctx, queries.UpdateSmsDeliveryReportParams{
+			ProviderMessageID: pgtype.Text{String: "fake-1", Valid: true},
+			Status:            "delivered",
+		}).Return(queries.SmsDelivery{ID: 9, NotificationID: 5, Status: "delivered"}, nil)
+
+		delivery, err := service.HandleDeliveryReport(ctx, "", url.Values{"id": {"fake-1"}, "status": {"Success"}})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.SMSDeliveryStatusDelivered, delivery.Status)
+		querier.AssertExpectations(t)
+	})
+
+	t.Run("unknown message is ignored", func(t *testing.T) {
+		service, querier, _ := newTestSMSService()
+
+		querier.On("UpdateSmsDeliveryReport", ctx, mock.Anything).Return(queries.SmsDelivery{}, pgx.ErrNoRows)
+
+		delivery, err := service.HandleDeliveryReport(ctx, "", url.Values{"id": {"other"}, "status": {"Failed"}})
+
+		require.NoError(t, err)
+		assert.Nil(t, delivery)
+	})
+}
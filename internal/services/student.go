@@ -93,6 +93,10 @@ func (s *StudentService) CreateStudent(ctx context.Context, req *models.CreateSt
 		FirstName:   req.FirstName,
 		LastName:    req.LastName,
 		YearOfStudy: req.YearOfStudy,
+		Locale:      req.Locale,
+	}
+	if params.Locale == "" {
+		params.Locale = models.DefaultLocale
 	}
 
 	// Handle optional fields
@@ -131,6 +135,7 @@ func (s *StudentService) CreateStudent(ctx context.Context, req *models.CreateSt
 		Department:     student.Department,
 		EnrollmentDate: student.EnrollmentDate,
 		PasswordHash:   student.PasswordHash,
+		Locale:         student.Locale,
 		IsActive:       student.IsActive,
 		DeletedAt:      student.DeletedAt,
 		CreatedAt:      student.CreatedAt,
@@ -159,6 +164,7 @@ func (s *StudentService) GetStudentByID(ctx context.Context, id int32) (*models.
 		Department:     student.Department,
 		EnrollmentDate: student.EnrollmentDate,
 		PasswordHash:   student.PasswordHash,
+		Locale:         student.Locale,
 		IsActive:       student.IsActive,
 		DeletedAt:      student.DeletedAt,
 		CreatedAt:      student.CreatedAt,
@@ -187,6 +193,7 @@ func (s *StudentService) GetStudentByStudentID(ctx context.Context, studentID st
 		Department:     student.Department,
 		EnrollmentDate: student.EnrollmentDate,
 		PasswordHash:   student.PasswordHash,
+		Locale:         student.Locale,
 		IsActive:       student.IsActive,
 		DeletedAt:      student.DeletedAt,
 		CreatedAt:      student.CreatedAt,
@@ -204,7 +211,7 @@ func (s *StudentService) UpdateStudent(ctx context.Context, id int32, req *model
 	}
 
 	// Check if student exists
-	_, err := s.queries.GetStudentByID(ctx, id)
+	currentStudent, err := s.queries.GetStudentByID(ctx, id)
 	if err != nil {
 		return nil, models.ErrStudentNotFound
 	}
@@ -224,6 +231,7 @@ func (s *StudentService) UpdateStudent(ctx context.Context, id int32, req *model
 		FirstName:   req.FirstName,
 		LastName:    req.LastName,
 		YearOfStudy: req.YearOfStudy,
+		Locale:      currentStudent.Locale,
 	}
 
 	// Handle optional fields
@@ -236,6 +244,9 @@ func (s *StudentService) UpdateStudent(ctx context.Context, id int32, req *model
 	if req.Department != "" {
 		params.Department = pgtype.Text{String: req.Department, Valid: true}
 	}
+	if req.Locale != "" {
+		params.Locale = req.Locale
+	}
 
 	// Update the student
 	student, err := s.queries.UpdateStudent(ctx, params)
@@ -255,6 +266,7 @@ func (s *StudentService) UpdateStudent(ctx context.Context, id int32, req *model
 		Department:     student.Department,
 		EnrollmentDate: student.EnrollmentDate,
 		PasswordHash:   student.PasswordHash,
+		Locale:         student.Locale,
 		IsActive:       student.IsActive,
 		DeletedAt:      student.DeletedAt,
 		CreatedAt:      student.CreatedAt,
@@ -293,6 +305,7 @@ func (s *StudentService) UpdateStudentProfile(ctx context.Context, id int32, req
 		LastName:    req.LastName,
 		YearOfStudy: currentStudent.YearOfStudy, // Keep current year
 		Department:  currentStudent.Department,  // Keep current department
+		Locale:      currentStudent.Locale,
 	}
 
 	// Handle optional fields
@@ -302,6 +315,9 @@ func (s *StudentService) UpdateStudentProfile(ctx context.Context, id int32, req
 	if req.Phone != "" {
 		params.Phone = pgtype.Text{String: req.Phone, Valid: true}
 	}
+	if req.Locale != "" {
+		params.Locale = req.Locale
+	}
 
 	// Update the student
 	student, err := s.queries.UpdateStudent(ctx, params)
@@ -321,6 +337,7 @@ func (s *StudentService) UpdateStudentProfile(ctx context.Context, id int32, req
 		Department:     student.Department,
 		EnrollmentDate: student.EnrollmentDate,
 		PasswordHash:   student.PasswordHash,
+		Locale:         student.Locale,
 		IsActive:       student.IsActive,
 		DeletedAt:      student.DeletedAt,
 		CreatedAt:      student.CreatedAt,
@@ -402,6 +419,7 @@ func (s *StudentService) ListStudents(ctx context.Context, req *models.StudentSe
 			Department:     student.Department,
 			EnrollmentDate: student.EnrollmentDate,
 			PasswordHash:   student.PasswordHash,
+			Locale:         student.Locale,
 			IsActive:       student.IsActive,
 			DeletedAt:      student.DeletedAt,
 			CreatedAt:      student.CreatedAt,
@@ -480,6 +498,7 @@ func (s *StudentService) SearchStudents(ctx context.Context, req *models.Student
 			Department:     student.Department,
 			EnrollmentDate: student.EnrollmentDate,
 			PasswordHash:   student.PasswordHash,
+			Locale:         student.Locale,
 			IsActive:       student.IsActive,
 			DeletedAt:      student.DeletedAt,
 			CreatedAt:      student.CreatedAt,
@@ -758,6 +777,7 @@ func (s *StudentService) ListStudentsByYearWithDetails(ctx context.Context, year
 			Department:     student.Department,
 			EnrollmentDate: student.EnrollmentDate,
 			PasswordHash:   student.PasswordHash,
+			Locale:         student.Locale,
 			IsActive:       student.IsActive,
 			DeletedAt:      student.DeletedAt,
 			CreatedAt:      student.CreatedAt,
@@ -1089,6 +1109,7 @@ func (s *StudentService) UpdateStudentStatus(ctx context.Context, studentID int3
 		Department:     student.Department,
 		EnrollmentDate: student.EnrollmentDate,
 		PasswordHash:   student.PasswordHash,
+		Locale:         student.Locale,
 		IsActive:       student.IsActive,
 		DeletedAt:      student.DeletedAt,
 		CreatedAt:      student.CreatedAt,
@@ -1131,6 +1152,7 @@ func (s *StudentService) GetStudentsByStatus(ctx context.Context, isActive bool,
 			Department:     student.Department,
 			EnrollmentDate: student.EnrollmentDate,
 			PasswordHash:   student.PasswordHash,
+			Locale:         student.Locale,
 			IsActive:       student.IsActive,
 			DeletedAt:      student.DeletedAt,
 			CreatedAt:      student.CreatedAt,
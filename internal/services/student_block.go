@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// ErrStudentBlocked is returned when a blocked student tries to borrow, renew or reserve
+var ErrStudentBlocked = errors.New("student account is blocked")
+
+// StudentBlockQuerier defines the queries needed to manage and check account blocks
+type StudentBlockQuerier interface {
+	GetStudentByID(ctx context.Context, id int32) (queries.Student, error)
+	CreateStudentBlock(ctx context.Context, arg queries.CreateStudentBlockParams) (queries.StudentBlock, error)
+	GetStudentBlockByIDForUpdate(ctx context.Context, id int32) (queries.StudentBlock, error)
+	LiftStudentBlock(ctx context.Context, arg queries.LiftStudentBlockParams) (queries.StudentBlock, error)
+	ListActiveStudentBlocks(ctx context.Context, studentID int32) ([]queries.StudentBlock, error)
+	ListStudentBlocks(ctx context.Context, studentID int32) ([]queries.StudentBlock, error)
+	GetStudentFineBalance(ctx context.Context, studentID int32) (queries.GetStudentFineBalanceRow, error)
+	ListActiveTransactionsByStudent(ctx context.Context, studentID int32) ([]queries.ListActiveTransactionsByStudentRow, error)
+	CreateAuditLog(ctx context.Context, arg queries.CreateAuditLogParams) error
+	// ExecTx runs fn inside a single database transaction, committing only if fn succeeds
+	ExecTx(ctx context.Context, fn func(StudentBlockQuerier) error) error
+}
+
+// StudentBlockServiceInterface defines the interface for account block operations
+type StudentBlockServiceInterface interface {
+	PlaceBlock(ctx context.Context, studentID int32, req models.CreateStudentBlockRequest, actor AuditActor) (*models.StudentBlockResponse, error)
+	LiftBlock(ctx context.Context, studentID, blockID int32, req models.LiftStudentBlockRequest, actor AuditActor) (*models.StudentBlockResponse, error)
+	GetStudentBlocks(ctx context.Context, studentID int32) (*models.StudentBlocksResponse, error)
+	ActiveBlocks(ctx context.Context, studentID int32) ([]models.StudentBlockResponse, error)
+	CheckNotBlocked(ctx context.Context, studentID int32) error
+}
+
+// BlockChecker refuses borrowing, renewals and reservations for blocked students;
+// StudentBlockService implements it
+type BlockChecker interface {
+	CheckNotBlocked(ctx context.Context, studentID int32) error
+}
+
+// StudentBlockService manages librarian-placed blocks and works out the automatic
+// blocks for unpaid fines and long overdue loans
+type StudentBlockService struct {
+	queries       StudentBlockQuerier
+	fineThreshold decimal.Decimal
+	overdueDays   int
+	notifier      StudentNotifier
+}
+
+// NewStudentBlockService creates a new account block service with default limits
+func NewStudentBlockService(querier StudentBlockQuerier) *StudentBlockService {
+	return &StudentBlockService{
+		queries:       querier,
+		fineThreshold: decimal.NewFromInt(10), // Block once more than 10.00 is owed
+		overdueDays:   30,                     // Block once a loan is more than 30 days overdue
+	}
+}
+
+// WithFineThreshold sets how much a student may owe before being blocked; zero disables the block
+func (s *StudentBlockService) WithFineThreshold(threshold decimal.Decimal) *StudentBlockService {
+	s.fineThreshold = threshold
+	return s
+}
+
+// WithOverdueDays sets how long a loan may be overdue before the student is blocked; zero disables the block
+func (s *StudentBlockService) WithOverdueDays(days int) *StudentBlockService {
+	s.overdueDays = days
+	return s
+}
+
+// WithNotifier sends students the notice that their account has been blocked
+func (s *StudentBlockService) WithNotifier(notifier StudentNotifier) *StudentBlockService {
+	s.notifier = notifier
+	return s
+}
+
+// PlaceBlock blocks a student's account until the block is lifted or expires
+func (s *StudentBlockService) PlaceBlock(ctx context.Context, studentID int32, req models.CreateStudentBlockRequest, actor AuditActor) (*models.StudentBlockResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	var response models.StudentBlockResponse
+	var notice *models.NotificationRequest
+	err := s.queries.ExecTx(ctx, func(q StudentBlockQuerier) error {
+		student, err := q.GetStudentByID(ctx, studentID)
+		if err != nil {
+			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+				return fmt.Errorf("student not found")
+			}
+			return fmt.Errorf("failed to get student: %w", err)
+		}
+
+		params := queries.CreateStudentBlockParams{
+			StudentID: studentID,
+			Reason:    req.Reason,
+		}
+		if req.ExpiresAt != nil {
+			params.ExpiresAt = pgtype.Timestamp{Time: *req.ExpiresAt, Valid: true}
+		}
+		if actor.UserID > 0 {
+			params.CreatedBy = pgtype.Int4{Int32: actor.UserID, Valid: true}
+		}
+
+		block, err := q.CreateStudentBlock(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to create block: %w", err)
+		}
+
+		notice, err = studentNotice(studentID, models.NotificationTypeAccountBlocked, models.NotificationPriorityHigh, student.Locale, map[string]interface{}{
+			"StudentName": student.FirstName + " " + student.LastName,
+			"BlockReason": req.Reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		response = convertToStudentBlockResponse(block)
+		return writeAuditLog(ctx, q, actor, "student_blocks", block.ID, "CREATE", nil, response)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The student is only told once the block has committed
+	sendStudentNotice(ctx, s.notifier, notice)
+	return &response, nil
+}
+
+// LiftBlock ends a librarian-placed block early
+func (s *StudentBlockService) LiftBlock(ctx context.Context, studentID, blockID int32, req models.LiftStudentBlockRequest, actor AuditActor) (*models.StudentBlockResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+
+	var response models.StudentBlockResponse
+	err := s.queries.ExecTx(ctx, func(q StudentBlockQuerier) error {
+		existing, err := q.GetStudentBlockByIDForUpdate(ctx, blockID)
+		if err != nil {
+			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+				return fmt.Errorf("block not found")
+			}
+			return fmt.Errorf("failed to get block: %w", err)
+		}
+		if existing.StudentID != studentID {
+			return fmt.Errorf("block not found")
+		}
+		if existing.LiftedAt.Valid {
+			return fmt.Errorf("validation error: block has already been lifted")
+		}
+
+		params := queries.LiftStudentBlockParams{
+			ID:         blockID,
+			LiftReason: pgtype.Text{String: req.Reason, Valid: true},
+		}
+		if actor.UserID > 0 {
+			params.LiftedBy = pgtype.Int4{Int32: actor.UserID, Valid: true}
+		}
+
+		block, err := q.LiftStudentBlock(ctx, params)
+		if err != nil {
+			return fmt.Errorf("failed to lift block: %w", err)
+		}
+
+		response = convertToStudentBlockResponse(block)
+		return writeAuditLog(ctx, q, actor, "student_blocks", block.ID, "UPDATE", convertToStudentBlockResponse(existing), response)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetStudentBlocks returns the blocks in force on a student's account and the
+// history of blocks placed by librarians
+func (s *StudentBlockService) GetStudentBlocks(ctx context.Context, studentID int32) (*models.StudentBlocksResponse, error) {
+	if _, err := s.queries.GetStudentByID(ctx, studentID); err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("student not found")
+		}
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+
+	active, err := s.ActiveBlocks(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	placed, err := s.queries.ListStudentBlocks(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocks: %w", err)
+	}
+
+	history := make([]models.StudentBlockResponse, 0, len(placed))
+	for _, block := range placed {
+		history = append(history, convertToStudentBlockResponse(block))
+	}
+
+	return &models.StudentBlocksResponse{
+		StudentID: studentID,
+		IsBlocked: len(active) > 0,
+		Active:    active,
+		History:   history,
+	}, nil
+}
+
+// ActiveBlocks returns every block currently stopping the student from borrowing,
+// renewing or reserving: unexpired librarian blocks followed by any automatic ones
+func (s *StudentBlockService) ActiveBlocks(ctx context.Context, studentID int32) ([]models.StudentBlockResponse, error) {
+	placed, err := s.queries.ListActiveStudentBlocks(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocks: %w", err)
+	}
+
+	blocks := make([]models.StudentBlockResponse, 0, len(placed))
+	for _, block := range placed {
+		blocks = append(blocks, convertToStudentBlockResponse(block))
+	}
+
+	if block, err := s.fineBlock(ctx, studentID); err != nil {
+		return nil, err
+	} else if block != nil {
+		blocks = append(blocks, *block)
+	}
+
+	if block, err := s.overdueBlock(ctx, studentID); err != nil {
+		return nil, err
+	} else if block != nil {
+		blocks = append(blocks, *block)
+	}
+
+	return blocks, nil
+}
+
+// CheckNotBlocked returns an error wrapping ErrStudentBlocked, with the reason for
+// the first block, if the student may not borrow, renew or reserve
+func (s *StudentBlockService) CheckNotBlocked(ctx context.Context, studentID int32) error {
+	blocks, err := s.ActiveBlocks(ctx, studentID)
+	if err != nil {
+		return fmt.Errorf("failed to check account blocks: %w", err)
+	}
+	if len(blocks) > 0 {
+		return fmt.Errorf("%w: %s", ErrStudentBlocked, blocks[0].Reason)
+	}
+	return nil
+}
+
+// fineBlock applies when the student's outstanding fines exceed the threshold
+func (s *StudentBlockService) fineBlock(ctx context.Context, studentID int32) (*models.StudentBlockResponse, error) {
+	if !s.fineThreshold.IsPositive() {
+		return nil, nil
+	}
+
+	balance, err := s.queries.GetStudentFineBalance(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fine balance: %w", err)
+	}
+
+	owed := numericToDecimal(balance.Balance)
+	if !owed.GreaterThan(s.fineThreshold) {
+		return nil, nil
+	}
+
+	return &models.StudentBlockResponse{
+		StudentID: studentID,
+		BlockType: models.StudentBlockTypeFines,
+		Reason:    fmt.Sprintf("unpaid fines of %s exceed the limit of %s", owed.StringFixed(2), s.fineThreshold.StringFixed(2)),
+	}, nil
+}
+
+// overdueBlock applies when any loan still out is overdue by more than the allowed days
+func (s *StudentBlockService) overdueBlock(ctx context.Context, studentID int32) (*models.StudentBlockResponse, error) {
+	if s.overdueDays <= 0 {
+		return nil, nil
+	}
+
+	loans, err := s.queries.ListActiveTransactionsByStudent(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check active transactions: %w", err)
+	}
+
+	now := time.Now()
+	worst := 0
+	for _, loan := range currentLoans(loans) {
+		if !loan.DueDate.Valid {
+			continue
+		}
+		if days := int(now.Sub(loan.DueDate.Time).Hours() / 24); days > worst {
+			worst = days
+		}
+	}
+	if worst <= s.overdueDays {
+		return nil, nil
+	}
+
+	return &models.StudentBlockResponse{
+		StudentID: studentID,
+		BlockType: models.StudentBlockTypeOverdue,
+		Reason:    fmt.Sprintf("a loan has been overdue for %d days, more than the %d allowed", worst, s.overdueDays),
+	}, nil
+}
+
+// currentLoans drops borrow rows that have been superseded by a later renewal of
+// the same book, which stay open alongside the renewal
+func currentLoans(loans []queries.ListActiveTransactionsByStudentRow) []queries.ListActiveTransactionsByStudentRow {
+	latest := make(map[int32]int32, len(loans))
+	for _, loan := range loans {
+		if loan.ID > latest[loan.BookID] {
+			latest[loan.BookID] = loan.ID
+		}
+	}
+
+	current := make([]queries.ListActiveTransactionsByStudentRow, 0, len(loans))
+	for _, loan := range loans {
+		if loan.ID == latest[loan.BookID] {
+			current = append(current, loan)
+		}
+	}
+	return current
+}
+
+func convertToStudentBlockResponse(block queries.StudentBlock) models.StudentBlockResponse {
+	id := block.ID
+	response := models.StudentBlockResponse{
+		ID:        &id,
+		StudentID: block.StudentID,
+		BlockType: models.StudentBlockTypeManual,
+		Reason:    block.Reason,
+	}
+
+	if block.ExpiresAt.Valid {
+		response.ExpiresAt = &block.ExpiresAt.Time
+	}
+	if block.CreatedBy.Valid {
+		response.CreatedBy = &block.CreatedBy.Int32
+	}
+	if block.CreatedAt.Valid {
+		response.CreatedAt = &block.CreatedAt.Time
+	}
+	if block.LiftedAt.Valid {
+		response.LiftedAt = &block.LiftedAt.Time
+	}
+	if block.LiftedBy.Valid {
+		response.LiftedBy = &block.LiftedBy.Int32
+	}
+	if block.LiftReason.Valid {
+		response.LiftReason = &block.LiftReason.String
+	}
+
+	return response
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+)
+
+// StudentBlockStore provides the account block queries backed by a connection pool
+// and the ability to run several of them inside one database transaction
+type StudentBlockStore struct {
+	*queries.Queries
+	pool *pgxpool.Pool
+}
+
+// NewStudentBlockStore creates a new account block store for the given pool
+func NewStudentBlockStore(pool *pgxpool.Pool) *StudentBlockStore {
+	return &StudentBlockStore{
+		Queries: queries.New(pool),
+		pool:    pool,
+	}
+}
+
+// ExecTx runs fn inside a database transaction, committing only if fn succeeds.
+// Calls made on a store that is already bound to a transaction reuse it.
+func (s *StudentBlockStore) ExecTx(ctx context.Context, fn func(StudentBlockQuerier) error) error {
+	if s.pool == nil {
+		return fn(s)
+	}
+
+	return runInTx(ctx, s.pool, func(tx pgx.Tx) error {
+		return fn(&StudentBlockStore{Queries: s.Queries.WithTx(tx)})
+	})
+}
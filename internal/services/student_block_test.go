@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// MockStudentBlockQuerier is a mock implementation of StudentBlockQuerier
+type MockStudentBlockQuerier struct {
+	mock.Mock
+}
+
+func (m *MockStudentBlockQuerier) GetStudentByID(ctx context.Context, id int32) (queries.Student, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Student), args.Error(1)
+}
+
+func (m *MockStudentBlockQuerier) CreateStudentBlock(ctx context.Context, arg queries.CreateStudentBlockParams) (queries.StudentBlock, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.StudentBlock), args.Error(1)
+}
+
+func (m *MockStudentBlockQuerier) GetStudentBlockByIDForUpdate(ctx context.Context, id int32) (queries.StudentBlock, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.StudentBlock), args.Error(1)
+}
+
+func (m *MockStudentBlockQuerier) LiftStudentBlock(ctx context.Context, arg queries.LiftStudentBlockParams) (queries.StudentBlock, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.StudentBlock), args.Error(1)
+}
+
+func (m *MockStudentBlockQuerier) ListActiveStudentBlocks(ctx context.Context, studentID int32) ([]queries.StudentBlock, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]queries.StudentBlock), args.Error(1)
+}
+
+func (m *MockStudentBlockQuerier) ListStudentBlocks(ctx context.Context, studentID int32) ([]queries.StudentBlock, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]queries.StudentBlock), args.Error(1)
+}
+
+func (m *MockStudentBlockQuerier) GetStudentFineBalance(ctx context.Context, studentID int32) (queries.GetStudentFineBalanceRow, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).(queries.GetStudentFineBalanceRow), args.Error(1)
+}
+
+func (m *MockStudentBlockQuerier) ListActiveTransactionsByStudent(ctx context.Context, studentID int32) ([]queries.ListActiveTransactionsByStudentRow, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]queries.ListActiveTransactionsByStudentRow), args.Error(1)
+}
+
+func (m *MockStudentBlockQuerier) CreateAuditLog(ctx context.Context, arg queries.CreateAuditLogParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+// ExecTx runs fn directly against the mock; transactional behaviour is covered by integration tests
+func (m *MockStudentBlockQuerier) ExecTx(ctx context.Context, fn func(StudentBlockQuerier) error) error {
+	return fn(m)
+}
+
+// MockBlockChecker is a mock implementation of BlockChecker
+type MockBlockChecker struct {
+	mock.Mock
+}
+
+func (m *MockBlockChecker) CheckNotBlocked(ctx context.Context, studentID int32) error {
+	args := m.Called(ctx, studentID)
+	return args.Error(0)
+}
+
+func createTestStudentBlock() queries.StudentBlock {
+	return queries.StudentBlock{
+		ID:        3,
+		StudentID: 1,
+		Reason:    "Damaged three books this term",
+		CreatedBy: pgtype.Int4{Int32: 9, Valid: true},
+		CreatedAt: pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}
+}
+
+func TestStudentBlockService_PlaceBlock(t *testing.T) {
+	ctx := context.Background()
+	actor := AuditActor{UserID: 9, UserType: "librarian"}
+
+	t.Run("Success", func(t *testing.T) {
+		mockQuerier := &MockStudentBlockQuerier{}
+		notifier := &MockStudentNotifier{}
+		service := NewStudentBlockService(mockQuerier).WithNotifier(notifier)
+		expiresAt := time.Now().AddDate(0, 1, 0)
+
+		mockQuerier.On("GetStudentByID", ctx, int32(1)).Return(createTestStudent(), nil)
+		mockQuerier.On("CreateStudentBlock", ctx, queries.CreateStudentBlockParams{
+			StudentID: 1,
+			Reason:    "Damaged three books this term",
+			ExpiresAt: pgtype.Timestamp{Time: expiresAt, Valid: true},
+			CreatedBy: pgtype.Int4{Int32: 9, Valid: true},
+		}).Return(createTestStudentBlock(), nil)
+		notifier.On("CreateNotification", ctx, mock.MatchedBy(func(req *models.NotificationRequest) bool {
+			return req.RecipientID == 1 && req.Type == models.NotificationTypeAccountBlocked &&
+				assert.ObjectsAreEqual(emailAndSMS, req.Channels) &&
+				strings.Contains(req.Message, "Dear John Doe") &&
+				strings.Contains(req.Message, "Reason: Damaged three books this term")
+		})).Return(&models.NotificationResponse{ID: 12}, nil)
+		mockQuerier.On("CreateAuditLog", ctx, mock.MatchedBy(func(arg queries.CreateAuditLogParams) bool {
+			return arg.TableName == "student_blocks" && arg.RecordID == 3 && arg.Action == "CREATE"
+		})).Return(nil)
+
+		block, err := service.PlaceBlock(ctx, 1, models.CreateStudentBlockRequest{
+			Reason:    "  Damaged three books this term ",
+			ExpiresAt: &expiresAt,
+		}, actor)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.StudentBlockTypeManual, block.BlockType)
+		require.NotNil(t, block.ID)
+		assert.Equal(t, int32(3), *block.ID)
+		mockQuerier.AssertExpectations(t)
+		notifier.AssertExpectations(t)
+	})
+
+	t.Run("StudentNotFound", func(t *testing.T) {
+		mockQuerier := &MockStudentBlockQuerier{}
+		service := NewStudentBlockService(mockQuerier)
+
+		mockQuerier.On("GetStudentByID", ctx, int32(1)).Return(queries.Student{}, pgx.ErrNoRows)
+
+		_, err := service.PlaceBlock(ctx, 1, models.CreateStudentBlockRequest{Reason: "Lost ID card"}, actor)
+
+		require.Error(t, err)
+		assert.Equal(t, "student not found", err.Error())
+		mockQuerier.AssertNotCalled(t, "CreateStudentBlock", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ExpiryInThePast", func(t *testing.T) {
+		service := NewStudentBlockService(&MockStudentBlockQuerier{})
+		expiresAt := time.Now().Add(-time.Hour)
+
+		_, err := service.PlaceBlock(ctx, 1, models.CreateStudentBlockRequest{Reason: "Lost ID card", ExpiresAt: &expiresAt}, actor)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expires_at must be in the future")
+	})
+}
+
+func TestStudentBlockService_LiftBlock(t *testing.T) {
+	ctx := context.Background()
+	actor := AuditActor{UserID: 9, UserType: "librarian"}
+	req := models.LiftStudentBlockRequest{Reason: "Books paid for"}
+
+	t.Run("Success", func(t *testing.T) {
+		mockQuerier := &MockStudentBlockQuerier{}
+		service := NewStudentBlockService(mockQuerier)
+
+		lifted := createTestStudentBlock()
+		lifted.LiftedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+		lifted.LiftedBy = pgtype.Int4{Int32: 9, Valid: true}
+		lifted.LiftReason = pgtype.Text{String: "Books paid for", Valid: true}
+
+		mockQuerier.On("GetStudentBlockByIDForUpdate", ctx, int32(3)).Return(createTestStudentBlock(), nil)
+		mockQuerier.On("LiftStudentBlock", ctx, queries.LiftStudentBlockParams{
+			ID:         3,
+			LiftedBy:   pgtype.Int4{Int32: 9, Valid: true},
+			LiftReason: pgtype.Text{String: "Books paid for", Valid: true},
+		}).Return(lifted, nil)
+		mockQuerier.On("CreateAuditLog", ctx, mock.MatchedBy(func(arg queries.CreateAuditLogParams) bool {
+			return arg.TableName == "student_blocks" && arg.Action == "UPDATE" && arg.OldValues != nil
+		})).Return(nil)
+
+		block, err := service.LiftBlock(ctx, 1, 3, req, actor)
+
+		require.NoError(t, err)
+		require.NotNil(t, block.LiftReason)
+		assert.Equal(t, "Books paid for", *block.LiftReason)
+		mockQuerier.AssertExpectations(t)
+	})
+
+	t.Run("BlockBelongsToAnotherStudent", func(t *testing.T) {
+		mockQuerier := &MockStudentBlockQuerier{}
+		service := NewStudentBlockService(mockQuerier)
+
+		mockQuerier.On("GetStudentBlockByIDForUpdate", ctx, int32(3)).Return(createTestStudentBlock(), nil)
+
+		_, err := service.LiftBlock(ctx, 2, 3, req, actor)
+
+		require.Error(t, err)
+		assert.Equal(t, "block not found", err.Error())
+		mockQuerier.AssertNotCalled(t, "LiftStudentBlock", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AlreadyLifted", func(t *testing.T) {
+		mockQuerier := &MockStudentBlockQuerier{}
+		service := NewStudentBlockService(mockQuerier)
+
+		block := createTestStudentBlock()
+		block.LiftedAt = pgtype.Timestamp{Time: time.Now(), Valid: true}
+		mockQuerier.On("GetStudentBlockByIDForUpdate", ctx, int32(3)).Return(block, nil)
+
+		_, err := service.LiftBlock(ctx, 1, 3, req, actor)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already been lifted")
+	})
+}
+
+func TestStudentBlockService_ActiveBlocks(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("NoBlocks", func(t *testing.T) {
+		mockQuerier := &MockStudentBlockQuerier{}
+		service := NewStudentBlockService(mockQuerier)
+
+		mockQuerier.On("ListActiveStudentBlocks", ctx, int32(1)).Return([]queries.StudentBlock{}, nil)
+		mockQuerier.On("GetStudentFineBalance", ctx, int32(1)).Return(queries.GetStudentFineBalanceRow{Balance: testMoney("10.00")}, nil)
+		mockQuerier.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{
+			{ID: 4, BookID: 2, DueDate: pgtype.Timestamp{Time: now.AddDate(0, 0, -30), Valid: true}},
+		}, nil)
+
+		blocks, err := service.ActiveBlocks(ctx, 1)
+
+		require.NoError(t, err)
+		assert.Empty(t, blocks)
+		assert.NoError(t, service.CheckNotBlocked(ctx, 1))
+	})
+
+	t.Run("ManualAndAutomaticBlocks", func(t *testing.T) {
+		mockQuerier := &MockStudentBlockQuerier{}
+		service := NewStudentBlockService(mockQuerier).WithFineThreshold(decimal.NewFromInt(5)).WithOverdueDays(14)
+
+		mockQuerier.On("ListActiveStudentBlocks", ctx, int32(1)).Return([]queries.StudentBlock{createTestStudentBlock()}, nil)
+		mockQuerier.On("GetStudentFineBalance", ctx, int32(1)).Return(queries.GetStudentFineBalanceRow{Balance: testMoney("7.50")}, nil)
+		mockQuerier.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{
+			{ID: 4, BookID: 2, DueDate: pgtype.Timestamp{Time: now.AddDate(0, 0, -20), Valid: true}},
+		}, nil)
+
+		blocks, err := service.ActiveBlocks(ctx, 1)
+
+		require.NoError(t, err)
+		require.Len(t, blocks, 3)
+		assert.Equal(t, models.StudentBlockTypeManual, blocks[0].BlockType)
+		assert.Equal(t, models.StudentBlockTypeFines, blocks[1].BlockType)
+		assert.Contains(t, blocks[1].Reason, "7.50")
+		assert.Equal(t, models.StudentBlockTypeOverdue, blocks[2].BlockType)
+		assert.Nil(t, blocks[2].ID)
+
+		err = service.CheckNotBlocked(ctx, 1)
+		assert.ErrorIs(t, err, ErrStudentBlocked)
+		assert.Contains(t, err.Error(), "Damaged three books this term")
+	})
+
+	t.Run("IgnoresLoanSupersededByRenewal", func(t *testing.T) {
+		mockQuerier := &MockStudentBlockQuerier{}
+		service := NewStudentBlockService(mockQuerier).WithFineThreshold(decimal.Zero).WithOverdueDays(14)
+
+		mockQuerier.On("ListActiveStudentBlocks", ctx, int32(1)).Return([]queries.StudentBlock{}, nil)
+		mockQuerier.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{
+			{ID: 4, BookID: 2, TransactionType: "borrow", DueDate: pgtype.Timestamp{Time: now.AddDate(0, 0, -20), Valid: true}},
+			{ID: 8, BookID: 2, TransactionType: "renew", DueDate: pgtype.Timestamp{Time: now.AddDate(0, 0, 7), Valid: true}},
+		}, nil)
+
+		blocks, err := service.ActiveBlocks(ctx, 1)
+
+		require.NoError(t, err)
+		assert.Empty(t, blocks)
+		mockQuerier.AssertNotCalled(t, "GetStudentFineBalance", mock.Anything, mock.Anything)
+	})
+}
+
+func TestStudentBlocks_Enforcement(t *testing.T) {
+	ctx := context.Background()
+	studentID := int32(1)
+	blocked := ErrStudentBlocked
+
+	t.Run("Borrow", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		checker := &MockBlockChecker{}
+		service := NewTransactionService(mockQueries).WithBlockChecker(checker)
+
+		mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(createTestBook(), nil)
+		mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(createTestStudent(), nil)
+		mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, pgx.ErrNoRows)
+		checker.On("CheckNotBlocked", ctx, studentID).Return(blocked)
+
+		_, err := service.BorrowBook(ctx, studentID, 1, 1, "")
+
+		assert.ErrorIs(t, err, ErrStudentBlocked)
+		mockQueries.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Renew", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		checker := &MockBlockChecker{}
+		service := NewTransactionService(mockQueries).WithBlockChecker(checker)
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(5)).Return(queries.GetTransactionByIDForUpdateRow{
+			ID:              5,
+			StudentID:       studentID,
+			BookID:          1,
+			TransactionType: "borrow",
+			DueDate:         pgtype.Timestamp{Time: time.Now().AddDate(0, 0, 3), Valid: true},
+		}, nil)
+		mockQueries.On("GetCurrentLoanTransactionID", ctx, int32(5)).Return(int32(5), nil)
+		checker.On("CheckNotBlocked", ctx, studentID).Return(blocked)
+
+		_, err := service.RenewBook(ctx, 5, 1)
+
+		assert.ErrorIs(t, err, ErrStudentBlocked)
+		mockQueries.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CanRenewReportsBlock", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		checker := &MockBlockChecker{}
+		service := NewTransactionService(mockQueries).WithBlockChecker(checker)
+
+		mockQueries.On("GetTransactionByID", ctx, int32(5)).Return(queries.GetTransactionByIDRow{
+			ID:        5,
+			StudentID: studentID,
+			BookID:    1,
+			DueDate:   pgtype.Timestamp{Time: time.Now().AddDate(0, 0, 3), Valid: true},
+		}, nil)
+		checker.On("CheckNotBlocked", ctx, studentID).Return(fmt.Errorf("%w: %s", ErrStudentBlocked, "lost ID card"))
+
+		canRenew, reason, err := service.CanBookBeRenewed(ctx, 5)
+
+		require.NoError(t, err)
+		assert.False(t, canRenew)
+		assert.Equal(t, "Account is blocked: lost ID card", reason)
+	})
+
+	t.Run("Reserve", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		checker := &MockBlockChecker{}
+		service := NewReservationService(mockQuerier).WithBlockChecker(checker)
+
+		mockQuerier.On("GetStudentByID", ctx, studentID).Return(queries.Student{
+			ID:       studentID,
+			IsActive: pgtype.Bool{Bool: true, Valid: true},
+		}, nil)
+		mockQuerier.On("GetBookByID", ctx, int32(2)).Return(queries.Book{
+			ID:       2,
+			IsActive: pgtype.Bool{Bool: true, Valid: true},
+		}, nil)
+		checker.On("CheckNotBlocked", ctx, studentID).Return(blocked)
+
+		_, err := service.ReserveBook(ctx, studentID, 2)
+
+		assert.ErrorIs(t, err, ErrStudentBlocked)
+		mockQuerier.AssertNotCalled(t, "CreateReservation", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// StudentDashboardQuerier defines the queries needed to build a student's dashboard
+type StudentDashboardQuerier interface {
+	GetStudentByID(ctx context.Context, id int32) (queries.Student, error)
+	ListActiveTransactionsByStudent(ctx context.Context, studentID int32) ([]queries.ListActiveTransactionsByStudentRow, error)
+	ListReservationsByStudent(ctx context.Context, arg queries.ListReservationsByStudentParams) ([]queries.ListReservationsByStudentRow, error)
+	ListReservationsByBook(ctx context.Context, bookID int32) ([]queries.ListReservationsByBookRow, error)
+	GetStudentFineBalance(ctx context.Context, studentID int32) (queries.GetStudentFineBalanceRow, error)
+	ListOutstandingFinesByStudent(ctx context.Context, studentID int32) ([]queries.Fine, error)
+	CountUnreadNotificationsByRecipient(ctx context.Context, arg queries.CountUnreadNotificationsByRecipientParams) (int64, error)
+	ListUnreadNotificationsByRecipient(ctx context.Context, arg queries.ListUnreadNotificationsByRecipientParams) ([]queries.Notification, error)
+}
+
+// StudentDashboardServiceInterface defines the interface for student dashboard operations
+type StudentDashboardServiceInterface interface {
+	GetDashboard(ctx context.Context, studentID int32) (*models.StudentDashboardResponse, error)
+}
+
+// loanRenewalChecker reports whether a loan can be renewed and why not;
+// TransactionService implements it
+type loanRenewalChecker interface {
+	CanBookBeRenewed(ctx context.Context, transactionID int32) (bool, string, error)
+}
+
+const (
+	// dashboardReservationLimit bounds how many of a student's reservations are scanned for open ones
+	dashboardReservationLimit = 100
+	// dashboardNotificationLimit is how many unread notifications the dashboard shows
+	dashboardNotificationLimit = 10
+)
+
+// StudentDashboardService assembles a student's loans, reservations, fines and
+// notifications into a single response for the self-service dashboard
+type StudentDashboardService struct {
+	queries  StudentDashboardQuerier
+	renewals loanRenewalChecker
+}
+
+// NewStudentDashboardService creates a new student dashboard service
+func NewStudentDashboardService(querier StudentDashboardQuerier, renewals loanRenewalChecker) *StudentDashboardService {
+	return &StudentDashboardService{
+		queries:  querier,
+		renewals: renewals,
+	}
+}
+
+// GetDashboard returns the dashboard for a student
+func (s *StudentDashboardService) GetDashboard(ctx context.Context, studentID int32) (*models.StudentDashboardResponse, error) {
+	if _, err := s.queries.GetStudentByID(ctx, studentID); err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("student not found")
+		}
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+
+	now := time.Now()
+	response := &models.StudentDashboardResponse{
+		StudentID:   studentID,
+		GeneratedAt: now,
+	}
+
+	var err error
+	if response.Loans, err = s.loans(ctx, studentID, now); err != nil {
+		return nil, err
+	}
+	if response.Reservations, err = s.reservations(ctx, studentID); err != nil {
+		return nil, err
+	}
+	if response.Fines, err = s.fines(ctx, studentID); err != nil {
+		return nil, err
+	}
+	if response.Notifications, err = s.notifications(ctx, studentID); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *StudentDashboardService) loans(ctx context.Context, studentID int32, now time.Time) ([]models.DashboardLoan, error) {
+	rows, err := s.queries.ListActiveTransactionsByStudent(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loans: %w", err)
+	}
+
+	// A renewed loan is shown once, on its renewal, as borrowed when its borrow was
+	borrowedAt := make(map[int32]time.Time, len(rows))
+	for _, row := range rows {
+		if at, ok := borrowedAt[row.BookID]; !ok || row.TransactionDate.Time.Before(at) {
+			borrowedAt[row.BookID] = row.TransactionDate.Time
+		}
+	}
+
+	current := currentLoans(rows)
+	loans := make([]models.DashboardLoan, 0, len(current))
+	for _, row := range current {
+		loan := models.DashboardLoan{
+			TransactionID: row.ID,
+			BookID:        row.BookID,
+			BookCode:      row.BookID_2,
+			Title:         row.Title,
+			Author:        row.Author,
+			BorrowedAt:    borrowedAt[row.BookID],
+			DueDate:       row.DueDate.Time,
+		}
+		if row.DueDate.Valid {
+			loan.IsOverdue = now.After(row.DueDate.Time)
+			loan.DaysUntilDue = int(row.DueDate.Time.Sub(now).Hours() / 24)
+		}
+
+		canRenew, reason, err := s.renewals.CanBookBeRenewed(ctx, row.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check renewal for loan %d: %w", row.ID, err)
+		}
+		loan.CanRenew = canRenew
+		if !canRenew {
+			loan.RenewalBlocker = reason
+		}
+
+		loans = append(loans, loan)
+	}
+
+	return loans, nil
+}
+
+func (s *StudentDashboardService) reservations(ctx context.Context, studentID int32) ([]models.DashboardReservation, error) {
+	rows, err := s.queries.ListReservationsByStudent(ctx, queries.ListReservationsByStudentParams{
+		StudentID: studentID,
+		Limit:     dashboardReservationLimit,
+		Offset:    0,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reservations: %w", err)
+	}
+
+	reservations := make([]models.DashboardReservation, 0, len(rows))
+	for _, row := range rows {
+		reservation := models.DashboardReservation{
+			ReservationID: row.ID,
+			BookID:        row.BookID,
+			BookCode:      row.BookCode,
+			Title:         row.Title,
+			Author:        row.Author,
+			Status:        row.Status.String,
+			ReservedAt:    row.ReservedAt.Time,
+			ExpiresAt:     row.ExpiresAt.Time,
+		}
+
+		// A book waiting on the hold shelf has left the queue
+		if row.Status.String == models.ReservationStatusReady {
+			if row.PickupDeadline.Valid {
+				reservation.PickupDeadline = &row.PickupDeadline.Time
+			}
+			reservations = append(reservations, reservation)
+			continue
+		}
+		if row.Status.String != models.ReservationStatusActive {
+			continue
+		}
+
+		queue, err := s.queries.ListReservationsByBook(ctx, row.BookID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reservation queue: %w", err)
+		}
+
+		reservation.QueueLength = len(queue)
+		for i, queued := range queue {
+			if queued.ID == row.ID {
+				reservation.QueuePosition = i + 1
+				break
+			}
+		}
+
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, nil
+}
+
+func (s *StudentDashboardService) fines(ctx context.Context, studentID int32) (models.DashboardFines, error) {
+	balance, err := s.queries.GetStudentFineBalance(ctx, studentID)
+	if err != nil {
+		return models.DashboardFines{}, fmt.Errorf("failed to get fine balance: %w", err)
+	}
+
+	outstanding, err := s.queries.ListOutstandingFinesByStudent(ctx, studentID)
+	if err != nil {
+		return models.DashboardFines{}, fmt.Errorf("failed to list fines: %w", err)
+	}
+
+	fines := models.DashboardFines{
+		Balance:          numericToDecimal(balance.Balance),
+		OutstandingFines: balance.OutstandingFines,
+		Fines:            make([]models.FineResponse, 0, len(outstanding)),
+	}
+	for _, fine := range outstanding {
+		fines.Fines = append(fines.Fines, convertToFineResponse(fine))
+	}
+
+	return fines, nil
+}
+
+func (s *StudentDashboardService) notifications(ctx context.Context, studentID int32) (models.DashboardNotifications, error) {
+	recipientType := string(models.RecipientTypeStudent)
+
+	count, err := s.queries.CountUnreadNotificationsByRecipient(ctx, queries.CountUnreadNotificationsByRecipientParams{
+		RecipientID:   studentID,
+		RecipientType: recipientType,
+	})
+	if err != nil {
+		return models.DashboardNotifications{}, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	unread, err := s.queries.ListUnreadNotificationsByRecipient(ctx, queries.ListUnreadNotificationsByRecipientParams{
+		RecipientID:   studentID,
+		RecipientType: recipientType,
+		Limit:         dashboardNotificationLimit,
+		Offset:        0,
+	})
+	if err != nil {
+		return models.DashboardNotifications{}, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	notifications := models.DashboardNotifications{
+		UnreadCount: count,
+		Items:       make([]models.NotificationResponse, 0, len(unread)),
+	}
+	for _, notification := range unread {
+		notifications.Items = append(notifications.Items, *convertToNotificationResponse(notification))
+	}
+
+	return notifications, nil
+}
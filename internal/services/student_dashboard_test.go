@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+)
+
+// MockStudentDashboardQuerier is a mock implementation of StudentDashboardQuerier
+type MockStudentDashboardQuerier struct {
+	mock.Mock
+}
+
+func (m *MockStudentDashboardQuerier) GetStudentByID(ctx context.Context, id int32) (queries.Student, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Student), args.Error(1)
+}
+
+func (m *MockStudentDashboardQuerier) ListActiveTransactionsByStudent(ctx context.Context, studentID int32) ([]queries.ListActiveTransactionsByStudentRow, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]queries.ListActiveTransactionsByStudentRow), args.Error(1)
+}
+
+func (m *MockStudentDashboardQuerier) ListReservationsByStudent(ctx context.Context, arg queries.ListReservationsByStudentParams) ([]queries.ListReservationsByStudentRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]queries.ListReservationsByStudentRow), args.Error(1)
+}
+
+func (m *MockStudentDashboardQuerier) ListReservationsByBook(ctx context.Context, bookID int32) ([]queries.ListReservationsByBookRow, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).([]queries.ListReservationsByBookRow), args.Error(1)
+}
+
+func (m *MockStudentDashboardQuerier) GetStudentFineBalance(ctx context.Context, studentID int32) (queries.GetStudentFineBalanceRow, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).(queries.GetStudentFineBalanceRow), args.Error(1)
+}
+
+func (m *MockStudentDashboardQuerier) ListOutstandingFinesByStudent(ctx context.Context, studentID int32) ([]queries.Fine, error) {
+	args := m.Called(ctx, studentID)
+	return args.Get(0).([]queries.Fine), args.Error(1)
+}
+
+func (m *MockStudentDashboardQuerier) CountUnreadNotificationsByRecipient(ctx context.Context, arg queries.CountUnreadNotificationsByRecipientParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockStudentDashboardQuerier) ListUnreadNotificationsByRecipient(ctx context.Context, arg queries.ListUnreadNotificationsByRecipientParams) ([]queries.Notification, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]queries.Notification), args.Error(1)
+}
+
+// stubRenewalChecker reports a fixed renewal outcome per loan
+type stubRenewalChecker map[int32]string
+
+func (s stubRenewalChecker) CanBookBeRenewed(ctx context.Context, transactionID int32) (bool, string, error) {
+	reason := s[transactionID]
+	return reason == "", reason, nil
+}
+
+func TestStudentDashboardService_GetDashboard(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("CombinesLoansReservationsFinesAndNotifications", func(t *testing.T) {
+		mockQueries := &MockStudentDashboardQuerier{}
+		service := NewStudentDashboardService(mockQueries, stubRenewalChecker{8: "Book is reserved by another student"})
+
+		now := time.Now()
+		mockQueries.On("GetStudentByID", ctx, int32(1)).Return(createTestStudent(), nil)
+		mockQueries.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{
+			{ID: 7, BookID: 1, Title: "Go", DueDate: pgtype.Timestamp{Time: now.AddDate(0, 0, 3).Add(time.Hour), Valid: true}},
+			{ID: 8, BookID: 2, Title: "Rust", DueDate: pgtype.Timestamp{Time: now.AddDate(0, 0, -1), Valid: true}},
+		}, nil)
+		mockQueries.On("ListReservationsByStudent", ctx, queries.ListReservationsByStudentParams{StudentID: 1, Limit: dashboardReservationLimit}).Return([]queries.ListReservationsByStudentRow{
+			{ID: 20, BookID: 3, Status: pgtype.Text{String: "active", Valid: true}},
+			{ID: 21, BookID: 4, Status: pgtype.Text{String: "fulfilled", Valid: true}},
+		}, nil)
+		mockQueries.On("ListReservationsByBook", ctx, int32(3)).Return([]queries.ListReservationsByBookRow{{ID: 19}, {ID: 20}, {ID: 22}}, nil)
+		mockQueries.On("GetStudentFineBalance", ctx, int32(1)).Return(queries.GetStudentFineBalanceRow{
+			Balance:          decimalToNumeric(decimal.NewFromInt(15)),
+			OutstandingFines: 1,
+		}, nil)
+		mockQueries.On("ListOutstandingFinesByStudent", ctx, int32(1)).Return([]queries.Fine{
+			{ID: 4, StudentID: 1, FineType: "overdue", Amount: decimalToNumeric(decimal.NewFromInt(15))},
+		}, nil)
+		mockQueries.On("CountUnreadNotificationsByRecipient", ctx, queries.CountUnreadNotificationsByRecipientParams{RecipientID: 1, RecipientType: "student"}).Return(int64(12), nil)
+		mockQueries.On("ListUnreadNotificationsByRecipient", ctx, mock.MatchedBy(func(arg queries.ListUnreadNotificationsByRecipientParams) bool {
+			return arg.RecipientID == 1 && arg.Limit == dashboardNotificationLimit
+		})).Return([]queries.Notification{{ID: 30, RecipientID: 1, RecipientType: "student", Title: "Due soon"}}, nil)
+
+		dashboard, err := service.GetDashboard(ctx, 1)
+
+		require.NoError(t, err)
+		require.Len(t, dashboard.Loans, 2)
+		assert.True(t, dashboard.Loans[0].CanRenew)
+		assert.Equal(t, 3, dashboard.Loans[0].DaysUntilDue)
+		assert.False(t, dashboard.Loans[0].IsOverdue)
+		assert.False(t, dashboard.Loans[1].CanRenew)
+		assert.True(t, dashboard.Loans[1].IsOverdue)
+		assert.Equal(t, "Book is reserved by another student", dashboard.Loans[1].RenewalBlocker)
+
+		require.Len(t, dashboard.Reservations, 1)
+		assert.Equal(t, 2, dashboard.Reservations[0].QueuePosition)
+		assert.Equal(t, 3, dashboard.Reservations[0].QueueLength)
+
+		assert.True(t, dashboard.Fines.Balance.Equal(decimal.NewFromInt(15)))
+		assert.Len(t, dashboard.Fines.Fines, 1)
+		assert.Equal(t, int64(12), dashboard.Notifications.UnreadCount)
+		assert.Len(t, dashboard.Notifications.Items, 1)
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("ShowsRenewedLoanOnce", func(t *testing.T) {
+		mockQueries := &MockStudentDashboardQuerier{}
+		service := NewStudentDashboardService(mockQueries, stubRenewalChecker{7: "Book is overdue and must be returned first"})
+
+		now := time.Now()
+		borrowedAt := now.AddDate(0, 0, -20)
+		mockQueries.On("GetStudentByID", ctx, int32(1)).Return(createTestStudent(), nil)
+		mockQueries.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{
+			{ID: 7, BookID: 1, Title: "Go", TransactionType: "borrow", TransactionDate: pgtype.Timestamp{Time: borrowedAt, Valid: true}, DueDate: pgtype.Timestamp{Time: now.AddDate(0, 0, -6), Valid: true}},
+			{ID: 9, BookID: 1, Title: "Go", TransactionType: "renew", TransactionDate: pgtype.Timestamp{Time: now.AddDate(0, 0, -7), Valid: true}, DueDate: pgtype.Timestamp{Time: now.AddDate(0, 0, 7).Add(time.Hour), Valid: true}},
+		}, nil)
+		mockQueries.On("ListReservationsByStudent", ctx, queries.ListReservationsByStudentParams{StudentID: 1, Limit: dashboardReservationLimit}).Return([]queries.ListReservationsByStudentRow{}, nil)
+		mockQueries.On("GetStudentFineBalance", ctx, int32(1)).Return(queries.GetStudentFineBalanceRow{}, nil)
+		mockQueries.On("ListOutstandingFinesByStudent", ctx, int32(1)).Return([]queries.Fine{}, nil)
+		mockQueries.On("CountUnreadNotificationsByRecipient", ctx, mock.Anything).Return(int64(0), nil)
+		mockQueries.On("ListUnreadNotificationsByRecipient", ctx, mock.Anything).Return([]queries.Notification{}, nil)
+
+		dashboard, err := service.GetDashboard(ctx, 1)
+
+		require.NoError(t, err)
+		require.Len(t, dashboard.Loans, 1)
+		assert.Equal(t, int32(9), dashboard.Loans[0].TransactionID)
+		assert.False(t, dashboard.Loans[0].IsOverdue)
+		assert.Equal(t, 7, dashboard.Loans[0].DaysUntilDue)
+		assert.True(t, dashboard.Loans[0].CanRenew)
+		assert.True(t, dashboard.Loans[0].BorrowedAt.Equal(borrowedAt))
+	})
+
+	t.Run("StudentNotFound", func(t *testing.T) {
+		mockQueries := &MockStudentDashboardQuerier{}
+		service := NewStudentDashboardService(mockQueries, stubRenewalChecker{})
+
+		mockQueries.On("GetStudentByID", ctx, int32(99)).Return(queries.Student{}, sql.ErrNoRows)
+
+		_, err := service.GetDashboard(ctx, 99)
+
+		require.Error(t, err)
+		assert.Equal(t, "student not found", err.Error())
+	})
+}
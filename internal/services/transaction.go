@@ -3,36 +3,81 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/shopspring/decimal"
 
 	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
 )
 
 // TransactionQuerier defines the interface for transaction database operations
 type TransactionQuerier interface {
 	CreateTransaction(ctx context.Context, arg queries.CreateTransactionParams) (queries.Transaction, error)
 	GetTransactionByID(ctx context.Context, id int32) (queries.GetTransactionByIDRow, error)
+	GetTransactionByIDForUpdate(ctx context.Context, id int32) (queries.GetTransactionByIDForUpdateRow, error)
 	ListTransactions(ctx context.Context, arg queries.ListTransactionsParams) ([]queries.ListTransactionsRow, error)
 	ListTransactionsByStudent(ctx context.Context, arg queries.ListTransactionsByStudentParams) ([]queries.ListTransactionsByStudentRow, error)
 	ListActiveTransactionsByStudent(ctx context.Context, studentID int32) ([]queries.ListActiveTransactionsByStudentRow, error)
 	ListOverdueTransactions(ctx context.Context) ([]queries.ListOverdueTransactionsRow, error)
 	ReturnBook(ctx context.Context, arg queries.ReturnBookParams) (queries.Transaction, error)
 	UpdateTransactionFine(ctx context.Context, arg queries.UpdateTransactionFineParams) error
-	PayTransactionFine(ctx context.Context, id int32) error
+	CreateFine(ctx context.Context, arg queries.CreateFineParams) (queries.Fine, error)
 	CountOverdueTransactions(ctx context.Context) (int64, error)
 	GetBookByID(ctx context.Context, id int32) (queries.Book, error)
+	GetBookByIDForUpdate(ctx context.Context, id int32) (queries.Book, error)
 	GetStudentByID(ctx context.Context, id int32) (queries.Student, error)
+	GetStudentByIDForUpdate(ctx context.Context, id int32) (queries.Student, error)
 	UpdateBookAvailability(ctx context.Context, arg queries.UpdateBookAvailabilityParams) error
-	UpdateBookCondition(ctx context.Context, arg queries.UpdateBookConditionParams) error
+	// Copy-level queries
+	GetAvailableBookCopyForUpdate(ctx context.Context, bookID int32) (queries.BookCopy, error)
+	GetBookCopyByBarcode(ctx context.Context, barcode string) (queries.BookCopy, error)
+	GetBookCopyByBarcodeForUpdate(ctx context.Context, barcode string) (queries.BookCopy, error)
+	GetBookCopyByIDForUpdate(ctx context.Context, id int32) (queries.BookCopy, error)
+	UpdateBookCopyStatus(ctx context.Context, arg queries.UpdateBookCopyStatusParams) error
+	UpdateBookCopyCondition(ctx context.Context, arg queries.UpdateBookCopyConditionParams) error
+	SyncBookCopyCounts(ctx context.Context, id int32) error
+	GetActiveTransactionByCopyID(ctx context.Context, copyID pgtype.Int4) (queries.Transaction, error)
+	GetCurrentLoanTransactionID(ctx context.Context, id int32) (int32, error)
+	CloseRenewedLoanTransactions(ctx context.Context, id int32) error
 	// Renewal-related queries
 	CountRenewalsByStudentAndBook(ctx context.Context, arg queries.CountRenewalsByStudentAndBookParams) (int64, error)
 	HasActiveReservationsByOtherStudents(ctx context.Context, arg queries.HasActiveReservationsByOtherStudentsParams) (bool, error)
 	ListRenewalsByStudentAndBook(ctx context.Context, arg queries.ListRenewalsByStudentAndBookParams) ([]queries.ListRenewalsByStudentAndBookRow, error)
 	GetRenewalStatisticsByStudent(ctx context.Context, studentID int32) (queries.GetRenewalStatisticsByStudentRow, error)
+	// Lost and claimed-returned loans
+	CloseTransactionAsMissing(ctx context.Context, arg queries.CloseTransactionAsMissingParams) (queries.Transaction, error)
+	SetTransactionLossStatus(ctx context.Context, arg queries.SetTransactionLossStatusParams) (queries.Transaction, error)
+	UpdateBookCopyTotals(ctx context.Context, arg queries.UpdateBookCopyTotalsParams) error
+	// Fine ledger queries used to reverse a lost item charge when the copy is found
+	GetFineByIDForUpdate(ctx context.Context, id int32) (queries.Fine, error)
+	ListFinesByTransaction(ctx context.Context, transactionID pgtype.Int4) ([]queries.Fine, error)
+	UpdateFineTotals(ctx context.Context, arg queries.UpdateFineTotalsParams) (queries.Fine, error)
+	NextFineReceiptNumber(ctx context.Context) (int64, error)
+	CreateFineLedgerEntry(ctx context.Context, arg queries.CreateFineLedgerEntryParams) (queries.FineLedgerEntry, error)
+	SetTransactionFinePaid(ctx context.Context, arg queries.SetTransactionFinePaidParams) error
+	CreateAuditLog(ctx context.Context, arg queries.CreateAuditLogParams) error
+	// Reservation queries used when a return hands the copy to the next reservation
+	GetNextReservationForBook(ctx context.Context, bookID int32) (queries.GetNextReservationForBookRow, error)
+	UpdateReservationStatus(ctx context.Context, arg queries.UpdateReservationStatusParams) (queries.Reservation, error)
+	// Hold shelf queries for copies set aside for a ready reservation
+	MarkReservationReady(ctx context.Context, arg queries.MarkReservationReadyParams) (queries.Reservation, error)
+	GetReadyReservationForStudent(ctx context.Context, arg queries.GetReadyReservationForStudentParams) (queries.Reservation, error)
+	ExpireHold(ctx context.Context, id int32) (queries.Reservation, error)
+	CancelReservation(ctx context.Context, id int32) (queries.Reservation, error)
+	ListExpiredHolds(ctx context.Context) ([]queries.Reservation, error)
+	// Recalls of loans wanted by a reservation
+	RecallTransaction(ctx context.Context, arg queries.RecallTransactionParams) (queries.Transaction, error)
+	CountActiveReservationsByBook(ctx context.Context, bookID int32) (int64, error)
+	CountOpenRecallsByBook(ctx context.Context, bookID int32) (int64, error)
+	GetRecallableLoanByBook(ctx context.Context, bookID int32) (int32, error)
+	// ExecTx runs fn inside a single database transaction, committing only if fn succeeds
+	ExecTx(ctx context.Context, fn func(TransactionQuerier) error) error
 }
 
 // TransactionService handles all business logic related to book transactions
@@ -42,6 +87,21 @@ type TransactionService struct {
 	finePerDay      decimal.Decimal
 	maxBooksPerUser int
 	maxRenewals     int // Maximum number of renewals per book per student
+	policies        PolicyResolver
+	calendar        LibraryCalendar
+	blocks          BlockChecker
+
+	replacementCost     decimal.Decimal
+	processingFee       decimal.Decimal
+	damageChargePerStep decimal.Decimal
+	damageStepThreshold int
+
+	pickupDays int
+
+	realtime RealtimePublisher
+	notifier StudentNotifier
+	// outbox collects the changes to announce once the open database transaction commits
+	outbox *realtimeOutbox
 }
 
 // NewTransactionService creates a new transaction service with default settings
@@ -52,6 +112,13 @@ func NewTransactionService(queries TransactionQuerier) *TransactionService {
 		finePerDay:      decimal.NewFromFloat(0.50), // $0.50 per day fine
 		maxBooksPerUser: 5,                          // Max 5 books per student
 		maxRenewals:     2,                          // Max 2 renewals per book per student
+
+		replacementCost:     defaultReplacementCost,
+		processingFee:       defaultProcessingFee,
+		damageChargePerStep: defaultDamageChargePerStep,
+		damageStepThreshold: defaultDamageStepThreshold,
+
+		pickupDays: defaultPickupDays,
 	}
 }
 
@@ -79,6 +146,66 @@ func (s *TransactionService) WithMaxRenewals(maxRenewals int) *TransactionServic
 	return s
 }
 
+// WithReplacementCharges sets what is charged for a copy declared lost or claimed returned
+func (s *TransactionService) WithReplacementCharges(replacementCost, processingFee decimal.Decimal) *TransactionService {
+	s.replacementCost = replacementCost
+	s.processingFee = processingFee
+	return s
+}
+
+// WithDamageCharges sets the charge per condition step a copy loses on loan, levied
+// once the condition drops by at least thresholdSteps
+func (s *TransactionService) WithDamageCharges(chargePerStep decimal.Decimal, thresholdSteps int) *TransactionService {
+	s.damageChargePerStep = chargePerStep
+	s.damageStepThreshold = thresholdSteps
+	return s
+}
+
+// WithPickupDays sets how many days a returned book waits on the hold shelf for the student who reserved it
+func (s *TransactionService) WithPickupDays(days int) *TransactionService {
+	s.pickupDays = days
+	return s
+}
+
+// WithPolicyResolver resolves loan rules from the circulation policy matrix;
+// the settings above remain the fallback when no rule matches
+func (s *TransactionService) WithPolicyResolver(policies PolicyResolver) *TransactionService {
+	s.policies = policies
+	return s
+}
+
+// WithCalendar makes due dates skip closed days and stops fines accruing while the library is closed
+func (s *TransactionService) WithCalendar(calendar LibraryCalendar) *TransactionService {
+	s.calendar = calendar
+	return s
+}
+
+// WithBlockChecker refuses loans and renewals to students whose accounts are blocked
+func (s *TransactionService) WithBlockChecker(blocks BlockChecker) *TransactionService {
+	s.blocks = blocks
+	return s
+}
+
+// WithRealtime announces returns and hold shelf changes to connected clients
+func (s *TransactionService) WithRealtime(realtime RealtimePublisher) *TransactionService {
+	s.realtime = realtime
+	return s
+}
+
+// WithNotifier sends students the notices for held copies and recalled loans
+func (s *TransactionService) WithNotifier(notifier StudentNotifier) *TransactionService {
+	s.notifier = notifier
+	return s
+}
+
+// withQuerier returns a copy of the service bound to the given querier,
+// used to run the service logic against an open database transaction
+func (s *TransactionService) withQuerier(q TransactionQuerier) *TransactionService {
+	txService := *s
+	txService.queries = q
+	return &txService
+}
+
 // BorrowBookRequest represents a book borrowing request
 type BorrowBookRequest struct {
 	StudentID   int32  `json:"student_id" validate:"required"`
@@ -99,6 +226,8 @@ type TransactionResponse struct {
 	ID              int32           `json:"id"`
 	StudentID       int32           `json:"student_id"`
 	BookID          int32           `json:"book_id"`
+	CopyID          *int32          `json:"copy_id,omitempty"`
+	Barcode         string          `json:"barcode,omitempty"`
 	TransactionType string          `json:"transaction_type"`
 	TransactionDate time.Time       `json:"transaction_date"`
 	DueDate         time.Time       `json:"due_date"`
@@ -109,14 +238,57 @@ type TransactionResponse struct {
 	Notes           string          `json:"notes"`
 	ReturnCondition string          `json:"return_condition,omitempty"`
 	ConditionNotes  string          `json:"condition_notes,omitempty"`
+	LossStatus      string          `json:"loss_status,omitempty"`
+	RecalledAt      *time.Time      `json:"recalled_at,omitempty"`
+	OriginalDueDate *time.Time      `json:"original_due_date,omitempty"`
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
+	// Charges raised by this operation, such as overdue, damage or replacement fines
+	Charges []models.FineResponse `json:"charges,omitempty"`
 }
 
-// BorrowBook processes a book borrowing request
+// BorrowBook processes a book borrowing request for the next available copy of a title.
+// The book and student rows are locked for the duration of the transaction so
+// concurrent checkouts cannot oversubscribe copies or exceed loan limits.
 func (s *TransactionService) BorrowBook(ctx context.Context, studentID, bookID, librarianID int32, notes string) (*TransactionResponse, error) {
+	var response *TransactionResponse
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		var err error
+		response, err = tx.borrowBook(ctx, studentID, bookID, "", librarianID, notes)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// BorrowBookByBarcode processes a book borrowing request for the scanned copy
+func (s *TransactionService) BorrowBookByBarcode(ctx context.Context, studentID int32, barcode string, librarianID int32, notes string) (*TransactionResponse, error) {
+	var response *TransactionResponse
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		bookCopy, err := tx.queries.GetBookCopyByBarcode(ctx, barcode)
+		if err != nil {
+			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+				return fmt.Errorf("book copy not found")
+			}
+			return fmt.Errorf("failed to get book copy: %w", err)
+		}
+
+		response, err = tx.borrowBook(ctx, studentID, bookCopy.BookID, barcode, librarianID, notes)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// borrowBook performs the borrowing steps; it must run inside ExecTx.
+// When barcode is empty the first available copy of the title is issued.
+func (s *TransactionService) borrowBook(ctx context.Context, studentID, bookID int32, barcode string, librarianID int32, notes string) (*TransactionResponse, error) {
 	// Validate book exists and is available
-	book, err := s.queries.GetBookByID(ctx, bookID)
+	book, err := s.queries.GetBookByIDForUpdate(ctx, bookID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("book not found")
@@ -125,7 +297,7 @@ func (s *TransactionService) BorrowBook(ctx context.Context, studentID, bookID,
 	}
 
 	// Validate student exists and is active
-	student, err := s.queries.GetStudentByID(ctx, studentID)
+	student, err := s.queries.GetStudentByIDForUpdate(ctx, studentID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("student not found")
@@ -133,13 +305,40 @@ func (s *TransactionService) BorrowBook(ctx context.Context, studentID, bookID,
 		return nil, fmt.Errorf("failed to get student: %w", err)
 	}
 
+	policy, err := s.resolvePolicy(ctx, studentPolicyContext(student, book))
+	if err != nil {
+		return nil, err
+	}
+
+	// A copy on the hold shelf counts as available to the student it is held for
+	hold, err := s.readyHold(ctx, studentID, bookID)
+	if err != nil {
+		return nil, err
+	}
+	var heldCopyID pgtype.Int4
+	if hold != nil {
+		heldCopyID = hold.CopyID
+		if heldCopyID.Valid {
+			book.AvailableCopies.Int32++
+		}
+	}
+
 	// Enhanced validation with comprehensive business rules
-	if err := s.validateBorrowingEligibility(ctx, student, book, studentID, bookID); err != nil {
+	if err := s.validateBorrowingEligibility(ctx, student, book, policy, studentID, bookID); err != nil {
 		return nil, err
 	}
 
-	// Calculate due date based on student year and borrowing rules
-	dueDate := s.calculateDueDate(student)
+	// Pick the physical copy being handed out
+	bookCopy, err := s.selectCopyForLoan(ctx, bookID, barcode, heldCopyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Calculate due date from the applicable circulation policy
+	dueDate, err := s.calculateDueDate(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create transaction
 	transaction, err := s.queries.CreateTransaction(ctx, queries.CreateTransactionParams{
@@ -149,37 +348,113 @@ func (s *TransactionService) BorrowBook(ctx context.Context, studentID, bookID,
 		DueDate:         pgtype.Timestamp{Time: dueDate, Valid: true},
 		LibrarianID:     pgtype.Int4{Int32: librarianID, Valid: true},
 		Notes:           pgtype.Text{String: notes, Valid: notes != ""},
+		CopyID:          pgtype.Int4{Int32: bookCopy.ID, Valid: true},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
 
-	// Update book availability
-	err = s.queries.UpdateBookAvailability(ctx, queries.UpdateBookAvailabilityParams{
-		ID:              bookID,
-		AvailableCopies: pgtype.Int4{Int32: book.AvailableCopies.Int32 - 1, Valid: true},
+	// Mark the copy as borrowed and refresh the title's availability counts
+	err = s.queries.UpdateBookCopyStatus(ctx, queries.UpdateBookCopyStatusParams{
+		ID:     bookCopy.ID,
+		Status: pgtype.Text{String: "borrowed", Valid: true},
 	})
 	if err != nil {
+		return nil, fmt.Errorf("failed to update book copy status: %w", err)
+	}
+
+	// Collecting a reserved book fulfils the reservation
+	if hold != nil {
+		if err := s.collectHold(ctx, *hold, bookCopy.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.queries.SyncBookCopyCounts(ctx, bookID); err != nil {
 		return nil, fmt.Errorf("failed to update book availability: %w", err)
 	}
 
-	return s.convertToTransactionResponse(transaction), nil
+	response := s.convertToTransactionResponse(transaction)
+	response.Barcode = bookCopy.Barcode
+	return response, nil
+}
+
+// selectCopyForLoan locks the copy to be issued: the scanned one, the copy held
+// for the student, or the first available copy of the title
+func (s *TransactionService) selectCopyForLoan(ctx context.Context, bookID int32, barcode string, heldCopyID pgtype.Int4) (queries.BookCopy, error) {
+	if barcode == "" && heldCopyID.Valid {
+		bookCopy, err := s.queries.GetBookCopyByIDForUpdate(ctx, heldCopyID.Int32)
+		if err != nil {
+			return queries.BookCopy{}, fmt.Errorf("failed to get held copy: %w", err)
+		}
+		return bookCopy, nil
+	}
+
+	if barcode == "" {
+		bookCopy, err := s.queries.GetAvailableBookCopyForUpdate(ctx, bookID)
+		if err != nil {
+			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+				return queries.BookCopy{}, fmt.Errorf("book not available")
+			}
+			return queries.BookCopy{}, fmt.Errorf("failed to get available copy: %w", err)
+		}
+		return bookCopy, nil
+	}
+
+	bookCopy, err := s.queries.GetBookCopyByBarcodeForUpdate(ctx, barcode)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return queries.BookCopy{}, fmt.Errorf("book copy not found")
+		}
+		return queries.BookCopy{}, fmt.Errorf("failed to get book copy: %w", err)
+	}
+
+	if bookCopy.BookID != bookID {
+		return queries.BookCopy{}, fmt.Errorf("book copy %s does not belong to this book", barcode)
+	}
+
+	if heldCopyID.Valid && bookCopy.ID == heldCopyID.Int32 {
+		return bookCopy, nil
+	}
+
+	if bookCopy.Status.String == string(models.BookCopyStatusOnHold) {
+		return queries.BookCopy{}, fmt.Errorf("book copy %s is on the hold shelf for another student", barcode)
+	}
+
+	if bookCopy.Status.String != "available" {
+		return queries.BookCopy{}, fmt.Errorf("book copy %s is not available (status: %s)", barcode, bookCopy.Status.String)
+	}
+
+	return bookCopy, nil
 }
 
 // ReturnBook processes a book return with enhanced validation (backward compatibility)
-func (s *TransactionService) ReturnBook(ctx context.Context, transactionID int32) (*TransactionResponse, error) {
-	return s.ReturnBookWithCondition(ctx, transactionID, "good", "")
+func (s *TransactionService) ReturnBook(ctx context.Context, transactionID int32, actor AuditActor) (*TransactionResponse, error) {
+	return s.ReturnBookWithCondition(ctx, transactionID, "good", "", actor)
 }
 
-// ReturnBookWithCondition processes a book return with condition assessment
-func (s *TransactionService) ReturnBookWithCondition(ctx context.Context, transactionID int32, returnCondition, conditionNotes string) (*TransactionResponse, error) {
+// ReturnBookWithCondition processes a book return with condition assessment.
+// The transaction and book rows are locked so a loan cannot be returned twice
+// and availability is updated from the current copy count.
+func (s *TransactionService) ReturnBookWithCondition(ctx context.Context, transactionID int32, returnCondition, conditionNotes string, actor AuditActor) (*TransactionResponse, error) {
+	var response *TransactionResponse
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		var err error
+		response, err = tx.returnBookWithCondition(ctx, transactionID, returnCondition, conditionNotes, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// returnBookWithCondition performs the return steps; it must run inside ExecTx
+func (s *TransactionService) returnBookWithCondition(ctx context.Context, transactionID int32, returnCondition, conditionNotes string, actor AuditActor) (*TransactionResponse, error) {
 	// Get transaction
-	transactionRow, err := s.queries.GetTransactionByID(ctx, transactionID)
+	transactionRow, err := s.lockLoan(ctx, transactionID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("transaction not found")
-		}
-		return nil, fmt.Errorf("failed to get transaction: %w", err)
+		return nil, err
 	}
 
 	// Enhanced validation for return processing
@@ -192,78 +467,283 @@ func (s *TransactionService) ReturnBookWithCondition(ctx context.Context, transa
 		return nil, err
 	}
 
-	// Calculate fine if overdue
-	fine := decimal.Zero
-	if transactionRow.DueDate.Valid {
-		fine = s.calculateFine(transactionRow.DueDate.Time, time.Now())
+	policy, err := s.resolvePolicy(ctx, transactionPolicyContext(transactionRow))
+	if err != nil {
+		return nil, err
 	}
 
-	// Convert decimal to pgtype.Numeric with proper precision
-	fineNumeric := pgtype.Numeric{}
-	if fine.GreaterThan(decimal.Zero) {
-		// Convert to proper numeric format with 2 decimal places
-		fineScaled := fine.Shift(2) // Shift by 2 decimal places for cents
-		fineNumeric.Int = fineScaled.BigInt()
-		fineNumeric.Exp = -2 // 2 decimal places
-		fineNumeric.Valid = true
+	// Calculate fine if overdue
+	fine, err := s.overdueFine(ctx, transactionRow, policy)
+	if err != nil {
+		return nil, err
 	}
 
 	// Return book with condition assessment
 	transaction, err := s.queries.ReturnBook(ctx, queries.ReturnBookParams{
-		ID:              transactionID,
-		FineAmount:      fineNumeric,
+		ID:              transactionRow.ID,
+		FineAmount:      fineToNumeric(fine),
 		ReturnCondition: pgtype.Text{String: returnCondition, Valid: true},
 		ConditionNotes:  pgtype.Text{String: conditionNotes, Valid: conditionNotes != ""},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to return book: %w", err)
 	}
+	if err := s.queries.CloseRenewedLoanTransactions(ctx, transactionRow.ID); err != nil {
+		return nil, fmt.Errorf("failed to close renewed loan: %w", err)
+	}
+	s.announceCounters()
+
+	var charges []models.FineResponse
+
+	// Open an overdue fine in the ledger so it can be paid, waived or refunded
+	if fine.GreaterThan(decimal.Zero) {
+		charge, err := s.chargeLoan(ctx, transactionRow, models.FineTypeOverdue, fine, "", actor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record overdue fine: %w", err)
+		}
+		charges = append(charges, charge)
+	}
 
 	// Update book availability
-	book, err := s.queries.GetBookByID(ctx, transactionRow.BookID)
+	book, err := s.queries.GetBookByIDForUpdate(ctx, transactionRow.BookID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get book for availability update: %w", err)
 	}
 
-	err = s.queries.UpdateBookAvailability(ctx, queries.UpdateBookAvailabilityParams{
-		ID:              transactionRow.BookID,
-		AvailableCopies: pgtype.Int4{Int32: book.AvailableCopies.Int32 + 1, Valid: true},
+	// Loans issued before copies were tracked only adjust the title counters
+	if !transactionRow.CopyID.Valid {
+		err = s.queries.UpdateBookAvailability(ctx, queries.UpdateBookAvailabilityParams{
+			ID:              transactionRow.BookID,
+			AvailableCopies: pgtype.Int4{Int32: book.AvailableCopies.Int32 + 1, Valid: true},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update book availability: %w", err)
+		}
+
+		response := s.convertToTransactionResponse(transaction)
+		response.Charges = charges
+		return response, nil
+	}
+
+	bookCopy, err := s.queries.GetBookCopyByIDForUpdate(ctx, transactionRow.CopyID.Int32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book copy: %w", err)
+	}
+
+	err = s.queries.UpdateBookCopyStatus(ctx, queries.UpdateBookCopyStatusParams{
+		ID:     bookCopy.ID,
+		Status: pgtype.Text{String: "available", Valid: true},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to update book availability: %w", err)
+		return nil, fmt.Errorf("failed to update book copy status: %w", err)
+	}
+
+	// Charge for damage once the condition drops further than the policy allows
+	previousCondition := copyCondition(bookCopy)
+	if damage := policy.damageCharge(conditionStepsLost(previousCondition, returnCondition)); damage.IsPositive() {
+		description := fmt.Sprintf("Returned in %s condition, issued in %s condition", returnCondition, previousCondition)
+		charge, err := s.chargeLoan(ctx, transactionRow, models.FineTypeDamage, damage, description, actor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record damage charge: %w", err)
+		}
+		charges = append(charges, charge)
 	}
 
-	// Update book condition if it's deteriorated
-	if err := s.updateBookConditionIfNeeded(ctx, transactionRow.BookID, book, returnCondition); err != nil {
+	// Update copy condition if it's deteriorated
+	if err := s.updateBookConditionIfNeeded(ctx, bookCopy, returnCondition); err != nil {
 		return nil, fmt.Errorf("failed to update book condition: %w", err)
 	}
 
-	return s.convertToTransactionResponse(transaction), nil
+	if err := s.queries.SyncBookCopyCounts(ctx, transactionRow.BookID); err != nil {
+		return nil, fmt.Errorf("failed to update book availability: %w", err)
+	}
+
+	response := s.convertToTransactionResponse(transaction)
+	response.Barcode = bookCopy.Barcode
+	response.Charges = charges
+	return response, nil
 }
 
-// RenewBook renews a borrowed book with comprehensive validation
+// lockLoan locks a loan for a return or renewal. A borrow that has been renewed
+// stays open alongside its renewals, so whichever of them is given, the latest
+// open row is the loan that is locked and its due date the one that applies.
+func (s *TransactionService) lockLoan(ctx context.Context, transactionID int32) (queries.GetTransactionByIDRow, error) {
+	lockedRow, err := s.queries.GetTransactionByIDForUpdate(ctx, transactionID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return queries.GetTransactionByIDRow{}, fmt.Errorf("transaction not found")
+		}
+		return queries.GetTransactionByIDRow{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if lockedRow.ReturnedDate.Valid {
+		return queries.GetTransactionByIDRow(lockedRow), nil
+	}
+
+	currentID, err := s.queries.GetCurrentLoanTransactionID(ctx, transactionID)
+	if err != nil {
+		return queries.GetTransactionByIDRow{}, fmt.Errorf("failed to get current loan: %w", err)
+	}
+	if currentID != transactionID {
+		if lockedRow, err = s.queries.GetTransactionByIDForUpdate(ctx, currentID); err != nil {
+			return queries.GetTransactionByIDRow{}, fmt.Errorf("failed to get transaction: %w", err)
+		}
+	}
+	return queries.GetTransactionByIDRow(lockedRow), nil
+}
+
+// overdueFine returns the fine accrued by a loan up to now
+func (s *TransactionService) overdueFine(ctx context.Context, tx queries.GetTransactionByIDRow, policy *CirculationPolicy) (decimal.Decimal, error) {
+	if !tx.DueDate.Valid {
+		return decimal.Zero, nil
+	}
+	if tx.RecalledAt.Valid {
+		policy = policy.forRecall()
+	}
+	return s.calculateFine(ctx, tx.DueDate.Time, time.Now(), policy)
+}
+
+// chargeLoan opens a fine against the loan so it can be paid, waived or refunded,
+// recording the charge in the audit log like every other change to the ledger
+func (s *TransactionService) chargeLoan(ctx context.Context, tx queries.GetTransactionByIDRow, fineType models.FineType, amount decimal.Decimal, description string, actor AuditActor) (models.FineResponse, error) {
+	params := queries.CreateFineParams{
+		StudentID:     tx.StudentID,
+		TransactionID: pgtype.Int4{Int32: tx.ID, Valid: true},
+		FineType:      string(fineType),
+		Description:   pgtype.Text{String: description, Valid: description != ""},
+		Amount:        fineToNumeric(amount),
+	}
+	if actor.UserID > 0 {
+		params.CreatedBy = pgtype.Int4{Int32: actor.UserID, Valid: true}
+	}
+
+	fine, err := s.queries.CreateFine(ctx, params)
+	if err != nil {
+		return models.FineResponse{}, err
+	}
+
+	response := convertToFineResponse(fine)
+	if err := writeAuditLog(ctx, s.queries, actor, "fines", fine.ID, "CREATE", nil, response); err != nil {
+		return models.FineResponse{}, err
+	}
+	return response, nil
+}
+
+// fineToNumeric converts a fine to a two decimal place numeric; zero is stored as NULL
+func fineToNumeric(fine decimal.Decimal) pgtype.Numeric {
+	fineNumeric := pgtype.Numeric{}
+	if fine.GreaterThan(decimal.Zero) {
+		// Convert to proper numeric format with 2 decimal places
+		fineScaled := fine.Shift(2) // Shift by 2 decimal places for cents
+		fineNumeric.Int = fineScaled.BigInt()
+		fineNumeric.Exp = -2 // 2 decimal places
+		fineNumeric.Valid = true
+	}
+	return fineNumeric
+}
+
+// ReturnBookByBarcode processes the return of the scanned copy
+func (s *TransactionService) ReturnBookByBarcode(ctx context.Context, barcode, returnCondition, conditionNotes string, actor AuditActor) (*TransactionResponse, error) {
+	var response *TransactionResponse
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		transactionID, err := tx.activeTransactionForBarcode(ctx, barcode)
+		if err != nil {
+			return err
+		}
+
+		response, err = tx.returnBookWithCondition(ctx, transactionID, returnCondition, conditionNotes, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// activeTransactionForBarcode finds the open loan for a scanned copy
+func (s *TransactionService) activeTransactionForBarcode(ctx context.Context, barcode string) (int32, error) {
+	bookCopy, err := s.queries.GetBookCopyByBarcode(ctx, barcode)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("book copy not found")
+		}
+		return 0, fmt.Errorf("failed to get book copy: %w", err)
+	}
+
+	transaction, err := s.queries.GetActiveTransactionByCopyID(ctx, pgtype.Int4{Int32: bookCopy.ID, Valid: true})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("no active loan found for copy %s", barcode)
+		}
+		return 0, fmt.Errorf("failed to get active loan for copy: %w", err)
+	}
+
+	return transaction.ID, nil
+}
+
+// RenewBook renews a borrowed book with comprehensive validation.
+// The original transaction and the student rows are locked while renewal
+// limits are checked so concurrent renewals cannot exceed them.
 func (s *TransactionService) RenewBook(ctx context.Context, transactionID, librarianID int32) (*TransactionResponse, error) {
+	var response *TransactionResponse
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		var err error
+		response, err = tx.renewBook(ctx, transactionID, librarianID, 0)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// RenewOwnLoan renews a loan on behalf of the student who holds it. The same
+// eligibility rules apply as for renewals at the desk.
+func (s *TransactionService) RenewOwnLoan(ctx context.Context, transactionID, studentID int32) (*TransactionResponse, error) {
+	var response *TransactionResponse
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		var err error
+		response, err = tx.renewBook(ctx, transactionID, 0, studentID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// renewBook performs the renewal steps; it must run inside ExecTx. A non-zero
+// studentID restricts the renewal to that student's own loans.
+func (s *TransactionService) renewBook(ctx context.Context, transactionID, librarianID, studentID int32) (*TransactionResponse, error) {
 	// Get original transaction
-	transactionRow, err := s.queries.GetTransactionByID(ctx, transactionID)
+	transactionRow, err := s.lockLoan(ctx, transactionID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("transaction not found")
-		}
-		return nil, fmt.Errorf("failed to get transaction: %w", err)
+		return nil, err
+	}
+
+	// Other students' loans are reported as missing rather than forbidden
+	if studentID != 0 && transactionRow.StudentID != studentID {
+		return nil, fmt.Errorf("transaction not found")
+	}
+
+	policy, err := s.resolvePolicy(ctx, transactionPolicyContext(transactionRow))
+	if err != nil {
+		return nil, err
 	}
 
 	// Comprehensive renewal validation
-	if err := s.validateRenewalEligibility(ctx, transactionRow); err != nil {
+	if err := s.validateRenewalEligibility(ctx, transactionRow, policy); err != nil {
 		return nil, err
 	}
 
-	// Calculate new due date based on student year
-	student, err := s.queries.GetStudentByID(ctx, transactionRow.StudentID)
-	if err != nil {
+	// Lock the student so concurrent renewals are serialised
+	if _, err := s.queries.GetStudentByIDForUpdate(ctx, transactionRow.StudentID); err != nil {
 		return nil, fmt.Errorf("failed to get student: %w", err)
 	}
 
-	newDueDate := s.calculateDueDate(student)
+	newDueDate, err := s.calculateDueDate(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create renewal transaction
 	transaction, err := s.queries.CreateTransaction(ctx, queries.CreateTransactionParams{
@@ -271,8 +751,9 @@ func (s *TransactionService) RenewBook(ctx context.Context, transactionID, libra
 		BookID:          transactionRow.BookID,
 		TransactionType: "renew",
 		DueDate:         pgtype.Timestamp{Time: newDueDate, Valid: true},
-		LibrarianID:     pgtype.Int4{Int32: librarianID, Valid: true},
-		Notes:           pgtype.Text{String: fmt.Sprintf("Renewal of transaction #%d", transactionID), Valid: true},
+		LibrarianID:     pgtype.Int4{Int32: librarianID, Valid: librarianID != 0},
+		Notes:           pgtype.Text{String: fmt.Sprintf("Renewal of transaction #%d", transactionRow.ID), Valid: true},
+		CopyID:          transactionRow.CopyID,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create renewal transaction: %w", err)
@@ -290,13 +771,60 @@ func (s *TransactionService) GetOverdueTransactions(ctx context.Context) ([]quer
 	return transactions, nil
 }
 
-// PayFine marks a transaction fine as paid
-func (s *TransactionService) PayFine(ctx context.Context, transactionID int32) error {
-	err := s.queries.PayTransactionFine(ctx, transactionID)
+// PayFine pays off the outstanding balance of every fine raised against a loan.
+// Each payment is recorded in the fine ledger with its own receipt.
+func (s *TransactionService) PayFine(ctx context.Context, transactionID int32, req models.PayTransactionFineRequest, actor AuditActor) ([]models.FineLedgerResultResponse, error) {
+	paymentMethod := req.PaymentMethod
+	if paymentMethod == "" {
+		paymentMethod = string(models.PaymentMethodCash)
+	}
+
+	var results []models.FineLedgerResultResponse
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		if _, err := tx.queries.GetTransactionByID(ctx, transactionID); err != nil {
+			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+				return fmt.Errorf("transaction not found")
+			}
+			return fmt.Errorf("failed to get transaction: %w", err)
+		}
+
+		fines, err := tx.queries.ListFinesByTransaction(ctx, pgtype.Int4{Int32: transactionID, Valid: true})
+		if err != nil {
+			return fmt.Errorf("failed to list loan fines: %w", err)
+		}
+
+		for _, listed := range fines {
+			fine, err := tx.queries.GetFineByIDForUpdate(ctx, listed.ID)
+			if err != nil {
+				return fmt.Errorf("failed to get fine: %w", err)
+			}
+
+			balance := convertToFineResponse(fine).Balance
+			if !balance.IsPositive() {
+				continue
+			}
+
+			result, err := recordLedgerEntry(ctx, tx.queries, fine, ledgerEntry{
+				entryType:     models.FineLedgerEntryPayment,
+				amount:        balance,
+				paymentMethod: paymentMethod,
+				reference:     req.Reference,
+			}, actor)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+
+		if len(results) == 0 {
+			return fmt.Errorf("validation error: transaction %d has no outstanding fine", transactionID)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to pay fine: %w", err)
+		return nil, err
 	}
-	return nil
+	return results, nil
 }
 
 // GetTransactionHistory returns transaction history for a student
@@ -312,10 +840,19 @@ func (s *TransactionService) GetTransactionHistory(ctx context.Context, studentI
 	return transactions, nil
 }
 
-// calculateFine calculates the fine amount based on overdue days
-func (s *TransactionService) calculateFine(dueDate, returnDate time.Time) decimal.Decimal {
+// calculateFine calculates the fine amount based on overdue days, charging only
+// for the days beyond the policy's grace period. Days the library was closed are not counted.
+func (s *TransactionService) calculateFine(ctx context.Context, dueDate, returnDate time.Time, policy *CirculationPolicy) (decimal.Decimal, error) {
 	if returnDate.Before(dueDate) || returnDate.Equal(dueDate) {
-		return decimal.Zero
+		return decimal.Zero, nil
+	}
+
+	if s.calendar != nil {
+		openDays, err := s.calendar.CountOpenDays(ctx, dueDate, returnDate)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("failed to count overdue days: %w", err)
+		}
+		return policy.fineForOverdueDays(openDays), nil
 	}
 
 	// Calculate calendar days difference for overdue period
@@ -328,20 +865,21 @@ func (s *TransactionService) calculateFine(dueDate, returnDate time.Time) decima
 	// Use a more precise approach: calculate the number of full days between dates
 	daysDiff := int(returnDateMidnight.Sub(dueDateMidnight) / (24 * time.Hour))
 
-	if daysDiff <= 0 {
-		return decimal.Zero
-	}
-
-	return s.finePerDay.Mul(decimal.NewFromInt(int64(daysDiff)))
+	return policy.fineForOverdueDays(daysDiff), nil
 }
 
 // validateBorrowingEligibility performs comprehensive validation for borrowing eligibility
-func (s *TransactionService) validateBorrowingEligibility(ctx context.Context, student queries.Student, book queries.Book, studentID, bookID int32) error {
+func (s *TransactionService) validateBorrowingEligibility(ctx context.Context, student queries.Student, book queries.Book, policy *CirculationPolicy, studentID, bookID int32) error {
 	// Check if student is active
 	if !student.IsActive.Bool {
 		return fmt.Errorf("student account is not active")
 	}
 
+	// Check for fines, overdue and librarian blocks on the account
+	if err := s.checkNotBlocked(ctx, studentID); err != nil {
+		return err
+	}
+
 	// Check if book is available
 	if book.AvailableCopies.Int32 <= 0 {
 		return fmt.Errorf("book not available")
@@ -357,9 +895,10 @@ func (s *TransactionService) validateBorrowingEligibility(ctx context.Context, s
 	if err != nil {
 		return fmt.Errorf("failed to check active transactions: %w", err)
 	}
+	activeTransactions = currentLoans(activeTransactions)
 
-	if len(activeTransactions) >= s.maxBooksPerUser {
-		return fmt.Errorf("student has reached the maximum number of books (%d)", s.maxBooksPerUser)
+	if len(activeTransactions) >= policy.MaxLoans {
+		return fmt.Errorf("student has reached the maximum number of books (%d)", policy.MaxLoans)
 	}
 
 	// Check if student already has this book
@@ -382,6 +921,14 @@ func (s *TransactionService) validateBorrowingEligibility(ctx context.Context, s
 	return nil
 }
 
+// checkNotBlocked returns an error wrapping ErrStudentBlocked if the student's account is blocked
+func (s *TransactionService) checkNotBlocked(ctx context.Context, studentID int32) error {
+	if s.blocks == nil {
+		return nil
+	}
+	return s.blocks.CheckNotBlocked(ctx, studentID)
+}
+
 // hasOverdueBooks checks if a student has any overdue books
 func (s *TransactionService) hasOverdueBooks(ctx context.Context, studentID int32) (bool, error) {
 	activeTransactions, err := s.queries.ListActiveTransactionsByStudent(ctx, studentID)
@@ -390,7 +937,7 @@ func (s *TransactionService) hasOverdueBooks(ctx context.Context, studentID int3
 	}
 
 	now := time.Now()
-	for _, tx := range activeTransactions {
+	for _, tx := range currentLoans(activeTransactions) {
 		if tx.DueDate.Valid && now.After(tx.DueDate.Time) {
 			return true, nil
 		}
@@ -401,8 +948,13 @@ func (s *TransactionService) hasOverdueBooks(ctx context.Context, studentID int3
 
 // validateBorrowingPeriod validates the borrowing period based on student year
 func (s *TransactionService) validateBorrowingPeriod(student queries.Student) int {
+	return loanPeriodForYear(student.YearOfStudy)
+}
+
+// loanPeriodForYear returns the built-in loan period used when no policy matches
+func loanPeriodForYear(yearOfStudy int32) int {
 	// Different loan periods based on student year
-	switch student.YearOfStudy {
+	switch yearOfStudy {
 	case 1, 2:
 		return 14 // 2 weeks for junior students
 	case 3, 4:
@@ -412,10 +964,52 @@ func (s *TransactionService) validateBorrowingPeriod(student queries.Student) in
 	}
 }
 
-// calculateDueDate calculates the due date based on student type and borrowing rules
-func (s *TransactionService) calculateDueDate(student queries.Student) time.Time {
-	loanPeriod := s.validateBorrowingPeriod(student)
-	return time.Now().AddDate(0, 0, loanPeriod)
+// calculateDueDate calculates the due date from the applicable circulation policy,
+// rolling forward to the next day the library is open
+func (s *TransactionService) calculateDueDate(ctx context.Context, policy *CirculationPolicy) (time.Time, error) {
+	dueDate := time.Now().AddDate(0, 0, policy.LoanDays)
+	if s.calendar == nil {
+		return dueDate, nil
+	}
+
+	dueDate, err := s.calendar.NextOpenDay(ctx, dueDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to calculate due date: %w", err)
+	}
+	return dueDate, nil
+}
+
+// resolvePolicy returns the circulation policy for a patron and item, falling
+// back to the service defaults when no resolver is set or no rule matches
+func (s *TransactionService) resolvePolicy(ctx context.Context, pc PolicyContext) (*CirculationPolicy, error) {
+	if s.policies != nil {
+		policy, err := s.policies.ResolvePolicy(ctx, pc)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			return policy, nil
+		}
+	}
+	return s.defaultPolicy(pc), nil
+}
+
+// defaultPolicy builds the fallback policy from the service settings
+func (s *TransactionService) defaultPolicy(pc PolicyContext) *CirculationPolicy {
+	return &CirculationPolicy{
+		Name:        "default",
+		LoanDays:    loanPeriodForYear(pc.YearOfStudy),
+		MaxLoans:    s.maxBooksPerUser,
+		MaxRenewals: s.maxRenewals,
+		FinePerDay:  s.finePerDay,
+
+		ReplacementCost:     s.replacementCost,
+		ProcessingFee:       s.processingFee,
+		DamageChargePerStep: s.damageChargePerStep,
+		DamageStepThreshold: s.damageStepThreshold,
+		RecallLoanDays:      defaultRecallLoanDays,
+		RecallFinePerDay:    defaultRecallFinePerDay,
+	}
 }
 
 // validateReturnTransaction validates a transaction for return processing
@@ -452,29 +1046,40 @@ func (s *TransactionService) validateReturnCondition(condition string) error {
 	return fmt.Errorf("invalid return condition: %s. Valid conditions are: %v", condition, validConditions)
 }
 
-// updateBookConditionIfNeeded updates the book's condition if it has deteriorated
-func (s *TransactionService) updateBookConditionIfNeeded(ctx context.Context, bookID int32, book queries.Book, returnCondition string) error {
-	currentCondition := "good" // Default condition
-	if book.Condition.Valid {
-		currentCondition = book.Condition.String
+// conditionRank orders copy conditions: excellent > good > fair > poor > damaged
+var conditionRank = map[string]int{
+	"excellent": 5,
+	"good":      4,
+	"fair":      3,
+	"poor":      2,
+	"damaged":   1,
+}
+
+// copyCondition returns a copy's recorded condition, assuming good when unset
+func copyCondition(bookCopy queries.BookCopy) string {
+	if bookCopy.Condition.Valid {
+		return bookCopy.Condition.String
 	}
+	return "good"
+}
 
-	// Condition hierarchy: excellent > good > fair > poor > damaged
-	conditionRank := map[string]int{
-		"excellent": 5,
-		"good":      4,
-		"fair":      3,
-		"poor":      2,
-		"damaged":   1,
+// conditionStepsLost returns how many condition steps a copy dropped; zero if it did not deteriorate
+func conditionStepsLost(from, to string) int {
+	steps := conditionRank[from] - conditionRank[to]
+	if steps < 0 {
+		return 0
 	}
+	return steps
+}
 
-	currentRank := conditionRank[currentCondition]
-	returnRank := conditionRank[returnCondition]
+// updateBookConditionIfNeeded updates the returned copy's condition if it has deteriorated
+func (s *TransactionService) updateBookConditionIfNeeded(ctx context.Context, bookCopy queries.BookCopy, returnCondition string) error {
+	currentCondition := copyCondition(bookCopy)
 
 	// Only update if condition has deteriorated
-	if returnRank < currentRank {
-		err := s.queries.UpdateBookCondition(ctx, queries.UpdateBookConditionParams{
-			ID:        bookID,
+	if conditionStepsLost(currentCondition, returnCondition) > 0 {
+		err := s.queries.UpdateBookCopyCondition(ctx, queries.UpdateBookCopyConditionParams{
+			ID:        bookCopy.ID,
 			Condition: pgtype.Text{String: returnCondition, Valid: true},
 		})
 		if err != nil {
@@ -509,6 +1114,10 @@ func (s *TransactionService) convertToTransactionResponse(tx queries.Transaction
 		response.LibrarianID = &tx.LibrarianID.Int32
 	}
 
+	if tx.CopyID.Valid {
+		response.CopyID = &tx.CopyID.Int32
+	}
+
 	if tx.FineAmount.Valid && tx.FineAmount.Int != nil {
 		// Handle the decimal conversion with proper scale
 		if tx.FineAmount.Exp == 0 {
@@ -528,13 +1137,25 @@ func (s *TransactionService) convertToTransactionResponse(tx queries.Transaction
 		response.ConditionNotes = tx.ConditionNotes.String
 	}
 
+	if tx.LossStatus.Valid {
+		response.LossStatus = tx.LossStatus.String
+	}
+
+	if tx.RecalledAt.Valid {
+		response.RecalledAt = &tx.RecalledAt.Time
+	}
+
+	if tx.OriginalDueDate.Valid {
+		response.OriginalDueDate = &tx.OriginalDueDate.Time
+	}
+
 	return response
 }
 
 // Phase 6.7: Enhanced Renewal System Functions
 
 // validateRenewalEligibility performs comprehensive validation for renewal eligibility
-func (s *TransactionService) validateRenewalEligibility(ctx context.Context, tx queries.GetTransactionByIDRow) error {
+func (s *TransactionService) validateRenewalEligibility(ctx context.Context, tx queries.GetTransactionByIDRow, policy *CirculationPolicy) error {
 	// Check if already returned
 	if tx.ReturnedDate.Valid {
 		return fmt.Errorf("cannot renew returned book")
@@ -545,6 +1166,16 @@ func (s *TransactionService) validateRenewalEligibility(ctx context.Context, tx
 		return fmt.Errorf("cannot renew overdue book")
 	}
 
+	// A recalled book has to come back by its recall due date
+	if tx.RecalledAt.Valid {
+		return fmt.Errorf("cannot renew recalled book")
+	}
+
+	// Check for fines, overdue and librarian blocks on the account
+	if err := s.checkNotBlocked(ctx, tx.StudentID); err != nil {
+		return err
+	}
+
 	// Check maximum renewals limit
 	renewalCount, err := s.queries.CountRenewalsByStudentAndBook(ctx, queries.CountRenewalsByStudentAndBookParams{
 		StudentID: tx.StudentID,
@@ -554,8 +1185,8 @@ func (s *TransactionService) validateRenewalEligibility(ctx context.Context, tx
 		return fmt.Errorf("failed to check renewal count: %w", err)
 	}
 
-	if renewalCount >= int64(s.maxRenewals) {
-		return fmt.Errorf("maximum number of renewals (%d) reached for this book", s.maxRenewals)
+	if renewalCount >= int64(policy.MaxRenewals) {
+		return fmt.Errorf("maximum number of renewals (%d) reached for this book", policy.MaxRenewals)
 	}
 
 	// Check if book is reserved by another student
@@ -585,6 +1216,27 @@ func (s *TransactionService) CanBookBeRenewed(ctx context.Context, transactionID
 		return false, "", fmt.Errorf("failed to get transaction: %w", err)
 	}
 
+	return s.canRenew(ctx, transactionRow)
+}
+
+// CanRenewOwnLoan checks whether a student can renew one of their own loans
+func (s *TransactionService) CanRenewOwnLoan(ctx context.Context, transactionID, studentID int32) (bool, string, error) {
+	transactionRow, err := s.queries.GetTransactionByID(ctx, transactionID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return false, "Transaction not found", nil
+		}
+		return false, "", fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if transactionRow.StudentID != studentID {
+		return false, "Transaction not found", nil
+	}
+
+	return s.canRenew(ctx, transactionRow)
+}
+
+// canRenew reports whether a loan can be renewed and the reason when it cannot
+func (s *TransactionService) canRenew(ctx context.Context, transactionRow queries.GetTransactionByIDRow) (bool, string, error) {
 	// Check if already returned
 	if transactionRow.ReturnedDate.Valid {
 		return false, "Book has already been returned", nil
@@ -595,6 +1247,18 @@ func (s *TransactionService) CanBookBeRenewed(ctx context.Context, transactionID
 		return false, "Book is overdue and must be returned first", nil
 	}
 
+	if transactionRow.RecalledAt.Valid {
+		return false, fmt.Sprintf("Book has been recalled and must be returned by %s", transactionRow.DueDate.Time.Format("2006-01-02")), nil
+	}
+
+	// Check for fines, overdue and librarian blocks on the account
+	if err := s.checkNotBlocked(ctx, transactionRow.StudentID); err != nil {
+		if errors.Is(err, ErrStudentBlocked) {
+			return false, "Account is blocked: " + strings.TrimPrefix(err.Error(), ErrStudentBlocked.Error()+": "), nil
+		}
+		return false, "", err
+	}
+
 	// Check maximum renewals limit
 	renewalCount, err := s.queries.CountRenewalsByStudentAndBook(ctx, queries.CountRenewalsByStudentAndBookParams{
 		StudentID: transactionRow.StudentID,
@@ -604,8 +1268,13 @@ func (s *TransactionService) CanBookBeRenewed(ctx context.Context, transactionID
 		return false, "", fmt.Errorf("failed to check renewal count: %w", err)
 	}
 
-	if renewalCount >= int64(s.maxRenewals) {
-		return false, fmt.Sprintf("Maximum number of renewals (%d) reached", s.maxRenewals), nil
+	policy, err := s.resolvePolicy(ctx, transactionPolicyContext(transactionRow))
+	if err != nil {
+		return false, "", err
+	}
+
+	if renewalCount >= int64(policy.MaxRenewals) {
+		return false, fmt.Sprintf("Maximum number of renewals (%d) reached", policy.MaxRenewals), nil
 	}
 
 	// Check if book is reserved by another student
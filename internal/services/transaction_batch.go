@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// errBatchRolledBack is reported for items undone because another item in an
+// all-or-nothing batch failed
+var errBatchRolledBack = errors.New("not processed: another item in the batch failed and the batch was rolled back")
+
+// BatchItemResult is the outcome of one item in a batch checkout or check-in
+type BatchItemResult struct {
+	Index       int
+	Transaction *TransactionResponse
+	Err         error
+}
+
+// BatchBorrow issues several books to one student. The whole set is checked
+// against the student's loan limit before anything is issued; each item then
+// goes through the same rules as a single checkout.
+func (s *TransactionService) BatchBorrow(ctx context.Context, req models.BatchBorrowRequest) ([]BatchItemResult, error) {
+	bookIDs, err := s.validateBatchBorrow(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.runBatch(ctx, len(req.Items), req.Mode, func(tx *TransactionService, i int) (*TransactionResponse, error) {
+		item := req.Items[i]
+		return tx.borrowBook(ctx, req.StudentID, bookIDs[i], item.Barcode, req.LibrarianID, item.Notes)
+	}), nil
+}
+
+// validateBatchBorrow checks the batch as a whole and returns the book ID of each item
+func (s *TransactionService) validateBatchBorrow(ctx context.Context, req models.BatchBorrowRequest) ([]int32, error) {
+	student, err := s.queries.GetStudentByID(ctx, req.StudentID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("student not found")
+		}
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+	if !student.IsActive.Bool {
+		return nil, fmt.Errorf("student account is not active")
+	}
+	if err := s.checkNotBlocked(ctx, req.StudentID); err != nil {
+		return nil, err
+	}
+
+	bookIDs := make([]int32, len(req.Items))
+	seen := make(map[int32]int, len(req.Items))
+	maxLoans := -1
+	for i, item := range req.Items {
+		if (item.BookID == 0) == (item.Barcode == "") {
+			return nil, fmt.Errorf("item %d: either book_id or barcode must be provided", i)
+		}
+
+		bookID := item.BookID
+		if item.Barcode != "" {
+			bookCopy, err := s.queries.GetBookCopyByBarcode(ctx, item.Barcode)
+			if err != nil {
+				if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+					return nil, fmt.Errorf("item %d: book copy not found", i)
+				}
+				return nil, fmt.Errorf("failed to get book copy: %w", err)
+			}
+			bookID = bookCopy.BookID
+		}
+		if first, ok := seen[bookID]; ok {
+			return nil, fmt.Errorf("items %d and %d are the same book", first, i)
+		}
+		seen[bookID] = i
+		bookIDs[i] = bookID
+
+		book, err := s.queries.GetBookByID(ctx, bookID)
+		if err != nil {
+			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+				return nil, fmt.Errorf("item %d: book not found", i)
+			}
+			return nil, fmt.Errorf("failed to get book: %w", err)
+		}
+
+		// The strictest policy among the requested books bounds the batch
+		policy, err := s.resolvePolicy(ctx, studentPolicyContext(student, book))
+		if err != nil {
+			return nil, err
+		}
+		if maxLoans < 0 || policy.MaxLoans < maxLoans {
+			maxLoans = policy.MaxLoans
+		}
+	}
+
+	active, err := s.queries.ListActiveTransactionsByStudent(ctx, req.StudentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check active transactions: %w", err)
+	}
+	active = currentLoans(active)
+	if len(active)+len(req.Items) > maxLoans {
+		return nil, fmt.Errorf("student has %d books on loan; borrowing %d more would exceed the maximum of %d", len(active), len(req.Items), maxLoans)
+	}
+
+	return bookIDs, nil
+}
+
+// BatchReturn checks in several loans, each by transaction ID or copy barcode
+func (s *TransactionService) BatchReturn(ctx context.Context, req models.BatchReturnRequest, actor AuditActor) ([]BatchItemResult, error) {
+	return s.batchReturn(ctx, req, actor, nil)
+}
+
+// BatchReturn checks in several loans. Every returned copy is held for the next
+// reservation in its queue, in the same database transaction as the return.
+func (s *EnhancedTransactionService) BatchReturn(ctx context.Context, req models.BatchReturnRequest, actor AuditActor) ([]BatchItemResult, error) {
+	return s.TransactionService.batchReturn(ctx, req, actor, func(tx *TransactionService, transaction *TransactionResponse) error {
+		return s.handleReservationFulfillment(ctx, tx, transaction)
+	})
+}
+
+// batchReturn returns each item and then runs afterReturn, if set, inside the
+// same database transaction
+func (s *TransactionService) batchReturn(ctx context.Context, req models.BatchReturnRequest, actor AuditActor, afterReturn func(tx *TransactionService, transaction *TransactionResponse) error) ([]BatchItemResult, error) {
+	if err := s.validateBatchReturn(req); err != nil {
+		return nil, err
+	}
+
+	return s.runBatch(ctx, len(req.Items), req.Mode, func(tx *TransactionService, i int) (*TransactionResponse, error) {
+		item := req.Items[i]
+
+		transactionID := item.TransactionID
+		if item.Barcode != "" {
+			var err error
+			if transactionID, err = tx.activeTransactionForBarcode(ctx, item.Barcode); err != nil {
+				return nil, err
+			}
+		}
+
+		condition := item.ReturnCondition
+		if condition == "" {
+			condition = "good"
+		}
+
+		transaction, err := tx.returnBookWithCondition(ctx, transactionID, condition, item.ConditionNotes, actor)
+		if err != nil {
+			return nil, err
+		}
+
+		if afterReturn != nil {
+			if err := afterReturn(tx, transaction); err != nil {
+				return nil, err
+			}
+		}
+		return transaction, nil
+	}), nil
+}
+
+// validateBatchReturn checks that each item names one loan and no loan is returned twice
+func (s *TransactionService) validateBatchReturn(req models.BatchReturnRequest) error {
+	transactions := make(map[int32]int, len(req.Items))
+	barcodes := make(map[string]int, len(req.Items))
+	for i, item := range req.Items {
+		if (item.TransactionID == 0) == (item.Barcode == "") {
+			return fmt.Errorf("item %d: either transaction_id or barcode must be provided", i)
+		}
+		if item.ReturnCondition != "" {
+			if err := s.validateReturnCondition(item.ReturnCondition); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+
+		if item.TransactionID != 0 {
+			if first, ok := transactions[item.TransactionID]; ok {
+				return fmt.Errorf("items %d and %d return the same transaction", first, i)
+			}
+			transactions[item.TransactionID] = i
+		} else {
+			if first, ok := barcodes[item.Barcode]; ok {
+				return fmt.Errorf("items %d and %d return the same copy", first, i)
+			}
+			barcodes[item.Barcode] = i
+		}
+	}
+	return nil
+}
+
+// runBatch processes n items. In all-or-nothing mode they share one database
+// transaction and the first failure undoes the rest; otherwise each item commits
+// on its own. process is given the service bound to the item's database transaction.
+func (s *TransactionService) runBatch(ctx context.Context, n int, mode models.BatchMode, process func(tx *TransactionService, i int) (*TransactionResponse, error)) []BatchItemResult {
+	results := make([]BatchItemResult, n)
+	for i := range results {
+		results[i].Index = i
+	}
+
+	if mode == models.BatchModeBestEffort {
+		for i := range results {
+			err := s.execTx(ctx, func(tx *TransactionService) error {
+				var err error
+				results[i].Transaction, err = process(tx, i)
+				return err
+			})
+			if err != nil {
+				results[i].Transaction = nil
+				results[i].Err = err
+			}
+		}
+		return results
+	}
+
+	failed := -1
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		for i := range results {
+			transaction, err := process(tx, i)
+			if err != nil {
+				failed = i
+				return err
+			}
+			results[i].Transaction = transaction
+		}
+		return nil
+	})
+	if err != nil {
+		for i := range results {
+			results[i].Transaction = nil
+			results[i].Err = err
+			if failed >= 0 && i != failed {
+				results[i].Err = errBatchRolledBack
+			}
+		}
+	}
+	return results
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+func TestTransactionService_BatchBorrow(t *testing.T) {
+	ctx := context.Background()
+	studentID := int32(1)
+
+	secondBook := createTestBook()
+	secondBook.ID = 2
+	secondBook.BookID = "BK002"
+
+	t.Run("RejectsBatchOverLoanLimit", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries).WithMaxBooksPerUser(2)
+
+		mockQueries.On("GetStudentByID", ctx, studentID).Return(createTestStudent(), nil)
+		mockQueries.On("GetBookByID", ctx, int32(1)).Return(createTestBook(), nil)
+		mockQueries.On("GetBookByID", ctx, int32(2)).Return(secondBook, nil)
+		mockQueries.On("ListActiveTransactionsByStudent", ctx, studentID).Return([]queries.ListActiveTransactionsByStudentRow{
+			{ID: 5, StudentID: studentID, BookID: 7, TransactionType: "borrow"},
+			{ID: 6, StudentID: studentID, BookID: 7, TransactionType: "renew"},
+		}, nil)
+
+		results, err := service.BatchBorrow(ctx, models.BatchBorrowRequest{
+			StudentID:   studentID,
+			LibrarianID: 1,
+			Items:       []models.BatchBorrowItem{{BookID: 1}, {BookID: 2}},
+		})
+
+		// The renewed loan counts once
+		require.Error(t, err)
+		assert.Nil(t, results)
+		assert.Contains(t, err.Error(), "student has 1 books on loan")
+		assert.Contains(t, err.Error(), "would exceed the maximum of 2")
+		mockQueries.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("RejectsDuplicateBook", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		mockQueries.On("GetStudentByID", ctx, studentID).Return(createTestStudent(), nil)
+		mockQueries.On("GetBookCopyByBarcode", ctx, "BK001-001").Return(createTestBookCopy(), nil)
+		mockQueries.On("GetBookByID", ctx, int32(1)).Return(createTestBook(), nil)
+
+		_, err := service.BatchBorrow(ctx, models.BatchBorrowRequest{
+			StudentID:   studentID,
+			LibrarianID: 1,
+			Items:       []models.BatchBorrowItem{{BookID: 1}, {Barcode: "BK001-001"}},
+		})
+
+		require.Error(t, err)
+		assert.Equal(t, "items 0 and 1 are the same book", err.Error())
+	})
+
+	// setupBorrows stubs validation for two books, a successful loan of the
+	// first and a failure on the second
+	setupBorrows := func(mockQueries *MockTransactionQueries) {
+		mockQueries.On("GetStudentByID", ctx, studentID).Return(createTestStudent(), nil)
+		mockQueries.On("GetBookByID", ctx, int32(1)).Return(createTestBook(), nil)
+		mockQueries.On("GetBookByID", ctx, int32(2)).Return(secondBook, nil)
+		mockQueries.On("ListActiveTransactionsByStudent", ctx, studentID).Return([]queries.ListActiveTransactionsByStudentRow{}, nil)
+
+		mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(createTestBook(), nil)
+		mockQueries.On("GetBookByIDForUpdate", ctx, int32(2)).Return(queries.Book{}, errors.New("connection reset"))
+		mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(createTestStudent(), nil)
+		mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, pgx.ErrNoRows)
+		mockQueries.On("GetAvailableBookCopyForUpdate", ctx, int32(1)).Return(createTestBookCopy(), nil)
+		mockQueries.On("CreateTransaction", ctx, mock.AnythingOfType("queries.CreateTransactionParams")).Return(createTestTransaction(), nil)
+		mockQueries.On("UpdateBookCopyStatus", ctx, mock.AnythingOfType("queries.UpdateBookCopyStatusParams")).Return(nil)
+		mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+	}
+
+	t.Run("BestEffortKeepsSuccessfulItems", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+		setupBorrows(mockQueries)
+
+		results, err := service.BatchBorrow(ctx, models.BatchBorrowRequest{
+			StudentID:   studentID,
+			LibrarianID: 1,
+			Mode:        models.BatchModeBestEffort,
+			Items:       []models.BatchBorrowItem{{BookID: 1}, {BookID: 2}},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.NoError(t, results[0].Err)
+		assert.NotNil(t, results[0].Transaction)
+		assert.Error(t, results[1].Err)
+		assert.Contains(t, results[1].Err.Error(), "failed to get book")
+	})
+
+	t.Run("AllOrNothingRollsBackEveryItem", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+		setupBorrows(mockQueries)
+
+		results, err := service.BatchBorrow(ctx, models.BatchBorrowRequest{
+			StudentID:   studentID,
+			LibrarianID: 1,
+			Items:       []models.BatchBorrowItem{{BookID: 1}, {BookID: 2}},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Nil(t, results[0].Transaction)
+		assert.Equal(t, errBatchRolledBack, results[0].Err)
+		assert.Contains(t, results[1].Err.Error(), "failed to get book")
+	})
+}
+
+func TestEnhancedTransactionService_BatchReturn(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("HoldsCopyForEachReservation", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		notifier := &MockStudentNotifier{}
+		service := NewEnhancedTransactionService(mockQueries, nil)
+		service.WithNotifier(notifier)
+
+		now := time.Now()
+		for _, id := range []int32{1, 2} {
+			loan := queries.GetTransactionByIDForUpdateRow{
+				ID:              id,
+				StudentID:       1,
+				BookID:          id,
+				TransactionType: "borrow",
+				DueDate:         pgtype.Timestamp{Time: now.AddDate(0, 0, 1), Valid: true},
+			}
+			returned := createTestTransaction()
+			returned.ID = id
+			returned.BookID = id
+			returned.ReturnedDate = pgtype.Timestamp{Time: now, Valid: true}
+
+			book := createTestBook()
+			book.ID = id
+
+			mockQueries.On("GetTransactionByIDForUpdate", ctx, id).Return(loan, nil)
+			mockQueries.On("GetCurrentLoanTransactionID", ctx, id).Return(id, nil)
+			mockQueries.On("ReturnBook", ctx, mock.MatchedBy(func(arg queries.ReturnBookParams) bool { return arg.ID == id })).Return(returned, nil)
+			mockQueries.On("CloseRenewedLoanTransactions", ctx, id).Return(nil)
+			mockQueries.On("GetBookByIDForUpdate", ctx, id).Return(book, nil)
+		}
+		mockQueries.On("UpdateBookAvailability", ctx, mock.AnythingOfType("queries.UpdateBookAvailabilityParams")).Return(nil)
+		mockQueries.On("GetNextReservationForBook", ctx, int32(1)).Return(queries.GetNextReservationForBookRow{ID: 40, BookID: 1}, nil)
+		mockQueries.On("GetNextReservationForBook", ctx, int32(2)).Return(queries.GetNextReservationForBookRow{}, pgx.ErrNoRows)
+		mockQueries.On("MarkReservationReady", ctx, mock.MatchedBy(func(arg queries.MarkReservationReadyParams) bool {
+			return arg.ID == 40 && arg.PickupDeadline.Time.After(now)
+		})).Return(queries.Reservation{ID: 40, Status: pgtype.Text{String: "ready", Valid: true}}, nil)
+		mockQueries.On("GetBookByID", ctx, int32(1)).Return(createTestBook(), nil)
+		notifier.On("CreateNotification", ctx, mock.MatchedBy(func(req *models.NotificationRequest) bool {
+			return req.Type == models.NotificationTypeReservationReady
+		})).Return(&models.NotificationResponse{}, nil).Once()
+
+		results, err := service.BatchReturn(ctx, models.BatchReturnRequest{
+			Items: []models.BatchReturnItem{{TransactionID: 1}, {TransactionID: 2, ReturnCondition: "fair"}},
+		}, testLibrarian)
+
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for _, result := range results {
+			assert.NoError(t, result.Err)
+		}
+		mockQueries.AssertCalled(t, "GetNextReservationForBook", ctx, int32(1))
+		mockQueries.AssertCalled(t, "GetNextReservationForBook", ctx, int32(2))
+		mockQueries.AssertExpectations(t)
+		notifier.AssertExpectations(t)
+	})
+
+	t.Run("RejectsDuplicateTransaction", func(t *testing.T) {
+		service := NewEnhancedTransactionService(&MockTransactionQueries{}, nil)
+
+		_, err := service.BatchReturn(ctx, models.BatchReturnRequest{
+			Items: []models.BatchReturnItem{{TransactionID: 3}, {TransactionID: 3}},
+		}, testLibrarian)
+
+		require.Error(t, err)
+		assert.Equal(t, "items 0 and 1 return the same transaction", err.Error())
+	})
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+)
+
+// realtimeOutbox holds the changes made in a database transaction, and the
+// notices telling students about them, until it commits, so nobody is ever told
+// about changes that are rolled back
+type realtimeOutbox struct {
+	notices         []*models.NotificationRequest
+	reservations    []queries.Reservation
+	countersChanged bool
+}
+
+// execTx runs fn with a copy of the service bound to a new database transaction,
+// and sends the notices and announces the changes fn recorded once the
+// transaction has committed
+func (s *TransactionService) execTx(ctx context.Context, fn func(tx *TransactionService) error) error {
+	outbox := &realtimeOutbox{}
+	err := s.queries.ExecTx(ctx, func(q TransactionQuerier) error {
+		tx := s.withQuerier(q)
+		tx.outbox = outbox
+		return fn(tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.announce(ctx, outbox)
+	return nil
+}
+
+// notifyStudent sends a notice about a change made in the open transaction once
+// it commits, or straight away when there is no open transaction
+func (s *TransactionService) notifyStudent(ctx context.Context, notice *models.NotificationRequest) {
+	if s.outbox == nil {
+		sendStudentNotice(ctx, s.notifier, notice)
+		return
+	}
+	s.outbox.notices = append(s.outbox.notices, notice)
+}
+
+// announceReservation records a reservation whose status changed in the open
+// transaction. Reservation changes move the hold shelf counter.
+func (s *TransactionService) announceReservation(reservation queries.Reservation) {
+	if s.outbox != nil {
+		s.outbox.reservations = append(s.outbox.reservations, reservation)
+		s.outbox.countersChanged = true
+	}
+}
+
+// announceCounters records that the open transaction may have moved the desk counters
+func (s *TransactionService) announceCounters() {
+	if s.outbox != nil {
+		s.outbox.countersChanged = true
+	}
+}
+
+func (s *TransactionService) announce(ctx context.Context, outbox *realtimeOutbox) {
+	// Notices announce themselves to the student as they are created
+	for _, notice := range outbox.notices {
+		sendStudentNotice(ctx, s.notifier, notice)
+	}
+
+	if s.realtime == nil {
+		return
+	}
+
+	for _, reservation := range outbox.reservations {
+		s.realtime.ReservationChanged(ctx, reservation)
+	}
+	if outbox.countersChanged {
+		s.realtime.CountersChanged(ctx)
+	}
+}
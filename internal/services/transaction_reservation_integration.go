@@ -4,13 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // ReservationServiceInterface defines the interface for reservation service operations
 type ReservationServiceInterface interface {
 	GetNextReservationForBook(ctx context.Context, bookID int32) (*ReservationResponse, error)
 	FulfillReservation(ctx context.Context, reservationID int32) (*ReservationResponse, error)
-	HasStudentFulfilledReservation(ctx context.Context, studentID, bookID int32) (*ReservationResponse, error)
+	HasStudentReadyReservation(ctx context.Context, studentID, bookID int32) (*ReservationResponse, error)
 }
 
 // EnhancedTransactionService extends the basic transaction service with reservation integration
@@ -27,59 +29,95 @@ func NewEnhancedTransactionService(queries TransactionQuerier, reservationServic
 	}
 }
 
-// ReturnBookWithReservationHandling processes a book return with automatic reservation fulfillment
-func (s *EnhancedTransactionService) ReturnBookWithReservationHandling(ctx context.Context, transactionID int32, returnCondition, conditionNotes string) (*TransactionResponse, error) {
-	// First, process the book return normally
-	transaction, err := s.TransactionService.ReturnBookWithCondition(ctx, transactionID, returnCondition, conditionNotes)
+// ReturnBookWithReservationHandling processes a book return and sets the copy aside
+// for the next reservation in the queue. The return and the hold commit together,
+// so the returned copy is never visible as available while a reservation is waiting for it.
+func (s *EnhancedTransactionService) ReturnBookWithReservationHandling(ctx context.Context, transactionID int32, returnCondition, conditionNotes string, actor AuditActor) (*TransactionResponse, error) {
+	var transaction *TransactionResponse
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		var err error
+		transaction, err = tx.returnBookWithCondition(ctx, transactionID, returnCondition, conditionNotes, actor)
+		if err != nil {
+			return err
+		}
+
+		// After successful return, check if there are any reservations for this book
+		return s.handleReservationFulfillment(ctx, tx, transaction)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// After successful return, check if there are any reservations for this book
-	go func() {
-		// Use a background goroutine to handle reservation fulfillment
-		// This ensures the return process is not blocked by reservation handling
-		s.handleReservationFulfillment(context.Background(), transaction.BookID)
-	}()
-
 	return transaction, nil
 }
 
-// handleReservationFulfillment checks for and fulfills the next reservation for a book
-func (s *EnhancedTransactionService) handleReservationFulfillment(ctx context.Context, bookID int32) {
-	// Get the next reservation for this book
-	nextReservation, err := s.reservationService.GetNextReservationForBook(ctx, bookID)
+// ReturnBook processes a book return, holding the copy for the next reservation
+func (s *EnhancedTransactionService) ReturnBook(ctx context.Context, transactionID int32, actor AuditActor) (*TransactionResponse, error) {
+	return s.ReturnBookWithReservationHandling(ctx, transactionID, "good", "", actor)
+}
+
+// ReturnBookWithCondition processes a book return with condition assessment, holding
+// the copy for the next reservation
+func (s *EnhancedTransactionService) ReturnBookWithCondition(ctx context.Context, transactionID int32, returnCondition, conditionNotes string, actor AuditActor) (*TransactionResponse, error) {
+	return s.ReturnBookWithReservationHandling(ctx, transactionID, returnCondition, conditionNotes, actor)
+}
+
+// ReturnBookByBarcode returns the open loan of the scanned copy, holding the copy
+// for the next reservation
+func (s *EnhancedTransactionService) ReturnBookByBarcode(ctx context.Context, barcode, returnCondition, conditionNotes string, actor AuditActor) (*TransactionResponse, error) {
+	var transaction *TransactionResponse
+	err := s.execTx(ctx, func(tx *TransactionService) error {
+		transactionID, err := tx.activeTransactionForBarcode(ctx, barcode)
+		if err != nil {
+			return err
+		}
+
+		transaction, err = tx.returnBookWithCondition(ctx, transactionID, returnCondition, conditionNotes, actor)
+		if err != nil {
+			return err
+		}
+
+		return s.handleReservationFulfillment(ctx, tx, transaction)
+	})
 	if err != nil {
-		log.Printf("Error getting next reservation for book %d: %v", bookID, err)
-		return
+		return nil, err
 	}
 
-	// If there's no reservation, nothing to do
-	if nextReservation == nil {
-		return
+	return transaction, nil
+}
+
+// handleReservationFulfillment puts the returned copy on the hold shelf for the
+// next reservation, if one is waiting. tx is the service bound to the return's
+// database transaction.
+func (s *EnhancedTransactionService) handleReservationFulfillment(ctx context.Context, tx *TransactionService, transaction *TransactionResponse) error {
+	var copyID pgtype.Int4
+	if transaction.CopyID != nil {
+		copyID = pgtype.Int4{Int32: *transaction.CopyID, Valid: true}
 	}
 
-	// Fulfill the reservation
-	_, err = s.reservationService.FulfillReservation(ctx, nextReservation.ID)
+	hold, err := tx.holdForNextReservation(ctx, transaction.BookID, copyID)
 	if err != nil {
-		log.Printf("Error fulfilling reservation %d for book %d: %v", nextReservation.ID, bookID, err)
-		return
+		return err
+	}
+
+	if hold != nil {
+		log.Printf("Held book %d for reservation %d until %s",
+			transaction.BookID, hold.ID, hold.PickupDeadline.Time.Format("2006-01-02"))
 	}
 
-	log.Printf("Successfully fulfilled reservation %d for book %d (student: %s)",
-		nextReservation.ID, bookID, nextReservation.StudentName)
+	return nil
 }
 
 // BorrowBookWithReservationCheck processes a book borrowing request with reservation priority check
 func (s *EnhancedTransactionService) BorrowBookWithReservationCheck(ctx context.Context, studentID, bookID, librarianID int32, notes string) (*TransactionResponse, error) {
-	// First check if the student has a fulfilled reservation for this book
-	fulfilledReservation, err := s.reservationService.HasStudentFulfilledReservation(ctx, studentID, bookID)
+	// First check if a copy is waiting on the hold shelf for the student
+	readyReservation, err := s.reservationService.HasStudentReadyReservation(ctx, studentID, bookID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check fulfilled reservation: %w", err)
+		return nil, fmt.Errorf("failed to check ready reservation: %w", err)
 	}
 
-	// If student has a fulfilled reservation, they can borrow directly
-	if fulfilledReservation != nil {
+	// Borrowing collects the held copy and fulfils the reservation
+	if readyReservation != nil {
 		return s.TransactionService.BorrowBook(ctx, studentID, bookID, librarianID, notes)
 	}
 
@@ -112,8 +150,8 @@ func (s *EnhancedTransactionService) ReservationAwareBorrowBook(ctx context.Cont
 }
 
 // ReservationAwareReturnBook is an alias for ReturnBookWithReservationHandling for backward compatibility
-func (s *EnhancedTransactionService) ReservationAwareReturnBook(ctx context.Context, transactionID int32, returnCondition, conditionNotes string) (*TransactionResponse, error) {
-	return s.ReturnBookWithReservationHandling(ctx, transactionID, returnCondition, conditionNotes)
+func (s *EnhancedTransactionService) ReservationAwareReturnBook(ctx context.Context, transactionID int32, returnCondition, conditionNotes string, actor AuditActor) (*TransactionResponse, error) {
+	return s.ReturnBookWithReservationHandling(ctx, transactionID, returnCondition, conditionNotes, actor)
 }
 
 // GetBookAvailabilityStatus returns detailed availability status including reservations
@@ -181,22 +219,30 @@ func (s *EnhancedTransactionService) CanStudentBorrowBook(ctx context.Context, s
 		Reasons:   []string{},
 	}
 
-	// Check basic validation
-	if err := s.validateBorrowingEligibility(ctx, student, book, studentID, bookID); err != nil {
-		eligibility.Reasons = append(eligibility.Reasons, err.Error())
-		return eligibility, nil
+	policy, err := s.resolvePolicy(ctx, studentPolicyContext(student, book))
+	if err != nil {
+		return nil, err
 	}
 
-	// First check if the student has a fulfilled reservation for this book
-	fulfilledReservation, err := s.reservationService.HasStudentFulfilledReservation(ctx, studentID, bookID)
+	// A copy on the hold shelf counts as available to the student it is held for
+	readyReservation, err := s.reservationService.HasStudentReadyReservation(ctx, studentID, bookID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check fulfilled reservation: %w", err)
+		return nil, fmt.Errorf("failed to check ready reservation: %w", err)
+	}
+	if readyReservation != nil && readyReservation.CopyID != nil {
+		book.AvailableCopies.Int32++
+	}
+
+	// Check basic validation
+	if err := s.validateBorrowingEligibility(ctx, student, book, policy, studentID, bookID); err != nil {
+		eligibility.Reasons = append(eligibility.Reasons, err.Error())
+		return eligibility, nil
 	}
 
-	// If student has a fulfilled reservation, they can borrow directly
-	if fulfilledReservation != nil {
+	// If a copy is held for the student, they can borrow directly
+	if readyReservation != nil {
 		eligibility.HasReservationForStudent = true
-		eligibility.ReservationID = &fulfilledReservation.ID
+		eligibility.ReservationID = &readyReservation.ID
 		eligibility.CanBorrow = true
 		return eligibility, nil
 	}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+)
+
+// TransactionStore provides the transaction queries backed by a connection pool
+// and the ability to run several of them inside one database transaction
+type TransactionStore struct {
+	*queries.Queries
+	pool *pgxpool.Pool
+}
+
+// NewTransactionStore creates a new transaction store for the given pool
+func NewTransactionStore(pool *pgxpool.Pool) *TransactionStore {
+	return &TransactionStore{
+		Queries: queries.New(pool),
+		pool:    pool,
+	}
+}
+
+// ExecTx runs fn inside a database transaction. The transaction is committed if
+// fn returns nil and rolled back otherwise. Calls made on a store that is already
+// bound to a transaction reuse it instead of starting a nested one.
+func (s *TransactionStore) ExecTx(ctx context.Context, fn func(TransactionQuerier) error) error {
+	if s.pool == nil {
+		return fn(s)
+	}
+
+	return runInTx(ctx, s.pool, func(tx pgx.Tx) error {
+		return fn(&TransactionStore{Queries: s.Queries.WithTx(tx)})
+	})
+}
+
+// runInTx begins a transaction on pool, runs fn and commits if fn succeeds
+func runInTx(ctx context.Context, pool *pgxpool.Pool, fn func(pgx.Tx) error) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		// Rollback is a no-op once the transaction has been committed
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
 )
 
 // MockQueries implements the Querier interface for testing
@@ -31,6 +32,11 @@ func (m *MockTransactionQueries) GetTransactionByID(ctx context.Context, id int3
 	return args.Get(0).(queries.GetTransactionByIDRow), args.Error(1)
 }
 
+func (m *MockTransactionQueries) GetTransactionByIDForUpdate(ctx context.Context, id int32) (queries.GetTransactionByIDForUpdateRow, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.GetTransactionByIDForUpdateRow), args.Error(1)
+}
+
 func (m *MockTransactionQueries) ListTransactions(ctx context.Context, arg queries.ListTransactionsParams) ([]queries.ListTransactionsRow, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).([]queries.ListTransactionsRow), args.Error(1)
@@ -61,9 +67,9 @@ func (m *MockTransactionQueries) UpdateTransactionFine(ctx context.Context, arg
 	return args.Error(0)
 }
 
-func (m *MockTransactionQueries) PayTransactionFine(ctx context.Context, id int32) error {
-	args := m.Called(ctx, id)
-	return args.Error(0)
+func (m *MockTransactionQueries) CreateFine(ctx context.Context, arg queries.CreateFineParams) (queries.Fine, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Fine), args.Error(1)
 }
 
 func (m *MockTransactionQueries) CountOverdueTransactions(ctx context.Context) (int64, error) {
@@ -76,21 +82,76 @@ func (m *MockTransactionQueries) GetBookByID(ctx context.Context, id int32) (que
 	return args.Get(0).(queries.Book), args.Error(1)
 }
 
+func (m *MockTransactionQueries) GetBookByIDForUpdate(ctx context.Context, id int32) (queries.Book, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Book), args.Error(1)
+}
+
 func (m *MockTransactionQueries) GetStudentByID(ctx context.Context, id int32) (queries.Student, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(queries.Student), args.Error(1)
 }
 
+func (m *MockTransactionQueries) GetStudentByIDForUpdate(ctx context.Context, id int32) (queries.Student, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Student), args.Error(1)
+}
+
 func (m *MockTransactionQueries) UpdateBookAvailability(ctx context.Context, arg queries.UpdateBookAvailabilityParams) error {
 	args := m.Called(ctx, arg)
 	return args.Error(0)
 }
 
-func (m *MockTransactionQueries) UpdateBookCondition(ctx context.Context, arg queries.UpdateBookConditionParams) error {
+func (m *MockTransactionQueries) GetAvailableBookCopyForUpdate(ctx context.Context, bookID int32) (queries.BookCopy, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).(queries.BookCopy), args.Error(1)
+}
+
+func (m *MockTransactionQueries) GetBookCopyByBarcode(ctx context.Context, barcode string) (queries.BookCopy, error) {
+	args := m.Called(ctx, barcode)
+	return args.Get(0).(queries.BookCopy), args.Error(1)
+}
+
+func (m *MockTransactionQueries) GetBookCopyByBarcodeForUpdate(ctx context.Context, barcode string) (queries.BookCopy, error) {
+	args := m.Called(ctx, barcode)
+	return args.Get(0).(queries.BookCopy), args.Error(1)
+}
+
+func (m *MockTransactionQueries) GetBookCopyByIDForUpdate(ctx context.Context, id int32) (queries.BookCopy, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.BookCopy), args.Error(1)
+}
+
+func (m *MockTransactionQueries) UpdateBookCopyStatus(ctx context.Context, arg queries.UpdateBookCopyStatusParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockTransactionQueries) UpdateBookCopyCondition(ctx context.Context, arg queries.UpdateBookCopyConditionParams) error {
 	args := m.Called(ctx, arg)
 	return args.Error(0)
 }
 
+func (m *MockTransactionQueries) SyncBookCopyCounts(ctx context.Context, id int32) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTransactionQueries) GetActiveTransactionByCopyID(ctx context.Context, copyID pgtype.Int4) (queries.Transaction, error) {
+	args := m.Called(ctx, copyID)
+	return args.Get(0).(queries.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionQueries) GetCurrentLoanTransactionID(ctx context.Context, id int32) (int32, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockTransactionQueries) CloseRenewedLoanTransactions(ctx context.Context, id int32) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockTransactionQueries) CountRenewalsByStudentAndBook(ctx context.Context, arg queries.CountRenewalsByStudentAndBookParams) (int64, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(int64), args.Error(1)
@@ -111,6 +172,119 @@ func (m *MockTransactionQueries) GetRenewalStatisticsByStudent(ctx context.Conte
 	return args.Get(0).(queries.GetRenewalStatisticsByStudentRow), args.Error(1)
 }
 
+func (m *MockTransactionQueries) GetNextReservationForBook(ctx context.Context, bookID int32) (queries.GetNextReservationForBookRow, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).(queries.GetNextReservationForBookRow), args.Error(1)
+}
+
+func (m *MockTransactionQueries) UpdateReservationStatus(ctx context.Context, arg queries.UpdateReservationStatusParams) (queries.Reservation, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Reservation), args.Error(1)
+}
+
+func (m *MockTransactionQueries) MarkReservationReady(ctx context.Context, arg queries.MarkReservationReadyParams) (queries.Reservation, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Reservation), args.Error(1)
+}
+
+func (m *MockTransactionQueries) GetReadyReservationForStudent(ctx context.Context, arg queries.GetReadyReservationForStudentParams) (queries.Reservation, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Reservation), args.Error(1)
+}
+
+func (m *MockTransactionQueries) ExpireHold(ctx context.Context, id int32) (queries.Reservation, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Reservation), args.Error(1)
+}
+
+func (m *MockTransactionQueries) CancelReservation(ctx context.Context, id int32) (queries.Reservation, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Reservation), args.Error(1)
+}
+
+func (m *MockTransactionQueries) ListExpiredHolds(ctx context.Context) ([]queries.Reservation, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]queries.Reservation), args.Error(1)
+}
+
+func (m *MockTransactionQueries) RecallTransaction(ctx context.Context, arg queries.RecallTransactionParams) (queries.Transaction, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionQueries) CountActiveReservationsByBook(ctx context.Context, bookID int32) (int64, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTransactionQueries) CountOpenRecallsByBook(ctx context.Context, bookID int32) (int64, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTransactionQueries) GetRecallableLoanByBook(ctx context.Context, bookID int32) (int32, error) {
+	args := m.Called(ctx, bookID)
+	return args.Get(0).(int32), args.Error(1)
+}
+
+func (m *MockTransactionQueries) CloseTransactionAsMissing(ctx context.Context, arg queries.CloseTransactionAsMissingParams) (queries.Transaction, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionQueries) SetTransactionLossStatus(ctx context.Context, arg queries.SetTransactionLossStatusParams) (queries.Transaction, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionQueries) UpdateBookCopyTotals(ctx context.Context, arg queries.UpdateBookCopyTotalsParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockTransactionQueries) GetFineByIDForUpdate(ctx context.Context, id int32) (queries.Fine, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(queries.Fine), args.Error(1)
+}
+
+func (m *MockTransactionQueries) ListFinesByTransaction(ctx context.Context, transactionID pgtype.Int4) ([]queries.Fine, error) {
+	args := m.Called(ctx, transactionID)
+	return args.Get(0).([]queries.Fine), args.Error(1)
+}
+
+func (m *MockTransactionQueries) UpdateFineTotals(ctx context.Context, arg queries.UpdateFineTotalsParams) (queries.Fine, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.Fine), args.Error(1)
+}
+
+func (m *MockTransactionQueries) NextFineReceiptNumber(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTransactionQueries) CreateFineLedgerEntry(ctx context.Context, arg queries.CreateFineLedgerEntryParams) (queries.FineLedgerEntry, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(queries.FineLedgerEntry), args.Error(1)
+}
+
+func (m *MockTransactionQueries) SetTransactionFinePaid(ctx context.Context, arg queries.SetTransactionFinePaidParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockTransactionQueries) CreateAuditLog(ctx context.Context, arg queries.CreateAuditLogParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+// ExecTx runs fn directly against the mock; transactional behaviour is covered by integration tests
+func (m *MockTransactionQueries) ExecTx(ctx context.Context, fn func(TransactionQuerier) error) error {
+	return fn(m)
+}
+
+// testLibrarian is the librarian at the desk when loans are checked in
+var testLibrarian = AuditActor{UserID: 1, UserType: "librarian"}
+
 // Test helper functions
 func createTestTransaction() queries.Transaction {
 	now := time.Now()
@@ -143,6 +317,16 @@ func createTestBook() queries.Book {
 	}
 }
 
+func createTestBookCopy() queries.BookCopy {
+	return queries.BookCopy{
+		ID:        10,
+		BookID:    1,
+		Barcode:   "BK001-001",
+		Condition: pgtype.Text{String: "good", Valid: true},
+		Status:    pgtype.Text{String: "available", Valid: true},
+	}
+}
+
 func createTestStudent() queries.Student {
 	return queries.Student{
 		ID:          1,
@@ -180,11 +364,17 @@ func TestTransactionService_BorrowBook_Success(t *testing.T) {
 	student := createTestStudent()
 	transaction := createTestTransaction()
 
-	mockQueries.On("GetBookByID", ctx, bookID).Return(book, nil)
-	mockQueries.On("GetStudentByID", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, bookID).Return(book, nil)
+	mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, sql.ErrNoRows)
 	mockQueries.On("ListActiveTransactionsByStudent", ctx, studentID).Return([]queries.ListActiveTransactionsByStudentRow{}, nil)
+	mockQueries.On("GetAvailableBookCopyForUpdate", ctx, bookID).Return(createTestBookCopy(), nil)
 	mockQueries.On("CreateTransaction", ctx, mock.AnythingOfType("queries.CreateTransactionParams")).Return(transaction, nil)
-	mockQueries.On("UpdateBookAvailability", ctx, mock.AnythingOfType("queries.UpdateBookAvailabilityParams")).Return(nil)
+	mockQueries.On("UpdateBookCopyStatus", ctx, queries.UpdateBookCopyStatusParams{
+		ID:     10,
+		Status: pgtype.Text{String: "borrowed", Valid: true},
+	}).Return(nil)
+	mockQueries.On("SyncBookCopyCounts", ctx, bookID).Return(nil)
 
 	// Execute
 	result, err := service.BorrowBook(ctx, studentID, bookID, librarianID, "")
@@ -206,7 +396,7 @@ func TestTransactionService_BorrowBook_BookNotFound(t *testing.T) {
 	librarianID := int32(1)
 
 	// Setup mock to return book not found
-	mockQueries.On("GetBookByID", ctx, bookID).Return(queries.Book{}, sql.ErrNoRows)
+	mockQueries.On("GetBookByIDForUpdate", ctx, bookID).Return(queries.Book{}, sql.ErrNoRows)
 
 	// Execute
 	_, err := service.BorrowBook(ctx, studentID, bookID, librarianID, "")
@@ -229,8 +419,8 @@ func TestTransactionService_BorrowBook_StudentNotFound(t *testing.T) {
 	book := createTestBook()
 
 	// Setup mocks
-	mockQueries.On("GetBookByID", ctx, bookID).Return(book, nil)
-	mockQueries.On("GetStudentByID", ctx, studentID).Return(queries.Student{}, sql.ErrNoRows)
+	mockQueries.On("GetBookByIDForUpdate", ctx, bookID).Return(book, nil)
+	mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(queries.Student{}, sql.ErrNoRows)
 
 	// Execute
 	_, err := service.BorrowBook(ctx, studentID, bookID, librarianID, "")
@@ -256,8 +446,9 @@ func TestTransactionService_BorrowBook_BookNotAvailable(t *testing.T) {
 	student := createTestStudent()
 
 	// Setup mocks
-	mockQueries.On("GetBookByID", ctx, bookID).Return(book, nil)
-	mockQueries.On("GetStudentByID", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, bookID).Return(book, nil)
+	mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, sql.ErrNoRows)
 
 	// Execute
 	_, err := service.BorrowBook(ctx, studentID, bookID, librarianID, "")
@@ -292,8 +483,9 @@ func TestTransactionService_BorrowBook_MaxBooksReached(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockQueries.On("GetBookByID", ctx, bookID).Return(book, nil)
-	mockQueries.On("GetStudentByID", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, bookID).Return(book, nil)
+	mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, sql.ErrNoRows)
 	mockQueries.On("ListActiveTransactionsByStudent", ctx, studentID).Return(activeTransactions, nil)
 
 	// Execute
@@ -319,8 +511,9 @@ func TestTransactionService_BorrowBook_StudentInactive(t *testing.T) {
 	student.IsActive = pgtype.Bool{Bool: false, Valid: true}
 
 	// Setup mocks
-	mockQueries.On("GetBookByID", ctx, bookID).Return(book, nil)
-	mockQueries.On("GetStudentByID", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, bookID).Return(book, nil)
+	mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, sql.ErrNoRows)
 
 	// Execute
 	_, err := service.BorrowBook(ctx, studentID, bookID, librarianID, "")
@@ -355,13 +548,15 @@ func TestTransactionService_ReturnBook_Success(t *testing.T) {
 	book := createTestBook()
 
 	// Setup mocks
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 	mockQueries.On("ReturnBook", ctx, mock.AnythingOfType("queries.ReturnBookParams")).Return(returnedTransaction, nil)
-	mockQueries.On("GetBookByID", ctx, int32(1)).Return(book, nil)
+	mockQueries.On("CloseRenewedLoanTransactions", ctx, mock.AnythingOfType("int32")).Return(nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(book, nil)
 	mockQueries.On("UpdateBookAvailability", ctx, mock.AnythingOfType("queries.UpdateBookAvailabilityParams")).Return(nil)
 
 	// Execute
-	result, err := service.ReturnBook(ctx, transactionID)
+	result, err := service.ReturnBook(ctx, transactionID, testLibrarian)
 
 	// Assert
 	require.NoError(t, err)
@@ -378,10 +573,10 @@ func TestTransactionService_ReturnBook_TransactionNotFound(t *testing.T) {
 	transactionID := int32(999)
 
 	// Setup mock to return transaction not found
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(queries.GetTransactionByIDRow{}, sql.ErrNoRows)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow{}, sql.ErrNoRows)
 
 	// Execute
-	_, err := service.ReturnBook(ctx, transactionID)
+	_, err := service.ReturnBook(ctx, transactionID, testLibrarian)
 
 	// Assert
 	require.Error(t, err)
@@ -408,10 +603,10 @@ func TestTransactionService_ReturnBook_AlreadyReturned(t *testing.T) {
 	}
 
 	// Setup mock
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
 
 	// Execute
-	_, err := service.ReturnBook(ctx, transactionID)
+	_, err := service.ReturnBook(ctx, transactionID, testLibrarian)
 
 	// Assert
 	require.Error(t, err)
@@ -428,7 +623,8 @@ func TestTransactionService_CalculateFine_NoFine(t *testing.T) {
 	dueDate := time.Now().AddDate(0, 0, 1)
 	returnDate := time.Now()
 
-	fine := service.calculateFine(dueDate, returnDate)
+	fine, err := service.calculateFine(context.Background(), dueDate, returnDate, service.defaultPolicy(PolicyContext{}))
+	require.NoError(t, err)
 	assert.True(t, decimal.Zero.Equal(fine))
 }
 
@@ -441,7 +637,8 @@ func TestTransactionService_CalculateFine_WithFine(t *testing.T) {
 	dueDate := time.Now().AddDate(0, 0, -3)
 	returnDate := time.Now()
 
-	fine := service.calculateFine(dueDate, returnDate)
+	fine, err := service.calculateFine(context.Background(), dueDate, returnDate, service.defaultPolicy(PolicyContext{}))
+	require.NoError(t, err)
 	expected := decimal.NewFromFloat(1.50) // 3 days * $0.50 (exactly 3 calendar days)
 
 	assert.True(t, expected.Equal(fine))
@@ -474,7 +671,8 @@ func TestTransactionService_RenewBook_Success(t *testing.T) {
 	student := createTestStudent()
 
 	// Setup mocks for comprehensive renewal validation
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 	mockQueries.On("CountRenewalsByStudentAndBook", ctx, queries.CountRenewalsByStudentAndBookParams{
 		StudentID: studentID,
 		BookID:    bookID,
@@ -483,7 +681,7 @@ func TestTransactionService_RenewBook_Success(t *testing.T) {
 		BookID:    bookID,
 		StudentID: studentID,
 	}).Return(false, nil)
-	mockQueries.On("GetStudentByID", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(student, nil)
 	mockQueries.On("CreateTransaction", ctx, mock.AnythingOfType("queries.CreateTransactionParams")).Return(renewedTransaction, nil)
 
 	// Execute
@@ -495,6 +693,69 @@ func TestTransactionService_RenewBook_Success(t *testing.T) {
 	mockQueries.AssertExpectations(t)
 }
 
+func TestTransactionService_RenewOwnLoan(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	loan := queries.GetTransactionByIDRow{
+		ID:              1,
+		StudentID:       1,
+		BookID:          1,
+		TransactionType: "borrow",
+		DueDate:         pgtype.Timestamp{Time: now.AddDate(0, 0, 1), Valid: true},
+	}
+
+	t.Run("RenewsWithoutLibrarian", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		renewed := createTestTransaction()
+		renewed.TransactionType = "renew"
+		renewed.LibrarianID = pgtype.Int4{}
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(queries.GetTransactionByIDForUpdateRow(loan), nil)
+		mockQueries.On("GetCurrentLoanTransactionID", ctx, int32(1)).Return(int32(1), nil)
+		mockQueries.On("CountRenewalsByStudentAndBook", ctx, mock.AnythingOfType("queries.CountRenewalsByStudentAndBookParams")).Return(int64(0), nil)
+		mockQueries.On("HasActiveReservationsByOtherStudents", ctx, mock.AnythingOfType("queries.HasActiveReservationsByOtherStudentsParams")).Return(false, nil)
+		mockQueries.On("GetStudentByIDForUpdate", ctx, int32(1)).Return(createTestStudent(), nil)
+		mockQueries.On("CreateTransaction", ctx, mock.MatchedBy(func(arg queries.CreateTransactionParams) bool {
+			return arg.TransactionType == "renew" && !arg.LibrarianID.Valid
+		})).Return(renewed, nil)
+
+		result, err := service.RenewOwnLoan(ctx, 1, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, "renew", result.TransactionType)
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("OtherStudentsLoan", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(1)).Return(queries.GetTransactionByIDForUpdateRow(loan), nil)
+		mockQueries.On("GetCurrentLoanTransactionID", ctx, int32(1)).Return(int32(1), nil)
+
+		_, err := service.RenewOwnLoan(ctx, 1, 2)
+
+		require.Error(t, err)
+		assert.Equal(t, "transaction not found", err.Error())
+		mockQueries.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("CanRenewOtherStudentsLoan", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		mockQueries.On("GetTransactionByID", ctx, int32(1)).Return(loan, nil)
+
+		canRenew, reason, err := service.CanRenewOwnLoan(ctx, 1, 2)
+
+		require.NoError(t, err)
+		assert.False(t, canRenew)
+		assert.Equal(t, "Transaction not found", reason)
+	})
+}
+
 func TestTransactionService_GetOverdueTransactions_Success(t *testing.T) {
 	mockQueries := &MockTransactionQueries{}
 	service := NewTransactionService(mockQueries)
@@ -528,22 +789,72 @@ func TestTransactionService_GetOverdueTransactions_Success(t *testing.T) {
 	mockQueries.AssertExpectations(t)
 }
 
-func TestTransactionService_PayFine_Success(t *testing.T) {
-	mockQueries := &MockTransactionQueries{}
-	service := NewTransactionService(mockQueries)
-
+func TestTransactionService_PayFine(t *testing.T) {
 	ctx := context.Background()
 	transactionID := int32(1)
-
-	// Setup mock
-	mockQueries.On("PayTransactionFine", ctx, transactionID).Return(nil)
-
-	// Execute
-	err := service.PayFine(ctx, transactionID)
-
-	// Assert
-	require.NoError(t, err)
-	mockQueries.AssertExpectations(t)
+	loan := pgtype.Int4{Int32: transactionID, Valid: true}
+
+	t.Run("PaysOutstandingFinesThroughTheLedger", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		settled := queries.Fine{ID: 3, StudentID: 1, TransactionID: loan, FineType: "overdue", Amount: decimalToNumeric(decimal.NewFromInt(5)), AmountPaid: decimalToNumeric(decimal.NewFromInt(5)), Status: "paid"}
+		damage := queries.Fine{ID: 4, StudentID: 1, TransactionID: loan, FineType: "damage", Amount: decimalToNumeric(decimal.NewFromInt(30)), AmountPaid: decimalToNumeric(decimal.NewFromInt(10)), Status: "partially_paid"}
+		paid := damage
+		paid.AmountPaid = damage.Amount
+		paid.Status = "paid"
+
+		mockQueries.On("GetTransactionByID", ctx, transactionID).Return(queries.GetTransactionByIDRow{ID: transactionID, StudentID: 1}, nil)
+		mockQueries.On("ListFinesByTransaction", ctx, loan).Return([]queries.Fine{settled, damage}, nil).Once()
+		mockQueries.On("GetFineByIDForUpdate", ctx, int32(3)).Return(settled, nil)
+		mockQueries.On("GetFineByIDForUpdate", ctx, int32(4)).Return(damage, nil)
+		mockQueries.On("NextFineReceiptNumber", ctx).Return(int64(12), nil)
+		mockQueries.On("CreateFineLedgerEntry", ctx, mock.MatchedBy(func(arg queries.CreateFineLedgerEntryParams) bool {
+			return arg.FineID == 4 && arg.EntryType == "payment" && arg.PaymentMethod.String == "cash" &&
+				numericToDecimal(arg.Amount).Equal(decimal.NewFromInt(20)) && arg.RecordedBy.Int32 == testLibrarian.UserID
+		})).Return(queries.FineLedgerEntry{ID: 9, FineID: 4, EntryType: "payment"}, nil)
+		mockQueries.On("UpdateFineTotals", ctx, mock.MatchedBy(func(arg queries.UpdateFineTotalsParams) bool {
+			return arg.ID == 4 && arg.Status == "paid"
+		})).Return(paid, nil)
+		mockQueries.On("CreateAuditLog", ctx, mock.AnythingOfType("queries.CreateAuditLogParams")).Return(nil)
+		mockQueries.On("ListFinesByTransaction", ctx, loan).Return([]queries.Fine{settled, paid}, nil)
+		mockQueries.On("SetTransactionFinePaid", ctx, queries.SetTransactionFinePaidParams{ID: transactionID, FinePaid: pgtype.Bool{Bool: true, Valid: true}}).Return(nil)
+
+		results, err := service.PayFine(ctx, transactionID, models.PayTransactionFineRequest{}, testLibrarian)
+
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, int32(4), results[0].Fine.ID)
+		assert.Equal(t, models.FineStatusPaid, results[0].Fine.Status)
+		mockQueries.AssertNumberOfCalls(t, "CreateFineLedgerEntry", 1)
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("NoOutstandingFine", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		mockQueries.On("GetTransactionByID", ctx, transactionID).Return(queries.GetTransactionByIDRow{ID: transactionID, StudentID: 1}, nil)
+		mockQueries.On("ListFinesByTransaction", ctx, loan).Return([]queries.Fine{}, nil)
+
+		_, err := service.PayFine(ctx, transactionID, models.PayTransactionFineRequest{}, testLibrarian)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has no outstanding fine")
+		mockQueries.AssertNotCalled(t, "CreateFineLedgerEntry", mock.Anything, mock.Anything)
+	})
+
+	t.Run("TransactionNotFound", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		mockQueries.On("GetTransactionByID", ctx, int32(99)).Return(queries.GetTransactionByIDRow{}, sql.ErrNoRows)
+
+		_, err := service.PayFine(ctx, 99, models.PayTransactionFineRequest{}, testLibrarian)
+
+		require.Error(t, err)
+		assert.Equal(t, "transaction not found", err.Error())
+	})
 }
 
 func TestTransactionService_GetTransactionHistory_Success(t *testing.T) {
@@ -599,8 +910,9 @@ func TestTransactionService_BorrowBook_BookInactive(t *testing.T) {
 	student := createTestStudent()
 
 	// Setup mocks
-	mockQueries.On("GetBookByID", ctx, bookID).Return(book, nil)
-	mockQueries.On("GetStudentByID", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, bookID).Return(book, nil)
+	mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, sql.ErrNoRows)
 
 	// Execute
 	_, err := service.BorrowBook(ctx, studentID, bookID, librarianID, "")
@@ -633,8 +945,9 @@ func TestTransactionService_BorrowBook_StudentHasOverdueBooks(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockQueries.On("GetBookByID", ctx, bookID).Return(book, nil)
-	mockQueries.On("GetStudentByID", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, bookID).Return(book, nil)
+	mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, sql.ErrNoRows)
 	mockQueries.On("ListActiveTransactionsByStudent", ctx, studentID).Return([]queries.ListActiveTransactionsByStudentRow{overdueTransaction}, nil)
 
 	// Execute
@@ -692,10 +1005,8 @@ func TestTransactionService_CalculateDueDate_DifferentYears(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		student := createTestStudent()
-		student.YearOfStudy = tc.year
-
-		dueDate := service.calculateDueDate(student)
+		dueDate, err := service.calculateDueDate(context.Background(), service.defaultPolicy(PolicyContext{YearOfStudy: tc.year}))
+		require.NoError(t, err)
 		expectedDate := time.Now().AddDate(0, 0, tc.expected)
 
 		// Allow for slight time differences during test execution
@@ -761,6 +1072,40 @@ func TestTransactionService_HasOverdueBooks_WithOverdue(t *testing.T) {
 	mockQueries.AssertExpectations(t)
 }
 
+func TestTransactionService_HasOverdueBooks_RenewedLoan(t *testing.T) {
+	mockQueries := &MockTransactionQueries{}
+	service := NewTransactionService(mockQueries)
+
+	ctx := context.Background()
+	studentID := int32(1)
+
+	// The borrow stays open past its old due date alongside the renewal
+	activeTransactions := []queries.ListActiveTransactionsByStudentRow{
+		{
+			ID:              1,
+			StudentID:       studentID,
+			BookID:          1,
+			TransactionType: "borrow",
+			DueDate:         pgtype.Timestamp{Time: time.Now().AddDate(0, 0, -5), Valid: true},
+		},
+		{
+			ID:              2,
+			StudentID:       studentID,
+			BookID:          1,
+			TransactionType: "renew",
+			DueDate:         pgtype.Timestamp{Time: time.Now().AddDate(0, 0, 9), Valid: true},
+		},
+	}
+
+	mockQueries.On("ListActiveTransactionsByStudent", ctx, studentID).Return(activeTransactions, nil)
+
+	hasOverdue, err := service.hasOverdueBooks(ctx, studentID)
+
+	require.NoError(t, err)
+	assert.False(t, hasOverdue)
+	mockQueries.AssertExpectations(t)
+}
+
 func TestTransactionService_WithBorrowingPeriod(t *testing.T) {
 	service := NewTransactionService(&MockTransactionQueries{})
 
@@ -790,6 +1135,77 @@ func TestTransactionService_WithMaxRenewals(t *testing.T) {
 	assert.Equal(t, 3, service.maxRenewals)
 }
 
+func TestTransactionService_BorrowBook_PolicyLoanLimit(t *testing.T) {
+	mockQueries := &MockTransactionQueries{}
+	mockPolicies := &MockPolicyResolver{}
+	service := NewTransactionService(mockQueries).WithPolicyResolver(mockPolicies)
+
+	ctx := context.Background()
+	studentID := int32(1)
+	bookID := int32(1)
+
+	book := createTestBook()
+	book.Genre = pgtype.Text{String: "Reference", Valid: true}
+	student := createTestStudent()
+	student.Department = pgtype.Text{String: "Science", Valid: true}
+
+	activeTransactions := []queries.ListActiveTransactionsByStudentRow{
+		{ID: 1, StudentID: studentID, BookID: 7, TransactionType: "borrow"},
+	}
+
+	mockQueries.On("GetBookByIDForUpdate", ctx, bookID).Return(book, nil)
+	mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, sql.ErrNoRows)
+	mockQueries.On("ListActiveTransactionsByStudent", ctx, studentID).Return(activeTransactions, nil)
+	mockPolicies.On("ResolvePolicy", ctx, PolicyContext{
+		YearOfStudy: 1,
+		Department:  "Science",
+		UserType:    models.PatronTypeStudent,
+		ItemType:    "Reference",
+	}).Return(&CirculationPolicy{ID: 2, LoanDays: 3, MaxLoans: 1}, nil)
+
+	_, err := service.BorrowBook(ctx, studentID, bookID, 1, "")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "maximum number of books (1)")
+	mockQueries.AssertExpectations(t)
+	mockPolicies.AssertExpectations(t)
+}
+
+func TestTransactionService_ResolvePolicy_FallsBackToDefaults(t *testing.T) {
+	mockPolicies := &MockPolicyResolver{}
+	service := NewTransactionService(&MockTransactionQueries{}).WithPolicyResolver(mockPolicies)
+
+	ctx := context.Background()
+	pc := PolicyContext{YearOfStudy: 3, UserType: models.PatronTypeStudent}
+	mockPolicies.On("ResolvePolicy", ctx, pc).Return(nil, nil)
+
+	policy, err := service.resolvePolicy(ctx, pc)
+
+	require.NoError(t, err)
+	assert.Equal(t, 21, policy.LoanDays)
+	assert.Equal(t, 5, policy.MaxLoans)
+	assert.Equal(t, 2, policy.MaxRenewals)
+	assert.True(t, decimal.NewFromFloat(0.50).Equal(policy.FinePerDay))
+}
+
+func TestTransactionService_CalculateFine_GracePeriod(t *testing.T) {
+	service := NewTransactionService(&MockTransactionQueries{})
+	policy := &CirculationPolicy{FinePerDay: decimal.NewFromFloat(1.00), GracePeriodDays: 2}
+
+	returnDate := time.Now()
+
+	// Within the grace period no fine is charged
+	fine, err := service.calculateFine(context.Background(), returnDate.AddDate(0, 0, -2), returnDate, policy)
+	require.NoError(t, err)
+	assert.True(t, decimal.Zero.Equal(fine))
+
+	// Only the days beyond the grace period are charged
+	fine, err = service.calculateFine(context.Background(), returnDate.AddDate(0, 0, -5), returnDate, policy)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromFloat(3.00).Equal(fine))
+}
+
 // Phase 6.3: Enhanced Return Processing Tests
 
 func TestTransactionService_ReturnBook_WithOverdueFine(t *testing.T) {
@@ -824,13 +1240,22 @@ func TestTransactionService_ReturnBook_WithOverdueFine(t *testing.T) {
 	book := createTestBook()
 
 	// Setup mocks
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 	mockQueries.On("ReturnBook", ctx, mock.AnythingOfType("queries.ReturnBookParams")).Return(returnedTransaction, nil)
-	mockQueries.On("GetBookByID", ctx, int32(1)).Return(book, nil)
+	mockQueries.On("CloseRenewedLoanTransactions", ctx, mock.AnythingOfType("int32")).Return(nil)
+	mockQueries.On("CreateFine", ctx, mock.MatchedBy(func(arg queries.CreateFineParams) bool {
+		return arg.StudentID == 1 && arg.TransactionID.Int32 == transactionID && arg.FineType == "overdue" &&
+			numericToDecimal(arg.Amount).Equal(fineAmount) && arg.CreatedBy.Int32 == testLibrarian.UserID
+	})).Return(queries.Fine{ID: 1}, nil)
+	mockQueries.On("CreateAuditLog", ctx, mock.MatchedBy(func(arg queries.CreateAuditLogParams) bool {
+		return arg.TableName == "fines" && arg.Action == "CREATE" && arg.UserID.Int32 == testLibrarian.UserID
+	})).Return(nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(book, nil)
 	mockQueries.On("UpdateBookAvailability", ctx, mock.AnythingOfType("queries.UpdateBookAvailabilityParams")).Return(nil)
 
 	// Execute
-	result, err := service.ReturnBook(ctx, transactionID)
+	result, err := service.ReturnBook(ctx, transactionID, testLibrarian)
 
 	// Assert
 	require.NoError(t, err)
@@ -859,10 +1284,11 @@ func TestTransactionService_ReturnBook_ValidationFailure(t *testing.T) {
 	}
 
 	// Setup mock - only need to mock GetTransactionByID since validation will fail
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 
 	// Execute
-	_, err := service.ReturnBook(ctx, transactionID)
+	_, err := service.ReturnBook(ctx, transactionID, testLibrarian)
 
 	// Assert - should fail with validation error
 	require.Error(t, err)
@@ -893,13 +1319,15 @@ func TestTransactionService_ReturnBook_BookAvailabilityUpdateFailure(t *testing.
 	book := createTestBook()
 
 	// Setup mocks - book availability update will fail
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 	mockQueries.On("ReturnBook", ctx, mock.AnythingOfType("queries.ReturnBookParams")).Return(returnedTransaction, nil)
-	mockQueries.On("GetBookByID", ctx, int32(1)).Return(book, nil)
+	mockQueries.On("CloseRenewedLoanTransactions", ctx, mock.AnythingOfType("int32")).Return(nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(book, nil)
 	mockQueries.On("UpdateBookAvailability", ctx, mock.AnythingOfType("queries.UpdateBookAvailabilityParams")).Return(assert.AnError)
 
 	// Execute
-	_, err := service.ReturnBook(ctx, transactionID)
+	_, err := service.ReturnBook(ctx, transactionID, testLibrarian)
 
 	// Assert
 	require.Error(t, err)
@@ -929,12 +1357,14 @@ func TestTransactionService_ReturnBook_GetBookFailure(t *testing.T) {
 	returnedTransaction.ReturnedDate = pgtype.Timestamp{Time: now, Valid: true}
 
 	// Setup mocks - GetBookByID will fail
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 	mockQueries.On("ReturnBook", ctx, mock.AnythingOfType("queries.ReturnBookParams")).Return(returnedTransaction, nil)
-	mockQueries.On("GetBookByID", ctx, int32(1)).Return(queries.Book{}, assert.AnError)
+	mockQueries.On("CloseRenewedLoanTransactions", ctx, mock.AnythingOfType("int32")).Return(nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(queries.Book{}, assert.AnError)
 
 	// Execute
-	_, err := service.ReturnBook(ctx, transactionID)
+	_, err := service.ReturnBook(ctx, transactionID, testLibrarian)
 
 	// Assert
 	require.Error(t, err)
@@ -961,11 +1391,12 @@ func TestTransactionService_ReturnBook_ReturnOperationFailure(t *testing.T) {
 	}
 
 	// Setup mocks - ReturnBook operation will fail
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 	mockQueries.On("ReturnBook", ctx, mock.AnythingOfType("queries.ReturnBookParams")).Return(queries.Transaction{}, assert.AnError)
 
 	// Execute
-	_, err := service.ReturnBook(ctx, transactionID)
+	_, err := service.ReturnBook(ctx, transactionID, testLibrarian)
 
 	// Assert
 	require.Error(t, err)
@@ -1116,12 +1547,11 @@ func TestTransactionService_UpdateBookConditionIfNeeded_NoChange(t *testing.T) {
 	service := NewTransactionService(mockQueries)
 
 	ctx := context.Background()
-	bookID := int32(1)
-	book := createTestBook()
-	book.Condition = pgtype.Text{String: "good", Valid: true}
+	bookCopy := createTestBookCopy()
+	bookCopy.Condition = pgtype.Text{String: "good", Valid: true}
 
 	// Return condition is also good - no change needed
-	err := service.updateBookConditionIfNeeded(ctx, bookID, book, "good")
+	err := service.updateBookConditionIfNeeded(ctx, bookCopy, "good")
 	assert.NoError(t, err)
 	mockQueries.AssertExpectations(t)
 }
@@ -1131,18 +1561,17 @@ func TestTransactionService_UpdateBookConditionIfNeeded_ConditionDeteriorated(t
 	service := NewTransactionService(mockQueries)
 
 	ctx := context.Background()
-	bookID := int32(1)
-	book := createTestBook()
-	book.Condition = pgtype.Text{String: "good", Valid: true}
+	bookCopy := createTestBookCopy()
+	bookCopy.Condition = pgtype.Text{String: "good", Valid: true}
 
 	// Mock the condition update
-	mockQueries.On("UpdateBookCondition", ctx, queries.UpdateBookConditionParams{
-		ID:        bookID,
+	mockQueries.On("UpdateBookCopyCondition", ctx, queries.UpdateBookCopyConditionParams{
+		ID:        bookCopy.ID,
 		Condition: pgtype.Text{String: "fair", Valid: true},
 	}).Return(nil)
 
 	// Return condition is fair - should update
-	err := service.updateBookConditionIfNeeded(ctx, bookID, book, "fair")
+	err := service.updateBookConditionIfNeeded(ctx, bookCopy, "fair")
 	assert.NoError(t, err)
 	mockQueries.AssertExpectations(t)
 }
@@ -1152,12 +1581,11 @@ func TestTransactionService_UpdateBookConditionIfNeeded_ConditionImproved(t *tes
 	service := NewTransactionService(mockQueries)
 
 	ctx := context.Background()
-	bookID := int32(1)
-	book := createTestBook()
-	book.Condition = pgtype.Text{String: "fair", Valid: true}
+	bookCopy := createTestBookCopy()
+	bookCopy.Condition = pgtype.Text{String: "fair", Valid: true}
 
 	// Return condition is good - should not update (book condition doesn't improve)
-	err := service.updateBookConditionIfNeeded(ctx, bookID, book, "good")
+	err := service.updateBookConditionIfNeeded(ctx, bookCopy, "good")
 	assert.NoError(t, err)
 	mockQueries.AssertExpectations(t)
 }
@@ -1180,25 +1608,38 @@ func TestTransactionService_ReturnBookWithCondition_Success(t *testing.T) {
 		TransactionType: "borrow",
 		DueDate:         pgtype.Timestamp{Time: now.AddDate(0, 0, 1), Valid: true},
 		ReturnedDate:    pgtype.Timestamp{Valid: false},
+		CopyID:          pgtype.Int4{Int32: 10, Valid: true},
 	}
 
 	returnedTransaction := createTestTransaction()
 	returnedTransaction.ReturnedDate = pgtype.Timestamp{Time: now, Valid: true}
 	returnedTransaction.ReturnCondition = pgtype.Text{String: returnCondition, Valid: true}
 	returnedTransaction.ConditionNotes = pgtype.Text{String: conditionNotes, Valid: true}
+	returnedTransaction.CopyID = pgtype.Int4{Int32: 10, Valid: true}
 
 	book := createTestBook()
-	book.Condition = pgtype.Text{String: "good", Valid: true}
+	bookCopy := createTestBookCopy()
+	bookCopy.Status = pgtype.Text{String: "borrowed", Valid: true}
 
 	// Setup mocks
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 	mockQueries.On("ReturnBook", ctx, mock.AnythingOfType("queries.ReturnBookParams")).Return(returnedTransaction, nil)
-	mockQueries.On("GetBookByID", ctx, int32(1)).Return(book, nil)
-	mockQueries.On("UpdateBookAvailability", ctx, mock.AnythingOfType("queries.UpdateBookAvailabilityParams")).Return(nil)
-	mockQueries.On("UpdateBookCondition", ctx, mock.AnythingOfType("queries.UpdateBookConditionParams")).Return(nil)
+	mockQueries.On("CloseRenewedLoanTransactions", ctx, mock.AnythingOfType("int32")).Return(nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(book, nil)
+	mockQueries.On("GetBookCopyByIDForUpdate", ctx, int32(10)).Return(bookCopy, nil)
+	mockQueries.On("UpdateBookCopyStatus", ctx, queries.UpdateBookCopyStatusParams{
+		ID:     10,
+		Status: pgtype.Text{String: "available", Valid: true},
+	}).Return(nil)
+	mockQueries.On("UpdateBookCopyCondition", ctx, queries.UpdateBookCopyConditionParams{
+		ID:        10,
+		Condition: pgtype.Text{String: "fair", Valid: true},
+	}).Return(nil)
+	mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
 
 	// Execute
-	result, err := service.ReturnBookWithCondition(ctx, transactionID, returnCondition, conditionNotes)
+	result, err := service.ReturnBookWithCondition(ctx, transactionID, returnCondition, conditionNotes, testLibrarian)
 
 	// Assert
 	require.NoError(t, err)
@@ -1228,10 +1669,11 @@ func TestTransactionService_ReturnBookWithCondition_InvalidCondition(t *testing.
 	}
 
 	// Setup mock - only need to mock GetTransactionByID since validation will fail
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 
 	// Execute with invalid condition
-	_, err := service.ReturnBookWithCondition(ctx, transactionID, "invalid", "")
+	_, err := service.ReturnBookWithCondition(ctx, transactionID, "invalid", "", testLibrarian)
 
 	// Assert
 	require.Error(t, err)
@@ -1266,9 +1708,11 @@ func TestTransactionService_ReturnBook_AvailabilityUpdate_Success(t *testing.T)
 	book.AvailableCopies = pgtype.Int4{Int32: 2, Valid: true}
 
 	// Setup mocks
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 	mockQueries.On("ReturnBook", ctx, mock.AnythingOfType("queries.ReturnBookParams")).Return(returnedTransaction, nil)
-	mockQueries.On("GetBookByID", ctx, int32(1)).Return(book, nil)
+	mockQueries.On("CloseRenewedLoanTransactions", ctx, mock.AnythingOfType("int32")).Return(nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(book, nil)
 
 	// Verify that availability is increased by 1
 	expectedAvailability := int32(3)
@@ -1278,7 +1722,7 @@ func TestTransactionService_ReturnBook_AvailabilityUpdate_Success(t *testing.T)
 	}).Return(nil)
 
 	// Execute
-	result, err := service.ReturnBook(ctx, transactionID)
+	result, err := service.ReturnBook(ctx, transactionID, testLibrarian)
 
 	// Assert
 	require.NoError(t, err)
@@ -1302,17 +1746,20 @@ func TestTransactionService_BorrowBook_AvailabilityUpdate_Success(t *testing.T)
 	student := createTestStudent()
 	transaction := createTestTransaction()
 
-	mockQueries.On("GetBookByID", ctx, bookID).Return(book, nil)
-	mockQueries.On("GetStudentByID", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, bookID).Return(book, nil)
+	mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(student, nil)
+	mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, sql.ErrNoRows)
 	mockQueries.On("ListActiveTransactionsByStudent", ctx, studentID).Return([]queries.ListActiveTransactionsByStudentRow{}, nil)
-	mockQueries.On("CreateTransaction", ctx, mock.AnythingOfType("queries.CreateTransactionParams")).Return(transaction, nil)
+	mockQueries.On("GetAvailableBookCopyForUpdate", ctx, bookID).Return(createTestBookCopy(), nil)
 
-	// Verify that availability is decreased by 1
-	expectedAvailability := int32(2)
-	mockQueries.On("UpdateBookAvailability", ctx, queries.UpdateBookAvailabilityParams{
-		ID:              bookID,
-		AvailableCopies: pgtype.Int4{Int32: expectedAvailability, Valid: true},
-	}).Return(nil)
+	// The loan must reference the issued copy
+	mockQueries.On("CreateTransaction", ctx, mock.MatchedBy(func(arg queries.CreateTransactionParams) bool {
+		return arg.CopyID == pgtype.Int4{Int32: 10, Valid: true}
+	})).Return(transaction, nil)
+	mockQueries.On("UpdateBookCopyStatus", ctx, mock.AnythingOfType("queries.UpdateBookCopyStatusParams")).Return(nil)
+
+	// Verify that availability is recomputed from the copies
+	mockQueries.On("SyncBookCopyCounts", ctx, bookID).Return(nil)
 
 	// Execute
 	result, err := service.BorrowBook(ctx, studentID, bookID, librarianID, "")
@@ -1324,6 +1771,207 @@ func TestTransactionService_BorrowBook_AvailabilityUpdate_Success(t *testing.T)
 	mockQueries.AssertExpectations(t)
 }
 
+func TestTransactionService_BorrowBookByBarcode(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("IssuesScannedCopy", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		bookCopy := createTestBookCopy()
+		bookCopy.ID = 11
+		bookCopy.Barcode = "BK001-002"
+
+		mockQueries.On("GetBookCopyByBarcode", ctx, "BK001-002").Return(bookCopy, nil)
+		mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(createTestBook(), nil)
+		mockQueries.On("GetStudentByIDForUpdate", ctx, int32(1)).Return(createTestStudent(), nil)
+		mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, sql.ErrNoRows)
+		mockQueries.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{}, nil)
+		mockQueries.On("GetBookCopyByBarcodeForUpdate", ctx, "BK001-002").Return(bookCopy, nil)
+		mockQueries.On("CreateTransaction", ctx, mock.MatchedBy(func(arg queries.CreateTransactionParams) bool {
+			return arg.CopyID.Int32 == 11 && arg.BookID == 1
+		})).Return(createTestTransaction(), nil)
+		mockQueries.On("UpdateBookCopyStatus", ctx, queries.UpdateBookCopyStatusParams{
+			ID:     11,
+			Status: pgtype.Text{String: "borrowed", Valid: true},
+		}).Return(nil)
+		mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+
+		result, err := service.BorrowBookByBarcode(ctx, 1, "BK001-002", 1, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "BK001-002", result.Barcode)
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("CopyNotAvailable", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		bookCopy := createTestBookCopy()
+		bookCopy.Status = pgtype.Text{String: "maintenance", Valid: true}
+
+		mockQueries.On("GetBookCopyByBarcode", ctx, "BK001-001").Return(bookCopy, nil)
+		mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(createTestBook(), nil)
+		mockQueries.On("GetStudentByIDForUpdate", ctx, int32(1)).Return(createTestStudent(), nil)
+		mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, sql.ErrNoRows)
+		mockQueries.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{}, nil)
+		mockQueries.On("GetBookCopyByBarcodeForUpdate", ctx, "BK001-001").Return(bookCopy, nil)
+
+		_, err := service.BorrowBookByBarcode(ctx, 1, "BK001-001", 1, "")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not available")
+		mockQueries.AssertNotCalled(t, "CreateTransaction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("UnknownBarcode", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		mockQueries.On("GetBookCopyByBarcode", ctx, "NOPE").Return(queries.BookCopy{}, sql.ErrNoRows)
+
+		_, err := service.BorrowBookByBarcode(ctx, 1, "NOPE", 1, "")
+
+		require.Error(t, err)
+		assert.Equal(t, "book copy not found", err.Error())
+	})
+}
+
+func TestTransactionService_BorrowBook_NoAvailableCopy(t *testing.T) {
+	mockQueries := &MockTransactionQueries{}
+	service := NewTransactionService(mockQueries)
+
+	ctx := context.Background()
+
+	// The title counter says a copy is free but every copy is on loan or shelved for repair
+	mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(createTestBook(), nil)
+	mockQueries.On("GetStudentByIDForUpdate", ctx, int32(1)).Return(createTestStudent(), nil)
+	mockQueries.On("GetReadyReservationForStudent", ctx, mock.Anything).Return(queries.Reservation{}, sql.ErrNoRows)
+	mockQueries.On("ListActiveTransactionsByStudent", ctx, int32(1)).Return([]queries.ListActiveTransactionsByStudentRow{}, nil)
+	mockQueries.On("GetAvailableBookCopyForUpdate", ctx, int32(1)).Return(queries.BookCopy{}, sql.ErrNoRows)
+
+	_, err := service.BorrowBook(ctx, 1, 1, 1, "")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "book not available")
+	mockQueries.AssertExpectations(t)
+}
+
+func TestTransactionService_ReturnBookByBarcode(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ReturnsActiveLoan", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		now := time.Now()
+		bookCopy := createTestBookCopy()
+		bookCopy.Status = pgtype.Text{String: "borrowed", Valid: true}
+
+		activeLoan := createTestTransaction()
+		activeLoan.ID = 7
+		activeLoan.CopyID = pgtype.Int4{Int32: bookCopy.ID, Valid: true}
+
+		lockedRow := queries.GetTransactionByIDForUpdateRow{
+			ID:              7,
+			StudentID:       1,
+			BookID:          1,
+			TransactionType: "borrow",
+			DueDate:         pgtype.Timestamp{Time: now.AddDate(0, 0, 3), Valid: true},
+			CopyID:          pgtype.Int4{Int32: bookCopy.ID, Valid: true},
+		}
+
+		returned := activeLoan
+		returned.ReturnedDate = pgtype.Timestamp{Time: now, Valid: true}
+
+		mockQueries.On("GetBookCopyByBarcode", ctx, "BK001-001").Return(bookCopy, nil)
+		mockQueries.On("GetActiveTransactionByCopyID", ctx, pgtype.Int4{Int32: bookCopy.ID, Valid: true}).Return(activeLoan, nil)
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(7)).Return(lockedRow, nil)
+		mockQueries.On("GetCurrentLoanTransactionID", ctx, int32(7)).Return(int32(7), nil)
+		mockQueries.On("ReturnBook", ctx, mock.AnythingOfType("queries.ReturnBookParams")).Return(returned, nil)
+		mockQueries.On("CloseRenewedLoanTransactions", ctx, mock.AnythingOfType("int32")).Return(nil)
+		mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(createTestBook(), nil)
+		mockQueries.On("GetBookCopyByIDForUpdate", ctx, bookCopy.ID).Return(bookCopy, nil)
+		mockQueries.On("UpdateBookCopyStatus", ctx, queries.UpdateBookCopyStatusParams{
+			ID:     bookCopy.ID,
+			Status: pgtype.Text{String: "available", Valid: true},
+		}).Return(nil)
+		mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+
+		result, err := service.ReturnBookByBarcode(ctx, "BK001-001", "good", "", testLibrarian)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(7), result.ID)
+		assert.Equal(t, "BK001-001", result.Barcode)
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("RenewedLoanReturnsOnRenewal", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		now := time.Now()
+		bookCopy := createTestBookCopy()
+		bookCopy.Status = pgtype.Text{String: "borrowed", Valid: true}
+
+		// The borrow fell due before it was renewed; the renewal is not yet due
+		borrowRow := queries.GetTransactionByIDForUpdateRow{
+			ID:              7,
+			StudentID:       1,
+			BookID:          1,
+			TransactionType: "borrow",
+			DueDate:         pgtype.Timestamp{Time: now.AddDate(0, 0, -5), Valid: true},
+			CopyID:          pgtype.Int4{Int32: bookCopy.ID, Valid: true},
+		}
+		renewalRow := borrowRow
+		renewalRow.ID = 9
+		renewalRow.TransactionType = "renew"
+		renewalRow.DueDate = pgtype.Timestamp{Time: now.AddDate(0, 0, 5), Valid: true}
+
+		returned := createTestTransaction()
+		returned.ID = 9
+		returned.ReturnedDate = pgtype.Timestamp{Time: now, Valid: true}
+
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(7)).Return(borrowRow, nil)
+		mockQueries.On("GetCurrentLoanTransactionID", ctx, int32(7)).Return(int32(9), nil)
+		mockQueries.On("GetTransactionByIDForUpdate", ctx, int32(9)).Return(renewalRow, nil)
+		mockQueries.On("ReturnBook", ctx, mock.MatchedBy(func(arg queries.ReturnBookParams) bool {
+			return arg.ID == 9 && !arg.FineAmount.Valid
+		})).Return(returned, nil)
+		mockQueries.On("CloseRenewedLoanTransactions", ctx, int32(9)).Return(nil)
+		mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(createTestBook(), nil)
+		mockQueries.On("GetBookCopyByIDForUpdate", ctx, bookCopy.ID).Return(bookCopy, nil)
+		mockQueries.On("UpdateBookCopyStatus", ctx, queries.UpdateBookCopyStatusParams{
+			ID:     bookCopy.ID,
+			Status: pgtype.Text{String: "available", Valid: true},
+		}).Return(nil)
+		mockQueries.On("SyncBookCopyCounts", ctx, int32(1)).Return(nil)
+
+		result, err := service.ReturnBook(ctx, 7, testLibrarian)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(9), result.ID)
+		assert.Empty(t, result.Charges)
+		mockQueries.AssertNotCalled(t, "CreateFine", mock.Anything, mock.Anything)
+		mockQueries.AssertExpectations(t)
+	})
+
+	t.Run("NoActiveLoan", func(t *testing.T) {
+		mockQueries := &MockTransactionQueries{}
+		service := NewTransactionService(mockQueries)
+
+		bookCopy := createTestBookCopy()
+		mockQueries.On("GetBookCopyByBarcode", ctx, "BK001-001").Return(bookCopy, nil)
+		mockQueries.On("GetActiveTransactionByCopyID", ctx, pgtype.Int4{Int32: bookCopy.ID, Valid: true}).Return(queries.Transaction{}, sql.ErrNoRows)
+
+		_, err := service.ReturnBookByBarcode(ctx, "BK001-001", "good", "", testLibrarian)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no active loan found for copy BK001-001")
+	})
+}
+
 func TestTransactionService_ReturnBook_AvailabilityUpdate_BoundaryConditions(t *testing.T) {
 	mockQueries := &MockTransactionQueries{}
 	service := NewTransactionService(mockQueries)
@@ -1350,9 +1998,11 @@ func TestTransactionService_ReturnBook_AvailabilityUpdate_BoundaryConditions(t *
 	book.AvailableCopies = pgtype.Int4{Int32: 0, Valid: true}
 
 	// Setup mocks
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 	mockQueries.On("ReturnBook", ctx, mock.AnythingOfType("queries.ReturnBookParams")).Return(returnedTransaction, nil)
-	mockQueries.On("GetBookByID", ctx, int32(1)).Return(book, nil)
+	mockQueries.On("CloseRenewedLoanTransactions", ctx, mock.AnythingOfType("int32")).Return(nil)
+	mockQueries.On("GetBookByIDForUpdate", ctx, int32(1)).Return(book, nil)
 
 	// Verify that availability is increased to 1 from 0
 	expectedAvailability := int32(1)
@@ -1362,7 +2012,7 @@ func TestTransactionService_ReturnBook_AvailabilityUpdate_BoundaryConditions(t *
 	}).Return(nil)
 
 	// Execute
-	result, err := service.ReturnBook(ctx, transactionID)
+	result, err := service.ReturnBook(ctx, transactionID, testLibrarian)
 
 	// Assert
 	require.NoError(t, err)
@@ -1381,7 +2031,7 @@ func TestTransactionService_RenewBook_TransactionNotFound(t *testing.T) {
 	transactionID := int32(999)
 
 	// Setup mock to return transaction not found
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(queries.GetTransactionByIDRow{}, sql.ErrNoRows)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow{}, sql.ErrNoRows)
 
 	// Execute
 	_, err := service.RenewBook(ctx, transactionID, int32(1))
@@ -1411,7 +2061,7 @@ func TestTransactionService_RenewBook_AlreadyReturned(t *testing.T) {
 	}
 
 	// Setup mock
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
 
 	// Execute
 	_, err := service.RenewBook(ctx, transactionID, int32(1))
@@ -1441,7 +2091,8 @@ func TestTransactionService_RenewBook_OverdueBook(t *testing.T) {
 	}
 
 	// Setup mock
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 
 	// Execute
 	_, err := service.RenewBook(ctx, transactionID, int32(1))
@@ -1473,7 +2124,8 @@ func TestTransactionService_RenewBook_MaxRenewalsReached(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 	mockQueries.On("CountRenewalsByStudentAndBook", ctx, queries.CountRenewalsByStudentAndBookParams{
 		StudentID: studentID,
 		BookID:    bookID,
@@ -1509,7 +2161,8 @@ func TestTransactionService_RenewBook_BookReservedByOther(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 	mockQueries.On("CountRenewalsByStudentAndBook", ctx, mock.AnythingOfType("queries.CountRenewalsByStudentAndBookParams")).Return(int64(0), nil)
 	mockQueries.On("HasActiveReservationsByOtherStudents", ctx, queries.HasActiveReservationsByOtherStudentsParams{
 		BookID:    bookID,
@@ -1546,10 +2199,11 @@ func TestTransactionService_RenewBook_CreateTransactionFailure(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockQueries.On("GetTransactionByID", ctx, transactionID).Return(transaction, nil)
+	mockQueries.On("GetTransactionByIDForUpdate", ctx, transactionID).Return(queries.GetTransactionByIDForUpdateRow(transaction), nil)
+	mockQueries.On("GetCurrentLoanTransactionID", ctx, transactionID).Return(transactionID, nil)
 	mockQueries.On("CountRenewalsByStudentAndBook", ctx, mock.AnythingOfType("queries.CountRenewalsByStudentAndBookParams")).Return(int64(0), nil)
 	mockQueries.On("HasActiveReservationsByOtherStudents", ctx, mock.AnythingOfType("queries.HasActiveReservationsByOtherStudentsParams")).Return(false, nil)
-	mockQueries.On("GetStudentByID", ctx, studentID).Return(createTestStudent(), nil)
+	mockQueries.On("GetStudentByIDForUpdate", ctx, studentID).Return(createTestStudent(), nil)
 	mockQueries.On("CreateTransaction", ctx, mock.AnythingOfType("queries.CreateTransactionParams")).Return(queries.Transaction{}, assert.AnError)
 
 	// Execute
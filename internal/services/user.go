@@ -19,6 +19,8 @@ type UserServiceInterface interface {
 	UpdateLastLogin(userID int) error
 	UpdatePassword(userID int, hashedPassword string) error
 	UpdateStudentPassword(studentID int, hashedPassword string) error
+	UpdateLocale(userID int, locale string) error
+	UpdateStudentLocale(studentID int, locale string) error
 }
 
 type UserService struct {
@@ -36,7 +38,7 @@ func NewUserService(db *pgxpool.Pool, logger *slog.Logger) *UserService {
 func (s *UserService) GetUserByUsername(username string) (*models.User, error) {
 	ctx := context.Background()
 	query := `
-		SELECT id, username, email, password_hash, role, is_active, last_login, created_at, updated_at
+		SELECT id, username, email, password_hash, role, locale, is_active, last_login, created_at, updated_at
 		FROM users 
 		WHERE username = $1 AND is_active = true
 	`
@@ -50,6 +52,7 @@ func (s *UserService) GetUserByUsername(username string) (*models.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		&user.Locale,
 		&user.IsActive,
 		&lastLogin,
 		&user.CreatedAt,
@@ -71,7 +74,7 @@ func (s *UserService) GetUserByUsername(username string) (*models.User, error) {
 func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
 	ctx := context.Background()
 	query := `
-		SELECT id, username, email, password_hash, role, is_active, last_login, created_at, updated_at
+		SELECT id, username, email, password_hash, role, locale, is_active, last_login, created_at, updated_at
 		FROM users 
 		WHERE email = $1 AND is_active = true
 	`
@@ -85,6 +88,7 @@ func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		&user.Locale,
 		&user.IsActive,
 		&lastLogin,
 		&user.CreatedAt,
@@ -106,7 +110,7 @@ func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
 func (s *UserService) GetUserByID(id int) (*models.User, error) {
 	ctx := context.Background()
 	query := `
-		SELECT id, username, email, password_hash, role, is_active, last_login, created_at, updated_at
+		SELECT id, username, email, password_hash, role, locale, is_active, last_login, created_at, updated_at
 		FROM users 
 		WHERE id = $1
 	`
@@ -120,6 +124,7 @@ func (s *UserService) GetUserByID(id int) (*models.User, error) {
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		&user.Locale,
 		&user.IsActive,
 		&lastLogin,
 		&user.CreatedAt,
@@ -142,7 +147,7 @@ func (s *UserService) GetStudentByStudentID(studentID string) (*models.Student,
 	ctx := context.Background()
 	query := `
 		SELECT id, student_id, first_name, last_name, email, phone, year_of_study, 
-		       department, enrollment_date, password_hash, is_active, deleted_at, 
+		       department, enrollment_date, password_hash, locale, is_active, deleted_at, 
 		       created_at, updated_at
 		FROM students 
 		WHERE student_id = $1 AND is_active = true AND deleted_at IS NULL
@@ -163,6 +168,7 @@ func (s *UserService) GetStudentByStudentID(studentID string) (*models.Student,
 		&department,
 		&student.EnrollmentDate,
 		&passwordHash,
+		&student.Locale,
 		&student.IsActive,
 		&deletedAt,
 		&student.CreatedAt,
@@ -198,7 +204,7 @@ func (s *UserService) GetStudentByID(id int) (*models.Student, error) {
 	ctx := context.Background()
 	query := `
 		SELECT id, student_id, first_name, last_name, email, phone, year_of_study, 
-		       department, enrollment_date, password_hash, is_active, deleted_at, 
+		       department, enrollment_date, password_hash, locale, is_active, deleted_at, 
 		       created_at, updated_at
 		FROM students 
 		WHERE id = $1 AND deleted_at IS NULL
@@ -219,6 +225,7 @@ func (s *UserService) GetStudentByID(id int) (*models.Student, error) {
 		&department,
 		&student.EnrollmentDate,
 		&passwordHash,
+		&student.Locale,
 		&student.IsActive,
 		&deletedAt,
 		&student.CreatedAt,
@@ -301,12 +308,48 @@ func (s *UserService) UpdateStudentPassword(studentID int, hashedPassword string
 	return nil
 }
 
+// UpdateLocale sets the language a user's notifications are written in
+func (s *UserService) UpdateLocale(userID int, locale string) error {
+	ctx := context.Background()
+	query := `
+		UPDATE users 
+		SET locale = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := s.db.Exec(ctx, query, userID, locale)
+	if err != nil {
+		s.logger.Error("Error updating user locale", "error", err, "user_id", userID)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateStudentLocale sets the language a student's notifications are written in
+func (s *UserService) UpdateStudentLocale(studentID int, locale string) error {
+	ctx := context.Background()
+	query := `
+		UPDATE students 
+		SET locale = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := s.db.Exec(ctx, query, studentID, locale)
+	if err != nil {
+		s.logger.Error("Error updating student locale", "error", err, "student_id", studentID)
+		return err
+	}
+
+	return nil
+}
+
 func (s *UserService) CreateUser(user *models.User, hashedPassword string) error {
 	ctx := context.Background()
 	query := `
 		INSERT INTO users (username, email, password_hash, role, is_active, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
-		RETURNING id, created_at, updated_at
+		RETURNING id, locale, created_at, updated_at
 	`
 
 	err := s.db.QueryRow(ctx, query,
@@ -315,7 +358,7 @@ func (s *UserService) CreateUser(user *models.User, hashedPassword string) error
 		hashedPassword,
 		user.Role,
 		user.IsActive,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.Locale, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		s.logger.Error("Error creating user", "error", err, "username", user.Username)
@@ -331,7 +374,7 @@ func (s *UserService) CreateStudent(student *models.Student) error {
 		INSERT INTO students (student_id, first_name, last_name, email, phone, year_of_study, 
 		                     department, enrollment_date, password_hash, is_active, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
-		RETURNING id, created_at, updated_at
+		RETURNING id, locale, created_at, updated_at
 	`
 
 	err := s.db.QueryRow(ctx, query,
@@ -345,7 +388,7 @@ func (s *UserService) CreateStudent(student *models.Student) error {
 		student.EnrollmentDate,
 		student.PasswordHash,
 		student.IsActive,
-	).Scan(&student.ID, &student.CreatedAt, &student.UpdatedAt)
+	).Scan(&student.ID, &student.Locale, &student.CreatedAt, &student.UpdatedAt)
 
 	if err != nil {
 		s.logger.Error("Error creating student", "error", err, "student_id", student.StudentID)
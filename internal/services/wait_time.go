@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+)
+
+// returnHistoryDays is how far back returned loans are looked at when estimating waits
+const returnHistoryDays = 365
+
+// minReturnHistory is the fewest returns of a book needed to estimate from its own
+// history; below it the whole library's returns are used instead
+const minReturnHistory = 10
+
+// Used to estimate waits before the library has any return history
+const (
+	defaultEstimateLoanDays   = 14
+	defaultEstimateSpreadDays = 3
+)
+
+// WaitEstimate is when a reservation still in the queue is expected to be ready to
+// collect. Earliest and Latest lie one standard deviation of past return lateness
+// either side, widening with each loan the reservation waits behind.
+type WaitEstimate struct {
+	ExpectedAt time.Time `json:"expected_at"`
+	Earliest   time.Time `json:"earliest"`
+	Latest     time.Time `json:"latest"`
+}
+
+// returnHistory summarises how long loans run and how late they come back, in days,
+// and how many times a loan is renewed on average
+type returnHistory struct {
+	loanDays    float64
+	renewalRate float64
+	daysLate    float64
+	stddevLate  float64
+}
+
+// bookQueue is a book's reservation queue with the wait estimates worked out for it
+type bookQueue struct {
+	queue     []queries.ListReservationsByBookRow
+	estimates map[int32]WaitEstimate
+}
+
+// copySlot is a copy of a book being handed down the queue
+type copySlot struct {
+	freeAt time.Time
+	// returns is how many loans of the copy the next reader waits on
+	returns int
+}
+
+// estimateWaits predicts when each reservation in a book's queue will be ready,
+// keyed by reservation ID. Copies are handed down the queue as they are expected
+// back: a loan comes back on its due date plus the average lateness, and each
+// reader after that keeps the copy for the average loan period, stretched by the
+// average number of renewals, plus lateness. Renewals are counted for later
+// readers because the queue has often emptied by the time their loan is due,
+// leaving them free to renew. The queue is in ListReservationsByBook order.
+func (s *ReservationService) estimateWaits(ctx context.Context, bookID int32, queue []queries.ListReservationsByBookRow) (map[int32]WaitEstimate, error) {
+	if len(queue) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	history, err := s.returnHistory(ctx, bookID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := s.queries.GetBookByID(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book: %w", err)
+	}
+	dueDates, err := s.queries.ListOpenLoanDueDatesByBook(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans of book: %w", err)
+	}
+	heldSince, err := s.queries.ListHoldReadyTimesByBook(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get held copies of book: %w", err)
+	}
+
+	lateness := fractionalDays(history.daysLate)
+	loan := fractionalDays(history.loanDays*(1+history.renewalRate)) + lateness
+
+	var copies []copySlot
+	for i := int32(0); i < book.AvailableCopies.Int32; i++ {
+		copies = append(copies, copySlot{freeAt: now})
+	}
+	for _, due := range dueDates {
+		copies = append(copies, copySlot{freeAt: laterOf(now, due.Time.Add(lateness)), returns: 1})
+	}
+	// A copy on the hold shelf goes out on loan to the student it is held for first
+	for _, ready := range heldSince {
+		copies = append(copies, copySlot{freeAt: laterOf(now, ready.Time.Add(loan)), returns: 1})
+	}
+	if len(copies) == 0 {
+		// Every copy is lost or withdrawn, so there is nothing to wait for
+		return nil, nil
+	}
+
+	estimates := make(map[int32]WaitEstimate, len(queue))
+	waiting := append([]queries.ListReservationsByBookRow(nil), queue...)
+	for len(waiting) > 0 {
+		slot := &copies[0]
+		for i := range copies {
+			if copies[i].freeAt.Before(slot.freeAt) {
+				slot = &copies[i]
+			}
+		}
+
+		// The copy goes to the first reservation not suspended when it comes back,
+		// or waits for the earliest suspension to end
+		readyAt := slot.freeAt
+		taker := -1
+		for i, reservation := range waiting {
+			if !reservation.SuspendedUntil.Valid || !reservation.SuspendedUntil.Time.After(readyAt) {
+				taker = i
+				break
+			}
+		}
+		if taker < 0 {
+			taker = 0
+			for i, reservation := range waiting {
+				if reservation.SuspendedUntil.Time.Before(waiting[taker].SuspendedUntil.Time) {
+					taker = i
+				}
+			}
+			readyAt = waiting[taker].SuspendedUntil.Time
+		}
+
+		spread := fractionalDays(history.stddevLate * math.Sqrt(float64(slot.returns)))
+		estimates[waiting[taker].ID] = WaitEstimate{
+			ExpectedAt: readyAt,
+			Earliest:   laterOf(now, readyAt.Add(-spread)),
+			Latest:     readyAt.Add(spread),
+		}
+
+		slot.freeAt = readyAt.Add(loan)
+		slot.returns++
+		waiting = append(waiting[:taker], waiting[taker+1:]...)
+	}
+
+	return estimates, nil
+}
+
+// returnHistory summarises the last year of returns of a book, or of the whole
+// library when the book has too few to go on
+func (s *ReservationService) returnHistory(ctx context.Context, bookID int32, now time.Time) (returnHistory, error) {
+	since := pgtype.Timestamp{Time: now.AddDate(0, 0, -returnHistoryDays), Valid: true}
+
+	book, err := s.queries.GetBookReturnHistory(ctx, queries.GetBookReturnHistoryParams{
+		BookID:       bookID,
+		ReturnedDate: since,
+	})
+	if err != nil {
+		return returnHistory{}, fmt.Errorf("failed to get return history of book: %w", err)
+	}
+	if book.ReturnedLoans >= minReturnHistory {
+		return returnHistory{
+			loanDays:    book.AvgLoanDays,
+			renewalRate: float64(book.Renewals) / float64(book.ReturnedLoans),
+			daysLate:    book.AvgDaysLate,
+			stddevLate:  book.StddevDaysLate,
+		}, nil
+	}
+
+	library, err := s.queries.GetLibraryReturnHistory(ctx, since)
+	if err != nil {
+		return returnHistory{}, fmt.Errorf("failed to get return history: %w", err)
+	}
+	if library.ReturnedLoans >= minReturnHistory {
+		return returnHistory{
+			loanDays:    library.AvgLoanDays,
+			renewalRate: float64(library.Renewals) / float64(library.ReturnedLoans),
+			daysLate:    library.AvgDaysLate,
+			stddevLate:  library.StddevDaysLate,
+		}, nil
+	}
+
+	return returnHistory{loanDays: defaultEstimateLoanDays, stddevLate: defaultEstimateSpreadDays}, nil
+}
+
+// estimateQueuedWait fills in the queue position of a reservation still in the
+// queue and when it is expected to be ready. Queues are kept in cache by book so
+// several reservations of one book are estimated once.
+func (s *ReservationService) estimateQueuedWait(ctx context.Context, response *ReservationResponse, cache map[int32]*bookQueue) error {
+	book, ok := cache[response.BookID]
+	if !ok {
+		queue, err := s.queries.ListReservationsByBook(ctx, response.BookID)
+		if err != nil {
+			return fmt.Errorf("failed to get book reservations: %w", err)
+		}
+
+		estimates, err := s.estimateWaits(ctx, response.BookID, queue)
+		if err != nil {
+			return err
+		}
+		book = &bookQueue{queue: queue, estimates: estimates}
+		cache[response.BookID] = book
+	}
+
+	for i, queued := range book.queue {
+		if queued.ID == response.ID {
+			response.QueuePosition = i + 1
+		}
+	}
+	if estimate, ok := book.estimates[response.ID]; ok {
+		response.EstimatedWait = &estimate
+	}
+	return nil
+}
+
+// fractionalDays converts a fractional number of days to a duration
+func fractionalDays(d float64) time.Duration {
+	return time.Duration(d * float64(24*time.Hour))
+}
+
+// laterOf returns the later of two times
+func laterOf(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+)
+
+// expectWaitEstimate sets up a book with one copy on loan until due and a library
+// history of loans kept 14 days and returned a day late, give or take two days
+func expectWaitEstimate(m *MockReservationQuerier, ctx context.Context, bookID int32, due time.Time) {
+	m.On("GetBookReturnHistory", ctx, mock.MatchedBy(func(arg queries.GetBookReturnHistoryParams) bool {
+		return arg.BookID == bookID
+	})).Return(queries.GetBookReturnHistoryRow{ReturnedLoans: 3}, nil)
+	m.On("GetLibraryReturnHistory", ctx, mock.AnythingOfType("pgtype.Timestamp")).Return(queries.GetLibraryReturnHistoryRow{
+		ReturnedLoans:  200,
+		AvgLoanDays:    14,
+		AvgDaysLate:    1,
+		StddevDaysLate: 2,
+	}, nil)
+	m.On("GetBookByID", ctx, bookID).Return(queries.Book{ID: bookID, AvailableCopies: pgtype.Int4{Int32: 0, Valid: true}}, nil)
+	m.On("ListOpenLoanDueDatesByBook", ctx, bookID).Return([]pgtype.Timestamp{{Time: due, Valid: true}}, nil)
+	m.On("ListHoldReadyTimesByBook", ctx, bookID).Return([]pgtype.Timestamp{}, nil)
+}
+
+func TestEstimateWaits(t *testing.T) {
+	ctx := context.Background()
+	due := time.Now().AddDate(0, 0, 10).Truncate(time.Second)
+	day := 24 * time.Hour
+
+	t.Run("CopyPassesDownTheQueue", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier)
+		expectWaitEstimate(mockQuerier, ctx, 2, due)
+
+		estimates, err := service.estimateWaits(ctx, 2, []queries.ListReservationsByBookRow{{ID: 1}, {ID: 2}})
+
+		assert.NoError(t, err)
+		first, second := estimates[1], estimates[2]
+		assert.Equal(t, due.Add(day), first.ExpectedAt, "the loan comes back a day late")
+		assert.Equal(t, due.Add(-day), first.Earliest)
+		assert.Equal(t, due.Add(3*day), first.Latest)
+
+		assert.Equal(t, due.Add(16*day), second.ExpectedAt, "the first reader keeps it for a loan period and is a day late")
+		spread := fractionalDays(2 * math.Sqrt2)
+		assert.Equal(t, second.ExpectedAt.Add(-spread), second.Earliest)
+		assert.Equal(t, second.ExpectedAt.Add(spread), second.Latest)
+	})
+
+	t.Run("SuspendedReservationPassedOver", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier)
+		expectWaitEstimate(mockQuerier, ctx, 2, due)
+
+		queue := []queries.ListReservationsByBookRow{
+			{ID: 1, SuspendedUntil: pgtype.Timestamp{Time: due.AddDate(0, 0, 5), Valid: true}},
+			{ID: 2},
+		}
+		estimates, err := service.estimateWaits(ctx, 2, queue)
+
+		assert.NoError(t, err)
+		assert.Equal(t, due.Add(day), estimates[2].ExpectedAt)
+		assert.Equal(t, due.Add(16*day), estimates[1].ExpectedAt)
+	})
+
+	t.Run("RenewalsStretchLaterLoans", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier)
+
+		mockQuerier.On("GetBookReturnHistory", ctx, mock.Anything).Return(queries.GetBookReturnHistoryRow{
+			ReturnedLoans: 20,
+			Renewals:      10,
+			AvgLoanDays:   14,
+			AvgDaysLate:   1,
+		}, nil)
+		mockQuerier.On("GetBookByID", ctx, int32(2)).Return(queries.Book{ID: 2}, nil)
+		mockQuerier.On("ListOpenLoanDueDatesByBook", ctx, int32(2)).Return([]pgtype.Timestamp{{Time: due, Valid: true}}, nil)
+		mockQuerier.On("ListHoldReadyTimesByBook", ctx, int32(2)).Return([]pgtype.Timestamp{}, nil)
+
+		estimates, err := service.estimateWaits(ctx, 2, []queries.ListReservationsByBookRow{{ID: 1}, {ID: 2}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, due.Add(day), estimates[1].ExpectedAt, "the current loan still comes back on its due date")
+		assert.Equal(t, due.Add(23*day), estimates[2].ExpectedAt, "half of loans are renewed, so the first reader keeps it 21 days and is a day late")
+	})
+
+	t.Run("AvailableAndHeldCopies", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier)
+		heldAt := time.Now().Add(-day).Truncate(time.Second)
+
+		mockQuerier.On("GetBookReturnHistory", ctx, mock.Anything).Return(queries.GetBookReturnHistoryRow{
+			ReturnedLoans: 12,
+			AvgLoanDays:   7,
+			AvgDaysLate:   2,
+		}, nil)
+		mockQuerier.On("GetBookByID", ctx, int32(2)).Return(queries.Book{ID: 2, AvailableCopies: pgtype.Int4{Int32: 1, Valid: true}}, nil)
+		mockQuerier.On("ListOpenLoanDueDatesByBook", ctx, int32(2)).Return([]pgtype.Timestamp{}, nil)
+		mockQuerier.On("ListHoldReadyTimesByBook", ctx, int32(2)).Return([]pgtype.Timestamp{{Time: heldAt, Valid: true}}, nil)
+
+		estimates, err := service.estimateWaits(ctx, 2, []queries.ListReservationsByBookRow{{ID: 1}, {ID: 2}, {ID: 3}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, estimates[1].Earliest, estimates[1].Latest, "a copy on the shelf leaves nothing to guess")
+		assert.WithinDuration(t, time.Now(), estimates[1].ExpectedAt, time.Minute)
+		assert.Equal(t, heldAt.Add(9*day), estimates[2].ExpectedAt, "the book's own history is used")
+		assert.WithinDuration(t, time.Now().Add(9*day), estimates[3].ExpectedAt, time.Minute)
+		mockQuerier.AssertNotCalled(t, "GetLibraryReturnHistory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("NoHistory", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier)
+
+		mockQuerier.On("GetBookReturnHistory", ctx, mock.Anything).Return(queries.GetBookReturnHistoryRow{}, nil)
+		mockQuerier.On("GetLibraryReturnHistory", ctx, mock.Anything).Return(queries.GetLibraryReturnHistoryRow{}, nil)
+		mockQuerier.On("GetBookByID", ctx, int32(2)).Return(queries.Book{ID: 2}, nil)
+		mockQuerier.On("ListOpenLoanDueDatesByBook", ctx, int32(2)).Return([]pgtype.Timestamp{{Time: due, Valid: true}}, nil)
+		mockQuerier.On("ListHoldReadyTimesByBook", ctx, int32(2)).Return([]pgtype.Timestamp{}, nil)
+
+		estimates, err := service.estimateWaits(ctx, 2, []queries.ListReservationsByBookRow{{ID: 1}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, due, estimates[1].ExpectedAt)
+		assert.Equal(t, due.Add(defaultEstimateSpreadDays*day), estimates[1].Latest)
+	})
+
+	t.Run("NoCopies", func(t *testing.T) {
+		mockQuerier := &MockReservationQuerier{}
+		service := NewReservationService(mockQuerier)
+
+		mockQuerier.On("GetBookReturnHistory", ctx, mock.Anything).Return(queries.GetBookReturnHistoryRow{ReturnedLoans: 40}, nil)
+		mockQuerier.On("GetBookByID", ctx, int32(2)).Return(queries.Book{ID: 2}, nil)
+		mockQuerier.On("ListOpenLoanDueDatesByBook", ctx, int32(2)).Return([]pgtype.Timestamp{}, nil)
+		mockQuerier.On("ListHoldReadyTimesByBook", ctx, int32(2)).Return([]pgtype.Timestamp{}, nil)
+
+		estimates, err := service.estimateWaits(ctx, 2, []queries.ListReservationsByBookRow{{ID: 1}})
+
+		assert.NoError(t, err)
+		assert.Empty(t, estimates)
+	})
+}
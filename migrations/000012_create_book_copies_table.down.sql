@@ -0,0 +1,4 @@
+-- Remove item-level copy tracking
+DROP INDEX IF EXISTS idx_transactions_copy;
+ALTER TABLE transactions DROP COLUMN IF EXISTS copy_id;
+DROP TABLE IF EXISTS book_copies;
@@ -0,0 +1,68 @@
+-- Migration: Create book_copies table for item-level copy tracking
+-- Each physical copy of a title gets its own barcode, condition, location and status
+
+CREATE TABLE book_copies (
+    id SERIAL PRIMARY KEY,
+    book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    barcode VARCHAR(100) UNIQUE NOT NULL,
+    condition VARCHAR(20) DEFAULT 'good' CHECK (condition IN ('excellent', 'good', 'fair', 'poor', 'damaged')),
+    shelf_location VARCHAR(50),
+    status VARCHAR(20) DEFAULT 'available' CHECK (status IN ('available', 'borrowed', 'maintenance', 'lost', 'withdrawn')),
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Indexes for performance
+CREATE INDEX idx_book_copies_book_id ON book_copies(book_id);
+CREATE INDEX idx_book_copies_status ON book_copies(status);
+CREATE INDEX idx_book_copies_available ON book_copies(book_id) WHERE status = 'available';
+
+-- Link transactions to the copy that was handed out
+ALTER TABLE transactions ADD COLUMN copy_id INTEGER REFERENCES book_copies(id);
+CREATE INDEX idx_transactions_copy ON transactions(copy_id);
+
+-- Backfill one copy per counted copy of every existing title
+INSERT INTO book_copies (book_id, barcode, condition, shelf_location, status)
+SELECT b.id,
+       b.book_id || '-' || LPAD(n::text, 3, '0'),
+       COALESCE(b.condition, 'good'),
+       b.shelf_location,
+       CASE WHEN n <= COALESCE(b.available_copies, 0) THEN 'available' ELSE 'borrowed' END
+FROM books b
+CROSS JOIN LATERAL generate_series(1, COALESCE(b.total_copies, 0)) AS n;
+
+-- Attach open loans to the borrowed copies of their title
+WITH open_loans AS (
+    SELECT id, book_id, ROW_NUMBER() OVER (PARTITION BY book_id ORDER BY id) AS rn
+    FROM transactions
+    WHERE returned_date IS NULL AND transaction_type = 'borrow'
+),
+borrowed_copies AS (
+    SELECT id, book_id, ROW_NUMBER() OVER (PARTITION BY book_id ORDER BY id) AS rn
+    FROM book_copies
+    WHERE status = 'borrowed'
+)
+UPDATE transactions t
+SET copy_id = bc.id
+FROM open_loans ol
+JOIN borrowed_copies bc ON bc.book_id = ol.book_id AND bc.rn = ol.rn
+WHERE t.id = ol.id;
+
+-- Open renewals are on the copy of the borrow they renew, the latest borrow of
+-- the book by the same student before them
+UPDATE transactions r
+SET copy_id = b.copy_id
+FROM transactions b
+WHERE r.returned_date IS NULL AND r.transaction_type = 'renew'
+  AND b.id = (
+    SELECT MAX(p.id) FROM transactions p
+    WHERE p.student_id = r.student_id AND p.book_id = r.book_id
+      AND p.transaction_type = 'borrow' AND p.id < r.id
+  )
+  AND b.returned_date IS NULL;
+
+-- Comments for documentation
+COMMENT ON TABLE book_copies IS 'Physical copies of library titles tracked by barcode';
+COMMENT ON COLUMN book_copies.barcode IS 'Barcode printed on the physical copy';
+COMMENT ON COLUMN book_copies.status IS 'Copy status: available, borrowed, maintenance, lost, withdrawn';
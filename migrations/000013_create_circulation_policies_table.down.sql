@@ -0,0 +1,2 @@
+-- Drop circulation_policies table
+DROP TABLE IF EXISTS circulation_policies;
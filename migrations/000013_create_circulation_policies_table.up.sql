@@ -0,0 +1,42 @@
+-- Migration: Create circulation_policies table
+-- Loan rules are resolved per decision from the most specific active policy
+-- matching the patron (year of study, department, user type) and the item type.
+
+CREATE TABLE circulation_policies (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    year_of_study INTEGER CHECK (year_of_study >= 1 AND year_of_study <= 8),
+    department VARCHAR(100),
+    user_type VARCHAR(20) CHECK (user_type IN ('student', 'staff', 'librarian', 'admin')),
+    item_type VARCHAR(100),
+    loan_days INTEGER NOT NULL CHECK (loan_days > 0),
+    max_loans INTEGER NOT NULL CHECK (max_loans >= 0),
+    max_renewals INTEGER NOT NULL CHECK (max_renewals >= 0),
+    fine_per_day DECIMAL(10,2) NOT NULL CHECK (fine_per_day >= 0),
+    grace_period_days INTEGER NOT NULL DEFAULT 0 CHECK (grace_period_days >= 0),
+    max_reservations INTEGER NOT NULL CHECK (max_reservations >= 0),
+    reservation_days INTEGER NOT NULL CHECK (reservation_days > 0),
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Indexes for performance
+CREATE INDEX idx_circulation_policies_active ON circulation_policies(priority DESC) WHERE is_active = true;
+CREATE INDEX idx_circulation_policies_year ON circulation_policies(year_of_study);
+
+-- Seed the rules the service previously hard-coded
+INSERT INTO circulation_policies (name, description, year_of_study, loan_days, max_loans, max_renewals, fine_per_day, grace_period_days, max_reservations, reservation_days) VALUES
+    ('Default', 'Fallback rule for graduate and other patrons', NULL, 28, 5, 2, 0.50, 0, 5, 7),
+    ('Year 1 students', 'Junior student loan rule', 1, 14, 5, 2, 0.50, 0, 5, 7),
+    ('Year 2 students', 'Junior student loan rule', 2, 14, 5, 2, 0.50, 0, 5, 7),
+    ('Year 3 students', 'Senior student loan rule', 3, 21, 5, 2, 0.50, 0, 5, 7),
+    ('Year 4 students', 'Senior student loan rule', 4, 21, 5, 2, 0.50, 0, 5, 7);
+
+-- Add comments for documentation
+COMMENT ON TABLE circulation_policies IS 'Loan, renewal, fine and reservation rules by patron class and item type';
+COMMENT ON COLUMN circulation_policies.item_type IS 'Book genre the rule applies to; NULL matches any item';
+COMMENT ON COLUMN circulation_policies.grace_period_days IS 'Overdue days that are not fined';
+COMMENT ON COLUMN circulation_policies.priority IS 'Higher priority rules win before specificity is considered';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS library_closures;
+DROP TABLE IF EXISTS library_opening_hours;
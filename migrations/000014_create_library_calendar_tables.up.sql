@@ -0,0 +1,53 @@
+-- Migration: Create library calendar tables
+-- Opening hours per weekday plus dated closures (public holidays, term breaks).
+-- Due dates roll forward past closed days and fines are not charged for them.
+
+CREATE TABLE library_opening_hours (
+    day_of_week INTEGER PRIMARY KEY CHECK (day_of_week >= 0 AND day_of_week <= 6),
+    opens_at TIME,
+    closes_at TIME,
+    is_closed BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CHECK (is_closed = true OR (opens_at IS NOT NULL AND closes_at IS NOT NULL AND closes_at > opens_at))
+);
+
+CREATE TABLE library_closures (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    recurs_annually BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CHECK (end_date >= start_date OR recurs_annually = true)
+);
+
+-- Indexes for performance
+CREATE INDEX idx_library_closures_dates ON library_closures(start_date, end_date);
+CREATE INDEX idx_library_closures_recurring ON library_closures(recurs_annually) WHERE recurs_annually = true;
+
+-- Weekday service hours; closed on weekends
+INSERT INTO library_opening_hours (day_of_week, opens_at, closes_at, is_closed) VALUES
+    (0, NULL, NULL, true),
+    (1, '08:00', '20:00', false),
+    (2, '08:00', '20:00', false),
+    (3, '08:00', '20:00', false),
+    (4, '08:00', '20:00', false),
+    (5, '08:00', '20:00', false),
+    (6, NULL, NULL, true);
+
+-- Fixed-date public holidays
+INSERT INTO library_closures (name, start_date, end_date, recurs_annually) VALUES
+    ('New Year''s Day', '2000-01-01', '2000-01-01', true),
+    ('Labour Day', '2000-05-01', '2000-05-01', true),
+    ('Madaraka Day', '2000-06-01', '2000-06-01', true),
+    ('Mashujaa Day', '2000-10-20', '2000-10-20', true),
+    ('Jamhuri Day', '2000-12-12', '2000-12-12', true),
+    ('Christmas Day', '2000-12-25', '2000-12-25', true),
+    ('Boxing Day', '2000-12-26', '2000-12-26', true);
+
+-- Add comments for documentation
+COMMENT ON TABLE library_opening_hours IS 'Regular opening hours; day_of_week 0 is Sunday';
+COMMENT ON TABLE library_closures IS 'Holidays and breaks when the library is closed';
+COMMENT ON COLUMN library_closures.recurs_annually IS 'Closed on the same month and day every year; the year of the dates is ignored and the range may wrap over the new year';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS fine_ledger_entries;
+DROP TABLE IF EXISTS fines;
+DROP SEQUENCE IF EXISTS fine_receipt_seq;
@@ -0,0 +1,61 @@
+-- Migration: Create fines ledger
+-- Each fine records what a student owes; every payment, waiver, refund and
+-- adjustment against it is kept as an immutable ledger entry.
+
+CREATE SEQUENCE fine_receipt_seq;
+
+CREATE TABLE fines (
+    id SERIAL PRIMARY KEY,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    transaction_id INTEGER REFERENCES transactions(id) ON DELETE SET NULL,
+    fine_type VARCHAR(20) NOT NULL DEFAULT 'overdue' CHECK (fine_type IN ('overdue', 'damage', 'lost', 'other')),
+    description TEXT,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount >= 0),
+    amount_paid DECIMAL(10,2) NOT NULL DEFAULT 0.00 CHECK (amount_paid >= 0),
+    amount_waived DECIMAL(10,2) NOT NULL DEFAULT 0.00 CHECK (amount_waived >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'outstanding' CHECK (status IN ('outstanding', 'partially_paid', 'paid', 'waived')),
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CHECK (amount_paid + amount_waived <= amount)
+);
+
+CREATE TABLE fine_ledger_entries (
+    id SERIAL PRIMARY KEY,
+    fine_id INTEGER NOT NULL REFERENCES fines(id) ON DELETE CASCADE,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    entry_type VARCHAR(20) NOT NULL CHECK (entry_type IN ('payment', 'waiver', 'refund', 'adjustment')),
+    amount DECIMAL(10,2) NOT NULL,
+    payment_method VARCHAR(20) CHECK (payment_method IN ('cash', 'mpesa', 'card', 'bank_transfer')),
+    reference VARCHAR(100),
+    reason TEXT,
+    receipt_number VARCHAR(30) UNIQUE,
+    recorded_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK (entry_type = 'adjustment' OR amount > 0),
+    CHECK (entry_type NOT IN ('payment', 'refund') OR payment_method IS NOT NULL),
+    CHECK (entry_type = 'payment' OR reason IS NOT NULL)
+);
+
+-- Indexes for performance
+CREATE INDEX idx_fines_student ON fines(student_id);
+CREATE INDEX idx_fines_transaction ON fines(transaction_id);
+CREATE INDEX idx_fines_outstanding ON fines(student_id) WHERE status IN ('outstanding', 'partially_paid');
+CREATE INDEX idx_fine_ledger_entries_fine ON fine_ledger_entries(fine_id);
+CREATE INDEX idx_fine_ledger_entries_student ON fine_ledger_entries(student_id);
+CREATE INDEX idx_fine_ledger_entries_date ON fine_ledger_entries(created_at);
+
+-- Carry over fines already recorded on transactions
+INSERT INTO fines (student_id, transaction_id, fine_type, description, amount, amount_paid, status, created_at, updated_at)
+SELECT student_id, id, 'overdue', 'Overdue return', fine_amount,
+       CASE WHEN fine_paid THEN fine_amount ELSE 0.00 END,
+       CASE WHEN fine_paid THEN 'paid' ELSE 'outstanding' END,
+       COALESCE(returned_date, updated_at, NOW()), COALESCE(updated_at, NOW())
+FROM transactions
+WHERE fine_amount > 0;
+
+-- Add comments for documentation
+COMMENT ON TABLE fines IS 'Charges owed by students; balance is amount - amount_paid - amount_waived';
+COMMENT ON TABLE fine_ledger_entries IS 'Immutable record of payments, waivers, refunds and adjustments against fines';
+COMMENT ON COLUMN fine_ledger_entries.amount IS 'Positive for payments, waivers and refunds; signed change to the fine amount for adjustments';
+COMMENT ON COLUMN fine_ledger_entries.receipt_number IS 'Receipt issued for payments and refunds';
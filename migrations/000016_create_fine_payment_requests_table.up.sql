@@ -0,0 +1,31 @@
+-- Migration: Create fine payment requests
+-- Tracks mobile money requests sent to a student's phone so the provider's
+-- callback can be matched back and the student's fines settled.
+
+CREATE TABLE fine_payment_requests (
+    id SERIAL PRIMARY KEY,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL DEFAULT 'mpesa' CHECK (provider IN ('mpesa')),
+    phone VARCHAR(20) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    amount_applied DECIMAL(10,2) NOT NULL DEFAULT 0.00 CHECK (amount_applied >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+    merchant_request_id VARCHAR(100),
+    checkout_request_id VARCHAR(100) UNIQUE,
+    provider_receipt VARCHAR(50) UNIQUE,
+    result_code INTEGER,
+    result_desc TEXT,
+    requested_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    completed_at TIMESTAMP
+);
+
+-- Indexes for performance
+CREATE INDEX idx_fine_payment_requests_student ON fine_payment_requests(student_id);
+CREATE INDEX idx_fine_payment_requests_pending ON fine_payment_requests(created_at) WHERE status = 'pending';
+
+-- Add comments for documentation
+COMMENT ON TABLE fine_payment_requests IS 'Mobile money payment requests for student fines, settled by the provider callback';
+COMMENT ON COLUMN fine_payment_requests.amount_applied IS 'Part of the amount paid that was applied to fines; the provider charges whole shillings';
+COMMENT ON COLUMN fine_payment_requests.provider_receipt IS 'Provider transaction receipt, e.g. the M-Pesa receipt number';
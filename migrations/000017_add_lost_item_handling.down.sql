@@ -0,0 +1,8 @@
+ALTER TABLE circulation_policies DROP COLUMN IF EXISTS damage_step_threshold;
+ALTER TABLE circulation_policies DROP COLUMN IF EXISTS damage_charge_per_step;
+ALTER TABLE circulation_policies DROP COLUMN IF EXISTS processing_fee;
+ALTER TABLE circulation_policies DROP COLUMN IF EXISTS replacement_cost;
+
+DROP INDEX IF EXISTS idx_transactions_loss_status;
+ALTER TABLE transactions DROP COLUMN IF EXISTS loss_reported_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS loss_status;
@@ -0,0 +1,22 @@
+-- Migration: Lost and claimed-returned loans with replacement and damage charges
+-- A lost or claimed-returned loan is closed (returned_date is set) so it stops
+-- accruing overdue fines and no longer counts against the student's loan limit;
+-- loss_status records that the copy did not come back.
+
+ALTER TABLE transactions ADD COLUMN loss_status VARCHAR(20) CHECK (loss_status IN ('lost', 'claimed_returned', 'found'));
+ALTER TABLE transactions ADD COLUMN loss_reported_at TIMESTAMP;
+
+CREATE INDEX idx_transactions_loss_status ON transactions(loss_status) WHERE loss_status IS NOT NULL;
+
+-- Charges are part of the circulation policy so they can vary by patron and item type
+ALTER TABLE circulation_policies ADD COLUMN replacement_cost DECIMAL(10,2) NOT NULL DEFAULT 50.00 CHECK (replacement_cost >= 0);
+ALTER TABLE circulation_policies ADD COLUMN processing_fee DECIMAL(10,2) NOT NULL DEFAULT 10.00 CHECK (processing_fee >= 0);
+ALTER TABLE circulation_policies ADD COLUMN damage_charge_per_step DECIMAL(10,2) NOT NULL DEFAULT 5.00 CHECK (damage_charge_per_step >= 0);
+ALTER TABLE circulation_policies ADD COLUMN damage_step_threshold INTEGER NOT NULL DEFAULT 2 CHECK (damage_step_threshold >= 1);
+
+-- Add comments for documentation
+COMMENT ON COLUMN transactions.loss_status IS 'lost or claimed_returned while the copy is missing; found once it is recovered';
+COMMENT ON COLUMN circulation_policies.replacement_cost IS 'Charged when a borrowed copy is declared lost or claimed returned';
+COMMENT ON COLUMN circulation_policies.processing_fee IS 'Charged with the replacement cost';
+COMMENT ON COLUMN circulation_policies.damage_charge_per_step IS 'Charged per condition step lost on return once the threshold is reached';
+COMMENT ON COLUMN circulation_policies.damage_step_threshold IS 'Condition steps a copy can drop on return before a damage charge applies';
@@ -0,0 +1,24 @@
+-- Migration: Background job state
+-- One row per job records who holds the run lease and how the last run went.
+-- Instances claim a job by taking the lease, so a job only runs on one server at a time;
+-- a lease left behind by a crashed instance expires at locked_until.
+
+CREATE TABLE IF NOT EXISTS background_jobs (
+    name VARCHAR(100) PRIMARY KEY,
+    locked_by VARCHAR(255),
+    locked_until TIMESTAMP,
+    last_status VARCHAR(20) CHECK (last_status IN ('running', 'succeeded', 'failed')),
+    last_started_at TIMESTAMP,
+    last_finished_at TIMESTAMP,
+    last_success_at TIMESTAMP,
+    last_error TEXT,
+    last_result JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add comments for documentation
+COMMENT ON TABLE background_jobs IS 'Run lease and last run outcome for each background job';
+COMMENT ON COLUMN background_jobs.locked_by IS 'Server instance holding the run lease';
+COMMENT ON COLUMN background_jobs.locked_until IS 'When the lease expires if the holder never finishes';
+COMMENT ON COLUMN background_jobs.last_result IS 'Job specific summary of the last run';
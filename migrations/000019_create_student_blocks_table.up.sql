@@ -0,0 +1,26 @@
+-- Migration: Librarian-placed account blocks
+-- A block stops a student borrowing, renewing and reserving until it is lifted or expires.
+-- Blocks for unpaid fines and long overdue loans are worked out from the student's
+-- current fines and loans when they are checked, so only manual blocks are stored.
+
+CREATE TABLE student_blocks (
+    id SERIAL PRIMARY KEY,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMP,
+    created_by INTEGER REFERENCES users(id),
+    lifted_at TIMESTAMP,
+    lifted_by INTEGER REFERENCES users(id),
+    lift_reason TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CHECK (lifted_at IS NULL OR lift_reason IS NOT NULL)
+);
+
+-- Indexes for performance
+CREATE INDEX idx_student_blocks_student_id ON student_blocks(student_id);
+CREATE INDEX idx_student_blocks_active ON student_blocks(student_id) WHERE lifted_at IS NULL;
+
+-- Add comments for documentation
+COMMENT ON COLUMN student_blocks.expires_at IS 'The block ends on its own at this time; NULL blocks until lifted';
+COMMENT ON COLUMN student_blocks.lift_reason IS 'Why the block was lifted early';
@@ -0,0 +1,12 @@
+DELETE FROM notifications WHERE type = 'recall_notice';
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_type_check
+    CHECK (type IN ('overdue_reminder', 'due_soon', 'book_available', 'fine_notice'));
+
+ALTER TABLE circulation_policies DROP COLUMN IF EXISTS recall_fine_per_day;
+ALTER TABLE circulation_policies DROP COLUMN IF EXISTS recall_loan_days;
+
+DROP INDEX IF EXISTS idx_transactions_recalled;
+ALTER TABLE transactions DROP COLUMN IF EXISTS original_due_date;
+ALTER TABLE transactions DROP COLUMN IF EXISTS recalled_by;
+ALTER TABLE transactions DROP COLUMN IF EXISTS recalled_at;
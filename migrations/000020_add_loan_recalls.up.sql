@@ -0,0 +1,25 @@
+-- Migration: Recalls of loaned books that another student has reserved
+-- A recall brings the due date forward to the end of the minimum guaranteed loan
+-- period, stops the loan being renewed and charges a higher rate if the book is
+-- returned late. original_due_date keeps the due date the loan had before the recall.
+
+ALTER TABLE transactions ADD COLUMN recalled_at TIMESTAMP;
+ALTER TABLE transactions ADD COLUMN recalled_by INTEGER REFERENCES users(id);
+ALTER TABLE transactions ADD COLUMN original_due_date TIMESTAMP;
+
+CREATE INDEX idx_transactions_recalled ON transactions(book_id) WHERE recalled_at IS NOT NULL AND returned_date IS NULL;
+
+-- The guaranteed period and recall fine are part of the circulation policy so they can vary by patron and item type
+ALTER TABLE circulation_policies ADD COLUMN recall_loan_days INTEGER NOT NULL DEFAULT 7 CHECK (recall_loan_days >= 1);
+ALTER TABLE circulation_policies ADD COLUMN recall_fine_per_day DECIMAL(10,2) NOT NULL DEFAULT 1.00 CHECK (recall_fine_per_day >= 0);
+
+-- Recall notices are sent to the borrower
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_type_check
+    CHECK (type IN ('overdue_reminder', 'due_soon', 'book_available', 'fine_notice', 'recall_notice'));
+
+-- Add comments for documentation
+COMMENT ON COLUMN transactions.recalled_at IS 'Set when the loan is recalled for another student; recalled loans cannot be renewed';
+COMMENT ON COLUMN transactions.original_due_date IS 'Due date before the recall brought it forward';
+COMMENT ON COLUMN circulation_policies.recall_loan_days IS 'Days a borrower keeps a recalled book, counted from the loan date';
+COMMENT ON COLUMN circulation_policies.recall_fine_per_day IS 'Fine per day charged instead of fine_per_day when a recalled book is late';
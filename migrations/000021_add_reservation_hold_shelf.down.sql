@@ -0,0 +1,20 @@
+-- Put held copies back on the shelf and their reservations back in the queue
+UPDATE book_copies SET status = 'available', updated_at = NOW() WHERE status = 'on_hold';
+UPDATE reservations SET status = 'active', updated_at = NOW() WHERE status = 'ready';
+UPDATE books
+SET available_copies = (SELECT COUNT(*) FROM book_copies c WHERE c.book_id = books.id AND c.status = 'available'),
+    updated_at = NOW()
+WHERE EXISTS (SELECT 1 FROM book_copies c WHERE c.book_id = books.id);
+
+ALTER TABLE book_copies DROP CONSTRAINT IF EXISTS book_copies_status_check;
+ALTER TABLE book_copies ADD CONSTRAINT book_copies_status_check
+    CHECK (status IN ('available', 'borrowed', 'maintenance', 'lost', 'withdrawn'));
+
+DROP INDEX IF EXISTS idx_reservations_hold_shelf;
+ALTER TABLE reservations DROP COLUMN IF EXISTS pickup_deadline;
+ALTER TABLE reservations DROP COLUMN IF EXISTS ready_at;
+ALTER TABLE reservations DROP COLUMN IF EXISTS copy_id;
+
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS reservations_status_check;
+ALTER TABLE reservations ADD CONSTRAINT reservations_status_check
+    CHECK (status IN ('active', 'fulfilled', 'cancelled', 'expired'));
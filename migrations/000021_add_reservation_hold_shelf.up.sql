@@ -0,0 +1,25 @@
+-- Migration: Hold shelf for reservations
+-- When a reserved book comes back, the copy is set aside for the next student in
+-- the queue instead of going back on the shelf. The reservation becomes ready and
+-- the student has until pickup_deadline to collect it before it passes to the next
+-- student.
+
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS reservations_status_check;
+ALTER TABLE reservations ADD CONSTRAINT reservations_status_check
+    CHECK (status IN ('active', 'ready', 'fulfilled', 'cancelled', 'expired'));
+
+ALTER TABLE reservations ADD COLUMN copy_id INTEGER REFERENCES book_copies(id) ON DELETE SET NULL;
+ALTER TABLE reservations ADD COLUMN ready_at TIMESTAMP;
+ALTER TABLE reservations ADD COLUMN pickup_deadline TIMESTAMP;
+
+CREATE INDEX idx_reservations_hold_shelf ON reservations(pickup_deadline) WHERE status = 'ready';
+
+-- A held copy is out of general circulation until it is collected or the hold lapses
+ALTER TABLE book_copies DROP CONSTRAINT IF EXISTS book_copies_status_check;
+ALTER TABLE book_copies ADD CONSTRAINT book_copies_status_check
+    CHECK (status IN ('available', 'borrowed', 'on_hold', 'maintenance', 'lost', 'withdrawn'));
+
+-- Add comments for documentation
+COMMENT ON COLUMN reservations.copy_id IS 'Copy set aside on the hold shelf for a ready reservation';
+COMMENT ON COLUMN reservations.ready_at IS 'When the reserved book was set aside for the student';
+COMMENT ON COLUMN reservations.pickup_deadline IS 'Last day to collect a ready reservation before it passes to the next student';
@@ -0,0 +1,7 @@
+ALTER TABLE circulation_policies DROP COLUMN IF EXISTS reservation_priority;
+
+DROP INDEX IF EXISTS idx_reservations_queue;
+
+ALTER TABLE reservations DROP COLUMN IF EXISTS queued_at;
+ALTER TABLE reservations DROP COLUMN IF EXISTS priority;
+ALTER TABLE reservations DROP COLUMN IF EXISTS suspended_until;
@@ -0,0 +1,22 @@
+-- Migration: Reservation suspension and queue priority
+-- A student can pause a reservation until a date without losing their place;
+-- while suspended the queue passes over them. Queues are ordered by priority
+-- and then by queued_at, which starts as the reservation time and is only
+-- changed when a librarian moves an entry within the queue.
+
+ALTER TABLE reservations ADD COLUMN suspended_until TIMESTAMP;
+ALTER TABLE reservations ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE reservations ADD COLUMN queued_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+UPDATE reservations SET queued_at = COALESCE(reserved_at, created_at, NOW());
+
+CREATE INDEX idx_reservations_queue ON reservations(book_id, priority DESC, queued_at) WHERE status = 'active';
+
+-- Priority classes are part of the circulation policy so they can vary by patron and item type
+ALTER TABLE circulation_policies ADD COLUMN reservation_priority INTEGER NOT NULL DEFAULT 0;
+
+-- Add comments for documentation
+COMMENT ON COLUMN reservations.suspended_until IS 'The queue passes over the reservation until this time; it keeps its place';
+COMMENT ON COLUMN reservations.priority IS 'Queue priority from the circulation policy when the reservation was placed; higher goes first';
+COMMENT ON COLUMN reservations.queued_at IS 'Orders reservations of the same priority; moved when a librarian reorders the queue';
+COMMENT ON COLUMN circulation_policies.reservation_priority IS 'Queue priority given to reservations placed under this rule; higher goes first';
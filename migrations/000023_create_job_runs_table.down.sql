@@ -0,0 +1,5 @@
+ALTER TABLE background_jobs DROP COLUMN IF EXISTS paused_by;
+ALTER TABLE background_jobs DROP COLUMN IF EXISTS paused_at;
+
+DROP INDEX IF EXISTS idx_job_runs_job;
+DROP TABLE IF EXISTS job_runs;
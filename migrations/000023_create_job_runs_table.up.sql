@@ -0,0 +1,29 @@
+-- Migration: Scheduled job run history and pausing
+-- Every run of a scheduled job, whether fired by its schedule or by an admin, is
+-- recorded with its duration and outcome. Which instance runs a job is decided in
+-- Redis; the database only records what happened.
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    id SERIAL PRIMARY KEY,
+    job_name VARCHAR(100) NOT NULL,
+    trigger_type VARCHAR(20) NOT NULL CHECK (trigger_type IN ('schedule', 'manual')),
+    triggered_by INTEGER REFERENCES users(id),
+    instance_id VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'failed')),
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP,
+    duration_ms BIGINT,
+    error TEXT
+);
+
+CREATE INDEX idx_job_runs_job ON job_runs(job_name, started_at DESC);
+
+-- A paused job keeps its schedule but is skipped until it is resumed
+ALTER TABLE background_jobs ADD COLUMN paused_at TIMESTAMP;
+ALTER TABLE background_jobs ADD COLUMN paused_by INTEGER REFERENCES users(id);
+
+-- Add comments for documentation
+COMMENT ON TABLE job_runs IS 'History of scheduled job runs';
+COMMENT ON COLUMN job_runs.trigger_type IS 'Whether the run was fired by the schedule or triggered by an admin';
+COMMENT ON COLUMN job_runs.instance_id IS 'Server instance that ran the job';
+COMMENT ON COLUMN background_jobs.paused_at IS 'When the job schedule was paused; NULL while it runs on schedule';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_email_template_versions_published;
+DROP TABLE IF EXISTS email_template_versions;
+DROP TABLE IF EXISTS email_templates;
@@ -0,0 +1,41 @@
+-- Migration: Email templates stored in the database with version history
+-- Every edit to a template adds a version. A version starts as a draft and goes
+-- live when it is published, which archives the version published before it.
+-- Rolling back publishes a copy of an older version, so history only grows.
+
+CREATE TABLE IF NOT EXISTS email_templates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    latest_version INTEGER NOT NULL DEFAULT 0,
+    published_version INTEGER,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS email_template_versions (
+    id SERIAL PRIMARY KEY,
+    template_id INTEGER NOT NULL REFERENCES email_templates(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    is_html BOOLEAN NOT NULL DEFAULT FALSE,
+    variables TEXT[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'published', 'archived')),
+    change_note TEXT,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP,
+    UNIQUE (template_id, version)
+);
+
+-- A template has at most one published version
+CREATE UNIQUE INDEX idx_email_template_versions_published ON email_template_versions(template_id) WHERE status = 'published';
+
+-- Add comments for documentation
+COMMENT ON TABLE email_templates IS 'Email templates; the content lives in email_template_versions';
+COMMENT ON COLUMN email_templates.latest_version IS 'Highest version number given out, used to number the next version';
+COMMENT ON COLUMN email_templates.published_version IS 'Version sent to recipients; NULL until a version is published';
+COMMENT ON TABLE email_template_versions IS 'Every saved version of an email template';
+COMMENT ON COLUMN email_template_versions.status IS 'Version status: draft, published, archived';
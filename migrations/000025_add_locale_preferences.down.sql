@@ -0,0 +1,11 @@
+-- Templates in other languages have no place once names are unique again
+DELETE FROM email_templates WHERE locale <> 'en';
+ALTER TABLE email_templates DROP CONSTRAINT IF EXISTS email_templates_name_locale_key;
+ALTER TABLE email_templates ADD CONSTRAINT email_templates_name_key UNIQUE (name);
+ALTER TABLE email_templates DROP COLUMN IF EXISTS locale;
+
+ALTER TABLE notifications DROP COLUMN IF EXISTS template_data;
+ALTER TABLE notifications DROP COLUMN IF EXISTS locale;
+
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
+ALTER TABLE students DROP COLUMN IF EXISTS locale;
@@ -0,0 +1,22 @@
+-- Migration: Locale preferences for notifications
+-- Students and staff choose the language their notifications are written in.
+-- Email templates are kept per locale; a template missing in a recipient's
+-- locale falls back to English. Notifications remember the locale they were
+-- written in and the template data used, so their email renders the same way.
+
+ALTER TABLE students ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT 'en';
+ALTER TABLE users ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT 'en';
+
+ALTER TABLE notifications ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT 'en';
+ALTER TABLE notifications ADD COLUMN template_data JSONB;
+
+ALTER TABLE email_templates ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT 'en';
+ALTER TABLE email_templates DROP CONSTRAINT IF EXISTS email_templates_name_key;
+ALTER TABLE email_templates ADD CONSTRAINT email_templates_name_locale_key UNIQUE (name, locale);
+
+-- Add comments for documentation
+COMMENT ON COLUMN students.locale IS 'Language notifications are written in: en, sw';
+COMMENT ON COLUMN users.locale IS 'Language notifications are written in: en, sw';
+COMMENT ON COLUMN notifications.locale IS 'Language the notification was written in';
+COMMENT ON COLUMN notifications.template_data IS 'Values the email template is rendered with, already formatted for the locale';
+COMMENT ON COLUMN email_templates.locale IS 'Language of the template; each name has one template per locale';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sms_deliveries;
+ALTER TABLE notifications DROP COLUMN IF EXISTS channels;
@@ -0,0 +1,40 @@
+-- Migration: SMS notification channel
+-- Notifications choose the channels they go out on. Each SMS sent for a
+-- notification is tracked in sms_deliveries, next to email_deliveries.
+
+ALTER TABLE notifications ADD COLUMN channels TEXT[] NOT NULL DEFAULT '{email}';
+
+CREATE TABLE sms_deliveries (
+    id SERIAL PRIMARY KEY,
+    notification_id INTEGER NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    phone_number VARCHAR(20) NOT NULL,
+    message TEXT NOT NULL,
+    segments INTEGER NOT NULL DEFAULT 1 CHECK (segments > 0),
+    encoding VARCHAR(10) NOT NULL DEFAULT 'gsm7' CHECK (encoding IN ('gsm7', 'ucs2')),
+    provider VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'delivered', 'failed')),
+    provider_message_id VARCHAR(255),
+    cost VARCHAR(50),
+    error_message TEXT,
+    sent_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    failed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Indexes for performance
+CREATE INDEX idx_sms_deliveries_notification_id ON sms_deliveries(notification_id);
+CREATE INDEX idx_sms_deliveries_status ON sms_deliveries(status);
+CREATE INDEX idx_sms_deliveries_provider_message_id ON sms_deliveries(provider_message_id) WHERE provider_message_id IS NOT NULL;
+CREATE INDEX idx_sms_deliveries_created_at ON sms_deliveries(created_at);
+
+-- Comments for documentation
+COMMENT ON COLUMN notifications.channels IS 'Channels the notification is delivered on: email, sms';
+COMMENT ON TABLE sms_deliveries IS 'Tracks SMS delivery status for notifications';
+COMMENT ON COLUMN sms_deliveries.phone_number IS 'Recipient phone number in E.164 form';
+COMMENT ON COLUMN sms_deliveries.segments IS 'Number of SMS segments the message is billed as';
+COMMENT ON COLUMN sms_deliveries.encoding IS 'Message encoding: gsm7, or ucs2 when the text needs it';
+COMMENT ON COLUMN sms_deliveries.status IS 'SMS delivery status: pending, sent, delivered, failed';
+COMMENT ON COLUMN sms_deliveries.provider_message_id IS 'Message ID from the SMS provider, used to match delivery reports';
+COMMENT ON COLUMN sms_deliveries.cost IS 'Cost reported by the SMS provider, with its currency';
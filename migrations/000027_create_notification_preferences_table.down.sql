@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS notification_preferences;
+DROP INDEX IF EXISTS idx_notifications_pending_digest;
+ALTER TABLE notifications DROP COLUMN IF EXISTS digest;
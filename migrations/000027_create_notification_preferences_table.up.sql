@@ -0,0 +1,36 @@
+-- Migration: Notification preferences
+-- Each student and librarian chooses the channels each notification type is
+-- sent on, hours in which nothing is sent, and whether routine notifications
+-- are rolled into a daily or weekly digest email instead of sent one by one.
+-- Recipients without a row get the defaults: every channel, no quiet hours and
+-- no digest.
+
+CREATE TABLE notification_preferences (
+    id SERIAL PRIMARY KEY,
+    recipient_id INTEGER NOT NULL,
+    recipient_type VARCHAR(20) NOT NULL CHECK (recipient_type IN ('student', 'librarian')),
+    channels JSONB NOT NULL DEFAULT '{}',
+    quiet_hours_start TIME,
+    quiet_hours_end TIME,
+    digest_mode VARCHAR(10) NOT NULL DEFAULT 'off' CHECK (digest_mode IN ('off', 'daily', 'weekly')),
+    last_digest_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (recipient_id, recipient_type),
+    CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL))
+);
+
+ALTER TABLE notifications ADD COLUMN digest BOOLEAN NOT NULL DEFAULT false;
+
+-- Indexes for performance
+CREATE INDEX idx_notification_preferences_digest_mode ON notification_preferences(digest_mode);
+CREATE INDEX idx_notifications_pending_digest ON notifications(recipient_id, recipient_type) WHERE digest AND sent_at IS NULL;
+
+-- Comments for documentation
+COMMENT ON TABLE notification_preferences IS 'How each student and librarian wants to receive notifications';
+COMMENT ON COLUMN notification_preferences.channels IS 'Channels enabled per notification type, as {"due_soon": ["email"]}; types not listed use every channel';
+COMMENT ON COLUMN notification_preferences.quiet_hours_start IS 'Start of the daily window in which notifications are held back, in library time';
+COMMENT ON COLUMN notification_preferences.quiet_hours_end IS 'End of the quiet hours window; may be earlier than the start to span midnight';
+COMMENT ON COLUMN notification_preferences.digest_mode IS 'Routine notifications are collected into a digest: off, daily, weekly';
+COMMENT ON COLUMN notification_preferences.last_digest_at IS 'When the recipient was last sent a digest';
+COMMENT ON COLUMN notifications.digest IS 'Held for the recipient''s digest email instead of being sent on its own';
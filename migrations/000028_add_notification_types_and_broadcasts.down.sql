@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS notification_broadcast_recipients;
+DROP TABLE IF EXISTS notification_broadcasts;
+
+DELETE FROM notifications WHERE type IN ('reservation_ready', 'reservation_expired', 'account_blocked', 'announcement');
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_type_check
+    CHECK (type IN ('overdue_reminder', 'due_soon', 'book_available', 'fine_notice', 'recall_notice'));
@@ -0,0 +1,44 @@
+-- Migration: More notification types and broadcast announcements
+-- Students are told when a reserved book is ready, when a reservation or hold
+-- expires and when their account is blocked. Librarians can broadcast an
+-- announcement to a segment of students; each broadcast keeps one row per
+-- student it was sent to, linked to the notification created for them.
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_type_check
+    CHECK (type IN ('overdue_reminder', 'due_soon', 'book_available', 'fine_notice', 'recall_notice',
+                    'reservation_ready', 'reservation_expired', 'account_blocked', 'announcement'));
+
+CREATE TABLE notification_broadcasts (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    priority VARCHAR(10) NOT NULL DEFAULT 'medium' CHECK (priority IN ('low', 'medium', 'high', 'urgent')),
+    channels TEXT[] NOT NULL DEFAULT '{email}',
+    segment JSONB NOT NULL DEFAULT '{}',
+    scheduled_for TIMESTAMP,
+    created_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE notification_broadcast_recipients (
+    id SERIAL PRIMARY KEY,
+    broadcast_id INTEGER NOT NULL REFERENCES notification_broadcasts(id) ON DELETE CASCADE,
+    student_id INTEGER NOT NULL REFERENCES students(id),
+    notification_id INTEGER REFERENCES notifications(id) ON DELETE SET NULL,
+    error_message TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (broadcast_id, student_id)
+);
+
+-- Indexes for performance
+CREATE INDEX idx_notification_broadcasts_created_at ON notification_broadcasts(created_at);
+CREATE INDEX idx_notification_broadcast_recipients_notification_id ON notification_broadcast_recipients(notification_id);
+
+-- Comments for documentation
+COMMENT ON TABLE notification_broadcasts IS 'Announcements sent to a segment of students';
+COMMENT ON COLUMN notification_broadcasts.segment IS 'Students targeted, as {"year_of_study": 2, "department": "Physics", "active_borrowers": true, "with_overdue_loans": true}; an empty segment is every active student';
+COMMENT ON COLUMN notification_broadcasts.created_by IS 'Librarian who sent the broadcast';
+COMMENT ON TABLE notification_broadcast_recipients IS 'Each student a broadcast was sent to';
+COMMENT ON COLUMN notification_broadcast_recipients.notification_id IS 'Notification created for the student; NULL if it could not be created';
+COMMENT ON COLUMN notification_broadcast_recipients.error_message IS 'Why the notification could not be created';
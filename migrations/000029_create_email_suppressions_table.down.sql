@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_email_deliveries_provider_message_id;
+DROP TABLE IF EXISTS email_suppressions;
@@ -0,0 +1,28 @@
+-- Migration: Email bounces and suppressed addresses
+-- Bounce reports are matched to the email delivery they are about and mark it
+-- bounced. Hard bounces are counted against the address; once it has bounced
+-- often enough it is suppressed and no more email is sent to it until it is
+-- fixed or the suppression is cleared.
+
+CREATE TABLE email_suppressions (
+    id SERIAL PRIMARY KEY,
+    email_address VARCHAR(255) NOT NULL UNIQUE,
+    hard_bounce_count INTEGER NOT NULL DEFAULT 0 CHECK (hard_bounce_count >= 0),
+    last_bounced_at TIMESTAMP,
+    last_bounce_status VARCHAR(20),
+    last_bounce_reason TEXT,
+    suppressed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Indexes for performance
+CREATE INDEX idx_email_suppressions_suppressed_at ON email_suppressions(suppressed_at) WHERE suppressed_at IS NOT NULL;
+CREATE INDEX idx_email_deliveries_provider_message_id ON email_deliveries(provider_message_id);
+
+-- Comments for documentation
+COMMENT ON TABLE email_suppressions IS 'Hard bounces counted against each email address, and whether email to it is suppressed';
+COMMENT ON COLUMN email_suppressions.email_address IS 'Address in lower case';
+COMMENT ON COLUMN email_suppressions.last_bounce_status IS 'Enhanced status code of the last bounce, such as 5.1.1';
+COMMENT ON COLUMN email_suppressions.last_bounce_reason IS 'Diagnostic the receiving server gave for the last bounce';
+COMMENT ON COLUMN email_suppressions.suppressed_at IS 'When the address was flagged invalid; NULL while email is still sent to it';
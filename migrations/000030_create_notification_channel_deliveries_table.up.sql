@@ -0,0 +1,19 @@
+-- Migration: Channels each notification has been delivered on
+-- A notification sent by email and SMS is retried when either fails. Each channel
+-- that reaches the recipient is recorded so a retry only goes out again on the
+-- channels that did not.
+
+CREATE TABLE notification_channel_deliveries (
+    notification_id INTEGER NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    delivered_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (notification_id, channel)
+);
+
+-- Notifications sent before channels were tracked are not sent again on any channel
+INSERT INTO notification_channel_deliveries (notification_id, channel, delivered_at)
+SELECT id, unnest(channels), sent_at FROM notifications WHERE sent_at IS NOT NULL;
+
+-- Comments for documentation
+COMMENT ON TABLE notification_channel_deliveries IS 'Channels each notification has reached its recipient on';
+COMMENT ON COLUMN notification_channel_deliveries.channel IS 'Channel the notification was delivered on: email, sms';
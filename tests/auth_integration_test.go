@@ -123,6 +123,26 @@ func (m *MockUserService) UpdateStudentPassword(studentID int, hashedPassword st
 	return services.ErrUserNotFound
 }
 
+func (m *MockUserService) UpdateLocale(userID int, locale string) error {
+	for _, user := range m.users {
+		if user.ID == userID {
+			user.Locale = locale
+			return nil
+		}
+	}
+	return services.ErrUserNotFound
+}
+
+func (m *MockUserService) UpdateStudentLocale(studentID int, locale string) error {
+	for _, student := range m.students {
+		if student.ID == studentID {
+			student.Locale = locale
+			return nil
+		}
+	}
+	return services.ErrUserNotFound
+}
+
 func (m *MockUserService) AddUser(user *models.User) {
 	m.users[user.Username] = user
 }
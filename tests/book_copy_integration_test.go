@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+func TestBookCopyIntegration_BarcodeCirculation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	querier := queries.New(db)
+	transactionService := services.NewTransactionService(services.NewTransactionStore(db))
+	bookCopyService := services.NewBookCopyService(querier)
+
+	ctx := context.Background()
+
+	librarian := createTestLibrarian(t, querier, "test_librarian_copies", "test.librarian.copies@example.com")
+	student := createTestStudent(t, querier, "Copy", "Borrower", "STU_COPY001")
+	book := createTestBook(t, querier, "Copy Tracked Book", "Test Author", "BK_COPY001", 2)
+
+	// Borrow the second copy by scanning it
+	loan, err := transactionService.BorrowBookByBarcode(ctx, student.ID, "BK_COPY001-002", librarian.ID, "")
+	require.NoError(t, err)
+	require.NotNil(t, loan.CopyID)
+	assert.Equal(t, "BK_COPY001-002", loan.Barcode)
+
+	scanned, err := bookCopyService.GetCopyByBarcode(ctx, "BK_COPY001-002")
+	require.NoError(t, err)
+	assert.Equal(t, models.BookCopyStatusBorrowed, scanned.Status)
+
+	updatedBook, err := querier.GetBookByID(ctx, book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), updatedBook.AvailableCopies.Int32)
+
+	// A loaned copy cannot be borrowed again
+	_, err = transactionService.BorrowBookByBarcode(ctx, student.ID, "BK_COPY001-002", librarian.ID, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not available")
+
+	// Withdrawing the other copy takes it out of the stock counts
+	firstCopy, err := bookCopyService.GetCopyByBarcode(ctx, "BK_COPY001-001")
+	require.NoError(t, err)
+	withdrawn := string(models.BookCopyStatusWithdrawn)
+	_, err = bookCopyService.UpdateCopy(ctx, firstCopy.ID, models.UpdateBookCopyRequest{Status: &withdrawn})
+	require.NoError(t, err)
+
+	updatedBook, err = querier.GetBookByID(ctx, book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), updatedBook.TotalCopies.Int32)
+	assert.Equal(t, int32(0), updatedBook.AvailableCopies.Int32)
+
+	// The copy on loan cannot be withdrawn until it is returned
+	_, err = bookCopyService.UpdateCopy(ctx, scanned.ID, models.UpdateBookCopyRequest{Status: &withdrawn})
+	require.Error(t, err)
+
+	// Return by barcode with damage recorded on the copy
+	returned, err := transactionService.ReturnBookByBarcode(ctx, "BK_COPY001-002", "poor", "Torn spine", services.AuditActor{UserType: "librarian"})
+	require.NoError(t, err)
+	assert.Equal(t, loan.ID, returned.ID)
+
+	copies, err := bookCopyService.ListCopies(ctx, book.ID)
+	require.NoError(t, err)
+	require.Len(t, copies, 2)
+	assert.Equal(t, models.BookCopyStatusWithdrawn, copies[0].Status)
+	assert.Equal(t, models.BookCopyStatusAvailable, copies[1].Status)
+	assert.Equal(t, "poor", copies[1].Condition)
+
+	updatedBook, err = querier.GetBookByID(ctx, book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), updatedBook.AvailableCopies.Int32)
+	assert.Equal(t, int32(1), updatedBook.TotalCopies.Int32)
+}
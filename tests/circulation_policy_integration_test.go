@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+func TestCirculationPolicyIntegration_ResolvesMostSpecificRule(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	querier := queries.New(db)
+	policyService := services.NewCirculationPolicyService(querier)
+	transactionService := services.NewTransactionService(services.NewTransactionStore(db)).WithPolicyResolver(policyService)
+
+	ctx := context.Background()
+
+	year := int32(1)
+	department := "computer science"
+	itemType := "Test Reference"
+	policy, err := policyService.CreatePolicy(ctx, models.CreateCirculationPolicyRequest{
+		Name:            "Test year 1 reference",
+		YearOfStudy:     &year,
+		Department:      &department,
+		ItemType:        &itemType,
+		LoanDays:        3,
+		MaxLoans:        1,
+		MaxRenewals:     0,
+		FinePerDay:      decimal.NewFromFloat(2.00),
+		GracePeriodDays: 1,
+		MaxReservations: 1,
+		ReservationDays: 2,
+	})
+	require.NoError(t, err)
+	defer func() { _ = policyService.DeletePolicy(ctx, policy.ID) }()
+
+	// Department and item type match case-insensitively
+	resolved, err := policyService.ResolvePolicy(ctx, services.PolicyContext{
+		YearOfStudy: 1,
+		Department:  "Computer Science",
+		UserType:    models.PatronTypeStudent,
+		ItemType:    "test reference",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resolved)
+	assert.Equal(t, policy.ID, resolved.ID)
+
+	// Other items fall through to the seeded year 1 rule
+	resolved, err = policyService.ResolvePolicy(ctx, services.PolicyContext{
+		YearOfStudy: 1,
+		Department:  "Computer Science",
+		UserType:    models.PatronTypeStudent,
+		ItemType:    "Fiction",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resolved)
+	assert.NotEqual(t, policy.ID, resolved.ID)
+	assert.Equal(t, 14, resolved.LoanDays)
+
+	// Borrowing a matching item uses the rule's loan period and loan limit
+	librarian := createTestLibrarian(t, querier, "test_librarian_policy", "test.librarian.policy@example.com")
+	student := createTestStudent(t, querier, "Policy", "Borrower", "STU_POLICY001")
+	first := createTestBook(t, querier, "Policy Reference One", "Test Author", "BK_POLICY001", 1)
+	second := createTestBook(t, querier, "Policy Reference Two", "Test Author", "BK_POLICY002", 1)
+	_, err = db.Exec(ctx, "UPDATE books SET genre = $1 WHERE id IN ($2, $3)", itemType, first.ID, second.ID)
+	require.NoError(t, err)
+
+	loan, err := transactionService.BorrowBook(ctx, student.ID, first.ID, librarian.ID, "")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 3), loan.DueDate, time.Minute)
+
+	_, err = transactionService.BorrowBook(ctx, student.ID, second.ID, librarian.ID, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "maximum number of books (1)")
+}
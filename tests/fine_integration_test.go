@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/models"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+func TestFineIntegration_LedgerLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	defer cleanupTestData(t, db)
+
+	querier := queries.New(db)
+	fineService := services.NewFineService(services.NewFineStore(db))
+	actor := services.AuditActor{UserType: "librarian", IPAddress: "127.0.0.1"}
+
+	ctx := context.Background()
+
+	student, err := querier.CreateStudent(ctx, queries.CreateStudentParams{
+		StudentID:   "TEST_FINE_001",
+		FirstName:   "Test",
+		LastName:    "Fines",
+		YearOfStudy: 2,
+		Department:  pgtype.Text{String: "Computer Science", Valid: true},
+	})
+	require.NoError(t, err)
+
+	fine, err := fineService.AssessFine(ctx, models.CreateFineRequest{
+		StudentID: student.ID,
+		FineType:  string(models.FineTypeDamage),
+		Amount:    decimal.RequireFromString("250.00"),
+	}, actor)
+	require.NoError(t, err)
+	assert.Equal(t, models.FineStatusOutstanding, fine.Status)
+
+	// A partial payment gets a receipt
+	payment, err := fineService.RecordPayment(ctx, fine.ID, models.RecordFinePaymentRequest{
+		Amount:        decimal.RequireFromString("100.00"),
+		PaymentMethod: string(models.PaymentMethodCash),
+	}, actor)
+	require.NoError(t, err)
+	assert.Equal(t, models.FineStatusPartiallyPaid, payment.Fine.Status)
+	require.NotNil(t, payment.Entry.ReceiptNumber)
+
+	receipt, err := fineService.GetReceipt(ctx, *payment.Entry.ReceiptNumber)
+	require.NoError(t, err)
+	assert.Equal(t, payment.Entry.ID, receipt.ID)
+
+	// Paying more than is owed is rejected
+	_, err = fineService.RecordPayment(ctx, fine.ID, models.RecordFinePaymentRequest{
+		Amount:        decimal.RequireFromString("200.00"),
+		PaymentMethod: string(models.PaymentMethodCash),
+	}, actor)
+	require.Error(t, err)
+
+	waiver, err := fineService.WaiveFine(ctx, fine.ID, models.WaiveFineRequest{Reason: "Test waiver"}, actor)
+	require.NoError(t, err)
+	assert.True(t, waiver.Fine.Balance.IsZero())
+	assert.Equal(t, models.FineStatusPaid, waiver.Fine.Status)
+
+	balance, err := fineService.GetStudentBalance(ctx, student.ID)
+	require.NoError(t, err)
+	assert.True(t, balance.Balance.IsZero())
+	assert.Len(t, balance.Fines, 1)
+
+	ledger, err := fineService.GetFineLedger(ctx, fine.ID)
+	require.NoError(t, err)
+	assert.Len(t, ledger, 2)
+
+	report, err := fineService.GetCashReconciliation(ctx, time.Now())
+	require.NoError(t, err)
+	assert.True(t, report.Collected.GreaterThanOrEqual(decimal.RequireFromString("100.00")))
+}
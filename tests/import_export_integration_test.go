@@ -43,7 +43,7 @@ func TestImportExportIntegration(t *testing.T) {
 	defer db.Close()
 
 	// Initialize services
-	bookService := services.NewBookService(db.Queries)
+	bookService := services.NewBookService(services.NewBookStore(db.Pool))
 	importExportService := services.NewImportExportService(bookService, "./testdata")
 
 	// Initialize handlers
@@ -340,7 +340,7 @@ func TestImportExportFileOperations(t *testing.T) {
 		require.NoError(t, err)
 		defer db.Close()
 
-		bookService := services.NewBookService(db.Queries)
+		bookService := services.NewBookService(services.NewBookStore(db.Pool))
 		importExportService := services.NewImportExportService(bookService, "./testdata")
 		importExportHandler := handlers.NewImportExportHandler(importExportService)
 
@@ -384,7 +384,7 @@ func TestImportExportFileOperations(t *testing.T) {
 		require.NoError(t, err)
 		defer db.Close()
 
-		bookService := services.NewBookService(db.Queries)
+		bookService := services.NewBookService(services.NewBookStore(db.Pool))
 		importExportService := services.NewImportExportService(bookService, "./testdata")
 		importExportHandler := handlers.NewImportExportHandler(importExportService)
 
@@ -440,7 +440,7 @@ func TestImportExportPersistence(t *testing.T) {
 		require.NoError(t, err)
 		defer db.Close()
 
-		bookService := services.NewBookService(db.Queries)
+		bookService := services.NewBookService(services.NewBookStore(db.Pool))
 		importExportService := services.NewImportExportService(bookService, "./testdata")
 
 		// Use a unique book ID and ISBN for each test run to avoid conflicts
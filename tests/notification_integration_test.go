@@ -194,19 +194,9 @@ func (suite *NotificationIntegrationTestSuite) TestPhase7_2_DueSoonReminders() {
 	require.NoError(t, err)
 
 	// Update book availability
-	_, err = suite.queries.UpdateBook(suite.ctx, queries.UpdateBookParams{
+	err = suite.queries.UpdateBookAvailability(suite.ctx, queries.UpdateBookAvailabilityParams{
 		ID:              suite.testBook.ID,
-		BookID:          suite.testBook.BookID,
-		Title:           suite.testBook.Title,
-		Author:          suite.testBook.Author,
-		Publisher:       suite.testBook.Publisher,
-		PublishedYear:   suite.testBook.PublishedYear,
-		Genre:           suite.testBook.Genre,
-		Isbn:            suite.testBook.Isbn,
-		TotalCopies:     suite.testBook.TotalCopies,
 		AvailableCopies: pgtype.Int4{Int32: 0, Valid: true}, // Book is now borrowed
-		ShelfLocation:   suite.testBook.ShelfLocation,
-		Description:     suite.testBook.Description,
 	})
 	require.NoError(t, err)
 
@@ -251,19 +241,9 @@ func (suite *NotificationIntegrationTestSuite) TestPhase7_2_OverdueReminders() {
 	require.NoError(t, err)
 
 	// Update book availability
-	_, err = suite.queries.UpdateBook(suite.ctx, queries.UpdateBookParams{
+	err = suite.queries.UpdateBookAvailability(suite.ctx, queries.UpdateBookAvailabilityParams{
 		ID:              suite.testBook.ID,
-		BookID:          suite.testBook.BookID,
-		Title:           suite.testBook.Title,
-		Author:          suite.testBook.Author,
-		Publisher:       suite.testBook.Publisher,
-		PublishedYear:   suite.testBook.PublishedYear,
-		Genre:           suite.testBook.Genre,
-		Isbn:            suite.testBook.Isbn,
-		TotalCopies:     suite.testBook.TotalCopies,
 		AvailableCopies: pgtype.Int4{Int32: 0, Valid: true}, // Book is now borrowed
-		ShelfLocation:   suite.testBook.ShelfLocation,
-		Description:     suite.testBook.Description,
 	})
 	require.NoError(t, err)
 
@@ -296,19 +276,9 @@ func (suite *NotificationIntegrationTestSuite) TestPhase7_2_BookAvailableNotific
 	t := suite.T()
 
 	// First, make the book unavailable
-	_, err := suite.queries.UpdateBook(suite.ctx, queries.UpdateBookParams{
+	err := suite.queries.UpdateBookAvailability(suite.ctx, queries.UpdateBookAvailabilityParams{
 		ID:              suite.testBook.ID,
-		BookID:          suite.testBook.BookID,
-		Title:           suite.testBook.Title,
-		Author:          suite.testBook.Author,
-		Publisher:       suite.testBook.Publisher,
-		PublishedYear:   suite.testBook.PublishedYear,
-		Genre:           suite.testBook.Genre,
-		Isbn:            suite.testBook.Isbn,
-		TotalCopies:     suite.testBook.TotalCopies,
 		AvailableCopies: pgtype.Int4{Int32: 0, Valid: true}, // Book is unavailable
-		ShelfLocation:   suite.testBook.ShelfLocation,
-		Description:     suite.testBook.Description,
 	})
 	require.NoError(suite.T(), err)
 
@@ -507,6 +477,10 @@ func (m *MockEmailService) SendEmail(ctx context.Context, to, subject, body stri
 	return nil
 }
 
+func (m *MockEmailService) SendMessage(ctx context.Context, message *services.EmailMessage) error {
+	return nil
+}
+
 func (m *MockEmailService) SendTemplatedEmail(ctx context.Context, to string, template *models.EmailTemplate, data map[string]interface{}) error {
 	return nil
 }
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ngenohkevin/lms/internal/database/queries"
+	"github.com/ngenohkevin/lms/internal/services"
+)
+
+func TestTransactionConcurrency_BorrowDoesNotOversubscribeCopies(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	querier := queries.New(db)
+	transactionService := services.NewTransactionService(services.NewTransactionStore(db))
+
+	ctx := context.Background()
+
+	const copies = 3
+	const borrowers = 10
+
+	librarian := createTestLibrarian(t, querier, "test_librarian_concurrency", "test.librarian.concurrency@example.com")
+	book := createTestBook(t, querier, "Concurrent Book", "Test Author", "BK_CONC001", copies)
+
+	students := make([]queries.Student, borrowers)
+	for i := range students {
+		students[i] = createTestStudent(t, querier, "Concurrent", fmt.Sprintf("Borrower%d", i), fmt.Sprintf("STU_CONC%03d", i))
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var successes []*services.TransactionResponse
+	var failures []error
+
+	start := make(chan struct{})
+	for _, student := range students {
+		wg.Add(1)
+		go func(studentID int32) {
+			defer wg.Done()
+			<-start
+			transaction, err := transactionService.BorrowBook(ctx, studentID, book.ID, librarian.ID, "Concurrent borrow")
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures = append(failures, err)
+				return
+			}
+			successes = append(successes, transaction)
+		}(student.ID)
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Len(t, successes, copies)
+	assert.Len(t, failures, borrowers-copies)
+	for _, err := range failures {
+		assert.Contains(t, err.Error(), "book not available")
+	}
+
+	updatedBook, err := querier.GetBookByID(ctx, book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), updatedBook.AvailableCopies.Int32)
+
+	// Concurrent returns of the same loans must only be applied once each
+	t.Run("ConcurrentReturns", func(t *testing.T) {
+		var returnWg sync.WaitGroup
+		var returnMu sync.Mutex
+		returned := 0
+
+		returnStart := make(chan struct{})
+		for _, transaction := range successes {
+			for attempt := 0; attempt < 2; attempt++ {
+				returnWg.Add(1)
+				go func(transactionID int32) {
+					defer returnWg.Done()
+					<-returnStart
+					if _, err := transactionService.ReturnBook(ctx, transactionID, services.AuditActor{UserType: "librarian"}); err == nil {
+						returnMu.Lock()
+						returned++
+						returnMu.Unlock()
+					}
+				}(transaction.ID)
+			}
+		}
+		close(returnStart)
+		returnWg.Wait()
+
+		assert.Equal(t, copies, returned)
+
+		updatedBook, err := querier.GetBookByID(ctx, book.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int32(copies), updatedBook.AvailableCopies.Int32)
+	})
+}
+
+func TestTransactionConcurrency_StudentLoanLimitHolds(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	querier := queries.New(db)
+	transactionService := services.NewTransactionService(services.NewTransactionStore(db)).WithMaxBooksPerUser(2)
+
+	ctx := context.Background()
+
+	librarian := createTestLibrarian(t, querier, "test_librarian_limit", "test.librarian.limit@example.com")
+	student := createTestStudent(t, querier, "Limit", "Student", "STU_LIMIT001")
+
+	books := make([]queries.Book, 5)
+	for i := range books {
+		books[i] = createTestBook(t, querier, fmt.Sprintf("Limit Book %d", i), "Test Author", fmt.Sprintf("BK_LIMIT%03d", i), 1)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	successes := 0
+
+	start := make(chan struct{})
+	for _, book := range books {
+		wg.Add(1)
+		go func(bookID int32) {
+			defer wg.Done()
+			<-start
+			if _, err := transactionService.BorrowBook(ctx, student.ID, bookID, librarian.ID, ""); err == nil {
+				mu.Lock()
+				successes++
+				mu.Unlock()
+			}
+		}(book.ID)
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, 2, successes)
+
+	active, err := querier.ListActiveTransactionsByStudent(ctx, student.ID)
+	require.NoError(t, err)
+	assert.Len(t, active, 2)
+}
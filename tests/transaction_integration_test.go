@@ -5,6 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -33,6 +36,8 @@ type TransactionIntegrationTestSuite struct {
 	router  *gin.Engine
 	ctx     context.Context
 
+	transactionService *services.TransactionService
+
 	// Test data
 	testBook        queries.Book
 	testStudent     queries.Student
@@ -70,8 +75,8 @@ func (suite *TransactionIntegrationTestSuite) SetupSuite() {
 	suite.router = gin.New()
 
 	// Create transaction service and handler
-	transactionService := services.NewTransactionService(suite.queries)
-	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	suite.transactionService = services.NewTransactionService(services.NewTransactionStore(suite.db.Pool))
+	transactionHandler := handlers.NewTransactionHandler(suite.transactionService)
 
 	// Setup routes
 	v1 := suite.router.Group("/api/v1")
@@ -131,6 +136,7 @@ func (suite *TransactionIntegrationTestSuite) SetupTest() {
 		ShelfLocation:   pgtype.Text{String: "A1-B2", Valid: true},
 	})
 	require.NoError(suite.T(), err)
+	createTestBookCopies(suite.T(), suite.queries, testBook)
 	suite.testBook = testBook
 }
 
@@ -384,6 +390,61 @@ func (suite *TransactionIntegrationTestSuite) TestRenewBook_Success() {
 }
 
 // Test getting overdue transactions
+// A renewed loan returned by barcode is closed on its renewal, so the nightly
+// accrual neither fines the superseded borrow nor keeps the loan open
+func (suite *TransactionIntegrationTestSuite) TestRenewThenReturnByBarcode_StopsAccrual() {
+	borrowed, err := suite.transactionService.BorrowBook(suite.ctx, suite.testStudent.ID, suite.testBook.ID, suite.testUser.ID, "")
+	require.NoError(suite.T(), err)
+	require.NotEmpty(suite.T(), borrowed.Barcode)
+
+	renewed, err := suite.transactionService.RenewBook(suite.ctx, borrowed.ID, suite.testUser.ID)
+	require.NoError(suite.T(), err)
+
+	realtime := services.NewRealtimeService(suite.queries, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	before, err := realtime.LiveCounters(suite.ctx)
+	require.NoError(suite.T(), err)
+
+	// The borrow's own due date passes while the renewal runs
+	_, err = suite.db.Pool.Exec(suite.ctx, "UPDATE transactions SET due_date = NOW() - INTERVAL '5 days' WHERE id = $1", borrowed.ID)
+	require.NoError(suite.T(), err)
+
+	after, err := realtime.LiveCounters(suite.ctx)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), before.OverdueLoans, after.OverdueLoans, "the renewed loan is not overdue on the desk counter")
+
+	accrual := services.NewFineAccrualService(suite.queries, suite.transactionService, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	_, err = accrual.AccrueOverdueFines(suite.ctx)
+	require.NoError(suite.T(), err)
+
+	borrow, err := suite.queries.GetTransactionByID(suite.ctx, borrowed.ID)
+	require.NoError(suite.T(), err)
+	assert.False(suite.T(), borrow.FineAmount.Valid, "the superseded borrow must not accrue a fine")
+
+	returned, err := suite.transactionService.ReturnBookByBarcode(suite.ctx, borrowed.Barcode, "good", "", services.AuditActor{UserID: suite.testUser.ID, UserType: "librarian"})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), renewed.ID, returned.ID)
+	assert.True(suite.T(), returned.FineAmount.IsZero())
+	assert.Empty(suite.T(), returned.Charges)
+
+	active, err := suite.queries.ListActiveTransactionsByStudent(suite.ctx, suite.testStudent.ID)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), active, "every row of the returned loan is closed")
+
+	// Once the renewal's due date has passed too, nothing of the loan is fined
+	_, err = suite.db.Pool.Exec(suite.ctx, "UPDATE transactions SET due_date = NOW() - INTERVAL '1 day' WHERE id = $1", renewed.ID)
+	require.NoError(suite.T(), err)
+
+	_, err = accrual.AccrueOverdueFines(suite.ctx)
+	require.NoError(suite.T(), err)
+
+	for _, id := range []int32{borrowed.ID, renewed.ID} {
+		transaction, err := suite.queries.GetTransactionByID(suite.ctx, id)
+		require.NoError(suite.T(), err)
+		assert.True(suite.T(), transaction.ReturnedDate.Valid)
+		assert.False(suite.T(), transaction.FineAmount.Valid, "transaction %d must not accrue a fine after the return", id)
+	}
+}
+
 func (suite *TransactionIntegrationTestSuite) TestGetOverdueTransactions() {
 	// Create an overdue transaction
 	dueDate := time.Now().AddDate(0, 0, -3) // 3 days overdue
@@ -465,11 +526,11 @@ func (suite *TransactionIntegrationTestSuite) TestPayFine() {
 	})
 	require.NoError(suite.T(), err)
 
-	// Set a fine amount
-	fineAmount := decimal.NewFromFloat(1.00)
-	err = suite.queries.UpdateTransactionFine(suite.ctx, queries.UpdateTransactionFineParams{
-		ID:         transaction.ID,
-		FineAmount: pgtype.Numeric{Int: fineAmount.BigInt(), Valid: true},
+	fine, err := suite.queries.CreateFine(suite.ctx, queries.CreateFineParams{
+		StudentID:     suite.testStudent.ID,
+		TransactionID: pgtype.Int4{Int32: transaction.ID, Valid: true},
+		FineType:      "overdue",
+		Amount:        pgtype.Numeric{Int: big.NewInt(100), Exp: -2, Valid: true},
 	})
 	require.NoError(suite.T(), err)
 
@@ -491,7 +552,13 @@ func (suite *TransactionIntegrationTestSuite) TestPayFine() {
 	assert.True(suite.T(), response.Success)
 	assert.Equal(suite.T(), "Fine paid successfully", response.Message)
 
-	// Verify fine was marked as paid in database
+	// The payment is in the ledger and the loan is marked paid
+	entries, err := suite.queries.ListFineLedgerEntriesByFine(suite.ctx, fine.ID)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), entries, 1)
+	assert.Equal(suite.T(), "payment", entries[0].EntryType)
+	assert.True(suite.T(), entries[0].ReceiptNumber.Valid)
+
 	updatedTransaction, err := suite.queries.GetTransactionByID(suite.ctx, transaction.ID)
 	require.NoError(suite.T(), err)
 	assert.True(suite.T(), updatedTransaction.FinePaid.Bool)